@@ -0,0 +1,297 @@
+// Copyright 2019 HAProxy Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package client_native
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net"
+	"strings"
+
+	native_errors "github.com/haproxytech/client-native/v2/errors"
+	"github.com/haproxytech/models/v2"
+)
+
+// Blocklist is an IP allowlist or denylist of a frontend: an ACL file of addresses and
+// networks kept in general storage, the acl matching src against it and the http-request
+// deny rule referencing the acl
+type Blocklist struct {
+	// Name of the acl, the ACL file is stored as <name>.acl
+	Name string `json:"name"`
+	// Type is deny to deny listed addresses or allow to deny all other addresses
+	Type string `json:"type"`
+	// Entries are IP addresses or networks in CIDR notation
+	Entries []string `json:"entries,omitempty"`
+	// DenyStatus returned to denied clients, optional
+	DenyStatus *int64 `json:"deny_status,omitempty"`
+}
+
+// CreateBlocklist writes the ACL file to general storage and creates the acl and the deny
+// rule in the frontend in one transaction. One of version or transactionID is mandatory.
+// An ACL file created in a transaction is deleted with the transaction by DeleteTransaction.
+// Returns error on fail, nil on success.
+func (c *HAProxyClient) CreateBlocklist(frontend string, data *Blocklist, transactionID string, version int64) error {
+	if c.GeneralStorage == nil {
+		return fmt.Errorf("general storage not configured %w", native_errors.ErrGeneral)
+	}
+	if data.Type != "allow" && data.Type != "deny" {
+		return fmt.Errorf("unsupported blocklist type %s %w", data.Type, native_errors.ErrGeneral)
+	}
+	for _, e := range data.Entries {
+		if err := validateBlocklistEntry(e); err != nil {
+			return err
+		}
+	}
+
+	file := blocklistFile(data.Name)
+	path, err := c.GeneralStorage.Create(file, ioutil.NopCloser(strings.NewReader(serializeBlocklistEntries(data.Entries))))
+	if err != nil {
+		return err
+	}
+
+	err = c.withTransaction(transactionID, version, func(t string) error {
+		_, acls, err := c.Configuration.GetACLs("frontend", frontend, t)
+		if err != nil {
+			return err
+		}
+		aclIndex := int64(len(acls))
+		err = c.Configuration.CreateACL("frontend", frontend, &models.ACL{
+			Index:     &aclIndex,
+			ACLName:   data.Name,
+			Criterion: "src",
+			Value:     fmt.Sprintf("-f %s", path),
+		}, t, 0)
+		if err != nil {
+			return err
+		}
+
+		cond := "if"
+		if data.Type == "allow" {
+			cond = "unless"
+		}
+		// access control goes before any other rule of the frontend
+		ruleIndex := int64(0)
+		return c.Configuration.CreateHTTPRequestRule("frontend", frontend, &models.HTTPRequestRule{
+			Index:      &ruleIndex,
+			Type:       "deny",
+			DenyStatus: data.DenyStatus,
+			Cond:       cond,
+			CondTest:   data.Name,
+		}, t, 0)
+	})
+	if err != nil {
+		c.GeneralStorage.Delete(file)
+		return err
+	}
+	c.addTransactionFile(transactionID, c.GeneralStorage, file, path)
+	return nil
+}
+
+// GetBlocklist returns the blocklist with its entries from the frontend.
+// Returns error on fail or if blocklist does not exist.
+func (c *HAProxyClient) GetBlocklist(name string, frontend string, transactionID string) (*Blocklist, error) {
+	if c.GeneralStorage == nil {
+		return nil, fmt.Errorf("general storage not configured %w", native_errors.ErrGeneral)
+	}
+	path, err := c.GeneralStorage.Get(blocklistFile(name))
+	if err != nil {
+		return nil, err
+	}
+	if _, err := c.blocklistACL(name, path, frontend, transactionID); err != nil {
+		return nil, err
+	}
+	_, rules, err := c.Configuration.GetHTTPRequestRules("frontend", frontend, transactionID)
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range rules {
+		if r.Type != "deny" || r.CondTest != name {
+			continue
+		}
+		bl := &Blocklist{
+			Name:       name,
+			Type:       "deny",
+			DenyStatus: r.DenyStatus,
+		}
+		if r.Cond == "unless" {
+			bl.Type = "allow"
+		}
+		bl.Entries, err = readBlocklistEntries(path)
+		if err != nil {
+			return nil, err
+		}
+		return bl, nil
+	}
+	return nil, fmt.Errorf("blocklist %s in frontend %s %w", name, frontend, native_errors.ErrNotFound)
+}
+
+// DeleteBlocklist deletes the deny rule and the acl from the frontend in one transaction,
+// and the ACL file from general storage. One of version or transactionID is mandatory.
+// Returns error on fail, nil on success.
+func (c *HAProxyClient) DeleteBlocklist(name string, frontend string, transactionID string, version int64) error {
+	if _, err := c.GetBlocklist(name, frontend, transactionID); err != nil {
+		return err
+	}
+	path, err := c.GeneralStorage.Get(blocklistFile(name))
+	if err != nil {
+		return err
+	}
+	err = c.withTransaction(transactionID, version, func(t string) error {
+		_, rules, err := c.Configuration.GetHTTPRequestRules("frontend", frontend, t)
+		if err != nil {
+			return err
+		}
+		// delete from the end so the remaining indexes stay valid
+		for i := len(rules) - 1; i >= 0; i-- {
+			if rules[i].Type == "deny" && rules[i].CondTest == name {
+				if err := c.Configuration.DeleteHTTPRequestRule(*rules[i].Index, "frontend", frontend, t, 0); err != nil {
+					return err
+				}
+			}
+		}
+		acl, err := c.blocklistACL(name, path, frontend, t)
+		if err != nil {
+			return err
+		}
+		return c.Configuration.DeleteACL(*acl.Index, "frontend", frontend, t, 0)
+	})
+	if err != nil {
+		return err
+	}
+	return c.GeneralStorage.Delete(blocklistFile(name))
+}
+
+// AddBlocklistEntry adds an address or network to the ACL file and to the runtime ACL
+// if the file is already loaded by HAProxy. Without a runtime client only the file is changed
+func (c *HAProxyClient) AddBlocklistEntry(name, entry string) error {
+	if err := validateBlocklistEntry(entry); err != nil {
+		return err
+	}
+	if c.GeneralStorage == nil {
+		return fmt.Errorf("general storage not configured %w", native_errors.ErrGeneral)
+	}
+	path, err := c.GeneralStorage.Get(blocklistFile(name))
+	if err != nil {
+		return err
+	}
+	entries, err := readBlocklistEntries(path)
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		if e == entry {
+			return fmt.Errorf("entry %s in blocklist %s %w", entry, name, native_errors.ErrAlreadyExists)
+		}
+	}
+	entries = append(entries, entry)
+	if _, err := c.GeneralStorage.Replace(blocklistFile(name), serializeBlocklistEntries(entries)); err != nil {
+		return err
+	}
+	if c.Runtime == nil {
+		return nil
+	}
+	if a, _ := c.Runtime.GetACLFile(path); a != nil {
+		return c.Runtime.AddACLFileEntry(path, entry)
+	}
+	return nil
+}
+
+// DeleteBlocklistEntry removes an address or network from the ACL file and from the runtime
+// ACL if the file is already loaded by HAProxy. Without a runtime client only the file is changed
+func (c *HAProxyClient) DeleteBlocklistEntry(name, entry string) error {
+	if c.GeneralStorage == nil {
+		return fmt.Errorf("general storage not configured %w", native_errors.ErrGeneral)
+	}
+	path, err := c.GeneralStorage.Get(blocklistFile(name))
+	if err != nil {
+		return err
+	}
+	entries, err := readBlocklistEntries(path)
+	if err != nil {
+		return err
+	}
+	newEntries := []string{}
+	for _, e := range entries {
+		if e != entry {
+			newEntries = append(newEntries, e)
+		}
+	}
+	if len(newEntries) == len(entries) {
+		return fmt.Errorf("entry %s in blocklist %s %w", entry, name, native_errors.ErrNotFound)
+	}
+	if _, err := c.GeneralStorage.Replace(blocklistFile(name), serializeBlocklistEntries(newEntries)); err != nil {
+		return err
+	}
+	if c.Runtime == nil {
+		return nil
+	}
+	if a, _ := c.Runtime.GetACLFile(path); a != nil {
+		return c.Runtime.DeleteACLFileEntry(path, entry)
+	}
+	return nil
+}
+
+func (c *HAProxyClient) blocklistACL(name, path, frontend, transactionID string) (*models.ACL, error) {
+	_, acls, err := c.Configuration.GetACLs("frontend", frontend, transactionID)
+	if err != nil {
+		return nil, err
+	}
+	for _, a := range acls {
+		if a.ACLName == name && a.Criterion == "src" && a.Value == fmt.Sprintf("-f %s", path) {
+			return a, nil
+		}
+	}
+	return nil, fmt.Errorf("blocklist %s in frontend %s %w", name, frontend, native_errors.ErrNotFound)
+}
+
+func blocklistFile(name string) string {
+	return fmt.Sprintf("%s.acl", name)
+}
+
+func readBlocklistEntries(path string) ([]string, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	entries := []string{}
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		entries = append(entries, line)
+	}
+	return entries, nil
+}
+
+func serializeBlocklistEntries(entries []string) string {
+	var b strings.Builder
+	for _, e := range entries {
+		b.WriteString(e)
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+func validateBlocklistEntry(entry string) error {
+	if net.ParseIP(entry) != nil {
+		return nil
+	}
+	if _, _, err := net.ParseCIDR(entry); err == nil {
+		return nil
+	}
+	return fmt.Errorf("invalid address or network %s %w", entry, native_errors.ErrGeneral)
+}
@@ -0,0 +1,123 @@
+// Copyright 2019 HAProxy Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package client_native
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	native_errors "github.com/haproxytech/client-native/v2/errors"
+	"github.com/haproxytech/client-native/v2/storage"
+)
+
+func TestBlocklist(t *testing.T) {
+	c := newTestClient(t)
+	if err := c.CreateBlocklist("web", &Blocklist{Name: "blocked", Type: "deny"}, "", 1); err == nil {
+		t.Error("blocklist created without storage")
+	}
+	c.GeneralStorage = testStorage(t, storage.GeneralType)
+
+	if err := c.CreateBlocklist("web", &Blocklist{Name: "blocked", Type: "block"}, "", 1); err == nil {
+		t.Error("blocklist created with unsupported type")
+	}
+	if err := c.CreateBlocklist("web", &Blocklist{Name: "blocked", Type: "deny", Entries: []string{"10.0.0.0/33"}}, "", 1); err == nil {
+		t.Error("blocklist created with invalid network")
+	}
+	status := int64(403)
+	bl := &Blocklist{Name: "blocked", Type: "deny", Entries: []string{"10.0.0.1", "192.168.0.0/16"}, DenyStatus: &status}
+	if err := c.CreateBlocklist("web", bl, "", 1); err != nil {
+		t.Fatal(err)
+	}
+	got, err := c.GetBlocklist("blocked", "web", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, bl) {
+		t.Errorf("blocklist %+v, expected %+v", got, bl)
+	}
+	if err := c.CreateBlocklist("web", &Blocklist{Name: "blocked", Type: "deny"}, "", 2); err == nil {
+		t.Error("blocklist created twice")
+	}
+
+	if err := c.AddBlocklistEntry("blocked", "2001:db8::/32"); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.AddBlocklistEntry("blocked", "10.0.0.1"); !errors.Is(err, native_errors.ErrAlreadyExists) {
+		t.Errorf("entry added twice: %v", err)
+	}
+	if err := c.AddBlocklistEntry("blocked", "example.com"); err == nil {
+		t.Error("host name added as entry")
+	}
+	if err := c.DeleteBlocklistEntry("blocked", "10.0.0.1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.DeleteBlocklistEntry("blocked", "10.0.0.1"); !errors.Is(err, native_errors.ErrNotFound) {
+		t.Errorf("missing entry deleted: %v", err)
+	}
+	got, err = c.GetBlocklist("blocked", "web", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got.Entries, []string{"192.168.0.0/16", "2001:db8::/32"}) {
+		t.Errorf("blocklist entries %v", got.Entries)
+	}
+
+	if err := c.DeleteBlocklist("blocked", "web", "", 2); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.GeneralStorage.Get("blocked.acl"); err == nil {
+		t.Error("ACL file not deleted with blocklist")
+	}
+	_, acls, err := c.Configuration.GetACLs("frontend", "web", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, rules, err := c.Configuration.GetHTTPRequestRules("frontend", "web", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(acls) != 0 || len(rules) != 0 {
+		t.Errorf("%d acls and %d rules left after deleting the blocklist", len(acls), len(rules))
+	}
+}
+
+func TestBlocklistTransactionFiles(t *testing.T) {
+	c := newTestClient(t)
+	c.GeneralStorage = testStorage(t, storage.GeneralType)
+
+	committed := testTransaction(t, c)
+	if err := c.CreateBlocklist("web", &Blocklist{Name: "committed", Type: "allow"}, committed, 0); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Configuration.CommitTransaction(committed); err != nil {
+		t.Fatal(err)
+	}
+	discarded := testTransaction(t, c)
+	if err := c.CreateBlocklist("web", &Blocklist{Name: "discarded", Type: "deny"}, discarded, 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.DeleteTransaction(discarded); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := c.GetBlocklist("committed", "web", ""); err != nil {
+		t.Errorf("committed blocklist: %v", err)
+	}
+	if _, err := c.GeneralStorage.Get("discarded.acl"); err == nil {
+		t.Error("ACL file of deleted transaction not deleted")
+	}
+}
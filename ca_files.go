@@ -0,0 +1,112 @@
+// Copyright 2019 HAProxy Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package client_native
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	native_errors "github.com/haproxytech/client-native/v2/errors"
+	"github.com/haproxytech/client-native/v2/storage"
+)
+
+// UploadCAFile writes the PEM encoded CA certificates to CA storage, replacing the file if it
+// exists. Returns the path of the file.
+func (c *HAProxyClient) UploadCAFile(name string, content string) (string, error) {
+	if err := storage.ValidateCAFile(content); err != nil {
+		return "", err
+	}
+	return c.uploadCAStorageFile(name, content)
+}
+
+// UploadCRLFile writes the PEM encoded certificate revocation lists to CA storage, replacing the
+// file if it exists. Returns the path of the file.
+func (c *HAProxyClient) UploadCRLFile(name string, content string) (string, error) {
+	if err := storage.ValidateCRLFile(content); err != nil {
+		return "", err
+	}
+	return c.uploadCAStorageFile(name, content)
+}
+
+// SetBindCAFile configures the bind to verify client certificates with the CA file and the CRL
+// file in CA storage, an empty name removes the setting. One of version or transactionID is
+// mandatory. Returns error on fail, nil on success.
+func (c *HAProxyClient) SetBindCAFile(frontend string, bind string, caFile string, crlFile string, transactionID string, version int64) error {
+	caPath, crlPath, err := c.caStoragePaths(caFile, crlFile)
+	if err != nil {
+		return err
+	}
+	return c.withTransaction(transactionID, version, func(t string) error {
+		_, b, err := c.Configuration.GetBind(bind, frontend, t)
+		if err != nil {
+			return err
+		}
+		b.SslCafile = caPath
+		b.CrlFile = crlPath
+		return c.Configuration.EditBind(bind, frontend, b, t, 0)
+	})
+}
+
+// SetServerCAFile configures the server to verify its certificate with the CA file and the CRL
+// file in CA storage, an empty name removes the setting. One of version or transactionID is
+// mandatory. Returns error on fail, nil on success.
+func (c *HAProxyClient) SetServerCAFile(backend string, server string, caFile string, crlFile string, transactionID string, version int64) error {
+	caPath, crlPath, err := c.caStoragePaths(caFile, crlFile)
+	if err != nil {
+		return err
+	}
+	return c.withTransaction(transactionID, version, func(t string) error {
+		_, s, err := c.Configuration.GetServer(server, backend, t)
+		if err != nil {
+			return err
+		}
+		s.SslCafile = caPath
+		s.CrlFile = crlPath
+		return c.Configuration.EditServer(server, backend, s, t, 0)
+	})
+}
+
+func (c *HAProxyClient) uploadCAStorageFile(name string, content string) (string, error) {
+	if c.CAStorage == nil {
+		return "", fmt.Errorf("CA storage not configured %w", native_errors.ErrGeneral)
+	}
+	path, err := c.CAStorage.Replace(name, content)
+	if errors.Is(err, native_errors.ErrNotFound) {
+		path, err = c.CAStorage.Create(name, ioutil.NopCloser(strings.NewReader(content)))
+	}
+	return path, err
+}
+
+// caStoragePaths returns the paths of the CA and CRL files in CA storage, empty for empty names
+func (c *HAProxyClient) caStoragePaths(caFile string, crlFile string) (string, string, error) {
+	if c.CAStorage == nil {
+		return "", "", fmt.Errorf("CA storage not configured %w", native_errors.ErrGeneral)
+	}
+	paths := make([]string, 2)
+	for i, name := range []string{caFile, crlFile} {
+		if name == "" {
+			continue
+		}
+		path, err := c.CAStorage.Get(name)
+		if err != nil {
+			return "", "", err
+		}
+		paths[i] = path
+	}
+	return paths[0], paths[1], nil
+}
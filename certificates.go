@@ -0,0 +1,129 @@
+// Copyright 2019 HAProxy Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package client_native
+
+import (
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	native_errors "github.com/haproxytech/client-native/v2/errors"
+	"github.com/haproxytech/client-native/v2/storage"
+)
+
+// DeployCertificate writes the PEM bundle of an issued certificate to certificate storage and
+// hot-loads it in the running HAProxy if the certificate file is already used by a bind,
+// so a renewed certificate is served without a reload. A new certificate file needs to be
+// referenced from the configuration before it is used. Returns the path of the certificate file,
+// for storages encrypting private keys the path of the decrypted copy HAProxy loads.
+func (c *HAProxyClient) DeployCertificate(name string, bundle string) (string, error) {
+	if c.SSLCertStorage == nil {
+		return "", fmt.Errorf("certificate storage not configured %w", native_errors.ErrGeneral)
+	}
+	if err := validatePEMBundle(bundle); err != nil {
+		return "", err
+	}
+
+	path, err := c.SSLCertStorage.Replace(name, bundle)
+	if errors.Is(err, native_errors.ErrNotFound) {
+		path, err = c.SSLCertStorage.Create(name, ioutil.NopCloser(strings.NewReader(bundle)))
+	}
+	if err != nil {
+		return "", err
+	}
+
+	// HAProxy loads the decrypted copy of certificates with encrypted private keys
+	if enc, ok := c.SSLCertStorage.(*storage.EncryptedSSLStorage); ok {
+		path, err = enc.WriteRuntimeFile(name)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	certs, err := c.Runtime.ShowSSLCerts()
+	if err != nil {
+		return path, err
+	}
+	loaded := false
+	for _, cert := range certs {
+		if cert == path {
+			loaded = true
+			break
+		}
+	}
+	if !loaded {
+		return path, nil
+	}
+
+	if err := c.Runtime.SetSSLCert(path, bundle); err != nil {
+		_ = c.Runtime.AbortSSLCert(path)
+		return path, err
+	}
+	if err := c.Runtime.CommitSSLCert(path); err != nil {
+		_ = c.Runtime.AbortSSLCert(path)
+		return path, err
+	}
+	return path, nil
+}
+
+// DeployOCSPResponse writes the DER encoded OCSP response of the certificate to certificate
+// storage and updates the response the running HAProxy staples, so controllers can keep stapling
+// fresh without a reload. Returns the path of the OCSP response file.
+func (c *HAProxyClient) DeployOCSPResponse(name string, response []byte) (string, error) {
+	if c.SSLCertStorage == nil {
+		return "", fmt.Errorf("certificate storage not configured %w", native_errors.ErrGeneral)
+	}
+	path, err := c.SSLCertStorage.StoreOCSPResponse(name, response)
+	if err != nil {
+		return "", err
+	}
+	certs, err := c.Runtime.ShowSSLCerts()
+	if err != nil {
+		return path, err
+	}
+	for _, cert := range certs {
+		if cert+storage.OCSPSuffix == path {
+			return path, c.Runtime.SetOCSPResponse(base64.StdEncoding.EncodeToString(response))
+		}
+	}
+	return path, nil
+}
+
+// validatePEMBundle checks the bundle contains a certificate and a private key
+func validatePEMBundle(bundle string) error {
+	hasCert, hasKey := false, false
+	rest := []byte(bundle)
+	for {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		switch {
+		case block.Type == "CERTIFICATE":
+			hasCert = true
+		case strings.HasSuffix(block.Type, "PRIVATE KEY"):
+			hasKey = true
+		}
+	}
+	if !hasCert || !hasKey {
+		return fmt.Errorf("PEM bundle must contain a certificate and a private key %w", native_errors.ErrGeneral)
+	}
+	return nil
+}
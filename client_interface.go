@@ -3,7 +3,11 @@
 package client_native
 
 import (
+	"context"
+	"io"
+
 	"github.com/haproxytech/client-native/v2/configuration"
+	"github.com/haproxytech/client-native/v2/misc"
 	parser "github.com/haproxytech/config-parser/v3"
 	"github.com/haproxytech/models/v2"
 )
@@ -25,6 +29,36 @@ type IConfigurationClient interface {
 	// EditACL edits a ACL line in configuration. One of version or transactionID is
 	// mandatory. Returns error on fail, nil on success.
 	EditACL(id int64, parentType string, parentName string, data *models.ACL, transactionID string, version int64) error
+	// GetACMEChallenge returns configuration version and the ACME challenge routing of the frontend.
+	// Returns error on fail or if challenge routing does not exist.
+	GetACMEChallenge(frontend string, transactionID string) (int64, *configuration.ACMEChallenge, error)
+	// CreateACMEChallenge creates the challenge backend if it does not exist and routes challenge
+	// requests of the frontend to it with the first use_backend rule. An HTTPS redirect enabled on
+	// the frontend is updated to exclude challenge requests, since redirects are evaluated before
+	// backend switching. One of version or transactionID is mandatory. Returns error on fail, nil on success.
+	CreateACMEChallenge(frontend string, data *configuration.ACMEChallenge, transactionID string, version int64) error
+	// DeleteACMEChallenge removes the challenge routing from the frontend and deletes the challenge
+	// backend when no other frontend routes to it. One of version or transactionID is mandatory.
+	// Returns error on fail, nil on success.
+	DeleteACMEChallenge(frontend string, transactionID string, version int64) error
+	// GetFrontendAltSvc returns configuration version and the alt-svc header format set by
+	// the frontend http-response rules, empty string if the frontend does not set it.
+	GetFrontendAltSvc(frontend string, transactionID string) (int64, string, error)
+	// SetFrontendAltSvc sets the alt-svc header on the frontend responses with an
+	// http-response set-header rule, replacing the existing one. Nil value removes the rule.
+	SetFrontendAltSvc(frontend string, value *string, transactionID string, version int64) error
+	// SetAuditWriter enables the audit log written to w, nil disables it
+	SetAuditWriter(w io.Writer)
+	// SetAuditFile enables the audit log appended to the file at path, empty path disables it
+	SetAuditFile(path string) error
+	// SetAuthorizer sets the authorizer checking every change before it is made, nil disables
+	// authorization. The subject of a change is carried by the context of its transaction, see
+	// StartTransactionContext and SetContext. Changes made with a version have no transaction
+	// and are authorized with an empty subject.
+	SetAuthorizer(a configuration.Authorizer)
+	// SetContext sets the context of the changes made in the transaction. The subject of the
+	// context is passed to the authorizer. Returns error if the transaction does not exist.
+	SetContext(transactionID string, ctx context.Context) error
 	// GetBackends returns configuration version and an array of
 	// configured backends. Returns error on fail.
 	GetBackends(transactionID string) (int64, models.Backends, error)
@@ -70,6 +104,85 @@ type IConfigurationClient interface {
 	// EditBind edits a bind in configuration. One of version or transactionID is
 	// mandatory. Returns error on fail, nil on success.
 	EditBind(name string, frontend string, data *models.Bind, transactionID string, version int64) error
+	// GetCaches returns configuration version and an array of
+	// configured caches. Returns error on fail.
+	GetCaches(transactionID string) (int64, []*configuration.Cache, error)
+	// GetCache returns configuration version and a requested cache.
+	// Returns error on fail or if cache does not exist.
+	GetCache(name string, transactionID string) (int64, *configuration.Cache, error)
+	// DeleteCache deletes a cache in configuration. Caches used by frontends or backends can not be
+	// deleted. One of version or transactionID is mandatory. Returns error on fail, nil on success.
+	DeleteCache(name string, transactionID string, version int64) error
+	// CreateCache creates a cache in configuration. One of version or transactionID is mandatory.
+	// Returns error on fail, nil on success.
+	CreateCache(data *configuration.Cache, transactionID string, version int64) error
+	// EditCache edits a cache in configuration. One of version or transactionID is mandatory.
+	// Returns error on fail, nil on success.
+	EditCache(name string, data *configuration.Cache, transactionID string, version int64) error
+	// EnableCache attaches the cache to the frontend or backend with filter cache, http-request
+	// cache-use and http-response cache-store, in one change. The rules are appended after the
+	// existing ones. One of version or transactionID is mandatory. Returns error on fail, nil on
+	// success.
+	EnableCache(parentType string, parentName string, cache string, transactionID string, version int64) error
+	// DisableCache removes the filter cache, http-request cache-use and http-response cache-store of
+	// the cache from the frontend or backend. One of version or transactionID is mandatory. Returns
+	// error on fail, nil on success.
+	DisableCache(parentType string, parentName string, cache string, transactionID string, version int64) error
+	// CaptureHeader makes the frontend capture the header in the given direction and returns the
+	// capture index to reference from log formats, for example %[capture.req.hdr(0)]. A header that
+	// is already captured keeps its index and gets the new length. One of version or transactionID
+	// is mandatory. Returns error on fail.
+	CaptureHeader(frontend string, direction string, headerName string, length int64, transactionID string, version int64) (int64, error)
+	// GetHeaderCaptures returns configuration version and an array of
+	// configured capture header lines in the specified frontend. Returns error on fail.
+	GetHeaderCaptures(frontend string, transactionID string) (int64, []*configuration.HeaderCapture, error)
+	// GetHeaderCapture returns configuration version and a requested capture header line
+	// in the specified frontend. Returns error on fail or if the capture does not exist.
+	GetHeaderCapture(index int64, frontend string, transactionID string) (int64, *configuration.HeaderCapture, error)
+	// CreateHeaderCapture inserts a capture header line at its index, a header can be captured once
+	// in each direction. The slot ids of the following captures of the direction increase. One of
+	// version or transactionID is mandatory. Returns error on fail, nil on success.
+	CreateHeaderCapture(frontend string, data *configuration.HeaderCapture, transactionID string, version int64) error
+	// EditHeaderCapture edits a capture header line in configuration. Changing the direction changes
+	// the slot ids of the following captures. One of version or transactionID is mandatory. Returns
+	// error on fail, nil on success.
+	EditHeaderCapture(index int64, frontend string, data *configuration.HeaderCapture, transactionID string, version int64) error
+	// DeleteHeaderCapture deletes a capture header line in configuration, the slot ids of the
+	// following captures of the direction decrease. One of version or transactionID is mandatory.
+	// Returns error on fail, nil on success.
+	DeleteHeaderCapture(index int64, frontend string, transactionID string, version int64) error
+	// GetDeclareCaptures returns configuration version and an array of
+	// configured declare captures in the specified frontend. Returns error on fail.
+	GetDeclareCaptures(frontend string, transactionID string) (int64, []*configuration.DeclareCapture, error)
+	// GetDeclareCapture returns configuration version and a requested declare capture
+	// in the specified frontend. Returns error on fail or if declare capture does not exist.
+	GetDeclareCapture(index int64, frontend string, transactionID string) (int64, *configuration.DeclareCapture, error)
+	// CreateDeclareCapture appends a declare capture to the frontend and returns the id of its slot.
+	// Declarations are always appended so the slots referenced by existing rules keep their id. One
+	// of version or transactionID is mandatory. Returns error on fail.
+	CreateDeclareCapture(frontend string, data *configuration.DeclareCapture, transactionID string, version int64) (int64, error)
+	// EditDeclareCapture edits a declare capture in configuration. Changing the direction changes the
+	// slot ids of the following declarations. One of version or transactionID is mandatory. Returns
+	// error on fail, nil on success.
+	EditDeclareCapture(index int64, frontend string, data *configuration.DeclareCapture, transactionID string, version int64) error
+	// DeleteDeclareCapture deletes a declare capture in configuration, the slot ids of the following
+	// declarations of the direction decrease. One of version or transactionID is mandatory. Returns
+	// error on fail, nil on success.
+	DeleteDeclareCapture(index int64, frontend string, transactionID string, version int64) error
+	// AnnotateTransaction sets the author and the reason of the changes made in the transaction,
+	// they are stored in the configuration with the commit time once the transaction is committed.
+	// Empty author and reason remove the annotation.
+	AnnotateTransaction(transactionID, author, reason string) error
+	// GetCommitInfo returns the annotation of the transaction, or of the last commit of the
+	// configuration if transactionID is empty
+	GetCommitInfo(transactionID string) (*configuration.CommitInfo, error)
+	// GetCompression returns configuration version and the compression settings of the defaults,
+	// frontend or backend section. Returns error on fail or if compression algo is not set.
+	GetCompression(parentType string, parentName string, transactionID string) (int64, *configuration.Compression, error)
+	// SetCompression replaces the compression settings of the defaults, frontend or backend section,
+	// nil data removes them. One of version or transactionID is mandatory. Returns error on fail,
+	// nil on success.
+	SetCompression(parentType string, parentName string, data *configuration.Compression, transactionID string, version int64) error
 	// Init initializes a Client
 	Init(options configuration.ClientParams) error
 	// GetParser returns a parser for given transaction, if transaction is "", it returns "master" parser
@@ -84,12 +197,90 @@ type IConfigurationClient interface {
 	InitTransactionParsers() error
 	// GetVersion returns configuration file version
 	GetVersion(transaction string) (int64, error)
+	// GetConnectionLimits returns configuration version and the connection limits of the backend and
+	// all of its servers. Returns error on fail.
+	GetConnectionLimits(backend string, transactionID string) (int64, *configuration.ConnectionLimits, error)
+	// SetConnectionLimits sets fullconn and timeout queue of the backend and the limits of the given
+	// servers, unset values are removed. Servers that are not given keep their limits. The limits
+	// resulting from the change are validated together. One of version or transactionID is
+	// mandatory. Returns error on fail, nil on success.
+	SetConnectionLimits(backend string, data *configuration.ConnectionLimits, transactionID string, version int64) error
+	// GetCORSPolicy returns configuration version and a requested CORS policy of the frontend.
+	// Returns error on fail or if policy does not exist.
+	GetCORSPolicy(name string, frontend string, transactionID string) (int64, *configuration.CORSPolicy, error)
+	// CreateCORSPolicy creates the origin acl, the request rule capturing the origin, the request
+	// rule answering preflight requests and the response rules setting the Access-Control-*
+	// headers in the frontend in one transaction. The preflight rule is evaluated after all other
+	// http-request rules of the frontend.
+	// One of version or transactionID is mandatory. Returns error on fail, nil on success.
+	CreateCORSPolicy(frontend string, data *configuration.CORSPolicy, transactionID string, version int64) error
+	// EditCORSPolicy replaces all rules of the CORS policy in the frontend in one transaction.
+	// One of version or transactionID is mandatory. Returns error on fail, nil on success.
+	EditCORSPolicy(name string, frontend string, data *configuration.CORSPolicy, transactionID string, version int64) error
+	// DeleteCORSPolicy deletes all rules of the CORS policy from the frontend in one transaction.
+	// One of version or transactionID is mandatory. Returns error on fail, nil on success.
+	DeleteCORSPolicy(name string, frontend string, transactionID string, version int64) error
+	// GetDefaultServer returns configuration version and the default-server settings of the defaults
+	// section or of a backend, an empty object when none are set. Settings of several default-server
+	// lines are merged. Returns error on fail.
+	GetDefaultServer(parentType string, parentName string, transactionID string) (int64, *models.DefaultServer, error)
+	// EditDefaultServer replaces the default-server settings of the defaults section or of a backend
+	// with a single default-server line, nil or empty settings remove it. One of version or
+	// transactionID is mandatory. Returns error on fail, nil on success.
+	EditDefaultServer(parentType string, parentName string, data *models.DefaultServer, transactionID string, version int64) error
 	// GetDefaultsConfiguration returns configuration version and a
 	// struct representing Defaults configuration
 	GetDefaultsConfiguration(transactionID string) (int64, *models.Defaults, error)
 	// PushDefaultsConfiguration pushes a Defaults config struct to global
 	// config gile
 	PushDefaultsConfiguration(data *models.Defaults, transactionID string, version int64) error
+	// GetNamedDefaultsSections returns configuration version and an array of configured named
+	// defaults sections. Returns error on fail.
+	GetNamedDefaultsSections(transactionID string) (int64, []*configuration.NamedDefaults, error)
+	// GetNamedDefaults returns configuration version and a requested named defaults section.
+	// Returns error on fail or if defaults section does not exist.
+	GetNamedDefaults(name string, transactionID string) (int64, *configuration.NamedDefaults, error)
+	// CreateNamedDefaults creates a named defaults section in configuration. It is written before
+	// the anonymous defaults section, sections inherit from it only when they name it.
+	// One of version or transactionID is mandatory. Returns error on fail, nil on success.
+	CreateNamedDefaults(data *configuration.NamedDefaults, transactionID string, version int64) error
+	// EditNamedDefaults edits a named defaults section in configuration, it can not be renamed.
+	// One of version or transactionID is mandatory. Returns error on fail, nil on success.
+	EditNamedDefaults(name string, data *configuration.NamedDefaults, transactionID string, version int64) error
+	// DeleteNamedDefaults deletes a named defaults section in configuration, sections inheriting
+	// from it have to be changed first. One of version or transactionID is mandatory.
+	// Returns error on fail, nil on success.
+	DeleteNamedDefaults(name string, transactionID string, version int64) error
+	// GetInheritedDefaults returns configuration version and the name of the defaults section a
+	// frontend, backend or listen section inherits from. An empty name is the anonymous defaults
+	// section. Returns error on fail or if the section does not exist.
+	GetInheritedDefaults(section parser.Section, name string, transactionID string) (int64, string, error)
+	// SetInheritedDefaults sets the named defaults section a frontend, backend or listen section
+	// inherits from, an empty defaults name makes it inherit from the anonymous defaults section.
+	// One of version or transactionID is mandatory. Returns error on fail, nil on success.
+	SetInheritedDefaults(section parser.Section, name string, defaults string, transactionID string, version int64) error
+	// GetEmailAlert returns configuration version and the email alert settings of the defaults or
+	// backend section. Returns error on fail or if email-alert mailers is not set.
+	GetEmailAlert(parentType string, parentName string, transactionID string) (int64, *configuration.EmailAlert, error)
+	// SetEmailAlert replaces the email alert settings of the defaults or backend section, nil data
+	// removes them. The mailers section has to exist. One of version or transactionID is mandatory.
+	// Returns error on fail, nil on success.
+	SetEmailAlert(parentType string, parentName string, data *configuration.EmailAlert, transactionID string, version int64) error
+	// GetErrorPages returns configuration version and an array of
+	// configured error pages in the specified parent. Returns error on fail.
+	GetErrorPages(parentType string, parentName string, transactionID string) (int64, []*configuration.ErrorPage, error)
+	// GetErrorPage returns configuration version and the error page of the code
+	// in the specified parent. Returns error on fail or if error page does not exist.
+	GetErrorPage(code int64, parentType string, parentName string, transactionID string) (int64, *configuration.ErrorPage, error)
+	// DeleteErrorPage deletes the error page of the code in configuration. One of version or
+	// transactionID is mandatory. Returns error on fail, nil on success.
+	DeleteErrorPage(code int64, parentType string, parentName string, transactionID string, version int64) error
+	// CreateErrorPage creates an error page in configuration. One of version or transactionID is
+	// mandatory. Returns error on fail, nil on success.
+	CreateErrorPage(parentType string, parentName string, data *configuration.ErrorPage, transactionID string, version int64) error
+	// EditErrorPage edits the error page of the code in configuration, the type can be changed. One
+	// of version or transactionID is mandatory. Returns error on fail, nil on success.
+	EditErrorPage(code int64, parentType string, parentName string, data *configuration.ErrorPage, transactionID string, version int64) error
 	// GetFilters returns configuration version and an array of
 	// configured filters in the specified parent. Returns error on fail.
 	GetFilters(parentType, parentName string, transactionID string) (int64, models.Filters, error)
@@ -105,6 +296,18 @@ type IConfigurationClient interface {
 	// EditFilter edits a filter in configuration. One of version or transactionID is
 	// mandatory. Returns error on fail, nil on success.
 	EditFilter(id int64, parentType string, parentName string, data *models.Filter, transactionID string, version int64) error
+	// ReorderFilters reorders the filters of the parent, order lists the indexes of all the
+	// filters in their new order. Filters are applied in declaration order, e.g. a cache filter
+	// has to be declared after compression. One of version or transactionID is mandatory.
+	// Returns error on fail, nil on success.
+	ReorderFilters(parentType string, parentName string, order []int64, transactionID string, version int64) error
+	// GetForwarded returns configuration version and the option forwarded of the defaults or backend
+	// section. Returns error on fail or if the option is not set.
+	GetForwarded(parentType string, parentName string, transactionID string) (int64, *configuration.Forwarded, error)
+	// SetForwarded sets the option forwarded of the defaults or backend section, nil data removes it.
+	// The option can not be set together with option forwardfor. One of version or transactionID is
+	// mandatory. Returns error on fail, nil on success.
+	SetForwarded(parentType string, parentName string, data *configuration.Forwarded, transactionID string, version int64) error
 	// GetFrontends returns configuration version and an array of
 	// configured frontends. Returns error on fail.
 	GetFrontends(transactionID string) (int64, models.Frontends, error)
@@ -120,12 +323,154 @@ type IConfigurationClient interface {
 	// CreateFrontend creates a frontend in configuration. One of version or transactionID is
 	// mandatory. Returns error on fail, nil on success.
 	CreateFrontend(data *models.Frontend, transactionID string, version int64) error
+	// GetFrontendLimits returns configuration version and the capacity limits of the frontend.
+	// Returns error on fail.
+	GetFrontendLimits(frontend string, transactionID string) (int64, *configuration.FrontendLimits, error)
+	// SetFrontendLimits sets maxconn, backlog and rate-limit sessions of the frontend, unset values
+	// are removed. One of version or transactionID is mandatory. Returns error on fail, nil on
+	// success.
+	SetFrontendLimits(frontend string, data *configuration.FrontendLimits, transactionID string, version int64) error
 	// GetGlobalConfiguration returns configuration version and a
 	// struct representing Global configuration
 	GetGlobalConfiguration(transactionID string) (int64, *models.Global, error)
 	// PushGlobalConfiguration pushes a Global config struct to global
 	// config gile
 	PushGlobalConfiguration(data *models.Global, transactionID string, version int64) error
+	// GetEnvDirectives returns configuration version and an array of the
+	// environment variable directives of the global section. Returns error on fail.
+	GetEnvDirectives(transactionID string) (int64, []*configuration.EnvDirective, error)
+	// CreateEnvDirective adds an environment variable directive to the global section. One of
+	// version or transactionID is mandatory. Returns error on fail, nil on success.
+	CreateEnvDirective(data *configuration.EnvDirective, transactionID string, version int64) error
+	// DeleteEnvDirective removes the variable from the environment variable directive of the global
+	// section. One of version or transactionID is mandatory. Returns error on fail, nil on success.
+	DeleteEnvDirective(directive string, name string, transactionID string, version int64) error
+	// GetGlobalTuning returns configuration version and the global keywords
+	// not covered by models.Global. Returns error on fail.
+	GetGlobalTuning(transactionID string) (int64, *configuration.GlobalTuning, error)
+	// PushGlobalTuning replaces the global keywords not covered by models.Global. One of version or
+	// transactionID is mandatory. Returns error on fail, nil on success.
+	PushGlobalTuning(data *configuration.GlobalTuning, transactionID string, version int64) error
+	// GetGroups returns configuration version and an array of
+	// configured groups in the specified userlist. Returns error on fail.
+	GetGroups(userlist string, transactionID string) (int64, []*configuration.Group, error)
+	// GetGroup returns configuration version and a requested group
+	// in the specified userlist. Returns error on fail or if group does not exist.
+	GetGroup(name string, userlist string, transactionID string) (int64, *configuration.Group, error)
+	// DeleteGroup deletes a group in configuration, the group is removed from the users listing it.
+	// One of version or transactionID is mandatory. Returns error on fail, nil on success.
+	DeleteGroup(name string, userlist string, transactionID string, version int64) error
+	// CreateGroup creates a group in configuration. The users of the group have to exist. One of
+	// version or transactionID is mandatory. Returns error on fail, nil on success.
+	CreateGroup(userlist string, data *configuration.Group, transactionID string, version int64) error
+	// EditGroup edits a group in configuration. The users of the group have to exist. One of version
+	// or transactionID is mandatory. Returns error on fail, nil on success.
+	EditGroup(name string, userlist string, data *configuration.Group, transactionID string, version int64) error
+	// GetHashBalanceFactor returns configuration version and the hash-balance-factor of the defaults
+	// or backend section, the percentage of the average load a server of a consistent hash may take.
+	// Returns error on fail or if hash-balance-factor is not set.
+	GetHashBalanceFactor(parentType string, parentName string, transactionID string) (int64, int64, error)
+	// SetHashBalanceFactor sets the hash-balance-factor of the defaults or backend section, nil
+	// removes it. The factor is 0, which disables bounded loads, or a percentage of at least 100; it
+	// only applies with hash-type consistent. One of version or transactionID is mandatory. Returns
+	// error on fail, nil on success.
+	SetHashBalanceFactor(parentType string, parentName string, factor *int64, transactionID string, version int64) error
+	// GetHTTPHealthCheck returns configuration version and the HTTP health check of the backend or
+	// defaults section. Returns error on fail or if option httpchk is not set.
+	GetHTTPHealthCheck(parentType string, parentName string, transactionID string) (int64, *configuration.HTTPHealthCheck, error)
+	// SetHTTPHealthCheck sets option httpchk without arguments and replaces the http-check send and
+	// expect rules of the backend or defaults section, nil data removes them. Requests set the legacy
+	// way in option httpchk are migrated to the http-check send rule. One of version or transactionID
+	// is mandatory. Returns error on fail, nil on success.
+	SetHTTPHealthCheck(parentType string, parentName string, data *configuration.HTTPHealthCheck, transactionID string, version int64) error
+	// GetHTTPCheckRules returns configuration version and an array of
+	// configured http-check rules in the specified parent. Returns error on fail.
+	GetHTTPCheckRules(parentType string, parentName string, transactionID string) (int64, []*configuration.HTTPCheckRule, error)
+	// GetHTTPCheckRule returns configuration version and a requested http-check rule
+	// in the specified parent. Returns error on fail or if http-check rule does not exist.
+	GetHTTPCheckRule(id int64, parentType string, parentName string, transactionID string) (int64, *configuration.HTTPCheckRule, error)
+	// DeleteHTTPCheckRule deletes an http-check rule in configuration. One of version or
+	// transactionID is mandatory. Returns error on fail, nil on success.
+	DeleteHTTPCheckRule(id int64, parentType string, parentName string, transactionID string, version int64) error
+	// CreateHTTPCheckRule creates an http-check rule in configuration at the index of the rule. One
+	// of version or transactionID is mandatory. Returns error on fail, nil on success.
+	CreateHTTPCheckRule(parentType string, parentName string, data *configuration.HTTPCheckRule, transactionID string, version int64) error
+	// EditHTTPCheckRule edits an http-check rule in configuration. One of version or transactionID
+	// is mandatory. Returns error on fail, nil on success.
+	EditHTTPCheckRule(id int64, parentType string, parentName string, data *configuration.HTTPCheckRule, transactionID string, version int64) error
+	// GetHTTPErrorRules returns configuration version and an array of
+	// configured http-error rules in the specified parent. Returns error on fail.
+	GetHTTPErrorRules(parentType string, parentName string, transactionID string) (int64, []*configuration.HTTPErrorRule, error)
+	// GetHTTPErrorRule returns configuration version and the http-error rule of the status
+	// in the specified parent. Returns error on fail or if http-error rule does not exist.
+	GetHTTPErrorRule(status int64, parentType string, parentName string, transactionID string) (int64, *configuration.HTTPErrorRule, error)
+	// DeleteHTTPErrorRule deletes the http-error rule of the status in configuration. One of version
+	// or transactionID is mandatory. Returns error on fail, nil on success.
+	DeleteHTTPErrorRule(status int64, parentType string, parentName string, transactionID string, version int64) error
+	// CreateHTTPErrorRule creates an http-error rule in configuration, a status can have a single
+	// rule. One of version or transactionID is mandatory. Returns error on fail, nil on success.
+	CreateHTTPErrorRule(parentType string, parentName string, data *configuration.HTTPErrorRule, transactionID string, version int64) error
+	// EditHTTPErrorRule edits the http-error rule of the status in configuration. One of version or
+	// transactionID is mandatory. Returns error on fail, nil on success.
+	EditHTTPErrorRule(status int64, parentType string, parentName string, data *configuration.HTTPErrorRule, transactionID string, version int64) error
+	// GetHTTPErrorsSections returns configuration version and an array of
+	// configured http-errors sections. Returns error on fail.
+	GetHTTPErrorsSections(transactionID string) (int64, []*configuration.HTTPErrorsSection, error)
+	// GetHTTPErrorsSection returns configuration version and a requested http-errors section.
+	// Returns error on fail or if http-errors section does not exist.
+	GetHTTPErrorsSection(name string, transactionID string) (int64, *configuration.HTTPErrorsSection, error)
+	// DeleteHTTPErrorsSection deletes an http-errors section in configuration. Sections imported with
+	// errorfiles can not be deleted. One of version or transactionID is mandatory. Returns error on
+	// fail, nil on success.
+	DeleteHTTPErrorsSection(name string, transactionID string, version int64) error
+	// CreateHTTPErrorsSection creates an http-errors section in configuration. One of version or
+	// transactionID is mandatory. Returns error on fail, nil on success.
+	CreateHTTPErrorsSection(data *configuration.HTTPErrorsSection, transactionID string, version int64) error
+	// EditHTTPErrorsSection replaces the error pages of an http-errors section in configuration. One
+	// of version or transactionID is mandatory. Returns error on fail, nil on success.
+	EditHTTPErrorsSection(name string, data *configuration.HTTPErrorsSection, transactionID string, version int64) error
+	// GetErrorfilesReferences returns configuration version and the http-errors sections imported
+	// by the defaults, frontend or backend section. Returns error on fail.
+	GetErrorfilesReferences(parentType string, parentName string, transactionID string) (int64, []*configuration.ErrorfilesReference, error)
+	// SetErrorfilesReference imports the error pages of the http-errors section into the defaults,
+	// frontend or backend section, replacing an import of the same section. Nil codes import all
+	// pages. One of version or transactionID is mandatory. Returns error on fail, nil on success.
+	SetErrorfilesReference(parentType string, parentName string, data *configuration.ErrorfilesReference, transactionID string, version int64) error
+	// DeleteErrorfilesReference removes the import of the http-errors section from the defaults,
+	// frontend or backend section. One of version or transactionID is mandatory. Returns error on
+	// fail, nil on success.
+	DeleteErrorfilesReference(parentType string, parentName string, httpErrors string, transactionID string, version int64) error
+	// GetHTTPRequestRawRules returns configuration version and the http-request rules of the
+	// parent kept as raw lines. Returns error on fail.
+	GetHTTPRequestRawRules(parentType, parentName string, transactionID string) (int64, []*configuration.HTTPRawRule, error)
+	// GetHTTPRequestRawRule returns configuration version and a requested http-request rule kept
+	// as raw line. Returns error on fail or if the rule does not exist.
+	GetHTTPRequestRawRule(id int64, parentType, parentName string, transactionID string) (int64, *configuration.HTTPRawRule, error)
+	// CreateHTTPRequestRawRule creates an http-request rule with a return, normalize-uri or
+	// wait-for-body action. One of version or transactionID is mandatory. Returns error on fail,
+	// nil on success.
+	CreateHTTPRequestRawRule(parentType string, parentName string, data *configuration.HTTPRawRule, transactionID string, version int64) error
+	// EditHTTPRequestRawRule replaces an http-request rule kept as raw line. One of version or
+	// transactionID is mandatory. Returns error on fail, nil on success.
+	EditHTTPRequestRawRule(id int64, parentType string, parentName string, data *configuration.HTTPRawRule, transactionID string, version int64) error
+	// DeleteHTTPRequestRawRule deletes an http-request rule kept as raw line. One of version or
+	// transactionID is mandatory. Returns error on fail, nil on success.
+	DeleteHTTPRequestRawRule(id int64, parentType string, parentName string, transactionID string, version int64) error
+	// GetHTTPResponseRawRules returns configuration version and the http-response rules of the
+	// parent kept as raw lines. Returns error on fail.
+	GetHTTPResponseRawRules(parentType, parentName string, transactionID string) (int64, []*configuration.HTTPRawRule, error)
+	// GetHTTPResponseRawRule returns configuration version and a requested http-response rule
+	// kept as raw line. Returns error on fail or if the rule does not exist.
+	GetHTTPResponseRawRule(id int64, parentType, parentName string, transactionID string) (int64, *configuration.HTTPRawRule, error)
+	// CreateHTTPResponseRawRule creates an http-response rule with a cache-store or wait-for-body
+	// action. One of version or transactionID is mandatory. Returns error on fail, nil on success.
+	CreateHTTPResponseRawRule(parentType string, parentName string, data *configuration.HTTPRawRule, transactionID string, version int64) error
+	// EditHTTPResponseRawRule replaces an http-response rule kept as raw line. One of version or
+	// transactionID is mandatory. Returns error on fail, nil on success.
+	EditHTTPResponseRawRule(id int64, parentType string, parentName string, data *configuration.HTTPRawRule, transactionID string, version int64) error
+	// DeleteHTTPResponseRawRule deletes an http-response rule kept as raw line. One of version or
+	// transactionID is mandatory. Returns error on fail, nil on success.
+	DeleteHTTPResponseRawRule(id int64, parentType string, parentName string, transactionID string, version int64) error
 	// GetHTTPRequestRules returns configuration version and an array of
 	// configured http request rules in the specified parent. Returns error on fail.
 	GetHTTPRequestRules(parentType, parentName string, transactionID string) (int64, models.HTTPRequestRules, error)
@@ -156,6 +501,47 @@ type IConfigurationClient interface {
 	// EditHTTPResponseRule edits a http response rule in configuration. One of version or transactionID is
 	// mandatory. Returns error on fail, nil on success.
 	EditHTTPResponseRule(id int64, parentType string, parentName string, data *models.HTTPResponseRule, transactionID string, version int64) error
+	// GetHTTPSRedirect returns configuration version and the HTTPS redirect options of the frontend.
+	// Returns error on fail or if HTTPS redirect is not enabled.
+	GetHTTPSRedirect(frontend string, transactionID string) (int64, *configuration.HTTPSRedirectOptions, error)
+	// EnableHTTPSRedirect creates the scheme redirect rule as the first http-request rule of the
+	// frontend, replacing any redirect rule created before so the frontend has exactly one.
+	// One of version or transactionID is mandatory. Returns error on fail, nil on success.
+	EnableHTTPSRedirect(frontend string, opts *configuration.HTTPSRedirectOptions, transactionID string, version int64) error
+	// DisableHTTPSRedirect deletes the HTTPS redirect rule from the frontend. One of version or
+	// transactionID is mandatory. Returns error on fail, nil on success.
+	DisableHTTPSRedirect(frontend string, transactionID string, version int64) error
+	// SetHAProxyVersion sets the version of the managed HAProxy, binds and servers using keywords
+	// it does not support are then rejected. Empty version disables the check.
+	SetHAProxyVersion(version string) error
+	// GetListens returns configuration version and an array of
+	// configured listen sections. Returns error on fail.
+	GetListens(transactionID string) (int64, []*configuration.Listen, error)
+	// GetListen returns configuration version and a requested listen section.
+	// Returns error on fail or if listen section does not exist.
+	GetListen(name string, transactionID string) (int64, *configuration.Listen, error)
+	// DeleteListen deletes a listen section in configuration. One of version or transactionID is
+	// mandatory. Returns error on fail, nil on success.
+	DeleteListen(name string, transactionID string, version int64) error
+	// CreateListen creates a listen section in configuration. One of version or transactionID is
+	// mandatory. Returns error on fail, nil on success.
+	CreateListen(data *configuration.Listen, transactionID string, version int64) error
+	// EditListen replaces the content of a listen section in configuration. One of version or
+	// transactionID is mandatory. Returns error on fail, nil on success.
+	EditListen(name string, data *configuration.Listen, transactionID string, version int64) error
+	// GetLogFormat returns configuration version and the value of the log-format, log-format-sd or
+	// error-log-format directive of the defaults or frontend section. Returns error on fail or if
+	// the directive is not set.
+	GetLogFormat(directive string, parentType string, parentName string, transactionID string) (int64, string, error)
+	// SetLogFormat sets the log-format, log-format-sd or error-log-format directive of the defaults or
+	// frontend section, an empty format removes the directive. One of version or transactionID is
+	// mandatory. Returns error on fail, nil on success.
+	SetLogFormat(directive string, parentType string, parentName string, format string, transactionID string, version int64) error
+	// ApplyJSONLogFormat sets the log-format of the defaults or frontend section to the JSON access
+	// log preset with the selected fields available in haproxyVersion. Applying it again after an
+	// HAProxy upgrade adds the fields the new version provides. One of version or transactionID is
+	// mandatory. Returns error on fail, nil on success.
+	ApplyJSONLogFormat(parentType string, parentName string, fields []string, haproxyVersion string, transactionID string, version int64) error
 	// GetLogTargets returns configuration version and an array of
 	// configured log targets in the specified parent. Returns error on fail.
 	GetLogTargets(parentType, parentName string, transactionID string) (int64, models.LogTargets, error)
@@ -171,6 +557,43 @@ type IConfigurationClient interface {
 	// EditLogTarget edits a log target in configuration. One of version or transactionID is
 	// mandatory. Returns error on fail, nil on success.
 	EditLogTarget(id int64, parentType string, parentName string, data *models.LogTarget, transactionID string, version int64) error
+	// GetGlobalLua returns configuration version and the Lua keywords of the global section.
+	// Returns error on fail.
+	GetGlobalLua(transactionID string) (int64, *configuration.GlobalLua, error)
+	// PushGlobalLua replaces the lua-prepend-path and lua-load-per-thread keywords of the global
+	// section. One of version or transactionID is mandatory. Returns error on fail, nil on success.
+	PushGlobalLua(data *configuration.GlobalLua, transactionID string, version int64) error
+	// GetMailerEntries returns configuration version and an array of
+	// configured mailers in the specified mailers section. Returns error on fail.
+	GetMailerEntries(mailersSection string, transactionID string) (int64, []*configuration.MailerEntry, error)
+	// GetMailerEntry returns configuration version and a requested mailer
+	// in the specified mailers section. Returns error on fail or if mailer does not exist.
+	GetMailerEntry(name string, mailersSection string, transactionID string) (int64, *configuration.MailerEntry, error)
+	// DeleteMailerEntry deletes a mailer in configuration. One of version or transactionID is
+	// mandatory. Returns error on fail, nil on success.
+	DeleteMailerEntry(name string, mailersSection string, transactionID string, version int64) error
+	// CreateMailerEntry creates a mailer in configuration. One of version or transactionID is
+	// mandatory. Returns error on fail, nil on success.
+	CreateMailerEntry(mailersSection string, data *configuration.MailerEntry, transactionID string, version int64) error
+	// EditMailerEntry edits a mailer in configuration. One of version or transactionID is
+	// mandatory. Returns error on fail, nil on success.
+	EditMailerEntry(name string, mailersSection string, data *configuration.MailerEntry, transactionID string, version int64) error
+	// GetMailersSections returns configuration version and an array of
+	// configured mailers sections. Returns error on fail.
+	GetMailersSections(transactionID string) (int64, []*configuration.MailersSection, error)
+	// GetMailersSection returns configuration version and a requested mailers section.
+	// Returns error on fail or if mailers section does not exist.
+	GetMailersSection(name string, transactionID string) (int64, *configuration.MailersSection, error)
+	// DeleteMailersSection deletes a mailers section in configuration. Mailers sections email alerts
+	// are sent through can not be deleted. One of version or transactionID is mandatory. Returns
+	// error on fail, nil on success.
+	DeleteMailersSection(name string, transactionID string, version int64) error
+	// CreateMailersSection creates a mailers section in configuration. One of version or
+	// transactionID is mandatory. Returns error on fail, nil on success.
+	CreateMailersSection(data *configuration.MailersSection, transactionID string, version int64) error
+	// EditMailersSection edits a mailers section in configuration. One of version or transactionID
+	// is mandatory. Returns error on fail, nil on success.
+	EditMailersSection(name string, data *configuration.MailersSection, transactionID string, version int64) error
 	// GetNameservers returns configuration version and an array of
 	// configured namservers in the specified resolvers section. Returns error on fail.
 	GetNameservers(resolverSection string, transactionID string) (int64, models.Nameservers, error)
@@ -207,18 +630,66 @@ type IConfigurationClient interface {
 	// GetPeerSection returns configuration version and a requested peer section.
 	// Returns error on fail or if peer section does not exist.
 	GetPeerSection(name string, transactionID string) (int64, *models.PeerSection, error)
-	// DeletePeerSection deletes a peerSection in configuration. One of version or transactionID is
-	// mandatory. Returns error on fail, nil on success.
+	// DeletePeerSection deletes a peerSection in configuration. Peer sections replicating stick tables
+	// can not be deleted. One of version or transactionID is mandatory. Returns error on fail, nil on
+	// success.
 	DeletePeerSection(name string, transactionID string, version int64) error
 	// CreatePeerSection creates a peerSection in configuration. One of version or transactionID is
 	// mandatory. Returns error on fail, nil on success.
 	CreatePeerSection(data *models.PeerSection, transactionID string, version int64) error
+	// GetPrograms returns configuration version and an array of
+	// configured programs. Returns error on fail.
+	GetPrograms(transactionID string) (int64, []*configuration.Program, error)
+	// GetProgram returns configuration version and a requested program.
+	// Returns error on fail or if program does not exist.
+	GetProgram(name string, transactionID string) (int64, *configuration.Program, error)
+	// DeleteProgram deletes a program in configuration. One of version or transactionID is
+	// mandatory. Returns error on fail, nil on success.
+	DeleteProgram(name string, transactionID string, version int64) error
+	// CreateProgram creates a program in configuration. One of version or transactionID is
+	// mandatory. Returns error on fail, nil on success.
+	CreateProgram(data *configuration.Program, transactionID string, version int64) error
+	// EditProgram edits a program in configuration. One of version or transactionID is mandatory.
+	// Returns error on fail, nil on success.
+	EditProgram(name string, data *configuration.Program, transactionID string, version int64) error
+	// GetProtectionRuleSets returns configuration version and an array of protection rule sets
+	// instantiated in the frontend, found by the reserved prefix of their acls and rate limit
+	// policies. Returns error on fail.
+	GetProtectionRuleSets(frontend string, transactionID string) (int64, configuration.ProtectionRuleSets, error)
+	// GetProtectionRuleSet returns configuration version and a requested protection rule set
+	// of the frontend. Returns error on fail or if rule set does not exist.
+	GetProtectionRuleSet(name string, frontend string, transactionID string) (int64, *configuration.ProtectionRuleSet, error)
+	// CreateProtectionRuleSet instantiates the protection rule set template in the frontend in
+	// one transaction. One of version or transactionID is mandatory. Returns error on fail, nil on success.
+	CreateProtectionRuleSet(frontend string, data *configuration.ProtectionRuleSet, transactionID string, version int64) error
+	// DeleteProtectionRuleSet removes all acls, rules and tables of the protection rule set from
+	// the frontend in one transaction. One of version or transactionID is mandatory. Returns error
+	// on fail, nil on success.
+	DeleteProtectionRuleSet(name string, frontend string, transactionID string, version int64) error
+	// GetRateLimitPolicies returns configuration version and an array of rate limit
+	// policies applied to the frontend. Returns error on fail.
+	GetRateLimitPolicies(frontend string, transactionID string) (int64, configuration.RateLimitPolicies, error)
+	// GetRateLimitPolicy returns configuration version and a requested rate limit policy
+	// applied to the frontend. Returns error on fail or if policy does not exist.
+	GetRateLimitPolicy(name string, frontend string, transactionID string) (int64, *configuration.RateLimitPolicy, error)
+	// CreateRateLimitPolicy creates the stick table backend, the track rule and the deny rule of
+	// a rate limit policy in one transaction. One of version or transactionID is mandatory.
+	// Returns error on fail, nil on success.
+	CreateRateLimitPolicy(frontend string, data *configuration.RateLimitPolicy, transactionID string, version int64) error
+	// DeleteRateLimitPolicy deletes the rules and the stick table backend of a rate limit policy
+	// in one transaction. One of version or transactionID is mandatory. Returns error on fail,
+	// nil on success.
+	DeleteRateLimitPolicy(name string, frontend string, transactionID string, version int64) error
 	// GetRawConfiguration returns configuration version and a
 	// string containing raw config file
 	GetRawConfiguration(transactionID string, version int64) (int64, string, error)
 	// PostRawConfiguration pushes given string to the config file if the version
 	// matches
 	PostRawConfiguration(config *string, version int64, skipVersionCheck bool, onlyValidate ...bool) error
+	// SetReloader sets the reload strategy run after every successful commit, nil disables reloads
+	SetReloader(r configuration.Reloader)
+	// LastReload returns the result of the last reload, nil if no reload was done
+	LastReload() *configuration.ReloadResult
 	// GetResolvers returns configuration version and an array of
 	// configured resolvers. Returns error on fail.
 	GetResolvers(transactionID string) (int64, models.Resolvers, error)
@@ -234,6 +705,54 @@ type IConfigurationClient interface {
 	// CreateResolver creates a resolver in configuration. One of version or transactionID is
 	// mandatory. Returns error on fail, nil on success.
 	CreateResolver(data *models.Resolver, transactionID string, version int64) error
+	// GetRetryOn returns configuration version and the retry-on events of the defaults or backend
+	// section. Returns error on fail or if retry-on is not set.
+	GetRetryOn(parentType string, parentName string, transactionID string) (int64, []string, error)
+	// SetRetryOn sets the retry-on events of the defaults or backend section, nil or empty events
+	// remove it. Events are checked against the HAProxy version set with SetHAProxyVersion. One of
+	// version or transactionID is mandatory. Returns error on fail, nil on success.
+	SetRetryOn(parentType string, parentName string, events []string, transactionID string, version int64) error
+	// GetRings returns configuration version and an array of
+	// configured rings. Returns error on fail.
+	GetRings(transactionID string) (int64, []*configuration.Ring, error)
+	// GetRing returns configuration version and a requested ring.
+	// Returns error on fail or if ring does not exist.
+	GetRing(name string, transactionID string) (int64, *configuration.Ring, error)
+	// DeleteRing deletes a ring in configuration. Rings logs are sent to can not be deleted. One of
+	// version or transactionID is mandatory. Returns error on fail, nil on success.
+	DeleteRing(name string, transactionID string, version int64) error
+	// CreateRing creates a ring in configuration. One of version or transactionID is mandatory.
+	// Returns error on fail, nil on success.
+	CreateRing(data *configuration.Ring, transactionID string, version int64) error
+	// EditRing replaces the settings and servers of a ring in configuration. One of version or
+	// transactionID is mandatory. Returns error on fail, nil on success.
+	EditRing(name string, data *configuration.Ring, transactionID string, version int64) error
+	// GetRuntimeAPIs returns configuration version and an array of
+	// configured stats sockets of the global section. Returns error on fail.
+	GetRuntimeAPIs(transactionID string) (int64, []*models.RuntimeAPI, error)
+	// GetRuntimeAPI returns configuration version and the stats socket
+	// listening on address. Returns error on fail or if it does not exist.
+	GetRuntimeAPI(address string, transactionID string) (int64, *models.RuntimeAPI, error)
+	// CreateRuntimeAPI adds a stats socket to the global section. One of version or
+	// transactionID is mandatory. Returns error on fail, nil on success.
+	CreateRuntimeAPI(data *models.RuntimeAPI, transactionID string, version int64) error
+	// EditRuntimeAPI replaces the stats socket listening on address. One of version or
+	// transactionID is mandatory. Returns error on fail, nil on success.
+	EditRuntimeAPI(address string, data *models.RuntimeAPI, transactionID string, version int64) error
+	// DeleteRuntimeAPI removes the stats socket listening on address. One of version or
+	// transactionID is mandatory. Returns error on fail, nil on success.
+	DeleteRuntimeAPI(address string, transactionID string, version int64) error
+	// GetSecurityHeaders returns configuration version and the security headers set on the frontend.
+	// Returns error on fail.
+	GetSecurityHeaders(frontend string, transactionID string) (int64, *configuration.SecurityHeaders, error)
+	// ApplySecurityHeaders replaces the managed security header rules of the frontend with the
+	// given ones in one transaction, applying the same headers twice results in the same
+	// configuration. set-header rules written by users for the same headers are kept. One of
+	// version or transactionID is mandatory. Returns error on fail, nil on success.
+	ApplySecurityHeaders(frontend string, data *configuration.SecurityHeaders, transactionID string, version int64) error
+	// DeleteSecurityHeaders removes all managed security header rules from the frontend.
+	// One of version or transactionID is mandatory. Returns error on fail, nil on success.
+	DeleteSecurityHeaders(frontend string, transactionID string, version int64) error
 	//NewService creates and returns a new Service instance.
 	//name indicates the name of the service and only one Service instance with the given name can be created.
 	NewService(name string, scaling configuration.ScalingParams) (*configuration.Service, error)
@@ -269,6 +788,21 @@ type IConfigurationClient interface {
 	// EditServerSwitchingRule edits a server switching rule in configuration. One of version or transactionID is
 	// mandatory. Returns error on fail, nil on success.
 	EditServerSwitchingRule(id int64, backend string, data *models.ServerSwitchingRule, transactionID string, version int64) error
+	// GetServerTemplates returns configuration version and an array of
+	// configured server templates in the specified backend. Returns error on fail.
+	GetServerTemplates(backend string, transactionID string) (int64, []*configuration.ServerTemplate, error)
+	// GetServerTemplate returns configuration version and a requested server template
+	// in the specified backend. Returns error on fail or if server template does not exist.
+	GetServerTemplate(prefix string, backend string, transactionID string) (int64, *configuration.ServerTemplate, error)
+	// DeleteServerTemplate deletes a server template in configuration. One of version or
+	// transactionID is mandatory. Returns error on fail, nil on success.
+	DeleteServerTemplate(prefix string, backend string, transactionID string, version int64) error
+	// CreateServerTemplate creates a server template in configuration. One of version or
+	// transactionID is mandatory. Returns error on fail, nil on success.
+	CreateServerTemplate(backend string, data *configuration.ServerTemplate, transactionID string, version int64) error
+	// EditServerTemplate edits a server template in configuration. One of version or transactionID
+	// is mandatory. Returns error on fail, nil on success.
+	EditServerTemplate(prefix string, backend string, data *configuration.ServerTemplate, transactionID string, version int64) error
 	// GetSites returns configuration version and an array of
 	// configured sites. Returns error on fail.
 	GetSites(transactionID string) (int64, models.Sites, error)
@@ -284,6 +818,86 @@ type IConfigurationClient interface {
 	// DeleteSite deletes a site in configuration. One of version or transactionID is
 	// mandatory. Returns error on fail, nil on success.
 	DeleteSite(name string, transactionID string, version int64) error
+	// GetSNIRoutes returns configuration version and an array of SNI routes of the frontend.
+	// Returns error on fail.
+	GetSNIRoutes(frontend string, transactionID string) (int64, configuration.SNIRoutes, error)
+	// GetSNIRoute returns configuration version and a requested SNI route of the frontend.
+	// Returns error on fail or if SNI route does not exist.
+	GetSNIRoute(name string, frontend string, transactionID string) (int64, *configuration.SNIRoute, error)
+	// CreateSNIRoute creates the server name acl and the backend switching rule of the SNI route in
+	// the frontend. Passthrough routes also get the tcp-request rules waiting for the client hello,
+	// shared by all passthrough routes of the frontend. One of version or transactionID is mandatory.
+	// Returns error on fail, nil on success.
+	CreateSNIRoute(frontend string, data *configuration.SNIRoute, transactionID string, version int64) error
+	// DeleteSNIRoute removes the acl and the backend switching rule of the SNI route from the
+	// frontend. The client hello inspection rules are removed with the last passthrough route.
+	// One of version or transactionID is mandatory. Returns error on fail, nil on success.
+	DeleteSNIRoute(name string, frontend string, transactionID string, version int64) error
+	// GetSource returns configuration version and the source of the defaults or backend section.
+	// Returns error on fail or if source is not set.
+	GetSource(parentType string, parentName string, transactionID string) (int64, *configuration.Source, error)
+	// SetSource sets the source of the defaults or backend section, nil data removes it. One of
+	// version or transactionID is mandatory. Returns error on fail, nil on success.
+	SetSource(parentType string, parentName string, data *configuration.Source, transactionID string, version int64) error
+	// GetSRVDiscovery returns configuration version and the SRV discovery server-template of the
+	// backend with the given prefix. Returns error on fail or if it does not exist.
+	GetSRVDiscovery(backend string, prefix string, transactionID string) (int64, *configuration.SRVDiscovery, error)
+	// ApplySRVDiscovery creates or replaces the SRV discovery server-template of the backend with the
+	// prefix of data. The resolvers section is created with the given nameservers when it does not
+	// exist. One of version or transactionID is mandatory. Returns error on fail, nil on success.
+	ApplySRVDiscovery(backend string, data *configuration.SRVDiscovery, transactionID string, version int64) error
+	// DeleteSRVDiscovery removes the SRV discovery server-template with the prefix from the backend,
+	// the resolvers section is kept. One of version or transactionID is mandatory. Returns error on
+	// fail, nil on success.
+	DeleteSRVDiscovery(backend string, prefix string, transactionID string, version int64) error
+	// GetStatsAuth returns configuration version and the stats page credentials of the frontend or
+	// backend. Returns error on fail or if the stats page is not protected.
+	GetStatsAuth(parentType string, parentName string, transactionID string) (int64, *configuration.StatsAuth, error)
+	// ApplyStatsAuth replaces the stats page credentials of the frontend or backend, keeping other
+	// stats settings. Plain text passwords of users kept in a userlist are hashed before they are
+	// stored. One of version or transactionID is mandatory. Returns error on fail, nil on success.
+	ApplyStatsAuth(parentType string, parentName string, data *configuration.StatsAuth, transactionID string, version int64) error
+	// DeleteStatsAuth removes the stats page credentials of the frontend or backend, keeping other
+	// stats settings. A userlist used by the credentials is kept. One of version or transactionID
+	// is mandatory. Returns error on fail, nil on success.
+	DeleteStatsAuth(parentType string, parentName string, transactionID string, version int64) error
+	// GetStatsPage returns configuration version and the stats page of the frontend or backend.
+	// Returns error on fail.
+	GetStatsPage(parentType string, parentName string, transactionID string) (int64, *configuration.StatsPage, error)
+	// SetStatsPage replaces the stats page of the frontend or backend, keeping the other stats
+	// settings such as maxconn or http-request rules. One of version or transactionID is mandatory.
+	// Returns error on fail, nil on success.
+	SetStatsPage(parentType string, parentName string, data *configuration.StatsPage, transactionID string, version int64) error
+	// GetStatsAdminRules returns configuration version and an array of
+	// the stats admin rules of the frontend or backend. Returns error on fail.
+	GetStatsAdminRules(parentType string, parentName string, transactionID string) (int64, []*configuration.StatsAdminRule, error)
+	// GetStatsAdminRule returns configuration version and the stats admin rule
+	// at index id. Returns error on fail or if the rule does not exist.
+	GetStatsAdminRule(id int64, parentType string, parentName string, transactionID string) (int64, *configuration.StatsAdminRule, error)
+	// CreateStatsAdminRule inserts a stats admin rule at its index. One of version or
+	// transactionID is mandatory. Returns error on fail, nil on success.
+	CreateStatsAdminRule(parentType string, parentName string, data *configuration.StatsAdminRule, transactionID string, version int64) error
+	// EditStatsAdminRule replaces the stats admin rule at index id. One of version or
+	// transactionID is mandatory. Returns error on fail, nil on success.
+	EditStatsAdminRule(id int64, parentType string, parentName string, data *configuration.StatsAdminRule, transactionID string, version int64) error
+	// DeleteStatsAdminRule deletes the stats admin rule at index id. One of version or
+	// transactionID is mandatory. Returns error on fail, nil on success.
+	DeleteStatsAdminRule(id int64, parentType string, parentName string, transactionID string, version int64) error
+	// GetStatsHTTPRequestRules returns configuration version and an array of
+	// the stats http-request rules of the backend. Returns error on fail.
+	GetStatsHTTPRequestRules(parentType string, parentName string, transactionID string) (int64, []*configuration.StatsHTTPRequestRule, error)
+	// GetStatsHTTPRequestRule returns configuration version and the stats http-request rule
+	// at index id. Returns error on fail or if the rule does not exist.
+	GetStatsHTTPRequestRule(id int64, parentType string, parentName string, transactionID string) (int64, *configuration.StatsHTTPRequestRule, error)
+	// CreateStatsHTTPRequestRule inserts a stats http-request rule at its index. One of version or
+	// transactionID is mandatory. Returns error on fail, nil on success.
+	CreateStatsHTTPRequestRule(parentType string, parentName string, data *configuration.StatsHTTPRequestRule, transactionID string, version int64) error
+	// EditStatsHTTPRequestRule replaces the stats http-request rule at index id. One of version or
+	// transactionID is mandatory. Returns error on fail, nil on success.
+	EditStatsHTTPRequestRule(id int64, parentType string, parentName string, data *configuration.StatsHTTPRequestRule, transactionID string, version int64) error
+	// DeleteStatsHTTPRequestRule deletes the stats http-request rule at index id. One of version or
+	// transactionID is mandatory. Returns error on fail, nil on success.
+	DeleteStatsHTTPRequestRule(id int64, parentType string, parentName string, transactionID string, version int64) error
 	// GetStickRules returns configuration version and an array of
 	// configured stick rules in the specified backend. Returns error on fail.
 	GetStickRules(backend string, transactionID string) (int64, models.StickRules, error)
@@ -299,6 +913,46 @@ type IConfigurationClient interface {
 	// EditStickRule edits a stick rule in configuration. One of version or transactionID is
 	// mandatory. Returns error on fail, nil on success.
 	EditStickRule(id int64, backend string, data *models.StickRule, transactionID string, version int64) error
+	// GetStickTableDefinition returns configuration version and the stick-table declared
+	// in the frontend or backend. Returns error on fail or if no stick-table is declared.
+	GetStickTableDefinition(parentType string, parentName string, transactionID string) (int64, *configuration.StickTableDefinition, error)
+	// SetStickTableDefinition declares the stick-table of the frontend or backend, replacing the
+	// current one. The peers section of the table has to exist. One of version or transactionID is
+	// mandatory. Returns error on fail, nil on success.
+	SetStickTableDefinition(parentType string, parentName string, data *configuration.StickTableDefinition, transactionID string, version int64) error
+	// DeleteStickTableDefinition removes the stick-table of the frontend or backend. One of version
+	// or transactionID is mandatory. Returns error on fail, nil on success.
+	DeleteStickTableDefinition(parentType string, parentName string, transactionID string, version int64) error
+	// GetPeerTables returns configuration version and an array of
+	// tables declared in the specified peers section. Returns error on fail.
+	GetPeerTables(peerSection string, transactionID string) (int64, []*configuration.StickTableDefinition, error)
+	// GetPeerTable returns configuration version and a requested table
+	// in the specified peers section. Returns error on fail or if table does not exist.
+	GetPeerTable(name string, peerSection string, transactionID string) (int64, *configuration.StickTableDefinition, error)
+	// DeletePeerTable deletes a table in the peers section. One of version or transactionID is
+	// mandatory. Returns error on fail, nil on success.
+	DeletePeerTable(name string, peerSection string, transactionID string, version int64) error
+	// CreatePeerTable declares a table in the peers section. One of version or transactionID is
+	// mandatory. Returns error on fail, nil on success.
+	CreatePeerTable(peerSection string, data *configuration.StickTableDefinition, transactionID string, version int64) error
+	// EditPeerTable edits a table in the peers section. One of version or transactionID is
+	// mandatory. Returns error on fail, nil on success.
+	EditPeerTable(name string, peerSection string, data *configuration.StickTableDefinition, transactionID string, version int64) error
+	// GetTCPCheckRules returns configuration version and an array of
+	// configured tcp-check rules in the specified parent. Returns error on fail.
+	GetTCPCheckRules(parentType string, parentName string, transactionID string) (int64, []*configuration.TCPCheckRule, error)
+	// GetTCPCheckRule returns configuration version and a requested tcp-check rule
+	// in the specified parent. Returns error on fail or if tcp-check rule does not exist.
+	GetTCPCheckRule(id int64, parentType string, parentName string, transactionID string) (int64, *configuration.TCPCheckRule, error)
+	// DeleteTCPCheckRule deletes a tcp-check rule in configuration. One of version or transactionID
+	// is mandatory. Returns error on fail, nil on success.
+	DeleteTCPCheckRule(id int64, parentType string, parentName string, transactionID string, version int64) error
+	// CreateTCPCheckRule creates a tcp-check rule in configuration at the index of the rule. One of
+	// version or transactionID is mandatory. Returns error on fail, nil on success.
+	CreateTCPCheckRule(parentType string, parentName string, data *configuration.TCPCheckRule, transactionID string, version int64) error
+	// EditTCPCheckRule edits a tcp-check rule in configuration. One of version or transactionID is
+	// mandatory. Returns error on fail, nil on success.
+	EditTCPCheckRule(id int64, parentType string, parentName string, data *configuration.TCPCheckRule, transactionID string, version int64) error
 	// GetTCPRequestRules returns configuration version and an array of
 	// configured TCP request rules in the specified parent. Returns error on fail.
 	GetTCPRequestRules(parentType, parentName string, transactionID string) (int64, models.TCPRequestRules, error)
@@ -329,16 +983,67 @@ type IConfigurationClient interface {
 	// EditTCPResponseRule edits a tcp response rule in configuration. One of version or transactionID is
 	// mandatory. Returns error on fail, nil on success.
 	EditTCPResponseRule(id int64, backend string, data *models.TCPResponseRule, transactionID string, version int64) error
+	// GetTimeouts returns configuration version and the timeouts of the defaults, frontend or backend
+	// section. Returns error on fail.
+	GetTimeouts(parentType string, parentName string, transactionID string) (int64, *configuration.Timeouts, error)
+	// SetTimeouts replaces the timeouts of the defaults, frontend or backend section, nil timeouts are
+	// removed. Timeouts keeping their value are left as written, with their unit. One of version or
+	// transactionID is mandatory. Returns error on fail, nil on success.
+	SetTimeouts(parentType string, parentName string, data *configuration.Timeouts, transactionID string, version int64) error
 	// GetTransactions returns an array of transactions
 	GetTransactions(status string) (*models.Transactions, error)
 	// GetTransaction returns transaction information by id
 	GetTransaction(id string) (*models.Transaction, error)
 	// StartTransaction starts a new empty lbctl transaction
 	StartTransaction(version int64) (*models.Transaction, error)
-	// CommitTransaction commits a transaction by id.
+	// StartTransactionContext starts a new empty transaction whose changes are made in ctx, the
+	// subject of ctx is passed to the authorizer
+	StartTransactionContext(ctx context.Context, version int64) (*models.Transaction, error)
+	// CommitTransaction commits a transaction by id. When the reload following the commit fails,
+	// the committed transaction is returned along with an ErrReloadFailed error, callers have to
+	// check the error code to tell it from a failed commit.
 	CommitTransaction(id string) (*models.Transaction, error)
 	// DeleteTransaction deletes a transaction by id.
 	DeleteTransaction(id string) error
+	// GetUsers returns configuration version and an array of
+	// configured users in the specified userlist. Returns error on fail.
+	GetUsers(userlist string, transactionID string) (int64, []*configuration.User, error)
+	// GetUser returns configuration version and a requested user
+	// in the specified userlist. Returns error on fail or if user does not exist.
+	GetUser(username string, userlist string, transactionID string) (int64, *configuration.User, error)
+	// DeleteUser deletes a user in configuration, the user is removed from the groups listing it.
+	// One of version or transactionID is mandatory. Returns error on fail, nil on success.
+	DeleteUser(username string, userlist string, transactionID string, version int64) error
+	// CreateUser creates a user in configuration, hashing a plain text password. The groups of the
+	// user have to exist. One of version or transactionID is mandatory. Returns error on fail, nil on
+	// success.
+	CreateUser(userlist string, data *configuration.User, transactionID string, version int64) error
+	// EditUser edits a user in configuration, hashing a plain text password. The groups of the user
+	// have to exist. One of version or transactionID is mandatory. Returns error on fail, nil on
+	// success.
+	EditUser(username string, userlist string, data *configuration.User, transactionID string, version int64) error
+	// GetUserlists returns configuration version and an array of
+	// configured userlists. Returns error on fail.
+	GetUserlists(transactionID string) (int64, []*configuration.Userlist, error)
+	// GetUserlist returns configuration version and a requested userlist.
+	// Returns error on fail or if userlist does not exist.
+	GetUserlist(name string, transactionID string) (int64, *configuration.Userlist, error)
+	// DeleteUserlist deletes a userlist in configuration. Userlists used for HTTP authentication can
+	// not be deleted. One of version or transactionID is mandatory. Returns error on fail, nil on
+	// success.
+	DeleteUserlist(name string, transactionID string, version int64) error
+	// CreateUserlist creates a userlist in configuration. One of version or transactionID is
+	// mandatory. Returns error on fail, nil on success.
+	CreateUserlist(data *configuration.Userlist, transactionID string, version int64) error
+	// SetPasswordHashMethod sets the method used to hash plain text passwords of userlist users,
+	// SHA-512 crypt by default
+	SetPasswordHashMethod(method misc.PasswordHashMethod) error
+	// VerifyUserPassword checks the password of a user of the userlist against its stored
+	// password hash, or its insecure password
+	VerifyUserPassword(userlist string, username string, password string, transactionID string) (bool, error)
 	// GetConfigurationVersion returns configuration version
 	GetConfigurationVersion(transactionID string) (int64, error)
+	// SetVersionStrategy sets how the version of the configuration file is tracked, nil sets the
+	// default CommentVersion
+	SetVersionStrategy(s configuration.VersionStrategy)
 }
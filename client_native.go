@@ -16,10 +16,16 @@
 package client_native
 
 import (
+	"io"
 	"log"
+	"sync"
+	"time"
 
 	"github.com/haproxytech/client-native/v2/configuration"
 	"github.com/haproxytech/client-native/v2/runtime"
+	"github.com/haproxytech/client-native/v2/spoe"
+	"github.com/haproxytech/client-native/v2/storage"
+	"github.com/haproxytech/models/v2"
 )
 
 // LogFunc - default log function is from the stdlib
@@ -62,11 +68,57 @@ func (c *HAProxyClient) Init(configurationClient *configuration.Client, runtimeC
 type IHAProxyClient interface {
 	GetConfiguration() IConfigurationClient
 	GetRuntime() IRuntimeClient
+	DecommissionBackend(name string, reloader configuration.Reloader, timeout time.Duration) error
+	CreateMapRouting(frontend string, data *MapRouting, transactionID string, version int64) error
+	GetMapRouting(mapName string, frontend string, transactionID string) (*MapRouting, error)
+	DeleteMapRouting(mapName string, frontend string, transactionID string, version int64) error
+	GetMapRoutingEntries(mapName string) (models.MapEntries, error)
+	AddMapRoutingEntry(mapName, key, backend string) error
+	EditMapRoutingEntry(mapName, key, backend string) error
+	DeleteMapRoutingEntry(mapName, key string) error
+	CreateBlocklist(frontend string, data *Blocklist, transactionID string, version int64) error
+	GetBlocklist(name string, frontend string, transactionID string) (*Blocklist, error)
+	DeleteBlocklist(name string, frontend string, transactionID string, version int64) error
+	AddBlocklistEntry(name, entry string) error
+	DeleteBlocklistEntry(name, entry string) error
+	DeployCertificate(name string, bundle string) (string, error)
+	DeployOCSPResponse(name string, response []byte) (string, error)
+	CreateTLSTicketKeys(name string) (string, error)
+	SetBindTLSTicketKeys(frontend string, bind string, name string, transactionID string, version int64) error
+	CreateCrtList(name string, entries []*storage.CrtListEntry) (string, error)
+	GetCrtList(name string) ([]*storage.CrtListEntry, error)
+	AddCrtListEntry(name string, index *int64, entry *storage.CrtListEntry) error
+	EditCrtListEntry(name string, index int64, entry *storage.CrtListEntry) error
+	DeleteCrtListEntry(name string, index int64) error
+	SetBindCrtList(frontend string, bind string, name string, transactionID string, version int64) error
+	UploadCAFile(name string, content string) (string, error)
+	UploadCRLFile(name string, content string) (string, error)
+	SetBindCAFile(frontend string, bind string, caFile string, crlFile string, transactionID string, version int64) error
+	SetServerCAFile(backend string, server string, caFile string, crlFile string, transactionID string, version int64) error
+	SetErrorPageFile(parentType string, parentName string, code int64, name string, transactionID string, version int64) error
+	RotateTLSTicketKeys(name string) error
+	GetSRVServers(backend string, prefix string) (models.RuntimeServers, error)
+	ExportSnapshot(w io.Writer) (*SnapshotManifest, error)
+	ImportSnapshot(r io.Reader) (*SnapshotManifest, error)
+	AttachSpoeFilter(parentType string, parentName string, name string, engine string, transactionID string, version int64) error
+	ApplyAndReload(transactionID string, reloader configuration.Reloader, masterSocket string, timeout time.Duration) error
+	DeleteTransaction(transactionID string) error
 }
 
 type HAProxyClient struct {
-	Configuration *configuration.Client
-	Runtime       *runtime.Client
+	Configuration        *configuration.Client
+	Runtime              *runtime.Client
+	MapStorage           storage.Storage
+	GeneralStorage       storage.Storage
+	SSLCertStorage       storage.SSLStorage
+	TLSTicketKeysStorage storage.Storage
+	CrtListStorage       storage.Storage
+	CAStorage            storage.Storage
+	Spoe                 *spoe.Client
+
+	transactionFilesMu sync.Mutex
+	// transactionFiles are storage files created for changes in a transaction, by transaction id
+	transactionFiles map[string][]transactionFile
 }
 
 func (c *HAProxyClient) GetConfiguration() IConfigurationClient {
@@ -0,0 +1,104 @@
+// Copyright 2019 HAProxy Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package client_native
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/haproxytech/client-native/v2/configuration"
+	"github.com/haproxytech/client-native/v2/storage"
+)
+
+const testConfig = `# _version=1
+global
+  daemon
+
+defaults
+  mode http
+  timeout client 5s
+  timeout server 5s
+  timeout connect 5s
+
+frontend web
+  bind :8080
+  default_backend app
+
+backend app
+  server app1 127.0.0.1:8081
+`
+
+// newTestClient returns a client with a configuration file in a temporary directory, removed
+// when the test ends
+func newTestClient(t *testing.T) *HAProxyClient {
+	return newTestClientConfig(t, testConfig)
+}
+
+// newTestClientConfig returns a client with the configuration in a temporary directory, removed
+// when the test ends
+func newTestClientConfig(t *testing.T, config string) *HAProxyClient {
+	dir := testDir(t)
+	file := filepath.Join(dir, "haproxy.cfg")
+	if err := ioutil.WriteFile(file, []byte(config), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cc := &configuration.Client{}
+	err := cc.Init(configuration.ClientParams{
+		ConfigurationFile:      file,
+		Haproxy:                "echo",
+		UseValidation:          true,
+		PersistentTransactions: true,
+		TransactionDir:         filepath.Join(dir, "transactions"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &HAProxyClient{Configuration: cc}
+}
+
+// testDir returns a temporary directory removed when the test ends
+func testDir(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "client-native")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	return dir
+}
+
+// testStorage returns a storage in a temporary directory removed when the test ends
+func testStorage(t *testing.T, fileType storage.FileType) storage.Storage {
+	s, err := storage.New(testDir(t), fileType)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+// testTransaction starts a transaction on the current configuration version
+func testTransaction(t *testing.T, c *HAProxyClient) string {
+	v, err := c.Configuration.GetVersion("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tr, err := c.Configuration.StartTransaction(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tr.ID
+}
@@ -0,0 +1,186 @@
+// Copyright 2019 HAProxy Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package client_native
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// ErrClusterAborted is the result of nodes where a cluster change was prepared but not applied
+// because it failed on other nodes
+var ErrClusterAborted = errors.New("aborted, change failed on other nodes")
+
+// ClusterNode is a member of a cluster of identically configured HAProxy nodes
+type ClusterNode struct {
+	Name   string
+	Client *HAProxyClient
+}
+
+// NodeResult is the result of an operation on a cluster node
+type NodeResult struct {
+	Node string
+	Err  error
+	// RolledBack is set when a committed change was reverted because it failed on other nodes
+	RolledBack bool
+}
+
+// ClusterResult is the result of an operation on all cluster nodes, in the order of the nodes
+type ClusterResult []*NodeResult
+
+// Failed returns the results of the nodes the operation failed on
+func (r ClusterResult) Failed() ClusterResult {
+	failed := ClusterResult{}
+	for _, n := range r {
+		if n.Err != nil {
+			failed = append(failed, n)
+		}
+	}
+	return failed
+}
+
+// Err returns an error listing the failed nodes, nil if the operation succeeded on all nodes
+func (r ClusterResult) Err() error {
+	failed := r.Failed()
+	if len(failed) == 0 {
+		return nil
+	}
+	msgs := make([]string, 0, len(failed))
+	for _, n := range failed {
+		msgs = append(msgs, fmt.Sprintf("%s: %s", n.Node, n.Err))
+	}
+	return fmt.Errorf("operation failed on %d of %d nodes: %s", len(failed), len(r), strings.Join(msgs, "; "))
+}
+
+// ClusterClient fans operations out to all nodes of a cluster
+type ClusterClient struct {
+	nodes []*ClusterNode
+	// Rollback reverts nodes where a configuration change was committed when the commit fails
+	// on other nodes
+	Rollback bool
+}
+
+// NewClusterClient returns a cluster client for the nodes, node names have to be unique
+func NewClusterClient(nodes ...*ClusterNode) (*ClusterClient, error) {
+	names := map[string]bool{}
+	for _, n := range nodes {
+		if n.Client == nil {
+			return nil, fmt.Errorf("node %s has no client", n.Name)
+		}
+		if names[n.Name] {
+			return nil, fmt.Errorf("duplicate node %s", n.Name)
+		}
+		names[n.Name] = true
+	}
+	return &ClusterClient{nodes: nodes}, nil
+}
+
+// Nodes returns the nodes of the cluster
+func (c *ClusterClient) Nodes() []*ClusterNode {
+	return c.nodes
+}
+
+// Do runs fn on all nodes in parallel and returns the result of each node
+func (c *ClusterClient) Do(fn func(node *ClusterNode) error) ClusterResult {
+	result := make(ClusterResult, len(c.nodes))
+	var wg sync.WaitGroup
+	for i, n := range c.nodes {
+		wg.Add(1)
+		go func(i int, n *ClusterNode) {
+			defer wg.Done()
+			result[i] = &NodeResult{Node: n.Name, Err: fn(n)}
+		}(i, n)
+	}
+	wg.Wait()
+	return result
+}
+
+// ApplyConfiguration runs fn in a new transaction on every node and commits the transactions
+// only if fn succeeded on all nodes, otherwise they are deleted and nodes where fn succeeded
+// report ErrClusterAborted. When a commit fails and Rollback is set, the configuration of nodes
+// that already committed is restored.
+func (c *ClusterClient) ApplyConfiguration(fn func(node *ClusterNode, transactionID string) error) ClusterResult {
+	transactions := make([]string, len(c.nodes))
+	backups := make([]string, len(c.nodes))
+
+	result := c.Do(func(n *ClusterNode) error {
+		i := c.index(n)
+		v, config, err := n.Client.Configuration.GetRawConfiguration("", 0)
+		if err != nil {
+			return err
+		}
+		backups[i] = config
+		t, err := n.Client.Configuration.StartTransaction(v)
+		if err != nil {
+			return err
+		}
+		transactions[i] = t.ID
+		return fn(n, t.ID)
+	})
+
+	if result.Err() != nil {
+		for i, n := range c.nodes {
+			if transactions[i] == "" {
+				continue
+			}
+			n.Client.Configuration.DeleteTransaction(transactions[i])
+			if result[i].Err == nil {
+				result[i].Err = ErrClusterAborted
+			}
+		}
+		return result
+	}
+
+	result = c.Do(func(n *ClusterNode) error {
+		// failed commits are cleaned up by the configuration client
+		_, err := n.Client.Configuration.CommitTransaction(transactions[c.index(n)])
+		return err
+	})
+
+	if result.Err() != nil && c.Rollback {
+		for i, n := range c.nodes {
+			if result[i].Err != nil {
+				continue
+			}
+			if err := restoreConfiguration(n.Client, backups[i]); err != nil {
+				result[i].Err = fmt.Errorf("rollback failed: %w", err)
+				continue
+			}
+			result[i].RolledBack = true
+		}
+	}
+	return result
+}
+
+func (c *ClusterClient) index(node *ClusterNode) int {
+	for i, n := range c.nodes {
+		if n == node {
+			return i
+		}
+	}
+	return -1
+}
+
+// restoreConfiguration pushes the raw configuration as a new version
+func restoreConfiguration(client *HAProxyClient, config string) error {
+	v, err := client.Configuration.GetVersion("")
+	if err != nil {
+		return err
+	}
+	return client.Configuration.PostRawConfiguration(&config, v, false)
+}
@@ -0,0 +1,139 @@
+// Copyright 2019 HAProxy Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package client_native
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/haproxytech/models/v2"
+)
+
+func newTestCluster(t *testing.T, names ...string) *ClusterClient {
+	nodes := []*ClusterNode{}
+	for _, name := range names {
+		nodes = append(nodes, &ClusterNode{Name: name, Client: newTestClient(t)})
+	}
+	c, err := NewClusterClient(nodes...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func createClusterBackend(node *ClusterNode, transactionID string) error {
+	return node.Client.Configuration.CreateBackend(&models.Backend{Name: "cluster"}, transactionID, 0)
+}
+
+// hasClusterBackend returns true if the committed configuration of the node has the backend
+func hasClusterBackend(node *ClusterNode) bool {
+	_, _, err := node.Client.Configuration.GetBackend("cluster", "")
+	return err == nil
+}
+
+// inProgressTransactions returns the number of transactions left open on the node
+func inProgressTransactions(t *testing.T, node *ClusterNode) int {
+	transactions, err := node.Client.Configuration.GetTransactions("in_progress")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return len(*transactions)
+}
+
+func TestNewClusterClient(t *testing.T) {
+	c := newTestClient(t)
+	if _, err := NewClusterClient(&ClusterNode{Name: "a", Client: c}, &ClusterNode{Name: "a", Client: c}); err == nil {
+		t.Error("cluster created with duplicate nodes")
+	}
+	if _, err := NewClusterClient(&ClusterNode{Name: "a"}); err == nil {
+		t.Error("cluster created with node without client")
+	}
+}
+
+func TestApplyConfiguration(t *testing.T) {
+	c := newTestCluster(t, "a", "b")
+	result := c.ApplyConfiguration(createClusterBackend)
+	if err := result.Err(); err != nil {
+		t.Fatal(err)
+	}
+	for _, n := range c.Nodes() {
+		if !hasClusterBackend(n) {
+			t.Errorf("change not committed on %s", n.Name)
+		}
+		if v, _ := n.Client.Configuration.GetVersion(""); v != 2 {
+			t.Errorf("version %d on %s, expected 2", v, n.Name)
+		}
+	}
+}
+
+func TestApplyConfigurationAbort(t *testing.T) {
+	c := newTestCluster(t, "a", "b", "c")
+	result := c.ApplyConfiguration(func(node *ClusterNode, transactionID string) error {
+		if node.Name == "b" {
+			return fmt.Errorf("failed")
+		}
+		return createClusterBackend(node, transactionID)
+	})
+	if result.Err() == nil {
+		t.Fatal("change applied although it failed on a node")
+	}
+	for i, n := range c.Nodes() {
+		if n.Name == "b" {
+			if result[i].Err == nil || errors.Is(result[i].Err, ErrClusterAborted) {
+				t.Errorf("result of b %v, expected its own error", result[i].Err)
+			}
+		} else if !errors.Is(result[i].Err, ErrClusterAborted) {
+			t.Errorf("result of %s %v, expected aborted", n.Name, result[i].Err)
+		}
+		if hasClusterBackend(n) {
+			t.Errorf("aborted change committed on %s", n.Name)
+		}
+		if inProgressTransactions(t, n) != 0 {
+			t.Errorf("transaction of aborted change left on %s", n.Name)
+		}
+	}
+	if len(result.Failed()) != 3 {
+		t.Errorf("%d failed nodes, expected 3", len(result.Failed()))
+	}
+}
+
+func TestApplyConfigurationRollback(t *testing.T) {
+	for _, rollback := range []bool{true, false} {
+		t.Run(fmt.Sprintf("rollback %t", rollback), func(t *testing.T) {
+			c := newTestCluster(t, "a", "b")
+			c.Rollback = rollback
+			a, b := c.Nodes()[0], c.Nodes()[1]
+			// the configuration check run by the commit fails on b
+			b.Client.Configuration.ValidateConfigurationFile = true
+			b.Client.Configuration.Haproxy = "false"
+
+			result := c.ApplyConfiguration(createClusterBackend)
+			if result[1].Err == nil {
+				t.Fatal("commit did not fail on b")
+			}
+			if hasClusterBackend(b) {
+				t.Error("failed commit applied on b")
+			}
+			if result[0].Err != nil {
+				t.Fatalf("result of a %v", result[0].Err)
+			}
+			if result[0].RolledBack != rollback || hasClusterBackend(a) == rollback {
+				t.Errorf("a rolled back %t, expected %t", result[0].RolledBack, rollback)
+			}
+		})
+	}
+}
@@ -17,6 +17,7 @@ package configuration
 
 import (
 	"strconv"
+	"strings"
 
 	strfmt "github.com/go-openapi/strfmt"
 	parser "github.com/haproxytech/config-parser/v3"
@@ -59,6 +60,17 @@ func (c *Client) GetACL(id int64, parentType, parentName string, transactionID s
 		return 0, nil, err
 	}
 
+	if parentType == "listen" {
+		acls, err := parseListenACLs(parentName, p)
+		if err != nil {
+			return v, nil, c.handleError(strconv.FormatInt(id, 10), parentType, parentName, "", false, err)
+		}
+		if id < 0 || id >= int64(len(acls)) {
+			return v, nil, c.handleError(strconv.FormatInt(id, 10), parentType, parentName, "", false, parser_errors.ErrFetch)
+		}
+		return v, acls[id], nil
+	}
+
 	var section parser.Section
 	if parentType == "backend" {
 		section = parser.Backends
@@ -80,11 +92,22 @@ func (c *Client) GetACL(id int64, parentType, parentName string, transactionID s
 // DeleteACL deletes a ACL line in configuration. One of version or transactionID is
 // mandatory. Returns error on fail, nil on success.
 func (c *Client) DeleteACL(id int64, parentType string, parentName string, transactionID string, version int64) error {
+	if err := c.authorize("DeleteACL", "acl", authorizedIndex(id), parentType, parentName, transactionID); err != nil {
+		return err
+	}
+
 	p, t, err := c.loadDataForChange(transactionID, version)
 	if err != nil {
 		return err
 	}
 
+	if parentType == "listen" {
+		if err := setRawRule(p, parser.Listen, parentName, "acl", int(id), nil, false); err != nil {
+			return c.handleError(strconv.FormatInt(id, 10), parentType, parentName, t, transactionID == "", err)
+		}
+		return c.saveData(p, t, transactionID == "")
+	}
+
 	var section parser.Section
 	if parentType == "backend" {
 		section = parser.Backends
@@ -105,6 +128,13 @@ func (c *Client) DeleteACL(id int64, parentType string, parentName string, trans
 // CreateACL creates a ACL line in configuration. One of version or transactionID is
 // mandatory. Returns error on fail, nil on success.
 func (c *Client) CreateACL(parentType string, parentName string, data *models.ACL, transactionID string, version int64) error {
+	if err := validateProtectionReservedName(data.ACLName); err != nil {
+		return err
+	}
+	return c.createACL("CreateACL", parentType, parentName, data, transactionID, version)
+}
+
+func (c *Client) createACL(operation string, parentType string, parentName string, data *models.ACL, transactionID string, version int64) error {
 	if c.UseValidation {
 		validationErr := data.Validate(strfmt.Default)
 		if validationErr != nil {
@@ -112,11 +142,23 @@ func (c *Client) CreateACL(parentType string, parentName string, data *models.AC
 		}
 	}
 
+	if err := c.authorize(operation, "acl", authorizedIndexP(data.Index), parentType, parentName, transactionID); err != nil {
+		return err
+	}
+
 	p, t, err := c.loadDataForChange(transactionID, version)
 	if err != nil {
 		return err
 	}
 
+	if parentType == "listen" {
+		line := serializeListenACL(*data)
+		if err := setRawRule(p, parser.Listen, parentName, "acl", int(*data.Index), &line, true); err != nil {
+			return c.handleError(strconv.FormatInt(*data.Index, 10), parentType, parentName, t, transactionID == "", err)
+		}
+		return c.saveData(p, t, transactionID == "")
+	}
+
 	var section parser.Section
 	if parentType == "backend" {
 		section = parser.Backends
@@ -137,17 +179,32 @@ func (c *Client) CreateACL(parentType string, parentName string, data *models.AC
 // EditACL edits a ACL line in configuration. One of version or transactionID is
 // mandatory. Returns error on fail, nil on success.
 func (c *Client) EditACL(id int64, parentType string, parentName string, data *models.ACL, transactionID string, version int64) error {
+	if err := validateProtectionReservedName(data.ACLName); err != nil {
+		return err
+	}
 	if c.UseValidation {
 		validationErr := data.Validate(strfmt.Default)
 		if validationErr != nil {
 			return NewConfError(ErrValidationError, validationErr.Error())
 		}
 	}
+	if err := c.authorize("EditACL", "acl", authorizedIndex(id), parentType, parentName, transactionID); err != nil {
+		return err
+	}
+
 	p, t, err := c.loadDataForChange(transactionID, version)
 	if err != nil {
 		return err
 	}
 
+	if parentType == "listen" {
+		line := serializeListenACL(*data)
+		if err := setRawRule(p, parser.Listen, parentName, "acl", int(id), &line, false); err != nil {
+			return c.handleError(strconv.FormatInt(id, 10), parentType, parentName, t, transactionID == "", err)
+		}
+		return c.saveData(p, t, transactionID == "")
+	}
+
 	var section parser.Section
 	if parentType == "backend" {
 		section = parser.Backends
@@ -170,6 +227,10 @@ func (c *Client) EditACL(id int64, parentType string, parentName string, data *m
 }
 
 func ParseACLs(t, pName string, p *parser.Parser) (models.Acls, error) {
+	if t == "listen" {
+		return parseListenACLs(pName, p)
+	}
+
 	section := parser.Global
 	if t == "frontend" {
 		section = parser.Frontends
@@ -213,3 +274,29 @@ func SerializeACL(f models.ACL) types.ACL {
 		Value:     f.Value,
 	}
 }
+
+// parseListenACLs returns the ACLs of a listen section, the directives of listen sections have no
+// parser and are kept as unprocessed lines
+func parseListenACLs(name string, p *parser.Parser) (models.Acls, error) {
+	acls := models.Acls{}
+	lines, err := getRawRules(p, parser.Listen, name, "acl")
+	if err != nil {
+		return nil, err
+	}
+	for _, l := range lines {
+		// keep malformed lines so indexes match the acl lines of the section
+		words := append(strings.Fields(l), "", "")
+		id := int64(len(acls))
+		acls = append(acls, &models.ACL{
+			Index:     &id,
+			ACLName:   words[1],
+			Criterion: words[2],
+			Value:     strings.TrimSpace(strings.Join(words[3:], " ")),
+		})
+	}
+	return acls, nil
+}
+
+func serializeListenACL(f models.ACL) string {
+	return rawDirectiveLine("acl "+f.ACLName+" "+f.Criterion, f.Value)
+}
@@ -202,3 +202,71 @@ func TestCreateEditDeleteACL(t *testing.T) {
 		version++
 	}
 }
+
+func TestListenACLs(t *testing.T) {
+	l := &Listen{
+		Name:  "acl_listen",
+		Mode:  "http",
+		Lines: []string{"balance roundrobin", "acl is_admin path_beg /admin", "http-request deny if is_admin"},
+	}
+	if err := client.CreateListen(l, "", version); err != nil {
+		t.Fatal(err.Error())
+	}
+	version++
+
+	_, acls, err := client.GetACLs("listen", "acl_listen", "")
+	if err != nil {
+		t.Error(err.Error())
+	}
+	if len(acls) != 1 || acls[0].ACLName != "is_admin" || acls[0].Criterion != "path_beg" || acls[0].Value != "/admin" {
+		t.Errorf("listen ACLs %v not as expected", acls)
+	}
+
+	id := int64(1)
+	acl := &models.ACL{Index: &id, ACLName: "is_api", Criterion: "path_beg", Value: "/api"}
+	if err := client.CreateACL("listen", "acl_listen", acl, "", version); err != nil {
+		t.Error(err.Error())
+	} else {
+		version++
+	}
+
+	_, listen, err := client.GetListen("acl_listen", "")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	expected := []string{"balance roundrobin", "acl is_admin path_beg /admin", "acl is_api path_beg /api", "http-request deny if is_admin"}
+	if !reflect.DeepEqual(listen.Lines, expected) {
+		t.Errorf("listen lines %v, expected %v", listen.Lines, expected)
+	}
+
+	acl.Value = "/v1"
+	if err := client.EditACL(1, "listen", "acl_listen", acl, "", version); err != nil {
+		t.Error(err.Error())
+	} else {
+		version++
+	}
+	_, a, err := client.GetACL(1, "listen", "acl_listen", "")
+	if err != nil {
+		t.Error(err.Error())
+	} else if a.Value != "/v1" || *a.Index != 1 {
+		t.Errorf("listen ACL %+v not as edited", a)
+	}
+
+	if err := client.DeleteACL(0, "listen", "acl_listen", "", version); err != nil {
+		t.Error(err.Error())
+	} else {
+		version++
+	}
+	if _, _, err := client.GetACL(1, "listen", "acl_listen", ""); err == nil {
+		t.Error("DeleteACL failed, listen ACL 1 still exists")
+	}
+	if err := client.DeleteACL(1, "listen", "acl_listen", "", version); err == nil {
+		t.Error("Should throw error, non existent listen ACL")
+	}
+
+	if err := client.DeleteListen("acl_listen", "", version); err != nil {
+		t.Error(err.Error())
+	} else {
+		version++
+	}
+}
@@ -0,0 +1,208 @@
+// Copyright 2019 HAProxy Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package configuration
+
+import (
+	"fmt"
+	"strings"
+
+	strfmt "github.com/go-openapi/strfmt"
+	"github.com/haproxytech/models/v2"
+)
+
+const acmeChallengeServer = "acme"
+
+var acmeChallengeCond = fmt.Sprintf("{ path_beg %s }", ACMEChallengePath)
+
+// ACMEChallenge routes ACME HTTP-01 challenge requests of a frontend to a dedicated
+// backend pointing to the ACME client answering the challenges
+type ACMEChallenge struct {
+	// Backend name of the dedicated backend
+	Backend string `json:"backend"`
+	// Address of the ACME client HTTP-01 listener
+	Address string `json:"address"`
+	// Port of the ACME client HTTP-01 listener
+	Port *int64 `json:"port"`
+}
+
+// Validate validates the ACME challenge routing
+func (a *ACMEChallenge) Validate(formats strfmt.Registry) error {
+	if a.Backend == "" || strings.ContainsAny(a.Backend, " \t") {
+		return fmt.Errorf("invalid backend name %s", a.Backend)
+	}
+	if a.Address == "" {
+		return fmt.Errorf("address is required")
+	}
+	if a.Port == nil || *a.Port < 1 || *a.Port > 65535 {
+		return fmt.Errorf("port must be between 1 and 65535")
+	}
+	return nil
+}
+
+// GetACMEChallenge returns configuration version and the ACME challenge routing of the frontend.
+// Returns error on fail or if challenge routing does not exist.
+func (c *Client) GetACMEChallenge(frontend string, transactionID string) (int64, *ACMEChallenge, error) {
+	v, rules, err := c.GetBackendSwitchingRules(frontend, transactionID)
+	if err != nil {
+		return 0, nil, err
+	}
+	for _, r := range rules {
+		if r.Cond != "if" || r.CondTest != acmeChallengeCond {
+			continue
+		}
+		a := &ACMEChallenge{Backend: r.Name}
+		if _, s, err := c.GetServer(acmeChallengeServer, r.Name, transactionID); err == nil {
+			a.Address = s.Address
+			a.Port = s.Port
+		}
+		return v, a, nil
+	}
+	return v, nil, NewConfError(ErrObjectDoesNotExist, fmt.Sprintf("ACME challenge routing does not exist in frontend %s", frontend))
+}
+
+// CreateACMEChallenge creates the challenge backend if it does not exist and routes challenge
+// requests of the frontend to it with the first use_backend rule. An HTTPS redirect enabled on
+// the frontend is updated to exclude challenge requests, since redirects are evaluated before
+// backend switching. One of version or transactionID is mandatory. Returns error on fail, nil on success.
+func (c *Client) CreateACMEChallenge(frontend string, data *ACMEChallenge, transactionID string, version int64) error {
+	if c.UseValidation {
+		validationErr := data.Validate(strfmt.Default)
+		if validationErr != nil {
+			return NewConfError(ErrValidationError, validationErr.Error())
+		}
+	}
+
+	if err := c.authorize("CreateACMEChallenge", "acme_challenge", "", "frontend", frontend, transactionID); err != nil {
+		return err
+	}
+
+	p, t, err := c.loadDataForChange(transactionID, version)
+	if err != nil {
+		return err
+	}
+
+	if _, a, _ := c.GetACMEChallenge(frontend, t); a != nil {
+		return c.handleError("", "frontend", frontend, t, transactionID == "",
+			NewConfError(ErrObjectAlreadyExists, fmt.Sprintf("ACME challenge routing already exists in frontend %s", frontend)))
+	}
+
+	if _, _, err := c.GetBackend(data.Backend, t); err != nil {
+		if err := c.CreateBackend(&models.Backend{Name: data.Backend, Mode: "http"}, t, 0); err != nil {
+			return c.handleError(data.Backend, "", "", t, transactionID == "", err)
+		}
+		server := &models.Server{
+			Name:    acmeChallengeServer,
+			Address: data.Address,
+			Port:    data.Port,
+		}
+		if err := c.CreateServer(data.Backend, server, t, 0); err != nil {
+			return c.handleError(acmeChallengeServer, "backend", data.Backend, t, transactionID == "", err)
+		}
+	}
+
+	index := int64(0)
+	rule := &models.BackendSwitchingRule{
+		Index:    &index,
+		Name:     data.Backend,
+		Cond:     "if",
+		CondTest: acmeChallengeCond,
+	}
+	if err := c.CreateBackendSwitchingRule(frontend, rule, t, 0); err != nil {
+		return c.handleError("", "frontend", frontend, t, transactionID == "", err)
+	}
+
+	if _, redirect, _ := c.GetHTTPSRedirect(frontend, t); redirect != nil && !redirect.ExcludeACME {
+		redirect.ExcludeACME = true
+		if err := c.EnableHTTPSRedirect(frontend, redirect, t, 0); err != nil {
+			return c.handleError("", "frontend", frontend, t, transactionID == "", err)
+		}
+	}
+
+	if err := c.saveData(p, t, transactionID == ""); err != nil {
+		return err
+	}
+	return nil
+}
+
+// DeleteACMEChallenge removes the challenge routing from the frontend and deletes the challenge
+// backend when no other frontend routes to it. One of version or transactionID is mandatory.
+// Returns error on fail, nil on success.
+func (c *Client) DeleteACMEChallenge(frontend string, transactionID string, version int64) error {
+	if err := c.authorize("DeleteACMEChallenge", "acme_challenge", "", "frontend", frontend, transactionID); err != nil {
+		return err
+	}
+
+	p, t, err := c.loadDataForChange(transactionID, version)
+	if err != nil {
+		return err
+	}
+
+	_, a, err := c.GetACMEChallenge(frontend, t)
+	if err != nil {
+		return c.handleError("", "frontend", frontend, t, transactionID == "", err)
+	}
+
+	_, rules, err := c.GetBackendSwitchingRules(frontend, t)
+	if err != nil {
+		return c.handleError("", "frontend", frontend, t, transactionID == "", err)
+	}
+	// delete from the end so the remaining indexes stay valid
+	for i := len(rules) - 1; i >= 0; i-- {
+		if rules[i].Cond == "if" && rules[i].CondTest == acmeChallengeCond {
+			if err := c.DeleteBackendSwitchingRule(*rules[i].Index, frontend, t, 0); err != nil {
+				return c.handleError("", "frontend", frontend, t, transactionID == "", err)
+			}
+		}
+	}
+
+	used, err := c.backendInUse(a.Backend, t)
+	if err != nil {
+		return c.handleError("", "frontend", frontend, t, transactionID == "", err)
+	}
+	if !used {
+		if err := c.DeleteBackend(a.Backend, t, 0); err != nil {
+			return c.handleError(a.Backend, "", "", t, transactionID == "", err)
+		}
+	}
+
+	if err := c.saveData(p, t, transactionID == ""); err != nil {
+		return err
+	}
+	return nil
+}
+
+// backendInUse returns true if any frontend routes to the backend
+func (c *Client) backendInUse(backend string, t string) (bool, error) {
+	_, frontends, err := c.GetFrontends(t)
+	if err != nil {
+		return false, err
+	}
+	for _, f := range frontends {
+		if f.DefaultBackend == backend {
+			return true, nil
+		}
+		_, rules, err := c.GetBackendSwitchingRules(f.Name, t)
+		if err != nil {
+			return false, err
+		}
+		for _, r := range rules {
+			if r.Name == backend {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
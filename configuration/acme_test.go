@@ -0,0 +1,104 @@
+// Copyright 2019 HAProxy Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package configuration
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestCreateDeleteACMEChallenge(t *testing.T) {
+	port := int64(8888)
+	a := &ACMEChallenge{
+		Backend: "acme_challenge",
+		Address: "127.0.0.1",
+		Port:    &port,
+	}
+
+	err := client.EnableHTTPSRedirect("test_2", &HTTPSRedirectOptions{}, "", version)
+	if err != nil {
+		t.Error(err.Error())
+	} else {
+		version++
+	}
+
+	err = client.CreateACMEChallenge("test_2", a, "", version)
+	if err != nil {
+		t.Error(err.Error())
+	} else {
+		version++
+	}
+
+	v, challenge, err := client.GetACMEChallenge("test_2", "")
+	if err != nil {
+		t.Error(err.Error())
+	}
+
+	if !reflect.DeepEqual(challenge, a) {
+		fmt.Printf("Created ACME challenge: %v\n", challenge)
+		fmt.Printf("Given ACME challenge: %v\n", a)
+		t.Error("Created ACME challenge not equal to given ACME challenge")
+	}
+
+	if v != version {
+		t.Errorf("Version %v returned, expected %v", v, version)
+	}
+
+	_, rule, err := client.GetBackendSwitchingRule(0, "test_2", "")
+	if err != nil {
+		t.Error(err.Error())
+	} else if rule.Name != "acme_challenge" {
+		t.Errorf("First use_backend not acme_challenge: %v", rule.Name)
+	}
+
+	_, redirect, err := client.GetHTTPSRedirect("test_2", "")
+	if err != nil {
+		t.Error(err.Error())
+	} else if !redirect.ExcludeACME {
+		t.Error("HTTPS redirect does not exclude ACME challenge requests")
+	}
+
+	err = client.CreateACMEChallenge("test_2", a, "", version)
+	if err == nil {
+		t.Error("Should throw error, ACME challenge routing already exists")
+		version++
+	}
+
+	err = client.DeleteACMEChallenge("test_2", "", version)
+	if err != nil {
+		t.Error(err.Error())
+	} else {
+		version++
+	}
+
+	_, _, err = client.GetACMEChallenge("test_2", "")
+	if err == nil {
+		t.Error("DeleteACMEChallenge failed, ACME challenge routing still exists")
+	}
+
+	_, _, err = client.GetBackend("acme_challenge", "")
+	if err == nil {
+		t.Error("DeleteACMEChallenge failed, unused backend acme_challenge still exists")
+	}
+
+	err = client.DisableHTTPSRedirect("test_2", "", version)
+	if err != nil {
+		t.Error(err.Error())
+	} else {
+		version++
+	}
+}
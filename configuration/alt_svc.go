@@ -0,0 +1,131 @@
+// Copyright 2019 HAProxy Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package configuration
+
+import (
+	"fmt"
+	"strings"
+
+	parser "github.com/haproxytech/config-parser/v3"
+	"github.com/haproxytech/models/v2"
+)
+
+// HTTP3AltSvc returns the alt-svc header value advertising HTTP/3 on the given port,
+// escaped to be used as an http-response set-header format
+func HTTP3AltSvc(port int64, maxAge int64) string {
+	return fmt.Sprintf(`h3=\":%d\";ma=%d`, port, maxAge)
+}
+
+// GetFrontendAltSvc returns configuration version and the alt-svc header format set by
+// the frontend http-response rules, empty string if the frontend does not set it.
+func (c *Client) GetFrontendAltSvc(frontend string, transactionID string) (int64, string, error) {
+	p, err := c.GetParser(transactionID)
+	if err != nil {
+		return 0, "", err
+	}
+
+	v, err := c.GetVersion(transactionID)
+	if err != nil {
+		return 0, "", err
+	}
+
+	if !c.checkSectionExists(parser.Frontends, frontend, p) {
+		return v, "", NewConfError(ErrParentDoesNotExist, fmt.Sprintf("Frontend %s does not exist", frontend))
+	}
+
+	rule, err := getAltSvcRule(frontend, p)
+	if err != nil {
+		return v, "", err
+	}
+	if rule == nil {
+		return v, "", nil
+	}
+	return v, rule.HdrFormat, nil
+}
+
+// SetFrontendAltSvc sets the alt-svc header on the frontend responses with an
+// http-response set-header rule, replacing the existing one. Nil value removes the rule.
+func (c *Client) SetFrontendAltSvc(frontend string, value *string, transactionID string, version int64) error {
+	if value != nil && (*value == "" || strings.ContainsAny(*value, " \t")) {
+		return NewConfError(ErrValidationError, "alt-svc value must be a non empty string without whitespace")
+	}
+
+	if err := c.authorize("SetFrontendAltSvc", "alt_svc", "", "frontend", frontend, transactionID); err != nil {
+		return err
+	}
+
+	p, t, err := c.loadDataForChange(transactionID, version)
+	if err != nil {
+		return err
+	}
+
+	if !c.checkSectionExists(parser.Frontends, frontend, p) {
+		e := NewConfError(ErrParentDoesNotExist, fmt.Sprintf("Frontend %s does not exist", frontend))
+		return c.handleError(frontend, "", "", t, transactionID == "", e)
+	}
+
+	rule, err := getAltSvcRule(frontend, p)
+	if err != nil {
+		return c.handleError("alt-svc", "frontend", frontend, t, transactionID == "", err)
+	}
+
+	switch {
+	case value == nil && rule != nil:
+		err = p.Delete(parser.Frontends, frontend, "http-response", int(*rule.Index))
+	case value != nil && rule != nil:
+		rule.HdrFormat = *value
+		err = setAltSvcRule(frontend, rule, p, true)
+	case value != nil:
+		rule = &models.HTTPResponseRule{
+			Type:      "set-header",
+			HdrName:   "alt-svc",
+			HdrFormat: *value,
+		}
+		err = setAltSvcRule(frontend, rule, p, false)
+	}
+	if err != nil {
+		return c.handleError("alt-svc", "frontend", frontend, t, transactionID == "", err)
+	}
+
+	if err := c.saveData(p, t, transactionID == ""); err != nil {
+		return err
+	}
+	return nil
+}
+
+func getAltSvcRule(frontend string, p *parser.Parser) (*models.HTTPResponseRule, error) {
+	rules, err := ParseHTTPResponseRules("frontend", frontend, p)
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range rules {
+		if r.Type == "set-header" && strings.EqualFold(r.HdrName, "alt-svc") {
+			return r, nil
+		}
+	}
+	return nil, nil
+}
+
+func setAltSvcRule(frontend string, rule *models.HTTPResponseRule, p *parser.Parser, replace bool) error {
+	s, err := SerializeHTTPResponseRule(*rule)
+	if err != nil {
+		return err
+	}
+	if replace {
+		return p.Set(parser.Frontends, frontend, "http-response", s, int(*rule.Index))
+	}
+	return p.Insert(parser.Frontends, frontend, "http-response", s, -1)
+}
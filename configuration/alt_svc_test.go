@@ -0,0 +1,82 @@
+// Copyright 2019 HAProxy Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package configuration
+
+import (
+	"testing"
+
+	"github.com/haproxytech/models/v2"
+)
+
+func TestQUICBindAltSvc(t *testing.T) {
+	port := int64(4443)
+	b := &models.Bind{
+		Name:           "quic",
+		Address:        "quic6@::",
+		Port:           &port,
+		Ssl:            true,
+		SslCertificate: "dummy.crt",
+		Alpn:           "h3",
+	}
+	err := client.CreateBind("test", b, "", version)
+	if err != nil {
+		t.Error(err.Error())
+	} else {
+		version++
+	}
+
+	_, bind, err := client.GetBind("quic", "test", "")
+	if err != nil {
+		t.Error(err.Error())
+	} else if bind.Address != "quic6@::" || bind.Port == nil || *bind.Port != port {
+		t.Errorf("Bind address %s not parsed as quic6@:: with port %d", bind.Address, port)
+	}
+
+	value := HTTP3AltSvc(443, 900)
+	err = client.SetFrontendAltSvc("test", &value, "", version)
+	if err != nil {
+		t.Error(err.Error())
+	} else {
+		version++
+	}
+
+	_, altSvc, err := client.GetFrontendAltSvc("test", "")
+	if err != nil {
+		t.Error(err.Error())
+	}
+	if altSvc != `h3=\":443\";ma=900` {
+		t.Errorf("%s: alt-svc not set", altSvc)
+	}
+
+	err = client.SetFrontendAltSvc("test", nil, "", version)
+	if err != nil {
+		t.Error(err.Error())
+	} else {
+		version++
+	}
+
+	_, altSvc, _ = client.GetFrontendAltSvc("test", "")
+	if altSvc != "" {
+		t.Errorf("%s: alt-svc not removed", altSvc)
+	}
+
+	err = client.DeleteBind("quic", "test", "", version)
+	if err != nil {
+		t.Error(err.Error())
+	} else {
+		version++
+	}
+}
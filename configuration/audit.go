@@ -0,0 +1,301 @@
+// Copyright 2019 HAProxy Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package configuration
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	parser "github.com/haproxytech/config-parser/v3"
+)
+
+// Audit entry kinds
+const (
+	AuditChange = "change"
+	AuditCommit = "commit"
+)
+
+// Audit object actions
+const (
+	AuditCreated  = "created"
+	AuditModified = "modified"
+	AuditDeleted  = "deleted"
+)
+
+// AuditEntry is a line of the audit log, written as json for every change saved to a
+// transaction and for every commit
+type AuditEntry struct {
+	Time time.Time `json:"time"`
+	Kind string    `json:"kind"`
+	// Operation is the client method called, e.g. CreateBackend
+	Operation   string `json:"operation"`
+	Transaction string `json:"transaction"`
+	// Version is the version the transaction started from for changes, and the resulting
+	// version of the configuration for commits
+	Version int64         `json:"version"`
+	Objects []AuditObject `json:"objects,omitempty"`
+	Error   string        `json:"error,omitempty"`
+}
+
+// AuditObject is a section touched by a change with the number of lines added and removed
+type AuditObject struct {
+	Type    string `json:"type"`
+	Name    string `json:"name,omitempty"`
+	Action  string `json:"action"`
+	Added   int    `json:"added"`
+	Removed int    `json:"removed"`
+}
+
+type auditLog struct {
+	mu   sync.Mutex
+	w    io.Writer
+	file *os.File
+	// snapshots hold the configuration of each transaction as of its last audited change
+	snapshots map[string]string
+}
+
+// SetAuditWriter enables the audit log written to w, nil disables it
+func (c *Client) SetAuditWriter(w io.Writer) {
+	c.setAuditWriter(w, nil)
+}
+
+// SetAuditFile enables the audit log appended to the file at path, empty path disables it
+func (c *Client) SetAuditFile(path string) error {
+	if path == "" {
+		c.setAuditWriter(nil, nil)
+		return nil
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return NewConfError(ErrGeneralError, err.Error())
+	}
+	c.setAuditWriter(f, f)
+	return nil
+}
+
+// setAuditWriter replaces the audit writer, closing the previous audit file
+func (c *Client) setAuditWriter(w io.Writer, f *os.File) {
+	c.audit.mu.Lock()
+	defer c.audit.mu.Unlock()
+	if c.audit.file != nil {
+		c.audit.file.Close()
+	}
+	c.audit.w = w
+	c.audit.file = f
+}
+
+// auditSnapshot keeps the configuration of the transaction before its first change
+func (c *Client) auditSnapshot(p *parser.Parser, transactionID string) {
+	c.audit.mu.Lock()
+	defer c.audit.mu.Unlock()
+	if c.audit.w == nil {
+		return
+	}
+	if c.audit.snapshots == nil {
+		c.audit.snapshots = map[string]string{}
+	}
+	if _, ok := c.audit.snapshots[transactionID]; !ok {
+		c.audit.snapshots[transactionID] = configString(p)
+	}
+}
+
+// auditChange logs the sections changed in the transaction since the last audited change
+func (c *Client) auditChange(p *parser.Parser, transactionID string) {
+	c.audit.mu.Lock()
+	defer c.audit.mu.Unlock()
+	if c.audit.w == nil {
+		return
+	}
+	if c.audit.snapshots == nil {
+		c.audit.snapshots = map[string]string{}
+	}
+	after := configString(p)
+	before, ok := c.audit.snapshots[transactionID]
+	if !ok {
+		before = after
+	}
+	c.audit.snapshots[transactionID] = after
+	if c.audit.w == nil {
+		return
+	}
+
+	entry := &AuditEntry{
+		Kind:        AuditChange,
+		Operation:   auditOperation(),
+		Transaction: transactionID,
+		Version:     commentVersion(p),
+		Objects:     diffSections(before, after),
+	}
+	c.writeAuditEntry(entry)
+}
+
+// auditCommit logs the commit of the transaction, err is the error returned by the commit
+func (c *Client) auditCommit(transactionID string, version int64, err error) {
+	c.audit.mu.Lock()
+	defer c.audit.mu.Unlock()
+	delete(c.audit.snapshots, transactionID)
+	if c.audit.w == nil {
+		return
+	}
+
+	entry := &AuditEntry{
+		Kind:        AuditCommit,
+		Operation:   auditOperation(),
+		Transaction: transactionID,
+		Version:     version,
+	}
+	if err != nil {
+		entry.Error = err.Error()
+	}
+	c.writeAuditEntry(entry)
+}
+
+// auditForget drops the snapshot of a transaction that is deleted
+func (c *Client) auditForget(transactionID string) {
+	c.audit.mu.Lock()
+	defer c.audit.mu.Unlock()
+	delete(c.audit.snapshots, transactionID)
+}
+
+// writeAuditEntry writes the entry as a json line, the caller holds the audit lock. The
+// audit log never fails the audited call.
+func (c *Client) writeAuditEntry(entry *AuditEntry) {
+	entry.Time = time.Now().UTC()
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+	c.audit.w.Write(append(data, '\n'))
+}
+
+// auditOperation returns the outermost exported client method on the call stack
+func auditOperation() string {
+	pc := make([]uintptr, 32)
+	n := runtime.Callers(3, pc)
+	frames := runtime.CallersFrames(pc[:n])
+	operation := ""
+	for {
+		frame, more := frames.Next()
+		i := strings.Index(frame.Function, "/configuration.(*Client).")
+		if i != -1 {
+			// closures are named Method.funcN
+			name := strings.SplitN(frame.Function[i+len("/configuration.(*Client)."):], ".", 2)[0]
+			if name != "" && strings.ToUpper(name[:1]) == name[:1] {
+				operation = name
+			}
+		}
+		if !more {
+			break
+		}
+	}
+	return operation
+}
+
+// diffSections compares the sections of two configurations line by line
+func diffSections(before, after string) []AuditObject {
+	b := splitSections(before)
+	a := splitSections(after)
+
+	keys := []string{}
+	for k := range b {
+		keys = append(keys, k)
+	}
+	for k := range a {
+		if _, ok := b[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	objects := []AuditObject{}
+	for _, k := range keys {
+		obj := AuditObject{}
+		parts := strings.SplitN(k, " ", 2)
+		obj.Type = parts[0]
+		if len(parts) > 1 {
+			obj.Name = parts[1]
+		}
+
+		bLines, inBefore := b[k]
+		aLines, inAfter := a[k]
+		switch {
+		case !inBefore:
+			obj.Action = AuditCreated
+		case !inAfter:
+			obj.Action = AuditDeleted
+		default:
+			obj.Action = AuditModified
+		}
+
+		counts := map[string]int{}
+		for _, l := range bLines {
+			counts[l]++
+		}
+		for _, l := range aLines {
+			counts[l]--
+		}
+		for _, n := range counts {
+			if n > 0 {
+				obj.Removed += n
+			} else {
+				obj.Added -= n
+			}
+		}
+		if obj.Action == AuditModified && obj.Added == 0 && obj.Removed == 0 {
+			continue
+		}
+		objects = append(objects, obj)
+	}
+	return objects
+}
+
+// splitSections returns the lines of each section of a serialized configuration by section
+// header, the header comments before the first section are left out
+func splitSections(config string) map[string][]string {
+	sections := map[string][]string{}
+	current := ""
+	for _, line := range strings.Split(config, "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		// comments of the next section
+		if line[0] == '#' {
+			continue
+		}
+		if line[0] != ' ' && line[0] != '\t' {
+			// the defaults section a section inherits from is one of its lines
+			fields := strings.Fields(line)
+			name, from := sectionHeader(fields)
+			current = strings.TrimSpace(fields[0] + " " + name)
+			sections[current] = []string{}
+			if from != "" {
+				sections[current] = append(sections[current], "from "+from)
+			}
+			continue
+		}
+		if current == "" {
+			continue
+		}
+		sections[current] = append(sections[current], strings.TrimSpace(line))
+	}
+	return sections
+}
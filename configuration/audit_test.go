@@ -0,0 +1,74 @@
+// Copyright 2019 HAProxy Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package configuration
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestAuditLog(t *testing.T) {
+	buf := &bytes.Buffer{}
+	client.SetAuditWriter(buf)
+	defer client.SetAuditWriter(nil)
+
+	err := client.SetLogFormat(LogFormatDirective, "frontend", "test_2", `"%ci"`, "", version)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	version++
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("%d audit entries written, expected 2: %s", len(lines), buf.String())
+	}
+
+	change := &AuditEntry{}
+	if err := json.Unmarshal([]byte(lines[0]), change); err != nil {
+		t.Fatal(err.Error())
+	}
+	if change.Kind != AuditChange || change.Operation != "SetLogFormat" || change.Version != version-1 {
+		t.Errorf("Unexpected change entry: %s", lines[0])
+	}
+	if len(change.Objects) != 1 {
+		t.Fatalf("Unexpected change entry objects: %s", lines[0])
+	}
+	obj := change.Objects[0]
+	if obj.Type != "frontend" || obj.Name != "test_2" || obj.Action != AuditModified || obj.Added != 1 || obj.Removed != 0 {
+		t.Errorf("Unexpected change entry object: %+v", obj)
+	}
+
+	commit := &AuditEntry{}
+	if err := json.Unmarshal([]byte(lines[1]), commit); err != nil {
+		t.Fatal(err.Error())
+	}
+	if commit.Kind != AuditCommit || commit.Transaction != change.Transaction || commit.Version != version || commit.Error != "" {
+		t.Errorf("Unexpected commit entry: %s", lines[1])
+	}
+
+	buf.Reset()
+	client.SetAuditWriter(nil)
+	err = client.SetLogFormat(LogFormatDirective, "frontend", "test_2", "", "", version)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	version++
+	if buf.Len() != 0 {
+		t.Errorf("Audit entries written while disabled: %s", buf.String())
+	}
+}
@@ -0,0 +1,165 @@
+// Copyright 2019 HAProxy Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package configuration
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+
+	parser "github.com/haproxytech/config-parser/v3"
+)
+
+// Authorizer decides whether subject may perform operation on an object. The operation is the
+// name of the client method making the change, like CreateServer. The object is the
+// configuration object changed, objectType is its type (frontend, server, bind, acl...) and
+// objectName its name, or its index for rules, empty for objects that are unique in their
+// parent like global or timeouts. Objects inside a section have the type and name of the
+// section as parentType and parentName, which are empty for sections.
+type Authorizer interface {
+	Authorize(subject, operation, objectType, objectName, parentType, parentName string) bool
+}
+
+type subjectKey struct{}
+
+// ContextWithSubject returns a context carrying the subject passed to the authorizer
+func ContextWithSubject(ctx context.Context, subject string) context.Context {
+	return context.WithValue(ctx, subjectKey{}, subject)
+}
+
+// SubjectFromContext returns the subject carried by ctx, empty if none
+func SubjectFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	subject, _ := ctx.Value(subjectKey{}).(string)
+	return subject
+}
+
+type authorization struct {
+	mu         sync.Mutex
+	authorizer Authorizer
+	contexts   map[string]context.Context
+}
+
+// SetAuthorizer sets the authorizer checking every change before it is made, nil disables
+// authorization. The subject of a change is carried by the context of its transaction, see
+// StartTransactionContext and SetContext. Changes made with a version have no transaction
+// and are authorized with an empty subject.
+func (c *Client) SetAuthorizer(a Authorizer) {
+	c.authz.mu.Lock()
+	defer c.authz.mu.Unlock()
+	c.authz.authorizer = a
+}
+
+// SetContext sets the context of the changes made in the transaction. The subject of the
+// context is passed to the authorizer. Returns error if the transaction does not exist.
+func (c *Client) SetContext(transactionID string, ctx context.Context) error {
+	if transactionID == "" {
+		return NewConfError(ErrTransactionDoesNotExist, "context can only be set on a transaction")
+	}
+	if _, err := c.GetParser(transactionID); err != nil {
+		return err
+	}
+	c.authz.mu.Lock()
+	defer c.authz.mu.Unlock()
+	if c.authz.contexts == nil {
+		c.authz.contexts = map[string]context.Context{}
+	}
+	c.authz.contexts[transactionID] = ctx
+	return nil
+}
+
+// authorize checks that the subject of the transaction context may perform the operation on
+// the object, it is called by every change before the configuration is touched with the name
+// of the client method as operation. Returns ErrNotAuthorized if the change is denied.
+func (c *Client) authorize(operation, objectType, objectName, parentType, parentName, transactionID string) error {
+	c.authz.mu.Lock()
+	authorizer := c.authz.authorizer
+	ctx := c.authz.contexts[transactionID]
+	c.authz.mu.Unlock()
+	if authorizer == nil {
+		return nil
+	}
+
+	subject := SubjectFromContext(ctx)
+	if authorizer.Authorize(subject, operation, objectType, objectName, parentType, parentName) {
+		return nil
+	}
+	object := strings.TrimSpace(objectType + " " + objectName)
+	if parentType != "" {
+		object = strings.TrimSpace(fmt.Sprintf("%s in %s %s", object, parentType, parentName))
+	}
+	return NewConfError(ErrNotAuthorized, fmt.Sprintf("%s is not allowed to %s %s", subject, operation, object))
+}
+
+// authorizeRaw checks every section the raw configuration changes compared to the
+// configuration of the transaction, or the configuration file for an empty transactionID
+func (c *Client) authorizeRaw(operation string, config string, transactionID string) error {
+	c.authz.mu.Lock()
+	authorizer := c.authz.authorizer
+	c.authz.mu.Unlock()
+	if authorizer == nil {
+		return nil
+	}
+
+	p, err := c.GetParser(transactionID)
+	if err != nil {
+		return err
+	}
+	after := &parser.Parser{
+		Options: parser.Options{
+			UseV2HTTPCheck: true,
+		},
+	}
+	if err := parseConfig(after, config); err != nil {
+		return NewConfError(ErrErrorChangingConfig, err.Error())
+	}
+	defer forgetDefaultsSections(after)
+	for _, obj := range diffSections(configString(p), configString(after)) {
+		if err := c.authorize(operation, obj.Type, obj.Name, "", "", transactionID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func authorizedSectionName(section parser.Section, name string) string {
+	if section == parser.Global || section == parser.Defaults {
+		return ""
+	}
+	return name
+}
+
+func authorizedIndex(id int64) string {
+	return strconv.FormatInt(id, 10)
+}
+
+func authorizedIndexP(id *int64) string {
+	if id == nil {
+		return ""
+	}
+	return authorizedIndex(*id)
+}
+
+// forgetContext drops the context of a transaction that is committed or deleted
+func (c *Client) forgetContext(transactionID string) {
+	c.authz.mu.Lock()
+	defer c.authz.mu.Unlock()
+	delete(c.authz.contexts, transactionID)
+}
@@ -0,0 +1,134 @@
+// Copyright 2019 HAProxy Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package configuration
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/haproxytech/models/v2"
+)
+
+// testAuthorizer allows subjects to change only the frontends they own and the objects in them
+type testAuthorizer struct {
+	owners map[string]string
+	calls  []string
+}
+
+func (a *testAuthorizer) Authorize(subject, operation, objectType, objectName, parentType, parentName string) bool {
+	a.calls = append(a.calls, strings.Join([]string{subject, operation, objectType, objectName, parentType, parentName}, " "))
+	if parentType == "" {
+		return objectType == "frontend" && a.owners[objectName] == subject
+	}
+	return parentType == "frontend" && a.owners[parentName] == subject
+}
+
+func TestAuthorizer(t *testing.T) {
+	a := &testAuthorizer{owners: map[string]string{"test_2": "alice"}}
+	client.SetAuthorizer(a)
+	defer client.SetAuthorizer(nil)
+	if err := client.SetContext("", ContextWithSubject(context.Background(), "alice")); err == nil {
+		t.Error("Should throw error, context can only be set on a transaction")
+	}
+
+	tr, err := client.StartTransactionContext(ContextWithSubject(context.Background(), "alice"), version)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	err = client.SetLogFormat(LogFormatDirective, "frontend", "test", `"%ci"`, tr.ID, 0)
+	if err == nil {
+		t.Error("Should throw error, frontend test not owned by alice")
+	} else if confErr, ok := err.(*ConfError); !ok || confErr.Code() != ErrNotAuthorized {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	if len(a.calls) != 1 || a.calls[0] != "alice SetLogFormat log_format log-format frontend test" {
+		t.Errorf("Unexpected authorizer calls: %v", a.calls)
+	}
+
+	// objects are passed to the authorizer, not only the section holding them
+	a.calls = nil
+	err = client.CreateServer("test", &models.Server{Name: "authz", Address: "127.0.0.1"}, tr.ID, 0)
+	if err == nil {
+		t.Error("Should throw error, backend test not owned by alice")
+	}
+	if len(a.calls) != 1 || a.calls[0] != "alice CreateServer server authz backend test" {
+		t.Errorf("Unexpected authorizer calls: %v", a.calls)
+	}
+
+	// the operation is the method called, not the methods it uses
+	a.calls = nil
+	rs := &ProtectionRuleSet{Name: "authz", Paths: []string{"/.git"}}
+	if err := client.CreateProtectionRuleSet("test_2", rs, tr.ID, 0); err != nil {
+		t.Error(err.Error())
+	}
+	if len(a.calls) == 0 || a.calls[0] != "alice CreateProtectionRuleSet protection_rule_set authz frontend test_2" {
+		t.Errorf("Unexpected authorizer calls: %v", a.calls)
+	}
+	if err := client.DeleteTransaction(tr.ID); err != nil {
+		t.Error(err.Error())
+	}
+
+	// contexts belong to their transaction only
+	tr, err = client.StartTransaction(version)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if err := client.SetContext(tr.ID, ContextWithSubject(context.Background(), "bob")); err != nil {
+		t.Fatal(err.Error())
+	}
+	if err := client.SetLogFormat(LogFormatDirective, "frontend", "test_2", `"%ci"`, tr.ID, 0); err == nil {
+		t.Error("Should throw error, frontend test_2 not owned by bob")
+	}
+	if _, _, err := client.GetLogFormat(LogFormatDirective, "frontend", "test_2", tr.ID); err == nil {
+		t.Error("Denied change applied")
+	}
+	if err := client.DeleteTransaction(tr.ID); err != nil {
+		t.Error(err.Error())
+	}
+
+	// changes made with a version have no subject
+	a.calls = nil
+	err = client.SetLogFormat(LogFormatDirective, "frontend", "test_2", `"%ci"`, "", version)
+	if err == nil {
+		t.Error("Should throw error, frontend test_2 not owned by an empty subject")
+		version++
+	}
+	if len(a.calls) != 1 || a.calls[0] != " SetLogFormat log_format log-format frontend test_2" {
+		t.Errorf("Unexpected authorizer calls: %v", a.calls)
+	}
+
+	// raw configuration is authorized by changed section
+	_, raw, err := client.GetRawConfiguration("", 0)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	raw += "\nbackend authz_raw\n  mode http\n"
+	a.calls = nil
+	if err := client.PostRawConfiguration(&raw, version, false); err == nil {
+		t.Error("Should throw error, backend authz_raw not owned")
+		version++
+	}
+	if len(a.calls) != 1 || a.calls[0] != " PostRawConfiguration backend authz_raw  " {
+		t.Errorf("Unexpected authorizer calls: %v", a.calls)
+	}
+	if _, _, err := client.GetBackend("authz_raw", ""); err == nil {
+		t.Error("Denied raw configuration applied")
+	}
+	if v, _ := client.GetVersion(""); v != version {
+		t.Errorf("Version %v returned, expected %v", v, version)
+	}
+}
@@ -82,7 +82,7 @@ func (c *Client) GetBackend(name string, transactionID string) (int64, *models.B
 // DeleteBackend deletes a backend in configuration. One of version or transactionID is
 // mandatory. Returns error on fail, nil on success.
 func (c *Client) DeleteBackend(name string, transactionID string, version int64) error {
-	if err := c.deleteSection(parser.Backends, name, transactionID, version); err != nil {
+	if err := c.deleteSection("DeleteBackend", parser.Backends, name, transactionID, version); err != nil {
 		return err
 	}
 	return nil
@@ -97,7 +97,7 @@ func (c *Client) CreateBackend(data *models.Backend, transactionID string, versi
 			return NewConfError(ErrValidationError, validationErr.Error())
 		}
 	}
-	if err := c.createSection(parser.Backends, data.Name, data, transactionID, version); err != nil {
+	if err := c.createSection("CreateBackend", parser.Backends, data.Name, data, transactionID, version); err != nil {
 		return err
 	}
 	return nil
@@ -112,7 +112,7 @@ func (c *Client) EditBackend(name string, data *models.Backend, transactionID st
 			return NewConfError(ErrValidationError, validationErr.Error())
 		}
 	}
-	if err := c.editSection(parser.Backends, name, data, transactionID, version); err != nil {
+	if err := c.editSection("EditBackend", parser.Backends, name, data, transactionID, version); err != nil {
 		return err
 	}
 	return nil
@@ -74,6 +74,10 @@ func (c *Client) GetBackendSwitchingRule(id int64, frontend string, transactionI
 // DeleteBackendSwitchingRule deletes a backend switching rule in configuration. One of version or transactionID is
 // mandatory. Returns error on fail, nil on success.
 func (c *Client) DeleteBackendSwitchingRule(id int64, frontend string, transactionID string, version int64) error {
+	if err := c.authorize("DeleteBackendSwitchingRule", "backend_switching_rule", authorizedIndex(id), "frontend", frontend, transactionID); err != nil {
+		return err
+	}
+
 	p, t, err := c.loadDataForChange(transactionID, version)
 	if err != nil {
 		return err
@@ -99,6 +103,10 @@ func (c *Client) CreateBackendSwitchingRule(frontend string, data *models.Backen
 		}
 	}
 
+	if err := c.authorize("CreateBackendSwitchingRule", "backend_switching_rule", authorizedIndexP(data.Index), "frontend", frontend, transactionID); err != nil {
+		return err
+	}
+
 	p, t, err := c.loadDataForChange(transactionID, version)
 	if err != nil {
 		return err
@@ -124,6 +132,10 @@ func (c *Client) EditBackendSwitchingRule(id int64, frontend string, data *model
 			return NewConfError(ErrValidationError, validationErr.Error())
 		}
 	}
+	if err := c.authorize("EditBackendSwitchingRule", "backend_switching_rule", authorizedIndex(id), "frontend", frontend, transactionID); err != nil {
+		return err
+	}
+
 	p, t, err := c.loadDataForChange(transactionID, version)
 	if err != nil {
 		return err
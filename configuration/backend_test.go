@@ -489,3 +489,49 @@ func compareBackends(x, y *models.Backend, t *testing.T) bool {
 	}
 	return true
 }
+
+func TestBackendExternalCheckRequiresGlobal(t *testing.T) {
+	tr, err := client.StartTransaction(version)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	defer func() {
+		if err := client.DeleteTransaction(tr.ID); err != nil {
+			t.Error(err.Error())
+		}
+	}()
+
+	_, g, err := client.GetGlobalConfiguration(tr.ID)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	g.ExternalCheck = false
+	if err := client.PushGlobalConfiguration(g, tr.ID, 0); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	b := &models.Backend{
+		Name:                 "external_check",
+		ExternalCheck:        "enabled",
+		ExternalCheckCommand: "/bin/true",
+	}
+	if err := client.CreateBackend(b, tr.ID, 0); err == nil {
+		t.Error("Should throw error, external-check not enabled in global")
+	}
+}
+
+func TestCreateBackendInvalidBalance(t *testing.T) {
+	hdr := "hdr"
+	roundrobin := "roundrobin"
+	balances := []*models.Balance{
+		{Algorithm: &hdr},
+		{Algorithm: &roundrobin, URILen: 10},
+	}
+	for _, balance := range balances {
+		b := &models.Backend{Name: "invalid_balance", Mode: "http", Balance: balance}
+		if err := client.CreateBackend(b, "", version); err == nil {
+			t.Errorf("Should throw error, invalid balance %s", *balance.Algorithm)
+			version++
+		}
+	}
+}
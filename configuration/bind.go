@@ -73,6 +73,10 @@ func (c *Client) GetBind(name string, frontend string, transactionID string) (in
 // DeleteBind deletes a bind in configuration. One of version or transactionID is
 // mandatory. Returns error on fail, nil on success.
 func (c *Client) DeleteBind(name string, frontend string, transactionID string, version int64) error {
+	if err := c.authorize("DeleteBind", "bind", name, "frontend", frontend, transactionID); err != nil {
+		return err
+	}
+
 	p, t, err := c.loadDataForChange(transactionID, version)
 	if err != nil {
 		return err
@@ -103,6 +107,13 @@ func (c *Client) CreateBind(frontend string, data *models.Bind, transactionID st
 			return NewConfError(ErrValidationError, validationErr.Error())
 		}
 	}
+	if err := ValidateBindKeywords(data, c.haproxyVersion); err != nil {
+		return NewConfError(ErrValidationError, err.Error())
+	}
+
+	if err := c.authorize("CreateBind", "bind", data.Name, "frontend", frontend, transactionID); err != nil {
+		return err
+	}
 
 	p, t, err := c.loadDataForChange(transactionID, version)
 	if err != nil {
@@ -140,6 +151,13 @@ func (c *Client) EditBind(name string, frontend string, data *models.Bind, trans
 			return NewConfError(ErrValidationError, validationErr.Error())
 		}
 	}
+	if err := ValidateBindKeywords(data, c.haproxyVersion); err != nil {
+		return NewConfError(ErrValidationError, err.Error())
+	}
+	if err := c.authorize("EditBind", "bind", name, "frontend", frontend, transactionID); err != nil {
+		return err
+	}
+
 	p, t, err := c.loadDataForChange(transactionID, version)
 	if err != nil {
 		return err
@@ -187,10 +205,17 @@ func ParseBind(ondiskBind types.Bind) *models.Bind {
 	b := &models.Bind{
 		Name: ondiskBind.Path,
 	}
-	if strings.HasPrefix(ondiskBind.Path, "/") {
-		b.Address = ondiskBind.Path
+	// keep the address scheme (ipv4@, quic6@, ...) out of the port split
+	path := ondiskBind.Path
+	scheme := ""
+	if i := strings.Index(path, "@"); i != -1 {
+		scheme = path[:i+1]
+		path = path[i+1:]
+	}
+	if strings.HasPrefix(path, "/") {
+		b.Address = path
 	} else {
-		addSlice := strings.Split(ondiskBind.Path, ":")
+		addSlice := strings.Split(path, ":")
 		switch n := len(addSlice); {
 		case n == 0:
 			return nil
@@ -226,10 +251,11 @@ func ParseBind(ondiskBind types.Bind) *models.Bind {
 
 		}
 	}
+	b.Address = scheme + b.Address
 	for _, p := range ondiskBind.Params {
 		switch v := p.(type) {
 		case *params.BindOptionDoubleWord:
-			if v.Name == "expose-fd" && v.Value == "listener" {
+			if v.Name == "expose-fd" && v.Value == "listeners" {
 				b.ExposeFdListeners = true
 			}
 		case *params.BindOptionWord:
@@ -317,6 +343,8 @@ func ParseBind(ondiskBind types.Bind) *models.Bind {
 				b.CaSignFile = v.Value
 			case "ca-sign-pass":
 				b.CaSignPass = v.Value
+			case "ca-verify-file":
+				b.CaVerifyFile = v.Value
 			case "ciphers":
 				b.Ciphers = v.Value
 			case "ciphersuites":
@@ -449,14 +477,17 @@ func SerializeBind(b models.Bind) types.Bind {
 	if b.CaSignPass != "" {
 		bind.Params = append(bind.Params, &params.BindOptionValue{Name: "ca-sign-pass", Value: b.CaSignPass})
 	}
+	if b.CaVerifyFile != "" {
+		bind.Params = append(bind.Params, &params.BindOptionValue{Name: "ca-verify-file", Value: b.CaVerifyFile})
+	}
 	if b.Ciphers != "" {
-		bind.Params = append(bind.Params, &params.BindOptionValue{Name: "ciphers ", Value: b.Ciphers})
+		bind.Params = append(bind.Params, &params.BindOptionValue{Name: "ciphers", Value: b.Ciphers})
 	}
 	if b.Ciphersuites != "" {
-		bind.Params = append(bind.Params, &params.BindOptionValue{Name: "ciphersuites ", Value: b.Ciphersuites})
+		bind.Params = append(bind.Params, &params.BindOptionValue{Name: "ciphersuites", Value: b.Ciphersuites})
 	}
 	if b.CrlFile != "" {
-		bind.Params = append(bind.Params, &params.BindOptionValue{Name: "crl-file ", Value: b.CrlFile})
+		bind.Params = append(bind.Params, &params.BindOptionValue{Name: "crl-file", Value: b.CrlFile})
 	}
 	if b.CrtIgnoreErr != "" {
 		bind.Params = append(bind.Params, &params.BindOptionValue{Name: "crt-ignore-err", Value: b.CrtIgnoreErr})
@@ -468,25 +499,25 @@ func SerializeBind(b models.Bind) types.Bind {
 		bind.Params = append(bind.Params, &params.BindOptionWord{Name: "defer-accept"})
 	}
 	if b.ExposeFdListeners {
-		bind.Params = append(bind.Params, &params.ServerOptionDoubleWord{Name: "expose-fd", Value: "listeners"})
+		bind.Params = append(bind.Params, &params.BindOptionDoubleWord{Name: "expose-fd", Value: "listeners"})
 	}
 	if b.ForceSslv3 {
-		bind.Params = append(bind.Params, &params.ServerOptionWord{Name: "force-sslv3"})
+		bind.Params = append(bind.Params, &params.BindOptionWord{Name: "force-sslv3"})
 	}
 	if b.ForceTlsv10 {
-		bind.Params = append(bind.Params, &params.ServerOptionWord{Name: "force-tlsv10"})
+		bind.Params = append(bind.Params, &params.BindOptionWord{Name: "force-tlsv10"})
 	}
 	if b.ForceTlsv11 {
-		bind.Params = append(bind.Params, &params.ServerOptionWord{Name: "force-tlsv11"})
+		bind.Params = append(bind.Params, &params.BindOptionWord{Name: "force-tlsv11"})
 	}
 	if b.ForceTlsv12 {
-		bind.Params = append(bind.Params, &params.ServerOptionWord{Name: "force-tlsv12"})
+		bind.Params = append(bind.Params, &params.BindOptionWord{Name: "force-tlsv12"})
 	}
 	if b.ForceTlsv13 {
-		bind.Params = append(bind.Params, &params.ServerOptionWord{Name: "force-tlsv13"})
+		bind.Params = append(bind.Params, &params.BindOptionWord{Name: "force-tlsv13"})
 	}
 	if b.GenerateCertificates {
-		bind.Params = append(bind.Params, &params.ServerOptionWord{Name: "generate-certificates"})
+		bind.Params = append(bind.Params, &params.BindOptionWord{Name: "generate-certificates"})
 	}
 	if b.Gid != 0 {
 		bind.Params = append(bind.Params, &params.BindOptionValue{Name: "gid", Value: strconv.FormatInt(b.Gid, 10)})
@@ -497,6 +528,9 @@ func SerializeBind(b models.Bind) types.Bind {
 	if b.ID != "" {
 		bind.Params = append(bind.Params, &params.BindOptionValue{Name: "id", Value: b.ID})
 	}
+	if b.Interface != "" {
+		bind.Params = append(bind.Params, &params.BindOptionValue{Name: "interface", Value: b.Interface})
+	}
 	if b.Level != "" {
 		bind.Params = append(bind.Params, &params.BindOptionValue{Name: "level", Value: b.Level})
 	}
@@ -515,32 +549,35 @@ func SerializeBind(b models.Bind) types.Bind {
 	if b.Namespace != "" {
 		bind.Params = append(bind.Params, &params.BindOptionValue{Name: "namespace", Value: b.Namespace})
 	}
+	if b.Nice != 0 {
+		bind.Params = append(bind.Params, &params.BindOptionValue{Name: "nice", Value: strconv.FormatInt(b.Nice, 10)})
+	}
 	if b.NoCaNames {
-		bind.Params = append(bind.Params, &params.ServerOptionWord{Name: "no-ca-names"})
+		bind.Params = append(bind.Params, &params.BindOptionWord{Name: "no-ca-names"})
 	}
 	if b.NoSslv3 {
-		bind.Params = append(bind.Params, &params.ServerOptionWord{Name: "no-sslv3"})
+		bind.Params = append(bind.Params, &params.BindOptionWord{Name: "no-sslv3"})
 	}
 	if b.NoTLSTickets {
-		bind.Params = append(bind.Params, &params.ServerOptionWord{Name: "no-tls-tickets"})
+		bind.Params = append(bind.Params, &params.BindOptionWord{Name: "no-tls-tickets"})
 	}
 	if b.NoTlsv10 {
-		bind.Params = append(bind.Params, &params.ServerOptionWord{Name: "no-tlsv10"})
+		bind.Params = append(bind.Params, &params.BindOptionWord{Name: "no-tlsv10"})
 	}
 	if b.NoTlsv11 {
-		bind.Params = append(bind.Params, &params.ServerOptionWord{Name: "no-tlsv11"})
+		bind.Params = append(bind.Params, &params.BindOptionWord{Name: "no-tlsv11"})
 	}
 	if b.NoTlsv12 {
-		bind.Params = append(bind.Params, &params.ServerOptionWord{Name: "no-tlsv12"})
+		bind.Params = append(bind.Params, &params.BindOptionWord{Name: "no-tlsv12"})
 	}
 	if b.NoTlsv13 {
-		bind.Params = append(bind.Params, &params.ServerOptionWord{Name: "no-tlsv13"})
+		bind.Params = append(bind.Params, &params.BindOptionWord{Name: "no-tlsv13"})
 	}
 	if b.Npn != "" {
 		bind.Params = append(bind.Params, &params.BindOptionValue{Name: "npn", Value: b.Npn})
 	}
 	if b.PreferClientCiphers {
-		bind.Params = append(bind.Params, &params.ServerOptionWord{Name: "prefer-client-ciphers"})
+		bind.Params = append(bind.Params, &params.BindOptionWord{Name: "prefer-client-ciphers"})
 	}
 	if b.Proto != "" {
 		bind.Params = append(bind.Params, &params.BindOptionValue{Name: "proto", Value: b.Proto})
@@ -549,13 +586,13 @@ func SerializeBind(b models.Bind) types.Bind {
 		bind.Params = append(bind.Params, &params.BindOptionValue{Name: "ssl-max-ver", Value: b.SslMaxVer})
 	}
 	if b.SslMinVer != "" {
-		bind.Params = append(bind.Params, &params.BindOptionValue{Name: "ssl-min-ver", Value: b.SslMaxVer})
+		bind.Params = append(bind.Params, &params.BindOptionValue{Name: "ssl-min-ver", Value: b.SslMinVer})
 	}
 	if b.StrictSni {
-		bind.Params = append(bind.Params, &params.ServerOptionWord{Name: "strict-sni"})
+		bind.Params = append(bind.Params, &params.BindOptionWord{Name: "strict-sni"})
 	}
 	if b.Tfo {
-		bind.Params = append(bind.Params, &params.ServerOptionWord{Name: "tfo"})
+		bind.Params = append(bind.Params, &params.BindOptionWord{Name: "tfo"})
 	}
 	if b.TLSTicketKeys != "" {
 		bind.Params = append(bind.Params, &params.BindOptionValue{Name: "tls-ticket-keys", Value: b.TLSTicketKeys})
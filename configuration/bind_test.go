@@ -185,3 +185,50 @@ func TestCreateEditDeleteBind(t *testing.T) {
 		version++
 	}
 }
+
+func TestCreateDeleteSSLBind(t *testing.T) {
+	port := int64(4443)
+	l := &models.Bind{
+		Name:                 "created_ssl",
+		Address:              "192.168.2.2",
+		Port:                 &port,
+		Ssl:                  true,
+		SslCertificate:       "dummy.crt",
+		CrtList:              "dummy.list",
+		SslCafile:            "ca.pem",
+		Verify:               "required",
+		Ciphers:              "ECDHE-RSA-AES128-GCM-SHA256",
+		Ciphersuites:         "TLS_AES_128_GCM_SHA256",
+		Alpn:                 "h2,http/1.1",
+		Npn:                  "http/1.1",
+		SslMinVer:            "TLSv1.2",
+		SslMaxVer:            "TLSv1.3",
+		StrictSni:            true,
+		GenerateCertificates: true,
+	}
+
+	err := client.CreateBind("test", l, "", version)
+	if err != nil {
+		t.Error(err.Error())
+	} else {
+		version++
+	}
+
+	_, bind, err := client.GetBind("created_ssl", "test", "")
+	if err != nil {
+		t.Error(err.Error())
+	}
+
+	if !reflect.DeepEqual(bind, l) {
+		fmt.Printf("Created bind: %v\n", bind)
+		fmt.Printf("Given bind: %v\n", l)
+		t.Error("Created bind not equal to given bind")
+	}
+
+	err = client.DeleteBind("created_ssl", "test", "", version)
+	if err != nil {
+		t.Error(err.Error())
+	} else {
+		version++
+	}
+}
@@ -0,0 +1,411 @@
+// Copyright 2019 HAProxy Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package configuration
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	parser "github.com/haproxytech/config-parser/v3"
+	parser_errors "github.com/haproxytech/config-parser/v3/errors"
+	"github.com/haproxytech/config-parser/v3/parsers/filters"
+	"github.com/haproxytech/config-parser/v3/parsers/http/actions"
+	"github.com/haproxytech/config-parser/v3/types"
+	"github.com/haproxytech/models/v2"
+)
+
+// Cache is a cache section, an HTTP cache in shared memory
+type Cache struct {
+	Name string `json:"name"`
+	// TotalMaxSize is the size of the cache in megabytes
+	TotalMaxSize *int64 `json:"total_max_size,omitempty"`
+	// MaxObjectSize is the size of the largest cached object in bytes
+	MaxObjectSize *int64 `json:"max_object_size,omitempty"`
+	// MaxAge is the time objects are kept in the cache in seconds
+	MaxAge *int64 `json:"max_age,omitempty"`
+}
+
+// Validate checks the cache sizes, objects can not be larger than half of the cache
+func (cache *Cache) Validate() error {
+	if cache.Name == "" || strings.ContainsAny(cache.Name, " \t#") {
+		return fmt.Errorf("invalid cache name %s", cache.Name)
+	}
+	if cache.TotalMaxSize != nil && (*cache.TotalMaxSize < 1 || *cache.TotalMaxSize > 4095) {
+		return fmt.Errorf("cache %s: total-max-size must be between 1 and 4095", cache.Name)
+	}
+	if cache.MaxObjectSize != nil {
+		if *cache.MaxObjectSize < 1 {
+			return fmt.Errorf("cache %s: max-object-size has to be greater than 0", cache.Name)
+		}
+		if cache.TotalMaxSize != nil && *cache.MaxObjectSize > *cache.TotalMaxSize*1024*1024/2 {
+			return fmt.Errorf("cache %s: max-object-size can not exceed half of total-max-size", cache.Name)
+		}
+	}
+	if cache.MaxAge != nil && *cache.MaxAge < 1 {
+		return fmt.Errorf("cache %s: max-age has to be greater than 0", cache.Name)
+	}
+	return nil
+}
+
+// GetCaches returns configuration version and an array of
+// configured caches. Returns error on fail.
+func (c *Client) GetCaches(transactionID string) (int64, []*Cache, error) {
+	p, err := c.GetParser(transactionID)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	v, err := c.GetVersion(transactionID)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	names, err := p.SectionsGet(parser.Cache)
+	if err != nil {
+		return v, nil, err
+	}
+
+	caches := []*Cache{}
+	for _, name := range names {
+		cache, err := ParseCache(p, name)
+		if err != nil {
+			return v, nil, c.handleError(name, "", "", "", false, err)
+		}
+		caches = append(caches, cache)
+	}
+
+	return v, caches, nil
+}
+
+// GetCache returns configuration version and a requested cache.
+// Returns error on fail or if cache does not exist.
+func (c *Client) GetCache(name string, transactionID string) (int64, *Cache, error) {
+	p, err := c.GetParser(transactionID)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	v, err := c.GetVersion(transactionID)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	if !c.checkSectionExists(parser.Cache, name, p) {
+		return v, nil, NewConfError(ErrObjectDoesNotExist, fmt.Sprintf("Cache %s does not exist", name))
+	}
+
+	cache, err := ParseCache(p, name)
+	if err != nil {
+		return v, nil, c.handleError(name, "", "", "", false, err)
+	}
+
+	return v, cache, nil
+}
+
+// DeleteCache deletes a cache in configuration. Caches used by frontends or backends can not be
+// deleted. One of version or transactionID is mandatory. Returns error on fail, nil on success.
+func (c *Client) DeleteCache(name string, transactionID string, version int64) error {
+	if err := c.authorize("DeleteCache", "cache", name, "", "", transactionID); err != nil {
+		return err
+	}
+
+	p, t, err := c.loadDataForChange(transactionID, version)
+	if err != nil {
+		return err
+	}
+
+	if !c.checkSectionExists(parser.Cache, name, p) {
+		e := NewConfError(ErrObjectDoesNotExist, fmt.Sprintf("%s %s does not exist", parser.Cache, name))
+		return c.handleError(name, "", "", t, transactionID == "", e)
+	}
+
+	if cacheInUse(p, name) {
+		e := NewConfError(ErrValidationError, fmt.Sprintf("%s %s is used by a cache filter or rule", parser.Cache, name))
+		return c.handleError(name, "", "", t, transactionID == "", e)
+	}
+
+	if err := p.SectionsDelete(parser.Cache, name); err != nil {
+		return c.handleError(name, "", "", t, transactionID == "", err)
+	}
+
+	if err := c.saveData(p, t, transactionID == ""); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// CreateCache creates a cache in configuration. One of version or transactionID is mandatory.
+// Returns error on fail, nil on success.
+func (c *Client) CreateCache(data *Cache, transactionID string, version int64) error {
+	if err := data.Validate(); err != nil {
+		return NewConfError(ErrValidationError, err.Error())
+	}
+
+	if err := c.authorize("CreateCache", "cache", data.Name, "", "", transactionID); err != nil {
+		return err
+	}
+
+	p, t, err := c.loadDataForChange(transactionID, version)
+	if err != nil {
+		return err
+	}
+
+	if err := p.SectionsCreate(parser.Cache, data.Name); err != nil {
+		return c.handleError(data.Name, "", "", t, transactionID == "", err)
+	}
+	if err := SerializeCache(p, data); err != nil {
+		return c.handleError(data.Name, "", "", t, transactionID == "", err)
+	}
+
+	if err := c.saveData(p, t, transactionID == ""); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// EditCache edits a cache in configuration. One of version or transactionID is mandatory.
+// Returns error on fail, nil on success.
+func (c *Client) EditCache(name string, data *Cache, transactionID string, version int64) error {
+	if err := data.Validate(); err != nil {
+		return NewConfError(ErrValidationError, err.Error())
+	}
+	if data.Name != name {
+		return NewConfError(ErrValidationError, fmt.Sprintf("cache %s can not be renamed to %s", name, data.Name))
+	}
+
+	if err := c.authorize("EditCache", "cache", name, "", "", transactionID); err != nil {
+		return err
+	}
+
+	p, t, err := c.loadDataForChange(transactionID, version)
+	if err != nil {
+		return err
+	}
+
+	if !c.checkSectionExists(parser.Cache, name, p) {
+		e := NewConfError(ErrObjectDoesNotExist, fmt.Sprintf("%s %s does not exist", parser.Cache, name))
+		return c.handleError(name, "", "", t, transactionID == "", e)
+	}
+
+	if err := SerializeCache(p, data); err != nil {
+		return c.handleError(name, "", "", t, transactionID == "", err)
+	}
+
+	if err := c.saveData(p, t, transactionID == ""); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// EnableCache attaches the cache to the frontend or backend with filter cache, http-request
+// cache-use and http-response cache-store, in one change. The rules are appended after the
+// existing ones. One of version or transactionID is mandatory. Returns error on fail, nil on
+// success.
+func (c *Client) EnableCache(parentType string, parentName string, cache string, transactionID string, version int64) error {
+	section, err := cacheParentSection(parentType)
+	if err != nil {
+		return err
+	}
+
+	if err := c.authorize("EnableCache", "cache_use", cache, parentType, parentName, transactionID); err != nil {
+		return err
+	}
+
+	p, t, err := c.loadDataForChange(transactionID, version)
+	if err != nil {
+		return err
+	}
+
+	if !c.checkSectionExists(parser.Cache, cache, p) {
+		e := NewConfError(ErrObjectDoesNotExist, fmt.Sprintf("Cache %s does not exist", cache))
+		return c.handleError(cache, parentType, parentName, t, transactionID == "", e)
+	}
+	if !c.checkSectionExists(section, parentName, p) {
+		e := NewConfError(ErrParentDoesNotExist, fmt.Sprintf("%s %s does not exist", parentType, parentName))
+		return c.handleError(cache, parentType, parentName, t, transactionID == "", e)
+	}
+
+	filterIndex, ruleIndex, err := findCacheUse(p, section, parentName, cache)
+	if err != nil {
+		return c.handleError(cache, parentType, parentName, t, transactionID == "", err)
+	}
+	if filterIndex == -1 {
+		if err := p.Insert(section, parentName, "filter", SerializeFilter(models.Filter{Type: "cache", CacheName: cache}), -1); err != nil {
+			return c.handleError(cache, parentType, parentName, t, transactionID == "", err)
+		}
+	}
+	if ruleIndex == -1 {
+		rule, err := SerializeHTTPRequestRule(models.HTTPRequestRule{Type: "cache-use", CacheName: cache})
+		if err != nil {
+			return c.handleError(cache, parentType, parentName, t, transactionID == "", err)
+		}
+		if err := p.Insert(section, parentName, "http-request", rule, -1); err != nil {
+			return c.handleError(cache, parentType, parentName, t, transactionID == "", err)
+		}
+	}
+	// cache-store has no parser, it is kept as an unprocessed line
+	empty := ""
+	if err := setRawDirective(p, section, parentName, cacheStoreDirective(cache), &empty); err != nil {
+		return c.handleError(cache, parentType, parentName, t, transactionID == "", err)
+	}
+
+	if err := c.saveData(p, t, transactionID == ""); err != nil {
+		return err
+	}
+	return nil
+}
+
+// DisableCache removes the filter cache, http-request cache-use and http-response cache-store of
+// the cache from the frontend or backend. One of version or transactionID is mandatory. Returns
+// error on fail, nil on success.
+func (c *Client) DisableCache(parentType string, parentName string, cache string, transactionID string, version int64) error {
+	section, err := cacheParentSection(parentType)
+	if err != nil {
+		return err
+	}
+
+	if err := c.authorize("DisableCache", "cache_use", cache, parentType, parentName, transactionID); err != nil {
+		return err
+	}
+
+	p, t, err := c.loadDataForChange(transactionID, version)
+	if err != nil {
+		return err
+	}
+
+	if !c.checkSectionExists(section, parentName, p) {
+		e := NewConfError(ErrParentDoesNotExist, fmt.Sprintf("%s %s does not exist", parentType, parentName))
+		return c.handleError(cache, parentType, parentName, t, transactionID == "", e)
+	}
+
+	filterIndex, ruleIndex, err := findCacheUse(p, section, parentName, cache)
+	if err != nil {
+		return c.handleError(cache, parentType, parentName, t, transactionID == "", err)
+	}
+	if filterIndex != -1 {
+		if err := p.Delete(section, parentName, "filter", filterIndex); err != nil {
+			return c.handleError(cache, parentType, parentName, t, transactionID == "", err)
+		}
+	}
+	if ruleIndex != -1 {
+		if err := p.Delete(section, parentName, "http-request", ruleIndex); err != nil {
+			return c.handleError(cache, parentType, parentName, t, transactionID == "", err)
+		}
+	}
+	if err := setRawDirective(p, section, parentName, cacheStoreDirective(cache), nil); err != nil {
+		return c.handleError(cache, parentType, parentName, t, transactionID == "", err)
+	}
+
+	if err := c.saveData(p, t, transactionID == ""); err != nil {
+		return err
+	}
+	return nil
+}
+
+func ParseCache(p *parser.Parser, name string) (*Cache, error) {
+	cache := &Cache{Name: name}
+	for attribute, value := range map[string]**int64{
+		"total-max-size":  &cache.TotalMaxSize,
+		"max-object-size": &cache.MaxObjectSize,
+		"max-age":         &cache.MaxAge,
+	} {
+		data, err := p.Get(parser.Cache, name, attribute, false)
+		if err != nil {
+			if err == parser_errors.ErrFetch {
+				continue
+			}
+			return nil, err
+		}
+		v := data.(*types.Int64C).Value
+		*value = &v
+	}
+	return cache, nil
+}
+
+func SerializeCache(p *parser.Parser, data *Cache) error {
+	for attribute, value := range map[string]*int64{
+		"total-max-size":  data.TotalMaxSize,
+		"max-object-size": data.MaxObjectSize,
+		"max-age":         data.MaxAge,
+	} {
+		var d interface{}
+		if value != nil {
+			d = &types.Int64C{Value: *value}
+		}
+		if err := p.Set(parser.Cache, data.Name, attribute, d); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// findCacheUse returns the indexes of the filter cache and http-request cache-use of the cache,
+// -1 when not found
+func findCacheUse(p *parser.Parser, section parser.Section, name string, cache string) (int, int, error) {
+	filterIndex := -1
+	data, err := p.Get(section, name, "filter", false)
+	if err != nil && err != parser_errors.ErrFetch {
+		return 0, 0, err
+	}
+	if err == nil {
+		for i, f := range data.([]types.Filter) {
+			if fc, ok := f.(*filters.Cache); ok && fc.Name == cache {
+				filterIndex = i
+				break
+			}
+		}
+	}
+
+	ruleIndex := -1
+	data, err = p.Get(section, name, "http-request", false)
+	if err != nil && err != parser_errors.ErrFetch {
+		return 0, 0, err
+	}
+	if err == nil {
+		for i, r := range data.([]types.HTTPAction) {
+			if cu, ok := r.(*actions.CacheUse); ok && cu.Name == cache && cu.Cond == "" {
+				ruleIndex = i
+				break
+			}
+		}
+	}
+	return filterIndex, ruleIndex, nil
+}
+
+func cacheStoreDirective(cache string) string {
+	return "http-response cache-store " + cache
+}
+
+// cacheInUse returns true if a filter or rule of a frontend or backend uses the cache
+func cacheInUse(p *parser.Parser, name string) bool {
+	r := regexp.MustCompile(`(?m)^\s*(filter cache|http-request cache-use|http-response cache-store)\s+` + regexp.QuoteMeta(name) + `(\s|$)`)
+	return r.MatchString(configString(p))
+}
+
+func cacheParentSection(parentType string) (parser.Section, error) {
+	switch parentType {
+	case "frontend":
+		return parser.Frontends, nil
+	case "backend":
+		return parser.Backends, nil
+	default:
+		return "", NewConfError(ErrValidationError, fmt.Sprintf("cache is not supported in %s", parentType))
+	}
+}
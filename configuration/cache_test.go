@@ -0,0 +1,116 @@
+// Copyright 2019 HAProxy Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package configuration
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/haproxytech/client-native/v2/misc"
+)
+
+func TestCreateEditDeleteCache(t *testing.T) {
+	cache := &Cache{
+		Name:          "static",
+		TotalMaxSize:  misc.Int64P(64),
+		MaxObjectSize: misc.Int64P(40 * 1024 * 1024),
+	}
+	if err := client.CreateCache(cache, "", version); err == nil {
+		t.Error("Should throw error, max-object-size larger than half of total-max-size")
+		version++
+	}
+
+	cache.MaxObjectSize = misc.Int64P(1024 * 1024)
+	cache.MaxAge = misc.Int64P(60)
+	if err := client.CreateCache(cache, "", version); err != nil {
+		t.Fatal(err.Error())
+	}
+	version++
+
+	v, c, err := client.GetCache("static", "")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if !reflect.DeepEqual(c, cache) {
+		t.Errorf("Cache %v returned, expected %v", c, cache)
+	}
+	if v != version {
+		t.Errorf("Version %v returned, expected %v", v, version)
+	}
+	if err := client.CreateCache(cache, "", version); err == nil {
+		t.Error("Should throw error cache already exists")
+		version++
+	}
+
+	cache = &Cache{Name: "static", TotalMaxSize: misc.Int64P(128)}
+	if err := client.EditCache("static", cache, "", version); err != nil {
+		t.Error(err.Error())
+	} else {
+		version++
+	}
+	_, caches, err := client.GetCaches("")
+	if err != nil {
+		t.Error(err.Error())
+	} else if len(caches) != 1 || !reflect.DeepEqual(caches[0], cache) {
+		t.Errorf("Unexpected caches: %v", caches)
+	}
+
+	if err := client.EnableCache("backend", "test_2", "static", "", version); err != nil {
+		t.Fatal(err.Error())
+	}
+	version++
+	// enabling twice does not duplicate the filter and rules
+	if err := client.EnableCache("backend", "test_2", "static", "", version); err != nil {
+		t.Error(err.Error())
+	} else {
+		version++
+	}
+	_, filters, _ := client.GetFilters("backend", "test_2", "")
+	_, rules, _ := client.GetHTTPRequestRules("backend", "test_2", "")
+	countFilters, countRules := 0, 0
+	for _, f := range filters {
+		if f.Type == "cache" && f.CacheName == "static" {
+			countFilters++
+		}
+	}
+	for _, r := range rules {
+		if r.Type == "cache-use" && r.CacheName == "static" {
+			countRules++
+		}
+	}
+	if countFilters != 1 || countRules != 1 {
+		t.Errorf("%d cache filters and %d cache-use rules, expected 1", countFilters, countRules)
+	}
+
+	if err := client.DeleteCache("static", "", version); err == nil {
+		t.Error("Should throw error, cache used by backend test_2")
+		version++
+	}
+
+	if err := client.DisableCache("backend", "test_2", "static", "", version); err != nil {
+		t.Error(err.Error())
+	} else {
+		version++
+	}
+	if err := client.DeleteCache("static", "", version); err != nil {
+		t.Error(err.Error())
+	} else {
+		version++
+	}
+	if _, _, err := client.GetCache("static", ""); err == nil {
+		t.Error("DeleteCache failed, cache static still exists")
+	}
+}
@@ -0,0 +1,640 @@
+// Copyright 2019 HAProxy Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package configuration
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	parser "github.com/haproxytech/config-parser/v3"
+	parser_errors "github.com/haproxytech/config-parser/v3/errors"
+	http_actions "github.com/haproxytech/config-parser/v3/parsers/http/actions"
+	tcp_actions "github.com/haproxytech/config-parser/v3/parsers/tcp/actions"
+	tcp_types "github.com/haproxytech/config-parser/v3/parsers/tcp/types"
+	"github.com/haproxytech/config-parser/v3/types"
+	"github.com/haproxytech/models/v2"
+)
+
+const (
+	// CaptureRequest captures from the request
+	CaptureRequest = "request"
+	// CaptureResponse captures from the response
+	CaptureResponse = "response"
+)
+
+// CaptureHeader makes the frontend capture the header in the given direction and returns the
+// capture index to reference from log formats, for example %[capture.req.hdr(0)]. A header that
+// is already captured keeps its index and gets the new length. One of version or transactionID
+// is mandatory. Returns error on fail.
+func (c *Client) CaptureHeader(frontend string, direction string, headerName string, length int64, transactionID string, version int64) (int64, error) {
+	if direction != CaptureRequest && direction != CaptureResponse {
+		return 0, NewConfError(ErrValidationError, fmt.Sprintf("invalid capture direction %s", direction))
+	}
+	if headerName == "" || strings.ContainsAny(headerName, " \t#:") {
+		return 0, NewConfError(ErrValidationError, fmt.Sprintf("invalid header name %s", headerName))
+	}
+	if length <= 0 {
+		return 0, NewConfError(ErrValidationError, "capture length has to be greater than 0")
+	}
+
+	if err := c.authorize("CaptureHeader", "header_capture", "", "frontend", frontend, transactionID); err != nil {
+		return 0, err
+	}
+
+	p, t, err := c.loadDataForChange(transactionID, version)
+	if err != nil {
+		return 0, err
+	}
+
+	if !c.checkSectionExists(parser.Frontends, frontend, p) {
+		e := NewConfError(ErrObjectDoesNotExist, fmt.Sprintf("frontend %s does not exist", frontend))
+		return 0, c.handleError(frontend, "", "", t, transactionID == "", e)
+	}
+
+	index, err := captureSlotsBefore(p, frontend, direction)
+	if err != nil {
+		return 0, c.handleError(frontend, "frontend", frontend, t, transactionID == "", err)
+	}
+
+	lines, err := getRawLines(p, parser.Frontends, frontend)
+	if err != nil {
+		return 0, c.handleError(frontend, "frontend", frontend, t, transactionID == "", err)
+	}
+
+	keyword := "capture " + direction + " header"
+	line := fmt.Sprintf("%s %s len %d", keyword, headerName, length)
+	found := false
+	for i, l := range lines {
+		if !isCaptureSlot(l.Value, direction) {
+			continue
+		}
+		if value, ok := matchRawDirective(l.Value, keyword); ok && strings.EqualFold(strings.Fields(value)[0], headerName) {
+			lines[i] = types.UnProcessed{Value: line}
+			found = true
+			break
+		}
+		index++
+	}
+	if !found {
+		lines = append(lines, types.UnProcessed{Value: line})
+	}
+
+	if err := p.Set(parser.Frontends, frontend, "", lines); err != nil {
+		return 0, c.handleError(frontend, "frontend", frontend, t, transactionID == "", err)
+	}
+
+	if err := c.saveData(p, t, transactionID == ""); err != nil {
+		return 0, err
+	}
+	return index, nil
+}
+
+// HeaderCapture is a capture request header or capture response header line of a frontend,
+// capturing the header for logs in a slot of its direction
+type HeaderCapture struct {
+	// Index of the line among the capture header lines of the frontend
+	Index *int64 `json:"index"`
+	// Type is the direction of the header, request or response
+	Type   string `json:"type"`
+	Name   string `json:"name"`
+	Length int64  `json:"length"`
+	// SlotID is the id of the capture slot, read only
+	SlotID *int64 `json:"slot_id,omitempty"`
+}
+
+// Validate checks the direction, header name and length of the capture
+func (h *HeaderCapture) Validate() error {
+	if h.Type != CaptureRequest && h.Type != CaptureResponse {
+		return fmt.Errorf("invalid capture direction %s", h.Type)
+	}
+	if h.Name == "" || strings.ContainsAny(h.Name, " \t#:") {
+		return fmt.Errorf("invalid header name %s", h.Name)
+	}
+	if h.Length <= 0 {
+		return fmt.Errorf("capture length has to be greater than 0")
+	}
+	return nil
+}
+
+// GetHeaderCaptures returns configuration version and an array of
+// configured capture header lines in the specified frontend. Returns error on fail.
+func (c *Client) GetHeaderCaptures(frontend string, transactionID string) (int64, []*HeaderCapture, error) {
+	p, err := c.GetParser(transactionID)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	v, err := c.GetVersion(transactionID)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	if !c.checkSectionExists(parser.Frontends, frontend, p) {
+		return v, nil, NewConfError(ErrParentDoesNotExist, fmt.Sprintf("frontend %s does not exist", frontend))
+	}
+
+	captures, err := ParseHeaderCaptures(frontend, p)
+	if err != nil {
+		return v, nil, c.handleError("", "frontend", frontend, "", false, err)
+	}
+
+	return v, captures, nil
+}
+
+// GetHeaderCapture returns configuration version and a requested capture header line
+// in the specified frontend. Returns error on fail or if the capture does not exist.
+func (c *Client) GetHeaderCapture(index int64, frontend string, transactionID string) (int64, *HeaderCapture, error) {
+	v, captures, err := c.GetHeaderCaptures(frontend, transactionID)
+	if err != nil {
+		return 0, nil, err
+	}
+	if index < 0 || index >= int64(len(captures)) {
+		return v, nil, NewConfError(ErrObjectDoesNotExist, fmt.Sprintf("header capture %v does not exist in frontend %s", index, frontend))
+	}
+
+	return v, captures[index], nil
+}
+
+// CreateHeaderCapture inserts a capture header line at its index, a header can be captured once
+// in each direction. The slot ids of the following captures of the direction increase. One of
+// version or transactionID is mandatory. Returns error on fail, nil on success.
+func (c *Client) CreateHeaderCapture(frontend string, data *HeaderCapture, transactionID string, version int64) error {
+	if data.Index == nil || *data.Index < 0 {
+		return NewConfError(ErrValidationError, "index is required and can not be negative")
+	}
+	if err := data.Validate(); err != nil {
+		return NewConfError(ErrValidationError, err.Error())
+	}
+	return c.setHeaderCapture("CreateHeaderCapture", *data.Index, frontend, data, true, transactionID, version)
+}
+
+// EditHeaderCapture edits a capture header line in configuration. Changing the direction changes
+// the slot ids of the following captures. One of version or transactionID is mandatory. Returns
+// error on fail, nil on success.
+func (c *Client) EditHeaderCapture(index int64, frontend string, data *HeaderCapture, transactionID string, version int64) error {
+	if err := data.Validate(); err != nil {
+		return NewConfError(ErrValidationError, err.Error())
+	}
+	return c.setHeaderCapture("EditHeaderCapture", index, frontend, data, false, transactionID, version)
+}
+
+// DeleteHeaderCapture deletes a capture header line in configuration, the slot ids of the
+// following captures of the direction decrease. One of version or transactionID is mandatory.
+// Returns error on fail, nil on success.
+func (c *Client) DeleteHeaderCapture(index int64, frontend string, transactionID string, version int64) error {
+	return c.setHeaderCapture("DeleteHeaderCapture", index, frontend, nil, false, transactionID, version)
+}
+
+func (c *Client) setHeaderCapture(operation string, index int64, frontend string, data *HeaderCapture, insert bool, transactionID string, version int64) error {
+	if err := c.authorize(operation, "header_capture", authorizedIndex(index), "frontend", frontend, transactionID); err != nil {
+		return err
+	}
+
+	p, t, err := c.loadDataForChange(transactionID, version)
+	if err != nil {
+		return err
+	}
+
+	id := strconv.FormatInt(index, 10)
+	if !c.checkSectionExists(parser.Frontends, frontend, p) {
+		e := NewConfError(ErrParentDoesNotExist, fmt.Sprintf("frontend %s does not exist", frontend))
+		return c.handleError(id, "frontend", frontend, t, transactionID == "", e)
+	}
+
+	lines, err := getRawLines(p, parser.Frontends, frontend)
+	if err != nil {
+		return c.handleError(id, "frontend", frontend, t, transactionID == "", err)
+	}
+	positions := []int{}
+	for i, l := range lines {
+		h := ParseHeaderCapture(l.Value)
+		if h == nil {
+			continue
+		}
+		if data != nil && (insert || int64(len(positions)) != index) && h.Type == data.Type && strings.EqualFold(h.Name, data.Name) {
+			e := NewConfError(ErrObjectAlreadyExists, fmt.Sprintf("%s header %s is already captured in frontend %s", h.Type, h.Name, frontend))
+			return c.handleError(id, "frontend", frontend, t, transactionID == "", e)
+		}
+		positions = append(positions, i)
+	}
+
+	count := int64(len(positions))
+	if index < 0 || index > count || (!insert && index == count) {
+		e := NewConfError(ErrObjectDoesNotExist, fmt.Sprintf("header capture %v does not exist in frontend %s", index, frontend))
+		return c.handleError(id, "frontend", frontend, t, transactionID == "", e)
+	}
+
+	result := make([]types.UnProcessed, 0, len(lines)+1)
+	switch {
+	case insert:
+		pos := len(lines)
+		if index < count {
+			pos = positions[index]
+		} else if count > 0 {
+			pos = positions[count-1] + 1
+		}
+		result = append(result, lines[:pos]...)
+		result = append(result, types.UnProcessed{Value: SerializeHeaderCapture(*data)})
+		result = append(result, lines[pos:]...)
+	case data == nil:
+		result = append(result, lines[:positions[index]]...)
+		result = append(result, lines[positions[index]+1:]...)
+	default:
+		result = append(result, lines...)
+		result[positions[index]] = types.UnProcessed{Value: SerializeHeaderCapture(*data)}
+	}
+
+	if len(result) == 0 {
+		err = p.Set(parser.Frontends, frontend, "", nil)
+	} else {
+		err = p.Set(parser.Frontends, frontend, "", result)
+	}
+	if err != nil {
+		return c.handleError(id, "frontend", frontend, t, transactionID == "", err)
+	}
+
+	if err := c.saveData(p, t, transactionID == ""); err != nil {
+		return err
+	}
+	return nil
+}
+
+// ParseHeaderCaptures returns the capture header lines of the frontend with the ids of their
+// slots, counting the slots allocated by capture rules and declare capture lines
+func ParseHeaderCaptures(frontend string, p *parser.Parser) ([]*HeaderCapture, error) {
+	captures := []*HeaderCapture{}
+	requestSlots, err := captureSlotsBefore(p, frontend, CaptureRequest)
+	if err != nil {
+		return nil, err
+	}
+	slots := map[string]int64{CaptureRequest: requestSlots, CaptureResponse: 0}
+
+	lines, err := getRawLines(p, parser.Frontends, frontend)
+	if err != nil {
+		return nil, err
+	}
+	for _, l := range lines {
+		for direction := range slots {
+			if !isCaptureSlot(l.Value, direction) {
+				continue
+			}
+			if h := ParseHeaderCapture(l.Value); h != nil {
+				index := int64(len(captures))
+				slot := slots[direction]
+				h.Index = &index
+				h.SlotID = &slot
+				captures = append(captures, h)
+			}
+			slots[direction]++
+		}
+	}
+	return captures, nil
+}
+
+// ParseHeaderCapture returns the header capture of a line, nil if the line is not one
+func ParseHeaderCapture(line string) *HeaderCapture {
+	for _, direction := range []string{CaptureRequest, CaptureResponse} {
+		value, ok := matchRawDirective(line, "capture "+direction+" header")
+		if !ok {
+			continue
+		}
+		words := strings.Fields(value)
+		if len(words) != 3 || words[1] != "len" {
+			return nil
+		}
+		length, err := strconv.ParseInt(words[2], 10, 64)
+		if err != nil {
+			return nil
+		}
+		return &HeaderCapture{Type: direction, Name: words[0], Length: length}
+	}
+	return nil
+}
+
+func SerializeHeaderCapture(h HeaderCapture) string {
+	return fmt.Sprintf("capture %s header %s len %d", h.Type, h.Name, h.Length)
+}
+
+// DeclareCapture is a declare capture line of a frontend, a capture slot filled by capture rules
+// referencing its id
+type DeclareCapture struct {
+	// Index of the declaration among the declare capture lines of the frontend
+	Index *int64 `json:"index"`
+	// Type is the direction of the slot, request or response
+	Type   string `json:"type"`
+	Length int64  `json:"length"`
+	// SlotID is the id of the capture slot, read only
+	SlotID *int64 `json:"slot_id,omitempty"`
+}
+
+// Validate checks the direction and length of the declaration
+func (d *DeclareCapture) Validate() error {
+	if d.Type != CaptureRequest && d.Type != CaptureResponse {
+		return fmt.Errorf("invalid capture direction %s", d.Type)
+	}
+	if d.Length <= 0 {
+		return fmt.Errorf("capture length has to be greater than 0")
+	}
+	return nil
+}
+
+// HTTPRequestRule returns an http-request capture rule storing the sample in a request slot
+func (d *DeclareCapture) HTTPRequestRule(index int64, sample string) (*models.HTTPRequestRule, error) {
+	if d.Type != CaptureRequest || d.SlotID == nil {
+		return nil, fmt.Errorf("http-request capture requires a declared request slot")
+	}
+	id := *d.SlotID
+	return &models.HTTPRequestRule{Index: &index, Type: "capture", CaptureSample: sample, CaptureID: &id}, nil
+}
+
+// HTTPResponseRule returns an http-response capture rule storing the sample in a response slot
+func (d *DeclareCapture) HTTPResponseRule(index int64, sample string) (*models.HTTPResponseRule, error) {
+	if d.Type != CaptureResponse || d.SlotID == nil {
+		return nil, fmt.Errorf("http-response capture requires a declared response slot")
+	}
+	id := *d.SlotID
+	return &models.HTTPResponseRule{Index: &index, Type: "capture", CaptureSample: sample, CaptureID: &id}, nil
+}
+
+// GetDeclareCaptures returns configuration version and an array of
+// configured declare captures in the specified frontend. Returns error on fail.
+func (c *Client) GetDeclareCaptures(frontend string, transactionID string) (int64, []*DeclareCapture, error) {
+	p, err := c.GetParser(transactionID)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	v, err := c.GetVersion(transactionID)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	if !c.checkSectionExists(parser.Frontends, frontend, p) {
+		return v, nil, NewConfError(ErrParentDoesNotExist, fmt.Sprintf("frontend %s does not exist", frontend))
+	}
+
+	captures, err := ParseDeclareCaptures(frontend, p)
+	if err != nil {
+		return v, nil, c.handleError("", "frontend", frontend, "", false, err)
+	}
+
+	return v, captures, nil
+}
+
+// GetDeclareCapture returns configuration version and a requested declare capture
+// in the specified frontend. Returns error on fail or if declare capture does not exist.
+func (c *Client) GetDeclareCapture(index int64, frontend string, transactionID string) (int64, *DeclareCapture, error) {
+	p, err := c.GetParser(transactionID)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	v, err := c.GetVersion(transactionID)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	captures, err := ParseDeclareCaptures(frontend, p)
+	if err != nil {
+		return v, nil, c.handleError("", "frontend", frontend, "", false, err)
+	}
+	if index < 0 || index >= int64(len(captures)) {
+		return v, nil, NewConfError(ErrObjectDoesNotExist, fmt.Sprintf("declare capture %v does not exist in frontend %s", index, frontend))
+	}
+
+	return v, captures[index], nil
+}
+
+// CreateDeclareCapture appends a declare capture to the frontend and returns the id of its slot.
+// Declarations are always appended so the slots referenced by existing rules keep their id. One
+// of version or transactionID is mandatory. Returns error on fail.
+func (c *Client) CreateDeclareCapture(frontend string, data *DeclareCapture, transactionID string, version int64) (int64, error) {
+	if err := data.Validate(); err != nil {
+		return 0, NewConfError(ErrValidationError, err.Error())
+	}
+
+	if err := c.authorize("CreateDeclareCapture", "declare_capture", "", "frontend", frontend, transactionID); err != nil {
+		return 0, err
+	}
+
+	p, t, err := c.loadDataForChange(transactionID, version)
+	if err != nil {
+		return 0, err
+	}
+
+	if !c.checkSectionExists(parser.Frontends, frontend, p) {
+		e := NewConfError(ErrParentDoesNotExist, fmt.Sprintf("frontend %s does not exist", frontend))
+		return 0, c.handleError("", "frontend", frontend, t, transactionID == "", e)
+	}
+
+	lines, err := getRawLines(p, parser.Frontends, frontend)
+	if err != nil {
+		return 0, c.handleError("", "frontend", frontend, t, transactionID == "", err)
+	}
+	lines = append(lines, types.UnProcessed{Value: SerializeDeclareCapture(*data)})
+	if err := p.Set(parser.Frontends, frontend, "", lines); err != nil {
+		return 0, c.handleError("", "frontend", frontend, t, transactionID == "", err)
+	}
+
+	captures, err := ParseDeclareCaptures(frontend, p)
+	if err != nil {
+		return 0, c.handleError("", "frontend", frontend, t, transactionID == "", err)
+	}
+
+	if err := c.saveData(p, t, transactionID == ""); err != nil {
+		return 0, err
+	}
+	return *captures[len(captures)-1].SlotID, nil
+}
+
+// EditDeclareCapture edits a declare capture in configuration. Changing the direction changes the
+// slot ids of the following declarations. One of version or transactionID is mandatory. Returns
+// error on fail, nil on success.
+func (c *Client) EditDeclareCapture(index int64, frontend string, data *DeclareCapture, transactionID string, version int64) error {
+	if err := data.Validate(); err != nil {
+		return NewConfError(ErrValidationError, err.Error())
+	}
+	return c.setDeclareCapture("EditDeclareCapture", index, frontend, data, transactionID, version)
+}
+
+// DeleteDeclareCapture deletes a declare capture in configuration, the slot ids of the following
+// declarations of the direction decrease. One of version or transactionID is mandatory. Returns
+// error on fail, nil on success.
+func (c *Client) DeleteDeclareCapture(index int64, frontend string, transactionID string, version int64) error {
+	return c.setDeclareCapture("DeleteDeclareCapture", index, frontend, nil, transactionID, version)
+}
+
+func (c *Client) setDeclareCapture(operation string, index int64, frontend string, data *DeclareCapture, transactionID string, version int64) error {
+	if err := c.authorize(operation, "declare_capture", authorizedIndex(index), "frontend", frontend, transactionID); err != nil {
+		return err
+	}
+
+	p, t, err := c.loadDataForChange(transactionID, version)
+	if err != nil {
+		return err
+	}
+
+	if !c.checkSectionExists(parser.Frontends, frontend, p) {
+		e := NewConfError(ErrParentDoesNotExist, fmt.Sprintf("frontend %s does not exist", frontend))
+		return c.handleError(strconv.FormatInt(index, 10), "frontend", frontend, t, transactionID == "", e)
+	}
+
+	lines, err := getRawLines(p, parser.Frontends, frontend)
+	if err != nil {
+		return c.handleError(strconv.FormatInt(index, 10), "frontend", frontend, t, transactionID == "", err)
+	}
+	result := make([]types.UnProcessed, 0, len(lines))
+	found := false
+	var i int64
+	for _, l := range lines {
+		if ParseDeclareCapture(l.Value) == nil {
+			result = append(result, l)
+			continue
+		}
+		if i == index {
+			found = true
+			if data != nil {
+				result = append(result, types.UnProcessed{Value: SerializeDeclareCapture(*data)})
+			}
+		} else {
+			result = append(result, l)
+		}
+		i++
+	}
+	if !found {
+		e := NewConfError(ErrObjectDoesNotExist, fmt.Sprintf("declare capture %v does not exist in frontend %s", index, frontend))
+		return c.handleError(strconv.FormatInt(index, 10), "frontend", frontend, t, transactionID == "", e)
+	}
+
+	if len(result) == 0 {
+		err = p.Set(parser.Frontends, frontend, "", nil)
+	} else {
+		err = p.Set(parser.Frontends, frontend, "", result)
+	}
+	if err != nil {
+		return c.handleError(strconv.FormatInt(index, 10), "frontend", frontend, t, transactionID == "", err)
+	}
+
+	if err := c.saveData(p, t, transactionID == ""); err != nil {
+		return err
+	}
+	return nil
+}
+
+// ParseDeclareCaptures returns the declare captures of the frontend with the ids of their slots,
+// counting the slots allocated by capture rules and capture header lines
+func ParseDeclareCaptures(frontend string, p *parser.Parser) ([]*DeclareCapture, error) {
+	captures := []*DeclareCapture{}
+	requestSlots, err := captureSlotsBefore(p, frontend, CaptureRequest)
+	if err != nil {
+		return nil, err
+	}
+	slots := map[string]int64{CaptureRequest: requestSlots, CaptureResponse: 0}
+
+	lines, err := getRawLines(p, parser.Frontends, frontend)
+	if err != nil {
+		return nil, err
+	}
+	for _, l := range lines {
+		for direction := range slots {
+			if !isCaptureSlot(l.Value, direction) {
+				continue
+			}
+			if d := ParseDeclareCapture(l.Value); d != nil {
+				index := int64(len(captures))
+				slot := slots[direction]
+				d.Index = &index
+				d.SlotID = &slot
+				captures = append(captures, d)
+			}
+			slots[direction]++
+		}
+	}
+	return captures, nil
+}
+
+// ParseDeclareCapture returns the declare capture of a line, nil if the line is not one
+func ParseDeclareCapture(line string) *DeclareCapture {
+	value, ok := matchRawDirective(line, "declare capture")
+	if !ok {
+		return nil
+	}
+	words := strings.Fields(value)
+	if len(words) != 3 || words[1] != "len" {
+		return nil
+	}
+	length, err := strconv.ParseInt(words[2], 10, 64)
+	if err != nil {
+		return nil
+	}
+	return &DeclareCapture{Type: words[0], Length: length}
+}
+
+func SerializeDeclareCapture(d DeclareCapture) string {
+	return fmt.Sprintf("declare capture %s len %d", d.Type, d.Length)
+}
+
+// isCaptureSlot returns true if the raw line allocates a capture slot in the direction
+func isCaptureSlot(line string, direction string) bool {
+	if value, ok := matchRawDirective(line, "capture "+direction+" header"); ok {
+		return len(strings.Fields(value)) > 0
+	}
+	_, ok := matchRawDirective(line, "declare capture "+direction)
+	return ok
+}
+
+// captureSlotsBefore returns the number of capture slots allocated by rules, which are written
+// before the unprocessed capture lines of the frontend
+func captureSlotsBefore(p *parser.Parser, frontend string, direction string) (int64, error) {
+	// only request captures with a length allocate a new slot, others reference one by id
+	if direction != CaptureRequest {
+		return 0, nil
+	}
+
+	var slots int64
+	data, err := p.Get(parser.Frontends, frontend, "tcp-request", false)
+	if err != nil && err != parser_errors.ErrFetch {
+		return 0, err
+	}
+	if err == nil {
+		for _, r := range data.([]types.TCPType) {
+			var action types.TCPAction
+			switch v := r.(type) {
+			case *tcp_types.Connection:
+				action = v.Action
+			case *tcp_types.Content:
+				action = v.Action
+			}
+			if a, ok := action.(*tcp_actions.Capture); ok && a.Len > 0 {
+				slots++
+			}
+		}
+	}
+
+	data, err = p.Get(parser.Frontends, frontend, "http-request", false)
+	if err != nil && err != parser_errors.ErrFetch {
+		return 0, err
+	}
+	if err == nil {
+		for _, r := range data.([]types.HTTPAction) {
+			if a, ok := r.(*http_actions.Capture); ok && a.Len != nil && *a.Len > 0 {
+				slots++
+			}
+		}
+	}
+	return slots, nil
+}
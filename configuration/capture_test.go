@@ -0,0 +1,210 @@
+// Copyright 2019 HAProxy Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package configuration
+
+import (
+	"testing"
+)
+
+func TestCaptureHeader(t *testing.T) {
+	// test_2 already allocates request slot 0 with http-request capture
+	captures := []struct {
+		direction string
+		header    string
+		length    int64
+		index     int64
+	}{
+		{CaptureRequest, "Host", 64, 1},
+		{CaptureRequest, "User-Agent", 128, 2},
+		{CaptureResponse, "Server", 32, 0},
+		{CaptureRequest, "host", 32, 1},
+	}
+
+	for _, capture := range captures {
+		index, err := client.CaptureHeader("test_2", capture.direction, capture.header, capture.length, "", version)
+		if err != nil {
+			t.Error(err.Error())
+			continue
+		}
+		version++
+		if index != capture.index {
+			t.Errorf("%s header %s captured at %v, expected %v", capture.direction, capture.header, index, capture.index)
+		}
+	}
+
+	_, err := client.CaptureHeader("test_2", "both", "Host", 64, "", version)
+	if err == nil {
+		t.Error("Should throw error, invalid direction")
+		version++
+	}
+
+	_, err = client.CaptureHeader("doesnotexist", CaptureRequest, "Host", 64, "", version)
+	if err == nil {
+		t.Error("Should throw error, non existent frontend")
+		version++
+	}
+}
+
+func TestCreateEditDeleteDeclareCapture(t *testing.T) {
+	// TestCaptureHeader leaves request slots 0 to 2 and response slot 0 allocated in test_2
+	declares := []struct {
+		capture *DeclareCapture
+		slot    int64
+	}{
+		{&DeclareCapture{Type: CaptureRequest, Length: 20}, 3},
+		{&DeclareCapture{Type: CaptureResponse, Length: 10}, 1},
+		{&DeclareCapture{Type: CaptureRequest, Length: 30}, 4},
+	}
+	for _, d := range declares {
+		slot, err := client.CreateDeclareCapture("test_2", d.capture, "", version)
+		if err != nil {
+			t.Error(err.Error())
+			continue
+		}
+		version++
+		if slot != d.slot {
+			t.Errorf("%s capture declared at slot %v, expected %v", d.capture.Type, slot, d.slot)
+		}
+	}
+
+	_, captures, err := client.GetDeclareCaptures("test_2", "")
+	if err != nil {
+		t.Error(err.Error())
+	}
+	if len(captures) != 3 {
+		t.Fatalf("%v declare captures returned, expected 3", len(captures))
+	}
+	for i, d := range declares {
+		c := captures[i]
+		if *c.Index != int64(i) || c.Type != d.capture.Type || c.Length != d.capture.Length || *c.SlotID != d.slot {
+			t.Errorf("declare capture %v: %+v not as created", i, c)
+		}
+	}
+
+	rule, err := captures[0].HTTPRequestRule(0, "req.hdr(X-Id)")
+	if err != nil {
+		t.Error(err.Error())
+	} else if *rule.CaptureID != 3 || rule.CaptureSample != "req.hdr(X-Id)" {
+		t.Errorf("http-request capture rule %+v does not reference slot 3", rule)
+	}
+	if _, err := captures[0].HTTPResponseRule(0, "res.hdr(X-Id)"); err == nil {
+		t.Error("Should throw error, request slot referenced by http-response capture")
+	}
+
+	if err := client.EditDeclareCapture(1, "test_2", &DeclareCapture{Type: CaptureResponse, Length: 50}, "", version); err != nil {
+		t.Error(err.Error())
+	} else {
+		version++
+	}
+	_, c, err := client.GetDeclareCapture(1, "test_2", "")
+	if err != nil {
+		t.Error(err.Error())
+	} else if c.Length != 50 || *c.SlotID != 1 {
+		t.Errorf("declare capture %+v not as edited", c)
+	}
+
+	if _, err := client.CreateDeclareCapture("test_2", &DeclareCapture{Type: CaptureRequest}, "", version); err == nil {
+		t.Error("Should throw error, capture length is required")
+	}
+
+	if err := client.DeleteDeclareCapture(0, "test_2", "", version); err != nil {
+		t.Error(err.Error())
+	} else {
+		version++
+	}
+	_, c, err = client.GetDeclareCapture(1, "test_2", "")
+	if err != nil {
+		t.Error(err.Error())
+	} else if c.Length != 30 || *c.SlotID != 3 {
+		t.Errorf("declare capture %+v returned, expected the last declaration at slot 3", c)
+	}
+
+	for i := 1; i >= 0; i-- {
+		if err := client.DeleteDeclareCapture(int64(i), "test_2", "", version); err != nil {
+			t.Error(err.Error())
+		} else {
+			version++
+		}
+	}
+	if err := client.DeleteDeclareCapture(0, "test_2", "", version); err == nil {
+		t.Error("Should throw error, non existent declare capture")
+	}
+}
+
+func TestCreateEditDeleteHeaderCapture(t *testing.T) {
+	// TestCaptureHeader leaves Host and User-Agent request captures at slots 1 and 2 and a Server
+	// response capture at slot 0 in test_2
+	_, captures, err := client.GetHeaderCaptures("test_2", "")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if len(captures) != 3 {
+		t.Fatalf("%v header captures returned, expected 3", len(captures))
+	}
+	if captures[1].Name != "User-Agent" || captures[1].Length != 128 || *captures[1].SlotID != 2 {
+		t.Errorf("header capture %+v returned, expected User-Agent at slot 2", captures[1])
+	}
+
+	index := int64(0)
+	h := &HeaderCapture{Index: &index, Type: CaptureRequest, Name: "X-Forwarded-For", Length: 64}
+	if err := client.CreateHeaderCapture("test_2", h, "", version); err != nil {
+		t.Fatal(err.Error())
+	}
+	version++
+	if err := client.CreateHeaderCapture("test_2", h, "", version); err == nil {
+		t.Error("Should throw error, header already captured")
+		version++
+	}
+
+	_, c, err := client.GetHeaderCapture(0, "test_2", "")
+	if err != nil {
+		t.Error(err.Error())
+	} else if c.Name != "X-Forwarded-For" || *c.SlotID != 1 {
+		t.Errorf("header capture %+v returned, expected X-Forwarded-For at slot 1", c)
+	}
+	if _, c, err = client.GetHeaderCapture(2, "test_2", ""); err != nil {
+		t.Error(err.Error())
+	} else if c.Name != "User-Agent" || *c.SlotID != 3 {
+		t.Errorf("header capture %+v returned, expected User-Agent at slot 3", c)
+	}
+
+	h.Type = CaptureResponse
+	if err := client.EditHeaderCapture(0, "test_2", h, "", version); err != nil {
+		t.Error(err.Error())
+	} else {
+		version++
+	}
+	if _, c, err = client.GetHeaderCapture(0, "test_2", ""); err != nil {
+		t.Error(err.Error())
+	} else if c.Type != CaptureResponse || *c.SlotID != 0 {
+		t.Errorf("header capture %+v returned, expected a response capture at slot 0", c)
+	}
+
+	if err := client.DeleteHeaderCapture(0, "test_2", "", version); err != nil {
+		t.Error(err.Error())
+	} else {
+		version++
+	}
+	if err := client.DeleteHeaderCapture(3, "test_2", "", version); err == nil {
+		t.Error("Should throw error, header capture does not exist")
+		version++
+	}
+	if _, c, err = client.GetHeaderCapture(1, "test_2", ""); err != nil {
+		t.Error(err.Error())
+	} else if c.Name != "User-Agent" || *c.SlotID != 2 {
+		t.Errorf("header capture %+v returned, expected User-Agent at slot 2", c)
+	}
+}
@@ -0,0 +1,159 @@
+// Copyright 2019 HAProxy Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package configuration
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	parser "github.com/haproxytech/config-parser/v3"
+	"github.com/haproxytech/config-parser/v3/types"
+)
+
+// commitInfoPrefix starts the header comment holding the commit info as json
+const commitInfoPrefix = "_commit "
+
+// CommitInfo records who changed the configuration and why. It is kept in a
+// # _commit header comment of the transaction and of the committed configuration.
+type CommitInfo struct {
+	Author      string    `json:"author,omitempty"`
+	Reason      string    `json:"reason,omitempty"`
+	Time        time.Time `json:"time"`
+	Transaction string    `json:"transaction,omitempty"`
+	// Version is the version of the configuration, or the one the transaction started from
+	Version int64 `json:"-"`
+}
+
+// AnnotateTransaction sets the author and the reason of the changes made in the transaction,
+// they are stored in the configuration with the commit time once the transaction is committed.
+// Empty author and reason remove the annotation.
+func (c *Client) AnnotateTransaction(transactionID, author, reason string) error {
+	p, err := c.GetParser(transactionID)
+	if err != nil {
+		return err
+	}
+	if transactionID == "" {
+		return NewConfError(ErrValidationError, "Only transactions can be annotated")
+	}
+
+	var info *CommitInfo
+	if author != "" || reason != "" {
+		info = &CommitInfo{
+			Author:      author,
+			Reason:      reason,
+			Time:        time.Now().UTC(),
+			Transaction: transactionID,
+		}
+	}
+	if err := setCommitInfo(p, info); err != nil {
+		return NewConfError(ErrErrorChangingConfig, err.Error())
+	}
+	return c.saveData(p, transactionID, false)
+}
+
+// GetCommitInfo returns the annotation of the transaction, or of the last commit of the
+// configuration if transactionID is empty
+func (c *Client) GetCommitInfo(transactionID string) (*CommitInfo, error) {
+	p, err := c.GetParser(transactionID)
+	if err != nil {
+		return nil, err
+	}
+
+	info, _ := findCommitInfo(p)
+	if info == nil {
+		if transactionID == "" {
+			return nil, NewConfError(ErrObjectDoesNotExist, "Last commit is not annotated")
+		}
+		return nil, NewConfError(ErrObjectDoesNotExist, fmt.Sprintf("Transaction %s is not annotated", transactionID))
+	}
+
+	info.Version, err = c.GetVersion(transactionID)
+	if err != nil {
+		return nil, err
+	}
+	return info, nil
+}
+
+// clearCommitInfo removes the annotation the transaction inherited from the configuration
+func (c *Client) clearCommitInfo(transactionID string) error {
+	p, err := c.GetParser(transactionID)
+	if err != nil {
+		return err
+	}
+	if info, _ := findCommitInfo(p); info == nil {
+		return nil
+	}
+	if err := setCommitInfo(p, nil); err != nil {
+		return NewConfError(ErrErrorChangingConfig, err.Error())
+	}
+	return c.saveData(p, transactionID, false)
+}
+
+// stampCommitInfo sets the commit time on the transaction annotation before it is committed
+func stampCommitInfo(p *parser.Parser, transactionID string) error {
+	info, _ := findCommitInfo(p)
+	if info == nil {
+		return nil
+	}
+	info.Time = time.Now().UTC()
+	info.Transaction = transactionID
+	return setCommitInfo(p, info)
+}
+
+func findCommitInfo(p *parser.Parser) (*CommitInfo, int) {
+	data, err := p.Get(parser.Comments, parser.CommentsSectionName, "#", false)
+	if err != nil {
+		return nil, -1
+	}
+	comments, ok := data.([]types.Comments)
+	if !ok {
+		return nil, -1
+	}
+	for i, comment := range comments {
+		if !strings.HasPrefix(comment.Value, commitInfoPrefix) {
+			continue
+		}
+		info := &CommitInfo{}
+		if err := json.Unmarshal([]byte(strings.TrimPrefix(comment.Value, commitInfoPrefix)), info); err != nil {
+			continue
+		}
+		return info, i
+	}
+	return nil, -1
+}
+
+// setCommitInfo replaces the _commit header comment, nil removes it
+func setCommitInfo(p *parser.Parser, info *CommitInfo) error {
+	_, i := findCommitInfo(p)
+	if info == nil {
+		if i == -1 {
+			return nil
+		}
+		return p.Delete(parser.Comments, parser.CommentsSectionName, "#", i)
+	}
+
+	data, err := json.Marshal(info)
+	if err != nil {
+		return err
+	}
+	comment := types.Comments{Value: commitInfoPrefix + string(data)}
+	if i == -1 {
+		return p.Insert(parser.Comments, parser.CommentsSectionName, "#", comment, 0)
+	}
+	return p.Set(parser.Comments, parser.CommentsSectionName, "#", comment, i)
+}
@@ -0,0 +1,82 @@
+// Copyright 2019 HAProxy Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package configuration
+
+import (
+	"testing"
+)
+
+func TestCommitInfo(t *testing.T) {
+	tr, err := client.StartTransaction(version)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if err := client.AnnotateTransaction(tr.ID, "jdoe", "enable client ip logging"); err != nil {
+		t.Fatal(err.Error())
+	}
+	if err := client.SetLogFormat(LogFormatDirective, "frontend", "test_2", `"%ci"`, tr.ID, 0); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	info, err := client.GetCommitInfo(tr.ID)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if info.Author != "jdoe" || info.Reason != "enable client ip logging" || info.Version != version {
+		t.Errorf("Unexpected transaction annotation: %+v", info)
+	}
+
+	if _, err := client.CommitTransaction(tr.ID); err != nil {
+		t.Fatal(err.Error())
+	}
+	version++
+
+	info, err = client.GetCommitInfo("")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if info.Author != "jdoe" || info.Reason != "enable client ip logging" {
+		t.Errorf("Unexpected commit annotation: %+v", info)
+	}
+	if info.Transaction != tr.ID || info.Version != version || info.Time.IsZero() {
+		t.Errorf("Unexpected commit annotation: %+v", info)
+	}
+
+	// new transactions do not inherit the annotation of the last commit
+	tr, err = client.StartTransaction(version)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if _, err := client.GetCommitInfo(tr.ID); err == nil {
+		t.Error("Should throw error, transaction not annotated")
+	}
+	if err := client.SetLogFormat(LogFormatDirective, "frontend", "test_2", "", tr.ID, 0); err != nil {
+		t.Fatal(err.Error())
+	}
+	if _, err := client.CommitTransaction(tr.ID); err != nil {
+		t.Fatal(err.Error())
+	}
+	version++
+
+	if _, err := client.GetCommitInfo(""); err == nil {
+		t.Error("Should throw error, last commit not annotated")
+	}
+
+	if err := client.AnnotateTransaction("", "jdoe", "no transaction"); err == nil {
+		t.Error("Should throw error, annotation without transaction")
+	}
+}
@@ -0,0 +1,181 @@
+// Copyright 2019 HAProxy Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package configuration
+
+import (
+	"fmt"
+	"strings"
+
+	parser "github.com/haproxytech/config-parser/v3"
+)
+
+const (
+	compressionAlgo    = "compression algo"
+	compressionType    = "compression type"
+	compressionOffload = "compression offload"
+)
+
+var compressionAlgos = map[string]bool{
+	"identity":    true,
+	"gzip":        true,
+	"deflate":     true,
+	"raw-deflate": true,
+}
+
+// Compression groups the compression directives of a defaults, frontend or backend section.
+// Empty Types compresses every MIME type allowed by HAProxy.
+type Compression struct {
+	Algos   []string
+	Types   []string
+	Offload bool
+}
+
+// Validate checks the algorithms and the MIME types
+func (c *Compression) Validate() error {
+	if len(c.Algos) == 0 {
+		return fmt.Errorf("at least one compression algorithm is required")
+	}
+	for _, a := range c.Algos {
+		if !compressionAlgos[a] {
+			return fmt.Errorf("unsupported compression algorithm %s", a)
+		}
+	}
+	for _, t := range c.Types {
+		if t == "" || strings.ContainsAny(t, " \t#") {
+			return fmt.Errorf("invalid compression type %s", t)
+		}
+	}
+	return nil
+}
+
+// GetCompression returns configuration version and the compression settings of the defaults,
+// frontend or backend section. Returns error on fail or if compression algo is not set.
+func (c *Client) GetCompression(parentType string, parentName string, transactionID string) (int64, *Compression, error) {
+	section, name, err := compressionSection(parentType, parentName)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	p, err := c.GetParser(transactionID)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	v, err := c.GetVersion(transactionID)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	if !c.checkSectionExists(section, name, p) {
+		return v, nil, NewConfError(ErrObjectDoesNotExist, fmt.Sprintf("%s %s does not exist", parentType, parentName))
+	}
+
+	algo, found, err := getRawDirective(p, section, name, compressionAlgo)
+	if err != nil {
+		return v, nil, c.handleError(compressionAlgo, parentType, parentName, "", false, err)
+	}
+	if !found {
+		return v, nil, NewConfError(ErrObjectDoesNotExist, fmt.Sprintf("%s not set in %s %s", compressionAlgo, parentType, parentName))
+	}
+	types, _, err := getRawDirective(p, section, name, compressionType)
+	if err != nil {
+		return v, nil, c.handleError(compressionType, parentType, parentName, "", false, err)
+	}
+	_, offload, err := getRawDirective(p, section, name, compressionOffload)
+	if err != nil {
+		return v, nil, c.handleError(compressionOffload, parentType, parentName, "", false, err)
+	}
+
+	return v, &Compression{
+		Algos:   strings.Fields(algo),
+		Types:   strings.Fields(types),
+		Offload: offload,
+	}, nil
+}
+
+// SetCompression replaces the compression settings of the defaults, frontend or backend section,
+// nil data removes them. One of version or transactionID is mandatory. Returns error on fail,
+// nil on success.
+func (c *Client) SetCompression(parentType string, parentName string, data *Compression, transactionID string, version int64) error {
+	section, name, err := compressionSection(parentType, parentName)
+	if err != nil {
+		return err
+	}
+	if data != nil {
+		if err := data.Validate(); err != nil {
+			return NewConfError(ErrValidationError, err.Error())
+		}
+	}
+
+	if err := c.authorize("SetCompression", "compression", "", parentType, parentName, transactionID); err != nil {
+		return err
+	}
+
+	p, t, err := c.loadDataForChange(transactionID, version)
+	if err != nil {
+		return err
+	}
+
+	if !c.checkSectionExists(section, name, p) {
+		e := NewConfError(ErrObjectDoesNotExist, fmt.Sprintf("%s %s does not exist", parentType, parentName))
+		return c.handleError("compression", parentType, parentName, t, transactionID == "", e)
+	}
+
+	var algo, types, offload *string
+	if data != nil {
+		a := strings.Join(data.Algos, " ")
+		algo = &a
+		if len(data.Types) > 0 {
+			t := strings.Join(data.Types, " ")
+			types = &t
+		}
+		if data.Offload {
+			o := ""
+			offload = &o
+		}
+	}
+	directives := []struct {
+		keyword string
+		value   *string
+	}{
+		{compressionAlgo, algo},
+		{compressionType, types},
+		{compressionOffload, offload},
+	}
+	for _, d := range directives {
+		if err := setRawDirective(p, section, name, d.keyword, d.value); err != nil {
+			return c.handleError(d.keyword, parentType, parentName, t, transactionID == "", err)
+		}
+	}
+
+	if err := c.saveData(p, t, transactionID == ""); err != nil {
+		return err
+	}
+	return nil
+}
+
+func compressionSection(parentType string, parentName string) (parser.Section, string, error) {
+	switch parentType {
+	case "defaults":
+		return parser.Defaults, parser.DefaultSectionName, nil
+	case "frontend":
+		return parser.Frontends, parentName, nil
+	case "backend":
+		return parser.Backends, parentName, nil
+	default:
+		return "", "", NewConfError(ErrValidationError, fmt.Sprintf("compression is not supported in %s", parentType))
+	}
+}
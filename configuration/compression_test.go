@@ -0,0 +1,72 @@
+// Copyright 2019 HAProxy Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package configuration
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCompression(t *testing.T) {
+	if _, _, err := client.GetCompression("backend", "test", ""); err == nil {
+		t.Error("Should throw error, compression not set in backend test")
+	}
+	if err := client.SetCompression("backend", "test", &Compression{Algos: []string{"brotli"}}, "", version); err == nil {
+		t.Error("Should throw error, unsupported compression algorithm")
+	}
+
+	c := &Compression{
+		Algos:   []string{"gzip", "deflate"},
+		Types:   []string{"text/html", "application/json"},
+		Offload: true,
+	}
+	if err := client.SetCompression("backend", "test", c, "", version); err != nil {
+		t.Error(err.Error())
+	} else {
+		version++
+	}
+
+	v, compression, err := client.GetCompression("backend", "test", "")
+	if err != nil {
+		t.Error(err.Error())
+	}
+	if !reflect.DeepEqual(compression, c) {
+		t.Errorf("Compression %v not equal to given %v", compression, c)
+	}
+	if v != version {
+		t.Errorf("Version %v returned, expected %v", v, version)
+	}
+
+	c = &Compression{Algos: []string{"gzip"}}
+	if err := client.SetCompression("backend", "test", c, "", version); err != nil {
+		t.Error(err.Error())
+	} else {
+		version++
+	}
+	_, compression, _ = client.GetCompression("backend", "test", "")
+	if !reflect.DeepEqual(compression, &Compression{Algos: []string{"gzip"}, Types: []string{}}) {
+		t.Errorf("Compression %v not equal to given %v", compression, c)
+	}
+
+	if err := client.SetCompression("backend", "test", nil, "", version); err != nil {
+		t.Error(err.Error())
+	} else {
+		version++
+	}
+	if _, _, err := client.GetCompression("backend", "test", ""); err == nil {
+		t.Error("Should throw error, compression removed from backend test")
+	}
+}
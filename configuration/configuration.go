@@ -62,6 +62,9 @@ type ClientParams struct {
 	ValidateConfigurationFile bool
 	MasterWorker              bool
 	SkipFailedTransactions    bool
+	// ValidateReferencedFiles checks on commit that the CA and CRL files used by binds and
+	// servers exist
+	ValidateReferencedFiles bool
 }
 
 // Client configuration client
@@ -72,10 +75,18 @@ type ClientParams struct {
 // data to file on every change for persistence.
 type Client struct {
 	ClientParams
-	parsers  map[string]*parser.Parser
-	services map[string]*Service
-	Parser   *parser.Parser
-	mu       sync.Mutex
+	parsers         map[string]*parser.Parser
+	services        map[string]*Service
+	Parser          *parser.Parser
+	mu              sync.Mutex
+	reloader        Reloader
+	lastReload      *ReloadResult
+	reloadMu        sync.Mutex
+	versionStrategy VersionStrategy
+	audit           auditLog
+	authz           authorization
+	passwordHash    misc.PasswordHashMethod
+	haproxyVersion  string
 }
 
 // DefaultClient returns Client with sane defaults
@@ -122,12 +133,15 @@ func (c *Client) Init(options ClientParams) error {
 		return err
 	}
 
+	if c.Parser != nil {
+		forgetDefaultsSections(c.Parser)
+	}
 	c.Parser = &parser.Parser{
 		Options: parser.Options{
 			UseV2HTTPCheck: true,
 		},
 	}
-	if err := c.Parser.LoadData(options.ConfigurationFile); err != nil {
+	if err := loadConfig(c.Parser, options.ConfigurationFile); err != nil {
 		return NewConfError(ErrCannotReadConfFile, fmt.Sprintf("Cannot read %s", c.ConfigurationFile))
 	}
 
@@ -171,9 +185,16 @@ func (c *Client) AddParser(transaction string) error {
 	} else {
 		tFile = c.ConfigurationFile
 	}
-	if err := p.LoadData(tFile); err != nil {
+	if err := loadConfig(p, tFile); err != nil {
 		return NewConfError(ErrCannotReadConfFile, fmt.Sprintf("Cannot read %s", tFile))
 	}
+	// the transaction file is written from a configuration whose defaults sections could
+	// not be kept, changes are refused as well
+	if c.Parser != nil {
+		if err := defaultsSectionsErr(c.Parser); err != nil {
+			getDefaultsSections(p).err = err
+		}
+	}
 	c.parsers[transaction] = p
 	return nil
 }
@@ -183,11 +204,14 @@ func (c *Client) DeleteParser(transaction string) error {
 	if transaction == "" {
 		return NewConfError(ErrValidationError, fmt.Sprintf("Not a valid transaction"))
 	}
-	_, ok := c.parsers[transaction]
+	p, ok := c.parsers[transaction]
 	if !ok {
 		return NewConfError(ErrTransactionDoesNotExist, fmt.Sprintf("Transaction %s does not exist", transaction))
 	}
+	forgetDefaultsSections(p)
 	delete(c.parsers, transaction)
+	c.auditForget(transaction)
+	c.forgetContext(transaction)
 	return nil
 }
 
@@ -200,6 +224,7 @@ func (c *Client) CommitParser(transaction string) error {
 	if !ok {
 		return NewConfError(ErrTransactionDoesNotExist, fmt.Sprintf("Transaction %s does not exist", transaction))
 	}
+	forgetDefaultsSections(c.Parser)
 	c.Parser = p
 	delete(c.parsers, transaction)
 	return nil
@@ -224,7 +249,7 @@ func (c *Client) InitTransactionParsers() error {
 		if err != nil {
 			return err
 		}
-		if err := p.LoadData(tFile); err != nil {
+		if err := loadConfig(p, tFile); err != nil {
 			return NewConfError(ErrCannotReadConfFile, fmt.Sprintf("Cannot read %s", tFile))
 		}
 	}
@@ -242,20 +267,14 @@ func (c *Client) getVersion(transaction string) (int64, error) {
 		return 0, NewConfError(ErrCannotReadVersion, fmt.Sprintf("Cannot read version: %s", err.Error()))
 	}
 
-	data, _ := p.Get(parser.Comments, parser.CommentsSectionName, "# _version", true)
-	ver, _ := data.(*types.ConfigVersion)
-	return ver.Value, nil
-}
-
-func (c *Client) incrementVersion() error {
-	data, _ := c.Parser.Get(parser.Comments, parser.CommentsSectionName, "# _version", true)
-	ver, _ := data.(*types.ConfigVersion)
-	ver.Value = ver.Value + 1
-
-	if err := c.Parser.Save(c.ConfigurationFile); err != nil {
-		return NewConfError(ErrCannotSetVersion, fmt.Sprintf("Cannot set version: %s", err.Error()))
+	if transaction != "" {
+		return commentVersion(p), nil
 	}
-	return nil
+	v, err := c.getVersionStrategy().Version(p, c.ConfigurationFile)
+	if err != nil {
+		return 0, NewConfError(ErrCannotReadVersion, fmt.Sprintf("Cannot read version: %s", err.Error()))
+	}
+	return v, nil
 }
 
 func (c *Client) checkTransactionOrVersion(transactionID string, version int64) (string, error) {
@@ -392,19 +411,8 @@ func parseField(section parser.Section, sectionName string, fieldName string, p
 		}
 	}
 	if fieldName == "HTTPCheck" {
-		data, err := p.Get(section, sectionName, "http-check", false)
-		if err != nil {
-			return nil
-		}
-		d := data.([]types.HTTPCheckV2)
 		if section == parser.Defaults || section == parser.Backends {
-			hc := &models.HTTPCheck{}
-			for _, h := range d {
-				hc.ExclamationMark = h.ExclamationMark
-				hc.Match = h.Match
-				hc.Pattern = h.Pattern
-				hc.Type = &h.Type
-			}
+			hc, _ := legacyHTTPCheck(section, sectionName, p)
 			return hc
 		}
 		return nil
@@ -843,8 +851,7 @@ func parseField(section parser.Section, sectionName string, fieldName string, p
 	}
 
 	if fieldName == "UniqueIDHeader" {
-		_, e := p.Get(section, sectionName, "unique-id-format")
-		if e != nil {
+		if !hasUniqueIDFormat(p, section, sectionName) {
 			return nil
 		}
 		data, err := p.Get(section, sectionName, "unique-id-header")
@@ -954,14 +961,33 @@ func setFieldValue(section parser.Section, sectionName string, fieldName string,
 			return p.Set(section, sectionName, "monitor fail", nil)
 		}
 		opt := field.Elem().Interface().(models.MonitorFail)
+		// the parser keeps a single monitor fail line, conditions have to be combined in it
+		if opt.Cond == nil || opt.CondTest == nil || *opt.CondTest == "" {
+			return NewConfError(ErrValidationError, fmt.Sprintf("%s monitor fail requires a condition and a condition test", sectionName))
+		}
 		return p.Set(section, sectionName, "monitor fail", types.MonitorFail{
 			Condition: *opt.Cond,
 			ACLList:   strings.Split(*opt.CondTest, " "),
 		})
 	}
 	if fieldName == "StatsOptions" {
+		// keep the stats settings not covered by the model, such as credentials and admin rules
+		kept := []types.StatsSettings{}
+		if data, err := p.Get(section, sectionName, "stats", false); err == nil {
+			for _, s := range data.([]types.StatsSettings) {
+				switch s.(type) {
+				case *stats.OneWord, *stats.ShowDesc, *stats.Refresh, *stats.ShowNode, *stats.URI, *stats.MaxConn:
+					continue
+				}
+				kept = append(kept, s)
+			}
+		}
 		if valueIsNil(field) {
-			if err := p.Set(section, sectionName, "stats", nil); err != nil {
+			var value interface{}
+			if len(kept) > 0 {
+				value = kept
+			}
+			if err := p.Set(section, sectionName, "stats", value); err != nil {
 				return err
 			}
 			return nil
@@ -995,7 +1021,7 @@ func setFieldValue(section parser.Section, sectionName string, fieldName string,
 		}
 		if opt.StatsRefreshDelay != nil {
 			s := &stats.Refresh{
-				Delay: strconv.FormatInt(*opt.StatsRefreshDelay, 10),
+				Delay: strconv.FormatInt(*opt.StatsRefreshDelay, 10) + "ms",
 			}
 			ss = append(ss, s)
 		}
@@ -1022,6 +1048,7 @@ func setFieldValue(section parser.Section, sectionName string, fieldName string,
 			}
 			ss = append(ss, s)
 		}
+		ss = append(ss, kept...)
 		if err := p.Set(section, sectionName, "stats", ss); err != nil {
 			return err
 		}
@@ -1051,22 +1078,9 @@ func setFieldValue(section parser.Section, sectionName string, fieldName string,
 	if fieldName == "HTTPCheck" {
 		if section == parser.Defaults || section == parser.Backends {
 			if valueIsNil(field) {
-				if err := p.Set(section, sectionName, "http-check", nil); err != nil {
-					return err
-				}
-				return nil
-			}
-			hc := field.Interface().(*models.HTTPCheck)
-			d := &types.HTTPCheckV2{
-				Match:           hc.Match,
-				ExclamationMark: hc.ExclamationMark,
-				Pattern:         hc.Pattern,
-				Type:            *hc.Type,
-			}
-
-			if err := p.Set(section, sectionName, "http-check", d); err != nil {
-				return err
+				return setLegacyHTTPCheck(section, sectionName, nil, p)
 			}
+			return setLegacyHTTPCheck(section, sectionName, field.Interface().(*models.HTTPCheck), p)
 		}
 		return nil
 	}
@@ -1078,6 +1092,13 @@ func setFieldValue(section parser.Section, sectionName string, fieldName string,
 			}
 			return nil
 		}
+		forwarded, err := hasForwarded(p, section, sectionName)
+		if err != nil {
+			return err
+		}
+		if forwarded {
+			return NewConfError(ErrValidationError, fmt.Sprintf("%s has option forwarded, option forwardfor can not be set", sectionName))
+		}
 		ff := field.Elem().Interface().(models.Forwardfor)
 		d := &types.OptionForwardFor{
 			Except: ff.Except,
@@ -1099,10 +1120,13 @@ func setFieldValue(section parser.Section, sectionName string, fieldName string,
 			}
 			br := field.Elem().Interface().(models.Redispatch)
 			d := &types.OptionRedispatch{
-				Interval: &br.Interval,
 				NoOption: false,
 			}
-			if *br.Enabled == "disabled" {
+			// interval 0 disables redispatching, keep the default of redispatching on the last retry
+			if br.Interval != 0 {
+				d.Interval = &br.Interval
+			}
+			if br.Enabled != nil && *br.Enabled == "disabled" {
 				d.NoOption = true
 			}
 			if err := p.Set(section, sectionName, "option redispatch", d); err != nil {
@@ -1120,6 +1144,9 @@ func setFieldValue(section parser.Section, sectionName string, fieldName string,
 				return nil
 			}
 			b := field.Elem().Interface().(models.Balance)
+			if err := validateBalance(b); err != nil {
+				return NewConfError(ErrValidationError, err.Error())
+			}
 			d := types.Balance{
 				Algorithm: *b.Algorithm,
 			}
@@ -1636,6 +1663,9 @@ func setFieldValue(section parser.Section, sectionName string, fieldName string,
 				pExternalCheck = nil
 			} else if field.String() == "disabled" {
 				pExternalCheck.NoOption = true
+			} else if _, err := p.Get(parser.Global, parser.GlobalSectionName, "external-check"); err != nil {
+				// HAProxy refuses external checks unless they are enabled in the global section
+				return NewConfError(ErrValidationError, "option external-check requires external-check in the global section")
 			}
 			if err := p.Set(section, sectionName, "option external-check", pExternalCheck); err != nil {
 				return err
@@ -1761,6 +1791,13 @@ func setFieldValue(section parser.Section, sectionName string, fieldName string,
 			}
 			return nil
 		}
+		if strings.ContainsAny(field.String(), " \t#") {
+			return NewConfError(ErrValidationError, fmt.Sprintf("invalid unique-id-header %s", field.String()))
+		}
+		// the header carries the unique id, it is not sent without a format
+		if !hasUniqueIDFormat(p, section, sectionName) {
+			return NewConfError(ErrValidationError, "unique-id-header requires unique-id-format")
+		}
 		d := types.UniqueIDHeader{
 			Name: field.String(),
 		}
@@ -1826,16 +1863,10 @@ func setFieldValue(section parser.Section, sectionName string, fieldName string,
 	if strings.HasSuffix(fieldName, "Timeout") {
 		if pName := translateTimeout(fieldName); p.HasParser(section, pName) {
 			if valueIsNil(field) {
-				if err := p.Set(section, sectionName, pName, nil); err != nil {
-					return err
-				}
-				return nil
-			}
-			t := &types.SimpleTimeout{}
-			t.Value = strconv.FormatInt(field.Elem().Int(), 10)
-			if err := p.Set(section, sectionName, pName, t); err != nil {
-				return err
+				return setTimeout(p, section, sectionName, pName, nil)
 			}
+			t := field.Elem().Int()
+			return setTimeout(p, section, sectionName, pName, &t)
 		}
 		return nil
 	}
@@ -1912,7 +1943,11 @@ func (c *Client) errAndDeleteTransaction(err error, tID string) error {
 	return err
 }
 
-func (c *Client) deleteSection(section parser.Section, name string, transactionID string, version int64) error {
+func (c *Client) deleteSection(operation string, section parser.Section, name string, transactionID string, version int64) error {
+	if err := c.authorize(operation, string(section), authorizedSectionName(section, name), "", "", transactionID); err != nil {
+		return err
+	}
+
 	p, t, err := c.loadDataForChange(transactionID, version)
 	if err != nil {
 		return err
@@ -1934,7 +1969,11 @@ func (c *Client) deleteSection(section parser.Section, name string, transactionI
 	return nil
 }
 
-func (c *Client) editSection(section parser.Section, name string, data interface{}, transactionID string, version int64) error {
+func (c *Client) editSection(operation string, section parser.Section, name string, data interface{}, transactionID string, version int64) error {
+	if err := c.authorize(operation, string(section), authorizedSectionName(section, name), "", "", transactionID); err != nil {
+		return err
+	}
+
 	p, t, err := c.loadDataForChange(transactionID, version)
 	if err != nil {
 		return err
@@ -1956,7 +1995,11 @@ func (c *Client) editSection(section parser.Section, name string, data interface
 	return nil
 }
 
-func (c *Client) createSection(section parser.Section, name string, data interface{}, transactionID string, version int64) error {
+func (c *Client) createSection(operation string, section parser.Section, name string, data interface{}, transactionID string, version int64) error {
+	if err := c.authorize(operation, string(section), authorizedSectionName(section, name), "", "", transactionID); err != nil {
+		return err
+	}
+
 	p, t, err := c.loadDataForChange(transactionID, version)
 	if err != nil {
 		return err
@@ -2005,23 +2048,28 @@ func (c *Client) loadDataForChange(transactionID string, version int64) (*parser
 	}
 
 	p, err := c.GetParser(t)
+	if err == nil {
+		err = defaultsSectionsErr(p)
+	}
 	if err != nil {
 		if transactionID == "" && t != "" {
 			return nil, "", c.errAndDeleteTransaction(err, t)
 		}
 		return nil, "", err
 	}
+	c.auditSnapshot(p, t)
 	return p, t, nil
 }
 
 func (c *Client) saveData(p *parser.Parser, t string, commitImplicit bool) error {
+	getDefaultsSections(p).prune(p)
 	if c.PersistentTransactions {
 		tFile, err := c.getTransactionFile(t)
 		if err != nil {
 			return err
 		}
 
-		if err := p.Save(tFile); err != nil {
+		if err := saveConfig(p, tFile); err != nil {
 			e := NewConfError(ErrErrorChangingConfig, err.Error())
 			if commitImplicit {
 				return c.errAndDeleteTransaction(e, t)
@@ -2029,6 +2077,7 @@ func (c *Client) saveData(p *parser.Parser, t string, commitImplicit bool) error
 			return err
 		}
 	}
+	c.auditChange(p, t)
 
 	if commitImplicit {
 		if _, err := c.CommitTransaction(t); err != nil {
@@ -2088,3 +2137,73 @@ func translateTimeout(mName string) string {
 	mName = strings.TrimSuffix(mName, "Timeout")
 	return fmt.Sprintf("timeout %s", misc.DashCase(mName))
 }
+
+// hasUniqueIDFormat returns true if the section or, for frontends, the defaults section sets
+// unique-id-format
+func hasUniqueIDFormat(p *parser.Parser, section parser.Section, sectionName string) bool {
+	if _, err := p.Get(section, sectionName, "unique-id-format"); err == nil {
+		return true
+	}
+	if section == parser.Frontends {
+		_, err := p.Get(parser.Defaults, parser.DefaultSectionName, "unique-id-format")
+		return err == nil
+	}
+	return false
+}
+
+// validateBalance checks that the algorithm is set and that only its own parameters are, hdr
+// and url_param require their name
+func validateBalance(b models.Balance) error {
+	if b.Algorithm == nil || *b.Algorithm == "" {
+		return fmt.Errorf("balance algorithm is required")
+	}
+	a := *b.Algorithm
+	balanceParams := []struct {
+		name      string
+		set       bool
+		algorithm string
+	}{
+		{"uri len", b.URILen != 0, "uri"},
+		{"uri depth", b.URIDepth != 0, "uri"},
+		{"uri whole", b.URIWhole, "uri"},
+		{"url_param", b.URLParam != "", "url_param"},
+		{"url_param check_post", b.URLParamCheckPost != 0, "url_param"},
+		{"url_param max_wait", b.URLParamMaxWait != 0, "url_param"},
+		{"hdr name", b.HdrName != "", "hdr"},
+		{"hdr use_domain_only", b.HdrUseDomainOnly, "hdr"},
+		{"random draws", b.RandomDraws != 0, "random"},
+		{"rdp-cookie name", b.RdpCookieName != "", "rdp-cookie"},
+	}
+	for _, prm := range balanceParams {
+		if prm.set && prm.algorithm != a {
+			return fmt.Errorf("balance %s: %s only applies to %s", a, prm.name, prm.algorithm)
+		}
+	}
+	for name, value := range map[string]int64{
+		"uri len": b.URILen, "uri depth": b.URIDepth, "url_param check_post": b.URLParamCheckPost,
+		"url_param max_wait": b.URLParamMaxWait, "random draws": b.RandomDraws,
+	} {
+		if value < 0 {
+			return fmt.Errorf("balance %s: %s can not be negative", a, name)
+		}
+	}
+	switch a {
+	case "hdr":
+		if b.HdrName == "" {
+			return fmt.Errorf("balance hdr: header name is required")
+		}
+	case "url_param":
+		if b.URLParam == "" {
+			return fmt.Errorf("balance url_param: parameter name is required")
+		}
+		if b.URLParamMaxWait != 0 && b.URLParamCheckPost == 0 {
+			return fmt.Errorf("balance url_param: max_wait requires check_post")
+		}
+	}
+	for _, name := range []string{b.HdrName, b.URLParam, b.RdpCookieName} {
+		if strings.ContainsAny(name, " \t#()") {
+			return fmt.Errorf("balance %s: invalid name %s", a, name)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,216 @@
+// Copyright 2019 HAProxy Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package configuration
+
+import (
+	"fmt"
+	"strconv"
+
+	parser "github.com/haproxytech/config-parser/v3"
+	parser_errors "github.com/haproxytech/config-parser/v3/errors"
+	"github.com/haproxytech/config-parser/v3/types"
+	"github.com/haproxytech/models/v2"
+
+	"github.com/haproxytech/client-native/v2/misc"
+)
+
+// ServerLimits are the connection limits of a server
+type ServerLimits struct {
+	Name     string `json:"name"`
+	Maxconn  *int64 `json:"maxconn,omitempty"`
+	Maxqueue *int64 `json:"maxqueue,omitempty"`
+	Minconn  *int64 `json:"minconn,omitempty"`
+}
+
+// ConnectionLimits are the connection and queueing limits of a backend and its servers
+type ConnectionLimits struct {
+	Fullconn     *int64          `json:"fullconn,omitempty"`
+	QueueTimeout *int64          `json:"queue_timeout,omitempty"`
+	Servers      []*ServerLimits `json:"servers,omitempty"`
+}
+
+// Validate checks that the limits are positive and that minconn of a server does not exceed its
+// maxconn. Servers may get maxconn and minconn from default-server lines and server templates,
+// so the limits are not checked against each other across servers.
+func (l *ConnectionLimits) Validate() error {
+	if l.Fullconn != nil && *l.Fullconn <= 0 {
+		return fmt.Errorf("fullconn has to be greater than 0")
+	}
+	if l.QueueTimeout != nil && *l.QueueTimeout < 0 {
+		return fmt.Errorf("timeout queue can not be negative")
+	}
+	for _, s := range l.Servers {
+		if s.Maxconn != nil && *s.Maxconn < 0 || s.Maxqueue != nil && *s.Maxqueue < 0 || s.Minconn != nil && *s.Minconn < 0 {
+			return fmt.Errorf("limits of server %s can not be negative", s.Name)
+		}
+		if s.Minconn != nil && s.Maxconn != nil && *s.Minconn > *s.Maxconn {
+			return fmt.Errorf("minconn of server %s is greater than maxconn", s.Name)
+		}
+	}
+	return nil
+}
+
+// GetConnectionLimits returns configuration version and the connection limits of the backend and
+// all of its servers. Returns error on fail.
+func (c *Client) GetConnectionLimits(backend string, transactionID string) (int64, *ConnectionLimits, error) {
+	p, err := c.GetParser(transactionID)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	v, err := c.GetVersion(transactionID)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	if !c.checkSectionExists(parser.Backends, backend, p) {
+		return v, nil, NewConfError(ErrObjectDoesNotExist, fmt.Sprintf("Backend %s does not exist", backend))
+	}
+
+	limits, err := parseConnectionLimits(backend, p)
+	if err != nil {
+		return v, nil, c.handleError(backend, "", "", "", false, err)
+	}
+	return v, limits, nil
+}
+
+// SetConnectionLimits sets fullconn and timeout queue of the backend and the limits of the given
+// servers, unset values are removed. Servers that are not given keep their limits. The limits
+// resulting from the change are validated together. One of version or transactionID is
+// mandatory. Returns error on fail, nil on success.
+func (c *Client) SetConnectionLimits(backend string, data *ConnectionLimits, transactionID string, version int64) error {
+	if err := c.authorize("SetConnectionLimits", "connection_limits", "", "backend", backend, transactionID); err != nil {
+		return err
+	}
+
+	p, t, err := c.loadDataForChange(transactionID, version)
+	if err != nil {
+		return err
+	}
+
+	if !c.checkSectionExists(parser.Backends, backend, p) {
+		e := NewConfError(ErrObjectDoesNotExist, fmt.Sprintf("Backend %s does not exist", backend))
+		return c.handleError(backend, "", "", t, transactionID == "", e)
+	}
+
+	servers, err := ParseServers(backend, p)
+	if err != nil {
+		return c.handleError(backend, "", "", t, transactionID == "", err)
+	}
+
+	for _, sl := range data.Servers {
+		found := false
+		for _, s := range servers {
+			if s.Name == sl.Name {
+				s.Maxconn = sl.Maxconn
+				s.Maxqueue = sl.Maxqueue
+				s.Minconn = sl.Minconn
+				found = true
+				break
+			}
+		}
+		if !found {
+			e := NewConfError(ErrObjectDoesNotExist, fmt.Sprintf("Server %s does not exist in backend %s", sl.Name, backend))
+			return c.handleError(sl.Name, "backend", backend, t, transactionID == "", e)
+		}
+	}
+
+	result := &ConnectionLimits{
+		Fullconn:     data.Fullconn,
+		QueueTimeout: data.QueueTimeout,
+		Servers:      serverLimits(servers),
+	}
+	if err := result.Validate(); err != nil {
+		e := NewConfError(ErrValidationError, err.Error())
+		return c.handleError(backend, "", "", t, transactionID == "", e)
+	}
+
+	var fullconn *string
+	if data.Fullconn != nil {
+		fullconn = misc.StringP(strconv.FormatInt(*data.Fullconn, 10))
+	}
+	if err := setRawDirective(p, parser.Backends, backend, "fullconn", fullconn); err != nil {
+		return c.handleError(backend, "", "", t, transactionID == "", err)
+	}
+
+	var timeout interface{}
+	if data.QueueTimeout != nil {
+		timeout = &types.SimpleTimeout{Value: strconv.FormatInt(*data.QueueTimeout, 10)}
+	}
+	if err := p.Set(parser.Backends, backend, "timeout queue", timeout); err != nil {
+		return c.handleError(backend, "", "", t, transactionID == "", err)
+	}
+
+	for _, sl := range data.Servers {
+		for i, s := range servers {
+			if s.Name != sl.Name {
+				continue
+			}
+			if err := p.Set(parser.Backends, backend, "server", SerializeServer(*s), i); err != nil {
+				return c.handleError(s.Name, "backend", backend, t, transactionID == "", err)
+			}
+		}
+	}
+
+	if err := c.saveData(p, t, transactionID == ""); err != nil {
+		return err
+	}
+	return nil
+}
+
+func parseConnectionLimits(backend string, p *parser.Parser) (*ConnectionLimits, error) {
+	limits := &ConnectionLimits{}
+
+	fullconn, found, err := getRawDirective(p, parser.Backends, backend, "fullconn")
+	if err != nil {
+		return nil, err
+	}
+	if found {
+		f, err := strconv.ParseInt(fullconn, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid fullconn %s", fullconn)
+		}
+		limits.Fullconn = &f
+	}
+
+	data, err := p.Get(parser.Backends, backend, "timeout queue", false)
+	if err != nil && err != parser_errors.ErrFetch {
+		return nil, err
+	}
+	if err == nil {
+		limits.QueueTimeout = misc.ParseTimeout(data.(*types.SimpleTimeout).Value)
+	}
+
+	servers, err := ParseServers(backend, p)
+	if err != nil {
+		return nil, err
+	}
+	limits.Servers = serverLimits(servers)
+	return limits, nil
+}
+
+func serverLimits(servers models.Servers) []*ServerLimits {
+	limits := make([]*ServerLimits, 0, len(servers))
+	for _, s := range servers {
+		limits = append(limits, &ServerLimits{
+			Name:     s.Name,
+			Maxconn:  s.Maxconn,
+			Maxqueue: s.Maxqueue,
+			Minconn:  s.Minconn,
+		})
+	}
+	return limits
+}
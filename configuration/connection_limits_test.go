@@ -0,0 +1,110 @@
+// Copyright 2019 HAProxy Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package configuration
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/haproxytech/client-native/v2/misc"
+)
+
+func TestSetGetConnectionLimits(t *testing.T) {
+	l := &ConnectionLimits{
+		Fullconn:     misc.Int64P(500),
+		QueueTimeout: misc.Int64P(3000),
+		Servers: []*ServerLimits{
+			{Name: "webserv", Maxconn: misc.Int64P(300), Maxqueue: misc.Int64P(50), Minconn: misc.Int64P(100)},
+		},
+	}
+
+	err := client.SetConnectionLimits("test", l, "", version)
+	if err != nil {
+		t.Error(err.Error())
+	} else {
+		version++
+	}
+
+	v, limits, err := client.GetConnectionLimits("test", "")
+	if err != nil {
+		t.Error(err.Error())
+	} else {
+		expected := &ConnectionLimits{
+			Fullconn:     l.Fullconn,
+			QueueTimeout: l.QueueTimeout,
+			Servers: []*ServerLimits{
+				l.Servers[0],
+				{Name: "webserv2", Maxconn: misc.Int64P(1000)},
+			},
+		}
+		if !reflect.DeepEqual(limits, expected) {
+			fmt.Printf("Set connection limits: %v\n", limits)
+			fmt.Printf("Given connection limits: %v\n", expected)
+			t.Error("Set connection limits not equal to given connection limits")
+		}
+	}
+
+	if v != version {
+		t.Errorf("Version %v returned, expected %v", v, version)
+	}
+
+	l.Servers[0].Minconn = misc.Int64P(400)
+	err = client.SetConnectionLimits("test", l, "", version)
+	if err == nil {
+		t.Error("Should throw error, minconn greater than maxconn")
+		version++
+	}
+
+	l.Servers[0].Minconn = misc.Int64P(-1)
+	err = client.SetConnectionLimits("test", l, "", version)
+	if err == nil {
+		t.Error("Should throw error, negative minconn")
+		version++
+	}
+
+	// maxconn and minconn may come from default-server, the limits read back can be set again
+	l.Servers[0].Maxconn = nil
+	l.Servers[0].Minconn = misc.Int64P(100)
+	err = client.SetConnectionLimits("test", l, "", version)
+	if err != nil {
+		t.Error(err.Error())
+	} else {
+		version++
+	}
+	_, limits, err = client.GetConnectionLimits("test", "")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if err := client.SetConnectionLimits("test", limits, "", version); err != nil {
+		t.Error(err.Error())
+	} else {
+		version++
+	}
+
+	// restore the original limits
+	l = &ConnectionLimits{
+		Servers: []*ServerLimits{
+			{Name: "webserv", Maxconn: misc.Int64P(1000)},
+		},
+	}
+	err = client.SetConnectionLimits("test", l, "", version)
+	if err != nil {
+		t.Error(err.Error())
+	} else {
+		version++
+	}
+}
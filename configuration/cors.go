@@ -0,0 +1,432 @@
+// Copyright 2019 HAProxy Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package configuration
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	strfmt "github.com/go-openapi/strfmt"
+	"github.com/haproxytech/client-native/v2/misc"
+	parser "github.com/haproxytech/config-parser/v3"
+	"github.com/haproxytech/models/v2"
+)
+
+// CORSPolicy is a Cross-Origin Resource Sharing policy of a frontend. The request Origin
+// is matched against AllowedOrigins and stored in a transaction variable, responses to
+// matched requests get the Access-Control-* headers. Preflight OPTIONS requests of matched
+// origins carrying Access-Control-Request-Method are answered by HAProxy with a 204 holding
+// the allow headers, using http-request return which needs HAProxy 2.2 or later.
+//
+// The config parser has no return action, so the preflight rule is kept as an unprocessed line
+// which is written after all other http-request rules of the frontend. Rules denying,
+// redirecting or authenticating requests are evaluated first and answer preflight requests
+// they match, so they have to exclude them, e.g. with unless METH_OPTIONS.
+type CORSPolicy struct {
+	// Name of the policy, used for the origin acl and variable names
+	Name string `json:"name"`
+	// AllowedOrigins are exact origins allowed, * allows any origin
+	AllowedOrigins []string `json:"allowed_origins"`
+	// AllowedMethods returned in Access-Control-Allow-Methods
+	AllowedMethods []string `json:"allowed_methods,omitempty"`
+	// AllowedHeaders returned in Access-Control-Allow-Headers
+	AllowedHeaders []string `json:"allowed_headers,omitempty"`
+	// ExposedHeaders returned in Access-Control-Expose-Headers
+	ExposedHeaders []string `json:"exposed_headers,omitempty"`
+	// AllowCredentials sets Access-Control-Allow-Credentials
+	AllowCredentials bool `json:"allow_credentials,omitempty"`
+	// MaxAge in seconds returned in Access-Control-Max-Age
+	MaxAge *int64 `json:"max_age,omitempty"`
+}
+
+var corsNameRegex = regexp.MustCompile(`^[a-zA-Z0-9_]+$`)
+
+const (
+	corsAllowOrigin      = "Access-Control-Allow-Origin"
+	corsAllowMethods     = "Access-Control-Allow-Methods"
+	corsAllowHeaders     = "Access-Control-Allow-Headers"
+	corsExposeHeaders    = "Access-Control-Expose-Headers"
+	corsAllowCredentials = "Access-Control-Allow-Credentials"
+	corsMaxAge           = "Access-Control-Max-Age"
+)
+
+// Validate validates the CORS policy
+func (r *CORSPolicy) Validate(formats strfmt.Registry) error {
+	if !corsNameRegex.MatchString(r.Name) {
+		return fmt.Errorf("invalid name %s, only letters, digits and _ are allowed", r.Name)
+	}
+	if len(r.AllowedOrigins) == 0 {
+		return fmt.Errorf("allowed_origins is required")
+	}
+	for _, list := range [][]string{r.AllowedOrigins, r.AllowedMethods, r.AllowedHeaders, r.ExposedHeaders} {
+		for _, v := range list {
+			if v == "" || strings.ContainsAny(v, " \t,") {
+				return fmt.Errorf("invalid value '%s', values can not contain spaces or commas", v)
+			}
+		}
+	}
+	if r.AllowCredentials && misc.StringInSlice("*", r.AllowedOrigins) {
+		return fmt.Errorf("allow_credentials can not be used with any origin allowed")
+	}
+	if r.MaxAge != nil && *r.MaxAge < 0 {
+		return fmt.Errorf("max_age can not be negative")
+	}
+	return nil
+}
+
+func (r *CORSPolicy) originACL() string {
+	return r.Name + "_cors_origin"
+}
+
+func (r *CORSPolicy) condTest() string {
+	return fmt.Sprintf("{ var(txn.%s) -m found }", r.originACL())
+}
+
+func (r *CORSPolicy) preflightCond() string {
+	return fmt.Sprintf("if METH_OPTIONS %s { req.hdr(access-control-request-method) -m found }", r.originACL())
+}
+
+// preflightRule returns the http-request return line answering preflight requests, the
+// config parser has no return action so it is kept as a raw line, written after the http-request
+// rules the parser knows
+func (r *CORSPolicy) preflightRule() string {
+	line := []string{"http-request return status 204", "hdr", corsAllowOrigin, "%[req.hdr(origin)]"}
+	if len(r.AllowedMethods) > 0 {
+		line = append(line, "hdr", corsAllowMethods, strings.Join(r.AllowedMethods, ","))
+	}
+	if len(r.AllowedHeaders) > 0 {
+		line = append(line, "hdr", corsAllowHeaders, strings.Join(r.AllowedHeaders, ","))
+	}
+	if r.AllowCredentials {
+		line = append(line, "hdr", corsAllowCredentials, "true")
+	}
+	if r.MaxAge != nil {
+		line = append(line, "hdr", corsMaxAge, strconv.FormatInt(*r.MaxAge, 10))
+	}
+	line = append(line, r.preflightCond())
+	return strings.Join(line, " ")
+}
+
+func (r *CORSPolicy) isPreflightRule(line string) bool {
+	return strings.HasPrefix(line, "http-request return ") && strings.HasSuffix(line, " "+r.preflightCond())
+}
+
+// GetCORSPolicy returns configuration version and a requested CORS policy of the frontend.
+// Returns error on fail or if policy does not exist.
+func (c *Client) GetCORSPolicy(name string, frontend string, transactionID string) (int64, *CORSPolicy, error) {
+	v, acls, err := c.GetACLs("frontend", frontend, transactionID)
+	if err != nil {
+		return 0, nil, err
+	}
+	policy := &CORSPolicy{Name: name}
+
+	found := false
+	for _, a := range acls {
+		if a.ACLName != policy.originACL() || a.Criterion != "req.hdr(origin)" {
+			continue
+		}
+		found = true
+		if a.Value == "-m found" {
+			policy.AllowedOrigins = []string{"*"}
+		} else {
+			policy.AllowedOrigins = strings.Fields(strings.TrimPrefix(a.Value, "-m str "))
+		}
+	}
+	if !found {
+		return v, nil, NewConfError(ErrObjectDoesNotExist, fmt.Sprintf("CORS policy %s does not exist in frontend %s", name, frontend))
+	}
+
+	_, rules, err := c.GetHTTPResponseRules("frontend", frontend, transactionID)
+	if err != nil {
+		return 0, nil, err
+	}
+	for _, r := range rules {
+		if r.Type != "set-header" || r.CondTest != policy.condTest() {
+			continue
+		}
+		switch r.HdrName {
+		case corsAllowMethods:
+			policy.AllowedMethods = strings.Split(r.HdrFormat, ",")
+		case corsAllowHeaders:
+			policy.AllowedHeaders = strings.Split(r.HdrFormat, ",")
+		case corsExposeHeaders:
+			policy.ExposedHeaders = strings.Split(r.HdrFormat, ",")
+		case corsAllowCredentials:
+			policy.AllowCredentials = r.HdrFormat == "true"
+		case corsMaxAge:
+			if maxAge, err := strconv.ParseInt(r.HdrFormat, 10, 64); err == nil {
+				policy.MaxAge = &maxAge
+			}
+		}
+	}
+	return v, policy, nil
+}
+
+// CreateCORSPolicy creates the origin acl, the request rule capturing the origin, the request
+// rule answering preflight requests and the response rules setting the Access-Control-*
+// headers in the frontend in one transaction. The preflight rule is evaluated after all other
+// http-request rules of the frontend.
+// One of version or transactionID is mandatory. Returns error on fail, nil on success.
+func (c *Client) CreateCORSPolicy(frontend string, data *CORSPolicy, transactionID string, version int64) error {
+	if c.UseValidation {
+		validationErr := data.Validate(strfmt.Default)
+		if validationErr != nil {
+			return NewConfError(ErrValidationError, validationErr.Error())
+		}
+	}
+
+	if err := c.authorize("CreateCORSPolicy", "cors_policy", data.Name, "frontend", frontend, transactionID); err != nil {
+		return err
+	}
+
+	p, t, err := c.loadDataForChange(transactionID, version)
+	if err != nil {
+		return err
+	}
+
+	if _, policy, _ := c.GetCORSPolicy(data.Name, frontend, t); policy != nil {
+		return c.handleError(data.Name, "frontend", frontend, t, transactionID == "",
+			NewConfError(ErrObjectAlreadyExists, fmt.Sprintf("CORS policy %s already exists in frontend %s", data.Name, frontend)))
+	}
+
+	if err := c.createCORSPolicy(frontend, data, t); err != nil {
+		return c.handleError(data.Name, "frontend", frontend, t, transactionID == "", err)
+	}
+
+	if err := c.saveData(p, t, transactionID == ""); err != nil {
+		return err
+	}
+	return nil
+}
+
+// EditCORSPolicy replaces all rules of the CORS policy in the frontend in one transaction.
+// One of version or transactionID is mandatory. Returns error on fail, nil on success.
+func (c *Client) EditCORSPolicy(name string, frontend string, data *CORSPolicy, transactionID string, version int64) error {
+	if c.UseValidation {
+		validationErr := data.Validate(strfmt.Default)
+		if validationErr != nil {
+			return NewConfError(ErrValidationError, validationErr.Error())
+		}
+	}
+
+	if err := c.authorize("EditCORSPolicy", "cors_policy", name, "frontend", frontend, transactionID); err != nil {
+		return err
+	}
+
+	p, t, err := c.loadDataForChange(transactionID, version)
+	if err != nil {
+		return err
+	}
+
+	if err := c.deleteCORSPolicy(name, frontend, t); err != nil {
+		return c.handleError(name, "frontend", frontend, t, transactionID == "", err)
+	}
+	if err := c.createCORSPolicy(frontend, data, t); err != nil {
+		return c.handleError(name, "frontend", frontend, t, transactionID == "", err)
+	}
+
+	if err := c.saveData(p, t, transactionID == ""); err != nil {
+		return err
+	}
+	return nil
+}
+
+// DeleteCORSPolicy deletes all rules of the CORS policy from the frontend in one transaction.
+// One of version or transactionID is mandatory. Returns error on fail, nil on success.
+func (c *Client) DeleteCORSPolicy(name string, frontend string, transactionID string, version int64) error {
+	if err := c.authorize("DeleteCORSPolicy", "cors_policy", name, "frontend", frontend, transactionID); err != nil {
+		return err
+	}
+
+	p, t, err := c.loadDataForChange(transactionID, version)
+	if err != nil {
+		return err
+	}
+
+	if err := c.deleteCORSPolicy(name, frontend, t); err != nil {
+		return c.handleError(name, "frontend", frontend, t, transactionID == "", err)
+	}
+
+	if err := c.saveData(p, t, transactionID == ""); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (c *Client) createCORSPolicy(frontend string, data *CORSPolicy, t string) error {
+	var res []error
+
+	_, acls, err := c.GetACLs("frontend", frontend, t)
+	if err != nil {
+		return err
+	}
+	aclIndex := int64(len(acls))
+	acl := &models.ACL{
+		Index:     &aclIndex,
+		ACLName:   data.originACL(),
+		Criterion: "req.hdr(origin)",
+		Value:     "-m str " + strings.Join(data.AllowedOrigins, " "),
+	}
+	if misc.StringInSlice("*", data.AllowedOrigins) {
+		acl.Value = "-m found"
+	}
+	if err := c.CreateACL("frontend", frontend, acl, t, 0); err != nil {
+		res = append(res, err)
+	}
+
+	_, reqRules, err := c.GetHTTPRequestRules("frontend", frontend, t)
+	if err != nil {
+		return err
+	}
+	reqIndex := int64(len(reqRules))
+	err = c.CreateHTTPRequestRule("frontend", frontend, &models.HTTPRequestRule{
+		Index:    &reqIndex,
+		Type:     "set-var",
+		VarScope: "txn",
+		VarName:  data.originACL(),
+		VarExpr:  "req.hdr(origin)",
+		Cond:     "if",
+		CondTest: data.originACL(),
+	}, t, 0)
+	if err != nil {
+		res = append(res, err)
+	}
+
+	headers := [][]string{{corsAllowOrigin, "%[var(txn." + data.originACL() + ")]"}}
+	if len(data.AllowedMethods) > 0 {
+		headers = append(headers, []string{corsAllowMethods, strings.Join(data.AllowedMethods, ",")})
+	}
+	if len(data.AllowedHeaders) > 0 {
+		headers = append(headers, []string{corsAllowHeaders, strings.Join(data.AllowedHeaders, ",")})
+	}
+	if len(data.ExposedHeaders) > 0 {
+		headers = append(headers, []string{corsExposeHeaders, strings.Join(data.ExposedHeaders, ",")})
+	}
+	if data.AllowCredentials {
+		headers = append(headers, []string{corsAllowCredentials, "true"})
+	}
+	if data.MaxAge != nil {
+		headers = append(headers, []string{corsMaxAge, strconv.FormatInt(*data.MaxAge, 10)})
+	}
+
+	_, resRules, err := c.GetHTTPResponseRules("frontend", frontend, t)
+	if err != nil {
+		return err
+	}
+	resIndex := int64(len(resRules))
+	for _, h := range headers {
+		index := resIndex
+		resIndex++
+		err := c.CreateHTTPResponseRule("frontend", frontend, &models.HTTPResponseRule{
+			Index:     &index,
+			Type:      "set-header",
+			HdrName:   h[0],
+			HdrFormat: h[1],
+			Cond:      "if",
+			CondTest:  data.condTest(),
+		}, t, 0)
+		if err != nil {
+			res = append(res, err)
+		}
+	}
+
+	p, err := c.GetParser(t)
+	if err != nil {
+		return err
+	}
+	preflight, err := getRawRules(p, parser.Frontends, frontend, "http-request")
+	if err != nil {
+		return err
+	}
+	line := data.preflightRule()
+	if err := setRawRule(p, parser.Frontends, frontend, "http-request", len(preflight), &line, true); err != nil {
+		res = append(res, err)
+	}
+
+	if len(res) > 0 {
+		return CompositeTransactionError(res...)
+	}
+	return nil
+}
+
+func (c *Client) deleteCORSPolicy(name string, frontend string, t string) error {
+	var res []error
+	policy := &CORSPolicy{Name: name}
+
+	if _, _, err := c.GetCORSPolicy(name, frontend, t); err != nil {
+		return err
+	}
+
+	// delete from the end so the remaining indexes stay valid
+	_, resRules, err := c.GetHTTPResponseRules("frontend", frontend, t)
+	if err != nil {
+		return err
+	}
+	for i := len(resRules) - 1; i >= 0; i-- {
+		if resRules[i].CondTest == policy.condTest() {
+			if err := c.DeleteHTTPResponseRule(*resRules[i].Index, "frontend", frontend, t, 0); err != nil {
+				res = append(res, err)
+			}
+		}
+	}
+
+	_, reqRules, err := c.GetHTTPRequestRules("frontend", frontend, t)
+	if err != nil {
+		return err
+	}
+	for i := len(reqRules) - 1; i >= 0; i-- {
+		if reqRules[i].Type == "set-var" && reqRules[i].VarName == policy.originACL() && reqRules[i].CondTest == policy.originACL() {
+			if err := c.DeleteHTTPRequestRule(*reqRules[i].Index, "frontend", frontend, t, 0); err != nil {
+				res = append(res, err)
+			}
+		}
+	}
+
+	p, err := c.GetParser(t)
+	if err != nil {
+		return err
+	}
+	rawRules, err := getRawRules(p, parser.Frontends, frontend, "http-request")
+	if err != nil {
+		return err
+	}
+	for i := len(rawRules) - 1; i >= 0; i-- {
+		if policy.isPreflightRule(rawRules[i]) {
+			if err := setRawRule(p, parser.Frontends, frontend, "http-request", i, nil, false); err != nil {
+				res = append(res, err)
+			}
+		}
+	}
+
+	_, acls, err := c.GetACLs("frontend", frontend, t)
+	if err != nil {
+		return err
+	}
+	for i := len(acls) - 1; i >= 0; i-- {
+		if acls[i].ACLName == policy.originACL() {
+			if err := c.DeleteACL(*acls[i].Index, "frontend", frontend, t, 0); err != nil {
+				res = append(res, err)
+			}
+		}
+	}
+
+	if len(res) > 0 {
+		return CompositeTransactionError(res...)
+	}
+	return nil
+}
@@ -0,0 +1,154 @@
+// Copyright 2019 HAProxy Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package configuration
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/haproxytech/models/v2"
+)
+
+func TestCreateEditDeleteCORSPolicy(t *testing.T) {
+	maxAge := int64(600)
+	cp := &CORSPolicy{
+		Name:             "api",
+		AllowedOrigins:   []string{"https://example.com", "https://www.example.com"},
+		AllowedMethods:   []string{"GET", "POST", "OPTIONS"},
+		AllowedHeaders:   []string{"Content-Type", "Authorization"},
+		AllowCredentials: true,
+		MaxAge:           &maxAge,
+	}
+
+	err := client.CreateCORSPolicy("test_2", cp, "", version)
+	if err != nil {
+		t.Error(err.Error())
+	} else {
+		version++
+	}
+
+	v, policy, err := client.GetCORSPolicy("api", "test_2", "")
+	if err != nil {
+		t.Error(err.Error())
+	}
+
+	if !reflect.DeepEqual(policy, cp) {
+		fmt.Printf("Created CORS policy: %v\n", policy)
+		fmt.Printf("Given CORS policy: %v\n", cp)
+		t.Error("Created CORS policy not equal to given CORS policy")
+	}
+
+	if v != version {
+		t.Errorf("Version %v returned, expected %v", v, version)
+	}
+
+	preflight := "http-request return status 204 hdr Access-Control-Allow-Origin %[req.hdr(origin)] " +
+		"hdr Access-Control-Allow-Methods GET,POST,OPTIONS hdr Access-Control-Allow-Headers Content-Type,Authorization " +
+		"hdr Access-Control-Allow-Credentials true hdr Access-Control-Max-Age 600 " +
+		"if METH_OPTIONS api_cors_origin { req.hdr(access-control-request-method) -m found }"
+	_, raw, err := client.GetRawConfiguration("", 0)
+	if err != nil {
+		t.Error(err.Error())
+	} else if !strings.Contains(raw, preflight) {
+		t.Error("CORS preflight rule not found in the configuration")
+	}
+
+	cp = &CORSPolicy{
+		Name:           "api",
+		AllowedOrigins: []string{"*"},
+		ExposedHeaders: []string{"X-Request-Id"},
+	}
+
+	err = client.EditCORSPolicy("api", "test_2", cp, "", version)
+	if err != nil {
+		t.Error(err.Error())
+	} else {
+		version++
+	}
+
+	_, policy, err = client.GetCORSPolicy("api", "test_2", "")
+	if err != nil {
+		t.Error(err.Error())
+	}
+
+	if !reflect.DeepEqual(policy, cp) {
+		fmt.Printf("Edited CORS policy: %v\n", policy)
+		fmt.Printf("Given CORS policy: %v\n", cp)
+		t.Error("Edited CORS policy not equal to given CORS policy")
+	}
+
+	err = client.DeleteCORSPolicy("api", "test_2", "", version)
+	if err != nil {
+		t.Error(err.Error())
+	} else {
+		version++
+	}
+
+	if v, _ := client.GetVersion(""); v != version {
+		t.Error("Version not incremented")
+	}
+
+	_, _, err = client.GetCORSPolicy("api", "test_2", "")
+	if err == nil {
+		t.Error("DeleteCORSPolicy failed, CORS policy api still exists")
+	}
+
+	_, raw, err = client.GetRawConfiguration("", 0)
+	if err != nil {
+		t.Error(err.Error())
+	} else if strings.Contains(raw, "api_cors_origin") {
+		t.Error("DeleteCORSPolicy failed, CORS preflight rule still exists")
+	}
+}
+
+func TestCORSPolicyPreflightOrder(t *testing.T) {
+	tr, err := client.StartTransaction(version)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.DeleteTransaction(tr.ID)
+
+	index := int64(0)
+	deny := &models.HTTPRequestRule{Index: &index, Type: "deny", Cond: "unless", CondTest: "{ req.hdr(authorization) -m found }"}
+	if err := client.CreateHTTPRequestRule("frontend", "test_2", deny, tr.ID, 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.CreateCORSPolicy("test_2", &CORSPolicy{Name: "order", AllowedOrigins: []string{"*"}}, tr.ID, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	_, raw, err := client.GetRawConfiguration(tr.ID, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	section := raw[strings.Index(raw, "frontend test_2"):]
+	if end := strings.Index(section, "\n\n"); end > 0 {
+		section = section[:end]
+	}
+	rules := []string{}
+	for _, line := range strings.Split(section, "\n") {
+		if line = strings.TrimSpace(line); strings.HasPrefix(line, "http-request ") {
+			rules = append(rules, line)
+		}
+	}
+	// the preflight rule is kept as an unprocessed line, written after all other request rules
+	if len(rules) < 2 || rules[0] != "http-request deny unless { req.hdr(authorization) -m found }" ||
+		!strings.HasPrefix(rules[len(rules)-1], "http-request return status 204 ") {
+		t.Errorf("Unexpected order of http-request rules:\n%s", strings.Join(rules, "\n"))
+	}
+}
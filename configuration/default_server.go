@@ -0,0 +1,191 @@
+// Copyright 2019 HAProxy Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package configuration
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	strfmt "github.com/go-openapi/strfmt"
+	parser "github.com/haproxytech/config-parser/v3"
+	parser_errors "github.com/haproxytech/config-parser/v3/errors"
+	"github.com/haproxytech/config-parser/v3/params"
+	"github.com/haproxytech/config-parser/v3/types"
+	"github.com/haproxytech/models/v2"
+)
+
+// GetDefaultServer returns configuration version and the default-server settings of the defaults
+// section or of a backend, an empty object when none are set. Settings of several default-server
+// lines are merged. Returns error on fail.
+func (c *Client) GetDefaultServer(parentType string, parentName string, transactionID string) (int64, *models.DefaultServer, error) {
+	section, name, err := defaultServerSection(parentType, parentName)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	p, err := c.GetParser(transactionID)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	v, err := c.GetVersion(transactionID)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	if !c.checkSectionExists(section, name, p) {
+		return v, nil, NewConfError(ErrParentDoesNotExist, fmt.Sprintf("%s %s does not exist", parentType, parentName))
+	}
+
+	ds, err := ParseDefaultServer(section, name, p)
+	if err != nil {
+		return v, nil, c.handleError("default-server", parentType, parentName, "", false, err)
+	}
+
+	return v, ds, nil
+}
+
+// EditDefaultServer replaces the default-server settings of the defaults section or of a backend
+// with a single default-server line, nil or empty settings remove it. One of version or
+// transactionID is mandatory. Returns error on fail, nil on success.
+func (c *Client) EditDefaultServer(parentType string, parentName string, data *models.DefaultServer, transactionID string, version int64) error {
+	section, name, err := defaultServerSection(parentType, parentName)
+	if err != nil {
+		return err
+	}
+	if data != nil {
+		if err := c.validateDefaultServer(data); err != nil {
+			return NewConfError(ErrValidationError, err.Error())
+		}
+	}
+
+	if err := c.authorize("EditDefaultServer", "default_server", "", parentType, parentName, transactionID); err != nil {
+		return err
+	}
+
+	p, t, err := c.loadDataForChange(transactionID, version)
+	if err != nil {
+		return err
+	}
+
+	if !c.checkSectionExists(section, name, p) {
+		e := NewConfError(ErrParentDoesNotExist, fmt.Sprintf("%s %s does not exist", parentType, parentName))
+		return c.handleError("default-server", parentType, parentName, t, transactionID == "", e)
+	}
+
+	if err := SerializeDefaultServer(section, name, data, p); err != nil {
+		return c.handleError("default-server", parentType, parentName, t, transactionID == "", err)
+	}
+
+	if err := c.saveData(p, t, transactionID == ""); err != nil {
+		return err
+	}
+	return nil
+}
+
+// ParseDefaultServer returns the settings of the default-server lines of the section. The
+// options are the server options, port is the health check port and is returned as Port.
+func ParseDefaultServer(section parser.Section, name string, p *parser.Parser) (*models.DefaultServer, error) {
+	ds := &models.DefaultServer{}
+	data, err := p.Get(section, name, "default-server", false)
+	if err != nil {
+		if err == parser_errors.ErrFetch {
+			return ds, nil
+		}
+		return nil, err
+	}
+
+	options := []params.ServerOption{}
+	for _, d := range data.([]types.DefaultServer) {
+		options = append(options, d.Params...)
+	}
+	s := ParseServer(types.Server{Params: options})
+	if s == nil {
+		return ds, nil
+	}
+	if err := convertServerSettings(s, ds); err != nil {
+		return nil, err
+	}
+	ds.Port = s.HealthCheckPort
+	ds.HealthCheckPort = nil
+	return ds, nil
+}
+
+func SerializeDefaultServer(section parser.Section, name string, data *models.DefaultServer, p *parser.Parser) error {
+	if data == nil || reflect.DeepEqual(*data, models.DefaultServer{}) {
+		return p.Set(section, name, "default-server", nil)
+	}
+	s, err := defaultServerSettings(data)
+	if err != nil {
+		return err
+	}
+	return p.Set(section, name, "default-server", []types.DefaultServer{{Params: SerializeServer(*s).Params}})
+}
+
+// defaultServerSettings returns the settings as a server without name and address, Port is
+// written as the health check port
+func defaultServerSettings(data *models.DefaultServer) (*models.Server, error) {
+	s := &models.Server{}
+	if err := convertServerSettings(data, s); err != nil {
+		return nil, err
+	}
+	if s.HealthCheckPort == nil {
+		s.HealthCheckPort = data.Port
+	}
+	s.Name = ""
+	s.Address = ""
+	s.Port = nil
+	return s, nil
+}
+
+// convertServerSettings copies the settings between a server and a default server, both models
+// share the names of their fields
+func convertServerSettings(from interface{}, to interface{}) error {
+	b, err := json.Marshal(from)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, to)
+}
+
+func (c *Client) validateDefaultServer(data *models.DefaultServer) error {
+	if c.UseValidation {
+		if err := data.Validate(strfmt.Default); err != nil {
+			return err
+		}
+	}
+	s, err := defaultServerSettings(data)
+	if err != nil {
+		return err
+	}
+	s.Name = "default-server"
+	if err := validateServerAgent(s); err != nil {
+		return err
+	}
+	return ValidateServerKeywords(s, c.haproxyVersion)
+}
+
+func defaultServerSection(parentType string, parentName string) (parser.Section, string, error) {
+	switch parentType {
+	case "defaults":
+		return parser.Defaults, parser.DefaultSectionName, nil
+	case "backend":
+		return parser.Backends, parentName, nil
+	default:
+		return "", "", NewConfError(ErrValidationError, fmt.Sprintf("default-server is not supported in %s", parentType))
+	}
+}
@@ -0,0 +1,118 @@
+// Copyright 2019 HAProxy Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package configuration
+
+import (
+	"testing"
+
+	"github.com/haproxytech/client-native/v2/misc"
+	"github.com/haproxytech/models/v2"
+)
+
+func TestGetDefaultServer(t *testing.T) {
+	v, ds, err := client.GetDefaultServer("backend", "test_2", "")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if v != version {
+		t.Errorf("Version %v returned, expected %v", v, version)
+	}
+	if ds.Inter == nil || *ds.Inter != 5000 {
+		t.Errorf("Inter %v returned, expected 5000", ds.Inter)
+	}
+	if ds.Port == nil || *ds.Port != 8888 {
+		t.Errorf("Port %v returned, expected 8888", ds.Port)
+	}
+	if ds.Slowstart == nil || *ds.Slowstart != 6000 {
+		t.Errorf("Slowstart %v returned, expected 6000", ds.Slowstart)
+	}
+
+	if _, _, err := client.GetDefaultServer("frontend", "test", ""); err == nil {
+		t.Error("Should throw error, default-server is not supported in frontends")
+	}
+}
+
+func TestEditDefaultServer(t *testing.T) {
+	if err := client.CreateBackend(&models.Backend{Name: "default_server", Mode: "http"}, "", version); err != nil {
+		t.Fatal(err.Error())
+	}
+	version++
+
+	_, ds, err := client.GetDefaultServer("backend", "default_server", "")
+	if err != nil {
+		t.Error(err.Error())
+	} else if ds.Check != "" || ds.Inter != nil {
+		t.Errorf("default-server %+v returned, expected empty settings", ds)
+	}
+
+	ds = &models.DefaultServer{
+		Check:     "enabled",
+		Inter:     misc.Int64P(3000),
+		Maxconn:   misc.Int64P(100),
+		Resolvers: "test",
+		Ssl:       "enabled",
+		Verify:    "none",
+		Port:      misc.Int64P(8081),
+	}
+	if err := client.EditDefaultServer("backend", "default_server", ds, "", version); err != nil {
+		t.Error(err.Error())
+	} else {
+		version++
+	}
+
+	_, edited, err := client.GetDefaultServer("backend", "default_server", "")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if edited.Check != "enabled" || edited.Ssl != "enabled" || edited.Verify != "none" || edited.Resolvers != "test" {
+		t.Errorf("default-server %+v not as edited", edited)
+	}
+	if edited.Inter == nil || *edited.Inter != 3000 || edited.Maxconn == nil || *edited.Maxconn != 100 {
+		t.Errorf("default-server %+v not as edited", edited)
+	}
+	if edited.Port == nil || *edited.Port != 8081 {
+		t.Errorf("Port %v returned, expected 8081", edited.Port)
+	}
+
+	_, b, err := client.GetBackend("default_server", "")
+	if err != nil {
+		t.Error(err.Error())
+	} else if b.DefaultServer == nil || b.DefaultServer.Inter == nil || *b.DefaultServer.Inter != 3000 {
+		t.Errorf("backend default-server %+v not as edited", b.DefaultServer)
+	}
+
+	if err := client.EditDefaultServer("backend", "default_server", nil, "", version); err != nil {
+		t.Error(err.Error())
+	} else {
+		version++
+	}
+	_, ds, err = client.GetDefaultServer("backend", "default_server", "")
+	if err != nil {
+		t.Error(err.Error())
+	} else if ds.Check != "" || ds.Inter != nil {
+		t.Errorf("default-server %+v returned, expected empty settings", ds)
+	}
+
+	if err := client.EditDefaultServer("backend", "doesnotexist", ds, "", version); err == nil {
+		t.Error("Should throw error, non existent backend")
+	}
+
+	if err := client.DeleteBackend("default_server", "", version); err != nil {
+		t.Error(err.Error())
+	} else {
+		version++
+	}
+}
@@ -16,6 +16,9 @@
 package configuration
 
 import (
+	"fmt"
+	"strings"
+
 	strfmt "github.com/go-openapi/strfmt"
 	parser "github.com/haproxytech/config-parser/v3"
 	"github.com/haproxytech/models/v2"
@@ -50,9 +53,306 @@ func (c *Client) PushDefaultsConfiguration(data *models.Defaults, transactionID
 		}
 	}
 
-	if err := c.editSection(parser.Defaults, parser.DefaultSectionName, data, transactionID, version); err != nil {
+	if err := validateLogFormats(data.LogFormat, data.LogFormatSd); err != nil {
+		return err
+	}
+
+	if err := c.editSection("PushDefaultsConfiguration", parser.Defaults, parser.DefaultSectionName, data, transactionID, version); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// NamedDefaults is a named defaults section. Frontends, backends and listen sections inherit
+// its settings when they name it, see SetInheritedDefaults.
+type NamedDefaults struct {
+	Name string `json:"name"`
+	// From is the named defaults section this one inherits from
+	From     string           `json:"from,omitempty"`
+	Defaults *models.Defaults `json:"defaults,omitempty"`
+}
+
+// GetNamedDefaultsSections returns configuration version and an array of configured named
+// defaults sections. Returns error on fail.
+func (c *Client) GetNamedDefaultsSections(transactionID string) (int64, []*NamedDefaults, error) {
+	p, err := c.GetParser(transactionID)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	v, err := c.GetVersion(transactionID)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	sections := []*NamedDefaults{}
+	for _, s := range getDefaultsSections(p).sections {
+		sections = append(sections, parseNamedDefaults(s))
+	}
+	return v, sections, nil
+}
+
+// GetNamedDefaults returns configuration version and a requested named defaults section.
+// Returns error on fail or if defaults section does not exist.
+func (c *Client) GetNamedDefaults(name string, transactionID string) (int64, *NamedDefaults, error) {
+	p, err := c.GetParser(transactionID)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	v, err := c.GetVersion(transactionID)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	s := getDefaultsSections(p).get(name)
+	if s == nil {
+		return v, nil, NewConfError(ErrObjectDoesNotExist, fmt.Sprintf("%s %s does not exist", parser.Defaults, name))
+	}
+	return v, parseNamedDefaults(s), nil
+}
+
+// CreateNamedDefaults creates a named defaults section in configuration. It is written before
+// the anonymous defaults section, sections inherit from it only when they name it.
+// One of version or transactionID is mandatory. Returns error on fail, nil on success.
+func (c *Client) CreateNamedDefaults(data *NamedDefaults, transactionID string, version int64) error {
+	if err := c.validateNamedDefaults(data); err != nil {
+		return err
+	}
+
+	if err := c.authorize("CreateNamedDefaults", string(parser.Defaults), data.Name, "", "", transactionID); err != nil {
+		return err
+	}
+
+	p, t, err := c.loadDataForChange(transactionID, version)
+	if err != nil {
+		return err
+	}
+
+	d := getDefaultsSections(p)
+	if d.get(data.Name) != nil {
+		e := NewConfError(ErrObjectAlreadyExists, fmt.Sprintf("%s %s already exists", parser.Defaults, data.Name))
+		return c.handleError(data.Name, "", "", t, transactionID == "", e)
+	}
+	if data.From != "" && d.get(data.From) == nil {
+		e := NewConfError(ErrObjectDoesNotExist, fmt.Sprintf("%s %s does not exist", parser.Defaults, data.From))
+		return c.handleError(data.Name, "", "", t, transactionID == "", e)
+	}
+
+	s := &defaultsSection{
+		name: data.Name,
+		from: data.From,
+		p: &parser.Parser{
+			Options: parser.Options{
+				UseV2HTTPCheck: true,
+			},
+		},
+	}
+	if err := s.p.ParseData(""); err != nil {
+		return c.handleError(data.Name, "", "", t, transactionID == "", err)
+	}
+	if err := serializeNamedDefaults(s, data); err != nil {
+		return c.handleError(data.Name, "", "", t, transactionID == "", err)
+	}
+	d.sections = append(d.sections, s)
+
+	if err := c.saveData(p, t, transactionID == ""); err != nil {
 		return err
 	}
 
 	return nil
 }
+
+// EditNamedDefaults edits a named defaults section in configuration, it can not be renamed.
+// One of version or transactionID is mandatory. Returns error on fail, nil on success.
+func (c *Client) EditNamedDefaults(name string, data *NamedDefaults, transactionID string, version int64) error {
+	if err := c.validateNamedDefaults(data); err != nil {
+		return err
+	}
+	if data.Name != name {
+		return NewConfError(ErrValidationError, fmt.Sprintf("%s %s can not be renamed to %s", parser.Defaults, name, data.Name))
+	}
+
+	if err := c.authorize("EditNamedDefaults", string(parser.Defaults), name, "", "", transactionID); err != nil {
+		return err
+	}
+
+	p, t, err := c.loadDataForChange(transactionID, version)
+	if err != nil {
+		return err
+	}
+
+	d := getDefaultsSections(p)
+	s := d.get(name)
+	if s == nil {
+		e := NewConfError(ErrObjectDoesNotExist, fmt.Sprintf("%s %s does not exist", parser.Defaults, name))
+		return c.handleError(name, "", "", t, transactionID == "", e)
+	}
+	if data.From != "" && d.get(data.From) == nil {
+		e := NewConfError(ErrObjectDoesNotExist, fmt.Sprintf("%s %s does not exist", parser.Defaults, data.From))
+		return c.handleError(name, "", "", t, transactionID == "", e)
+	}
+	if data.From != "" && d.inherits(data.From, name) {
+		e := NewConfError(ErrValidationError, fmt.Sprintf("%s %s inherits from %s", parser.Defaults, data.From, name))
+		return c.handleError(name, "", "", t, transactionID == "", e)
+	}
+
+	if err := serializeNamedDefaults(s, data); err != nil {
+		return c.handleError(name, "", "", t, transactionID == "", err)
+	}
+	s.from = data.From
+
+	if err := c.saveData(p, t, transactionID == ""); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// DeleteNamedDefaults deletes a named defaults section in configuration, sections inheriting
+// from it have to be changed first. One of version or transactionID is mandatory.
+// Returns error on fail, nil on success.
+func (c *Client) DeleteNamedDefaults(name string, transactionID string, version int64) error {
+	if err := c.authorize("DeleteNamedDefaults", string(parser.Defaults), name, "", "", transactionID); err != nil {
+		return err
+	}
+
+	p, t, err := c.loadDataForChange(transactionID, version)
+	if err != nil {
+		return err
+	}
+
+	d := getDefaultsSections(p)
+	if d.get(name) == nil {
+		e := NewConfError(ErrObjectDoesNotExist, fmt.Sprintf("%s %s does not exist", parser.Defaults, name))
+		return c.handleError(name, "", "", t, transactionID == "", e)
+	}
+	if users := d.users(name); len(users) > 0 {
+		e := NewConfError(ErrValidationError, fmt.Sprintf("%s %s is inherited by %s", parser.Defaults, name, strings.Join(users, ", ")))
+		return c.handleError(name, "", "", t, transactionID == "", e)
+	}
+
+	for i, s := range d.sections {
+		if s.name == name {
+			d.sections = append(d.sections[:i], d.sections[i+1:]...)
+			break
+		}
+	}
+
+	if err := c.saveData(p, t, transactionID == ""); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// GetInheritedDefaults returns configuration version and the name of the defaults section a
+// frontend, backend or listen section inherits from. An empty name is the anonymous defaults
+// section. Returns error on fail or if the section does not exist.
+func (c *Client) GetInheritedDefaults(section parser.Section, name string, transactionID string) (int64, string, error) {
+	if err := validateInheritingSection(section); err != nil {
+		return 0, "", err
+	}
+
+	p, err := c.GetParser(transactionID)
+	if err != nil {
+		return 0, "", err
+	}
+
+	v, err := c.GetVersion(transactionID)
+	if err != nil {
+		return 0, "", err
+	}
+
+	if !c.checkSectionExists(section, name, p) {
+		return v, "", NewConfError(ErrObjectDoesNotExist, fmt.Sprintf("%s %s does not exist", section, name))
+	}
+	return v, getDefaultsSections(p).from[fmt.Sprintf("%s %s", section, name)], nil
+}
+
+// SetInheritedDefaults sets the named defaults section a frontend, backend or listen section
+// inherits from, an empty defaults name makes it inherit from the anonymous defaults section.
+// One of version or transactionID is mandatory. Returns error on fail, nil on success.
+func (c *Client) SetInheritedDefaults(section parser.Section, name string, defaults string, transactionID string, version int64) error {
+	if err := validateInheritingSection(section); err != nil {
+		return err
+	}
+
+	if err := c.authorize("SetInheritedDefaults", string(section), name, "", "", transactionID); err != nil {
+		return err
+	}
+
+	p, t, err := c.loadDataForChange(transactionID, version)
+	if err != nil {
+		return err
+	}
+
+	if !c.checkSectionExists(section, name, p) {
+		e := NewConfError(ErrObjectDoesNotExist, fmt.Sprintf("%s %s does not exist", section, name))
+		return c.handleError(name, "", "", t, transactionID == "", e)
+	}
+
+	d := getDefaultsSections(p)
+	key := fmt.Sprintf("%s %s", section, name)
+	if defaults == "" {
+		delete(d.from, key)
+	} else {
+		if d.get(defaults) == nil {
+			e := NewConfError(ErrObjectDoesNotExist, fmt.Sprintf("%s %s does not exist", parser.Defaults, defaults))
+			return c.handleError(name, "", "", t, transactionID == "", e)
+		}
+		d.from[key] = defaults
+	}
+
+	if err := c.saveData(p, t, transactionID == ""); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (c *Client) validateNamedDefaults(data *NamedDefaults) error {
+	if data.Name == "" || data.Name == "from" || strings.ContainsAny(data.Name, " \t#") {
+		return NewConfError(ErrValidationError, fmt.Sprintf("invalid defaults name %q", data.Name))
+	}
+	if data.From == data.Name {
+		return NewConfError(ErrValidationError, fmt.Sprintf("defaults %s can not inherit from itself", data.Name))
+	}
+	if data.Defaults == nil {
+		return nil
+	}
+	if c.UseValidation {
+		validationErr := data.Defaults.Validate(strfmt.Default)
+		if validationErr != nil {
+			return NewConfError(ErrValidationError, validationErr.Error())
+		}
+	}
+	return validateLogFormats(data.Defaults.LogFormat, data.Defaults.LogFormatSd)
+}
+
+func validateInheritingSection(section parser.Section) error {
+	switch section {
+	case parser.Frontends, parser.Backends, parser.Listen:
+		return nil
+	}
+	return NewConfError(ErrValidationError, fmt.Sprintf("%s sections do not inherit from defaults sections", section))
+}
+
+func parseNamedDefaults(s *defaultsSection) *NamedDefaults {
+	d := &models.Defaults{}
+	ParseSection(d, parser.Defaults, parser.DefaultSectionName, s.p)
+	return &NamedDefaults{
+		Name:     s.name,
+		From:     s.from,
+		Defaults: d,
+	}
+}
+
+func serializeNamedDefaults(s *defaultsSection, data *NamedDefaults) error {
+	d := data.Defaults
+	if d == nil {
+		d = &models.Defaults{}
+	}
+	return CreateEditSection(d, parser.Defaults, parser.DefaultSectionName, s.p)
+}
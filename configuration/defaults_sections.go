@@ -0,0 +1,383 @@
+// Copyright 2019 HAProxy Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package configuration
+
+import (
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"sync"
+
+	parser "github.com/haproxytech/config-parser/v3"
+
+	"github.com/haproxytech/client-native/v2/misc"
+)
+
+// sectionKeywords are the keywords starting a section of the configuration file
+var sectionKeywords = map[string]bool{
+	"global":      true,
+	"defaults":    true,
+	"frontend":    true,
+	"backend":     true,
+	"listen":      true,
+	"resolvers":   true,
+	"userlist":    true,
+	"peers":       true,
+	"mailers":     true,
+	"cache":       true,
+	"program":     true,
+	"http-errors": true,
+	"ring":        true,
+}
+
+// defaultsSection is a named defaults section, kept in a parser of its own as its anonymous
+// defaults section
+type defaultsSection struct {
+	name string
+	from string
+	p    *parser.Parser
+}
+
+// defaultsSections holds what the config parser can not keep of the defaults sections of a
+// configuration: the named defaults sections and the defaults section each frontend, backend,
+// listen and defaults section inherits from. The parser merges defaults sections into one
+// anonymous section and writes sections in its own order, so named defaults sections are
+// written before the anonymous one and sections inheriting from them name them with from.
+type defaultsSections struct {
+	sections []*defaultsSection
+	// from maps a section, "backend app" or "defaults" for the anonymous defaults section,
+	// to the named defaults section it inherits from
+	from map[string]string
+	// err is set when the configuration can not be kept, changes are refused
+	err error
+}
+
+// parsedDefaults holds the defaults sections of every loaded parser
+var parsedDefaults = struct {
+	sync.Mutex
+	m map[*parser.Parser]*defaultsSections
+}{m: map[*parser.Parser]*defaultsSections{}}
+
+func newDefaultsSections() *defaultsSections {
+	return &defaultsSections{from: map[string]string{}}
+}
+
+// getDefaultsSections returns the defaults sections of the parser
+func getDefaultsSections(p *parser.Parser) *defaultsSections {
+	parsedDefaults.Lock()
+	defer parsedDefaults.Unlock()
+	d, ok := parsedDefaults.m[p]
+	if !ok {
+		d = newDefaultsSections()
+		parsedDefaults.m[p] = d
+	}
+	return d
+}
+
+func forgetDefaultsSections(p *parser.Parser) {
+	parsedDefaults.Lock()
+	defer parsedDefaults.Unlock()
+	delete(parsedDefaults.m, p)
+}
+
+// loadConfig loads the configuration file into the parser
+func loadConfig(p *parser.Parser, filename string) error {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return err
+	}
+	return parseConfig(p, string(data))
+}
+
+// parseConfig parses the configuration into the parser, keeping the named defaults sections
+// aside. A configuration whose defaults sections can not be kept is parsed as is and changes
+// to it are refused.
+func parseConfig(p *parser.Parser, config string) error {
+	data, d, err := splitDefaultsSections(config)
+	if err != nil {
+		data = config
+		d = newDefaultsSections()
+		d.err = err
+	}
+	if err := p.ParseData(data); err != nil {
+		return err
+	}
+	parsedDefaults.Lock()
+	parsedDefaults.m[p] = d
+	parsedDefaults.Unlock()
+	return nil
+}
+
+// configString returns the configuration of the parser with its named defaults sections
+func configString(p *parser.Parser) string {
+	return getDefaultsSections(p).join(p.String())
+}
+
+// saveConfig writes the configuration of the parser with its named defaults sections to file
+func saveConfig(p *parser.Parser, filename string) error {
+	return ioutil.WriteFile(filename, []byte(configString(p)), 0644)
+}
+
+// defaultsSectionsErr returns an error if the defaults sections of the parser can not be kept
+func defaultsSectionsErr(p *parser.Parser) error {
+	return getDefaultsSections(p).err
+}
+
+func (d *defaultsSections) get(name string) *defaultsSection {
+	for _, s := range d.sections {
+		if s.name == name {
+			return s
+		}
+	}
+	return nil
+}
+
+// users returns the sections inheriting from the named defaults section
+func (d *defaultsSections) users(name string) []string {
+	users := []string{}
+	for section, from := range d.from {
+		if from == name {
+			users = append(users, section)
+		}
+	}
+	for _, s := range d.sections {
+		if s.from == name {
+			users = append(users, "defaults "+s.name)
+		}
+	}
+	return users
+}
+
+// inherits returns true if the named defaults section inherits from the other one, directly
+// or through other sections
+func (d *defaultsSections) inherits(name, other string) bool {
+	seen := map[string]bool{}
+	for s := d.get(name); s != nil && s.from != "" && !seen[s.name]; s = d.get(s.from) {
+		if s.from == other {
+			return true
+		}
+		seen[s.name] = true
+	}
+	return false
+}
+
+// prune forgets the sections removed from the parser, a section created again with the same
+// name inherits from the anonymous defaults section
+func (d *defaultsSections) prune(p *parser.Parser) {
+	for section := range d.from {
+		parts := strings.SplitN(section, " ", 2)
+		if len(parts) < 2 {
+			continue
+		}
+		names, err := p.SectionsGet(parser.Section(parts[0]))
+		if err != nil || !misc.StringInSlice(parts[1], names) {
+			delete(d.from, section)
+		}
+	}
+}
+
+// splitDefaultsSections returns the configuration without its named defaults sections and
+// without from on section lines, along with the defaults sections
+func splitDefaultsSections(config string) (string, *defaultsSections, error) {
+	d := newDefaultsSections()
+	var result strings.Builder
+	var current *defaultsSection
+	var body strings.Builder
+	// defaults section the following proxies inherit from
+	inherited := ""
+	anonymous := 0
+
+	endSection := func() error {
+		if current == nil {
+			return nil
+		}
+		current.p = &parser.Parser{
+			Options: parser.Options{
+				UseV2HTTPCheck: true,
+			},
+		}
+		if err := current.p.ParseData(body.String()); err != nil {
+			return NewConfError(ErrCannotReadConfFile, fmt.Sprintf("Cannot read defaults %s: %s", current.name, err.Error()))
+		}
+		d.sections = append(d.sections, current)
+		current = nil
+		body.Reset()
+		return nil
+	}
+
+	for _, line := range strings.SplitAfter(config, "\n") {
+		content := line
+		if i := strings.Index(content, "#"); i != -1 {
+			content = content[:i]
+		}
+		fields := strings.Fields(content)
+		if len(fields) == 0 || !sectionKeywords[fields[0]] {
+			if current != nil {
+				body.WriteString(line)
+			} else {
+				result.WriteString(line)
+			}
+			continue
+		}
+
+		if err := endSection(); err != nil {
+			return "", nil, err
+		}
+		name, from := sectionHeader(fields)
+		switch fields[0] {
+		case "defaults":
+			if name == "" {
+				anonymous++
+				if anonymous > 1 {
+					return "", nil, NewConfError(ErrUnsupportedConfig, "several anonymous defaults sections found, only one is supported, changes are refused to keep the configuration unchanged")
+				}
+				if from != "" {
+					d.from["defaults"] = from
+					line = "defaults\n"
+				}
+				inherited = ""
+				break
+			}
+			if d.get(name) != nil {
+				return "", nil, NewConfError(ErrValidationError, fmt.Sprintf("defaults %s declared twice", name))
+			}
+			current = &defaultsSection{name: name, from: from}
+			body.WriteString("defaults\n")
+			inherited = name
+			continue
+		case "frontend", "backend", "listen":
+			if from != "" {
+				line = fmt.Sprintf("%s %s\n", fields[0], name)
+			} else {
+				from = inherited
+			}
+			if from != "" {
+				d.from[fields[0]+" "+name] = from
+			}
+		}
+		result.WriteString(line)
+	}
+	if err := endSection(); err != nil {
+		return "", nil, err
+	}
+
+	for section, from := range d.from {
+		if d.get(from) == nil {
+			return "", nil, NewConfError(ErrValidationError, fmt.Sprintf("defaults %s inherited by %s does not exist", from, section))
+		}
+	}
+	for _, s := range d.sections {
+		if s.from == "" {
+			continue
+		}
+		if d.get(s.from) == nil {
+			return "", nil, NewConfError(ErrValidationError, fmt.Sprintf("defaults %s inherited by defaults %s does not exist", s.from, s.name))
+		}
+		if d.inherits(s.name, s.name) {
+			return "", nil, NewConfError(ErrValidationError, fmt.Sprintf("defaults %s inherits from itself", s.name))
+		}
+	}
+	return result.String(), d, nil
+}
+
+// sectionHeader returns the name of the section and the defaults section it inherits from
+func sectionHeader(fields []string) (string, string) {
+	name := ""
+	rest := fields[1:]
+	if len(rest) > 0 && rest[0] != "from" {
+		name = rest[0]
+		rest = rest[1:]
+	}
+	if len(rest) > 1 && rest[0] == "from" {
+		return name, rest[1]
+	}
+	return name, ""
+}
+
+// join returns the configuration written by the parser with the defaults sections. Named
+// defaults sections are written before the anonymous one, followed by an empty anonymous one
+// when there is none, so that sections not naming a defaults section inherit from it.
+func (d *defaultsSections) join(config string) string {
+	if len(d.sections) == 0 && len(d.from) == 0 {
+		return config
+	}
+	lines := []string{}
+	written := len(d.sections) == 0
+	for _, line := range strings.SplitAfter(config, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 || !sectionKeywords[fields[0]] {
+			lines = append(lines, line)
+			continue
+		}
+		if !written && fields[0] != "global" {
+			// before the comments of the section
+			i := len(lines)
+			for i > 0 && strings.HasPrefix(lines[i-1], "#") {
+				i--
+			}
+			if i > 0 && lines[i-1] == "\n" {
+				i--
+			}
+			sections := d.writeSections(fields[0] != "defaults")
+			lines = append(lines[:i], append([]string{sections}, lines[i:]...)...)
+			written = true
+		}
+		section := fields[0]
+		if len(fields) > 1 {
+			section += " " + fields[1]
+		}
+		if from, ok := d.from[section]; ok {
+			line = fmt.Sprintf("%s from %s\n", section, from)
+		}
+		lines = append(lines, line)
+	}
+	if !written {
+		lines = append(lines, d.writeSections(true))
+	}
+	return strings.Join(lines, "")
+}
+
+// writeSections returns the named defaults sections, followed by an empty anonymous defaults
+// section if asked
+func (d *defaultsSections) writeSections(anonymous bool) string {
+	var result strings.Builder
+	for _, s := range d.sections {
+		header := "defaults " + s.name
+		if s.from != "" {
+			header += " from " + s.from
+		}
+		config := s.p.String()
+		found := false
+		for _, line := range strings.SplitAfter(config, "\n") {
+			if fields := strings.Fields(line); !found && len(fields) == 1 && fields[0] == "defaults" {
+				line = header + "\n"
+				found = true
+			}
+			result.WriteString(line)
+		}
+		if !found {
+			result.WriteString("\n" + header + "\n")
+		}
+	}
+	if anonymous {
+		result.WriteString("\ndefaults")
+		if from, ok := d.from["defaults"]; ok {
+			result.WriteString(" from " + from)
+		}
+		result.WriteString("\n")
+	}
+	return result.String()
+}
@@ -3,8 +3,10 @@ package configuration
 import (
 	"fmt"
 	"reflect"
+	"strings"
 	"testing"
 
+	parser "github.com/haproxytech/config-parser/v3"
 	"github.com/haproxytech/models/v2"
 )
 
@@ -186,3 +188,196 @@ func TestPushDefaults(t *testing.T) {
 		t.Error("Should have returned version conflict.")
 	}
 }
+
+func TestMultipleDefaultsRefused(t *testing.T) {
+	confs := map[string]string{
+		"multiple": "defaults\n  mode http\n\ndefaults\n  mode tcp\n\nbackend b\n  mode http\n",
+		"missing":  "defaults\n  mode http\n\nbackend b from web\n  mode http\n",
+	}
+	for name, conf := range confs {
+		path := "/tmp/haproxy-defaults-" + name + ".cfg"
+		if err := prepareTestFile(conf, path); err != nil {
+			t.Fatal(err.Error())
+		}
+		defer deleteTestFile(path)
+
+		c := prepareClient(path)
+		err := c.CreateBackend(&models.Backend{Name: "refused"}, "", 1)
+		if err == nil {
+			t.Errorf("%s: should throw error, defaults sections not supported", name)
+		} else if _, ok := err.(*ConfError); !ok {
+			t.Errorf("%s: unexpected error: %v", name, err)
+		}
+		if _, _, err := c.GetBackend("b", ""); err != nil {
+			t.Errorf("%s: %v", name, err.Error())
+		}
+	}
+}
+
+const namedDefaultsConfig = `# _version=1
+global
+  daemon
+
+defaults base
+  timeout connect 5s
+
+defaults web from base
+  mode http
+  option forwardfor
+
+frontend site
+  bind :80
+  default_backend app
+
+backend app
+  server s1 127.0.0.1:8080
+
+defaults
+  mode tcp
+
+backend db from base
+  server db1 127.0.0.1:5432
+
+backend cache
+  server c1 127.0.0.1:6379
+`
+
+func TestNamedDefaults(t *testing.T) {
+	path := "/tmp/haproxy-named-defaults.cfg"
+	if err := prepareTestFile(namedDefaultsConfig, path); err != nil {
+		t.Fatal(err.Error())
+	}
+	defer deleteTestFile(path)
+	c := prepareClient(path)
+
+	_, sections, err := c.GetNamedDefaultsSections("")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if len(sections) != 2 || sections[0].Name != "base" || sections[1].Name != "web" || sections[1].From != "base" {
+		t.Fatalf("unexpected named defaults sections: %+v", sections)
+	}
+	_, web, err := c.GetNamedDefaults("web", "")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if web.Defaults.Mode != "http" || web.Defaults.Forwardfor == nil {
+		t.Errorf("unexpected defaults web: %+v", web.Defaults)
+	}
+	_, d, err := c.GetDefaultsConfiguration("")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if d.Mode != "tcp" {
+		t.Errorf("anonymous defaults mode %s, expected tcp", d.Mode)
+	}
+
+	inherited := map[string]string{"site": "web", "app": "web", "db": "base", "cache": ""}
+	for _, sectionType := range []parser.Section{parser.Frontends, parser.Backends} {
+		names, _ := c.Parser.SectionsGet(sectionType)
+		for _, name := range names {
+			_, from, err := c.GetInheritedDefaults(sectionType, name, "")
+			if err != nil {
+				t.Fatal(err.Error())
+			}
+			if from != inherited[name] {
+				t.Errorf("%s %s inherits from %q, expected %q", sectionType, name, from, inherited[name])
+			}
+		}
+	}
+
+	if err := c.DeleteNamedDefaults("base", "", 1); err == nil {
+		t.Error("should throw error, defaults base is inherited")
+	}
+	if err := c.CreateNamedDefaults(&NamedDefaults{Name: "web"}, "", 1); err == nil {
+		t.Error("should throw error, defaults web already exists")
+	}
+	if err := c.EditNamedDefaults("base", &NamedDefaults{Name: "base", From: "web"}, "", 1); err == nil {
+		t.Error("should throw error, defaults web inherits from base")
+	}
+
+	api := &NamedDefaults{Name: "api", From: "web", Defaults: &models.Defaults{Mode: "http", Httplog: true}}
+	if err := c.CreateNamedDefaults(api, "", 1); err != nil {
+		t.Fatal(err.Error())
+	}
+	if err := c.CreateBackend(&models.Backend{Name: "api"}, "", 2); err != nil {
+		t.Fatal(err.Error())
+	}
+	if err := c.SetInheritedDefaults(parser.Backends, "api", "api", "", 3); err != nil {
+		t.Fatal(err.Error())
+	}
+	if err := c.SetInheritedDefaults(parser.Backends, "cache", "base", "", 4); err != nil {
+		t.Fatal(err.Error())
+	}
+	if err := c.SetInheritedDefaults(parser.Backends, "db", "", "", 5); err != nil {
+		t.Fatal(err.Error())
+	}
+	if err := c.SetInheritedDefaults(parser.Backends, "app", "missing", "", 6); err == nil {
+		t.Error("should throw error, defaults missing does not exist")
+	}
+	api.Defaults.Httplog = false
+	if err := c.EditNamedDefaults("api", api, "", 6); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	// the configuration file keeps the named defaults sections and what sections inherit
+	c = prepareClient(path)
+	_, sections, err = c.GetNamedDefaultsSections("")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if len(sections) != 3 || sections[2].Name != "api" || sections[2].From != "web" {
+		t.Fatalf("unexpected named defaults sections: %+v", sections)
+	}
+	if sections[2].Defaults.Mode != "http" || sections[2].Defaults.Httplog {
+		t.Errorf("unexpected defaults api: %+v", sections[2].Defaults)
+	}
+	if sections[0].Defaults.ConnectTimeout == nil || *sections[0].Defaults.ConnectTimeout != 5000 {
+		t.Errorf("unexpected defaults base: %+v", sections[0].Defaults)
+	}
+	_, d, _ = c.GetDefaultsConfiguration("")
+	if d.Mode != "tcp" {
+		t.Errorf("anonymous defaults mode %s, expected tcp", d.Mode)
+	}
+	inherited = map[string]string{"site": "web", "app": "web", "db": "", "cache": "base", "api": "api"}
+	for _, sectionType := range []parser.Section{parser.Frontends, parser.Backends} {
+		names, _ := c.Parser.SectionsGet(sectionType)
+		for _, name := range names {
+			_, from, _ := c.GetInheritedDefaults(sectionType, name, "")
+			if from != inherited[name] {
+				t.Errorf("%s %s inherits from %q, expected %q", sectionType, name, from, inherited[name])
+			}
+		}
+	}
+
+	if err := c.DeleteBackend("api", "", 7); err != nil {
+		t.Fatal(err.Error())
+	}
+	if err := c.DeleteNamedDefaults("api", "", 8); err != nil {
+		t.Fatal(err.Error())
+	}
+	if _, _, err := c.GetNamedDefaults("api", ""); err == nil {
+		t.Error("should throw error, defaults api deleted")
+	}
+}
+
+func TestNamedDefaultsWithoutAnonymous(t *testing.T) {
+	conf := "backend first\n  mode tcp\n\ndefaults web\n  mode http\n\nbackend second\n  mode http\n"
+	p := &parser.Parser{}
+	if err := parseConfig(p, conf); err != nil {
+		t.Fatal(err.Error())
+	}
+	defer forgetDefaultsSections(p)
+
+	// sections not inheriting from a named defaults section follow an empty anonymous one
+	expected := []string{"defaults web", "defaults", "backend first", "backend second from web"}
+	headers := []string{}
+	for _, line := range strings.Split(configString(p), "\n") {
+		if line != "" && line[0] != ' ' && line[0] != '#' {
+			headers = append(headers, strings.TrimSpace(line))
+		}
+	}
+	if !reflect.DeepEqual(headers, expected) {
+		t.Errorf("sections %v, expected %v", headers, expected)
+	}
+}
@@ -0,0 +1,192 @@
+// Copyright 2019 HAProxy Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package configuration
+
+import (
+	"fmt"
+	"strings"
+
+	parser "github.com/haproxytech/config-parser/v3"
+)
+
+const emailAlertMailers = "email-alert mailers"
+
+var emailAlertLevels = map[string]bool{
+	"emerg":   true,
+	"alert":   true,
+	"crit":    true,
+	"err":     true,
+	"warning": true,
+	"notice":  true,
+	"info":    true,
+	"debug":   true,
+}
+
+// EmailAlert groups the email-alert directives of a defaults or backend section. Mailers is the
+// name of the mailers section the alerts are sent through.
+type EmailAlert struct {
+	Mailers    string
+	From       string
+	To         string
+	Level      string
+	Myhostname string
+}
+
+// Validate checks that mailers, from and to are set and that the level is a syslog level
+func (e *EmailAlert) Validate() error {
+	values := []struct {
+		name     string
+		value    string
+		required bool
+	}{
+		{"mailers", e.Mailers, true},
+		{"from", e.From, true},
+		{"to", e.To, true},
+		{"level", e.Level, false},
+		{"myhostname", e.Myhostname, false},
+	}
+	for _, v := range values {
+		if v.required && v.value == "" {
+			return fmt.Errorf("email-alert %s is required", v.name)
+		}
+		if strings.ContainsAny(v.value, " \t#") {
+			return fmt.Errorf("invalid email-alert %s %s", v.name, v.value)
+		}
+	}
+	if e.Level != "" && !emailAlertLevels[e.Level] {
+		return fmt.Errorf("invalid email-alert level %s", e.Level)
+	}
+	return nil
+}
+
+func (e *EmailAlert) directives() []struct {
+	keyword string
+	value   *string
+} {
+	return []struct {
+		keyword string
+		value   *string
+	}{
+		{emailAlertMailers, &e.Mailers},
+		{"email-alert from", &e.From},
+		{"email-alert to", &e.To},
+		{"email-alert level", &e.Level},
+		{"email-alert myhostname", &e.Myhostname},
+	}
+}
+
+// GetEmailAlert returns configuration version and the email alert settings of the defaults or
+// backend section. Returns error on fail or if email-alert mailers is not set.
+func (c *Client) GetEmailAlert(parentType string, parentName string, transactionID string) (int64, *EmailAlert, error) {
+	section, name, err := emailAlertSection(parentType, parentName)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	p, err := c.GetParser(transactionID)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	v, err := c.GetVersion(transactionID)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	if !c.checkSectionExists(section, name, p) {
+		return v, nil, NewConfError(ErrObjectDoesNotExist, fmt.Sprintf("%s %s does not exist", parentType, parentName))
+	}
+
+	if _, found, err := getRawDirective(p, section, name, emailAlertMailers); err != nil || !found {
+		if err != nil {
+			return v, nil, c.handleError(emailAlertMailers, parentType, parentName, "", false, err)
+		}
+		return v, nil, NewConfError(ErrObjectDoesNotExist, fmt.Sprintf("%s not set in %s %s", emailAlertMailers, parentType, parentName))
+	}
+
+	e := &EmailAlert{}
+	for _, d := range e.directives() {
+		value, _, err := getRawDirective(p, section, name, d.keyword)
+		if err != nil {
+			return v, nil, c.handleError(d.keyword, parentType, parentName, "", false, err)
+		}
+		*d.value = value
+	}
+	return v, e, nil
+}
+
+// SetEmailAlert replaces the email alert settings of the defaults or backend section, nil data
+// removes them. The mailers section has to exist. One of version or transactionID is mandatory.
+// Returns error on fail, nil on success.
+func (c *Client) SetEmailAlert(parentType string, parentName string, data *EmailAlert, transactionID string, version int64) error {
+	section, name, err := emailAlertSection(parentType, parentName)
+	if err != nil {
+		return err
+	}
+	if data != nil {
+		if err := data.Validate(); err != nil {
+			return NewConfError(ErrValidationError, err.Error())
+		}
+	}
+
+	if err := c.authorize("SetEmailAlert", "email_alert", "", parentType, parentName, transactionID); err != nil {
+		return err
+	}
+
+	p, t, err := c.loadDataForChange(transactionID, version)
+	if err != nil {
+		return err
+	}
+
+	if !c.checkSectionExists(section, name, p) {
+		e := NewConfError(ErrObjectDoesNotExist, fmt.Sprintf("%s %s does not exist", parentType, parentName))
+		return c.handleError("email-alert", parentType, parentName, t, transactionID == "", e)
+	}
+
+	e := data
+	if e == nil {
+		e = &EmailAlert{}
+	} else if !c.checkSectionExists(parser.Mailers, e.Mailers, p) {
+		err := NewConfError(ErrValidationError, fmt.Sprintf("%s %s does not exist", parser.Mailers, e.Mailers))
+		return c.handleError("email-alert", parentType, parentName, t, transactionID == "", err)
+	}
+
+	for _, d := range e.directives() {
+		var value *string
+		if *d.value != "" {
+			value = d.value
+		}
+		if err := setRawDirective(p, section, name, d.keyword, value); err != nil {
+			return c.handleError(d.keyword, parentType, parentName, t, transactionID == "", err)
+		}
+	}
+
+	if err := c.saveData(p, t, transactionID == ""); err != nil {
+		return err
+	}
+	return nil
+}
+
+func emailAlertSection(parentType string, parentName string) (parser.Section, string, error) {
+	switch parentType {
+	case "defaults":
+		return parser.Defaults, parser.DefaultSectionName, nil
+	case "backend":
+		return parser.Backends, parentName, nil
+	default:
+		return "", "", NewConfError(ErrValidationError, fmt.Sprintf("email-alert is not supported in %s", parentType))
+	}
+}
@@ -0,0 +1,79 @@
+// Copyright 2019 HAProxy Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package configuration
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestEmailAlert(t *testing.T) {
+	e := &EmailAlert{
+		Mailers: "email_alerts",
+		From:    "haproxy@example.com",
+		To:      "ops@example.com",
+		Level:   "notice",
+	}
+
+	if err := client.SetEmailAlert("backend", "test", e, "", version); err == nil {
+		t.Error("Should throw error, mailers section email_alerts does not exist")
+	}
+	if err := client.SetEmailAlert("backend", "test", &EmailAlert{Mailers: "email_alerts"}, "", version); err == nil {
+		t.Error("Should throw error, email-alert from and to are required")
+	}
+
+	if err := client.CreateMailersSection(&MailersSection{Name: "email_alerts"}, "", version); err != nil {
+		t.Fatal(err.Error())
+	}
+	version++
+
+	if err := client.SetEmailAlert("backend", "test", e, "", version); err != nil {
+		t.Error(err.Error())
+	} else {
+		version++
+	}
+
+	v, emailAlert, err := client.GetEmailAlert("backend", "test", "")
+	if err != nil {
+		t.Error(err.Error())
+	}
+	if !reflect.DeepEqual(emailAlert, e) {
+		t.Errorf("Email alert %v not equal to given %v", emailAlert, e)
+	}
+	if v != version {
+		t.Errorf("Version %v returned, expected %v", v, version)
+	}
+
+	if err := client.DeleteMailersSection("email_alerts", "", version); err == nil {
+		t.Error("Should throw error, mailers section email_alerts used by backend test")
+		version++
+	}
+
+	if err := client.SetEmailAlert("backend", "test", nil, "", version); err != nil {
+		t.Error(err.Error())
+	} else {
+		version++
+	}
+	if _, _, err := client.GetEmailAlert("backend", "test", ""); err == nil {
+		t.Error("Should throw error, email alert removed from backend test")
+	}
+
+	if err := client.DeleteMailersSection("email_alerts", "", version); err != nil {
+		t.Error(err.Error())
+	} else {
+		version++
+	}
+}
@@ -0,0 +1,331 @@
+// Copyright 2019 HAProxy Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package configuration
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	parser "github.com/haproxytech/config-parser/v3"
+	parser_errors "github.com/haproxytech/config-parser/v3/errors"
+	"github.com/haproxytech/config-parser/v3/types"
+)
+
+var errorPageTypes = []string{"errorfile", "errorloc", "errorloc302", "errorloc303"}
+
+// defaultsErrorfileCodes are the codes the errorfile parser of the defaults section keeps
+var defaultsErrorfileCodes = []int64{200, 400, 403, 405, 408, 425, 429, 500, 502, 503, 504}
+
+// ErrorPage is an errorfile, errorloc, errorloc302 or errorloc303 directive of a defaults,
+// frontend or backend section, the page or redirect returned for the status. A section has a
+// single error page per status.
+type ErrorPage struct {
+	Code int64 `json:"code"`
+	// Type is one of errorfile, errorloc, errorloc302 or errorloc303
+	Type string `json:"type"`
+	// Value is the path of the file for errorfile, the URL of the redirect otherwise
+	Value string `json:"value"`
+}
+
+// Validate checks the code, the type and the value of the error page
+func (e *ErrorPage) Validate() error {
+	found := false
+	for _, s := range httpErrorStatuses {
+		found = found || s == e.Code
+	}
+	if !found {
+		return fmt.Errorf("error page code %d is not supported", e.Code)
+	}
+	found = false
+	for _, t := range errorPageTypes {
+		found = found || t == e.Type
+	}
+	if !found {
+		return fmt.Errorf("error page %d: type must be one of %s", e.Code, strings.Join(errorPageTypes, ", "))
+	}
+	if e.Value == "" || strings.ContainsAny(e.Value, " \t#") {
+		return fmt.Errorf("error page %d: %s value can not be empty nor contain whitespace or '#'", e.Code, e.Type)
+	}
+	return nil
+}
+
+// GetErrorPages returns configuration version and an array of
+// configured error pages in the specified parent. Returns error on fail.
+func (c *Client) GetErrorPages(parentType string, parentName string, transactionID string) (int64, []*ErrorPage, error) {
+	section, name, err := errorPageSection(parentType, parentName)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	p, err := c.GetParser(transactionID)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	v, err := c.GetVersion(transactionID)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	if !c.checkSectionExists(section, name, p) {
+		return v, nil, NewConfError(ErrParentDoesNotExist, fmt.Sprintf("%s %s does not exist", parentType, parentName))
+	}
+
+	pages, err := ParseErrorPages(section, name, p)
+	if err != nil {
+		return v, nil, c.handleError("", parentType, parentName, "", false, err)
+	}
+
+	return v, pages, nil
+}
+
+// GetErrorPage returns configuration version and the error page of the code
+// in the specified parent. Returns error on fail or if error page does not exist.
+func (c *Client) GetErrorPage(code int64, parentType string, parentName string, transactionID string) (int64, *ErrorPage, error) {
+	section, name, err := errorPageSection(parentType, parentName)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	p, err := c.GetParser(transactionID)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	v, err := c.GetVersion(transactionID)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	page, err := getErrorPage(code, section, name, p)
+	if err != nil {
+		return v, nil, c.handleError(strconv.FormatInt(code, 10), parentType, parentName, "", false, err)
+	}
+	if page == nil {
+		return v, nil, NewConfError(ErrObjectDoesNotExist, fmt.Sprintf("error page %d does not exist in %s %s", code, parentType, parentName))
+	}
+
+	return v, page, nil
+}
+
+// DeleteErrorPage deletes the error page of the code in configuration. One of version or
+// transactionID is mandatory. Returns error on fail, nil on success.
+func (c *Client) DeleteErrorPage(code int64, parentType string, parentName string, transactionID string, version int64) error {
+	return c.setErrorPage("DeleteErrorPage", code, parentType, parentName, nil, false, transactionID, version)
+}
+
+// CreateErrorPage creates an error page in configuration. One of version or transactionID is
+// mandatory. Returns error on fail, nil on success.
+func (c *Client) CreateErrorPage(parentType string, parentName string, data *ErrorPage, transactionID string, version int64) error {
+	if err := data.Validate(); err != nil {
+		return NewConfError(ErrValidationError, err.Error())
+	}
+	return c.setErrorPage("CreateErrorPage", data.Code, parentType, parentName, data, true, transactionID, version)
+}
+
+// EditErrorPage edits the error page of the code in configuration, the type can be changed. One
+// of version or transactionID is mandatory. Returns error on fail, nil on success.
+func (c *Client) EditErrorPage(code int64, parentType string, parentName string, data *ErrorPage, transactionID string, version int64) error {
+	if err := data.Validate(); err != nil {
+		return NewConfError(ErrValidationError, err.Error())
+	}
+	if data.Code != code {
+		return NewConfError(ErrValidationError, fmt.Sprintf("error page %d can not be changed to %d", code, data.Code))
+	}
+	return c.setErrorPage("EditErrorPage", code, parentType, parentName, data, false, transactionID, version)
+}
+
+func (c *Client) setErrorPage(operation string, code int64, parentType string, parentName string, data *ErrorPage, create bool, transactionID string, version int64) error {
+	section, name, err := errorPageSection(parentType, parentName)
+	if err != nil {
+		return err
+	}
+
+	if err := c.authorize(operation, "error_page", authorizedIndex(code), parentType, parentName, transactionID); err != nil {
+		return err
+	}
+
+	p, t, err := c.loadDataForChange(transactionID, version)
+	if err != nil {
+		return err
+	}
+
+	id := strconv.FormatInt(code, 10)
+	if !c.checkSectionExists(section, name, p) {
+		e := NewConfError(ErrParentDoesNotExist, fmt.Sprintf("%s %s does not exist", parentType, parentName))
+		return c.handleError(id, parentType, parentName, t, transactionID == "", e)
+	}
+
+	page, err := getErrorPage(code, section, name, p)
+	if err != nil {
+		return c.handleError(id, parentType, parentName, t, transactionID == "", err)
+	}
+	if create && page != nil {
+		e := NewConfError(ErrObjectAlreadyExists, fmt.Sprintf("error page %d already exists in %s %s", code, parentType, parentName))
+		return c.handleError(id, parentType, parentName, t, transactionID == "", e)
+	}
+	if !create && page == nil {
+		e := NewConfError(ErrObjectDoesNotExist, fmt.Sprintf("error page %d does not exist in %s %s", code, parentType, parentName))
+		return c.handleError(id, parentType, parentName, t, transactionID == "", e)
+	}
+
+	if err := serializeErrorPage(p, section, name, code, data); err != nil {
+		return c.handleError(id, parentType, parentName, t, transactionID == "", err)
+	}
+
+	if err := c.saveData(p, t, transactionID == ""); err != nil {
+		return err
+	}
+	return nil
+}
+
+// ParseErrorPages returns the error pages of the section. errorfile lines of the defaults section
+// are handled by the parser, the other lines are kept as unprocessed lines.
+func ParseErrorPages(section parser.Section, name string, p *parser.Parser) ([]*ErrorPage, error) {
+	pages := []*ErrorPage{}
+	if section == parser.Defaults {
+		files, err := getDefaultsErrorfiles(p, name)
+		if err != nil {
+			return nil, err
+		}
+		for _, f := range files {
+			code, err := strconv.ParseInt(f.Code, 10, 64)
+			if err != nil {
+				continue
+			}
+			pages = append(pages, &ErrorPage{Code: code, Type: "errorfile", Value: f.File})
+		}
+	}
+
+	lines, err := getRawLines(p, section, name)
+	if err != nil {
+		return nil, err
+	}
+	for _, l := range lines {
+		if page := ParseErrorPage(l.Value); page != nil {
+			pages = append(pages, page)
+		}
+	}
+	return pages, nil
+}
+
+// ParseErrorPage returns the error page of an errorfile or errorloc line, nil if the line is not one
+func ParseErrorPage(line string) *ErrorPage {
+	words := strings.Fields(line)
+	if len(words) != 3 {
+		return nil
+	}
+	found := false
+	for _, t := range errorPageTypes {
+		found = found || t == words[0]
+	}
+	if !found {
+		return nil
+	}
+	code, err := strconv.ParseInt(words[1], 10, 64)
+	if err != nil {
+		return nil
+	}
+	return &ErrorPage{Code: code, Type: words[0], Value: words[2]}
+}
+
+func SerializeErrorPage(e ErrorPage) string {
+	return fmt.Sprintf("%s %d %s", e.Type, e.Code, e.Value)
+}
+
+// serializeErrorPage replaces the error page of the code in the section, nil data removes it
+func serializeErrorPage(p *parser.Parser, section parser.Section, name string, code int64, data *ErrorPage) error {
+	id := strconv.FormatInt(code, 10)
+	for _, t := range errorPageTypes {
+		if err := setRawDirective(p, section, name, t+" "+id, nil); err != nil {
+			return err
+		}
+	}
+
+	if section == parser.Defaults {
+		files, err := getDefaultsErrorfiles(p, name)
+		if err != nil {
+			return err
+		}
+		result := []types.ErrorFile{}
+		for _, f := range files {
+			if f.Code != id {
+				result = append(result, f)
+			}
+		}
+		if data != nil && data.Type == "errorfile" {
+			supported := false
+			for _, c := range defaultsErrorfileCodes {
+				supported = supported || c == code
+			}
+			if !supported {
+				return NewConfError(ErrValidationError, fmt.Sprintf("errorfile %d is not supported in defaults", code))
+			}
+			result = append(result, types.ErrorFile{Code: id, File: data.Value})
+			data = nil
+		}
+		if len(result) == 0 {
+			if err := p.Set(section, name, "errorfile", nil); err != nil {
+				return err
+			}
+		} else if err := p.Set(section, name, "errorfile", result); err != nil {
+			return err
+		}
+	}
+
+	if data == nil {
+		return nil
+	}
+	return setRawDirective(p, section, name, data.Type+" "+id, &data.Value)
+}
+
+func getDefaultsErrorfiles(p *parser.Parser, name string) ([]types.ErrorFile, error) {
+	data, err := p.Get(parser.Defaults, name, "errorfile", false)
+	if err != nil {
+		if err == parser_errors.ErrFetch {
+			return []types.ErrorFile{}, nil
+		}
+		return nil, err
+	}
+	return data.([]types.ErrorFile), nil
+}
+
+func getErrorPage(code int64, section parser.Section, name string, p *parser.Parser) (*ErrorPage, error) {
+	pages, err := ParseErrorPages(section, name, p)
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range pages {
+		if e.Code == code {
+			return e, nil
+		}
+	}
+	return nil, nil
+}
+
+func errorPageSection(parentType string, parentName string) (parser.Section, string, error) {
+	switch parentType {
+	case "defaults":
+		return parser.Defaults, parser.DefaultSectionName, nil
+	case "frontend":
+		return parser.Frontends, parentName, nil
+	case "backend":
+		return parser.Backends, parentName, nil
+	default:
+		return "", "", NewConfError(ErrValidationError, fmt.Sprintf("error pages are not supported in %s", parentType))
+	}
+}
@@ -0,0 +1,161 @@
+// Copyright 2019 HAProxy Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package configuration
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestGetErrorPages(t *testing.T) {
+	v, pages, err := client.GetErrorPages("defaults", "", "")
+	if err != nil {
+		t.Error(err.Error())
+	}
+	if v != version {
+		t.Errorf("Version %v returned, expected %v", v, version)
+	}
+	// other tests may add error pages to defaults, check only the ones from the test configuration
+	expected := map[int64]string{
+		403: "/test/403.html",
+		500: "/test/500.html",
+		429: "/test/429.html",
+	}
+	for code, path := range expected {
+		found := false
+		for _, p := range pages {
+			if p.Code == code {
+				found = true
+				if p.Type != "errorfile" || p.Value != path {
+					t.Errorf("%v: unexpected error page %v", code, p)
+				}
+			}
+		}
+		if !found {
+			t.Errorf("%v: error page not returned", code)
+		}
+	}
+
+	_, page, err := client.GetErrorPage(403, "defaults", "", "")
+	if err != nil {
+		t.Error(err.Error())
+	} else if page.Type != "errorfile" || page.Value != "/test/403.html" {
+		t.Errorf("403: unexpected error page %v", page)
+	}
+
+	_, _, err = client.GetErrorPage(404, "defaults", "", "")
+	if err == nil {
+		t.Error("Should throw error, non existant error page")
+	}
+}
+
+func TestCreateEditDeleteErrorPage(t *testing.T) {
+	page := &ErrorPage{Code: 503, Type: "errorloc302", Value: "https://status.example.com/"}
+	err := client.CreateErrorPage("frontend", "test", page, "", version)
+	if err != nil {
+		t.Error(err.Error())
+	} else {
+		version++
+	}
+
+	_, ondisk, err := client.GetErrorPage(503, "frontend", "test", "")
+	if err != nil {
+		t.Error(err.Error())
+	}
+	if !reflect.DeepEqual(ondisk, page) {
+		t.Errorf("Created error page %v not equal to given error page %v", ondisk, page)
+	}
+
+	err = client.CreateErrorPage("frontend", "test", page, "", version)
+	if err == nil {
+		t.Error("Should throw error, error page 503 already exists")
+		version++
+	}
+
+	page = &ErrorPage{Code: 503, Type: "errorfile", Value: "/test/503.html"}
+	err = client.EditErrorPage(503, "frontend", "test", page, "", version)
+	if err != nil {
+		t.Error(err.Error())
+	} else {
+		version++
+	}
+
+	_, pages, err := client.GetErrorPages("frontend", "test", "")
+	if err != nil {
+		t.Error(err.Error())
+	}
+	if len(pages) != 1 || !reflect.DeepEqual(pages[0], page) {
+		t.Errorf("Edited error pages %v, expected %v", pages, page)
+	}
+
+	err = client.DeleteErrorPage(503, "frontend", "test", "", version)
+	if err != nil {
+		t.Error(err.Error())
+	} else {
+		version++
+	}
+
+	_, _, err = client.GetErrorPage(503, "frontend", "test", "")
+	if err == nil {
+		t.Error("DeleteErrorPage failed, error page 503 still exists")
+	}
+
+	// errorfile lines of the defaults section are handled by the parser
+	page = &ErrorPage{Code: 502, Type: "errorfile", Value: "/test/502.html"}
+	err = client.CreateErrorPage("defaults", "", page, "", version)
+	if err != nil {
+		t.Error(err.Error())
+	} else {
+		version++
+	}
+
+	page = &ErrorPage{Code: 502, Type: "errorloc", Value: "https://status.example.com/"}
+	err = client.EditErrorPage(502, "defaults", "", page, "", version)
+	if err != nil {
+		t.Error(err.Error())
+	} else {
+		version++
+	}
+
+	_, ondisk, err = client.GetErrorPage(502, "defaults", "", "")
+	if err != nil {
+		t.Error(err.Error())
+	}
+	if !reflect.DeepEqual(ondisk, page) {
+		t.Errorf("Edited error page %v not equal to given error page %v", ondisk, page)
+	}
+
+	err = client.DeleteErrorPage(502, "defaults", "", "", version)
+	if err != nil {
+		t.Error(err.Error())
+	} else {
+		version++
+	}
+
+	page = &ErrorPage{Code: 401, Type: "errorfile", Value: "/test/401.html"}
+	err = client.CreateErrorPage("defaults", "", page, "", version)
+	if err == nil {
+		t.Error("Should throw error, errorfile 401 is not supported in defaults")
+		version++
+	}
+
+	page = &ErrorPage{Code: 418, Type: "errorloc", Value: "https://status.example.com/"}
+	err = client.CreateErrorPage("backend", "test", page, "", version)
+	if err == nil {
+		t.Error("Should throw error, unsupported error page code")
+		version++
+	}
+}
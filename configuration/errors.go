@@ -45,8 +45,13 @@ const (
 	ErrCannotReadConfFile  = 41
 	ErrCannotReadVersion   = 42
 	ErrCannotSetVersion    = 43
+	ErrUnsupportedConfig   = 44
 
 	ErrCannotFindHAProxy = 50
+
+	ErrReloadFailed = 60
+
+	ErrNotAuthorized = 70
 )
 
 // ConfError general configuration client error
@@ -16,6 +16,7 @@
 package configuration
 
 import (
+	"fmt"
 	"strconv"
 
 	"github.com/haproxytech/config-parser/v3/parsers/filters"
@@ -82,6 +83,10 @@ func (c *Client) GetFilter(id int64, parentType, parentName string, transactionI
 // DeleteFilter deletes a filter in configuration. One of version or transactionID is
 // mandatory. Returns error on fail, nil on success.
 func (c *Client) DeleteFilter(id int64, parentType string, parentName string, transactionID string, version int64) error {
+	if err := c.authorize("DeleteFilter", "filter", authorizedIndex(id), parentType, parentName, transactionID); err != nil {
+		return err
+	}
+
 	p, t, err := c.loadDataForChange(transactionID, version)
 	if err != nil {
 		return err
@@ -115,6 +120,10 @@ func (c *Client) CreateFilter(parentType string, parentName string, data *models
 		}
 	}
 
+	if err := c.authorize("CreateFilter", "filter", authorizedIndexP(data.Index), parentType, parentName, transactionID); err != nil {
+		return err
+	}
+
 	p, t, err := c.loadDataForChange(transactionID, version)
 	if err != nil {
 		return err
@@ -127,7 +136,12 @@ func (c *Client) CreateFilter(parentType string, parentName string, data *models
 		section = parser.Frontends
 	}
 
-	if err := p.Insert(section, parentName, "filter", SerializeFilter(*data), int(*data.Index)); err != nil {
+	filter := SerializeFilter(*data)
+	if filter == nil {
+		return NewConfError(ErrValidationError, fmt.Sprintf("unsupported filter type %s", data.Type))
+	}
+
+	if err := p.Insert(section, parentName, "filter", filter, int(*data.Index)); err != nil {
 		return c.handleError(strconv.FormatInt(*data.Index, 10), parentType, parentName, t, transactionID == "", err)
 	}
 
@@ -146,6 +160,10 @@ func (c *Client) EditFilter(id int64, parentType string, parentName string, data
 			return NewConfError(ErrValidationError, validationErr.Error())
 		}
 	}
+	if err := c.authorize("EditFilter", "filter", authorizedIndex(id), parentType, parentName, transactionID); err != nil {
+		return err
+	}
+
 	p, t, err := c.loadDataForChange(transactionID, version)
 	if err != nil {
 		return err
@@ -162,7 +180,12 @@ func (c *Client) EditFilter(id int64, parentType string, parentName string, data
 		return c.handleError(strconv.FormatInt(id, 10), parentType, parentName, t, transactionID == "", err)
 	}
 
-	if err := p.Set(section, parentName, "filter", SerializeFilter(*data), int(id)); err != nil {
+	filter := SerializeFilter(*data)
+	if filter == nil {
+		return NewConfError(ErrValidationError, fmt.Sprintf("unsupported filter type %s", data.Type))
+	}
+
+	if err := p.Set(section, parentName, "filter", filter, int(id)); err != nil {
 		return c.handleError(strconv.FormatInt(id, 10), parentType, parentName, t, transactionID == "", err)
 	}
 
@@ -173,6 +196,61 @@ func (c *Client) EditFilter(id int64, parentType string, parentName string, data
 	return nil
 }
 
+// ReorderFilters reorders the filters of the parent, order lists the indexes of all the
+// filters in their new order. Filters are applied in declaration order, e.g. a cache filter
+// has to be declared after compression. One of version or transactionID is mandatory.
+// Returns error on fail, nil on success.
+func (c *Client) ReorderFilters(parentType string, parentName string, order []int64, transactionID string, version int64) error {
+	if err := c.authorize("ReorderFilters", "filter", "", parentType, parentName, transactionID); err != nil {
+		return err
+	}
+
+	p, t, err := c.loadDataForChange(transactionID, version)
+	if err != nil {
+		return err
+	}
+
+	var section parser.Section
+	if parentType == "backend" {
+		section = parser.Backends
+	} else if parentType == "frontend" {
+		section = parser.Frontends
+	}
+
+	data, err := p.Get(section, parentName, "filter", false)
+	if err != nil {
+		return c.handleError("", parentType, parentName, t, transactionID == "", err)
+	}
+	current := data.([]types.Filter)
+
+	if len(order) != len(current) {
+		e := NewConfError(ErrValidationError, fmt.Sprintf("order lists %d filters, %s %s has %d", len(order), parentType, parentName, len(current)))
+		return c.handleError("", parentType, parentName, t, transactionID == "", e)
+	}
+	reordered := make([]types.Filter, len(order))
+	seen := make(map[int64]bool, len(order))
+	for i, id := range order {
+		if id < 0 || id >= int64(len(current)) || seen[id] {
+			e := NewConfError(ErrValidationError, fmt.Sprintf("invalid filter index %d in order", id))
+			return c.handleError(strconv.FormatInt(id, 10), parentType, parentName, t, transactionID == "", e)
+		}
+		seen[id] = true
+		reordered[i] = current[id]
+	}
+
+	for i, filter := range reordered {
+		if err := p.Set(section, parentName, "filter", filter, i); err != nil {
+			return c.handleError(strconv.Itoa(i), parentType, parentName, t, transactionID == "", err)
+		}
+	}
+
+	if err := c.saveData(p, t, transactionID == ""); err != nil {
+		return err
+	}
+
+	return nil
+}
+
 func ParseFilters(t, pName string, p *parser.Parser) (models.Filters, error) {
 	section := parser.Global
 	if t == "frontend" {
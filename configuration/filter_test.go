@@ -201,3 +201,43 @@ func TestCreateEditDeleteFilter(t *testing.T) {
 		version++
 	}
 }
+
+func TestReorderFilters(t *testing.T) {
+	_, before, err := client.GetFilters("frontend", "test", "")
+	if err != nil {
+		t.Error(err.Error())
+	}
+	if len(before) != 3 {
+		t.Fatalf("%v filters returned, expected 3", len(before))
+	}
+
+	err = client.ReorderFilters("frontend", "test", []int64{2, 1, 0}, "", version)
+	if err != nil {
+		t.Error(err.Error())
+	} else {
+		version++
+	}
+
+	_, after, err := client.GetFilters("frontend", "test", "")
+	if err != nil {
+		t.Error(err.Error())
+	}
+	for i, f := range after {
+		if f.Type != before[2-i].Type || f.TraceName != before[2-i].TraceName {
+			t.Errorf("%v: filter %v not reordered, expected %v", i, f, before[2-i])
+		}
+	}
+
+	err = client.ReorderFilters("frontend", "test", []int64{0, 0, 1}, "", version)
+	if err == nil {
+		t.Error("Should throw error, duplicate index in order")
+		version++
+	}
+
+	err = client.ReorderFilters("frontend", "test", []int64{2, 1, 0}, "", version)
+	if err != nil {
+		t.Error(err.Error())
+	} else {
+		version++
+	}
+}
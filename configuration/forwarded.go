@@ -0,0 +1,241 @@
+// Copyright 2019 HAProxy Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package configuration
+
+import (
+	"fmt"
+	"strings"
+
+	parser "github.com/haproxytech/config-parser/v3"
+	parser_errors "github.com/haproxytech/config-parser/v3/errors"
+)
+
+const forwardedDirective = "option forwarded"
+
+// Forwarded is the option forwarded directive adding the RFC 7239 Forwarded header to requests.
+// Each parameter is either added with its default value or computed by the sample expression,
+// option forwarded without parameters adds only for.
+type Forwarded struct {
+	Proto       bool
+	Host        bool
+	HostExpr    string
+	By          bool
+	ByExpr      string
+	ByPort      bool
+	ByPortExpr  string
+	For         bool
+	ForExpr     string
+	ForPort     bool
+	ForPortExpr string
+}
+
+// Validate checks that each parameter is set only once and that ports come with their address
+func (f *Forwarded) Validate() error {
+	params := []struct {
+		name    string
+		enabled bool
+		expr    string
+	}{
+		{"host", f.Host, f.HostExpr},
+		{"by", f.By, f.ByExpr},
+		{"by_port", f.ByPort, f.ByPortExpr},
+		{"for", f.For, f.ForExpr},
+		{"for_port", f.ForPort, f.ForPortExpr},
+	}
+	for _, param := range params {
+		if param.enabled && param.expr != "" {
+			return fmt.Errorf("%s and %s-expr are mutually exclusive", param.name, param.name)
+		}
+		if strings.ContainsAny(param.expr, " \t#") {
+			return fmt.Errorf("invalid %s-expr %s", param.name, param.expr)
+		}
+	}
+	if (f.ByPort || f.ByPortExpr != "") && !f.By && f.ByExpr == "" {
+		return fmt.Errorf("by_port requires by")
+	}
+	if (f.ForPort || f.ForPortExpr != "") && !f.For && f.ForExpr == "" {
+		return fmt.Errorf("for_port requires for")
+	}
+	return nil
+}
+
+// GetForwarded returns configuration version and the option forwarded of the defaults or backend
+// section. Returns error on fail or if the option is not set.
+func (c *Client) GetForwarded(parentType string, parentName string, transactionID string) (int64, *Forwarded, error) {
+	section, name, err := forwardedSection(parentType, parentName)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	p, err := c.GetParser(transactionID)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	v, err := c.GetVersion(transactionID)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	if !c.checkSectionExists(section, name, p) {
+		return v, nil, NewConfError(ErrObjectDoesNotExist, fmt.Sprintf("%s %s does not exist", parentType, parentName))
+	}
+
+	value, found, err := getRawDirective(p, section, name, forwardedDirective)
+	if err != nil {
+		return v, nil, c.handleError(forwardedDirective, parentType, parentName, "", false, err)
+	}
+	if !found {
+		return v, nil, NewConfError(ErrObjectDoesNotExist, fmt.Sprintf("%s not set in %s %s", forwardedDirective, parentType, parentName))
+	}
+	f, err := parseForwarded(value)
+	if err != nil {
+		return v, nil, c.handleError(forwardedDirective, parentType, parentName, "", false, err)
+	}
+	return v, f, nil
+}
+
+// SetForwarded sets the option forwarded of the defaults or backend section, nil data removes it.
+// The option can not be set together with option forwardfor. One of version or transactionID is
+// mandatory. Returns error on fail, nil on success.
+func (c *Client) SetForwarded(parentType string, parentName string, data *Forwarded, transactionID string, version int64) error {
+	section, name, err := forwardedSection(parentType, parentName)
+	if err != nil {
+		return err
+	}
+	if data != nil {
+		if err := data.Validate(); err != nil {
+			return NewConfError(ErrValidationError, err.Error())
+		}
+	}
+
+	if err := c.authorize("SetForwarded", "forwarded", "", parentType, parentName, transactionID); err != nil {
+		return err
+	}
+
+	p, t, err := c.loadDataForChange(transactionID, version)
+	if err != nil {
+		return err
+	}
+
+	if !c.checkSectionExists(section, name, p) {
+		e := NewConfError(ErrObjectDoesNotExist, fmt.Sprintf("%s %s does not exist", parentType, parentName))
+		return c.handleError(forwardedDirective, parentType, parentName, t, transactionID == "", e)
+	}
+
+	var value *string
+	if data != nil {
+		_, err := p.Get(section, name, "option forwardfor", false)
+		if err == nil {
+			e := NewConfError(ErrValidationError, fmt.Sprintf("%s %s has option forwardfor, option forwarded can not be set", parentType, parentName))
+			return c.handleError(forwardedDirective, parentType, parentName, t, transactionID == "", e)
+		}
+		if err != parser_errors.ErrFetch {
+			return c.handleError(forwardedDirective, parentType, parentName, t, transactionID == "", err)
+		}
+		s := serializeForwarded(data)
+		value = &s
+	}
+	if err := setRawDirective(p, section, name, forwardedDirective, value); err != nil {
+		return c.handleError(forwardedDirective, parentType, parentName, t, transactionID == "", err)
+	}
+
+	if err := c.saveData(p, t, transactionID == ""); err != nil {
+		return err
+	}
+	return nil
+}
+
+func parseForwarded(value string) (*Forwarded, error) {
+	f := &Forwarded{}
+	words := strings.Fields(value)
+	for i := 0; i < len(words); i++ {
+		var expr *string
+		switch words[i] {
+		case "proto":
+			f.Proto = true
+		case "host":
+			f.Host = true
+		case "by":
+			f.By = true
+		case "by_port":
+			f.ByPort = true
+		case "for":
+			f.For = true
+		case "for_port":
+			f.ForPort = true
+		case "host-expr":
+			expr = &f.HostExpr
+		case "by-expr":
+			expr = &f.ByExpr
+		case "by_port-expr":
+			expr = &f.ByPortExpr
+		case "for-expr":
+			expr = &f.ForExpr
+		case "for_port-expr":
+			expr = &f.ForPortExpr
+		default:
+			return nil, fmt.Errorf("unknown %s parameter %s", forwardedDirective, words[i])
+		}
+		if expr != nil {
+			if i+1 >= len(words) {
+				return nil, fmt.Errorf("%s %s requires an expression", forwardedDirective, words[i])
+			}
+			i++
+			*expr = words[i]
+		}
+	}
+	return f, nil
+}
+
+func serializeForwarded(f *Forwarded) string {
+	words := []string{}
+	if f.Proto {
+		words = append(words, "proto")
+	}
+	param := func(name string, enabled bool, expr string) {
+		if expr != "" {
+			words = append(words, name+"-expr", expr)
+		} else if enabled {
+			words = append(words, name)
+		}
+	}
+	param("host", f.Host, f.HostExpr)
+	param("by", f.By, f.ByExpr)
+	param("by_port", f.ByPort, f.ByPortExpr)
+	param("for", f.For, f.ForExpr)
+	param("for_port", f.ForPort, f.ForPortExpr)
+	return strings.Join(words, " ")
+}
+
+// hasForwarded returns true if the section has option forwarded
+func hasForwarded(p *parser.Parser, section parser.Section, name string) (bool, error) {
+	_, found, err := getRawDirective(p, section, name, forwardedDirective)
+	return found, err
+}
+
+func forwardedSection(parentType string, parentName string) (parser.Section, string, error) {
+	switch parentType {
+	case "defaults":
+		return parser.Defaults, parser.DefaultSectionName, nil
+	case "backend":
+		return parser.Backends, parentName, nil
+	default:
+		// like forwardfor the header is added on the way to the server, HAProxy rejects
+		// option forwarded in frontends
+		return "", "", NewConfError(ErrValidationError, fmt.Sprintf("%s is not supported in %s", forwardedDirective, parentType))
+	}
+}
@@ -0,0 +1,96 @@
+// Copyright 2019 HAProxy Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package configuration
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/haproxytech/models/v2"
+
+	"github.com/haproxytech/client-native/v2/misc"
+)
+
+func TestForwarded(t *testing.T) {
+	f := &Forwarded{
+		Proto:    true,
+		HostExpr: "req.hdr(host),lower",
+		By:       true,
+		ByPort:   true,
+		For:      true,
+	}
+
+	if err := client.SetForwarded("frontend", "test", f, "", version); err == nil {
+		t.Error("Should throw error, option forwarded not supported in frontends")
+	}
+	if err := client.SetForwarded("backend", "test_2", f, "", version); err == nil {
+		t.Error("Should throw error, backend test_2 has option forwardfor")
+	}
+	invalid := *f
+	invalid.Host = true
+	if err := client.SetForwarded("backend", "test_2", &invalid, "", version); err == nil {
+		t.Error("Should throw error, host and host-expr are exclusive")
+	}
+	invalid = *f
+	invalid.By = false
+	if err := client.SetForwarded("backend", "test_2", &invalid, "", version); err == nil {
+		t.Error("Should throw error, by_port requires by")
+	}
+
+	err := client.CreateBackend(&models.Backend{Name: "forwarded", Mode: "http"}, "", version)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	version++
+
+	if err := client.SetForwarded("backend", "forwarded", f, "", version); err != nil {
+		t.Fatal(err.Error())
+	}
+	version++
+
+	v, forwarded, err := client.GetForwarded("backend", "forwarded", "")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if !reflect.DeepEqual(forwarded, f) {
+		t.Errorf("Option forwarded %v returned, expected %v", forwarded, f)
+	}
+	if v != version {
+		t.Errorf("Version %v returned, expected %v", v, version)
+	}
+
+	ff := &models.Forwardfor{Enabled: misc.StringP("enabled")}
+	err = client.EditBackend("forwarded", &models.Backend{Name: "forwarded", Mode: "http", Forwardfor: ff}, "", version)
+	if err == nil {
+		t.Error("Should throw error, backend has option forwarded")
+		version++
+	}
+
+	if err := client.SetForwarded("backend", "forwarded", nil, "", version); err != nil {
+		t.Error(err.Error())
+	} else {
+		version++
+	}
+	if _, _, err := client.GetForwarded("backend", "forwarded", ""); err == nil {
+		t.Error("Should throw error, option forwarded removed")
+	}
+
+	if err := client.DeleteBackend("forwarded", "", version); err != nil {
+		t.Error(err.Error())
+	} else {
+		version++
+	}
+}
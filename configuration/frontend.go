@@ -81,7 +81,7 @@ func (c *Client) GetFrontend(name string, transactionID string) (int64, *models.
 // DeleteFrontend deletes a frontend in configuration. One of version or transactionID is
 // mandatory. Returns error on fail, nil on success.
 func (c *Client) DeleteFrontend(name string, transactionID string, version int64) error {
-	if err := c.deleteSection(parser.Frontends, name, transactionID, version); err != nil {
+	if err := c.deleteSection("DeleteFrontend", parser.Frontends, name, transactionID, version); err != nil {
 		return err
 	}
 	return nil
@@ -97,7 +97,11 @@ func (c *Client) EditFrontend(name string, data *models.Frontend, transactionID
 		}
 	}
 
-	if err := c.editSection(parser.Frontends, name, data, transactionID, version); err != nil {
+	if err := validateLogFormats(data.LogFormat, data.LogFormatSd); err != nil {
+		return err
+	}
+
+	if err := c.editSection("EditFrontend", parser.Frontends, name, data, transactionID, version); err != nil {
 		return err
 	}
 
@@ -114,7 +118,11 @@ func (c *Client) CreateFrontend(data *models.Frontend, transactionID string, ver
 		}
 	}
 
-	if err := c.createSection(parser.Frontends, data.Name, data, transactionID, version); err != nil {
+	if err := validateLogFormats(data.LogFormat, data.LogFormatSd); err != nil {
+		return err
+	}
+
+	if err := c.createSection("CreateFrontend", parser.Frontends, data.Name, data, transactionID, version); err != nil {
 		return err
 	}
 
@@ -0,0 +1,146 @@
+// Copyright 2019 HAProxy Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package configuration
+
+import (
+	"fmt"
+	"strconv"
+
+	parser "github.com/haproxytech/config-parser/v3"
+	parser_errors "github.com/haproxytech/config-parser/v3/errors"
+	"github.com/haproxytech/config-parser/v3/types"
+
+	"github.com/haproxytech/client-native/v2/misc"
+)
+
+// FrontendLimits are the capacity limits of a frontend, the concurrent connections it accepts, the
+// pending connections the system queues for it and the new sessions per second it accepts
+type FrontendLimits struct {
+	Maxconn           *int64 `json:"maxconn,omitempty"`
+	Backlog           *int64 `json:"backlog,omitempty"`
+	RateLimitSessions *int64 `json:"rate_limit_sessions,omitempty"`
+}
+
+// Validate checks that the limits are greater than 0
+func (l *FrontendLimits) Validate() error {
+	for name, value := range map[string]*int64{"maxconn": l.Maxconn, "backlog": l.Backlog, "rate-limit sessions": l.RateLimitSessions} {
+		if value != nil && *value <= 0 {
+			return fmt.Errorf("%s has to be greater than 0", name)
+		}
+	}
+	return nil
+}
+
+// GetFrontendLimits returns configuration version and the capacity limits of the frontend.
+// Returns error on fail.
+func (c *Client) GetFrontendLimits(frontend string, transactionID string) (int64, *FrontendLimits, error) {
+	p, err := c.GetParser(transactionID)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	v, err := c.GetVersion(transactionID)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	if !c.checkSectionExists(parser.Frontends, frontend, p) {
+		return v, nil, NewConfError(ErrObjectDoesNotExist, fmt.Sprintf("Frontend %s does not exist", frontend))
+	}
+
+	limits, err := parseFrontendLimits(frontend, p)
+	if err != nil {
+		return v, nil, c.handleError(frontend, "", "", "", false, err)
+	}
+	return v, limits, nil
+}
+
+// SetFrontendLimits sets maxconn, backlog and rate-limit sessions of the frontend, unset values
+// are removed. One of version or transactionID is mandatory. Returns error on fail, nil on
+// success.
+func (c *Client) SetFrontendLimits(frontend string, data *FrontendLimits, transactionID string, version int64) error {
+	if err := data.Validate(); err != nil {
+		return NewConfError(ErrValidationError, err.Error())
+	}
+
+	if err := c.authorize("SetFrontendLimits", "frontend_limits", "", "frontend", frontend, transactionID); err != nil {
+		return err
+	}
+
+	p, t, err := c.loadDataForChange(transactionID, version)
+	if err != nil {
+		return err
+	}
+
+	if !c.checkSectionExists(parser.Frontends, frontend, p) {
+		e := NewConfError(ErrObjectDoesNotExist, fmt.Sprintf("Frontend %s does not exist", frontend))
+		return c.handleError(frontend, "", "", t, transactionID == "", e)
+	}
+
+	var maxconn interface{}
+	if data.Maxconn != nil {
+		maxconn = &types.Int64C{Value: *data.Maxconn}
+	}
+	if err := p.Set(parser.Frontends, frontend, "maxconn", maxconn); err != nil {
+		return c.handleError(frontend, "", "", t, transactionID == "", err)
+	}
+
+	raw := map[string]*int64{"backlog": data.Backlog, "rate-limit sessions": data.RateLimitSessions}
+	for keyword, value := range raw {
+		var s *string
+		if value != nil {
+			s = misc.StringP(strconv.FormatInt(*value, 10))
+		}
+		if err := setRawDirective(p, parser.Frontends, frontend, keyword, s); err != nil {
+			return c.handleError(frontend, "", "", t, transactionID == "", err)
+		}
+	}
+
+	if err := c.saveData(p, t, transactionID == ""); err != nil {
+		return err
+	}
+	return nil
+}
+
+func parseFrontendLimits(frontend string, p *parser.Parser) (*FrontendLimits, error) {
+	limits := &FrontendLimits{}
+
+	data, err := p.Get(parser.Frontends, frontend, "maxconn", false)
+	if err != nil && err != parser_errors.ErrFetch {
+		return nil, err
+	}
+	if err == nil {
+		m := data.(*types.Int64C).Value
+		limits.Maxconn = &m
+	}
+
+	raw := map[string]**int64{"backlog": &limits.Backlog, "rate-limit sessions": &limits.RateLimitSessions}
+	for keyword, limit := range raw {
+		value, found, err := getRawDirective(p, parser.Frontends, frontend, keyword)
+		if err != nil {
+			return nil, err
+		}
+		if !found {
+			continue
+		}
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s %s", keyword, value)
+		}
+		*limit = &n
+	}
+	return limits, nil
+}
@@ -0,0 +1,62 @@
+// Copyright 2019 HAProxy Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package configuration
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/haproxytech/client-native/v2/misc"
+)
+
+func TestSetGetFrontendLimits(t *testing.T) {
+	_, original, err := client.GetFrontendLimits("test", "")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if err := client.SetFrontendLimits("test", &FrontendLimits{Backlog: misc.Int64P(0)}, "", version); err == nil {
+		t.Error("Should throw error, backlog has to be greater than 0")
+	}
+
+	l := &FrontendLimits{
+		Maxconn:           misc.Int64P(2000),
+		Backlog:           misc.Int64P(4096),
+		RateLimitSessions: misc.Int64P(100),
+	}
+	if err := client.SetFrontendLimits("test", l, "", version); err != nil {
+		t.Error(err.Error())
+	} else {
+		version++
+	}
+
+	v, limits, err := client.GetFrontendLimits("test", "")
+	if err != nil {
+		t.Error(err.Error())
+	}
+	if !reflect.DeepEqual(limits, l) {
+		t.Errorf("Frontend limits %v not equal to given %v", limits, l)
+	}
+	if v != version {
+		t.Errorf("Version %v returned, expected %v", v, version)
+	}
+
+	if err := client.SetFrontendLimits("test", original, "", version); err != nil {
+		t.Error(err.Error())
+	} else {
+		version++
+	}
+}
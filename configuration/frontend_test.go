@@ -228,6 +228,13 @@ func TestCreateEditDeleteFrontend(t *testing.T) {
 		t.Errorf("Version %v returned, expected %v", v, version)
 	}
 
+	f.MonitorFail = &models.MonitorFail{Cond: misc.StringP("if")}
+	err = client.EditFrontend("created", f, "", version)
+	if err == nil {
+		t.Error("Should throw error, monitor fail without condition test")
+		version++
+	}
+
 	// TestDeleteFrontend
 	err = client.DeleteFrontend("created", "", version)
 	if err != nil {
@@ -262,3 +269,35 @@ func TestCreateEditDeleteFrontend(t *testing.T) {
 		version++
 	}
 }
+
+func TestCreateFrontendUniqueIDHeaderWithoutFormat(t *testing.T) {
+	f := &models.Frontend{
+		Name:           "unique_id",
+		Mode:           "http",
+		UniqueIDHeader: "X-Request-ID",
+	}
+	if err := client.CreateFrontend(f, "", version); err == nil {
+		t.Error("Should throw error, unique-id-header requires unique-id-format")
+		version++
+	}
+
+	f.UniqueIDFormat = "%{+X}o%ci:%cp_%fi:%fp_%Ts_%rt"
+	if err := client.CreateFrontend(f, "", version); err != nil {
+		t.Error(err.Error())
+	} else {
+		version++
+	}
+
+	_, frontend, err := client.GetFrontend("unique_id", "")
+	if err != nil {
+		t.Error(err.Error())
+	} else if frontend.UniqueIDHeader != f.UniqueIDHeader || frontend.UniqueIDFormat != f.UniqueIDFormat {
+		t.Errorf("unique-id-format %s and unique-id-header %s not equal to given", frontend.UniqueIDFormat, frontend.UniqueIDHeader)
+	}
+
+	if err := client.DeleteFrontend("unique_id", "", version); err != nil {
+		t.Error(err.Error())
+	} else {
+		version++
+	}
+}
@@ -16,14 +16,16 @@
 package configuration
 
 import (
+	"fmt"
+	"regexp"
 	"strconv"
+	"strings"
 
 	"github.com/haproxytech/client-native/v2/misc"
 
 	strfmt "github.com/go-openapi/strfmt"
 	parser "github.com/haproxytech/config-parser/v3"
 	"github.com/haproxytech/config-parser/v3/errors"
-	"github.com/haproxytech/config-parser/v3/params"
 	"github.com/haproxytech/config-parser/v3/types"
 	"github.com/haproxytech/models/v2"
 )
@@ -58,6 +60,16 @@ func (c *Client) PushGlobalConfiguration(data *models.Global, transactionID stri
 			return NewConfError(ErrValidationError, validationErr.Error())
 		}
 	}
+	if err := validateCPUMaps(data.CPUMaps); err != nil {
+		return NewConfError(ErrValidationError, err.Error())
+	}
+	if err := validateSslDefaults(data); err != nil {
+		return NewConfError(ErrValidationError, err.Error())
+	}
+
+	if err := c.authorize("PushGlobalConfiguration", "global", "", "", "", transactionID); err != nil {
+		return err
+	}
 
 	p, t, err := c.loadDataForChange(transactionID, version)
 	if err != nil {
@@ -135,31 +147,9 @@ func ParseGlobalSection(p *parser.Parser) (*models.Global, error) {
 		pidfile = pidfileParser.Value
 	}
 
-	data, err = p.Get(parser.Global, parser.GlobalSectionName, "stats socket")
-	rAPIs := []*models.RuntimeAPI{}
-	if err == nil {
-		sockets := data.([]types.Socket)
-		for _, s := range sockets {
-			p := s.Path
-			rAPI := &models.RuntimeAPI{Address: &p}
-			for _, p := range s.Params {
-				switch v := p.(type) {
-				case *params.BindOptionDoubleWord:
-					if v.Name == "expose-fd" && v.Value == "listener" {
-						rAPI.ExposeFdListeners = true
-					}
-				case *params.BindOptionValue:
-					if v.Name == "level" {
-						rAPI.Level = v.Value
-					} else if v.Name == "mode" {
-						rAPI.Mode = v.Value
-					} else if v.Name == "process" {
-						rAPI.Process = v.Value
-					}
-				}
-			}
-			rAPIs = append(rAPIs, rAPI)
-		}
+	rAPIs, err := ParseRuntimeAPIs(p)
+	if err != nil {
+		return nil, err
 	}
 
 	data, err = p.Get(parser.Global, parser.GlobalSectionName, "cpu-map")
@@ -167,9 +157,11 @@ func ParseGlobalSection(p *parser.Parser) (*models.Global, error) {
 	if err == nil {
 		cMaps := data.([]types.CPUMap)
 		for _, m := range cMaps {
+			process := m.Process
+			cpuSet := m.CPUSet
 			cpuMap := &models.CPUMap{
-				Process: &m.Process,
-				CPUSet:  &m.CPUSet,
+				Process: &process,
+				CPUSet:  &cpuSet,
 			}
 			cpuMaps = append(cpuMaps, cpuMap)
 		}
@@ -365,31 +357,7 @@ func SerializeGlobalSection(p *parser.Parser, data *models.Global) error {
 	if err := p.Set(parser.Global, parser.GlobalSectionName, "pidfile", pPidfile); err != nil {
 		return err
 	}
-	sockets := []types.Socket{}
-	for _, rAPI := range data.RuntimeAPIs {
-		s := types.Socket{
-			Path:   *rAPI.Address,
-			Params: []params.BindOption{},
-		}
-		if rAPI.ExposeFdListeners {
-			p := &params.BindOptionDoubleWord{Name: "expose-fd", Value: "listeners"}
-			s.Params = append(s.Params, p)
-		}
-		if rAPI.Level != "" {
-			p := &params.BindOptionValue{Name: "level", Value: rAPI.Level}
-			s.Params = append(s.Params, p)
-		}
-		if rAPI.Mode != "" {
-			p := &params.BindOptionValue{Name: "mode", Value: rAPI.Mode}
-			s.Params = append(s.Params, p)
-		}
-		if rAPI.Process != "" {
-			p := &params.BindOptionValue{Name: "process", Value: rAPI.Process}
-			s.Params = append(s.Params, p)
-		}
-		sockets = append(sockets, s)
-	}
-	if err := p.Set(parser.Global, parser.GlobalSectionName, "stats socket", sockets); err != nil {
+	if err := SerializeRuntimeAPIs(p, data.RuntimeAPIs); err != nil {
 		return err
 	}
 	var statsTimeout *types.StringC
@@ -506,3 +474,90 @@ func SerializeGlobalSection(p *parser.Parser, data *models.Global) error {
 
 	return p.Set(parser.Global, parser.GlobalSectionName, "external-check", pExternalCheck)
 }
+
+var (
+	cpuMapProcessRegex = regexp.MustCompile(`^(auto:)?(all|odd|even|\d+(-\d*)?)(/(all|odd|even|\d+(-\d*)?))?$`)
+	cpuSetRegex        = regexp.MustCompile(`^\d+(-\d+)?$`)
+)
+
+// validateCPUMaps checks that each cpu-map binds a process or process/thread set, such as
+// 1, 1-4, all/odd or auto:1/1-4, to a list of CPU numbers or ranges
+func validateCPUMaps(cpuMaps []*models.CPUMap) error {
+	for _, m := range cpuMaps {
+		if m == nil || m.Process == nil || m.CPUSet == nil {
+			return fmt.Errorf("cpu-map needs a process set and a cpu set")
+		}
+		if !cpuMapProcessRegex.MatchString(*m.Process) {
+			return fmt.Errorf("cpu-map: invalid process/thread set %s", *m.Process)
+		}
+		cpus := strings.Fields(*m.CPUSet)
+		if len(cpus) == 0 {
+			return fmt.Errorf("cpu-map %s: cpu set is empty", *m.Process)
+		}
+		for _, cpu := range cpus {
+			if !cpuSetRegex.MatchString(cpu) {
+				return fmt.Errorf("cpu-map %s: invalid cpu set %s", *m.Process, cpu)
+			}
+		}
+	}
+	return nil
+}
+
+var (
+	sslVersions      = []string{"SSLv3", "TLSv1.0", "TLSv1.1", "TLSv1.2", "TLSv1.3"}
+	sslDefaultFlags  = []string{"no-sslv3", "no-tlsv10", "no-tlsv11", "no-tlsv12", "no-tlsv13", "no-tls-tickets", "force-sslv3", "force-tlsv10", "force-tlsv11", "force-tlsv12", "force-tlsv13"}
+	sslBindOnlyFlags = []string{"prefer-client-ciphers"}
+)
+
+// validateSslDefaults checks the ssl-default-bind-* and ssl-default-server-* keywords, ciphers are
+// a single word and options are flags or ssl-min-ver/ssl-max-ver followed by a version
+func validateSslDefaults(g *models.Global) error {
+	ciphers := map[string]string{
+		"ssl-default-bind-ciphers":        g.SslDefaultBindCiphers,
+		"ssl-default-bind-ciphersuites":   g.SslDefaultBindCiphersuites,
+		"ssl-default-server-ciphers":      g.SslDefaultServerCiphers,
+		"ssl-default-server-ciphersuites": g.SslDefaultServerCiphersuites,
+	}
+	for keyword, value := range ciphers {
+		if strings.ContainsAny(value, " \t#") {
+			return fmt.Errorf("%s can not contain whitespace or '#'", keyword)
+		}
+	}
+	if err := validateSslDefaultOptions("ssl-default-bind-options", g.SslDefaultBindOptions, true); err != nil {
+		return err
+	}
+	return validateSslDefaultOptions("ssl-default-server-options", g.SslDefaultServerOptions, false)
+}
+
+func validateSslDefaultOptions(keyword string, options string, bind bool) error {
+	words := strings.Fields(options)
+	minVer, maxVer := -1, len(sslVersions)
+	for i := 0; i < len(words); i++ {
+		w := words[i]
+		switch {
+		case w == "ssl-min-ver" || w == "ssl-max-ver":
+			if i+1 == len(words) || !misc.StringInSlice(words[i+1], sslVersions) {
+				return fmt.Errorf("%s: %s needs one of %s", keyword, w, strings.Join(sslVersions, ", "))
+			}
+			i++
+			for v, version := range sslVersions {
+				if version != words[i] {
+					continue
+				}
+				if w == "ssl-min-ver" {
+					minVer = v
+				} else {
+					maxVer = v
+				}
+			}
+		case misc.StringInSlice(w, sslDefaultFlags):
+		case bind && misc.StringInSlice(w, sslBindOnlyFlags):
+		default:
+			return fmt.Errorf("%s: unknown option %s", keyword, w)
+		}
+	}
+	if minVer > maxVer {
+		return fmt.Errorf("%s: ssl-min-ver is greater than ssl-max-ver", keyword)
+	}
+	return nil
+}
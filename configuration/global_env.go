@@ -0,0 +1,202 @@
+// Copyright 2019 HAProxy Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package configuration
+
+import (
+	"fmt"
+	"strings"
+
+	parser "github.com/haproxytech/config-parser/v3"
+	parser_errors "github.com/haproxytech/config-parser/v3/errors"
+	"github.com/haproxytech/config-parser/v3/types"
+
+	"github.com/haproxytech/client-native/v2/misc"
+)
+
+var envDirectives = []string{"presetenv", "resetenv", "setenv", "unsetenv"}
+
+// EnvDirective is an environment variable directive of the global section. setenv and presetenv
+// set the variable to Value, resetenv keeps only the listed variables and unsetenv removes the
+// variable. The config parser keeps a single setenv and a single presetenv line, resetenv and
+// unsetenv list their variables on one line.
+type EnvDirective struct {
+	Directive string `json:"directive"`
+	Name      string `json:"name"`
+	Value     string `json:"value,omitempty"`
+}
+
+// Validate checks the directive, variable name and value
+func (e *EnvDirective) Validate() error {
+	if !misc.StringInSlice(e.Directive, envDirectives) {
+		return fmt.Errorf("directive must be one of %s", strings.Join(envDirectives, ", "))
+	}
+	if e.Name == "" || strings.ContainsAny(e.Name, " \t#=$") {
+		return fmt.Errorf("%s: invalid variable name %s", e.Directive, e.Name)
+	}
+	switch e.Directive {
+	case "setenv", "presetenv":
+		if e.Value == "" || strings.ContainsAny(e.Value, " \t#") {
+			return fmt.Errorf("%s %s: value can not be empty nor contain whitespace or '#'", e.Directive, e.Name)
+		}
+	default:
+		if e.Value != "" {
+			return fmt.Errorf("%s %s: value can not be set", e.Directive, e.Name)
+		}
+	}
+	return nil
+}
+
+// GetEnvDirectives returns configuration version and an array of the
+// environment variable directives of the global section. Returns error on fail.
+func (c *Client) GetEnvDirectives(transactionID string) (int64, []*EnvDirective, error) {
+	p, err := c.GetParser(transactionID)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	v, err := c.GetVersion(transactionID)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	env, err := ParseEnvDirectives(p)
+	if err != nil {
+		return v, nil, c.handleError("", "global", "", "", false, err)
+	}
+
+	return v, env, nil
+}
+
+// CreateEnvDirective adds an environment variable directive to the global section. One of
+// version or transactionID is mandatory. Returns error on fail, nil on success.
+func (c *Client) CreateEnvDirective(data *EnvDirective, transactionID string, version int64) error {
+	if err := data.Validate(); err != nil {
+		return NewConfError(ErrValidationError, err.Error())
+	}
+
+	if err := c.authorize("CreateEnvDirective", "env_directive", data.Name, "global", "", transactionID); err != nil {
+		return err
+	}
+
+	p, t, err := c.loadDataForChange(transactionID, version)
+	if err != nil {
+		return err
+	}
+
+	env, err := ParseEnvDirectives(p)
+	if err != nil {
+		return c.handleError(data.Name, "global", "", t, transactionID == "", err)
+	}
+	names := []string{}
+	for _, d := range env {
+		if d.Directive != data.Directive {
+			continue
+		}
+		if d.Name == data.Name {
+			e := NewConfError(ErrObjectAlreadyExists, fmt.Sprintf("%s %s already exists", data.Directive, data.Name))
+			return c.handleError(data.Name, "global", "", t, transactionID == "", e)
+		}
+		if data.Value != "" {
+			e := NewConfError(ErrValidationError, fmt.Sprintf("%s is already set for %s, only one %s directive is supported", data.Directive, d.Name, data.Directive))
+			return c.handleError(data.Name, "global", "", t, transactionID == "", e)
+		}
+		names = append(names, d.Name)
+	}
+
+	var value interface{}
+	if data.Value != "" {
+		value = &types.StringKeyValueC{Key: data.Name, Value: data.Value}
+	} else {
+		value = &types.StringSliceC{Value: append(names, data.Name)}
+	}
+	if err := p.Set(parser.Global, parser.GlobalSectionName, data.Directive, value); err != nil {
+		return c.handleError(data.Name, "global", "", t, transactionID == "", err)
+	}
+
+	if err := c.saveData(p, t, transactionID == ""); err != nil {
+		return err
+	}
+	return nil
+}
+
+// DeleteEnvDirective removes the variable from the environment variable directive of the global
+// section. One of version or transactionID is mandatory. Returns error on fail, nil on success.
+func (c *Client) DeleteEnvDirective(directive string, name string, transactionID string, version int64) error {
+	if err := c.authorize("DeleteEnvDirective", "env_directive", name, "global", "", transactionID); err != nil {
+		return err
+	}
+
+	p, t, err := c.loadDataForChange(transactionID, version)
+	if err != nil {
+		return err
+	}
+
+	env, err := ParseEnvDirectives(p)
+	if err != nil {
+		return c.handleError(name, "global", "", t, transactionID == "", err)
+	}
+	found := false
+	names := []string{}
+	for _, d := range env {
+		if d.Directive != directive {
+			continue
+		}
+		if d.Name == name {
+			found = true
+			continue
+		}
+		names = append(names, d.Name)
+	}
+	if !found {
+		e := NewConfError(ErrObjectDoesNotExist, fmt.Sprintf("%s %s does not exist", directive, name))
+		return c.handleError(name, "global", "", t, transactionID == "", e)
+	}
+
+	var value interface{}
+	if len(names) > 0 {
+		value = &types.StringSliceC{Value: names}
+	}
+	if err := p.Set(parser.Global, parser.GlobalSectionName, directive, value); err != nil {
+		return c.handleError(name, "global", "", t, transactionID == "", err)
+	}
+
+	if err := c.saveData(p, t, transactionID == ""); err != nil {
+		return err
+	}
+	return nil
+}
+
+func ParseEnvDirectives(p *parser.Parser) ([]*EnvDirective, error) {
+	env := []*EnvDirective{}
+	for _, directive := range envDirectives {
+		data, err := p.Get(parser.Global, parser.GlobalSectionName, directive, false)
+		if err != nil {
+			if err == parser_errors.ErrFetch {
+				continue
+			}
+			return nil, err
+		}
+		switch d := data.(type) {
+		case *types.StringKeyValueC:
+			env = append(env, &EnvDirective{Directive: directive, Name: d.Key, Value: d.Value})
+		case *types.StringSliceC:
+			for _, name := range d.Value {
+				env = append(env, &EnvDirective{Directive: directive, Name: name})
+			}
+		}
+	}
+	return env, nil
+}
@@ -0,0 +1,80 @@
+// Copyright 2019 HAProxy Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package configuration
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCreateDeleteEnvDirective(t *testing.T) {
+	if err := client.CreateEnvDirective(&EnvDirective{Directive: "setenv", Name: "PORT"}, "", version); err == nil {
+		t.Error("Should throw error, setenv requires a value")
+		version++
+	}
+
+	env := []*EnvDirective{
+		{Directive: "presetenv", Name: "LOG_LEVEL", Value: "info"},
+		{Directive: "setenv", Name: "PORT", Value: "8080"},
+		{Directive: "unsetenv", Name: "TMPDIR"},
+		{Directive: "unsetenv", Name: "LD_PRELOAD"},
+	}
+	for _, e := range env {
+		if err := client.CreateEnvDirective(e, "", version); err != nil {
+			t.Fatal(err.Error())
+		}
+		version++
+	}
+	if err := client.CreateEnvDirective(&EnvDirective{Directive: "setenv", Name: "HOST", Value: "0.0.0.0"}, "", version); err == nil {
+		t.Error("Should throw error, setenv is already set")
+		version++
+	}
+	if err := client.CreateEnvDirective(&EnvDirective{Directive: "unsetenv", Name: "TMPDIR"}, "", version); err == nil {
+		t.Error("Should throw error, unsetenv TMPDIR already exists")
+		version++
+	}
+
+	v, directives, err := client.GetEnvDirectives("")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if !reflect.DeepEqual(directives, env) {
+		t.Errorf("Environment directives %v returned, expected %v", directives, env)
+	}
+	if v != version {
+		t.Errorf("Version %v returned, expected %v", v, version)
+	}
+
+	if err := client.DeleteEnvDirective("unsetenv", "TMPDIR", "", version); err != nil {
+		t.Error(err.Error())
+	} else {
+		version++
+	}
+	if err := client.DeleteEnvDirective("unsetenv", "TMPDIR", "", version); err == nil {
+		t.Error("Should throw error, unsetenv TMPDIR does not exist")
+		version++
+	}
+	for _, e := range []*EnvDirective{env[0], env[1], env[3]} {
+		if err := client.DeleteEnvDirective(e.Directive, e.Name, "", version); err != nil {
+			t.Error(err.Error())
+		} else {
+			version++
+		}
+	}
+	if _, directives, _ := client.GetEnvDirectives(""); len(directives) != 0 {
+		t.Errorf("DeleteEnvDirective failed, %v still exist", directives)
+	}
+}
@@ -137,3 +137,41 @@ func TestPutGlobal(t *testing.T) {
 		t.Error("Should have returned version conflict.")
 	}
 }
+
+func TestPutGlobalInvalidCPUMap(t *testing.T) {
+	_, g, err := client.GetGlobalConfiguration("")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	for _, m := range [][2]string{{"1/1", "0-"}, {"first", "0"}, {"1/1", ""}} {
+		process, cpuSet := m[0], m[1]
+		g.CPUMaps = []*models.CPUMap{&models.CPUMap{Process: &process, CPUSet: &cpuSet}}
+		if err := client.PushGlobalConfiguration(g, "", version); err == nil {
+			t.Errorf("Should throw error, invalid cpu-map %s %s", process, cpuSet)
+			version++
+		}
+	}
+}
+
+func TestPutGlobalInvalidSslDefaults(t *testing.T) {
+	_, g, err := client.GetGlobalConfiguration("")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	for _, options := range []string{"no-tlsv10 tls-tickets", "ssl-min-ver", "ssl-min-ver TLSv1.3 ssl-max-ver TLSv1.2"} {
+		g.SslDefaultBindOptions = options
+		if err := client.PushGlobalConfiguration(g, "", version); err == nil {
+			t.Errorf("Should throw error, invalid ssl-default-bind-options %s", options)
+			version++
+		}
+	}
+
+	g.SslDefaultBindOptions = "ssl-min-ver TLSv1.2 prefer-client-ciphers"
+	g.SslDefaultServerOptions = "prefer-client-ciphers"
+	if err := client.PushGlobalConfiguration(g, "", version); err == nil {
+		t.Error("Should throw error, prefer-client-ciphers is a bind option")
+		version++
+	}
+}
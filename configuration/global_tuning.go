@@ -0,0 +1,460 @@
+// Copyright 2019 HAProxy Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package configuration
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	parser "github.com/haproxytech/config-parser/v3"
+	parser_errors "github.com/haproxytech/config-parser/v3/errors"
+	"github.com/haproxytech/config-parser/v3/types"
+
+	"github.com/haproxytech/client-native/v2/misc"
+)
+
+// GlobalTuning holds the global keywords not covered by models.Global, rate limits, buffer
+// tuning and process settings. Pushing models.Global leaves these keywords untouched.
+type GlobalTuning struct {
+	Maxconnrate     *int64 `json:"maxconnrate,omitempty"`
+	Maxcomprate     *int64 `json:"maxcomprate,omitempty"`
+	Maxcompcpuusage *int64 `json:"maxcompcpuusage,omitempty"`
+	Maxpipes        *int64 `json:"maxpipes,omitempty"`
+	Maxsessrate     *int64 `json:"maxsessrate,omitempty"`
+	Maxsslconn      *int64 `json:"maxsslconn,omitempty"`
+	Maxsslrate      *int64 `json:"maxsslrate,omitempty"`
+	// Maxzlibmem is the memory usable by zlib in megabytes
+	Maxzlibmem *int64 `json:"maxzlibmem,omitempty"`
+	// TuneBufsize is the buffer size in bytes, tune.bufsize
+	TuneBufsize *int64 `json:"tune_bufsize,omitempty"`
+	// TuneMaxrewrite is the buffer space reserved for header rewriting in bytes, tune.maxrewrite
+	TuneMaxrewrite *int64 `json:"tune_maxrewrite,omitempty"`
+	// HardStopAfter is the maximum time old processes keep running after a soft stop in
+	// milliseconds
+	HardStopAfter *int64 `json:"hard_stop_after,omitempty"`
+	// Grace is the time a stopping process keeps accepting connections in milliseconds
+	Grace *int64 `json:"grace,omitempty"`
+	// MworkerMaxReloads is the number of reloads a worker survives before being killed in
+	// master-worker mode, mworker-max-reloads
+	MworkerMaxReloads *int64 `json:"mworker_max_reloads,omitempty"`
+	// ThreadGroups is the number of thread groups the threads are spread over, thread-groups
+	ThreadGroups *int64 `json:"thread_groups,omitempty"`
+	// TuneH2HeaderTableSize is the size of the HPACK header table in bytes,
+	// tune.h2.header-table-size
+	TuneH2HeaderTableSize *int64 `json:"tune_h2_header_table_size,omitempty"`
+	// TuneH2InitialWindowSize is the initial HTTP/2 stream window size in bytes,
+	// tune.h2.initial-window-size
+	TuneH2InitialWindowSize *int64 `json:"tune_h2_initial_window_size,omitempty"`
+	// TuneH2MaxConcurrentStreams is the number of concurrent streams per HTTP/2 connection,
+	// tune.h2.max-concurrent-streams
+	TuneH2MaxConcurrentStreams *int64           `json:"tune_h2_max_concurrent_streams,omitempty"`
+	Localpeer                  string           `json:"localpeer,omitempty"`
+	Nosplice                   bool             `json:"nosplice,omitempty"`
+	ServerStateBase            string           `json:"server_state_base,omitempty"`
+	ServerStateFile            string           `json:"server_state_file,omitempty"`
+	SslDhParamFile             string           `json:"ssl_dh_param_file,omitempty"`
+	SslEngine                  *GlobalSslEngine `json:"ssl_engine,omitempty"`
+	SslModeAsync               bool             `json:"ssl_mode_async,omitempty"`
+	// SslServerVerify is the default verification of server certificates, none or required
+	SslServerVerify string `json:"ssl_server_verify,omitempty"`
+	// Tune holds the other tune.* keywords with their value as written in configuration, such as
+	// tune.http.maxhdr or tune.ssl.cachesize
+	Tune map[string]string `json:"tune,omitempty"`
+}
+
+// GlobalSslEngine is an OpenSSL engine used for the listed algorithms, all algorithms when none
+// are listed
+type GlobalSslEngine struct {
+	Name       string   `json:"name"`
+	Algorithms []string `json:"algorithms,omitempty"`
+}
+
+// tuneParserKeywords are the tune.* keywords with a parser of their own
+var tuneParserKeywords = []string{"tune.bufsize", "tune.maxrewrite", "tune.ssl.default-dh-param"}
+
+// h2Numbers returns the HTTP/2 tune.* keywords, kept as raw lines in configuration
+func (g *GlobalTuning) h2Numbers() map[string]**int64 {
+	return map[string]**int64{
+		"tune.h2.header-table-size":      &g.TuneH2HeaderTableSize,
+		"tune.h2.initial-window-size":    &g.TuneH2InitialWindowSize,
+		"tune.h2.max-concurrent-streams": &g.TuneH2MaxConcurrentStreams,
+	}
+}
+
+func (g *GlobalTuning) numbers() map[string]**int64 {
+	return map[string]**int64{
+		"maxconnrate":     &g.Maxconnrate,
+		"maxcomprate":     &g.Maxcomprate,
+		"maxcompcpuusage": &g.Maxcompcpuusage,
+		"maxpipes":        &g.Maxpipes,
+		"maxsessrate":     &g.Maxsessrate,
+		"maxsslconn":      &g.Maxsslconn,
+		"maxsslrate":      &g.Maxsslrate,
+		"maxzlibmem":      &g.Maxzlibmem,
+		"tune.bufsize":    &g.TuneBufsize,
+		"tune.maxrewrite": &g.TuneMaxrewrite,
+	}
+}
+
+func (g *GlobalTuning) words() map[string]*string {
+	return map[string]*string{
+		"localpeer":         &g.Localpeer,
+		"server-state-base": &g.ServerStateBase,
+		"server-state-file": &g.ServerStateFile,
+		"ssl-dh-param-file": &g.SslDhParamFile,
+		"ssl-server-verify": &g.SslServerVerify,
+	}
+}
+
+// Validate checks the values of the global keywords
+func (g *GlobalTuning) Validate() error {
+	for keyword, v := range g.numbers() {
+		if *v != nil && **v < 0 {
+			return fmt.Errorf("%s can not be negative", keyword)
+		}
+	}
+	if g.Maxcompcpuusage != nil && *g.Maxcompcpuusage > 100 {
+		return fmt.Errorf("maxcompcpuusage is a percentage and can not exceed 100")
+	}
+	if g.TuneBufsize != nil && g.TuneMaxrewrite != nil && *g.TuneMaxrewrite > *g.TuneBufsize/2 {
+		return fmt.Errorf("tune.maxrewrite can not exceed half of tune.bufsize")
+	}
+	if g.HardStopAfter != nil && *g.HardStopAfter <= 0 {
+		return fmt.Errorf("hard-stop-after has to be greater than 0")
+	}
+	if g.Grace != nil && *g.Grace < 0 {
+		return fmt.Errorf("grace can not be negative")
+	}
+	if g.MworkerMaxReloads != nil && *g.MworkerMaxReloads <= 0 {
+		return fmt.Errorf("mworker-max-reloads has to be greater than 0")
+	}
+	if g.ThreadGroups != nil && (*g.ThreadGroups < 1 || *g.ThreadGroups > 16) {
+		return fmt.Errorf("thread-groups has to be between 1 and 16")
+	}
+	h2 := g.h2Numbers()
+	for keyword, v := range h2 {
+		if *v != nil && **v <= 0 {
+			return fmt.Errorf("%s has to be greater than 0", keyword)
+		}
+	}
+	if g.TuneH2HeaderTableSize != nil && *g.TuneH2HeaderTableSize > 65535 {
+		return fmt.Errorf("tune.h2.header-table-size can not exceed 65535")
+	}
+	if g.TuneH2InitialWindowSize != nil && *g.TuneH2InitialWindowSize > 2147483647 {
+		return fmt.Errorf("tune.h2.initial-window-size can not exceed 2147483647")
+	}
+	for keyword, v := range g.words() {
+		if strings.ContainsAny(*v, " \t#") {
+			return fmt.Errorf("%s can not contain whitespace or '#'", keyword)
+		}
+	}
+	if g.SslServerVerify != "" && g.SslServerVerify != "none" && g.SslServerVerify != "required" {
+		return fmt.Errorf("ssl-server-verify must be none or required")
+	}
+	if g.SslEngine != nil {
+		if g.SslEngine.Name == "" || strings.ContainsAny(g.SslEngine.Name, " \t#") {
+			return fmt.Errorf("invalid ssl-engine name %s", g.SslEngine.Name)
+		}
+		for _, a := range g.SslEngine.Algorithms {
+			if a == "" || strings.ContainsAny(a, " \t#,") {
+				return fmt.Errorf("ssl-engine %s: invalid algorithm %s", g.SslEngine.Name, a)
+			}
+		}
+	}
+	for keyword, value := range g.Tune {
+		if !strings.HasPrefix(keyword, "tune.") || strings.ContainsAny(keyword, " \t#") {
+			return fmt.Errorf("invalid tune keyword %s", keyword)
+		}
+		if _, ok := h2[keyword]; ok || misc.StringInSlice(keyword, tuneParserKeywords) {
+			return fmt.Errorf("%s has to be set with its own field", keyword)
+		}
+		if strings.TrimSpace(value) == "" || strings.ContainsAny(value, "#\n") {
+			return fmt.Errorf("%s: invalid value %s", keyword, value)
+		}
+	}
+	return nil
+}
+
+// GetGlobalTuning returns configuration version and the global keywords
+// not covered by models.Global. Returns error on fail.
+func (c *Client) GetGlobalTuning(transactionID string) (int64, *GlobalTuning, error) {
+	p, err := c.GetParser(transactionID)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	v, err := c.GetVersion(transactionID)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	g, err := ParseGlobalTuning(p)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return v, g, nil
+}
+
+// PushGlobalTuning replaces the global keywords not covered by models.Global. One of version or
+// transactionID is mandatory. Returns error on fail, nil on success.
+func (c *Client) PushGlobalTuning(data *GlobalTuning, transactionID string, version int64) error {
+	if err := data.Validate(); err != nil {
+		return NewConfError(ErrValidationError, err.Error())
+	}
+
+	if err := c.authorize("PushGlobalTuning", "global_tuning", "", "global", "", transactionID); err != nil {
+		return err
+	}
+
+	p, t, err := c.loadDataForChange(transactionID, version)
+	if err != nil {
+		return err
+	}
+
+	if err := SerializeGlobalTuning(p, data); err != nil {
+		return c.handleError("", "global", "", t, transactionID == "", err)
+	}
+	if err := c.saveData(p, t, transactionID == ""); err != nil {
+		return err
+	}
+	return nil
+}
+
+func ParseGlobalTuning(p *parser.Parser) (*GlobalTuning, error) {
+	g := &GlobalTuning{}
+	get := func(attribute string) (interface{}, error) {
+		data, err := p.Get(parser.Global, parser.GlobalSectionName, attribute, false)
+		if err == parser_errors.ErrFetch {
+			return nil, nil
+		}
+		return data, err
+	}
+
+	for keyword, v := range g.numbers() {
+		data, err := get(keyword)
+		if err != nil {
+			return nil, err
+		}
+		if data != nil {
+			value := data.(*types.Int64C).Value
+			*v = &value
+		}
+	}
+	for keyword, v := range g.words() {
+		data, err := get(keyword)
+		if err != nil {
+			return nil, err
+		}
+		if data != nil {
+			*v = data.(*types.StringC).Value
+		}
+	}
+
+	data, err := get("hard-stop-after")
+	if err != nil {
+		return nil, err
+	}
+	if data != nil {
+		g.HardStopAfter = misc.ParseTimeout(data.(*types.StringC).Value)
+	}
+	if data, err = get("nosplice"); err != nil {
+		return nil, err
+	}
+	g.Nosplice = data != nil
+	if data, err = get("ssl-mode-async"); err != nil {
+		return nil, err
+	}
+	g.SslModeAsync = data != nil
+	if data, err = get("ssl-engine"); err != nil {
+		return nil, err
+	}
+	if data != nil {
+		engine := data.(*types.SslEngine)
+		g.SslEngine = &GlobalSslEngine{Name: engine.Name, Algorithms: engine.Algorithms}
+	}
+
+	grace, found, err := getRawDirective(p, parser.Global, parser.GlobalSectionName, "grace")
+	if err != nil {
+		return nil, err
+	}
+	if found {
+		g.Grace = misc.ParseTimeout(grace)
+	}
+	maxReloads, found, err := getRawDirective(p, parser.Global, parser.GlobalSectionName, "mworker-max-reloads")
+	if err != nil {
+		return nil, err
+	}
+	if found {
+		n, err := strconv.ParseInt(maxReloads, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid mworker-max-reloads %s", maxReloads)
+		}
+		g.MworkerMaxReloads = &n
+	}
+
+	threadGroups, found, err := getRawDirective(p, parser.Global, parser.GlobalSectionName, "thread-groups")
+	if err != nil {
+		return nil, err
+	}
+	if found {
+		n, err := strconv.ParseInt(threadGroups, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid thread-groups %s", threadGroups)
+		}
+		g.ThreadGroups = &n
+	}
+
+	lines, err := getRawLines(p, parser.Global, parser.GlobalSectionName)
+	if err != nil {
+		return nil, err
+	}
+	h2 := g.h2Numbers()
+	for _, l := range lines {
+		words := strings.Fields(l.Value)
+		if len(words) < 2 || !strings.HasPrefix(words[0], "tune.") {
+			continue
+		}
+		if v, ok := h2[words[0]]; ok {
+			n, err := strconv.ParseInt(words[1], 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s %s", words[0], words[1])
+			}
+			*v = &n
+			continue
+		}
+		if g.Tune == nil {
+			g.Tune = map[string]string{}
+		}
+		g.Tune[words[0]] = strings.Join(words[1:], " ")
+	}
+	return g, nil
+}
+
+func SerializeGlobalTuning(p *parser.Parser, data *GlobalTuning) error {
+	set := func(attribute string, value interface{}) error {
+		return p.Set(parser.Global, parser.GlobalSectionName, attribute, value)
+	}
+
+	for keyword, v := range data.numbers() {
+		var value interface{}
+		if *v != nil {
+			value = &types.Int64C{Value: **v}
+		}
+		if err := set(keyword, value); err != nil {
+			return err
+		}
+	}
+	for keyword, v := range data.words() {
+		var value interface{}
+		if *v != "" {
+			value = &types.StringC{Value: *v}
+		}
+		if err := set(keyword, value); err != nil {
+			return err
+		}
+	}
+
+	var value interface{}
+	if data.HardStopAfter != nil {
+		value = &types.StringC{Value: strconv.FormatInt(*data.HardStopAfter, 10) + "ms"}
+	}
+	if err := set("hard-stop-after", value); err != nil {
+		return err
+	}
+	value = nil
+	if data.Nosplice {
+		value = &types.Enabled{}
+	}
+	if err := set("nosplice", value); err != nil {
+		return err
+	}
+	value = nil
+	if data.SslModeAsync {
+		value = &types.SslModeAsync{}
+	}
+	if err := set("ssl-mode-async", value); err != nil {
+		return err
+	}
+	value = nil
+	if data.SslEngine != nil {
+		value = &types.SslEngine{Name: data.SslEngine.Name, Algorithms: data.SslEngine.Algorithms}
+	}
+	if err := set("ssl-engine", value); err != nil {
+		return err
+	}
+
+	lines, err := getRawLines(p, parser.Global, parser.GlobalSectionName)
+	if err != nil {
+		return err
+	}
+	result := []types.UnProcessed{}
+	for _, l := range lines {
+		if words := strings.Fields(l.Value); len(words) == 0 || !strings.HasPrefix(words[0], "tune.") {
+			result = append(result, l)
+		}
+	}
+	h2 := data.h2Numbers()
+	keywords := make([]string, 0, len(h2))
+	for keyword, v := range h2 {
+		if *v != nil {
+			keywords = append(keywords, keyword)
+		}
+	}
+	sort.Strings(keywords)
+	for _, keyword := range keywords {
+		result = append(result, types.UnProcessed{Value: rawDirectiveLine(keyword, strconv.FormatInt(**h2[keyword], 10))})
+	}
+	keywords = make([]string, 0, len(data.Tune))
+	for keyword := range data.Tune {
+		keywords = append(keywords, keyword)
+	}
+	sort.Strings(keywords)
+	for _, keyword := range keywords {
+		result = append(result, types.UnProcessed{Value: rawDirectiveLine(keyword, strings.TrimSpace(data.Tune[keyword]))})
+	}
+	if len(result) == 0 {
+		value = nil
+	} else {
+		value = result
+	}
+	if err := set("", value); err != nil {
+		return err
+	}
+
+	var grace *string
+	if data.Grace != nil {
+		grace = misc.StringP(strconv.FormatInt(*data.Grace, 10) + "ms")
+	}
+	if err := setRawDirective(p, parser.Global, parser.GlobalSectionName, "grace", grace); err != nil {
+		return err
+	}
+	var maxReloads *string
+	if data.MworkerMaxReloads != nil {
+		maxReloads = misc.StringP(strconv.FormatInt(*data.MworkerMaxReloads, 10))
+	}
+	if err := setRawDirective(p, parser.Global, parser.GlobalSectionName, "mworker-max-reloads", maxReloads); err != nil {
+		return err
+	}
+
+	var threadGroups *string
+	if data.ThreadGroups != nil {
+		threadGroups = misc.StringP(strconv.FormatInt(*data.ThreadGroups, 10))
+	}
+	return setRawDirective(p, parser.Global, parser.GlobalSectionName, "thread-groups", threadGroups)
+}
@@ -0,0 +1,136 @@
+// Copyright 2019 HAProxy Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package configuration
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/haproxytech/client-native/v2/misc"
+)
+
+func TestPushGlobalTuning(t *testing.T) {
+	_, g, err := client.GetGlobalTuning("")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if !reflect.DeepEqual(g, &GlobalTuning{}) {
+		t.Errorf("Unexpected global tuning %v", g)
+	}
+
+	g = &GlobalTuning{
+		Maxconnrate:                misc.Int64P(1000),
+		Maxsslrate:                 misc.Int64P(200),
+		TuneBufsize:                misc.Int64P(32768),
+		TuneMaxrewrite:             misc.Int64P(20000),
+		HardStopAfter:              misc.Int64P(30000),
+		Grace:                      misc.Int64P(5000),
+		MworkerMaxReloads:          misc.Int64P(50),
+		ThreadGroups:               misc.Int64P(2),
+		TuneH2HeaderTableSize:      misc.Int64P(8192),
+		TuneH2MaxConcurrentStreams: misc.Int64P(200),
+		Nosplice:                   true,
+		SslEngine:                  &GlobalSslEngine{Name: "rdrand", Algorithms: []string{"RAND"}},
+		Tune: map[string]string{
+			"tune.http.maxhdr": "128",
+		},
+	}
+	if err := client.PushGlobalTuning(g, "", version); err == nil {
+		t.Error("Should throw error, tune.maxrewrite exceeds half of tune.bufsize")
+		version++
+	}
+	g.TuneMaxrewrite = misc.Int64P(1024)
+	g.Tune["tune.bufsize"] = "16384"
+	if err := client.PushGlobalTuning(g, "", version); err == nil {
+		t.Error("Should throw error, tune.bufsize has its own field")
+		version++
+	}
+	delete(g.Tune, "tune.bufsize")
+	g.Tune["tune.h2.initial-window-size"] = "131072"
+	if err := client.PushGlobalTuning(g, "", version); err == nil {
+		t.Error("Should throw error, tune.h2.initial-window-size has its own field")
+		version++
+	}
+	delete(g.Tune, "tune.h2.initial-window-size")
+	g.TuneH2InitialWindowSize = misc.Int64P(0)
+	if err := client.PushGlobalTuning(g, "", version); err == nil {
+		t.Error("Should throw error, tune.h2.initial-window-size has to be greater than 0")
+		version++
+	}
+	g.TuneH2InitialWindowSize = misc.Int64P(131072)
+	g.ThreadGroups = misc.Int64P(17)
+	if err := client.PushGlobalTuning(g, "", version); err == nil {
+		t.Error("Should throw error, thread-groups can not exceed 16")
+		version++
+	}
+	g.ThreadGroups = misc.Int64P(16)
+	g.Grace = misc.Int64P(-1)
+	if err := client.PushGlobalTuning(g, "", version); err == nil {
+		t.Error("Should throw error, grace can not be negative")
+		version++
+	}
+	g.Grace = misc.Int64P(5000)
+	g.MworkerMaxReloads = misc.Int64P(0)
+	if err := client.PushGlobalTuning(g, "", version); err == nil {
+		t.Error("Should throw error, mworker-max-reloads has to be greater than 0")
+		version++
+	}
+	g.MworkerMaxReloads = misc.Int64P(50)
+
+	if err := client.PushGlobalTuning(g, "", version); err != nil {
+		t.Fatal(err.Error())
+	}
+	version++
+
+	v, tuning, err := client.GetGlobalTuning("")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if !reflect.DeepEqual(tuning, g) {
+		t.Errorf("Global tuning %v returned, expected %v", tuning, g)
+	}
+	_, raw, err := client.GetRawConfiguration("", 0)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	for _, line := range []string{"grace 5000ms", "mworker-max-reloads 50"} {
+		if !strings.Contains(raw, line) {
+			t.Errorf("%s not found in the configuration", line)
+		}
+	}
+	if v != version {
+		t.Errorf("Version %v returned, expected %v", v, version)
+	}
+
+	_, global, err := client.GetGlobalConfiguration("")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if err := client.PushGlobalConfiguration(global, "", version); err != nil {
+		t.Fatal(err.Error())
+	}
+	version++
+	if _, tuning, _ = client.GetGlobalTuning(""); !reflect.DeepEqual(tuning, g) {
+		t.Errorf("PushGlobalConfiguration changed global tuning to %v", tuning)
+	}
+
+	if err := client.PushGlobalTuning(&GlobalTuning{}, "", version); err != nil {
+		t.Error(err.Error())
+	} else {
+		version++
+	}
+}
@@ -0,0 +1,266 @@
+// Copyright 2019 HAProxy Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package configuration
+
+import (
+	"fmt"
+	"strings"
+
+	parser "github.com/haproxytech/config-parser/v3"
+	parser_errors "github.com/haproxytech/config-parser/v3/errors"
+	"github.com/haproxytech/config-parser/v3/types"
+
+	"github.com/haproxytech/client-native/v2/misc"
+)
+
+// Group is a group of a userlist
+type Group struct {
+	Name string `json:"name"`
+	// Users of the userlist belonging to the group, in addition to the users listing the group
+	Users []string `json:"users,omitempty"`
+}
+
+// GetGroups returns configuration version and an array of
+// configured groups in the specified userlist. Returns error on fail.
+func (c *Client) GetGroups(userlist string, transactionID string) (int64, []*Group, error) {
+	p, err := c.GetParser(transactionID)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	v, err := c.GetVersion(transactionID)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	if !c.checkSectionExists(parser.UserList, userlist, p) {
+		return v, nil, NewConfError(ErrParentDoesNotExist, fmt.Sprintf("Userlist %s does not exist", userlist))
+	}
+
+	groups, err := ParseGroups(userlist, p)
+	if err != nil {
+		return v, nil, c.handleError("", "userlist", userlist, "", false, err)
+	}
+
+	return v, groups, nil
+}
+
+// GetGroup returns configuration version and a requested group
+// in the specified userlist. Returns error on fail or if group does not exist.
+func (c *Client) GetGroup(name string, userlist string, transactionID string) (int64, *Group, error) {
+	p, err := c.GetParser(transactionID)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	v, err := c.GetVersion(transactionID)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	group, _ := GetGroupByName(name, userlist, p)
+	if group == nil {
+		return v, nil, NewConfError(ErrObjectDoesNotExist, fmt.Sprintf("Group %s does not exist in userlist %s", name, userlist))
+	}
+
+	return v, group, nil
+}
+
+// DeleteGroup deletes a group in configuration, the group is removed from the users listing it.
+// One of version or transactionID is mandatory. Returns error on fail, nil on success.
+func (c *Client) DeleteGroup(name string, userlist string, transactionID string, version int64) error {
+	if err := c.authorize("DeleteGroup", "group", name, "userlist", userlist, transactionID); err != nil {
+		return err
+	}
+
+	p, t, err := c.loadDataForChange(transactionID, version)
+	if err != nil {
+		return err
+	}
+
+	group, i := GetGroupByName(name, userlist, p)
+	if group == nil {
+		e := NewConfError(ErrObjectDoesNotExist, fmt.Sprintf("Group %s does not exist in userlist %s", name, userlist))
+		return c.handleError(name, "userlist", userlist, t, transactionID == "", e)
+	}
+
+	if err := p.Delete(parser.UserList, userlist, "group", i); err != nil {
+		return c.handleError(name, "userlist", userlist, t, transactionID == "", err)
+	}
+
+	users, err := ParseUsers(userlist, p)
+	if err != nil {
+		return c.handleError(name, "userlist", userlist, t, transactionID == "", err)
+	}
+	for i, u := range users {
+		if !misc.StringInSlice(name, u.Groups) {
+			continue
+		}
+		u.Groups = removeString(u.Groups, name)
+		if err := p.Set(parser.UserList, userlist, "user", SerializeUser(*u), i); err != nil {
+			return c.handleError(name, "userlist", userlist, t, transactionID == "", err)
+		}
+	}
+
+	if err := c.saveData(p, t, transactionID == ""); err != nil {
+		return err
+	}
+	return nil
+}
+
+// CreateGroup creates a group in configuration. The users of the group have to exist. One of
+// version or transactionID is mandatory. Returns error on fail, nil on success.
+func (c *Client) CreateGroup(userlist string, data *Group, transactionID string, version int64) error {
+	if err := validateGroup(data); err != nil {
+		return NewConfError(ErrValidationError, err.Error())
+	}
+	if err := c.authorize("CreateGroup", "group", data.Name, "userlist", userlist, transactionID); err != nil {
+		return err
+	}
+
+	p, t, err := c.loadDataForChange(transactionID, version)
+	if err != nil {
+		return err
+	}
+
+	if !c.checkSectionExists(parser.UserList, userlist, p) {
+		e := NewConfError(ErrParentDoesNotExist, fmt.Sprintf("Userlist %s does not exist", userlist))
+		return c.handleError(data.Name, "userlist", userlist, t, transactionID == "", e)
+	}
+
+	group, _ := GetGroupByName(data.Name, userlist, p)
+	if group != nil {
+		e := NewConfError(ErrObjectAlreadyExists, fmt.Sprintf("Group %s already exists in userlist %s", data.Name, userlist))
+		return c.handleError(data.Name, "userlist", userlist, t, transactionID == "", e)
+	}
+
+	if err := checkGroupUsers(data, userlist, p); err != nil {
+		return c.handleError(data.Name, "userlist", userlist, t, transactionID == "", err)
+	}
+	if err := p.Insert(parser.UserList, userlist, "group", SerializeGroup(*data), -1); err != nil {
+		return c.handleError(data.Name, "userlist", userlist, t, transactionID == "", err)
+	}
+
+	if err := c.saveData(p, t, transactionID == ""); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// EditGroup edits a group in configuration. The users of the group have to exist. One of version
+// or transactionID is mandatory. Returns error on fail, nil on success.
+func (c *Client) EditGroup(name string, userlist string, data *Group, transactionID string, version int64) error {
+	if err := validateGroup(data); err != nil {
+		return NewConfError(ErrValidationError, err.Error())
+	}
+	if data.Name != name {
+		return NewConfError(ErrValidationError, fmt.Sprintf("group %s can not be renamed to %s", name, data.Name))
+	}
+	if err := c.authorize("EditGroup", "group", name, "userlist", userlist, transactionID); err != nil {
+		return err
+	}
+
+	p, t, err := c.loadDataForChange(transactionID, version)
+	if err != nil {
+		return err
+	}
+
+	group, i := GetGroupByName(name, userlist, p)
+	if group == nil {
+		e := NewConfError(ErrObjectDoesNotExist, fmt.Sprintf("Group %s does not exist in userlist %s", name, userlist))
+		return c.handleError(name, "userlist", userlist, t, transactionID == "", e)
+	}
+
+	if err := checkGroupUsers(data, userlist, p); err != nil {
+		return c.handleError(name, "userlist", userlist, t, transactionID == "", err)
+	}
+	if err := p.Set(parser.UserList, userlist, "group", SerializeGroup(*data), i); err != nil {
+		return c.handleError(name, "userlist", userlist, t, transactionID == "", err)
+	}
+
+	if err := c.saveData(p, t, transactionID == ""); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func ParseGroups(userlist string, p *parser.Parser) ([]*Group, error) {
+	groups := []*Group{}
+
+	data, err := p.Get(parser.UserList, userlist, "group", false)
+	if err != nil {
+		if err == parser_errors.ErrFetch {
+			return groups, nil
+		}
+		return nil, err
+	}
+
+	for _, g := range data.([]types.Group) {
+		groups = append(groups, ParseGroup(g))
+	}
+	return groups, nil
+}
+
+func ParseGroup(g types.Group) *Group {
+	return &Group{
+		Name:  g.Name,
+		Users: g.Users,
+	}
+}
+
+func SerializeGroup(g Group) types.Group {
+	return types.Group{
+		Name:  g.Name,
+		Users: g.Users,
+	}
+}
+
+func GetGroupByName(name string, userlist string, p *parser.Parser) (*Group, int) {
+	groups, err := ParseGroups(userlist, p)
+	if err != nil {
+		return nil, 0
+	}
+
+	for i, g := range groups {
+		if g.Name == name {
+			return g, i
+		}
+	}
+	return nil, 0
+}
+
+func checkGroupUsers(data *Group, userlist string, p *parser.Parser) error {
+	for _, u := range data.Users {
+		if user, _ := GetUserByName(u, userlist, p); user == nil {
+			return NewConfError(ErrValidationError, fmt.Sprintf("User %s does not exist in userlist %s", u, userlist))
+		}
+	}
+	return nil
+}
+
+func validateGroup(g *Group) error {
+	if g.Name == "" || strings.ContainsAny(g.Name, " \t#,") {
+		return fmt.Errorf("invalid group name %s", g.Name)
+	}
+	for _, u := range g.Users {
+		if u == "" || strings.ContainsAny(u, " \t#,") {
+			return fmt.Errorf("group %s: invalid user %s", g.Name, u)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+// Copyright 2019 HAProxy Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package configuration
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCreateEditDeleteGroup(t *testing.T) {
+	if err := client.CreateUserlist(&Userlist{Name: "teams"}, "", version); err != nil {
+		t.Fatal(err.Error())
+	}
+	version++
+
+	g := &Group{Name: "admins"}
+	if err := client.CreateGroup("teams", g, "", version); err != nil {
+		t.Fatal(err.Error())
+	}
+	version++
+	if err := client.CreateGroup("teams", g, "", version); err == nil {
+		t.Error("Should throw error group already exists")
+		version++
+	}
+	if err := client.CreateGroup("teams", &Group{Name: "devs", Users: []string{"nobody"}}, "", version); err == nil {
+		t.Error("Should throw error, user nobody does not exist")
+		version++
+	}
+
+	if err := client.CreateUser("teams", &User{Username: "carol", Password: "secret", Groups: []string{"admins"}}, "", version); err != nil {
+		t.Fatal(err.Error())
+	}
+	version++
+
+	g = &Group{Name: "admins", Users: []string{"carol"}}
+	if err := client.EditGroup("admins", "teams", g, "", version); err != nil {
+		t.Error(err.Error())
+	} else {
+		version++
+	}
+	v, group, err := client.GetGroup("admins", "teams", "")
+	if err != nil {
+		t.Error(err.Error())
+	} else if !reflect.DeepEqual(group, g) {
+		t.Errorf("Group %v returned, expected %v", group, g)
+	}
+	if v != version {
+		t.Errorf("Version %v returned, expected %v", v, version)
+	}
+
+	if err := client.DeleteGroup("admins", "teams", "", version); err != nil {
+		t.Error(err.Error())
+	} else {
+		version++
+	}
+	_, groups, err := client.GetGroups("teams", "")
+	if err != nil || len(groups) != 0 {
+		t.Errorf("DeleteGroup failed: %v %v", groups, err)
+	}
+	if _, u, err := client.GetUser("carol", "teams", ""); err != nil || len(u.Groups) != 0 {
+		t.Errorf("Group admins not removed from user carol: %v %v", u, err)
+	}
+
+	if err := client.DeleteUserlist("teams", "", version); err != nil {
+		t.Error(err.Error())
+	} else {
+		version++
+	}
+}
@@ -0,0 +1,115 @@
+// Copyright 2019 HAProxy Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package configuration
+
+import (
+	"fmt"
+	"strconv"
+
+	parser "github.com/haproxytech/config-parser/v3"
+)
+
+const hashBalanceFactorDirective = "hash-balance-factor"
+
+// GetHashBalanceFactor returns configuration version and the hash-balance-factor of the defaults
+// or backend section, the percentage of the average load a server of a consistent hash may take.
+// Returns error on fail or if hash-balance-factor is not set.
+func (c *Client) GetHashBalanceFactor(parentType string, parentName string, transactionID string) (int64, int64, error) {
+	section, name, err := hashBalanceFactorSection(parentType, parentName)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	p, err := c.GetParser(transactionID)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	v, err := c.GetVersion(transactionID)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	if !c.checkSectionExists(section, name, p) {
+		return v, 0, NewConfError(ErrObjectDoesNotExist, fmt.Sprintf("%s %s does not exist", parentType, parentName))
+	}
+
+	value, found, err := getRawDirective(p, section, name, hashBalanceFactorDirective)
+	if err != nil {
+		return v, 0, c.handleError(hashBalanceFactorDirective, parentType, parentName, "", false, err)
+	}
+	if !found {
+		return v, 0, NewConfError(ErrObjectDoesNotExist, fmt.Sprintf("%s not set in %s %s", hashBalanceFactorDirective, parentType, parentName))
+	}
+	factor, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return v, 0, c.handleError(hashBalanceFactorDirective, parentType, parentName, "", false, NewConfError(ErrGeneralError, fmt.Sprintf("invalid %s %s", hashBalanceFactorDirective, value)))
+	}
+	return v, factor, nil
+}
+
+// SetHashBalanceFactor sets the hash-balance-factor of the defaults or backend section, nil
+// removes it. The factor is 0, which disables bounded loads, or a percentage of at least 100; it
+// only applies with hash-type consistent. One of version or transactionID is mandatory. Returns
+// error on fail, nil on success.
+func (c *Client) SetHashBalanceFactor(parentType string, parentName string, factor *int64, transactionID string, version int64) error {
+	section, name, err := hashBalanceFactorSection(parentType, parentName)
+	if err != nil {
+		return err
+	}
+	if factor != nil && *factor != 0 && *factor < 100 {
+		return NewConfError(ErrValidationError, fmt.Sprintf("%s has to be 0 or at least 100", hashBalanceFactorDirective))
+	}
+
+	if err := c.authorize("SetHashBalanceFactor", "hash_balance_factor", "", parentType, parentName, transactionID); err != nil {
+		return err
+	}
+
+	p, t, err := c.loadDataForChange(transactionID, version)
+	if err != nil {
+		return err
+	}
+
+	if !c.checkSectionExists(section, name, p) {
+		e := NewConfError(ErrObjectDoesNotExist, fmt.Sprintf("%s %s does not exist", parentType, parentName))
+		return c.handleError(hashBalanceFactorDirective, parentType, parentName, t, transactionID == "", e)
+	}
+
+	var value *string
+	if factor != nil {
+		s := strconv.FormatInt(*factor, 10)
+		value = &s
+	}
+	if err := setRawDirective(p, section, name, hashBalanceFactorDirective, value); err != nil {
+		return c.handleError(hashBalanceFactorDirective, parentType, parentName, t, transactionID == "", err)
+	}
+
+	if err := c.saveData(p, t, transactionID == ""); err != nil {
+		return err
+	}
+	return nil
+}
+
+func hashBalanceFactorSection(parentType string, parentName string) (parser.Section, string, error) {
+	switch parentType {
+	case "defaults":
+		return parser.Defaults, parser.DefaultSectionName, nil
+	case "backend":
+		return parser.Backends, parentName, nil
+	default:
+		return "", "", NewConfError(ErrValidationError, fmt.Sprintf("%s is not supported in %s", hashBalanceFactorDirective, parentType))
+	}
+}
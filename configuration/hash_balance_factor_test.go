@@ -0,0 +1,57 @@
+// Copyright 2019 HAProxy Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package configuration
+
+import (
+	"testing"
+
+	"github.com/haproxytech/client-native/v2/misc"
+)
+
+func TestHashBalanceFactor(t *testing.T) {
+	if err := client.SetHashBalanceFactor("backend", "test", misc.Int64P(50), "", version); err == nil {
+		t.Error("Should throw error, hash-balance-factor lower than 100")
+	}
+	if err := client.SetHashBalanceFactor("frontend", "test", misc.Int64P(150), "", version); err == nil {
+		t.Error("Should throw error, hash-balance-factor not supported in frontends")
+	}
+
+	if err := client.SetHashBalanceFactor("backend", "test", misc.Int64P(150), "", version); err != nil {
+		t.Error(err.Error())
+	} else {
+		version++
+	}
+
+	v, factor, err := client.GetHashBalanceFactor("backend", "test", "")
+	if err != nil {
+		t.Error(err.Error())
+	}
+	if factor != 150 {
+		t.Errorf("hash-balance-factor %d returned, expected 150", factor)
+	}
+	if v != version {
+		t.Errorf("Version %v returned, expected %v", v, version)
+	}
+
+	if err := client.SetHashBalanceFactor("backend", "test", nil, "", version); err != nil {
+		t.Error(err.Error())
+	} else {
+		version++
+	}
+	if _, _, err := client.GetHashBalanceFactor("backend", "test", ""); err == nil {
+		t.Error("Should throw error, hash-balance-factor removed from backend test")
+	}
+}
@@ -0,0 +1,250 @@
+// Copyright 2019 HAProxy Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package configuration
+
+import (
+	"fmt"
+	"strings"
+
+	parser_errors "github.com/haproxytech/config-parser/v3/errors"
+	"github.com/haproxytech/config-parser/v3/types"
+
+	"github.com/haproxytech/client-native/v2/misc"
+)
+
+var httpHealthCheckMatches = []string{"status", "rstatus", "string", "rstring"}
+
+// HTTPHealthCheck is the HTTP health check of a backend or defaults section, the request sent by
+// the http-check send rule and the response expected by the http-check expect rule. Requests set
+// in option httpchk, including headers appended to the version, are read the same way.
+type HTTPHealthCheck struct {
+	Method  string             `json:"method,omitempty"`
+	URI     string             `json:"uri,omitempty"`
+	Version string             `json:"version,omitempty"`
+	Headers []*HTTPCheckHeader `json:"headers,omitempty"`
+	// ExpectMatch is one of status, rstatus, string or rstring, empty accepts 2xx and 3xx
+	// responses
+	ExpectMatch     string `json:"expect_match,omitempty"`
+	ExpectPattern   string `json:"expect_pattern,omitempty"`
+	ExclamationMark bool   `json:"exclamation_mark,omitempty"`
+}
+
+// Validate checks the request words, the headers and the expected response
+func (h *HTTPHealthCheck) Validate() error {
+	for keyword, value := range map[string]string{"method": h.Method, "uri": h.URI, "version": h.Version} {
+		if strings.ContainsAny(value, " \t#") {
+			return fmt.Errorf("http check %s can not contain whitespace or '#'", keyword)
+		}
+	}
+	for _, hdr := range h.Headers {
+		if hdr.Name == "" || hdr.Value == "" || strings.ContainsAny(hdr.Name+hdr.Value, " \t#") {
+			return fmt.Errorf("http check header name and value can not be empty nor contain whitespace or '#'")
+		}
+	}
+	if h.ExpectMatch == "" {
+		if h.ExpectPattern != "" || h.ExclamationMark {
+			return fmt.Errorf("http check expect pattern requires a match")
+		}
+		return nil
+	}
+	if !misc.StringInSlice(h.ExpectMatch, httpHealthCheckMatches) {
+		return fmt.Errorf("http check expect match must be one of %s", strings.Join(httpHealthCheckMatches, ", "))
+	}
+	if strings.TrimSpace(h.ExpectPattern) == "" || strings.ContainsAny(h.ExpectPattern, "#\n") {
+		return fmt.Errorf("http check expect: invalid pattern %s", h.ExpectPattern)
+	}
+	return nil
+}
+
+// GetHTTPHealthCheck returns configuration version and the HTTP health check of the backend or
+// defaults section. Returns error on fail or if option httpchk is not set.
+func (c *Client) GetHTTPHealthCheck(parentType string, parentName string, transactionID string) (int64, *HTTPHealthCheck, error) {
+	section, name, err := httpCheckSection(parentType, parentName)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	p, err := c.GetParser(transactionID)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	v, err := c.GetVersion(transactionID)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	if !c.checkSectionExists(section, name, p) {
+		return v, nil, NewConfError(ErrObjectDoesNotExist, fmt.Sprintf("%s %s does not exist", parentType, parentName))
+	}
+
+	data, err := p.Get(section, name, "option httpchk", false)
+	if err != nil {
+		if err == parser_errors.ErrFetch {
+			return v, nil, NewConfError(ErrObjectDoesNotExist, fmt.Sprintf("option httpchk not set in %s %s", parentType, parentName))
+		}
+		return v, nil, c.handleError("option httpchk", parentType, parentName, "", false, err)
+	}
+	rules, err := ParseHTTPCheckRules(section, name, p)
+	if err != nil {
+		return v, nil, c.handleError("http-check", parentType, parentName, "", false, err)
+	}
+
+	return v, parseHTTPHealthCheck(data.(*types.OptionHttpchk), rules), nil
+}
+
+// SetHTTPHealthCheck sets option httpchk without arguments and replaces the http-check send and
+// expect rules of the backend or defaults section, nil data removes them. Requests set the legacy
+// way in option httpchk are migrated to the http-check send rule. One of version or transactionID
+// is mandatory. Returns error on fail, nil on success.
+func (c *Client) SetHTTPHealthCheck(parentType string, parentName string, data *HTTPHealthCheck, transactionID string, version int64) error {
+	section, name, err := httpCheckSection(parentType, parentName)
+	if err != nil {
+		return err
+	}
+	var rules []HTTPCheckRule
+	if data != nil {
+		if err := data.Validate(); err != nil {
+			return NewConfError(ErrValidationError, err.Error())
+		}
+		rules = serializeHTTPHealthCheck(data)
+	}
+
+	if err := c.authorize("SetHTTPHealthCheck", "http_check", "", parentType, parentName, transactionID); err != nil {
+		return err
+	}
+
+	p, t, err := c.loadDataForChange(transactionID, version)
+	if err != nil {
+		return err
+	}
+
+	if !c.checkSectionExists(section, name, p) {
+		e := NewConfError(ErrObjectDoesNotExist, fmt.Sprintf("%s %s does not exist", parentType, parentName))
+		return c.handleError("option httpchk", parentType, parentName, t, transactionID == "", e)
+	}
+
+	existing, err := ParseHTTPCheckRules(section, name, p)
+	if err != nil {
+		return c.handleError("http-check", parentType, parentName, t, transactionID == "", err)
+	}
+	for i := len(existing) - 1; i >= 0; i-- {
+		if existing[i].Type == "send" || existing[i].Type == "expect" {
+			if err := p.Delete(section, name, "http-check", i); err != nil {
+				return c.handleError("http-check", parentType, parentName, t, transactionID == "", err)
+			}
+		}
+	}
+	for _, r := range rules {
+		d, err := serializeHTTPCheckData(section, r)
+		if err != nil {
+			return c.handleError("http-check", parentType, parentName, t, transactionID == "", NewConfError(ErrValidationError, err.Error()))
+		}
+		if err := p.Insert(section, name, "http-check", d, -1); err != nil {
+			return c.handleError("http-check", parentType, parentName, t, transactionID == "", err)
+		}
+	}
+
+	var httpchk *types.OptionHttpchk
+	if data != nil {
+		httpchk = &types.OptionHttpchk{}
+	}
+	if err := p.Set(section, name, "option httpchk", httpchk); err != nil {
+		return c.handleError("option httpchk", parentType, parentName, t, transactionID == "", err)
+	}
+
+	if err := c.saveData(p, t, transactionID == ""); err != nil {
+		return err
+	}
+	return nil
+}
+
+func parseHTTPHealthCheck(httpchk *types.OptionHttpchk, rules []*HTTPCheckRule) *HTTPHealthCheck {
+	version, headers := parseLegacyHttpchkVersion(httpchk.Version)
+	h := &HTTPHealthCheck{
+		Method:  httpchk.Method,
+		URI:     httpchk.URI,
+		Version: version,
+		Headers: headers,
+	}
+	sent := false
+	for _, r := range rules {
+		switch {
+		case r.Type == "send" && !sent:
+			sent = true
+			if r.Method != "" {
+				h.Method = r.Method
+			}
+			if r.URI != "" {
+				h.URI = r.URI
+			}
+			if r.Version != "" {
+				h.Version = r.Version
+			}
+			h.Headers = append(h.Headers, r.Headers...)
+		case r.Type == "expect" && misc.StringInSlice(r.Match, httpHealthCheckMatches):
+			h.ExpectMatch = r.Match
+			h.ExpectPattern = r.Pattern
+			h.ExclamationMark = r.ExclamationMark
+		}
+	}
+	return h
+}
+
+// parseLegacyHttpchkVersion splits the version of option httpchk from the headers appended to it
+// the legacy way, HTTP/1.1\r\nHost:\ www.example.com
+func parseLegacyHttpchkVersion(version string) (string, []*HTTPCheckHeader) {
+	parts := strings.Split(version, `\r\n`)
+	headers := []*HTTPCheckHeader{}
+	for _, part := range parts[1:] {
+		part = strings.ReplaceAll(part, `\ `, " ")
+		i := strings.Index(part, ":")
+		if i < 1 {
+			continue
+		}
+		value := strings.TrimSpace(part[i+1:])
+		if value == "" {
+			continue
+		}
+		headers = append(headers, &HTTPCheckHeader{Name: strings.TrimSpace(part[:i]), Value: value})
+	}
+	if len(headers) == 0 {
+		headers = nil
+	}
+	return strings.TrimSpace(parts[0]), headers
+}
+
+func serializeHTTPHealthCheck(h *HTTPHealthCheck) []HTTPCheckRule {
+	rules := []HTTPCheckRule{}
+	if h.Method != "" || h.URI != "" || h.Version != "" || len(h.Headers) > 0 {
+		rules = append(rules, HTTPCheckRule{
+			Type:    "send",
+			Method:  h.Method,
+			URI:     h.URI,
+			Version: h.Version,
+			Headers: h.Headers,
+		})
+	}
+	if h.ExpectMatch != "" {
+		rules = append(rules, HTTPCheckRule{
+			Type:            "expect",
+			Match:           h.ExpectMatch,
+			Pattern:         h.ExpectPattern,
+			ExclamationMark: h.ExclamationMark,
+		})
+	}
+	return rules
+}
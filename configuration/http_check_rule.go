@@ -0,0 +1,629 @@
+// Copyright 2019 HAProxy Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package configuration
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	parser "github.com/haproxytech/config-parser/v3"
+	"github.com/haproxytech/config-parser/v3/common"
+	parser_errors "github.com/haproxytech/config-parser/v3/errors"
+	"github.com/haproxytech/config-parser/v3/parsers/http/actions"
+	"github.com/haproxytech/config-parser/v3/types"
+	"github.com/haproxytech/models/v2"
+
+	"github.com/haproxytech/client-native/v2/misc"
+)
+
+var httpCheckRuleTypes = []string{"comment", "connect", "disable-on-404", "expect", "send", "send-state", "set-var", "unset-var"}
+
+var httpCheckExpectMatches = []string{"status", "rstatus", "hdr", "fhdr", "string", "rstring"}
+
+var varScopes = []string{"proc", "sess", "txn", "req", "res"}
+
+var varNameRegexp = regexp.MustCompile(`^[a-zA-Z0-9._]+$`)
+
+// HTTPCheckRule is an http-check rule of a backend or defaults section. The rules of a section
+// form the ordered sequence of the HTTP health check.
+type HTTPCheckRule struct {
+	Index *int64 `json:"index"`
+	// Type is one of comment, connect, disable-on-404, expect, send, send-state, set-var or
+	// unset-var
+	Type string `json:"type"`
+	// CheckComment is the message of comment rules and the comment logged when connect, send or
+	// expect rules fail
+	CheckComment string `json:"check_comment,omitempty"`
+
+	// connect
+	Default   bool   `json:"default,omitempty"`
+	Port      *int64 `json:"port,omitempty"`
+	Addr      string `json:"addr,omitempty"`
+	SendProxy bool   `json:"send_proxy,omitempty"`
+	ViaSocks4 bool   `json:"via_socks4,omitempty"`
+	SSL       bool   `json:"ssl,omitempty"`
+	SNI       string `json:"sni,omitempty"`
+	ALPN      string `json:"alpn,omitempty"`
+	Linger    bool   `json:"linger,omitempty"`
+	Proto     string `json:"proto,omitempty"`
+
+	// send
+	Method        string             `json:"method,omitempty"`
+	URI           string             `json:"uri,omitempty"`
+	URILogFormat  string             `json:"uri_log_format,omitempty"`
+	Version       string             `json:"version,omitempty"`
+	Headers       []*HTTPCheckHeader `json:"headers,omitempty"`
+	Body          string             `json:"body,omitempty"`
+	BodyLogFormat string             `json:"body_log_format,omitempty"`
+
+	// expect
+	MinRecv         *int64 `json:"min_recv,omitempty"`
+	OkStatus        string `json:"ok_status,omitempty"`
+	ErrorStatus     string `json:"error_status,omitempty"`
+	TimeoutStatus   string `json:"timeout_status,omitempty"`
+	OnSuccess       string `json:"on_success,omitempty"`
+	OnError         string `json:"on_error,omitempty"`
+	StatusCode      string `json:"status_code,omitempty"`
+	ExclamationMark bool   `json:"exclamation_mark,omitempty"`
+	Match           string `json:"match,omitempty"`
+	Pattern         string `json:"pattern,omitempty"`
+
+	// set-var and unset-var
+	VarScope string `json:"var_scope,omitempty"`
+	VarName  string `json:"var_name,omitempty"`
+	VarExpr  string `json:"var_expr,omitempty"`
+}
+
+// HTTPCheckHeader is a header sent by an http-check send rule
+type HTTPCheckHeader struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// Validate checks the rule type and the arguments of the type. Except for the expect pattern and
+// the set-var expression, arguments are single words.
+func (r *HTTPCheckRule) Validate() error {
+	if r.Index == nil {
+		return fmt.Errorf("http-check rule index is required")
+	}
+	if !misc.StringInSlice(r.Type, httpCheckRuleTypes) {
+		return fmt.Errorf("http-check rule type must be one of %s", strings.Join(httpCheckRuleTypes, ", "))
+	}
+	words := map[string]string{
+		"comment": r.CheckComment, "addr": r.Addr, "sni": r.SNI, "alpn": r.ALPN, "proto": r.Proto,
+		"meth": r.Method, "uri": r.URI, "uri-lf": r.URILogFormat, "ver": r.Version, "body": r.Body,
+		"body-lf": r.BodyLogFormat, "ok-status": r.OkStatus, "error-status": r.ErrorStatus,
+		"tout-status": r.TimeoutStatus, "on-success": r.OnSuccess, "on-error": r.OnError,
+		"status-code": r.StatusCode,
+	}
+	for keyword, value := range words {
+		if strings.ContainsAny(value, " \t#") {
+			return fmt.Errorf("http-check %s: %s can not contain whitespace or '#'", r.Type, keyword)
+		}
+	}
+	if r.Port != nil && (*r.Port < 1 || *r.Port > 65535) {
+		return fmt.Errorf("http-check connect: port must be between 1 and 65535")
+	}
+	for _, h := range r.Headers {
+		if h.Name == "" || h.Value == "" || strings.ContainsAny(h.Name+h.Value, " \t#") {
+			return fmt.Errorf("http-check send: header name and value can not be empty nor contain whitespace or '#'")
+		}
+	}
+	switch r.Type {
+	case "comment":
+		if r.CheckComment == "" {
+			return fmt.Errorf("http-check comment: message is required")
+		}
+	case "expect":
+		if !misc.StringInSlice(r.Match, httpCheckExpectMatches) {
+			return fmt.Errorf("http-check expect: match must be one of %s", strings.Join(httpCheckExpectMatches, ", "))
+		}
+		if strings.TrimSpace(r.Pattern) == "" || strings.ContainsAny(r.Pattern, "#\n") {
+			return fmt.Errorf("http-check expect: invalid pattern %s", r.Pattern)
+		}
+		if r.MinRecv != nil && *r.MinRecv < -1 {
+			return fmt.Errorf("http-check expect: min-recv can not be lower than -1")
+		}
+	case "set-var", "unset-var":
+		if !misc.StringInSlice(r.VarScope, varScopes) {
+			return fmt.Errorf("http-check %s: scope must be one of %s", r.Type, strings.Join(varScopes, ", "))
+		}
+		if !varNameRegexp.MatchString(r.VarName) {
+			return fmt.Errorf("http-check %s: invalid variable name %s", r.Type, r.VarName)
+		}
+		if r.Type == "set-var" && (strings.TrimSpace(r.VarExpr) == "" || strings.ContainsAny(r.VarExpr, "#\n")) {
+			return fmt.Errorf("http-check set-var: invalid expression %s", r.VarExpr)
+		}
+	}
+	return nil
+}
+
+// GetHTTPCheckRules returns configuration version and an array of
+// configured http-check rules in the specified parent. Returns error on fail.
+func (c *Client) GetHTTPCheckRules(parentType string, parentName string, transactionID string) (int64, []*HTTPCheckRule, error) {
+	section, name, err := httpCheckSection(parentType, parentName)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	p, err := c.GetParser(transactionID)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	v, err := c.GetVersion(transactionID)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	rules, err := ParseHTTPCheckRules(section, name, p)
+	if err != nil {
+		return v, nil, c.handleError("", parentType, parentName, "", false, err)
+	}
+
+	return v, rules, nil
+}
+
+// GetHTTPCheckRule returns configuration version and a requested http-check rule
+// in the specified parent. Returns error on fail or if http-check rule does not exist.
+func (c *Client) GetHTTPCheckRule(id int64, parentType string, parentName string, transactionID string) (int64, *HTTPCheckRule, error) {
+	section, name, err := httpCheckSection(parentType, parentName)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	p, err := c.GetParser(transactionID)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	v, err := c.GetVersion(transactionID)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	data, err := p.GetOne(section, name, "http-check", int(id))
+	if err != nil {
+		return v, nil, c.handleError(strconv.FormatInt(id, 10), parentType, parentName, "", false, err)
+	}
+
+	rule, err := parseHTTPCheckData(data)
+	if err != nil {
+		return v, nil, err
+	}
+	rule.Index = &id
+
+	return v, rule, nil
+}
+
+// DeleteHTTPCheckRule deletes an http-check rule in configuration. One of version or
+// transactionID is mandatory. Returns error on fail, nil on success.
+func (c *Client) DeleteHTTPCheckRule(id int64, parentType string, parentName string, transactionID string, version int64) error {
+	section, name, err := httpCheckSection(parentType, parentName)
+	if err != nil {
+		return err
+	}
+
+	if err := c.authorize("DeleteHTTPCheckRule", "http_check_rule", authorizedIndex(id), parentType, parentName, transactionID); err != nil {
+		return err
+	}
+
+	p, t, err := c.loadDataForChange(transactionID, version)
+	if err != nil {
+		return err
+	}
+
+	if err := p.Delete(section, name, "http-check", int(id)); err != nil {
+		return c.handleError(strconv.FormatInt(id, 10), parentType, parentName, t, transactionID == "", err)
+	}
+
+	if err := c.saveData(p, t, transactionID == ""); err != nil {
+		return err
+	}
+	return nil
+}
+
+// CreateHTTPCheckRule creates an http-check rule in configuration at the index of the rule. One
+// of version or transactionID is mandatory. Returns error on fail, nil on success.
+func (c *Client) CreateHTTPCheckRule(parentType string, parentName string, data *HTTPCheckRule, transactionID string, version int64) error {
+	section, name, err := httpCheckSection(parentType, parentName)
+	if err != nil {
+		return err
+	}
+	if err := data.Validate(); err != nil {
+		return NewConfError(ErrValidationError, err.Error())
+	}
+	d, err := serializeHTTPCheckData(section, *data)
+	if err != nil {
+		return NewConfError(ErrValidationError, err.Error())
+	}
+
+	if err := c.authorize("CreateHTTPCheckRule", "http_check_rule", authorizedIndexP(data.Index), parentType, parentName, transactionID); err != nil {
+		return err
+	}
+
+	p, t, err := c.loadDataForChange(transactionID, version)
+	if err != nil {
+		return err
+	}
+
+	if err := p.Insert(section, name, "http-check", d, int(*data.Index)); err != nil {
+		return c.handleError(strconv.FormatInt(*data.Index, 10), parentType, parentName, t, transactionID == "", err)
+	}
+
+	if err := c.saveData(p, t, transactionID == ""); err != nil {
+		return err
+	}
+	return nil
+}
+
+// EditHTTPCheckRule edits an http-check rule in configuration. One of version or transactionID
+// is mandatory. Returns error on fail, nil on success.
+func (c *Client) EditHTTPCheckRule(id int64, parentType string, parentName string, data *HTTPCheckRule, transactionID string, version int64) error {
+	section, name, err := httpCheckSection(parentType, parentName)
+	if err != nil {
+		return err
+	}
+	if err := data.Validate(); err != nil {
+		return NewConfError(ErrValidationError, err.Error())
+	}
+	d, err := serializeHTTPCheckData(section, *data)
+	if err != nil {
+		return NewConfError(ErrValidationError, err.Error())
+	}
+
+	if err := c.authorize("EditHTTPCheckRule", "http_check_rule", authorizedIndex(id), parentType, parentName, transactionID); err != nil {
+		return err
+	}
+
+	p, t, err := c.loadDataForChange(transactionID, version)
+	if err != nil {
+		return err
+	}
+
+	if _, err := p.GetOne(section, name, "http-check", int(id)); err != nil {
+		return c.handleError(strconv.FormatInt(id, 10), parentType, parentName, t, transactionID == "", err)
+	}
+
+	if err := p.Set(section, name, "http-check", d, int(id)); err != nil {
+		return c.handleError(strconv.FormatInt(id, 10), parentType, parentName, t, transactionID == "", err)
+	}
+
+	if err := c.saveData(p, t, transactionID == ""); err != nil {
+		return err
+	}
+	return nil
+}
+
+func ParseHTTPCheckRules(section parser.Section, name string, p *parser.Parser) ([]*HTTPCheckRule, error) {
+	rules := []*HTTPCheckRule{}
+	data, err := p.Get(section, name, "http-check", false)
+	if err != nil {
+		if err == parser_errors.ErrFetch {
+			return rules, nil
+		}
+		return nil, err
+	}
+
+	var items []common.ParserData
+	switch d := data.(type) {
+	case []types.HTTPAction:
+		for _, a := range d {
+			items = append(items, a)
+		}
+	case []types.HTTPCheckV2:
+		for _, hc := range d {
+			items = append(items, hc)
+		}
+	}
+	for i, item := range items {
+		id := int64(i)
+		rule, err := parseHTTPCheckData(item)
+		if err != nil {
+			return nil, err
+		}
+		rule.Index = &id
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+func ParseHTTPCheckRule(a types.HTTPAction) (*HTTPCheckRule, error) {
+	switch v := a.(type) {
+	case *actions.CheckComment:
+		return &HTTPCheckRule{Type: "comment", CheckComment: v.LogMessage}, nil
+	case *actions.CheckConnect:
+		r := &HTTPCheckRule{
+			Type:         "connect",
+			CheckComment: v.CheckComment,
+			Default:      v.Default,
+			Addr:         v.Addr,
+			SendProxy:    v.SendProxy,
+			ViaSocks4:    v.ViaSOCKS4,
+			SSL:          v.SSL,
+			SNI:          v.SNI,
+			ALPN:         v.ALPN,
+			Linger:       v.Linger,
+			Proto:        v.Proto,
+		}
+		if port, err := strconv.ParseInt(v.Port, 10, 64); err == nil {
+			r.Port = &port
+		}
+		return r, nil
+	case *actions.CheckDisableOn404:
+		return &HTTPCheckRule{Type: "disable-on-404"}, nil
+	case *actions.CheckExpect:
+		return &HTTPCheckRule{
+			Type:            "expect",
+			CheckComment:    v.CheckComment,
+			MinRecv:         v.MinRecv,
+			OkStatus:        v.OKStatus,
+			ErrorStatus:     v.ErrorStatus,
+			TimeoutStatus:   v.TimeoutStatus,
+			OnSuccess:       v.OnSuccess,
+			OnError:         v.OnError,
+			StatusCode:      v.StatusCode,
+			ExclamationMark: v.ExclamationMark,
+			Match:           v.Match,
+			Pattern:         v.Pattern,
+		}, nil
+	case *actions.CheckSend:
+		r := &HTTPCheckRule{
+			Type:          "send",
+			CheckComment:  v.CheckComment,
+			Method:        v.Method,
+			URI:           v.URI,
+			URILogFormat:  v.URILogFormat,
+			Version:       v.Version,
+			Body:          v.Body,
+			BodyLogFormat: v.BodyLogFormat,
+		}
+		for _, h := range v.Header {
+			r.Headers = append(r.Headers, &HTTPCheckHeader{Name: h.Name, Value: h.Format})
+		}
+		return r, nil
+	case *actions.CheckSendState:
+		return &HTTPCheckRule{Type: "send-state"}, nil
+	case *actions.SetVar:
+		return &HTTPCheckRule{
+			Type:     "set-var",
+			VarScope: v.VarScope,
+			VarName:  v.VarName,
+			VarExpr:  strings.Join(v.Expr.Expr, " "),
+		}, nil
+	case *actions.UnsetVar:
+		return &HTTPCheckRule{Type: "unset-var", VarScope: v.Scope, VarName: v.Name}, nil
+	}
+	return nil, NewConfError(ErrValidationError, fmt.Sprintf("unsupported http-check rule %s", a.String()))
+}
+
+func SerializeHTTPCheckRule(r HTTPCheckRule) (types.HTTPAction, error) {
+	switch r.Type {
+	case "comment":
+		return &actions.CheckComment{LogMessage: r.CheckComment}, nil
+	case "connect":
+		a := &actions.CheckConnect{
+			Default:      r.Default,
+			Addr:         r.Addr,
+			SendProxy:    r.SendProxy,
+			ViaSOCKS4:    r.ViaSocks4,
+			SSL:          r.SSL,
+			SNI:          r.SNI,
+			ALPN:         r.ALPN,
+			Linger:       r.Linger,
+			Proto:        r.Proto,
+			CheckComment: r.CheckComment,
+		}
+		if r.Port != nil {
+			a.Port = strconv.FormatInt(*r.Port, 10)
+		}
+		return a, nil
+	case "disable-on-404":
+		return &actions.CheckDisableOn404{}, nil
+	case "expect":
+		return &actions.CheckExpect{
+			MinRecv:         r.MinRecv,
+			CheckComment:    r.CheckComment,
+			OKStatus:        r.OkStatus,
+			ErrorStatus:     r.ErrorStatus,
+			TimeoutStatus:   r.TimeoutStatus,
+			OnSuccess:       r.OnSuccess,
+			OnError:         r.OnError,
+			StatusCode:      r.StatusCode,
+			ExclamationMark: r.ExclamationMark,
+			Match:           r.Match,
+			Pattern:         strings.TrimSpace(r.Pattern),
+		}, nil
+	case "send":
+		a := &actions.CheckSend{
+			Method:        r.Method,
+			URI:           r.URI,
+			URILogFormat:  r.URILogFormat,
+			Version:       r.Version,
+			Body:          r.Body,
+			BodyLogFormat: r.BodyLogFormat,
+			CheckComment:  r.CheckComment,
+		}
+		for _, h := range r.Headers {
+			a.Header = append(a.Header, actions.CheckSendHeader{Name: h.Name, Format: h.Value})
+		}
+		return a, nil
+	case "send-state":
+		return &actions.CheckSendState{}, nil
+	case "set-var":
+		return &actions.SetVar{
+			VarScope: r.VarScope,
+			VarName:  r.VarName,
+			Expr:     common.Expression{Expr: strings.Fields(r.VarExpr)},
+		}, nil
+	case "unset-var":
+		return &actions.UnsetVar{Scope: r.VarScope, Name: r.VarName}, nil
+	}
+	return nil, fmt.Errorf("unsupported http-check rule type %s", r.Type)
+}
+
+// parseHTTPCheckData returns the rule of an http-check line. Backends parse http-check lines
+// into actions, defaults keep the type and the rest of the line, which is parsed here the same
+// way.
+func parseHTTPCheckData(data common.ParserData) (*HTTPCheckRule, error) {
+	switch d := data.(type) {
+	case types.HTTPAction:
+		return ParseHTTPCheckRule(d)
+	case types.HTTPCheckV2:
+		return parseHTTPCheckV2(d)
+	case *types.HTTPCheckV2:
+		return parseHTTPCheckV2(*d)
+	}
+	return nil, NewConfError(ErrGeneralError, "unexpected http-check data")
+}
+
+func parseHTTPCheckV2(hc types.HTTPCheckV2) (*HTTPCheckRule, error) {
+	parts := []string{"http-check", hc.Type}
+	if hc.ExclamationMark {
+		parts = append(parts, "!")
+	}
+	if hc.Match != "" {
+		parts = append(parts, hc.Match)
+	}
+	parts = append(parts, strings.Fields(hc.Pattern)...)
+
+	var a types.HTTPAction
+	switch {
+	case strings.HasPrefix(hc.Type, "set-var("):
+		a = &actions.SetVar{}
+	case strings.HasPrefix(hc.Type, "unset-var("):
+		a = &actions.UnsetVar{}
+	default:
+		a = map[string]types.HTTPAction{
+			"comment":        &actions.CheckComment{},
+			"connect":        &actions.CheckConnect{},
+			"disable-on-404": &actions.CheckDisableOn404{},
+			"expect":         &actions.CheckExpect{},
+			"send":           &actions.CheckSend{},
+			"send-state":     &actions.CheckSendState{},
+		}[hc.Type]
+	}
+	if a == nil {
+		return nil, NewConfError(ErrValidationError, fmt.Sprintf("unsupported http-check rule %s", hc.Type))
+	}
+	if err := a.Parse(parts, hc.Comment); err != nil {
+		return nil, NewConfError(ErrValidationError, err.Error())
+	}
+	return ParseHTTPCheckRule(a)
+}
+
+// serializeHTTPCheckData returns the http-check line data of the section. The parser of defaults
+// sections splits lines into the type, a first argument and the rest, lines with a single
+// argument are not read back and are refused.
+func serializeHTTPCheckData(section parser.Section, r HTTPCheckRule) (common.ParserData, error) {
+	a, err := SerializeHTTPCheckRule(r)
+	if err != nil {
+		return nil, err
+	}
+	if section != parser.Defaults {
+		return a, nil
+	}
+
+	words := strings.Fields(a.String())
+	hc := &types.HTTPCheckV2{Type: words[0]}
+	switch {
+	case len(words) == 1:
+		return hc, nil
+	case len(words) == 2:
+		return nil, fmt.Errorf("http-check %s: rules with a single argument are not supported in defaults", r.Type)
+	case words[1] == "!" && len(words) > 3:
+		hc.ExclamationMark = true
+		hc.Match = words[2]
+		hc.Pattern = strings.Join(words[3:], " ")
+	default:
+		hc.Match = words[1]
+		hc.Pattern = strings.Join(words[2:], " ")
+	}
+	return hc, nil
+}
+
+// legacyHTTPCheck returns the last disable-on-404, expect or send-state rule of the section as
+// the single http-check of the backend and defaults models
+func legacyHTTPCheck(section parser.Section, name string, p *parser.Parser) (*models.HTTPCheck, int) {
+	rules, err := ParseHTTPCheckRules(section, name, p)
+	if err != nil {
+		return nil, -1
+	}
+	for i := len(rules) - 1; i >= 0; i-- {
+		r := rules[i]
+		switch r.Type {
+		case "disable-on-404", "expect", "send-state":
+			t := r.Type
+			return &models.HTTPCheck{
+				Type:            &t,
+				ExclamationMark: r.ExclamationMark,
+				Match:           r.Match,
+				Pattern:         r.Pattern,
+			}, i
+		}
+	}
+	return nil, -1
+}
+
+// setLegacyHTTPCheck replaces the rule returned by legacyHTTPCheck with the http-check of the
+// backend or defaults model, or appends it to the rules of the section. Nil removes the
+// disable-on-404, expect and send-state rules and keeps the rest of the sequence.
+func setLegacyHTTPCheck(section parser.Section, name string, hc *models.HTTPCheck, p *parser.Parser) error {
+	if hc == nil {
+		rules, err := ParseHTTPCheckRules(section, name, p)
+		if err != nil {
+			return err
+		}
+		for i := len(rules) - 1; i >= 0; i-- {
+			switch rules[i].Type {
+			case "disable-on-404", "expect", "send-state":
+				if err := p.Delete(section, name, "http-check", i); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+
+	if hc.Type == nil {
+		return NewConfError(ErrValidationError, "http-check type is required")
+	}
+	r := HTTPCheckRule{
+		Type:            *hc.Type,
+		ExclamationMark: hc.ExclamationMark,
+		Match:           hc.Match,
+		Pattern:         hc.Pattern,
+	}
+	d, err := serializeHTTPCheckData(section, r)
+	if err != nil {
+		return err
+	}
+	_, i := legacyHTTPCheck(section, name, p)
+	return p.Set(section, name, "http-check", d, i)
+}
+
+func httpCheckSection(parentType string, parentName string) (parser.Section, string, error) {
+	switch parentType {
+	case "defaults":
+		return parser.Defaults, parser.DefaultSectionName, nil
+	case "backend":
+		return parser.Backends, parentName, nil
+	default:
+		return "", "", NewConfError(ErrValidationError, fmt.Sprintf("http-check rules are not supported in %s", parentType))
+	}
+}
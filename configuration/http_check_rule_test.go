@@ -0,0 +1,183 @@
+// Copyright 2019 HAProxy Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package configuration
+
+import (
+	"testing"
+
+	"github.com/haproxytech/client-native/v2/misc"
+)
+
+func TestCreateEditDeleteHTTPCheckRule(t *testing.T) {
+	_, rules, err := client.GetHTTPCheckRules("backend", "test_2", "")
+	if err != nil {
+		t.Error(err.Error())
+	}
+	if len(rules) != 0 {
+		t.Errorf("%v http-check rules returned, expected 0", len(rules))
+	}
+
+	send := &HTTPCheckRule{
+		Index:   misc.Int64P(0),
+		Type:    "send",
+		Method:  "GET",
+		URI:     "/health",
+		Version: "HTTP/1.1",
+		Headers: []*HTTPCheckHeader{{Name: "Host", Value: "example.com"}},
+	}
+	if err := client.CreateHTTPCheckRule("backend", "test_2", send, "", version); err != nil {
+		t.Error(err.Error())
+	} else {
+		version++
+	}
+
+	expect := &HTTPCheckRule{
+		Index:        misc.Int64P(1),
+		Type:         "expect",
+		Match:        "status",
+		Pattern:      "200-399",
+		CheckComment: "bad_status",
+	}
+	if err := client.CreateHTTPCheckRule("backend", "test_2", expect, "", version); err != nil {
+		t.Error(err.Error())
+	} else {
+		version++
+	}
+
+	connect := &HTTPCheckRule{
+		Index: misc.Int64P(0),
+		Type:  "connect",
+		Port:  misc.Int64P(8080),
+		SSL:   true,
+	}
+	if err := client.CreateHTTPCheckRule("backend", "test_2", connect, "", version); err != nil {
+		t.Error(err.Error())
+	} else {
+		version++
+	}
+
+	_, rules, err = client.GetHTTPCheckRules("backend", "test_2", "")
+	if err != nil {
+		t.Error(err.Error())
+	}
+	if len(rules) != 3 {
+		t.Fatalf("%v http-check rules returned, expected 3", len(rules))
+	}
+	for i, typ := range []string{"connect", "send", "expect"} {
+		if rules[i].Type != typ || *rules[i].Index != int64(i) {
+			t.Errorf("http-check rule %v: %v returned, expected %v", i, rules[i].Type, typ)
+		}
+	}
+	if rules[0].Port == nil || *rules[0].Port != 8080 || !rules[0].SSL {
+		t.Errorf("connect rule %+v not as created", rules[0])
+	}
+	if len(rules[1].Headers) != 1 || rules[1].Headers[0].Name != "Host" || rules[1].URI != "/health" {
+		t.Errorf("send rule %+v not as created", rules[1])
+	}
+	if rules[2].CheckComment != "bad_status" || rules[2].Pattern != "200-399" {
+		t.Errorf("expect rule %+v not as created", rules[2])
+	}
+
+	_, b, err := client.GetBackend("test_2", "")
+	if err != nil {
+		t.Error(err.Error())
+	} else if b.HTTPCheck == nil || *b.HTTPCheck.Type != "expect" || b.HTTPCheck.Pattern != "200-399" {
+		t.Errorf("backend http-check %+v not the expect rule", b.HTTPCheck)
+	}
+
+	expect.Index = misc.Int64P(2)
+	expect.ExclamationMark = true
+	expect.Match = "rstatus"
+	expect.Pattern = "^5"
+	if err := client.EditHTTPCheckRule(2, "backend", "test_2", expect, "", version); err != nil {
+		t.Error(err.Error())
+	} else {
+		version++
+	}
+
+	_, r, err := client.GetHTTPCheckRule(2, "backend", "test_2", "")
+	if err != nil {
+		t.Error(err.Error())
+	} else if !r.ExclamationMark || r.Match != "rstatus" || r.Pattern != "^5" {
+		t.Errorf("expect rule %+v not as edited", r)
+	}
+
+	if err := client.DeleteHTTPCheckRule(0, "backend", "test_2", "", version); err != nil {
+		t.Error(err.Error())
+	} else {
+		version++
+	}
+	if err := client.DeleteHTTPCheckRule(5, "backend", "test_2", "", version); err == nil {
+		t.Error("Should throw error, non existent http-check rule")
+	}
+
+	_, rules, err = client.GetHTTPCheckRules("backend", "test_2", "")
+	if err != nil {
+		t.Error(err.Error())
+	}
+	if len(rules) != 2 || rules[0].Type != "send" {
+		t.Errorf("http-check rules %v not as expected after delete", len(rules))
+	}
+
+	for _, i := range []int64{1, 0} {
+		if err := client.DeleteHTTPCheckRule(i, "backend", "test_2", "", version); err != nil {
+			t.Error(err.Error())
+		} else {
+			version++
+		}
+	}
+}
+
+func TestHTTPCheckRuleDefaults(t *testing.T) {
+	expect := &HTTPCheckRule{
+		Index:   misc.Int64P(0),
+		Type:    "expect",
+		Match:   "string",
+		Pattern: "OK",
+	}
+	if err := client.CreateHTTPCheckRule("defaults", "", expect, "", version); err != nil {
+		t.Error(err.Error())
+	} else {
+		version++
+	}
+
+	_, r, err := client.GetHTTPCheckRule(0, "defaults", "", "")
+	if err != nil {
+		t.Error(err.Error())
+	} else if r.Type != "expect" || r.Match != "string" || r.Pattern != "OK" {
+		t.Errorf("expect rule %+v not as created", r)
+	}
+
+	comment := &HTTPCheckRule{Index: misc.Int64P(0), Type: "comment", CheckComment: "first"}
+	if err := client.CreateHTTPCheckRule("defaults", "", comment, "", version); err == nil {
+		t.Error("Should throw error, single argument rules are not supported in defaults")
+	}
+
+	if err := client.CreateHTTPCheckRule("frontend", "test", expect, "", version); err == nil {
+		t.Error("Should throw error, http-check rules are not supported in frontends")
+	}
+
+	invalid := &HTTPCheckRule{Index: misc.Int64P(0), Type: "expect", Match: "body", Pattern: "OK"}
+	if err := client.CreateHTTPCheckRule("backend", "test_2", invalid, "", version); err == nil {
+		t.Error("Should throw error, invalid expect match")
+	}
+
+	if err := client.DeleteHTTPCheckRule(0, "defaults", "", "", version); err != nil {
+		t.Error(err.Error())
+	} else {
+		version++
+	}
+}
@@ -0,0 +1,102 @@
+// Copyright 2019 HAProxy Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package configuration
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/haproxytech/models/v2"
+)
+
+func TestHTTPHealthCheck(t *testing.T) {
+	b := &models.Backend{
+		Name: "httpchk",
+		Mode: "http",
+		Httpchk: &models.Httpchk{
+			Method:  "GET",
+			URI:     "/health",
+			Version: `HTTP/1.1\r\nHost:www.example.com`,
+		},
+	}
+	if err := client.CreateBackend(b, "", version); err != nil {
+		t.Fatal(err.Error())
+	}
+	version++
+
+	// legacy option httpchk headers are read as headers
+	_, h, err := client.GetHTTPHealthCheck("backend", "httpchk", "")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	expected := &HTTPHealthCheck{
+		Method:  "GET",
+		URI:     "/health",
+		Version: "HTTP/1.1",
+		Headers: []*HTTPCheckHeader{{Name: "Host", Value: "www.example.com"}},
+	}
+	if !reflect.DeepEqual(h, expected) {
+		t.Errorf("HTTP health check %v not equal to expected %v", h, expected)
+	}
+
+	invalid := *expected
+	invalid.ExpectPattern = "200"
+	if err := client.SetHTTPHealthCheck("backend", "httpchk", &invalid, "", version); err == nil {
+		t.Error("Should throw error, expect pattern without match")
+	}
+
+	expected.ExpectMatch = "status"
+	expected.ExpectPattern = "200-399"
+	if err := client.SetHTTPHealthCheck("backend", "httpchk", expected, "", version); err != nil {
+		t.Error(err.Error())
+	} else {
+		version++
+	}
+
+	v, h, err := client.GetHTTPHealthCheck("backend", "httpchk", "")
+	if err != nil {
+		t.Error(err.Error())
+	}
+	if !reflect.DeepEqual(h, expected) {
+		t.Errorf("HTTP health check %v not equal to given %v", h, expected)
+	}
+	if v != version {
+		t.Errorf("Version %v returned, expected %v", v, version)
+	}
+
+	_, rules, err := client.GetHTTPCheckRules("backend", "httpchk", "")
+	if err != nil {
+		t.Error(err.Error())
+	}
+	if len(rules) != 2 || rules[0].Type != "send" || rules[1].Type != "expect" {
+		t.Errorf("%v: expected http-check send and expect rules", rules)
+	}
+
+	if err := client.SetHTTPHealthCheck("backend", "httpchk", nil, "", version); err != nil {
+		t.Error(err.Error())
+	} else {
+		version++
+	}
+	if _, _, err := client.GetHTTPHealthCheck("backend", "httpchk", ""); err == nil {
+		t.Error("Should throw error, option httpchk removed")
+	}
+
+	if err := client.DeleteBackend("httpchk", "", version); err != nil {
+		t.Error(err.Error())
+	} else {
+		version++
+	}
+}
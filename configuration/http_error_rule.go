@@ -0,0 +1,339 @@
+// Copyright 2019 HAProxy Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package configuration
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	parser "github.com/haproxytech/config-parser/v3"
+)
+
+var httpErrorStatuses = []int64{200, 400, 401, 403, 404, 405, 407, 408, 410, 413, 425, 429, 500, 501, 502, 503, 504}
+
+var httpErrorReturnTypes = []string{"default-errorfiles", "errorfile", "errorfiles", "file", "lf-file", "string", "lf-string"}
+
+// HTTPErrorRule is an http-error directive of a defaults, frontend or backend section, the
+// response returned for the status instead of the built-in error message
+type HTTPErrorRule struct {
+	Status int64 `json:"status"`
+	// ContentType of the payload returned by file, lf-file, string and lf-string responses
+	ContentType string `json:"content_type,omitempty"`
+	// ReturnType is one of default-errorfiles, errorfile, errorfiles, file, lf-file, string or
+	// lf-string
+	ReturnType string `json:"return_type,omitempty"`
+	// Content is the file, http-errors section or string of the return type
+	Content string `json:"content,omitempty"`
+	// Headers added to file, lf-file, string and lf-string responses
+	Headers []*HTTPErrorHeader `json:"headers,omitempty"`
+}
+
+// HTTPErrorHeader is a header added to an http-error response
+type HTTPErrorHeader struct {
+	Name string `json:"name"`
+	Fmt  string `json:"fmt"`
+}
+
+// Validate checks the status and the response. Arguments are single words, the parser does not
+// keep quoted strings together.
+func (r *HTTPErrorRule) Validate() error {
+	found := false
+	for _, s := range httpErrorStatuses {
+		found = found || s == r.Status
+	}
+	if !found {
+		return fmt.Errorf("http-error status %d is not supported", r.Status)
+	}
+	for _, w := range []string{r.ContentType, r.Content} {
+		if strings.ContainsAny(w, " \t#") {
+			return fmt.Errorf("http-error status %d: arguments can not contain whitespace or '#'", r.Status)
+		}
+	}
+	payload := false
+	switch r.ReturnType {
+	case "":
+		if r.Content != "" {
+			return fmt.Errorf("http-error status %d: content requires a return type", r.Status)
+		}
+	case "default-errorfiles":
+		if r.Content != "" {
+			return fmt.Errorf("http-error status %d: default-errorfiles has no content", r.Status)
+		}
+	case "errorfile", "errorfiles":
+		if r.Content == "" {
+			return fmt.Errorf("http-error status %d: %s requires content", r.Status, r.ReturnType)
+		}
+	case "file", "lf-file", "string", "lf-string":
+		if r.Content == "" || r.ContentType == "" {
+			return fmt.Errorf("http-error status %d: %s requires content and content type", r.Status, r.ReturnType)
+		}
+		payload = true
+	default:
+		return fmt.Errorf("http-error status %d: return type must be one of %s", r.Status, strings.Join(httpErrorReturnTypes, ", "))
+	}
+	if !payload && (r.ContentType != "" || len(r.Headers) > 0) {
+		return fmt.Errorf("http-error status %d: content type and headers require a file or string response", r.Status)
+	}
+	for _, h := range r.Headers {
+		if h.Name == "" || h.Fmt == "" || strings.ContainsAny(h.Name+h.Fmt, " \t#") {
+			return fmt.Errorf("http-error status %d: header name and format can not be empty nor contain whitespace or '#'", r.Status)
+		}
+	}
+	return nil
+}
+
+// GetHTTPErrorRules returns configuration version and an array of
+// configured http-error rules in the specified parent. Returns error on fail.
+func (c *Client) GetHTTPErrorRules(parentType string, parentName string, transactionID string) (int64, []*HTTPErrorRule, error) {
+	section, name, err := httpErrorSection(parentType, parentName)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	p, err := c.GetParser(transactionID)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	v, err := c.GetVersion(transactionID)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	if !c.checkSectionExists(section, name, p) {
+		return v, nil, NewConfError(ErrParentDoesNotExist, fmt.Sprintf("%s %s does not exist", parentType, parentName))
+	}
+
+	rules, err := ParseHTTPErrorRules(section, name, p)
+	if err != nil {
+		return v, nil, c.handleError("", parentType, parentName, "", false, err)
+	}
+
+	return v, rules, nil
+}
+
+// GetHTTPErrorRule returns configuration version and the http-error rule of the status
+// in the specified parent. Returns error on fail or if http-error rule does not exist.
+func (c *Client) GetHTTPErrorRule(status int64, parentType string, parentName string, transactionID string) (int64, *HTTPErrorRule, error) {
+	section, name, err := httpErrorSection(parentType, parentName)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	p, err := c.GetParser(transactionID)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	v, err := c.GetVersion(transactionID)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	rule, err := getHTTPErrorRule(status, section, name, p)
+	if err != nil {
+		return v, nil, c.handleError(strconv.FormatInt(status, 10), parentType, parentName, "", false, err)
+	}
+	if rule == nil {
+		return v, nil, NewConfError(ErrObjectDoesNotExist, fmt.Sprintf("http-error status %d does not exist in %s %s", status, parentType, parentName))
+	}
+
+	return v, rule, nil
+}
+
+// DeleteHTTPErrorRule deletes the http-error rule of the status in configuration. One of version
+// or transactionID is mandatory. Returns error on fail, nil on success.
+func (c *Client) DeleteHTTPErrorRule(status int64, parentType string, parentName string, transactionID string, version int64) error {
+	return c.setHTTPErrorRule("DeleteHTTPErrorRule", status, parentType, parentName, nil, false, transactionID, version)
+}
+
+// CreateHTTPErrorRule creates an http-error rule in configuration, a status can have a single
+// rule. One of version or transactionID is mandatory. Returns error on fail, nil on success.
+func (c *Client) CreateHTTPErrorRule(parentType string, parentName string, data *HTTPErrorRule, transactionID string, version int64) error {
+	if err := data.Validate(); err != nil {
+		return NewConfError(ErrValidationError, err.Error())
+	}
+	return c.setHTTPErrorRule("CreateHTTPErrorRule", data.Status, parentType, parentName, data, true, transactionID, version)
+}
+
+// EditHTTPErrorRule edits the http-error rule of the status in configuration. One of version or
+// transactionID is mandatory. Returns error on fail, nil on success.
+func (c *Client) EditHTTPErrorRule(status int64, parentType string, parentName string, data *HTTPErrorRule, transactionID string, version int64) error {
+	if err := data.Validate(); err != nil {
+		return NewConfError(ErrValidationError, err.Error())
+	}
+	if data.Status != status {
+		return NewConfError(ErrValidationError, fmt.Sprintf("http-error status %d can not be changed to %d", status, data.Status))
+	}
+	return c.setHTTPErrorRule("EditHTTPErrorRule", status, parentType, parentName, data, false, transactionID, version)
+}
+
+func (c *Client) setHTTPErrorRule(operation string, status int64, parentType string, parentName string, data *HTTPErrorRule, create bool, transactionID string, version int64) error {
+	section, name, err := httpErrorSection(parentType, parentName)
+	if err != nil {
+		return err
+	}
+
+	if err := c.authorize(operation, "http_error_rule", authorizedIndex(status), parentType, parentName, transactionID); err != nil {
+		return err
+	}
+
+	p, t, err := c.loadDataForChange(transactionID, version)
+	if err != nil {
+		return err
+	}
+
+	id := strconv.FormatInt(status, 10)
+	if !c.checkSectionExists(section, name, p) {
+		e := NewConfError(ErrParentDoesNotExist, fmt.Sprintf("%s %s does not exist", parentType, parentName))
+		return c.handleError(id, parentType, parentName, t, transactionID == "", e)
+	}
+
+	rule, err := getHTTPErrorRule(status, section, name, p)
+	if err != nil {
+		return c.handleError(id, parentType, parentName, t, transactionID == "", err)
+	}
+	if create && rule != nil {
+		e := NewConfError(ErrObjectAlreadyExists, fmt.Sprintf("http-error status %d already exists in %s %s", status, parentType, parentName))
+		return c.handleError(id, parentType, parentName, t, transactionID == "", e)
+	}
+	if !create && rule == nil {
+		e := NewConfError(ErrObjectDoesNotExist, fmt.Sprintf("http-error status %d does not exist in %s %s", status, parentType, parentName))
+		return c.handleError(id, parentType, parentName, t, transactionID == "", e)
+	}
+	if data != nil && data.ReturnType == "errorfiles" && !c.checkSectionExists(parser.HTTPErrors, data.Content, p) {
+		e := NewConfError(ErrValidationError, fmt.Sprintf("HTTPErrorsSection %s does not exist", data.Content))
+		return c.handleError(id, parentType, parentName, t, transactionID == "", e)
+	}
+
+	var value *string
+	if data != nil {
+		v := serializeHTTPErrorRuleValue(*data)
+		value = &v
+	}
+	if err := setRawDirective(p, section, name, "http-error status "+id, value); err != nil {
+		return c.handleError(id, parentType, parentName, t, transactionID == "", err)
+	}
+
+	if err := c.saveData(p, t, transactionID == ""); err != nil {
+		return err
+	}
+	return nil
+}
+
+// ParseHTTPErrorRules returns the http-error rules of the section, http-error lines have no
+// parser and are kept as unprocessed lines
+func ParseHTTPErrorRules(section parser.Section, name string, p *parser.Parser) ([]*HTTPErrorRule, error) {
+	rules := []*HTTPErrorRule{}
+	lines, err := getRawLines(p, section, name)
+	if err != nil {
+		return nil, err
+	}
+	for _, l := range lines {
+		if r := ParseHTTPErrorRule(l.Value); r != nil {
+			rules = append(rules, r)
+		}
+	}
+	return rules, nil
+}
+
+// ParseHTTPErrorRule returns the rule of an http-error line, nil if the line is not one
+func ParseHTTPErrorRule(line string) *HTTPErrorRule {
+	value, ok := matchRawDirective(line, "http-error status")
+	if !ok {
+		return nil
+	}
+	words := strings.Fields(value)
+	if len(words) == 0 {
+		return nil
+	}
+	status, err := strconv.ParseInt(words[0], 10, 64)
+	if err != nil {
+		return nil
+	}
+	r := &HTTPErrorRule{Status: status}
+	for i := 1; i < len(words); i++ {
+		switch words[i] {
+		case "content-type":
+			if i+1 < len(words) {
+				r.ContentType = words[i+1]
+				i++
+			}
+		case "default-errorfiles":
+			r.ReturnType = words[i]
+		case "errorfile", "errorfiles", "file", "lf-file", "string", "lf-string":
+			r.ReturnType = words[i]
+			if i+1 < len(words) {
+				r.Content = words[i+1]
+				i++
+			}
+		case "hdr":
+			if i+2 < len(words) {
+				r.Headers = append(r.Headers, &HTTPErrorHeader{Name: words[i+1], Fmt: words[i+2]})
+				i += 2
+			}
+		}
+	}
+	return r
+}
+
+func SerializeHTTPErrorRule(r HTTPErrorRule) string {
+	return rawDirectiveLine("http-error status "+strconv.FormatInt(r.Status, 10), serializeHTTPErrorRuleValue(r))
+}
+
+func serializeHTTPErrorRuleValue(r HTTPErrorRule) string {
+	words := []string{}
+	if r.ContentType != "" {
+		words = append(words, "content-type", r.ContentType)
+	}
+	if r.ReturnType != "" {
+		words = append(words, r.ReturnType)
+	}
+	if r.Content != "" {
+		words = append(words, r.Content)
+	}
+	for _, h := range r.Headers {
+		words = append(words, "hdr", h.Name, h.Fmt)
+	}
+	return strings.Join(words, " ")
+}
+
+func getHTTPErrorRule(status int64, section parser.Section, name string, p *parser.Parser) (*HTTPErrorRule, error) {
+	rules, err := ParseHTTPErrorRules(section, name, p)
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range rules {
+		if r.Status == status {
+			return r, nil
+		}
+	}
+	return nil, nil
+}
+
+func httpErrorSection(parentType string, parentName string) (parser.Section, string, error) {
+	switch parentType {
+	case "defaults":
+		return parser.Defaults, parser.DefaultSectionName, nil
+	case "frontend":
+		return parser.Frontends, parentName, nil
+	case "backend":
+		return parser.Backends, parentName, nil
+	default:
+		return "", "", NewConfError(ErrValidationError, fmt.Sprintf("http-error is not supported in %s", parentType))
+	}
+}
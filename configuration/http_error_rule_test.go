@@ -0,0 +1,98 @@
+// Copyright 2019 HAProxy Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package configuration
+
+import (
+	"testing"
+)
+
+func TestCreateEditDeleteHTTPErrorRule(t *testing.T) {
+	_, rules, err := client.GetHTTPErrorRules("frontend", "test", "")
+	if err != nil {
+		t.Error(err.Error())
+	}
+	if len(rules) != 0 {
+		t.Errorf("%v http-error rules returned, expected 0", len(rules))
+	}
+
+	r := &HTTPErrorRule{
+		Status:      503,
+		ContentType: "application/json",
+		ReturnType:  "string",
+		Content:     `{"error":"unavailable"}`,
+		Headers:     []*HTTPErrorHeader{{Name: "Retry-After", Fmt: "30"}},
+	}
+	if err := client.CreateHTTPErrorRule("frontend", "test", r, "", version); err != nil {
+		t.Error(err.Error())
+	} else {
+		version++
+	}
+
+	if err := client.CreateHTTPErrorRule("frontend", "test", r, "", version); err == nil {
+		t.Error("Should throw error, http-error status 503 already exists")
+	}
+
+	_, rule, err := client.GetHTTPErrorRule(503, "frontend", "test", "")
+	if err != nil {
+		t.Error(err.Error())
+	} else {
+		if rule.ContentType != "application/json" || rule.ReturnType != "string" || rule.Content != `{"error":"unavailable"}` {
+			t.Errorf("http-error rule %+v not as created", rule)
+		}
+		if len(rule.Headers) != 1 || rule.Headers[0].Name != "Retry-After" || rule.Headers[0].Fmt != "30" {
+			t.Errorf("http-error rule headers %v not as created", rule.Headers)
+		}
+	}
+
+	edit := &HTTPErrorRule{Status: 503, ReturnType: "errorfile", Content: "/etc/haproxy/errors/503.http"}
+	if err := client.EditHTTPErrorRule(503, "frontend", "test", edit, "", version); err != nil {
+		t.Error(err.Error())
+	} else {
+		version++
+	}
+
+	_, rules, err = client.GetHTTPErrorRules("frontend", "test", "")
+	if err != nil {
+		t.Error(err.Error())
+	}
+	if len(rules) != 1 || rules[0].ReturnType != "errorfile" || rules[0].Content != "/etc/haproxy/errors/503.http" || len(rules[0].Headers) != 0 {
+		t.Errorf("http-error rules %v not as edited", rules)
+	}
+
+	invalid := []*HTTPErrorRule{
+		{Status: 418, ReturnType: "default-errorfiles"},
+		{Status: 500, ReturnType: "string", Content: "error"},
+		{Status: 500, ReturnType: "errorfile", Content: "/tmp/500.http", ContentType: "text/plain"},
+		{Status: 500, ReturnType: "errorfiles", Content: "nonexistent"},
+	}
+	for _, i := range invalid {
+		if err := client.CreateHTTPErrorRule("backend", "test_2", i, "", version); err == nil {
+			t.Errorf("Should throw error, invalid http-error rule %+v", i)
+		}
+	}
+
+	if err := client.DeleteHTTPErrorRule(503, "frontend", "test", "", version); err != nil {
+		t.Error(err.Error())
+	} else {
+		version++
+	}
+	if err := client.DeleteHTTPErrorRule(503, "frontend", "test", "", version); err == nil {
+		t.Error("Should throw error, non existent http-error rule")
+	}
+	if _, _, err := client.GetHTTPErrorRule(503, "frontend", "test", ""); err == nil {
+		t.Error("Should throw error, non existent http-error rule")
+	}
+}
@@ -0,0 +1,395 @@
+// Copyright 2019 HAProxy Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package configuration
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	strfmt "github.com/go-openapi/strfmt"
+	parser "github.com/haproxytech/config-parser/v3"
+	"github.com/haproxytech/config-parser/v3/types"
+	"github.com/haproxytech/models/v2"
+)
+
+// HTTPErrorsSection is an http-errors section, a group of error pages frontends and backends
+// import with errorfiles
+type HTTPErrorsSection struct {
+	Name       string              `json:"name"`
+	ErrorFiles []*models.Errorfile `json:"error_files"`
+}
+
+// ErrorfilesReference is an errorfiles directive importing the error pages of an http-errors
+// section, all pages when no codes are given
+type ErrorfilesReference struct {
+	Name  string  `json:"name"`
+	Codes []int64 `json:"codes,omitempty"`
+}
+
+// Validate checks the section name and error pages, codes can be set only once
+func (s *HTTPErrorsSection) Validate() error {
+	if s.Name == "" || strings.ContainsAny(s.Name, " \t#") {
+		return fmt.Errorf("invalid http-errors section name %s", s.Name)
+	}
+	codes := map[int64]bool{}
+	for _, ef := range s.ErrorFiles {
+		if err := ef.Validate(strfmt.Default); err != nil {
+			return err
+		}
+		if ef.Code == 0 || ef.File == "" || strings.ContainsAny(ef.File, " \t#") {
+			return fmt.Errorf("http-errors %s: errorfile requires a code and a file without whitespace", s.Name)
+		}
+		if codes[ef.Code] {
+			return fmt.Errorf("http-errors %s: errorfile %d set more than once", s.Name, ef.Code)
+		}
+		codes[ef.Code] = true
+	}
+	return nil
+}
+
+// GetHTTPErrorsSections returns configuration version and an array of
+// configured http-errors sections. Returns error on fail.
+func (c *Client) GetHTTPErrorsSections(transactionID string) (int64, []*HTTPErrorsSection, error) {
+	p, err := c.GetParser(transactionID)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	v, err := c.GetVersion(transactionID)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	names, err := p.SectionsGet(parser.HTTPErrors)
+	if err != nil {
+		return v, nil, err
+	}
+
+	sections := []*HTTPErrorsSection{}
+	for _, name := range names {
+		s, err := ParseHTTPErrorsSection(p, name)
+		if err != nil {
+			return v, nil, c.handleError(name, "", "", "", false, err)
+		}
+		sections = append(sections, s)
+	}
+
+	return v, sections, nil
+}
+
+// GetHTTPErrorsSection returns configuration version and a requested http-errors section.
+// Returns error on fail or if http-errors section does not exist.
+func (c *Client) GetHTTPErrorsSection(name string, transactionID string) (int64, *HTTPErrorsSection, error) {
+	p, err := c.GetParser(transactionID)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	v, err := c.GetVersion(transactionID)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	if !c.checkSectionExists(parser.HTTPErrors, name, p) {
+		return v, nil, NewConfError(ErrObjectDoesNotExist, fmt.Sprintf("HTTPErrorsSection %s does not exist", name))
+	}
+
+	s, err := ParseHTTPErrorsSection(p, name)
+	if err != nil {
+		return v, nil, c.handleError(name, "", "", "", false, err)
+	}
+
+	return v, s, nil
+}
+
+// DeleteHTTPErrorsSection deletes an http-errors section in configuration. Sections imported with
+// errorfiles can not be deleted. One of version or transactionID is mandatory. Returns error on
+// fail, nil on success.
+func (c *Client) DeleteHTTPErrorsSection(name string, transactionID string, version int64) error {
+	if err := c.authorize("DeleteHTTPErrorsSection", "http_errors", name, "", "", transactionID); err != nil {
+		return err
+	}
+
+	p, t, err := c.loadDataForChange(transactionID, version)
+	if err != nil {
+		return err
+	}
+
+	if !c.checkSectionExists(parser.HTTPErrors, name, p) {
+		e := NewConfError(ErrObjectDoesNotExist, fmt.Sprintf("%s %s does not exist", parser.HTTPErrors, name))
+		return c.handleError(name, "", "", t, transactionID == "", e)
+	}
+
+	r := regexp.MustCompile(`(?m)^\s*errorfiles\s+` + regexp.QuoteMeta(name) + `(\s|$)`)
+	if r.MatchString(configString(p)) {
+		e := NewConfError(ErrValidationError, fmt.Sprintf("%s %s is imported with errorfiles", parser.HTTPErrors, name))
+		return c.handleError(name, "", "", t, transactionID == "", e)
+	}
+
+	if err := p.SectionsDelete(parser.HTTPErrors, name); err != nil {
+		return c.handleError(name, "", "", t, transactionID == "", err)
+	}
+
+	if err := c.saveData(p, t, transactionID == ""); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// CreateHTTPErrorsSection creates an http-errors section in configuration. One of version or
+// transactionID is mandatory. Returns error on fail, nil on success.
+func (c *Client) CreateHTTPErrorsSection(data *HTTPErrorsSection, transactionID string, version int64) error {
+	if err := data.Validate(); err != nil {
+		return NewConfError(ErrValidationError, err.Error())
+	}
+
+	if err := c.authorize("CreateHTTPErrorsSection", "http_errors", data.Name, "", "", transactionID); err != nil {
+		return err
+	}
+
+	p, t, err := c.loadDataForChange(transactionID, version)
+	if err != nil {
+		return err
+	}
+
+	if err := p.SectionsCreate(parser.HTTPErrors, data.Name); err != nil {
+		return c.handleError(data.Name, "", "", t, transactionID == "", err)
+	}
+	if err := SerializeHTTPErrorsSection(p, data); err != nil {
+		return c.handleError(data.Name, "", "", t, transactionID == "", err)
+	}
+
+	if err := c.saveData(p, t, transactionID == ""); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// EditHTTPErrorsSection replaces the error pages of an http-errors section in configuration. One
+// of version or transactionID is mandatory. Returns error on fail, nil on success.
+func (c *Client) EditHTTPErrorsSection(name string, data *HTTPErrorsSection, transactionID string, version int64) error {
+	if err := data.Validate(); err != nil {
+		return NewConfError(ErrValidationError, err.Error())
+	}
+	if data.Name != name {
+		return NewConfError(ErrValidationError, fmt.Sprintf("http-errors section %s can not be renamed to %s", name, data.Name))
+	}
+
+	if err := c.authorize("EditHTTPErrorsSection", "http_errors", name, "", "", transactionID); err != nil {
+		return err
+	}
+
+	p, t, err := c.loadDataForChange(transactionID, version)
+	if err != nil {
+		return err
+	}
+
+	if !c.checkSectionExists(parser.HTTPErrors, name, p) {
+		e := NewConfError(ErrObjectDoesNotExist, fmt.Sprintf("%s %s does not exist", parser.HTTPErrors, name))
+		return c.handleError(name, "", "", t, transactionID == "", e)
+	}
+
+	if err := SerializeHTTPErrorsSection(p, data); err != nil {
+		return c.handleError(name, "", "", t, transactionID == "", err)
+	}
+
+	if err := c.saveData(p, t, transactionID == ""); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// GetErrorfilesReferences returns configuration version and the http-errors sections imported
+// by the defaults, frontend or backend section. Returns error on fail.
+func (c *Client) GetErrorfilesReferences(parentType string, parentName string, transactionID string) (int64, []*ErrorfilesReference, error) {
+	section, name, err := errorfilesSection(parentType, parentName)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	p, err := c.GetParser(transactionID)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	v, err := c.GetVersion(transactionID)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	if !c.checkSectionExists(section, name, p) {
+		return v, nil, NewConfError(ErrParentDoesNotExist, fmt.Sprintf("%s %s does not exist", parentType, parentName))
+	}
+
+	lines, err := getRawLines(p, section, name)
+	if err != nil {
+		return v, nil, c.handleError("errorfiles", parentType, parentName, "", false, err)
+	}
+	refs := []*ErrorfilesReference{}
+	for _, l := range lines {
+		value, ok := matchRawDirective(l.Value, "errorfiles")
+		if !ok || value == "" {
+			continue
+		}
+		words := strings.Fields(value)
+		ref := &ErrorfilesReference{Name: words[0]}
+		for _, w := range words[1:] {
+			if code, err := strconv.ParseInt(w, 10, 64); err == nil {
+				ref.Codes = append(ref.Codes, code)
+			}
+		}
+		refs = append(refs, ref)
+	}
+	return v, refs, nil
+}
+
+// SetErrorfilesReference imports the error pages of the http-errors section into the defaults,
+// frontend or backend section, replacing an import of the same section. Nil codes import all
+// pages. One of version or transactionID is mandatory. Returns error on fail, nil on success.
+func (c *Client) SetErrorfilesReference(parentType string, parentName string, data *ErrorfilesReference, transactionID string, version int64) error {
+	return c.setErrorfilesReference("SetErrorfilesReference", parentType, parentName, data.Name, data, transactionID, version)
+}
+
+// DeleteErrorfilesReference removes the import of the http-errors section from the defaults,
+// frontend or backend section. One of version or transactionID is mandatory. Returns error on
+// fail, nil on success.
+func (c *Client) DeleteErrorfilesReference(parentType string, parentName string, httpErrors string, transactionID string, version int64) error {
+	return c.setErrorfilesReference("DeleteErrorfilesReference", parentType, parentName, httpErrors, nil, transactionID, version)
+}
+
+func (c *Client) setErrorfilesReference(operation string, parentType string, parentName string, httpErrors string, data *ErrorfilesReference, transactionID string, version int64) error {
+	section, name, err := errorfilesSection(parentType, parentName)
+	if err != nil {
+		return err
+	}
+
+	if err := c.authorize(operation, "errorfiles", httpErrors, parentType, parentName, transactionID); err != nil {
+		return err
+	}
+
+	p, t, err := c.loadDataForChange(transactionID, version)
+	if err != nil {
+		return err
+	}
+
+	if !c.checkSectionExists(section, name, p) {
+		e := NewConfError(ErrParentDoesNotExist, fmt.Sprintf("%s %s does not exist", parentType, parentName))
+		return c.handleError(httpErrors, parentType, parentName, t, transactionID == "", e)
+	}
+
+	keyword := "errorfiles " + httpErrors
+	var value *string
+	if data != nil {
+		if !c.checkSectionExists(parser.HTTPErrors, httpErrors, p) {
+			e := NewConfError(ErrObjectDoesNotExist, fmt.Sprintf("HTTPErrorsSection %s does not exist", httpErrors))
+			return c.handleError(httpErrors, parentType, parentName, t, transactionID == "", e)
+		}
+		s, err := ParseHTTPErrorsSection(p, httpErrors)
+		if err != nil {
+			return c.handleError(httpErrors, parentType, parentName, t, transactionID == "", err)
+		}
+		codes := make([]string, 0, len(data.Codes))
+		for _, code := range data.Codes {
+			found := false
+			for _, ef := range s.ErrorFiles {
+				found = found || ef.Code == code
+			}
+			if !found {
+				e := NewConfError(ErrValidationError, fmt.Sprintf("http-errors %s has no errorfile %d", httpErrors, code))
+				return c.handleError(httpErrors, parentType, parentName, t, transactionID == "", e)
+			}
+			codes = append(codes, strconv.FormatInt(code, 10))
+		}
+		v := strings.Join(codes, " ")
+		value = &v
+	} else if _, found, err := getRawDirective(p, section, name, keyword); err != nil || !found {
+		e := NewConfError(ErrObjectDoesNotExist, fmt.Sprintf("%s %s does not import http-errors %s", parentType, parentName, httpErrors))
+		return c.handleError(httpErrors, parentType, parentName, t, transactionID == "", e)
+	}
+
+	if err := setRawDirective(p, section, name, keyword, value); err != nil {
+		return c.handleError(httpErrors, parentType, parentName, t, transactionID == "", err)
+	}
+
+	if err := c.saveData(p, t, transactionID == ""); err != nil {
+		return err
+	}
+	return nil
+}
+
+// ParseHTTPErrorsSection returns the http-errors section, errorfile lines of the section have no
+// parser and are kept as unprocessed lines
+func ParseHTTPErrorsSection(p *parser.Parser, name string) (*HTTPErrorsSection, error) {
+	s := &HTTPErrorsSection{Name: name, ErrorFiles: []*models.Errorfile{}}
+	lines, err := getRawLines(p, parser.HTTPErrors, name)
+	if err != nil {
+		return nil, err
+	}
+	for _, l := range lines {
+		value, ok := matchRawDirective(l.Value, "errorfile")
+		if !ok {
+			continue
+		}
+		words := strings.Fields(value)
+		if len(words) != 2 {
+			continue
+		}
+		code, err := strconv.ParseInt(words[0], 10, 64)
+		if err != nil {
+			continue
+		}
+		s.ErrorFiles = append(s.ErrorFiles, &models.Errorfile{Code: code, File: words[1]})
+	}
+	return s, nil
+}
+
+func SerializeHTTPErrorsSection(p *parser.Parser, data *HTTPErrorsSection) error {
+	lines, err := getRawLines(p, parser.HTTPErrors, data.Name)
+	if err != nil {
+		return err
+	}
+	result := []types.UnProcessed{}
+	for _, l := range lines {
+		if _, ok := matchRawDirective(l.Value, "errorfile"); !ok {
+			result = append(result, l)
+		}
+	}
+	for _, ef := range data.ErrorFiles {
+		result = append(result, types.UnProcessed{Value: fmt.Sprintf("errorfile %d %s", ef.Code, ef.File)})
+	}
+	if len(result) == 0 {
+		return p.Set(parser.HTTPErrors, data.Name, "", nil)
+	}
+	return p.Set(parser.HTTPErrors, data.Name, "", result)
+}
+
+func errorfilesSection(parentType string, parentName string) (parser.Section, string, error) {
+	switch parentType {
+	case "defaults":
+		return parser.Defaults, parser.DefaultSectionName, nil
+	case "frontend":
+		return parser.Frontends, parentName, nil
+	case "backend":
+		return parser.Backends, parentName, nil
+	default:
+		return "", "", NewConfError(ErrValidationError, fmt.Sprintf("errorfiles is not supported in %s", parentType))
+	}
+}
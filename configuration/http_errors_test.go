@@ -0,0 +1,107 @@
+// Copyright 2019 HAProxy Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package configuration
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/haproxytech/models/v2"
+)
+
+func TestCreateEditDeleteHTTPErrorsSection(t *testing.T) {
+	s := &HTTPErrorsSection{
+		Name: "website",
+		ErrorFiles: []*models.Errorfile{
+			{Code: 503, File: "/etc/haproxy/errors/503.http"},
+			{Code: 503, File: "/etc/haproxy/errors/503-other.http"},
+		},
+	}
+	if err := client.CreateHTTPErrorsSection(s, "", version); err == nil {
+		t.Error("Should throw error, errorfile 503 set twice")
+		version++
+	}
+	s.ErrorFiles[1] = &models.Errorfile{Code: 404, File: "/etc/haproxy/errors/404.http"}
+	if err := client.CreateHTTPErrorsSection(s, "", version); err == nil {
+		t.Error("Should throw error, 404 is not a supported code")
+		version++
+	}
+
+	s.ErrorFiles[1] = &models.Errorfile{Code: 500, File: "/etc/haproxy/errors/500.http"}
+	if err := client.CreateHTTPErrorsSection(s, "", version); err != nil {
+		t.Fatal(err.Error())
+	}
+	version++
+
+	v, section, err := client.GetHTTPErrorsSection("website", "")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if !reflect.DeepEqual(section, s) {
+		t.Errorf("HTTPErrorsSection %v returned, expected %v", section, s)
+	}
+	if v != version {
+		t.Errorf("Version %v returned, expected %v", v, version)
+	}
+
+	s.ErrorFiles = s.ErrorFiles[:1]
+	if err := client.EditHTTPErrorsSection("website", s, "", version); err != nil {
+		t.Error(err.Error())
+	} else {
+		version++
+	}
+	_, sections, err := client.GetHTTPErrorsSections("")
+	if err != nil {
+		t.Error(err.Error())
+	} else if len(sections) != 1 || !reflect.DeepEqual(sections[0], s) {
+		t.Errorf("Unexpected http-errors sections: %v", sections)
+	}
+
+	ref := &ErrorfilesReference{Name: "website", Codes: []int64{500}}
+	if err := client.SetErrorfilesReference("backend", "test_2", ref, "", version); err == nil {
+		t.Error("Should throw error, http-errors website has no errorfile 500")
+		version++
+	}
+	ref.Codes = []int64{503}
+	if err := client.SetErrorfilesReference("backend", "test_2", ref, "", version); err != nil {
+		t.Fatal(err.Error())
+	}
+	version++
+	_, refs, err := client.GetErrorfilesReferences("backend", "test_2", "")
+	if err != nil {
+		t.Error(err.Error())
+	} else if len(refs) != 1 || !reflect.DeepEqual(refs[0], ref) {
+		t.Errorf("Unexpected errorfiles: %v", refs)
+	}
+
+	if err := client.DeleteHTTPErrorsSection("website", "", version); err == nil {
+		t.Error("Should throw error, http-errors website imported by backend test_2")
+		version++
+	}
+	if err := client.DeleteErrorfilesReference("backend", "test_2", "website", "", version); err != nil {
+		t.Error(err.Error())
+	} else {
+		version++
+	}
+	if err := client.DeleteHTTPErrorsSection("website", "", version); err != nil {
+		t.Error(err.Error())
+	} else {
+		version++
+	}
+	if _, _, err := client.GetHTTPErrorsSection("website", ""); err == nil {
+		t.Error("DeleteHTTPErrorsSection failed, http-errors website still exists")
+	}
+}
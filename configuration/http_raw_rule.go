@@ -0,0 +1,266 @@
+// Copyright 2019 HAProxy Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package configuration
+
+import (
+	"fmt"
+	"strings"
+
+	parser "github.com/haproxytech/config-parser/v3"
+
+	"github.com/haproxytech/client-native/v2/misc"
+)
+
+// httpRequestRawActions are the http-request actions not covered by the config parser and
+// the HTTPRequestRule model, rules using them are kept as raw lines
+var httpRequestRawActions = []string{"return", "normalize-uri", "wait-for-body"}
+
+// httpResponseRawActions are the http-response actions not covered by the config parser and
+// the HTTPResponseRule model, rules using them are kept as raw lines
+var httpResponseRawActions = []string{"cache-store", "wait-for-body"}
+
+// HTTPRawRule is an http-request or http-response rule with an action the rule models do not
+// cover. The rules are written after the ones handled by the models, so HAProxy evaluates
+// them last. Index is the position among the raw rules of the same keyword.
+type HTTPRawRule struct {
+	Index *int64 `json:"index"`
+	// Type is the action of the rule
+	Type string `json:"type"`
+	// Params are the arguments of the action, written as given
+	Params   string `json:"params,omitempty"`
+	Cond     string `json:"cond,omitempty"`
+	CondTest string `json:"cond_test,omitempty"`
+}
+
+// validate checks the action is one of actions and that the rule fits on one line
+func (r *HTTPRawRule) validate(keyword string, actions []string) error {
+	if r.Index == nil {
+		return fmt.Errorf("%s rule index is required", keyword)
+	}
+	if !misc.StringInSlice(r.Type, actions) {
+		return fmt.Errorf("%s raw rule type must be one of %s", keyword, strings.Join(actions, ", "))
+	}
+	if strings.ContainsAny(r.Params+r.CondTest, "#\r\n") {
+		return fmt.Errorf("%s %s: params and cond_test can not contain '#' or line breaks", keyword, r.Type)
+	}
+	if r.Cond != "" && r.Cond != "if" && r.Cond != "unless" {
+		return fmt.Errorf("%s %s: cond must be if or unless", keyword, r.Type)
+	}
+	if (r.Cond == "") != (r.CondTest == "") {
+		return fmt.Errorf("%s %s: cond and cond_test must be set together", keyword, r.Type)
+	}
+	return nil
+}
+
+// GetHTTPRequestRawRules returns configuration version and the http-request rules of the
+// parent kept as raw lines. Returns error on fail.
+func (c *Client) GetHTTPRequestRawRules(parentType, parentName string, transactionID string) (int64, []*HTTPRawRule, error) {
+	return c.getHTTPRawRules("http-request", parentType, parentName, transactionID)
+}
+
+// GetHTTPRequestRawRule returns configuration version and a requested http-request rule kept
+// as raw line. Returns error on fail or if the rule does not exist.
+func (c *Client) GetHTTPRequestRawRule(id int64, parentType, parentName string, transactionID string) (int64, *HTTPRawRule, error) {
+	return c.getHTTPRawRule(id, "http-request", parentType, parentName, transactionID)
+}
+
+// CreateHTTPRequestRawRule creates an http-request rule with a return, normalize-uri or
+// wait-for-body action. One of version or transactionID is mandatory. Returns error on fail,
+// nil on success.
+func (c *Client) CreateHTTPRequestRawRule(parentType string, parentName string, data *HTTPRawRule, transactionID string, version int64) error {
+	if err := data.validate("http-request", httpRequestRawActions); err != nil {
+		return NewConfError(ErrValidationError, err.Error())
+	}
+	if err := c.authorize("CreateHTTPRequestRawRule", "http_request_rule", authorizedIndexP(data.Index), parentType, parentName, transactionID); err != nil {
+		return err
+	}
+	return c.setHTTPRawRule(*data.Index, "http-request", parentType, parentName, data, true, transactionID, version)
+}
+
+// EditHTTPRequestRawRule replaces an http-request rule kept as raw line. One of version or
+// transactionID is mandatory. Returns error on fail, nil on success.
+func (c *Client) EditHTTPRequestRawRule(id int64, parentType string, parentName string, data *HTTPRawRule, transactionID string, version int64) error {
+	if err := data.validate("http-request", httpRequestRawActions); err != nil {
+		return NewConfError(ErrValidationError, err.Error())
+	}
+	if err := c.authorize("EditHTTPRequestRawRule", "http_request_rule", authorizedIndex(id), parentType, parentName, transactionID); err != nil {
+		return err
+	}
+	return c.setHTTPRawRule(id, "http-request", parentType, parentName, data, false, transactionID, version)
+}
+
+// DeleteHTTPRequestRawRule deletes an http-request rule kept as raw line. One of version or
+// transactionID is mandatory. Returns error on fail, nil on success.
+func (c *Client) DeleteHTTPRequestRawRule(id int64, parentType string, parentName string, transactionID string, version int64) error {
+	if err := c.authorize("DeleteHTTPRequestRawRule", "http_request_rule", authorizedIndex(id), parentType, parentName, transactionID); err != nil {
+		return err
+	}
+	return c.setHTTPRawRule(id, "http-request", parentType, parentName, nil, false, transactionID, version)
+}
+
+// GetHTTPResponseRawRules returns configuration version and the http-response rules of the
+// parent kept as raw lines. Returns error on fail.
+func (c *Client) GetHTTPResponseRawRules(parentType, parentName string, transactionID string) (int64, []*HTTPRawRule, error) {
+	return c.getHTTPRawRules("http-response", parentType, parentName, transactionID)
+}
+
+// GetHTTPResponseRawRule returns configuration version and a requested http-response rule
+// kept as raw line. Returns error on fail or if the rule does not exist.
+func (c *Client) GetHTTPResponseRawRule(id int64, parentType, parentName string, transactionID string) (int64, *HTTPRawRule, error) {
+	return c.getHTTPRawRule(id, "http-response", parentType, parentName, transactionID)
+}
+
+// CreateHTTPResponseRawRule creates an http-response rule with a cache-store or wait-for-body
+// action. One of version or transactionID is mandatory. Returns error on fail, nil on success.
+func (c *Client) CreateHTTPResponseRawRule(parentType string, parentName string, data *HTTPRawRule, transactionID string, version int64) error {
+	if err := data.validate("http-response", httpResponseRawActions); err != nil {
+		return NewConfError(ErrValidationError, err.Error())
+	}
+	if err := c.authorize("CreateHTTPResponseRawRule", "http_response_rule", authorizedIndexP(data.Index), parentType, parentName, transactionID); err != nil {
+		return err
+	}
+	return c.setHTTPRawRule(*data.Index, "http-response", parentType, parentName, data, true, transactionID, version)
+}
+
+// EditHTTPResponseRawRule replaces an http-response rule kept as raw line. One of version or
+// transactionID is mandatory. Returns error on fail, nil on success.
+func (c *Client) EditHTTPResponseRawRule(id int64, parentType string, parentName string, data *HTTPRawRule, transactionID string, version int64) error {
+	if err := data.validate("http-response", httpResponseRawActions); err != nil {
+		return NewConfError(ErrValidationError, err.Error())
+	}
+	if err := c.authorize("EditHTTPResponseRawRule", "http_response_rule", authorizedIndex(id), parentType, parentName, transactionID); err != nil {
+		return err
+	}
+	return c.setHTTPRawRule(id, "http-response", parentType, parentName, data, false, transactionID, version)
+}
+
+// DeleteHTTPResponseRawRule deletes an http-response rule kept as raw line. One of version or
+// transactionID is mandatory. Returns error on fail, nil on success.
+func (c *Client) DeleteHTTPResponseRawRule(id int64, parentType string, parentName string, transactionID string, version int64) error {
+	if err := c.authorize("DeleteHTTPResponseRawRule", "http_response_rule", authorizedIndex(id), parentType, parentName, transactionID); err != nil {
+		return err
+	}
+	return c.setHTTPRawRule(id, "http-response", parentType, parentName, nil, false, transactionID, version)
+}
+
+func (c *Client) getHTTPRawRules(keyword, parentType, parentName string, transactionID string) (int64, []*HTTPRawRule, error) {
+	p, err := c.GetParser(transactionID)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	v, err := c.GetVersion(transactionID)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	section := httpRawRuleSection(parentType)
+	if !c.checkSectionExists(section, parentName, p) {
+		return v, nil, NewConfError(ErrParentDoesNotExist, fmt.Sprintf("%s %s does not exist", parentType, parentName))
+	}
+
+	lines, err := getRawRules(p, section, parentName, keyword)
+	if err != nil {
+		return v, nil, c.handleError("", parentType, parentName, "", false, err)
+	}
+	rules := make([]*HTTPRawRule, 0, len(lines))
+	for i, line := range lines {
+		rules = append(rules, parseHTTPRawRule(int64(i), keyword, line))
+	}
+	return v, rules, nil
+}
+
+func (c *Client) getHTTPRawRule(id int64, keyword, parentType, parentName string, transactionID string) (int64, *HTTPRawRule, error) {
+	v, rules, err := c.getHTTPRawRules(keyword, parentType, parentName, transactionID)
+	if err != nil {
+		return v, nil, err
+	}
+	if id < 0 || id >= int64(len(rules)) {
+		return v, nil, NewConfError(ErrObjectDoesNotExist, fmt.Sprintf("%s raw rule %d does not exist in %s %s", keyword, id, parentType, parentName))
+	}
+	return v, rules[id], nil
+}
+
+// setHTTPRawRule inserts, replaces or, for nil data, deletes the raw rule at the index
+func (c *Client) setHTTPRawRule(id int64, keyword, parentType, parentName string, data *HTTPRawRule, insert bool, transactionID string, version int64) error {
+	p, t, err := c.loadDataForChange(transactionID, version)
+	if err != nil {
+		return err
+	}
+
+	section := httpRawRuleSection(parentType)
+	if !c.checkSectionExists(section, parentName, p) {
+		e := NewConfError(ErrParentDoesNotExist, fmt.Sprintf("%s %s does not exist", parentType, parentName))
+		return c.handleError(authorizedIndex(id), parentType, parentName, t, transactionID == "", e)
+	}
+
+	var line *string
+	if data != nil {
+		l := serializeHTTPRawRule(keyword, data)
+		line = &l
+	}
+	if err := setRawRule(p, section, parentName, keyword, int(id), line, insert); err != nil {
+		return c.handleError(authorizedIndex(id), parentType, parentName, t, transactionID == "", err)
+	}
+
+	if err := c.saveData(p, t, transactionID == ""); err != nil {
+		return err
+	}
+	return nil
+}
+
+func httpRawRuleSection(parentType string) parser.Section {
+	if parentType == "backend" {
+		return parser.Backends
+	}
+	return parser.Frontends
+}
+
+func parseHTTPRawRule(id int64, keyword, line string) *HTTPRawRule {
+	fields := strings.Fields(line)[len(strings.Fields(keyword)):]
+	rule := &HTTPRawRule{Index: &id}
+	if len(fields) == 0 {
+		return rule
+	}
+	rule.Type = fields[0]
+	params := fields[1:]
+	quoted := false
+	for i, f := range params {
+		// words of quoted arguments are not conditions
+		if strings.Count(f, "\"")%2 == 1 {
+			quoted = !quoted
+		}
+		if !quoted && (f == "if" || f == "unless") {
+			rule.Cond = f
+			rule.CondTest = strings.Join(params[i+1:], " ")
+			params = params[:i]
+			break
+		}
+	}
+	rule.Params = strings.Join(params, " ")
+	return rule
+}
+
+func serializeHTTPRawRule(keyword string, r *HTTPRawRule) string {
+	line := []string{keyword, r.Type}
+	if r.Params != "" {
+		line = append(line, r.Params)
+	}
+	if r.Cond != "" {
+		line = append(line, r.Cond, r.CondTest)
+	}
+	return strings.Join(line, " ")
+}
@@ -0,0 +1,129 @@
+// Copyright 2019 HAProxy Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package configuration
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/haproxytech/client-native/v2/misc"
+)
+
+func TestCreateEditDeleteHTTPRequestRawRule(t *testing.T) {
+	rules := []*HTTPRawRule{
+		{Index: misc.Int64P(0), Type: "wait-for-body", Params: "time 1s at-least 1k"},
+		{Index: misc.Int64P(1), Type: "return", Params: `status 200 content-type "text/plain" string "if ok"`, Cond: "if", CondTest: "{ path /ping }"},
+		{Index: misc.Int64P(2), Type: "normalize-uri", Params: "path-merge-slashes"},
+	}
+	for _, r := range rules {
+		if err := client.CreateHTTPRequestRawRule("frontend", "test_2", r, "", version); err != nil {
+			t.Error(err.Error())
+		} else {
+			version++
+		}
+	}
+
+	v, ondisk, err := client.GetHTTPRequestRawRules("frontend", "test_2", "")
+	if err != nil {
+		t.Error(err.Error())
+	}
+	if !reflect.DeepEqual(ondisk, rules) {
+		for _, r := range ondisk {
+			fmt.Printf("Created raw rule: %v\n", *r)
+		}
+		t.Error("Created http-request raw rules not equal to given rules")
+	}
+	if v != version {
+		t.Errorf("Version %v returned, expected %v", v, version)
+	}
+
+	// modelled rules are not affected
+	_, modelled, err := client.GetHTTPRequestRules("frontend", "test_2", "")
+	if err != nil {
+		t.Error(err.Error())
+	}
+	for _, r := range modelled {
+		if r.Type == "return" || r.Type == "normalize-uri" || r.Type == "wait-for-body" {
+			t.Errorf("Raw rule %s returned as http request rule", r.Type)
+		}
+	}
+
+	edited := &HTTPRawRule{Index: misc.Int64P(1), Type: "return", Params: "status 204", Cond: "unless", CondTest: "TRUE"}
+	if err := client.EditHTTPRequestRawRule(1, "frontend", "test_2", edited, "", version); err != nil {
+		t.Error(err.Error())
+	} else {
+		version++
+	}
+	_, r, err := client.GetHTTPRequestRawRule(1, "frontend", "test_2", "")
+	if err != nil {
+		t.Error(err.Error())
+	} else if !reflect.DeepEqual(r, edited) {
+		t.Errorf("Edited raw rule %v not equal to given rule %v", *r, *edited)
+	}
+
+	invalid := &HTTPRawRule{Index: misc.Int64P(0), Type: "deny"}
+	if err := client.CreateHTTPRequestRawRule("frontend", "test_2", invalid, "", version); err == nil {
+		t.Error("Should throw error, deny is not a raw rule")
+		version++
+	}
+	if err := client.DeleteHTTPRequestRawRule(5, "frontend", "test_2", "", version); err == nil {
+		t.Error("Should throw error, non existant raw rule")
+		version++
+	}
+
+	for i := len(rules) - 1; i >= 0; i-- {
+		if err := client.DeleteHTTPRequestRawRule(int64(i), "frontend", "test_2", "", version); err != nil {
+			t.Error(err.Error())
+		} else {
+			version++
+		}
+	}
+	if _, ondisk, _ := client.GetHTTPRequestRawRules("frontend", "test_2", ""); len(ondisk) != 0 {
+		t.Errorf("%v raw rules left, expected 0", len(ondisk))
+	}
+}
+
+func TestCreateDeleteHTTPResponseRawRule(t *testing.T) {
+	r := &HTTPRawRule{Index: misc.Int64P(0), Type: "cache-store", Params: "test_cache"}
+	if err := client.CreateHTTPResponseRawRule("backend", "test_2", r, "", version); err != nil {
+		t.Error(err.Error())
+	} else {
+		version++
+	}
+
+	_, ondisk, err := client.GetHTTPResponseRawRule(0, "backend", "test_2", "")
+	if err != nil {
+		t.Error(err.Error())
+	} else if !reflect.DeepEqual(ondisk, r) {
+		t.Errorf("Created raw rule %v not equal to given rule %v", *ondisk, *r)
+	}
+
+	invalid := &HTTPRawRule{Index: misc.Int64P(0), Type: "return"}
+	if err := client.CreateHTTPResponseRawRule("backend", "test_2", invalid, "", version); err == nil {
+		t.Error("Should throw error, return is not an http-response raw rule")
+		version++
+	}
+
+	if err := client.DeleteHTTPResponseRawRule(0, "backend", "test_2", "", version); err != nil {
+		t.Error(err.Error())
+	} else {
+		version++
+	}
+	if _, _, err := client.GetHTTPResponseRawRule(0, "backend", "test_2", ""); err == nil {
+		t.Error("DeleteHTTPResponseRawRule failed, raw rule still exists")
+	}
+}
@@ -16,6 +16,7 @@
 package configuration
 
 import (
+	"fmt"
 	"strconv"
 	"strings"
 
@@ -88,6 +89,10 @@ func (c *Client) GetHTTPRequestRule(id int64, parentType, parentName string, tra
 // DeleteHTTPRequestRule deletes a http request rule in configuration. One of version or transactionID is
 // mandatory. Returns error on fail, nil on success.
 func (c *Client) DeleteHTTPRequestRule(id int64, parentType string, parentName string, transactionID string, version int64) error {
+	if err := c.authorize("DeleteHTTPRequestRule", "http_request_rule", authorizedIndex(id), parentType, parentName, transactionID); err != nil {
+		return err
+	}
+
 	p, t, err := c.loadDataForChange(transactionID, version)
 	if err != nil {
 		return err
@@ -120,6 +125,10 @@ func (c *Client) CreateHTTPRequestRule(parentType string, parentName string, dat
 		}
 	}
 
+	if err := c.authorize("CreateHTTPRequestRule", "http_request_rule", authorizedIndexP(data.Index), parentType, parentName, transactionID); err != nil {
+		return err
+	}
+
 	p, t, err := c.loadDataForChange(transactionID, version)
 	if err != nil {
 		return err
@@ -134,7 +143,7 @@ func (c *Client) CreateHTTPRequestRule(parentType string, parentName string, dat
 
 	s, err := SerializeHTTPRequestRule(*data)
 	if err != nil {
-		return err
+		return c.handleError(strconv.FormatInt(*data.Index, 10), parentType, parentName, t, transactionID == "", err)
 	}
 
 	if err := p.Insert(section, parentName, "http-request", s, int(*data.Index)); err != nil {
@@ -156,6 +165,10 @@ func (c *Client) EditHTTPRequestRule(id int64, parentType string, parentName str
 			return NewConfError(ErrValidationError, validationErr.Error())
 		}
 	}
+	if err := c.authorize("EditHTTPRequestRule", "http_request_rule", authorizedIndex(id), parentType, parentName, transactionID); err != nil {
+		return err
+	}
+
 	p, t, err := c.loadDataForChange(transactionID, version)
 	if err != nil {
 		return err
@@ -174,7 +187,7 @@ func (c *Client) EditHTTPRequestRule(id int64, parentType string, parentName str
 
 	s, err := SerializeHTTPRequestRule(*data)
 	if err != nil {
-		return err
+		return c.handleError(strconv.FormatInt(id, 10), parentType, parentName, t, transactionID == "", err)
 	}
 
 	if err := p.Set(section, parentName, "http-request", s, int(id)); err != nil {
@@ -619,12 +632,18 @@ func ParseHTTPRequestRule(f types.HTTPAction) (rule *models.HTTPRequestRule, err
 			Cond:        v.Cond,
 			CondTest:    v.CondTest,
 		}
+	default:
+		return nil, NewConfError(ErrValidationError, "unsupported action in http_request_rule, return, normalize-uri and wait-for-body rules are handled as raw rules")
 	}
 
 	return rule, err
 }
 
 func SerializeHTTPRequestRule(f models.HTTPRequestRule) (rule types.HTTPAction, err error) {
+	if err := validateHTTPRequestRuleParams(f); err != nil {
+		return nil, err
+	}
+
 	switch f.Type {
 	case "allow":
 		rule = &actions.Allow{
@@ -976,7 +995,53 @@ func SerializeHTTPRequestRule(f models.HTTPRequestRule) (rule types.HTTPAction,
 			Cond:     f.Cond,
 			CondTest: f.CondTest,
 		}
+	default:
+		return nil, NewConfError(ErrValidationError, "unsupported action in http_request_rule, return, normalize-uri and wait-for-body rules are handled as raw rules")
 	}
 
 	return rule, err
 }
+
+// validateHTTPRequestRuleParams checks that the parameters an action needs are set, an empty
+// parameter would be written as a rule HAProxy can not parse
+func validateHTTPRequestRuleParams(f models.HTTPRequestRule) error {
+	var missing string
+	switch f.Type {
+	case "set-map":
+		if f.MapFile == "" || f.MapKeyfmt == "" || f.MapValuefmt == "" {
+			missing = "map_file, map_keyfmt and map_valuefmt"
+		}
+	case "del-map":
+		if f.MapFile == "" || f.MapKeyfmt == "" {
+			missing = "map_file and map_keyfmt"
+		}
+	case "track-sc0":
+		if f.TrackSc0Key == "" {
+			missing = "track-sc0-key"
+		}
+	case "track-sc1":
+		if f.TrackSc1Key == "" {
+			missing = "track-sc1-key"
+		}
+	case "track-sc2":
+		if f.TrackSc2Key == "" {
+			missing = "track-sc2-key"
+		}
+	case "set-priority-class", "set-priority-offset", "set-dst", "set-dst-port", "set-src", "set-src-port":
+		if f.Expr == "" {
+			missing = "expr"
+		}
+	case "use-service":
+		if f.ServiceName == "" {
+			missing = "service_name"
+		}
+	case "lua":
+		if f.LuaAction == "" {
+			missing = "lua_action"
+		}
+	}
+	if missing != "" {
+		return NewConfError(ErrValidationError, fmt.Sprintf("http-request %s requires %s", f.Type, missing))
+	}
+	return nil
+}
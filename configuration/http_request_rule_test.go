@@ -605,3 +605,59 @@ func TestCreateEditDeleteHTTPRequestRule(t *testing.T) {
 		version++
 	}
 }
+
+func TestHTTPRequestRuleRoundTrip(t *testing.T) {
+	rules := []*models.HTTPRequestRule{
+		{Type: "set-map", MapFile: "map.lst", MapKeyfmt: "%[src]", MapValuefmt: "%[req.hdr(X-Value)]", Cond: "if", CondTest: "FALSE"},
+		{Type: "del-map", MapFile: "map.lst", MapKeyfmt: "%[src]"},
+		{Type: "track-sc1", TrackSc1Key: "src", TrackSc1Table: "test_2"},
+		{Type: "track-sc2", TrackSc2Key: "src"},
+		{Type: "sc-inc-gpc0", ScID: 1, Cond: "if", CondTest: "FALSE"},
+		{Type: "set-priority-class", Expr: "int(1)"},
+		{Type: "use-service", ServiceName: "prometheus-exporter", Cond: "if", CondTest: "TRUE"},
+	}
+	for i, r := range rules {
+		id := int64(i + 2)
+		r.Index = &id
+		if err := client.CreateHTTPRequestRule("frontend", "test_2", r, "", version); err != nil {
+			t.Error(err.Error())
+		} else {
+			version++
+		}
+	}
+
+	for _, r := range rules {
+		_, ondiskR, err := client.GetHTTPRequestRule(*r.Index, "frontend", "test_2", "")
+		if err != nil {
+			t.Error(err.Error())
+			continue
+		}
+		if !reflect.DeepEqual(ondiskR, r) {
+			fmt.Printf("Created HTTP request rule: %v\n", ondiskR)
+			fmt.Printf("Given HTTP request rule: %v\n", r)
+			t.Errorf("Created HTTP request %s rule not equal to given HTTP request rule", r.Type)
+		}
+	}
+
+	id := int64(2)
+	invalid := []*models.HTTPRequestRule{
+		{Index: &id, Type: "return"},
+		{Index: &id, Type: "set-map", MapFile: "map.lst"},
+		{Index: &id, Type: "track-sc0"},
+		{Index: &id, Type: "use-service"},
+	}
+	for _, r := range invalid {
+		if err := client.CreateHTTPRequestRule("frontend", "test_2", r, "", version); err == nil {
+			t.Errorf("Should throw error, invalid http-request %s rule", r.Type)
+			version++
+		}
+	}
+
+	for i := len(rules) - 1; i >= 0; i-- {
+		if err := client.DeleteHTTPRequestRule(*rules[i].Index, "frontend", "test_2", "", version); err != nil {
+			t.Error(err.Error())
+		} else {
+			version++
+		}
+	}
+}
@@ -74,7 +74,10 @@ func (c *Client) GetHTTPResponseRule(id int64, parentType, parentName string, tr
 		return v, nil, c.handleError(strconv.FormatInt(id, 10), parentType, parentName, "", false, err)
 	}
 
-	httpRule := ParseHTTPResponseRule(data.(types.HTTPAction))
+	httpRule, err := ParseHTTPResponseRule(data.(types.HTTPAction))
+	if err != nil {
+		return v, nil, c.handleError(strconv.FormatInt(id, 10), parentType, parentName, "", false, err)
+	}
 	httpRule.Index = &id
 
 	return v, httpRule, nil
@@ -83,6 +86,10 @@ func (c *Client) GetHTTPResponseRule(id int64, parentType, parentName string, tr
 // DeleteHTTPResponseRule deletes a http response rule in configuration. One of version or transactionID is
 // mandatory. Returns error on fail, nil on success.
 func (c *Client) DeleteHTTPResponseRule(id int64, parentType string, parentName string, transactionID string, version int64) error {
+	if err := c.authorize("DeleteHTTPResponseRule", "http_response_rule", authorizedIndex(id), parentType, parentName, transactionID); err != nil {
+		return err
+	}
+
 	p, t, err := c.loadDataForChange(transactionID, version)
 	if err != nil {
 		return err
@@ -115,6 +122,10 @@ func (c *Client) CreateHTTPResponseRule(parentType string, parentName string, da
 			return NewConfError(ErrValidationError, validationErr.Error())
 		}
 	}
+	if err := c.authorize("CreateHTTPResponseRule", "http_response_rule", authorizedIndexP(data.Index), parentType, parentName, transactionID); err != nil {
+		return err
+	}
+
 	p, t, err := c.loadDataForChange(transactionID, version)
 	if err != nil {
 		return err
@@ -127,7 +138,12 @@ func (c *Client) CreateHTTPResponseRule(parentType string, parentName string, da
 		section = parser.Frontends
 	}
 
-	if err := p.Insert(section, parentName, "http-response", SerializeHTTPResponseRule(*data), int(*data.Index)); err != nil {
+	s, err := SerializeHTTPResponseRule(*data)
+	if err != nil {
+		return c.handleError(strconv.FormatInt(*data.Index, 10), parentType, parentName, t, transactionID == "", err)
+	}
+
+	if err := p.Insert(section, parentName, "http-response", s, int(*data.Index)); err != nil {
 		return c.handleError(strconv.FormatInt(*data.Index, 10), parentType, parentName, t, transactionID == "", err)
 	}
 
@@ -147,6 +163,10 @@ func (c *Client) EditHTTPResponseRule(id int64, parentType string, parentName st
 		}
 	}
 
+	if err := c.authorize("EditHTTPResponseRule", "http_response_rule", authorizedIndex(id), parentType, parentName, transactionID); err != nil {
+		return err
+	}
+
 	p, t, err := c.loadDataForChange(transactionID, version)
 	if err != nil {
 		return err
@@ -163,7 +183,12 @@ func (c *Client) EditHTTPResponseRule(id int64, parentType string, parentName st
 		return c.handleError(strconv.FormatInt(id, 10), parentType, parentName, t, transactionID == "", err)
 	}
 
-	if err := p.Set(section, parentName, "http-response", SerializeHTTPResponseRule(*data), int(id)); err != nil {
+	s, err := SerializeHTTPResponseRule(*data)
+	if err != nil {
+		return c.handleError(strconv.FormatInt(id, 10), parentType, parentName, t, transactionID == "", err)
+	}
+
+	if err := p.Set(section, parentName, "http-response", s, int(id)); err != nil {
 		return c.handleError(strconv.FormatInt(id, 10), parentType, parentName, t, transactionID == "", err)
 	}
 
@@ -193,8 +218,8 @@ func ParseHTTPResponseRules(t, pName string, p *parser.Parser) (models.HTTPRespo
 	rules := data.([]types.HTTPAction)
 	for i, r := range rules {
 		id := int64(i)
-		httpResRule := ParseHTTPResponseRule(r)
-		if httpResRule != nil {
+		httpResRule, err := ParseHTTPResponseRule(r)
+		if err == nil {
 			httpResRule.Index = &id
 			httpResRules = append(httpResRules, httpResRule)
 		}
@@ -202,20 +227,20 @@ func ParseHTTPResponseRules(t, pName string, p *parser.Parser) (models.HTTPRespo
 	return httpResRules, nil
 }
 
-func ParseHTTPResponseRule(f types.HTTPAction) *models.HTTPResponseRule {
+func ParseHTTPResponseRule(f types.HTTPAction) (*models.HTTPResponseRule, error) {
 	switch v := f.(type) {
 	case *actions.Allow:
 		return &models.HTTPResponseRule{
 			Type:     "allow",
 			Cond:     v.Cond,
 			CondTest: v.CondTest,
-		}
+		}, nil
 	case *actions.Deny:
 		return &models.HTTPResponseRule{
 			Type:     "deny",
 			Cond:     v.Cond,
 			CondTest: v.CondTest,
-		}
+		}, nil
 	case *actions.Redirect:
 		var codePtr *int64
 		if code, err := strconv.ParseInt(v.Code, 10, 64); err == nil {
@@ -230,7 +255,7 @@ func ParseHTTPResponseRule(f types.HTTPAction) *models.HTTPResponseRule {
 			CondTest:    v.CondTest,
 			RedirCode:   codePtr,
 		}
-		return r
+		return r, nil
 	case *actions.AddHeader:
 		return &models.HTTPResponseRule{
 			Type:      "add-header",
@@ -238,7 +263,7 @@ func ParseHTTPResponseRule(f types.HTTPAction) *models.HTTPResponseRule {
 			HdrFormat: v.Fmt,
 			Cond:      v.Cond,
 			CondTest:  v.CondTest,
-		}
+		}, nil
 	case *actions.SetHeader:
 		return &models.HTTPResponseRule{
 			Type:      "set-header",
@@ -246,14 +271,14 @@ func ParseHTTPResponseRule(f types.HTTPAction) *models.HTTPResponseRule {
 			HdrFormat: v.Fmt,
 			Cond:      v.Cond,
 			CondTest:  v.CondTest,
-		}
+		}, nil
 	case *actions.DelHeader:
 		return &models.HTTPResponseRule{
 			Type:     "del-header",
 			HdrName:  v.Name,
 			Cond:     v.Cond,
 			CondTest: v.CondTest,
-		}
+		}, nil
 	case *actions.ReplaceHeader:
 		return &models.HTTPResponseRule{
 			Type:      "replace-header",
@@ -262,7 +287,7 @@ func ParseHTTPResponseRule(f types.HTTPAction) *models.HTTPResponseRule {
 			HdrMatch:  v.MatchRegex,
 			Cond:      v.Cond,
 			CondTest:  v.CondTest,
-		}
+		}, nil
 	case *actions.ReplaceValue:
 		return &models.HTTPResponseRule{
 			Type:      "replace-value",
@@ -271,14 +296,14 @@ func ParseHTTPResponseRule(f types.HTTPAction) *models.HTTPResponseRule {
 			HdrMatch:  v.MatchRegex,
 			Cond:      v.Cond,
 			CondTest:  v.CondTest,
-		}
+		}, nil
 	case *actions.SetLogLevel:
 		return &models.HTTPResponseRule{
 			Type:     "set-log-level",
 			LogLevel: v.Level,
 			Cond:     v.Cond,
 			CondTest: v.CondTest,
-		}
+		}, nil
 	case *actions.SetVar:
 		return &models.HTTPResponseRule{
 			Type:     "set-var",
@@ -287,7 +312,7 @@ func ParseHTTPResponseRule(f types.HTTPAction) *models.HTTPResponseRule {
 			VarScope: v.VarScope,
 			Cond:     v.Cond,
 			CondTest: v.CondTest,
-		}
+		}, nil
 	case *actions.SetStatus:
 		status, _ := strconv.ParseInt(v.Status, 10, 64)
 		r := &models.HTTPResponseRule{
@@ -299,7 +324,7 @@ func ParseHTTPResponseRule(f types.HTTPAction) *models.HTTPResponseRule {
 		if status != 0 {
 			r.Status = status
 		}
-		return r
+		return r, nil
 	case *actions.AddACL:
 		return &models.HTTPResponseRule{
 			Type:      "add-acl",
@@ -307,7 +332,7 @@ func ParseHTTPResponseRule(f types.HTTPAction) *models.HTTPResponseRule {
 			ACLKeyfmt: v.KeyFmt,
 			Cond:      v.Cond,
 			CondTest:  v.CondTest,
-		}
+		}, nil
 	case *actions.DelACL:
 		return &models.HTTPResponseRule{
 			Type:      "del-acl",
@@ -315,7 +340,7 @@ func ParseHTTPResponseRule(f types.HTTPAction) *models.HTTPResponseRule {
 			ACLKeyfmt: v.KeyFmt,
 			Cond:      v.Cond,
 			CondTest:  v.CondTest,
-		}
+		}, nil
 	case *actions.SendSpoeGroup:
 		return &models.HTTPResponseRule{
 			Type:       "send-spoe-group",
@@ -323,7 +348,7 @@ func ParseHTTPResponseRule(f types.HTTPAction) *models.HTTPResponseRule {
 			SpoeGroup:  v.Group,
 			Cond:       v.Cond,
 			CondTest:   v.CondTest,
-		}
+		}, nil
 	case *actions.Capture:
 		return &models.HTTPResponseRule{
 			Type:          "capture",
@@ -331,7 +356,7 @@ func ParseHTTPResponseRule(f types.HTTPAction) *models.HTTPResponseRule {
 			Cond:          v.Cond,
 			CondTest:      v.CondTest,
 			CaptureID:     v.SlotID,
-		}
+		}, nil
 	case *actions.SetMap:
 		return &models.HTTPResponseRule{
 			Type:        "set-map",
@@ -340,7 +365,7 @@ func ParseHTTPResponseRule(f types.HTTPAction) *models.HTTPResponseRule {
 			MapValuefmt: v.ValueFmt,
 			Cond:        v.Cond,
 			CondTest:    v.CondTest,
-		}
+		}, nil
 	case *actions.DelMap:
 		return &models.HTTPResponseRule{
 			Type:      "del-map",
@@ -348,7 +373,7 @@ func ParseHTTPResponseRule(f types.HTTPAction) *models.HTTPResponseRule {
 			MapKeyfmt: v.KeyFmt,
 			Cond:      v.Cond,
 			CondTest:  v.CondTest,
-		}
+		}, nil
 	case *actions.ScIncGpc0:
 		ID, _ := strconv.ParseInt(v.ID, 10, 64)
 		return &models.HTTPResponseRule{
@@ -356,7 +381,7 @@ func ParseHTTPResponseRule(f types.HTTPAction) *models.HTTPResponseRule {
 			ScID:     ID,
 			Cond:     v.Cond,
 			CondTest: v.CondTest,
-		}
+		}, nil
 	case *actions.ScIncGpc1:
 		ID, _ := strconv.ParseInt(v.ID, 10, 64)
 		return &models.HTTPResponseRule{
@@ -364,10 +389,10 @@ func ParseHTTPResponseRule(f types.HTTPAction) *models.HTTPResponseRule {
 			ScID:     ID,
 			Cond:     v.Cond,
 			CondTest: v.CondTest,
-		}
+		}, nil
 	case *actions.ScSetGpt0:
 		if (v.Int == nil && len(v.Expr.Expr) == 0) || (v.Int != nil && len(v.Expr.Expr) > 0) {
-			return nil
+			return nil, NewConfError(ErrValidationError, "sc-set-gpt0 requires either an integer or an expression")
 		}
 		ID, _ := strconv.ParseInt(v.ID, 10, 64)
 		return &models.HTTPResponseRule{
@@ -377,14 +402,14 @@ func ParseHTTPResponseRule(f types.HTTPAction) *models.HTTPResponseRule {
 			ScInt:    v.Int,
 			Cond:     v.Cond,
 			CondTest: v.CondTest,
-		}
+		}, nil
 	case *actions.SetMark:
 		return &models.HTTPResponseRule{
 			Type:      "set-mark",
 			MarkValue: v.Value,
 			Cond:      v.Cond,
 			CondTest:  v.CondTest,
-		}
+		}, nil
 	case *actions.SetNice:
 		nice, _ := strconv.ParseInt(v.Value, 10, 64)
 		return &models.HTTPResponseRule{
@@ -392,20 +417,20 @@ func ParseHTTPResponseRule(f types.HTTPAction) *models.HTTPResponseRule {
 			NiceValue: nice,
 			Cond:      v.Cond,
 			CondTest:  v.CondTest,
-		}
+		}, nil
 	case *actions.SetTos:
 		return &models.HTTPResponseRule{
 			Type:     "set-tos",
 			TosValue: v.Value,
 			Cond:     v.Cond,
 			CondTest: v.CondTest,
-		}
+		}, nil
 	case *actions.SilentDrop:
 		return &models.HTTPResponseRule{
 			Type:     "silent-drop",
 			Cond:     v.Cond,
 			CondTest: v.CondTest,
-		}
+		}, nil
 	case *actions.UnsetVar:
 		return &models.HTTPResponseRule{
 			Type:     "unset-var",
@@ -413,7 +438,7 @@ func ParseHTTPResponseRule(f types.HTTPAction) *models.HTTPResponseRule {
 			VarScope: v.Scope,
 			Cond:     v.Cond,
 			CondTest: v.CondTest,
-		}
+		}, nil
 	case *actions.TrackSc0:
 		return &models.HTTPResponseRule{
 			Type:          "track-sc0",
@@ -421,7 +446,7 @@ func ParseHTTPResponseRule(f types.HTTPAction) *models.HTTPResponseRule {
 			TrackSc0Table: v.Table,
 			Cond:          v.Cond,
 			CondTest:      v.CondTest,
-		}
+		}, nil
 	case *actions.TrackSc1:
 		return &models.HTTPResponseRule{
 			Type:          "track-sc1",
@@ -429,7 +454,7 @@ func ParseHTTPResponseRule(f types.HTTPAction) *models.HTTPResponseRule {
 			TrackSc1Table: v.Table,
 			Cond:          v.Cond,
 			CondTest:      v.CondTest,
-		}
+		}, nil
 	case *actions.TrackSc2:
 		return &models.HTTPResponseRule{
 			Type:          "track-sc2",
@@ -437,14 +462,14 @@ func ParseHTTPResponseRule(f types.HTTPAction) *models.HTTPResponseRule {
 			TrackSc2Table: v.Table,
 			Cond:          v.Cond,
 			CondTest:      v.CondTest,
-		}
+		}, nil
 	case *actions.StrictMode:
 		return &models.HTTPResponseRule{
 			Type:       "strict-mode",
 			StrictMode: v.Mode,
 			Cond:       v.Cond,
 			CondTest:   v.CondTest,
-		}
+		}, nil
 	case *actions.Lua:
 		return &models.HTTPResponseRule{
 			Type:      "lua",
@@ -452,23 +477,23 @@ func ParseHTTPResponseRule(f types.HTTPAction) *models.HTTPResponseRule {
 			LuaParams: v.Params,
 			Cond:      v.Cond,
 			CondTest:  v.CondTest,
-		}
+		}, nil
 	}
-	return nil
+	return nil, NewConfError(ErrValidationError, "unsupported action in http_response_rule, cache-store and wait-for-body rules are handled as raw rules")
 }
 
-func SerializeHTTPResponseRule(f models.HTTPResponseRule) types.HTTPAction {
+func SerializeHTTPResponseRule(f models.HTTPResponseRule) (types.HTTPAction, error) {
 	switch f.Type {
 	case "allow":
 		return &actions.Allow{
 			Cond:     f.Cond,
 			CondTest: f.CondTest,
-		}
+		}, nil
 	case "deny":
 		return &actions.Deny{
 			Cond:     f.Cond,
 			CondTest: f.CondTest,
-		}
+		}, nil
 	case "redirect":
 		code := ""
 		if f.RedirCode != nil {
@@ -481,27 +506,27 @@ func SerializeHTTPResponseRule(f models.HTTPResponseRule) types.HTTPAction {
 			Option:   f.RedirOption,
 			Cond:     f.Cond,
 			CondTest: f.CondTest,
-		}
+		}, nil
 	case "add-header":
 		return &actions.AddHeader{
 			Name:     f.HdrName,
 			Fmt:      f.HdrFormat,
 			Cond:     f.Cond,
 			CondTest: f.CondTest,
-		}
+		}, nil
 	case "set-header":
 		return &actions.SetHeader{
 			Name:     f.HdrName,
 			Fmt:      f.HdrFormat,
 			Cond:     f.Cond,
 			CondTest: f.CondTest,
-		}
+		}, nil
 	case "del-header":
 		return &actions.DelHeader{
 			Name:     f.HdrName,
 			Cond:     f.Cond,
 			CondTest: f.CondTest,
-		}
+		}, nil
 	case "replace-header":
 		return &actions.ReplaceHeader{
 			Name:       f.HdrName,
@@ -509,7 +534,7 @@ func SerializeHTTPResponseRule(f models.HTTPResponseRule) types.HTTPAction {
 			MatchRegex: f.HdrMatch,
 			Cond:       f.Cond,
 			CondTest:   f.CondTest,
-		}
+		}, nil
 	case "replace-value":
 		return &actions.ReplaceValue{
 			Name:       f.HdrName,
@@ -517,20 +542,26 @@ func SerializeHTTPResponseRule(f models.HTTPResponseRule) types.HTTPAction {
 			MatchRegex: f.HdrMatch,
 			Cond:       f.Cond,
 			CondTest:   f.CondTest,
-		}
+		}, nil
 	case "set-log-level":
 		return &actions.SetLogLevel{
 			Level:    f.LogLevel,
 			Cond:     f.Cond,
 			CondTest: f.CondTest,
-		}
+		}, nil
 	case "set-status":
+		if f.Status < 100 || f.Status > 999 {
+			return nil, NewConfError(ErrValidationError, "set-status requires a status between 100 and 999")
+		}
+		if strings.ContainsAny(f.StatusReason, " \t") {
+			return nil, NewConfError(ErrValidationError, "set-status reason can not contain whitespace")
+		}
 		return &actions.SetStatus{
 			Status:   strconv.FormatInt(f.Status, 10),
 			Reason:   f.StatusReason,
 			Cond:     f.Cond,
 			CondTest: f.CondTest,
-		}
+		}, nil
 	case "set-var":
 		return &actions.SetVar{
 			Expr:     common.Expression{Expr: strings.Split(f.VarExpr, " ")},
@@ -538,35 +569,35 @@ func SerializeHTTPResponseRule(f models.HTTPResponseRule) types.HTTPAction {
 			VarScope: f.VarScope,
 			Cond:     f.Cond,
 			CondTest: f.CondTest,
-		}
+		}, nil
 	case "add-acl":
 		return &actions.AddACL{
 			FileName: f.ACLFile,
 			KeyFmt:   f.ACLKeyfmt,
 			Cond:     f.Cond,
 			CondTest: f.CondTest,
-		}
+		}, nil
 	case "del-acl":
 		return &actions.DelACL{
 			FileName: f.ACLFile,
 			KeyFmt:   f.ACLKeyfmt,
 			Cond:     f.Cond,
 			CondTest: f.CondTest,
-		}
+		}, nil
 	case "send-spoe-group":
 		return &actions.SendSpoeGroup{
 			Engine:   f.SpoeEngine,
 			Group:    f.SpoeGroup,
 			Cond:     f.Cond,
 			CondTest: f.CondTest,
-		}
+		}, nil
 	case "capture":
 		return &actions.Capture{
 			Sample:   f.CaptureSample,
 			Cond:     f.Cond,
 			CondTest: f.CondTest,
 			SlotID:   f.CaptureID,
-		}
+		}, nil
 	case "set-map":
 		return &actions.SetMap{
 			FileName: f.MapFile,
@@ -574,29 +605,29 @@ func SerializeHTTPResponseRule(f models.HTTPResponseRule) types.HTTPAction {
 			ValueFmt: f.MapValuefmt,
 			Cond:     f.Cond,
 			CondTest: f.CondTest,
-		}
+		}, nil
 	case "del-map":
 		return &actions.DelMap{
 			FileName: f.MapFile,
 			KeyFmt:   f.MapKeyfmt,
 			Cond:     f.Cond,
 			CondTest: f.CondTest,
-		}
+		}, nil
 	case "sc-inc-gpc0":
 		return &actions.ScIncGpc0{
 			ID:       strconv.FormatInt(f.ScID, 10),
 			Cond:     f.Cond,
 			CondTest: f.CondTest,
-		}
+		}, nil
 	case "sc-inc-gpc1":
 		return &actions.ScIncGpc1{
 			ID:       strconv.FormatInt(f.ScID, 10),
 			Cond:     f.Cond,
 			CondTest: f.CondTest,
-		}
+		}, nil
 	case "sc-set-gpt0":
 		if (len(f.ScExpr) > 0 && f.ScInt != nil) || (len(f.ScExpr) == 0 && f.ScInt == nil) {
-			return nil
+			return nil, NewConfError(ErrValidationError, "sc-set-gpt0 requires either an integer or an expression")
 		}
 		return &actions.ScSetGpt0{
 			ID:       strconv.FormatInt(f.ScID, 10),
@@ -604,71 +635,71 @@ func SerializeHTTPResponseRule(f models.HTTPResponseRule) types.HTTPAction {
 			Expr:     common.Expression{Expr: strings.Split(f.ScExpr, " ")},
 			Cond:     f.Cond,
 			CondTest: f.CondTest,
-		}
+		}, nil
 	case "set-mark":
 		return &actions.SetMark{
 			Value:    f.MarkValue,
 			Cond:     f.Cond,
 			CondTest: f.CondTest,
-		}
+		}, nil
 	case "set-nice":
 		return &actions.SetNice{
 			Value:    strconv.FormatInt(f.NiceValue, 10),
 			Cond:     f.Cond,
 			CondTest: f.CondTest,
-		}
+		}, nil
 	case "set-tos":
 		return &actions.SetTos{
 			Value:    f.TosValue,
 			Cond:     f.Cond,
 			CondTest: f.CondTest,
-		}
+		}, nil
 	case "silent-drop":
 		return &actions.SilentDrop{
 			Cond:     f.Cond,
 			CondTest: f.CondTest,
-		}
+		}, nil
 	case "unset-var":
 		return &actions.UnsetVar{
 			Name:     f.VarName,
 			Scope:    f.VarScope,
 			Cond:     f.Cond,
 			CondTest: f.CondTest,
-		}
+		}, nil
 	case "track-sc0":
 		return &actions.TrackSc0{
 			Key:      f.TrackSc0Key,
 			Table:    f.TrackSc0Table,
 			Cond:     f.Cond,
 			CondTest: f.CondTest,
-		}
+		}, nil
 	case "track-sc1":
 		return &actions.TrackSc1{
 			Key:      f.TrackSc1Key,
 			Table:    f.TrackSc1Table,
 			Cond:     f.Cond,
 			CondTest: f.CondTest,
-		}
+		}, nil
 	case "track-sc2":
 		return &actions.TrackSc2{
 			Key:      f.TrackSc2Key,
 			Table:    f.TrackSc2Table,
 			Cond:     f.Cond,
 			CondTest: f.CondTest,
-		}
+		}, nil
 	case "strict-mode":
 		return &actions.StrictMode{
 			Mode:     f.StrictMode,
 			Cond:     f.Cond,
 			CondTest: f.CondTest,
-		}
+		}, nil
 	case "lua":
 		return &actions.Lua{
 			Action:   f.LuaAction,
 			Params:   f.LuaParams,
 			Cond:     f.Cond,
 			CondTest: f.CondTest,
-		}
+		}, nil
 	}
-	return nil
+	return nil, NewConfError(ErrValidationError, "unsupported action in http_response_rule, cache-store and wait-for-body rules are handled as raw rules")
 }
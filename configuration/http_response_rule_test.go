@@ -452,3 +452,48 @@ func TestCreateEditDeleteHTTPResponseRule(t *testing.T) {
 		version++
 	}
 }
+
+func TestSerializeHTTPResponseRuleErrors(t *testing.T) {
+	id := int64(1)
+	r := &models.HTTPResponseRule{
+		Index:        &id,
+		Type:         "set-status",
+		Status:       503,
+		StatusReason: "SlowDown",
+		Cond:         "if",
+		CondTest:     "FALSE",
+	}
+	if err := client.CreateHTTPResponseRule("frontend", "test_2", r, "", version); err != nil {
+		t.Error(err.Error())
+	} else {
+		version++
+	}
+
+	_, ondiskR, err := client.GetHTTPResponseRule(1, "frontend", "test_2", "")
+	if err != nil {
+		t.Error(err.Error())
+	}
+	if !reflect.DeepEqual(ondiskR, r) {
+		fmt.Printf("Created HTTP response rule: %v\n", ondiskR)
+		fmt.Printf("Given HTTP response rule: %v\n", r)
+		t.Error("Created HTTP response rule not equal to given HTTP response rule")
+	}
+
+	if err := client.DeleteHTTPResponseRule(1, "frontend", "test_2", "", version); err != nil {
+		t.Error(err.Error())
+	} else {
+		version++
+	}
+
+	invalid := []*models.HTTPResponseRule{
+		{Index: &id, Type: "cache-store"},
+		{Index: &id, Type: "set-status", Status: 503, StatusReason: "Slow Down"},
+		{Index: &id, Type: "sc-set-gpt0", ScID: 1},
+	}
+	for _, r := range invalid {
+		if err := client.CreateHTTPResponseRule("frontend", "test_2", r, "", version); err == nil {
+			t.Errorf("Should throw error, invalid http-response %s rule", r.Type)
+			version++
+		}
+	}
+}
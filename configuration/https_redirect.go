@@ -0,0 +1,190 @@
+// Copyright 2019 HAProxy Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package configuration
+
+import (
+	"fmt"
+	"strings"
+
+	strfmt "github.com/go-openapi/strfmt"
+	"github.com/haproxytech/models/v2"
+)
+
+// ACMEChallengePath is the path prefix of ACME HTTP-01 challenge requests
+const ACMEChallengePath = "/.well-known/acme-challenge/"
+
+const httpsRedirectCond = "!{ ssl_fc }"
+
+// HTTPSRedirectOptions are the options of the HTTP to HTTPS redirect of a frontend
+type HTTPSRedirectOptions struct {
+	// Code of the redirect, 301 if not set
+	Code *int64 `json:"code,omitempty"`
+	// ExcludeACME keeps ACME HTTP-01 challenge requests on plain HTTP
+	ExcludeACME bool `json:"exclude_acme,omitempty"`
+	// ExcludePaths are path prefixes which are not redirected
+	ExcludePaths []string `json:"exclude_paths,omitempty"`
+}
+
+// Validate validates the HTTPS redirect options
+func (o *HTTPSRedirectOptions) Validate(formats strfmt.Registry) error {
+	if o.Code != nil {
+		switch *o.Code {
+		case 301, 302, 303, 307, 308:
+		default:
+			return fmt.Errorf("unsupported redirect code %d", *o.Code)
+		}
+	}
+	for _, p := range o.ExcludePaths {
+		if !strings.HasPrefix(p, "/") || strings.ContainsAny(p, " \t{}") {
+			return fmt.Errorf("invalid exclude path %s", p)
+		}
+	}
+	return nil
+}
+
+// GetHTTPSRedirect returns configuration version and the HTTPS redirect options of the frontend.
+// Returns error on fail or if HTTPS redirect is not enabled.
+func (c *Client) GetHTTPSRedirect(frontend string, transactionID string) (int64, *HTTPSRedirectOptions, error) {
+	v, rules, err := c.GetHTTPRequestRules("frontend", frontend, transactionID)
+	if err != nil {
+		return 0, nil, err
+	}
+	for _, r := range rules {
+		if !isHTTPSRedirect(r) {
+			continue
+		}
+		opts := &HTTPSRedirectOptions{Code: r.RedirCode}
+		rest := strings.TrimSpace(strings.TrimPrefix(r.CondTest, httpsRedirectCond))
+		for _, p := range strings.Split(rest, "!{ path_beg ") {
+			p = strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(p), "}"))
+			if p == "" {
+				continue
+			}
+			if p == ACMEChallengePath {
+				opts.ExcludeACME = true
+				continue
+			}
+			opts.ExcludePaths = append(opts.ExcludePaths, p)
+		}
+		return v, opts, nil
+	}
+	return v, nil, NewConfError(ErrObjectDoesNotExist, fmt.Sprintf("HTTPS redirect not enabled in frontend %s", frontend))
+}
+
+// EnableHTTPSRedirect creates the scheme redirect rule as the first http-request rule of the
+// frontend, replacing any redirect rule created before so the frontend has exactly one.
+// One of version or transactionID is mandatory. Returns error on fail, nil on success.
+func (c *Client) EnableHTTPSRedirect(frontend string, opts *HTTPSRedirectOptions, transactionID string, version int64) error {
+	if opts == nil {
+		opts = &HTTPSRedirectOptions{}
+	}
+	if c.UseValidation {
+		validationErr := opts.Validate(strfmt.Default)
+		if validationErr != nil {
+			return NewConfError(ErrValidationError, validationErr.Error())
+		}
+	}
+
+	if err := c.authorize("EnableHTTPSRedirect", "https_redirect", "", "frontend", frontend, transactionID); err != nil {
+		return err
+	}
+
+	p, t, err := c.loadDataForChange(transactionID, version)
+	if err != nil {
+		return err
+	}
+
+	if err := c.deleteHTTPSRedirect(frontend, t); err != nil {
+		return c.handleError("", "frontend", frontend, t, transactionID == "", err)
+	}
+
+	code := int64(301)
+	if opts.Code != nil {
+		code = *opts.Code
+	}
+	condTest := []string{httpsRedirectCond}
+	if opts.ExcludeACME {
+		condTest = append(condTest, fmt.Sprintf("!{ path_beg %s }", ACMEChallengePath))
+	}
+	for _, path := range opts.ExcludePaths {
+		condTest = append(condTest, fmt.Sprintf("!{ path_beg %s }", path))
+	}
+
+	index := int64(0)
+	rule := &models.HTTPRequestRule{
+		Index:      &index,
+		Type:       "redirect",
+		RedirType:  "scheme",
+		RedirValue: "https",
+		RedirCode:  &code,
+		Cond:       "if",
+		CondTest:   strings.Join(condTest, " "),
+	}
+	if err := c.CreateHTTPRequestRule("frontend", frontend, rule, t, 0); err != nil {
+		return c.handleError("", "frontend", frontend, t, transactionID == "", err)
+	}
+
+	if err := c.saveData(p, t, transactionID == ""); err != nil {
+		return err
+	}
+	return nil
+}
+
+// DisableHTTPSRedirect deletes the HTTPS redirect rule from the frontend. One of version or
+// transactionID is mandatory. Returns error on fail, nil on success.
+func (c *Client) DisableHTTPSRedirect(frontend string, transactionID string, version int64) error {
+	if err := c.authorize("DisableHTTPSRedirect", "https_redirect", "", "frontend", frontend, transactionID); err != nil {
+		return err
+	}
+
+	p, t, err := c.loadDataForChange(transactionID, version)
+	if err != nil {
+		return err
+	}
+
+	if _, _, err := c.GetHTTPSRedirect(frontend, t); err != nil {
+		return c.handleError("", "frontend", frontend, t, transactionID == "", err)
+	}
+	if err := c.deleteHTTPSRedirect(frontend, t); err != nil {
+		return c.handleError("", "frontend", frontend, t, transactionID == "", err)
+	}
+
+	if err := c.saveData(p, t, transactionID == ""); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (c *Client) deleteHTTPSRedirect(frontend string, t string) error {
+	_, rules, err := c.GetHTTPRequestRules("frontend", frontend, t)
+	if err != nil {
+		return err
+	}
+	// delete from the end so the remaining indexes stay valid
+	for i := len(rules) - 1; i >= 0; i-- {
+		if isHTTPSRedirect(rules[i]) {
+			if err := c.DeleteHTTPRequestRule(*rules[i].Index, "frontend", frontend, t, 0); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func isHTTPSRedirect(r *models.HTTPRequestRule) bool {
+	return r.Type == "redirect" && r.RedirType == "scheme" && r.RedirValue == "https" &&
+		r.Cond == "if" && strings.HasPrefix(r.CondTest, httpsRedirectCond)
+}
@@ -0,0 +1,86 @@
+// Copyright 2019 HAProxy Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package configuration
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestEnableDisableHTTPSRedirect(t *testing.T) {
+	code := int64(308)
+	opts := &HTTPSRedirectOptions{
+		Code:         &code,
+		ExcludeACME:  true,
+		ExcludePaths: []string{"/health"},
+	}
+
+	err := client.EnableHTTPSRedirect("test_2", &HTTPSRedirectOptions{}, "", version)
+	if err != nil {
+		t.Error(err.Error())
+	} else {
+		version++
+	}
+
+	// enabling again replaces the existing redirect
+	err = client.EnableHTTPSRedirect("test_2", opts, "", version)
+	if err != nil {
+		t.Error(err.Error())
+	} else {
+		version++
+	}
+
+	v, redirect, err := client.GetHTTPSRedirect("test_2", "")
+	if err != nil {
+		t.Error(err.Error())
+	}
+
+	if !reflect.DeepEqual(redirect, opts) {
+		fmt.Printf("Enabled HTTPS redirect: %v\n", redirect)
+		fmt.Printf("Given HTTPS redirect: %v\n", opts)
+		t.Error("Enabled HTTPS redirect not equal to given HTTPS redirect")
+	}
+
+	if v != version {
+		t.Errorf("Version %v returned, expected %v", v, version)
+	}
+
+	_, rule, err := client.GetHTTPRequestRule(0, "frontend", "test_2", "")
+	if err != nil {
+		t.Error(err.Error())
+	} else if rule.CondTest != "!{ ssl_fc } !{ path_beg /.well-known/acme-challenge/ } !{ path_beg /health }" {
+		t.Errorf("CondTest not !{ ssl_fc } !{ path_beg /.well-known/acme-challenge/ } !{ path_beg /health }: %v", rule.CondTest)
+	}
+
+	err = client.DisableHTTPSRedirect("test_2", "", version)
+	if err != nil {
+		t.Error(err.Error())
+	} else {
+		version++
+	}
+
+	_, _, err = client.GetHTTPSRedirect("test_2", "")
+	if err == nil {
+		t.Error("DisableHTTPSRedirect failed, HTTPS redirect still exists")
+	}
+
+	err = client.DisableHTTPSRedirect("test_2", "", version)
+	if err == nil {
+		t.Error("Should throw error, HTTPS redirect not enabled")
+		version++
+	}
+}
@@ -0,0 +1,111 @@
+// Copyright 2019 HAProxy Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package configuration
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/haproxytech/models/v2"
+)
+
+// BindKeywordVersions are the HAProxy versions introducing the per-connection bind keywords
+var BindKeywordVersions = map[string]string{
+	"allow-0rtt": "1.8",
+	"tfo":        "1.8",
+	"proto":      "1.9",
+}
+
+// ServerKeywordVersions are the HAProxy versions introducing the per-connection server keywords
+var ServerKeywordVersions = map[string]string{
+	"allow-0rtt": "1.8",
+	"proto":      "1.9",
+	"tfo":        "2.0",
+}
+
+// bindProtos and serverProtos are the multiplexers accepted by proto
+var (
+	bindProtos   = []string{"h1", "h2"}
+	serverProtos = []string{"h1", "h2", "fcgi"}
+)
+
+// SetHAProxyVersion sets the version of the managed HAProxy, binds and servers using keywords
+// it does not support are then rejected. Empty version disables the check.
+func (c *Client) SetHAProxyVersion(version string) error {
+	if _, ok := parseMajorMinor(version); version != "" && !ok {
+		return NewConfError(ErrValidationError, fmt.Sprintf("invalid HAProxy version %s", version))
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.haproxyVersion = version
+	return nil
+}
+
+// ValidateBindKeywords checks the per-connection keywords of the bind, against the HAProxy
+// version if not empty
+func ValidateBindKeywords(b *models.Bind, haproxyVersion string) error {
+	used := []string{}
+	if b.Allow0rtt {
+		if !b.Ssl {
+			return fmt.Errorf("bind %s: allow-0rtt requires ssl", b.Name)
+		}
+		used = append(used, "allow-0rtt")
+	}
+	if b.Tfo {
+		used = append(used, "tfo")
+	}
+	if b.Proto != "" {
+		if !stringSelected(b.Proto, bindProtos) {
+			return fmt.Errorf("bind %s: unsupported proto %s, expected one of %s", b.Name, b.Proto, strings.Join(bindProtos, ", "))
+		}
+		used = append(used, "proto")
+	}
+	return checkKeywordVersions("bind", b.Name, used, BindKeywordVersions, haproxyVersion)
+}
+
+// ValidateServerKeywords checks the per-connection keywords of the server, against the HAProxy
+// version if not empty
+func ValidateServerKeywords(s *models.Server, haproxyVersion string) error {
+	used := []string{}
+	if s.Allow0rtt {
+		if s.Ssl != "enabled" {
+			return fmt.Errorf("server %s: allow-0rtt requires ssl", s.Name)
+		}
+		used = append(used, "allow-0rtt")
+	}
+	if s.Tfo != "" {
+		used = append(used, "tfo")
+	}
+	if s.Proto != "" {
+		if !stringSelected(s.Proto, serverProtos) {
+			return fmt.Errorf("server %s: unsupported proto %s, expected one of %s", s.Name, s.Proto, strings.Join(serverProtos, ", "))
+		}
+		used = append(used, "proto")
+	}
+	return checkKeywordVersions("server", s.Name, used, ServerKeywordVersions, haproxyVersion)
+}
+
+func checkKeywordVersions(objectType, name string, used []string, versions map[string]string, haproxyVersion string) error {
+	if haproxyVersion == "" {
+		return nil
+	}
+	for _, keyword := range used {
+		if min, ok := versions[keyword]; ok && !versionAtLeast(haproxyVersion, min) {
+			return fmt.Errorf("%s %s: %s requires HAProxy %s, running %s", objectType, name, keyword, min, haproxyVersion)
+		}
+	}
+	return nil
+}
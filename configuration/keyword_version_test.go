@@ -0,0 +1,114 @@
+// Copyright 2019 HAProxy Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package configuration
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/haproxytech/models/v2"
+)
+
+func TestBindServerKeywords(t *testing.T) {
+	port := int64(4400)
+	b := &models.Bind{
+		Name:           "keywords",
+		Address:        "192.168.2.1",
+		Port:           &port,
+		Ssl:            true,
+		SslCertificate: "dummy.crt",
+		Allow0rtt:      true,
+		Tfo:            true,
+		Proto:          "h2",
+	}
+	s := &models.Server{
+		Name:      "keywords",
+		Address:   "192.168.2.1",
+		Port:      &port,
+		Ssl:       "enabled",
+		Allow0rtt: true,
+		Tfo:       "enabled",
+		Proto:     "h2",
+	}
+
+	if err := client.SetHAProxyVersion("two"); err == nil {
+		t.Error("Should throw error, invalid HAProxy version")
+	}
+	if err := client.SetHAProxyVersion("1.8"); err != nil {
+		t.Fatal(err.Error())
+	}
+	if err := client.CreateBind("test", b, "", version); err == nil {
+		t.Error("Should throw error, proto requires HAProxy 1.9")
+	}
+	if err := client.CreateServer("test", s, "", version); err == nil {
+		t.Error("Should throw error, proto requires HAProxy 1.9")
+	}
+	if err := client.SetHAProxyVersion("2.2"); err != nil {
+		t.Fatal(err.Error())
+	}
+	defer client.SetHAProxyVersion("")
+
+	invalid := *b
+	invalid.Ssl = false
+	if err := client.CreateBind("test", &invalid, "", version); err == nil {
+		t.Error("Should throw error, allow-0rtt requires ssl")
+	}
+	invalid = *b
+	invalid.Proto = "fcgi"
+	if err := client.CreateBind("test", &invalid, "", version); err == nil {
+		t.Error("Should throw error, unsupported bind proto")
+	}
+
+	if err := client.CreateBind("test", b, "", version); err != nil {
+		t.Fatal(err.Error())
+	}
+	version++
+	_, bind, err := client.GetBind("keywords", "test", "")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if !reflect.DeepEqual(bind, b) {
+		fmt.Printf("Created bind: %v\n", bind)
+		fmt.Printf("Given bind: %v\n", b)
+		t.Error("Created bind not equal to given bind")
+	}
+
+	if err := client.CreateServer("test", s, "", version); err != nil {
+		t.Fatal(err.Error())
+	}
+	version++
+	_, server, err := client.GetServer("keywords", "test", "")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if !reflect.DeepEqual(server, s) {
+		fmt.Printf("Created server: %v\n", server)
+		fmt.Printf("Given server: %v\n", s)
+		t.Error("Created server not equal to given server")
+	}
+
+	if err := client.DeleteBind("keywords", "test", "", version); err != nil {
+		t.Error(err.Error())
+	} else {
+		version++
+	}
+	if err := client.DeleteServer("keywords", "test", "", version); err != nil {
+		t.Error(err.Error())
+	} else {
+		version++
+	}
+}
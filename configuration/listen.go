@@ -0,0 +1,442 @@
+// Copyright 2019 HAProxy Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package configuration
+
+import (
+	"fmt"
+	"strings"
+
+	strfmt "github.com/go-openapi/strfmt"
+	parser "github.com/haproxytech/config-parser/v3"
+	"github.com/haproxytech/config-parser/v3/params"
+	"github.com/haproxytech/config-parser/v3/types"
+	"github.com/haproxytech/models/v2"
+)
+
+// Listen is a listen section, a frontend and a backend declared in one section. The binds and
+// servers of the section are returned as objects, its other directives are kept verbatim in
+// Lines. Editing a section keeps the order of its lines and the bind and server options the
+// models do not cover.
+type Listen struct {
+	Name    string           `json:"name"`
+	Mode    string           `json:"mode,omitempty"`
+	Binds   []*models.Bind   `json:"binds,omitempty"`
+	Servers []*models.Server `json:"servers,omitempty"`
+	// Lines are the other directives of the section in order, such as options, timeouts, ACLs
+	// and rules
+	Lines []string `json:"lines,omitempty"`
+}
+
+// GetListens returns configuration version and an array of
+// configured listen sections. Returns error on fail.
+func (c *Client) GetListens(transactionID string) (int64, []*Listen, error) {
+	p, err := c.GetParser(transactionID)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	v, err := c.GetVersion(transactionID)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	names, err := p.SectionsGet(parser.Listen)
+	if err != nil {
+		return v, nil, err
+	}
+
+	listens := []*Listen{}
+	for _, name := range names {
+		l, err := ParseListen(p, name)
+		if err != nil {
+			return v, nil, c.handleError(name, "", "", "", false, err)
+		}
+		listens = append(listens, l)
+	}
+
+	return v, listens, nil
+}
+
+// GetListen returns configuration version and a requested listen section.
+// Returns error on fail or if listen section does not exist.
+func (c *Client) GetListen(name string, transactionID string) (int64, *Listen, error) {
+	p, err := c.GetParser(transactionID)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	v, err := c.GetVersion(transactionID)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	if !c.checkSectionExists(parser.Listen, name, p) {
+		return v, nil, NewConfError(ErrObjectDoesNotExist, fmt.Sprintf("Listen %s does not exist", name))
+	}
+
+	l, err := ParseListen(p, name)
+	if err != nil {
+		return v, nil, c.handleError(name, "", "", "", false, err)
+	}
+
+	return v, l, nil
+}
+
+// DeleteListen deletes a listen section in configuration. One of version or transactionID is
+// mandatory. Returns error on fail, nil on success.
+func (c *Client) DeleteListen(name string, transactionID string, version int64) error {
+	if err := c.deleteSection("DeleteListen", parser.Listen, name, transactionID, version); err != nil {
+		return err
+	}
+	return nil
+}
+
+// CreateListen creates a listen section in configuration. One of version or transactionID is
+// mandatory. Returns error on fail, nil on success.
+func (c *Client) CreateListen(data *Listen, transactionID string, version int64) error {
+	if err := c.validateListen(data); err != nil {
+		return NewConfError(ErrValidationError, err.Error())
+	}
+
+	if err := c.authorize("CreateListen", "listen", data.Name, "", "", transactionID); err != nil {
+		return err
+	}
+
+	p, t, err := c.loadDataForChange(transactionID, version)
+	if err != nil {
+		return err
+	}
+
+	if err := p.SectionsCreate(parser.Listen, data.Name); err != nil {
+		return c.handleError(data.Name, "", "", t, transactionID == "", err)
+	}
+	if err := SerializeListen(p, data); err != nil {
+		return c.handleError(data.Name, "", "", t, transactionID == "", err)
+	}
+
+	if err := c.saveData(p, t, transactionID == ""); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// EditListen replaces the content of a listen section in configuration. One of version or
+// transactionID is mandatory. Returns error on fail, nil on success.
+func (c *Client) EditListen(name string, data *Listen, transactionID string, version int64) error {
+	if err := c.validateListen(data); err != nil {
+		return NewConfError(ErrValidationError, err.Error())
+	}
+	if data.Name != name {
+		return NewConfError(ErrValidationError, fmt.Sprintf("listen %s can not be renamed to %s", name, data.Name))
+	}
+
+	if err := c.authorize("EditListen", "listen", name, "", "", transactionID); err != nil {
+		return err
+	}
+
+	p, t, err := c.loadDataForChange(transactionID, version)
+	if err != nil {
+		return err
+	}
+
+	if !c.checkSectionExists(parser.Listen, name, p) {
+		e := NewConfError(ErrObjectDoesNotExist, fmt.Sprintf("%s %s does not exist", parser.Listen, name))
+		return c.handleError(name, "", "", t, transactionID == "", e)
+	}
+
+	if err := SerializeListen(p, data); err != nil {
+		return c.handleError(name, "", "", t, transactionID == "", err)
+	}
+
+	if err := c.saveData(p, t, transactionID == ""); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// ParseListen returns the listen section, the directives of listen sections have no parser and
+// are kept as unprocessed lines
+func ParseListen(p *parser.Parser, name string) (*Listen, error) {
+	l := &Listen{Name: name}
+	lines, err := getRawLines(p, parser.Listen, name)
+	if err != nil {
+		return nil, err
+	}
+	for _, line := range lines {
+		words := strings.Fields(line.Value)
+		if len(words) == 0 {
+			continue
+		}
+		mode, b, s := parseListenLine(words)
+		switch {
+		case mode != "":
+			l.Mode = mode
+		case b != nil:
+			l.Binds = append(l.Binds, b)
+		case s != nil:
+			l.Servers = append(l.Servers, s)
+		default:
+			l.Lines = append(l.Lines, line.Value)
+		}
+	}
+	return l, nil
+}
+
+// parseListenLine returns the mode, the bind or the server the words of a listen line set, all
+// empty for other directives
+func parseListenLine(words []string) (string, *models.Bind, *models.Server) {
+	switch {
+	case words[0] == "mode" && len(words) == 2:
+		return words[1], nil, nil
+	case words[0] == "bind" && len(words) >= 2:
+		return "", ParseBind(types.Bind{Path: words[1], Params: params.ParseBindOptions(words[2:])}), nil
+	case words[0] == "server" && len(words) >= 3:
+		return "", nil, ParseServer(types.Server{Name: words[1], Address: words[2], Params: params.ParseServerOptions(words[3:])})
+	}
+	return "", nil, nil
+}
+
+// SerializeListen writes the listen section. Lines already in the section keep their position:
+// the mode, binds and servers replace the lines setting them, matched by name, and Lines fill
+// the places of the other directives in order. New binds follow the existing ones and new
+// servers the existing ones, or the end of the section. Options of existing binds and servers
+// which the models do not cover are kept.
+func SerializeListen(p *parser.Parser, data *Listen) error {
+	ondisk, err := getRawLines(p, parser.Listen, data.Name)
+	if err != nil {
+		return err
+	}
+
+	binds := map[string]string{}
+	for _, b := range data.Binds {
+		binds[b.Name] = serializeListenBind(b)
+	}
+	servers := map[string]string{}
+	for _, s := range data.Servers {
+		servers[s.Name] = serializeListenServer(s)
+	}
+
+	result := []string{}
+	modeAt, bindAt, lineAt, serverAt := -1, -1, -1, -1
+	modeDone := data.Mode == ""
+	lines := data.Lines
+	for _, line := range ondisk {
+		words := strings.Fields(line.Value)
+		if len(words) == 0 {
+			continue
+		}
+		mode, b, s := parseListenLine(words)
+		switch {
+		case mode != "":
+			if !modeDone {
+				result = append(result, "mode "+data.Mode)
+				modeAt, modeDone = len(result)-1, true
+			}
+		case b != nil:
+			if value, ok := binds[b.Name]; ok {
+				result = append(result, joinOptions(value, unmodelledBindOptions(words[2:], b)))
+				bindAt = len(result) - 1
+				delete(binds, b.Name)
+			}
+		case s != nil:
+			if value, ok := servers[s.Name]; ok {
+				result = append(result, joinOptions(value, unmodelledServerOptions(words[3:], s)))
+				serverAt = len(result) - 1
+				delete(servers, s.Name)
+			}
+		default:
+			if len(lines) > 0 {
+				result = append(result, strings.TrimSpace(lines[0]))
+				lineAt = len(result) - 1
+				lines = lines[1:]
+			}
+		}
+	}
+
+	// lines not replacing an existing one are inserted after the index
+	inserts := map[int][]string{}
+	if !modeDone {
+		inserts[-1] = append(inserts[-1], "mode "+data.Mode)
+	}
+	if bindAt == -1 {
+		bindAt = modeAt
+	}
+	for _, b := range data.Binds {
+		if value, ok := binds[b.Name]; ok {
+			inserts[bindAt] = append(inserts[bindAt], value)
+		}
+	}
+	if lineAt == -1 {
+		lineAt = bindAt
+	}
+	for _, line := range lines {
+		inserts[lineAt] = append(inserts[lineAt], strings.TrimSpace(line))
+	}
+	if serverAt == -1 {
+		serverAt = len(result) - 1
+	}
+	for _, s := range data.Servers {
+		if value, ok := servers[s.Name]; ok {
+			inserts[serverAt] = append(inserts[serverAt], value)
+		}
+	}
+
+	section := []types.UnProcessed{}
+	for i := -1; i < len(result); i++ {
+		if i >= 0 {
+			section = append(section, types.UnProcessed{Value: result[i]})
+		}
+		for _, value := range inserts[i] {
+			section = append(section, types.UnProcessed{Value: value})
+		}
+	}
+
+	if len(section) == 0 {
+		return p.Set(parser.Listen, data.Name, "", nil)
+	}
+	return p.Set(parser.Listen, data.Name, "", section)
+}
+
+func serializeListenBind(b *models.Bind) string {
+	bind := SerializeBind(*b)
+	return joinOptions("bind "+bind.Path, []string{params.BindOptionsString(bind.Params)})
+}
+
+func serializeListenServer(s *models.Server) string {
+	srv := SerializeServer(*s)
+	return joinOptions("server "+srv.Name+" "+srv.Address, []string{params.ServerOptionsString(srv.Params)})
+}
+
+func joinOptions(line string, options []string) string {
+	for _, o := range options {
+		if o != "" {
+			line += " " + o
+		}
+	}
+	return line
+}
+
+// unmodelledBindOptions returns the options of the bind line which are lost when the bind is
+// written from its model, as written
+func unmodelledBindOptions(words []string, b *models.Bind) []string {
+	parse := func(words []string) []string {
+		options := []string{}
+		for _, o := range params.ParseBindOptions(words) {
+			options = append(options, o.String())
+		}
+		return options
+	}
+	return unmodelledOptions(words, parse, parse(strings.Fields(params.BindOptionsString(SerializeBind(*b).Params))))
+}
+
+// unmodelledServerOptions returns the options of the server line which are lost when the server
+// is written from its model, as written
+func unmodelledServerOptions(words []string, s *models.Server) []string {
+	parse := func(words []string) []string {
+		options := []string{}
+		for _, o := range params.ParseServerOptions(words) {
+			options = append(options, o.String())
+		}
+		return options
+	}
+	return unmodelledOptions(words, parse, parse(strings.Fields(params.ServerOptionsString(SerializeServer(*s).Params))))
+}
+
+// unmodelledOptions splits words into options of one or two words recognized by parse and
+// returns those not in modelled, along with the words parse does not recognize
+func unmodelledOptions(words []string, parse func([]string) []string, modelled []string) []string {
+	known := map[string]bool{}
+	for _, o := range modelled {
+		known[o] = true
+	}
+	result := []string{}
+	for i := 0; i < len(words); i++ {
+		if o := parse(words[i : i+1]); len(o) == 1 && o[0] == words[i] {
+			if !known[o[0]] {
+				result = append(result, words[i])
+			}
+			continue
+		}
+		if i+1 < len(words) {
+			if o := parse(words[i : i+2]); len(o) == 1 && len(strings.Fields(o[0])) == 2 {
+				if !known[o[0]] {
+					result = append(result, words[i], words[i+1])
+				}
+				i++
+				continue
+			}
+		}
+		result = append(result, words[i])
+	}
+	return result
+}
+
+// validateListen checks the listen section like the frontend and backend objects it combines,
+// binds and servers have to be set in their own fields and not as lines
+func (c *Client) validateListen(data *Listen) error {
+	if data.Name == "" || strings.ContainsAny(data.Name, " \t#") {
+		return fmt.Errorf("invalid listen name %s", data.Name)
+	}
+	if data.Mode != "" && data.Mode != "http" && data.Mode != "tcp" {
+		return fmt.Errorf("listen %s: mode must be http or tcp", data.Name)
+	}
+	binds := map[string]bool{}
+	for _, b := range data.Binds {
+		if c.UseValidation {
+			if err := b.Validate(strfmt.Default); err != nil {
+				return err
+			}
+		}
+		if binds[b.Name] {
+			return fmt.Errorf("listen %s: bind %s set more than once", data.Name, b.Name)
+		}
+		binds[b.Name] = true
+		if err := ValidateBindKeywords(b, c.haproxyVersion); err != nil {
+			return err
+		}
+	}
+	names := map[string]bool{}
+	for _, s := range data.Servers {
+		if c.UseValidation {
+			if err := s.Validate(strfmt.Default); err != nil {
+				return err
+			}
+		}
+		if names[s.Name] {
+			return fmt.Errorf("listen %s: server %s set more than once", data.Name, s.Name)
+		}
+		names[s.Name] = true
+		if err := validateServerAgent(s); err != nil {
+			return err
+		}
+		if err := ValidateServerKeywords(s, c.haproxyVersion); err != nil {
+			return err
+		}
+	}
+	for _, line := range data.Lines {
+		words := strings.Fields(line)
+		if len(words) == 0 || strings.Contains(line, "\n") {
+			return fmt.Errorf("listen %s: invalid line %q", data.Name, line)
+		}
+		switch words[0] {
+		case "mode", "bind", "server":
+			return fmt.Errorf("listen %s: %s has to be set with its own field", data.Name, words[0])
+		}
+	}
+	return nil
+}
@@ -0,0 +1,178 @@
+// Copyright 2019 HAProxy Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package configuration
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	parser "github.com/haproxytech/config-parser/v3"
+	"github.com/haproxytech/models/v2"
+
+	"github.com/haproxytech/client-native/v2/misc"
+)
+
+func TestCreateEditDeleteListen(t *testing.T) {
+	l := &Listen{
+		Name: "legacy",
+		Mode: "http",
+		Binds: []*models.Bind{
+			{Name: "legacy_http", Address: "*", Port: misc.Int64P(8080)},
+		},
+		Servers: []*models.Server{
+			{Name: "app1", Address: "10.0.0.20", Port: misc.Int64P(80)},
+			{Name: "app2", Address: "10.0.0.21", Port: misc.Int64P(80)},
+		},
+		Lines: []string{
+			"balance roundrobin",
+			"server app3 10.0.0.22:80",
+		},
+	}
+	if err := client.CreateListen(l, "", version); err == nil {
+		t.Error("Should throw error, servers can not be set as lines")
+		version++
+	}
+
+	l.Lines = nil
+	l.Binds = append(l.Binds, &models.Bind{Name: "legacy_http", Address: "*", Port: misc.Int64P(8081)})
+	if err := client.CreateListen(l, "", version); err == nil {
+		t.Error("Should throw error, bind legacy_http set twice")
+		version++
+	}
+	l.Binds = l.Binds[:1]
+
+	l.Lines = []string{
+		"balance roundrobin",
+		"acl is_admin path_beg /admin",
+		"http-request deny if is_admin",
+	}
+	if err := client.CreateListen(l, "", version); err != nil {
+		t.Fatal(err.Error())
+	}
+	version++
+
+	v, listen, err := client.GetListen("legacy", "")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if v != version {
+		t.Errorf("Version %v returned, expected %v", v, version)
+	}
+	if listen.Mode != l.Mode || !reflect.DeepEqual(listen.Lines, l.Lines) {
+		t.Errorf("Listen %v returned, expected %v", listen, l)
+	}
+	if len(listen.Binds) != 1 || listen.Binds[0].Name != "legacy_http" || *listen.Binds[0].Port != 8080 {
+		t.Errorf("Unexpected binds in listen legacy: %v", listen.Binds)
+	}
+	if len(listen.Servers) != 2 || listen.Servers[1].Name != "app2" || listen.Servers[1].Address != "10.0.0.21" {
+		t.Errorf("Unexpected servers in listen legacy: %v", listen.Servers)
+	}
+
+	l.Mode = "tcp"
+	l.Servers = l.Servers[:1]
+	l.Lines = []string{"balance leastconn"}
+	if err := client.EditListen("legacy", l, "", version); err != nil {
+		t.Error(err.Error())
+	} else {
+		version++
+	}
+	_, listens, err := client.GetListens("")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if len(listens) != 1 || listens[0].Mode != "tcp" || len(listens[0].Servers) != 1 || !reflect.DeepEqual(listens[0].Lines, l.Lines) {
+		t.Errorf("Unexpected listen sections: %v", listens)
+	}
+
+	if err := client.DeleteListen("legacy", "", version); err != nil {
+		t.Error(err.Error())
+	} else {
+		version++
+	}
+	if _, _, err := client.GetListen("legacy", ""); err == nil {
+		t.Error("DeleteListen failed, listen legacy still exists")
+	}
+}
+
+func TestSerializeListenKeepsOrder(t *testing.T) {
+	p := &parser.Parser{}
+	err := p.ParseData(`listen legacy
+  balance roundrobin
+  bind :8080 name http tfo foo-opt 3
+  mode http
+  server app1 10.0.0.1:80 check unknown-x
+  acl is_admin path_beg /admin
+  server app2 10.0.0.2:80
+`)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	l, err := ParseListen(p, "legacy")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	// unchanged sections are written back as they are
+	if err := SerializeListen(p, l); err != nil {
+		t.Fatal(err.Error())
+	}
+	expected := []string{
+		"balance roundrobin",
+		"bind :8080 name http tfo foo-opt 3",
+		"mode http",
+		"server app1 10.0.0.1:80 check unknown-x",
+		"acl is_admin path_beg /admin",
+		"server app2 10.0.0.2:80",
+	}
+	checkListenLines(t, p, expected)
+
+	l.Mode = "tcp"
+	l.Lines = []string{"balance leastconn", "acl is_admin path_beg /admin", "http-request deny if is_admin"}
+	l.Binds = append(l.Binds, &models.Bind{Name: "https", Address: "*", Port: misc.Int64P(8443)})
+	l.Servers = []*models.Server{
+		{Name: "app1", Address: "10.0.0.1", Port: misc.Int64P(8080), Check: "enabled"},
+		{Name: "app3", Address: "10.0.0.3", Port: misc.Int64P(80)},
+	}
+	if err := SerializeListen(p, l); err != nil {
+		t.Fatal(err.Error())
+	}
+	expected = []string{
+		"balance leastconn",
+		"bind :8080 name http tfo foo-opt 3",
+		"bind *:8443 name https",
+		"mode tcp",
+		"server app1 10.0.0.1:8080 check unknown-x",
+		"server app3 10.0.0.3:80",
+		"acl is_admin path_beg /admin",
+		"http-request deny if is_admin",
+	}
+	checkListenLines(t, p, expected)
+}
+
+func checkListenLines(t *testing.T, p *parser.Parser, expected []string) {
+	lines, err := getRawLines(p, parser.Listen, "legacy")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	values := []string{}
+	for _, l := range lines {
+		values = append(values, l.Value)
+	}
+	if !reflect.DeepEqual(values, expected) {
+		t.Errorf("Listen lines:\n%s\nexpected:\n%s", strings.Join(values, "\n"), strings.Join(expected, "\n"))
+	}
+}
@@ -0,0 +1,332 @@
+// Copyright 2019 HAProxy Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package configuration
+
+import (
+	"fmt"
+	"strings"
+
+	parser "github.com/haproxytech/config-parser/v3"
+	parser_errors "github.com/haproxytech/config-parser/v3/errors"
+	"github.com/haproxytech/config-parser/v3/types"
+)
+
+const (
+	// LogFormatDirective is the format of access logs
+	LogFormatDirective = "log-format"
+	// LogFormatSDDirective is the format of the RFC5424 structured-data of access logs
+	LogFormatSDDirective = "log-format-sd"
+	// ErrorLogFormatDirective is the format of connection error logs
+	ErrorLogFormatDirective = "error-log-format"
+)
+
+// LogVar is a log-format variable
+type LogVar string
+
+// Log-format variables, see "Custom log format" in the HAProxy documentation
+const (
+	LogVarClientIP           LogVar = "ci"
+	LogVarClientPort         LogVar = "cp"
+	LogVarFrontendIP         LogVar = "fi"
+	LogVarFrontendPort       LogVar = "fp"
+	LogVarFrontendName       LogVar = "f"
+	LogVarFrontendTransport  LogVar = "ft"
+	LogVarBackendIP          LogVar = "bi"
+	LogVarBackendPort        LogVar = "bp"
+	LogVarBackendName        LogVar = "b"
+	LogVarServerIP           LogVar = "si"
+	LogVarServerPort         LogVar = "sp"
+	LogVarServerName         LogVar = "s"
+	LogVarAcceptDate         LogVar = "t"
+	LogVarRequestDate        LogVar = "tr"
+	LogVarTimestamp          LogVar = "Ts"
+	LogVarMilliseconds       LogVar = "ms"
+	LogVarTimeIdle           LogVar = "Ti"
+	LogVarTimeRequest        LogVar = "TR"
+	LogVarTimeQueue          LogVar = "Tw"
+	LogVarTimeConnect        LogVar = "Tc"
+	LogVarTimeResponse       LogVar = "Tr"
+	LogVarTimeActive         LogVar = "Ta"
+	LogVarTimeTotal          LogVar = "Tt"
+	LogVarTimeHandshake      LogVar = "Th"
+	LogVarStatusCode         LogVar = "ST"
+	LogVarBytesRead          LogVar = "B"
+	LogVarBytesUploaded      LogVar = "U"
+	LogVarCapturedReqCookie  LogVar = "CC"
+	LogVarCapturedResCookie  LogVar = "CS"
+	LogVarTerminationState   LogVar = "ts"
+	LogVarTerminationCookie  LogVar = "tsc"
+	LogVarActiveConns        LogVar = "ac"
+	LogVarFrontendConns      LogVar = "fc"
+	LogVarBackendConns       LogVar = "bc"
+	LogVarServerConns        LogVar = "sc"
+	LogVarRetries            LogVar = "rc"
+	LogVarServerQueue        LogVar = "sq"
+	LogVarBackendQueue       LogVar = "bq"
+	LogVarCapturedReqHeaders LogVar = "hr"
+	LogVarCapturedResHeaders LogVar = "hs"
+	LogVarRequestLine        LogVar = "r"
+	LogVarMethod             LogVar = "HM"
+	LogVarPath               LogVar = "HP"
+	LogVarQuery              LogVar = "HQ"
+	LogVarURI                LogVar = "HU"
+	LogVarHTTPVersion        LogVar = "HV"
+	LogVarUniqueID           LogVar = "ID"
+	LogVarHostname           LogVar = "H"
+	LogVarPID                LogVar = "pid"
+	LogVarSSLCipher          LogVar = "sslc"
+	LogVarSSLVersion         LogVar = "sslv"
+)
+
+// LogFlag is a flag changing how a log-format variable is written
+type LogFlag string
+
+const (
+	// LogFlagQuote writes the value in double quotes
+	LogFlagQuote LogFlag = "Q"
+	// LogFlagHex writes numbers in hexadecimal
+	LogFlagHex LogFlag = "X"
+	// LogFlagEscape escapes characters of the value with a backslash
+	LogFlagEscape LogFlag = "E"
+)
+
+// LogFormatBuilder builds log-format strings from literal text, variables and sample
+// expressions, escaping text so it is written as given
+type LogFormatBuilder struct {
+	parts []string
+	err   error
+}
+
+// NewLogFormatBuilder returns an empty log-format builder
+func NewLogFormatBuilder() *LogFormatBuilder {
+	return &LogFormatBuilder{parts: []string{}}
+}
+
+// Text appends literal text
+func (b *LogFormatBuilder) Text(text string) *LogFormatBuilder {
+	if strings.Contains(text, "#") {
+		b.setErr(fmt.Errorf("log-format text can not contain '#'"))
+		return b
+	}
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "%", "%%")
+	b.parts = append(b.parts, r.Replace(text))
+	return b
+}
+
+// Var appends a log-format variable
+func (b *LogFormatBuilder) Var(v LogVar, flags ...LogFlag) *LogFormatBuilder {
+	if v == "" || strings.ContainsAny(string(v), " \t%{}[]\"#") {
+		b.setErr(fmt.Errorf("invalid log-format variable %s", v))
+		return b
+	}
+	b.parts = append(b.parts, "%"+logFlags(flags)+string(v))
+	return b
+}
+
+// Expr appends the result of a sample expression, for example req.hdr(host),lower
+func (b *LogFormatBuilder) Expr(expr string, flags ...LogFlag) *LogFormatBuilder {
+	if expr == "" || strings.ContainsAny(expr, " \t\"#") || strings.Count(expr, "[") != strings.Count(expr, "]") {
+		b.setErr(fmt.Errorf("invalid log-format expression %s", expr))
+		return b
+	}
+	b.parts = append(b.parts, "%"+logFlags(flags)+"["+expr+"]")
+	return b
+}
+
+// Sample appends the result of the sample expression built with SampleExpr
+func (b *LogFormatBuilder) Sample(e *SampleExpr, flags ...LogFlag) *LogFormatBuilder {
+	expr, err := e.Build()
+	if err != nil {
+		b.setErr(err)
+		return b
+	}
+	return b.Expr(expr, flags...)
+}
+
+// Build returns the log-format string in double quotes, ready to be set as a directive value
+func (b *LogFormatBuilder) Build() (string, error) {
+	if b.err != nil {
+		return "", b.err
+	}
+	if len(b.parts) == 0 {
+		return "", fmt.Errorf("log-format is empty")
+	}
+	format := fmt.Sprintf("\"%s\"", strings.Join(b.parts, ""))
+	if err := ValidateLogFormat(format); err != nil {
+		return "", err
+	}
+	return format, nil
+}
+
+// ValidateLogFormat checks that the log format is kept as given when it is written to and read
+// back from configuration. The parser splits lines on whitespace and joins the words with a single
+// space, so tabs and consecutive spaces are not kept, even in quotes, and '#' starts a comment.
+func ValidateLogFormat(format string) error {
+	if strings.ContainsAny(format, "#\r\n") {
+		return fmt.Errorf("log format can not contain '#' or line breaks")
+	}
+	if strings.Join(strings.Fields(format), " ") != format {
+		return fmt.Errorf("log format can not contain tabs, consecutive spaces or surrounding spaces")
+	}
+	quotes := 0
+	for i := 0; i < len(format); i++ {
+		switch format[i] {
+		case '\\':
+			i++
+		case '"':
+			quotes++
+		}
+	}
+	if quotes%2 != 0 {
+		return fmt.Errorf("log format has unbalanced quotes")
+	}
+	return nil
+}
+
+// validateLogFormats checks the log-format and log-format-sd of a defaults or frontend model
+func validateLogFormats(logFormat string, logFormatSD string) error {
+	for directive, format := range map[string]string{LogFormatDirective: logFormat, LogFormatSDDirective: logFormatSD} {
+		if format == "" {
+			continue
+		}
+		if err := ValidateLogFormat(format); err != nil {
+			return NewConfError(ErrValidationError, fmt.Sprintf("%s: %s", directive, err.Error()))
+		}
+	}
+	return nil
+}
+
+func (b *LogFormatBuilder) setErr(err error) {
+	if b.err == nil {
+		b.err = err
+	}
+}
+
+func logFlags(flags []LogFlag) string {
+	if len(flags) == 0 {
+		return ""
+	}
+	f := make([]string, 0, len(flags))
+	for _, flag := range flags {
+		f = append(f, "+"+string(flag))
+	}
+	return "{" + strings.Join(f, ",") + "}"
+}
+
+// GetLogFormat returns configuration version and the value of the log-format, log-format-sd or
+// error-log-format directive of the defaults or frontend section. Returns error on fail or if
+// the directive is not set.
+func (c *Client) GetLogFormat(directive string, parentType string, parentName string, transactionID string) (int64, string, error) {
+	section, name, err := logFormatSection(directive, parentType, parentName)
+	if err != nil {
+		return 0, "", err
+	}
+
+	p, err := c.GetParser(transactionID)
+	if err != nil {
+		return 0, "", err
+	}
+
+	v, err := c.GetVersion(transactionID)
+	if err != nil {
+		return 0, "", err
+	}
+
+	format, found, err := getLogFormat(p, section, name, directive)
+	if err != nil {
+		return v, "", c.handleError(directive, parentType, parentName, "", false, err)
+	}
+	if !found {
+		return v, "", NewConfError(ErrObjectDoesNotExist, fmt.Sprintf("%s not set in %s %s", directive, parentType, parentName))
+	}
+	return v, format, nil
+}
+
+// SetLogFormat sets the log-format, log-format-sd or error-log-format directive of the defaults or
+// frontend section, an empty format removes the directive. One of version or transactionID is
+// mandatory. Returns error on fail, nil on success.
+func (c *Client) SetLogFormat(directive string, parentType string, parentName string, format string, transactionID string, version int64) error {
+	section, name, err := logFormatSection(directive, parentType, parentName)
+	if err != nil {
+		return err
+	}
+	if format != "" {
+		if err := ValidateLogFormat(format); err != nil {
+			return NewConfError(ErrValidationError, fmt.Sprintf("%s: %s", directive, err.Error()))
+		}
+	}
+
+	if err := c.authorize("SetLogFormat", "log_format", directive, parentType, parentName, transactionID); err != nil {
+		return err
+	}
+
+	p, t, err := c.loadDataForChange(transactionID, version)
+	if err != nil {
+		return err
+	}
+
+	if directive == ErrorLogFormatDirective {
+		var value *string
+		if format != "" {
+			value = &format
+		}
+		err = setRawDirective(p, section, name, directive, value)
+	} else {
+		var value interface{}
+		if format != "" {
+			value = &types.StringC{Value: format}
+		}
+		err = p.Set(section, name, directive, value)
+	}
+	if err != nil {
+		return c.handleError(directive, parentType, parentName, t, transactionID == "", err)
+	}
+
+	if err := c.saveData(p, t, transactionID == ""); err != nil {
+		return err
+	}
+	return nil
+}
+
+func getLogFormat(p *parser.Parser, section parser.Section, name string, directive string) (string, bool, error) {
+	if directive == ErrorLogFormatDirective {
+		return getRawDirective(p, section, name, directive)
+	}
+	data, err := p.Get(section, name, directive, false)
+	if err != nil {
+		if err == parser_errors.ErrFetch {
+			return "", false, nil
+		}
+		return "", false, err
+	}
+	return data.(*types.StringC).Value, true, nil
+}
+
+func logFormatSection(directive string, parentType string, parentName string) (parser.Section, string, error) {
+	switch directive {
+	case LogFormatDirective, LogFormatSDDirective, ErrorLogFormatDirective:
+	default:
+		return "", "", NewConfError(ErrValidationError, fmt.Sprintf("unsupported log format directive %s", directive))
+	}
+	switch parentType {
+	case "defaults":
+		return parser.Defaults, parser.DefaultSectionName, nil
+	case "frontend":
+		return parser.Frontends, parentName, nil
+	default:
+		return "", "", NewConfError(ErrValidationError, fmt.Sprintf("%s is not supported in %s", directive, parentType))
+	}
+}
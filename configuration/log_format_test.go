@@ -0,0 +1,137 @@
+// Copyright 2019 HAProxy Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package configuration
+
+import (
+	"testing"
+)
+
+func TestLogFormatBuilder(t *testing.T) {
+	format, err := NewLogFormatBuilder().
+		Var(LogVarClientIP).Text(":").Var(LogVarClientPort).
+		Text(" 100% \"done\" ").
+		Var(LogVarRequestLine, LogFlagQuote).
+		Text(" ").
+		Expr("req.hdr(host),lower", LogFlagQuote, LogFlagEscape).
+		Build()
+	if err != nil {
+		t.Error(err.Error())
+	}
+	expected := `"%ci:%cp 100%% \"done\" %{+Q}r %{+Q,+E}[req.hdr(host),lower]"`
+	if format != expected {
+		t.Errorf("Log format %s, expected %s", format, expected)
+	}
+
+	_, err = NewLogFormatBuilder().Text("# comment").Build()
+	if err == nil {
+		t.Error("Should throw error, text contains #")
+	}
+
+	_, err = NewLogFormatBuilder().Expr("req.hdr(host").Expr("var(txn.a]").Build()
+	if err == nil {
+		t.Error("Should throw error, invalid expression")
+	}
+
+	_, err = NewLogFormatBuilder().Var(LogVarClientIP).Text("  ").Var(LogVarClientPort).Build()
+	if err == nil {
+		t.Error("Should throw error, consecutive spaces are not kept in configuration")
+	}
+}
+
+func TestSetGetLogFormat(t *testing.T) {
+	format, _ := NewLogFormatBuilder().Var(LogVarClientIP).Text(" ").Var(LogVarStatusCode).Build()
+	errorFormat, _ := NewLogFormatBuilder().Var(LogVarClientIP).Text(" ").Var(LogVarTerminationState).Build()
+
+	for directive, f := range map[string]string{LogFormatDirective: format, ErrorLogFormatDirective: errorFormat} {
+		err := client.SetLogFormat(directive, "frontend", "test_2", f, "", version)
+		if err != nil {
+			t.Error(err.Error())
+		} else {
+			version++
+		}
+
+		v, got, err := client.GetLogFormat(directive, "frontend", "test_2", "")
+		if err != nil {
+			t.Error(err.Error())
+		} else if got != f {
+			t.Errorf("%s %s, expected %s", directive, got, f)
+		}
+
+		if v != version {
+			t.Errorf("Version %v returned, expected %v", v, version)
+		}
+
+		err = client.SetLogFormat(directive, "frontend", "test_2", "", "", version)
+		if err != nil {
+			t.Error(err.Error())
+		} else {
+			version++
+		}
+
+		_, _, err = client.GetLogFormat(directive, "frontend", "test_2", "")
+		if err == nil {
+			t.Errorf("SetLogFormat failed, %s still set", directive)
+		}
+	}
+
+	err := client.SetLogFormat(LogFormatDirective, "backend", "test", format, "", version)
+	if err == nil {
+		t.Error("Should throw error, log-format is not supported in backends")
+		version++
+	}
+	err = client.SetLogFormat(LogFormatDirective, "frontend", "test_2", "\"%ci\t%cp\"", "", version)
+	if err == nil {
+		t.Error("Should throw error, tabs are not kept in configuration")
+		version++
+	}
+}
+
+func TestEditFrontendLogFormat(t *testing.T) {
+	_, f, err := client.GetFrontend("test_2", "")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	logFormat, logFormatSD, logTag := f.LogFormat, f.LogFormatSd, f.LogTag
+
+	f.LogFormat = `"%ci:%cp [%tr] %ft \"%r\""`
+	f.LogTag = "edge-01"
+	f.LogFormatSd = `"[exampleSDID@1234 bytes=\"%B\"]   "`
+	if err := client.EditFrontend("test_2", f, "", version); err == nil {
+		t.Error("Should throw error, log-format-sd spaces are not kept in configuration")
+		version++
+	}
+	f.LogFormatSd = `"[exampleSDID@1234 bytes=\"%B\" status=\"%ST\"]"`
+	if err := client.EditFrontend("test_2", f, "", version); err != nil {
+		t.Fatal(err.Error())
+	}
+	version++
+
+	_, got, err := client.GetFrontend("test_2", "")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if got.LogFormat != f.LogFormat || got.LogFormatSd != f.LogFormatSd || got.LogTag != f.LogTag {
+		t.Errorf("Log format %s, log-format-sd %s and log-tag %s returned, expected %s, %s and %s",
+			got.LogFormat, got.LogFormatSd, got.LogTag, f.LogFormat, f.LogFormatSd, f.LogTag)
+	}
+
+	f.LogFormat, f.LogFormatSd, f.LogTag = logFormat, logFormatSD, logTag
+	if err := client.EditFrontend("test_2", f, "", version); err != nil {
+		t.Error(err.Error())
+	} else {
+		version++
+	}
+}
@@ -0,0 +1,182 @@
+// Copyright 2019 HAProxy Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package configuration
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// JSONLogField is a field of the JSON access log preset
+type JSONLogField struct {
+	// Name of the JSON key
+	Name string
+	// Var written as the value
+	Var LogVar
+	// String values are written quoted and escaped
+	String bool
+	// MinVersion is the first HAProxy version providing the variable
+	MinVersion string
+}
+
+// JSONLogFields are the fields available in the JSON access log preset, in the order they are written
+var JSONLogFields = []JSONLogField{
+	{Name: "timestamp", Var: LogVarTimestamp, MinVersion: "1.6"},
+	{Name: "accept_date", Var: LogVarAcceptDate, String: true, MinVersion: "1.5"},
+	{Name: "request_date", Var: LogVarRequestDate, String: true, MinVersion: "1.8"},
+	{Name: "client_ip", Var: LogVarClientIP, String: true, MinVersion: "1.5"},
+	{Name: "client_port", Var: LogVarClientPort, MinVersion: "1.5"},
+	{Name: "frontend_ip", Var: LogVarFrontendIP, String: true, MinVersion: "1.5"},
+	{Name: "frontend_port", Var: LogVarFrontendPort, MinVersion: "1.5"},
+	{Name: "frontend", Var: LogVarFrontendName, String: true, MinVersion: "1.5"},
+	{Name: "backend", Var: LogVarBackendName, String: true, MinVersion: "1.5"},
+	{Name: "server", Var: LogVarServerName, String: true, MinVersion: "1.5"},
+	{Name: "method", Var: LogVarMethod, String: true, MinVersion: "1.7"},
+	{Name: "uri", Var: LogVarURI, String: true, MinVersion: "1.7"},
+	{Name: "path", Var: LogVarPath, String: true, MinVersion: "1.7"},
+	{Name: "query", Var: LogVarQuery, String: true, MinVersion: "1.8"},
+	{Name: "http_version", Var: LogVarHTTPVersion, String: true, MinVersion: "1.7"},
+	{Name: "status", Var: LogVarStatusCode, MinVersion: "1.5"},
+	{Name: "bytes_read", Var: LogVarBytesRead, MinVersion: "1.5"},
+	{Name: "bytes_uploaded", Var: LogVarBytesUploaded, MinVersion: "1.5"},
+	{Name: "time_handshake", Var: LogVarTimeHandshake, MinVersion: "1.7"},
+	{Name: "time_idle", Var: LogVarTimeIdle, MinVersion: "1.7"},
+	{Name: "time_request", Var: LogVarTimeRequest, MinVersion: "1.7"},
+	{Name: "time_queue", Var: LogVarTimeQueue, MinVersion: "1.5"},
+	{Name: "time_connect", Var: LogVarTimeConnect, MinVersion: "1.5"},
+	{Name: "time_response", Var: LogVarTimeResponse, MinVersion: "1.5"},
+	{Name: "time_active", Var: LogVarTimeActive, MinVersion: "1.7"},
+	{Name: "time_total", Var: LogVarTimeTotal, MinVersion: "1.5"},
+	{Name: "termination_state", Var: LogVarTerminationState, String: true, MinVersion: "1.5"},
+	{Name: "active_conns", Var: LogVarActiveConns, MinVersion: "1.5"},
+	{Name: "frontend_conns", Var: LogVarFrontendConns, MinVersion: "1.5"},
+	{Name: "backend_conns", Var: LogVarBackendConns, MinVersion: "1.5"},
+	{Name: "server_conns", Var: LogVarServerConns, MinVersion: "1.5"},
+	{Name: "retries", Var: LogVarRetries, MinVersion: "1.5"},
+	{Name: "server_queue", Var: LogVarServerQueue, MinVersion: "1.5"},
+	{Name: "backend_queue", Var: LogVarBackendQueue, MinVersion: "1.5"},
+	{Name: "unique_id", Var: LogVarUniqueID, String: true, MinVersion: "1.5"},
+	{Name: "ssl_version", Var: LogVarSSLVersion, String: true, MinVersion: "1.5"},
+	{Name: "ssl_cipher", Var: LogVarSSLCipher, String: true, MinVersion: "1.5"},
+}
+
+// DefaultJSONLogFields are the fields of the JSON access log preset used when none are selected
+var DefaultJSONLogFields = []string{
+	"timestamp", "client_ip", "client_port", "frontend", "backend", "server", "method", "uri",
+	"http_version", "status", "bytes_read", "time_request", "time_queue", "time_connect",
+	"time_response", "time_active", "time_total", "termination_state", "retries",
+}
+
+// escapeFlagVersion is the first HAProxy version supporting the E log-format flag
+const escapeFlagVersion = "1.8"
+
+// JSONLogFormat returns the log-format writing the selected fields as one JSON object, in the
+// order of JSONLogFields. Fields whose variable is not available in haproxyVersion are left out,
+// an empty version selects all fields. Returns error on unknown fields.
+func JSONLogFormat(fields []string, haproxyVersion string) (string, error) {
+	if len(fields) == 0 {
+		fields = DefaultJSONLogFields
+	}
+	for _, f := range fields {
+		known := false
+		for _, jf := range JSONLogFields {
+			if jf.Name == f {
+				known = true
+				break
+			}
+		}
+		if !known {
+			return "", fmt.Errorf("unknown JSON log field %s", f)
+		}
+	}
+
+	stringFlags := []LogFlag{LogFlagQuote, LogFlagEscape}
+	if !versionAtLeast(haproxyVersion, escapeFlagVersion) {
+		stringFlags = []LogFlag{LogFlagQuote}
+	}
+
+	b := NewLogFormatBuilder()
+	sep := "{"
+	for _, jf := range JSONLogFields {
+		if !stringSelected(jf.Name, fields) || !versionAtLeast(haproxyVersion, jf.MinVersion) {
+			continue
+		}
+		b.Text(fmt.Sprintf("%s\"%s\":", sep, jf.Name))
+		if jf.String {
+			b.Var(jf.Var, stringFlags...)
+		} else {
+			b.Var(jf.Var)
+		}
+		sep = ","
+	}
+	if sep == "{" {
+		return "", fmt.Errorf("no JSON log field available in HAProxy %s", haproxyVersion)
+	}
+	b.Text("}")
+	return b.Build()
+}
+
+// ApplyJSONLogFormat sets the log-format of the defaults or frontend section to the JSON access
+// log preset with the selected fields available in haproxyVersion. Applying it again after an
+// HAProxy upgrade adds the fields the new version provides. One of version or transactionID is
+// mandatory. Returns error on fail, nil on success.
+func (c *Client) ApplyJSONLogFormat(parentType string, parentName string, fields []string, haproxyVersion string, transactionID string, version int64) error {
+	format, err := JSONLogFormat(fields, haproxyVersion)
+	if err != nil {
+		return NewConfError(ErrValidationError, err.Error())
+	}
+	return c.SetLogFormat(LogFormatDirective, parentType, parentName, format, transactionID, version)
+}
+
+func stringSelected(s string, list []string) bool {
+	for _, l := range list {
+		if l == s {
+			return true
+		}
+	}
+	return false
+}
+
+// versionAtLeast returns true if the major.minor HAProxy version is at least min, an empty or
+// unparsable version is treated as the latest one
+func versionAtLeast(version string, min string) bool {
+	v, ok := parseMajorMinor(version)
+	if !ok {
+		return true
+	}
+	m, _ := parseMajorMinor(min)
+	if v[0] != m[0] {
+		return v[0] > m[0]
+	}
+	return v[1] >= m[1]
+}
+
+func parseMajorMinor(version string) ([2]int, bool) {
+	parts := strings.SplitN(strings.TrimSpace(version), ".", 3)
+	if len(parts) < 2 {
+		return [2]int{}, false
+	}
+	major, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return [2]int{}, false
+	}
+	minor, err := strconv.Atoi(strings.TrimRightFunc(parts[1], func(r rune) bool { return r < '0' || r > '9' }))
+	if err != nil {
+		return [2]int{}, false
+	}
+	return [2]int{major, minor}, true
+}
@@ -0,0 +1,71 @@
+// Copyright 2019 HAProxy Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package configuration
+
+import (
+	"testing"
+)
+
+func TestJSONLogFormat(t *testing.T) {
+	fields := []string{"status", "client_ip", "time_active"}
+
+	format, err := JSONLogFormat(fields, "2.2.4")
+	if err != nil {
+		t.Error(err.Error())
+	}
+	expected := `"{\"client_ip\":%{+Q,+E}ci,\"status\":%ST,\"time_active\":%Ta}"`
+	if format != expected {
+		t.Errorf("Log format %s, expected %s", format, expected)
+	}
+
+	format, err = JSONLogFormat(fields, "1.6")
+	if err != nil {
+		t.Error(err.Error())
+	}
+	expected = `"{\"client_ip\":%{+Q}ci,\"status\":%ST}"`
+	if format != expected {
+		t.Errorf("Log format %s, expected %s", format, expected)
+	}
+
+	_, err = JSONLogFormat([]string{"client_ip", "unknown"}, "")
+	if err == nil {
+		t.Error("Should throw error, unknown field")
+	}
+}
+
+func TestApplyJSONLogFormat(t *testing.T) {
+	err := client.ApplyJSONLogFormat("frontend", "test_2", nil, "2.2", "", version)
+	if err != nil {
+		t.Error(err.Error())
+	} else {
+		version++
+	}
+
+	expected, _ := JSONLogFormat(DefaultJSONLogFields, "2.2")
+	_, format, err := client.GetLogFormat(LogFormatDirective, "frontend", "test_2", "")
+	if err != nil {
+		t.Error(err.Error())
+	} else if format != expected {
+		t.Errorf("Log format %s, expected %s", format, expected)
+	}
+
+	err = client.SetLogFormat(LogFormatDirective, "frontend", "test_2", "", "", version)
+	if err != nil {
+		t.Error(err.Error())
+	} else {
+		version++
+	}
+}
@@ -80,6 +80,10 @@ func (c *Client) GetLogTarget(id int64, parentType, parentName string, transacti
 // DeleteLogTarget deletes a log target in configuration. One of version or transactionID is
 // mandatory. Returns error on fail, nil on success.
 func (c *Client) DeleteLogTarget(id int64, parentType string, parentName string, transactionID string, version int64) error {
+	if err := c.authorize("DeleteLogTarget", "log_target", authorizedIndex(id), parentType, parentName, transactionID); err != nil {
+		return err
+	}
+
 	p, t, err := c.loadDataForChange(transactionID, version)
 	if err != nil {
 		return err
@@ -113,6 +117,10 @@ func (c *Client) CreateLogTarget(parentType string, parentName string, data *mod
 		}
 	}
 
+	if err := c.authorize("CreateLogTarget", "log_target", authorizedIndexP(data.Index), parentType, parentName, transactionID); err != nil {
+		return err
+	}
+
 	p, t, err := c.loadDataForChange(transactionID, version)
 	if err != nil {
 		return err
@@ -144,6 +152,10 @@ func (c *Client) EditLogTarget(id int64, parentType string, parentName string, d
 			return NewConfError(ErrValidationError, validationErr.Error())
 		}
 	}
+	if err := c.authorize("EditLogTarget", "log_target", authorizedIndex(id), parentType, parentName, transactionID); err != nil {
+		return err
+	}
+
 	p, t, err := c.loadDataForChange(transactionID, version)
 	if err != nil {
 		return err
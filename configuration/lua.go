@@ -0,0 +1,160 @@
+// Copyright 2019 HAProxy Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package configuration
+
+import (
+	"fmt"
+	"strings"
+
+	parser "github.com/haproxytech/config-parser/v3"
+)
+
+// GlobalLua holds the Lua keywords of the global section not covered by models.Global, lua-load
+// files are part of models.Global and tune.lua.* keywords of GlobalTuning
+type GlobalLua struct {
+	PrependPaths []*LuaPrependPath `json:"prepend_paths,omitempty"`
+	// LoadPerThread are the files loaded by each thread in its own Lua state
+	LoadPerThread []string `json:"load_per_thread,omitempty"`
+}
+
+// LuaPrependPath is a pattern prepended to the Lua package.path, or package.cpath when Type is
+// cpath
+type LuaPrependPath struct {
+	Path string `json:"path"`
+	Type string `json:"type,omitempty"`
+}
+
+// Validate checks the paths and files of the Lua keywords
+func (l *GlobalLua) Validate() error {
+	for _, p := range l.PrependPaths {
+		if p == nil || p.Path == "" || strings.ContainsAny(p.Path, " \t#") {
+			return fmt.Errorf("invalid lua-prepend-path")
+		}
+		if p.Type != "" && p.Type != "path" && p.Type != "cpath" {
+			return fmt.Errorf("lua-prepend-path %s: type must be path or cpath", p.Path)
+		}
+	}
+	for _, f := range l.LoadPerThread {
+		if f == "" || strings.ContainsAny(f, " \t#") {
+			return fmt.Errorf("invalid lua-load-per-thread file %s", f)
+		}
+	}
+	return nil
+}
+
+// GetGlobalLua returns configuration version and the Lua keywords of the global section.
+// Returns error on fail.
+func (c *Client) GetGlobalLua(transactionID string) (int64, *GlobalLua, error) {
+	p, err := c.GetParser(transactionID)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	v, err := c.GetVersion(transactionID)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	l, err := parseGlobalLua(p)
+	if err != nil {
+		return 0, nil, err
+	}
+	return v, l, nil
+}
+
+// PushGlobalLua replaces the lua-prepend-path and lua-load-per-thread keywords of the global
+// section. One of version or transactionID is mandatory. Returns error on fail, nil on success.
+func (c *Client) PushGlobalLua(data *GlobalLua, transactionID string, version int64) error {
+	if err := data.Validate(); err != nil {
+		return NewConfError(ErrValidationError, err.Error())
+	}
+
+	if err := c.authorize("PushGlobalLua", "lua", "", "global", "", transactionID); err != nil {
+		return err
+	}
+
+	p, t, err := c.loadDataForChange(transactionID, version)
+	if err != nil {
+		return err
+	}
+
+	paths := make([]string, 0, len(data.PrependPaths))
+	for _, path := range data.PrependPaths {
+		paths = append(paths, strings.TrimSpace(path.Path+" "+path.Type))
+	}
+	if err := setGlobalLuaLines(p, "lua-prepend-path", paths); err != nil {
+		return c.handleError("", "global", "", t, transactionID == "", err)
+	}
+	if err := setGlobalLuaLines(p, "lua-load-per-thread", data.LoadPerThread); err != nil {
+		return c.handleError("", "global", "", t, transactionID == "", err)
+	}
+
+	if err := c.saveData(p, t, transactionID == ""); err != nil {
+		return err
+	}
+	return nil
+}
+
+func parseGlobalLua(p *parser.Parser) (*GlobalLua, error) {
+	l := &GlobalLua{}
+
+	lines, err := getRawRules(p, parser.Global, parser.GlobalSectionName, "lua-prepend-path")
+	if err != nil {
+		return nil, err
+	}
+	for _, line := range lines {
+		value, _ := matchRawDirective(line, "lua-prepend-path")
+		words := strings.Fields(value)
+		if len(words) == 0 {
+			continue
+		}
+		path := &LuaPrependPath{Path: words[0]}
+		if len(words) > 1 {
+			path.Type = words[1]
+		}
+		l.PrependPaths = append(l.PrependPaths, path)
+	}
+
+	if lines, err = getRawRules(p, parser.Global, parser.GlobalSectionName, "lua-load-per-thread"); err != nil {
+		return nil, err
+	}
+	for _, line := range lines {
+		if value, _ := matchRawDirective(line, "lua-load-per-thread"); value != "" {
+			l.LoadPerThread = append(l.LoadPerThread, value)
+		}
+	}
+	return l, nil
+}
+
+// setGlobalLuaLines replaces the lines of the keyword with one line for each value, in order
+func setGlobalLuaLines(p *parser.Parser, keyword string, values []string) error {
+	lines, err := getRawRules(p, parser.Global, parser.GlobalSectionName, keyword)
+	if err != nil {
+		return err
+	}
+	for range lines {
+		if err := setRawRule(p, parser.Global, parser.GlobalSectionName, keyword, 0, nil, false); err != nil {
+			return err
+		}
+	}
+	for i, value := range values {
+		line := rawDirectiveLine(keyword, value)
+		if err := setRawRule(p, parser.Global, parser.GlobalSectionName, keyword, i, &line, true); err != nil {
+			return err
+		}
+	}
+	return nil
+}
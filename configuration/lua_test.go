@@ -0,0 +1,66 @@
+// Copyright 2019 HAProxy Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package configuration
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPushGlobalLua(t *testing.T) {
+	l := &GlobalLua{
+		PrependPaths: []*LuaPrependPath{
+			&LuaPrependPath{Path: "/usr/share/haproxy/lua/?.lua"},
+			&LuaPrependPath{Path: "/usr/share/haproxy/lua/?.so", Type: "cpath"},
+		},
+		LoadPerThread: []string{"/etc/haproxy/auth.lua"},
+	}
+	l.PrependPaths[1].Type = "lib"
+	if err := client.PushGlobalLua(l, "", version); err == nil {
+		t.Error("Should throw error, lua-prepend-path type must be path or cpath")
+		version++
+	}
+	l.PrependPaths[1].Type = "cpath"
+
+	if err := client.PushGlobalLua(l, "", version); err != nil {
+		t.Fatal(err.Error())
+	}
+	version++
+
+	v, lua, err := client.GetGlobalLua("")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if !reflect.DeepEqual(lua, l) {
+		t.Errorf("Global Lua %v returned, expected %v", lua, l)
+	}
+	if v != version {
+		t.Errorf("Version %v returned, expected %v", v, version)
+	}
+
+	if err := client.PushGlobalLua(&GlobalLua{}, "", version); err != nil {
+		t.Fatal(err.Error())
+	}
+	version++
+
+	_, lua, err = client.GetGlobalLua("")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if !reflect.DeepEqual(lua, &GlobalLua{}) {
+		t.Errorf("Global Lua %v returned, expected no Lua keywords", lua)
+	}
+}
@@ -0,0 +1,212 @@
+// Copyright 2019 HAProxy Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package configuration
+
+import (
+	"fmt"
+
+	parser "github.com/haproxytech/config-parser/v3"
+	parser_errors "github.com/haproxytech/config-parser/v3/errors"
+	"github.com/haproxytech/config-parser/v3/types"
+)
+
+// MailerEntry is a mailer of a mailers section, an SMTP server
+type MailerEntry struct {
+	Name    string  `json:"name"`
+	Address *string `json:"address"`
+	Port    *int64  `json:"port"`
+}
+
+// GetMailerEntries returns configuration version and an array of
+// configured mailers in the specified mailers section. Returns error on fail.
+func (c *Client) GetMailerEntries(mailersSection string, transactionID string) (int64, []*MailerEntry, error) {
+	p, err := c.GetParser(transactionID)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	v, err := c.GetVersion(transactionID)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	mailerEntries, err := ParseMailerEntries(mailersSection, p)
+	if err != nil {
+		return v, nil, c.handleError("", "mailers", mailersSection, "", false, err)
+	}
+
+	return v, mailerEntries, nil
+}
+
+// GetMailerEntry returns configuration version and a requested mailer
+// in the specified mailers section. Returns error on fail or if mailer does not exist.
+func (c *Client) GetMailerEntry(name string, mailersSection string, transactionID string) (int64, *MailerEntry, error) {
+	p, err := c.GetParser(transactionID)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	v, err := c.GetVersion(transactionID)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	mailerEntry, _ := GetMailerEntryByName(name, mailersSection, p)
+	if mailerEntry == nil {
+		return v, nil, NewConfError(ErrObjectDoesNotExist, fmt.Sprintf("MailerEntry %s does not exist in mailers section %s", name, mailersSection))
+	}
+
+	return v, mailerEntry, nil
+}
+
+// DeleteMailerEntry deletes a mailer in configuration. One of version or transactionID is
+// mandatory. Returns error on fail, nil on success.
+func (c *Client) DeleteMailerEntry(name string, mailersSection string, transactionID string, version int64) error {
+	if err := c.authorize("DeleteMailerEntry", "mailer_entry", name, "mailers", mailersSection, transactionID); err != nil {
+		return err
+	}
+
+	p, t, err := c.loadDataForChange(transactionID, version)
+	if err != nil {
+		return err
+	}
+
+	mailerEntry, i := GetMailerEntryByName(name, mailersSection, p)
+	if mailerEntry == nil {
+		e := NewConfError(ErrObjectDoesNotExist, fmt.Sprintf("MailerEntry %s does not exist in mailers section %s", name, mailersSection))
+		return c.handleError(name, "mailers", mailersSection, t, transactionID == "", e)
+	}
+
+	if err := p.Delete(parser.Mailers, mailersSection, "mailer", i); err != nil {
+		return c.handleError(name, "mailers", mailersSection, t, transactionID == "", err)
+	}
+
+	if err := c.saveData(p, t, transactionID == ""); err != nil {
+		return err
+	}
+	return nil
+}
+
+// CreateMailerEntry creates a mailer in configuration. One of version or transactionID is
+// mandatory. Returns error on fail, nil on success.
+func (c *Client) CreateMailerEntry(mailersSection string, data *MailerEntry, transactionID string, version int64) error {
+	if err := validateAddressPort("mailer", data.Name, data.Address, data.Port); err != nil {
+		return NewConfError(ErrValidationError, err.Error())
+	}
+	if err := c.authorize("CreateMailerEntry", "mailer_entry", data.Name, "mailers", mailersSection, transactionID); err != nil {
+		return err
+	}
+
+	p, t, err := c.loadDataForChange(transactionID, version)
+	if err != nil {
+		return err
+	}
+
+	mailerEntry, _ := GetMailerEntryByName(data.Name, mailersSection, p)
+	if mailerEntry != nil {
+		e := NewConfError(ErrObjectAlreadyExists, fmt.Sprintf("MailerEntry %s already exists in mailers section %s", data.Name, mailersSection))
+		return c.handleError(data.Name, "mailers", mailersSection, t, transactionID == "", e)
+	}
+
+	if err := p.Insert(parser.Mailers, mailersSection, "mailer", SerializeMailerEntry(*data), -1); err != nil {
+		return c.handleError(data.Name, "mailers", mailersSection, t, transactionID == "", err)
+	}
+
+	if err := c.saveData(p, t, transactionID == ""); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// EditMailerEntry edits a mailer in configuration. One of version or transactionID is
+// mandatory. Returns error on fail, nil on success.
+func (c *Client) EditMailerEntry(name string, mailersSection string, data *MailerEntry, transactionID string, version int64) error {
+	if err := validateAddressPort("mailer", data.Name, data.Address, data.Port); err != nil {
+		return NewConfError(ErrValidationError, err.Error())
+	}
+	if err := c.authorize("EditMailerEntry", "mailer_entry", name, "mailers", mailersSection, transactionID); err != nil {
+		return err
+	}
+
+	p, t, err := c.loadDataForChange(transactionID, version)
+	if err != nil {
+		return err
+	}
+
+	mailerEntry, i := GetMailerEntryByName(name, mailersSection, p)
+	if mailerEntry == nil {
+		e := NewConfError(ErrObjectDoesNotExist, fmt.Sprintf("MailerEntry %v does not exist in mailers section %s", name, mailersSection))
+		return c.handleError(data.Name, "mailers", mailersSection, t, transactionID == "", e)
+	}
+
+	if err := p.Set(parser.Mailers, mailersSection, "mailer", SerializeMailerEntry(*data), i); err != nil {
+		return c.handleError(data.Name, "mailers", mailersSection, t, transactionID == "", err)
+	}
+
+	if err := c.saveData(p, t, transactionID == ""); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func ParseMailerEntries(mailersSection string, p *parser.Parser) ([]*MailerEntry, error) {
+	mailerEntries := []*MailerEntry{}
+
+	data, err := p.Get(parser.Mailers, mailersSection, "mailer", false)
+	if err != nil {
+		if err == parser_errors.ErrFetch {
+			return mailerEntries, nil
+		}
+		return nil, err
+	}
+
+	for _, e := range data.([]types.Mailer) {
+		mailerEntries = append(mailerEntries, ParseMailerEntry(e))
+	}
+	return mailerEntries, nil
+}
+
+func ParseMailerEntry(m types.Mailer) *MailerEntry {
+	return &MailerEntry{
+		Name:    m.Name,
+		Address: &m.IP,
+		Port:    &m.Port,
+	}
+}
+
+func SerializeMailerEntry(me MailerEntry) types.Mailer {
+	return types.Mailer{
+		Name: me.Name,
+		IP:   *me.Address,
+		Port: *me.Port,
+	}
+}
+
+func GetMailerEntryByName(name string, mailersSection string, p *parser.Parser) (*MailerEntry, int) {
+	mailerEntries, err := ParseMailerEntries(mailersSection, p)
+	if err != nil {
+		return nil, 0
+	}
+
+	for i, m := range mailerEntries {
+		if m.Name == name {
+			return m, i
+		}
+	}
+	return nil, 0
+}
@@ -0,0 +1,95 @@
+// Copyright 2019 HAProxy Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package configuration
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCreateEditDeleteMailerEntry(t *testing.T) {
+	err := client.CreateMailersSection(&MailersSection{Name: "smtp"}, "", version)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	version++
+
+	address := "192.168.0.1"
+	port := int64(587)
+	e := &MailerEntry{
+		Name:    "smtp1",
+		Address: &address,
+		Port:    &port,
+	}
+	if err := client.CreateMailerEntry("smtp", e, "", version); err != nil {
+		t.Error(err.Error())
+	} else {
+		version++
+	}
+
+	v, m, err := client.GetMailerEntry("smtp1", "smtp", "")
+	if err != nil {
+		t.Error(err.Error())
+	} else if !reflect.DeepEqual(m, e) {
+		t.Errorf("Mailer %v returned, expected %v", m, e)
+	}
+	if v != version {
+		t.Errorf("Version %v returned, expected %v", v, version)
+	}
+
+	if err := client.CreateMailerEntry("smtp", e, "", version); err == nil {
+		t.Error("Should throw error mailer already exists")
+		version++
+	}
+	if err := client.CreateMailerEntry("nonexistent", e, "", version); err == nil {
+		t.Error("Should throw error, mailers section does not exist")
+		version++
+	}
+
+	invalid := int64(0)
+	if err := client.EditMailerEntry("smtp1", "smtp", &MailerEntry{Name: "smtp1", Address: &address, Port: &invalid}, "", version); err == nil {
+		t.Error("Should throw error, invalid port")
+		version++
+	}
+
+	port = 25
+	if err := client.EditMailerEntry("smtp1", "smtp", e, "", version); err != nil {
+		t.Error(err.Error())
+	} else {
+		version++
+	}
+	_, mailers, err := client.GetMailerEntries("smtp", "")
+	if err != nil {
+		t.Error(err.Error())
+	} else if len(mailers) != 1 || *mailers[0].Port != 25 {
+		t.Errorf("Unexpected mailers: %v", mailers)
+	}
+
+	if err := client.DeleteMailerEntry("smtp1", "smtp", "", version); err != nil {
+		t.Error(err.Error())
+	} else {
+		version++
+	}
+	if _, _, err := client.GetMailerEntry("smtp1", "smtp", ""); err == nil {
+		t.Error("DeleteMailerEntry failed, mailer still exists")
+	}
+
+	if err := client.DeleteMailersSection("smtp", "", version); err != nil {
+		t.Error(err.Error())
+	} else {
+		version++
+	}
+}
@@ -0,0 +1,239 @@
+// Copyright 2019 HAProxy Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package configuration
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	parser "github.com/haproxytech/config-parser/v3"
+	parser_errors "github.com/haproxytech/config-parser/v3/errors"
+	"github.com/haproxytech/config-parser/v3/types"
+
+	"github.com/haproxytech/client-native/v2/misc"
+)
+
+// MailersSection is a mailers section, the SMTP servers email alerts are sent through
+type MailersSection struct {
+	Name string `json:"name"`
+	// Timeout is the time to send an email alert in milliseconds, timeout mail
+	Timeout *int64 `json:"timeout,omitempty"`
+}
+
+// GetMailersSections returns configuration version and an array of
+// configured mailers sections. Returns error on fail.
+func (c *Client) GetMailersSections(transactionID string) (int64, []*MailersSection, error) {
+	p, err := c.GetParser(transactionID)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	v, err := c.GetVersion(transactionID)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	names, err := p.SectionsGet(parser.Mailers)
+	if err != nil {
+		return v, nil, err
+	}
+
+	mailersSections := []*MailersSection{}
+	for _, name := range names {
+		ms, err := ParseMailersSection(p, name)
+		if err != nil {
+			return v, nil, c.handleError(name, "", "", "", false, err)
+		}
+		mailersSections = append(mailersSections, ms)
+	}
+
+	return v, mailersSections, nil
+}
+
+// GetMailersSection returns configuration version and a requested mailers section.
+// Returns error on fail or if mailers section does not exist.
+func (c *Client) GetMailersSection(name string, transactionID string) (int64, *MailersSection, error) {
+	p, err := c.GetParser(transactionID)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	v, err := c.GetVersion(transactionID)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	if !c.checkSectionExists(parser.Mailers, name, p) {
+		return v, nil, NewConfError(ErrObjectDoesNotExist, fmt.Sprintf("MailersSection %s does not exist", name))
+	}
+
+	ms, err := ParseMailersSection(p, name)
+	if err != nil {
+		return v, nil, c.handleError(name, "", "", "", false, err)
+	}
+
+	return v, ms, nil
+}
+
+// DeleteMailersSection deletes a mailers section in configuration. Mailers sections email alerts
+// are sent through can not be deleted. One of version or transactionID is mandatory. Returns
+// error on fail, nil on success.
+func (c *Client) DeleteMailersSection(name string, transactionID string, version int64) error {
+	if err := c.authorize("DeleteMailersSection", "mailers", name, "", "", transactionID); err != nil {
+		return err
+	}
+
+	p, t, err := c.loadDataForChange(transactionID, version)
+	if err != nil {
+		return err
+	}
+
+	if !c.checkSectionExists(parser.Mailers, name, p) {
+		e := NewConfError(ErrObjectDoesNotExist, fmt.Sprintf("%s %s does not exist", parser.Mailers, name))
+		return c.handleError(name, "", "", t, transactionID == "", e)
+	}
+
+	if users := mailersSectionUsers(p, name); len(users) > 0 {
+		e := NewConfError(ErrValidationError, fmt.Sprintf("%s %s is used by email-alert of %s", parser.Mailers, name, strings.Join(users, ", ")))
+		return c.handleError(name, "", "", t, transactionID == "", e)
+	}
+
+	if err := p.SectionsDelete(parser.Mailers, name); err != nil {
+		return c.handleError(name, "", "", t, transactionID == "", err)
+	}
+
+	if err := c.saveData(p, t, transactionID == ""); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// CreateMailersSection creates a mailers section in configuration. One of version or
+// transactionID is mandatory. Returns error on fail, nil on success.
+func (c *Client) CreateMailersSection(data *MailersSection, transactionID string, version int64) error {
+	if err := validateMailersSection(data); err != nil {
+		return NewConfError(ErrValidationError, err.Error())
+	}
+
+	if err := c.authorize("CreateMailersSection", "mailers", data.Name, "", "", transactionID); err != nil {
+		return err
+	}
+
+	p, t, err := c.loadDataForChange(transactionID, version)
+	if err != nil {
+		return err
+	}
+
+	if err := p.SectionsCreate(parser.Mailers, data.Name); err != nil {
+		return c.handleError(data.Name, "", "", t, transactionID == "", err)
+	}
+	if err := SerializeMailersSection(p, data); err != nil {
+		return c.handleError(data.Name, "", "", t, transactionID == "", err)
+	}
+
+	if err := c.saveData(p, t, transactionID == ""); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// EditMailersSection edits a mailers section in configuration. One of version or transactionID
+// is mandatory. Returns error on fail, nil on success.
+func (c *Client) EditMailersSection(name string, data *MailersSection, transactionID string, version int64) error {
+	if err := validateMailersSection(data); err != nil {
+		return NewConfError(ErrValidationError, err.Error())
+	}
+	if data.Name != name {
+		return NewConfError(ErrValidationError, fmt.Sprintf("mailers section %s can not be renamed to %s", name, data.Name))
+	}
+
+	if err := c.authorize("EditMailersSection", "mailers", name, "", "", transactionID); err != nil {
+		return err
+	}
+
+	p, t, err := c.loadDataForChange(transactionID, version)
+	if err != nil {
+		return err
+	}
+
+	if !c.checkSectionExists(parser.Mailers, name, p) {
+		e := NewConfError(ErrObjectDoesNotExist, fmt.Sprintf("%s %s does not exist", parser.Mailers, name))
+		return c.handleError(name, "", "", t, transactionID == "", e)
+	}
+
+	if err := SerializeMailersSection(p, data); err != nil {
+		return c.handleError(name, "", "", t, transactionID == "", err)
+	}
+
+	if err := c.saveData(p, t, transactionID == ""); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func ParseMailersSection(p *parser.Parser, name string) (*MailersSection, error) {
+	ms := &MailersSection{Name: name}
+	data, err := p.Get(parser.Mailers, name, "timeout mail", false)
+	if err != nil {
+		if err == parser_errors.ErrFetch {
+			return ms, nil
+		}
+		return nil, err
+	}
+	ms.Timeout = misc.ParseTimeout(data.(*types.StringC).Value)
+	return ms, nil
+}
+
+func SerializeMailersSection(p *parser.Parser, data *MailersSection) error {
+	var timeout interface{}
+	if data.Timeout != nil {
+		timeout = &types.StringC{Value: strconv.FormatInt(*data.Timeout, 10)}
+	}
+	return p.Set(parser.Mailers, data.Name, "timeout mail", timeout)
+}
+
+func validateMailersSection(data *MailersSection) error {
+	if data.Name == "" || strings.ContainsAny(data.Name, " \t#") {
+		return fmt.Errorf("invalid mailers section name %s", data.Name)
+	}
+	if data.Timeout != nil && *data.Timeout <= 0 {
+		return fmt.Errorf("mailers section %s: timeout mail has to be greater than 0", data.Name)
+	}
+	return nil
+}
+
+// mailersSectionUsers returns the defaults, frontends and backends sending email alerts through
+// the mailers section
+func mailersSectionUsers(p *parser.Parser, name string) []string {
+	users := []string{}
+	for _, section := range []parser.Section{parser.Defaults, parser.Frontends, parser.Backends} {
+		names, err := p.SectionsGet(section)
+		if err != nil {
+			continue
+		}
+		for _, n := range names {
+			mailers, found, err := getRawDirective(p, section, n, "email-alert mailers")
+			if err == nil && found && mailers == name {
+				users = append(users, fmt.Sprintf("%s %s", section, n))
+			}
+		}
+	}
+	return users
+}
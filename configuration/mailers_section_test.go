@@ -0,0 +1,74 @@
+// Copyright 2019 HAProxy Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package configuration
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCreateEditDeleteMailersSection(t *testing.T) {
+	timeout := int64(20000)
+	ms := &MailersSection{Name: "alerts", Timeout: &timeout}
+
+	err := client.CreateMailersSection(ms, "", version)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	version++
+
+	v, m, err := client.GetMailersSection("alerts", "")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if !reflect.DeepEqual(m, ms) {
+		t.Errorf("Mailers section %v returned, expected %v", m, ms)
+	}
+	if v != version {
+		t.Errorf("Version %v returned, expected %v", v, version)
+	}
+
+	if err := client.CreateMailersSection(ms, "", version); err == nil {
+		t.Error("Should throw error mailers section already exists")
+		version++
+	}
+
+	ms = &MailersSection{Name: "alerts"}
+	if err := client.EditMailersSection("alerts", ms, "", version); err != nil {
+		t.Error(err.Error())
+	} else {
+		version++
+	}
+	_, sections, err := client.GetMailersSections("")
+	if err != nil {
+		t.Error(err.Error())
+	} else if len(sections) != 1 || !reflect.DeepEqual(sections[0], ms) {
+		t.Errorf("Unexpected mailers sections: %v", sections)
+	}
+
+	if err := client.DeleteMailersSection("alerts", "", version); err != nil {
+		t.Error(err.Error())
+	} else {
+		version++
+	}
+	if _, _, err := client.GetMailersSection("alerts", ""); err == nil {
+		t.Error("DeleteMailersSection failed, mailers section alerts still exists")
+	}
+	if err := client.DeleteMailersSection("alerts", "", version); err == nil {
+		t.Error("Should throw error, non existant mailers section")
+		version++
+	}
+}
@@ -72,6 +72,10 @@ func (c *Client) GetNameserver(name string, resolverSection string, transactionI
 // DeleteNameserver deletes an nameserver in configuration. One of version or transactionID is
 // mandatory. Returns error on fail, nil on success.
 func (c *Client) DeleteNameserver(name string, resolverSection string, transactionID string, version int64) error {
+	if err := c.authorize("DeleteNameserver", "nameserver", name, "resolvers", resolverSection, transactionID); err != nil {
+		return err
+	}
+
 	p, t, err := c.loadDataForChange(transactionID, version)
 	if err != nil {
 		return err
@@ -102,6 +106,13 @@ func (c *Client) CreateNameserver(resolverSection string, data *models.Nameserve
 			return NewConfError(ErrValidationError, validationErr.Error())
 		}
 	}
+	if err := validateAddressPort("nameserver", data.Name, data.Address, data.Port); err != nil {
+		return NewConfError(ErrValidationError, err.Error())
+	}
+	if err := c.authorize("CreateNameserver", "nameserver", data.Name, "resolvers", resolverSection, transactionID); err != nil {
+		return err
+	}
+
 	p, t, err := c.loadDataForChange(transactionID, version)
 	if err != nil {
 		return err
@@ -133,6 +144,13 @@ func (c *Client) EditNameserver(name string, resolverSection string, data *model
 			return NewConfError(ErrValidationError, validationErr.Error())
 		}
 	}
+	if err := validateAddressPort("nameserver", data.Name, data.Address, data.Port); err != nil {
+		return NewConfError(ErrValidationError, err.Error())
+	}
+	if err := c.authorize("EditNameserver", "nameserver", name, "resolvers", resolverSection, transactionID); err != nil {
+		return err
+	}
+
 	p, t, err := c.loadDataForChange(transactionID, version)
 	if err != nil {
 		return err
@@ -177,12 +195,13 @@ func ParseNameservers(resolverSection string, p *parser.Parser) (models.Nameserv
 }
 
 func ParseNameserver(p types.Nameserver) *models.Nameserver {
-	parts := strings.Split(p.Address, ":")
-	if len(parts) != 2 {
+	// the port follows the last colon, IPv6 addresses contain colons
+	i := strings.LastIndex(p.Address, ":")
+	if i <= 0 {
 		return nil
 	}
-	ip := parts[0]
-	port, err := strconv.ParseInt(parts[1], 10, 64)
+	ip := p.Address[:i]
+	port, err := strconv.ParseInt(p.Address[i+1:], 10, 64)
 	if err != nil {
 		return nil
 	}
@@ -200,6 +219,18 @@ func SerializeNameserver(pe models.Nameserver) types.Nameserver {
 	}
 }
 
+// validateAddressPort checks that the nameserver, peer or mailer has an address and a valid port,
+// whether or not validation is enabled, as both are needed to write its line
+func validateAddressPort(kind string, name string, address *string, port *int64) error {
+	if address == nil || *address == "" || strings.ContainsAny(*address, " \t#") {
+		return fmt.Errorf("%s %s: invalid address", kind, name)
+	}
+	if port == nil || *port < 1 || *port > 65535 {
+		return fmt.Errorf("%s %s: port must be between 1 and 65535", kind, name)
+	}
+	return nil
+}
+
 func GetNameserverByName(name string, resolverSection string, p *parser.Parser) (*models.Nameserver, int) {
 	nameservers, err := ParseNameservers(resolverSection, p)
 	if err != nil {
@@ -175,3 +175,53 @@ func TestCreateEditDeleteNameserver(t *testing.T) {
 		version++
 	}
 }
+
+func TestNameserverValidation(t *testing.T) {
+	address := "10.0.0.2"
+	port := int64(0)
+	e := &models.Nameserver{
+		Address: &address,
+		Port:    &port,
+		Name:    "invalid",
+	}
+	if err := client.CreateNameserver("test", e, "", version); err == nil {
+		t.Error("Should throw error, invalid port")
+		version++
+	}
+	e.Port = nil
+	if err := client.CreateNameserver("test", e, "", version); err == nil {
+		t.Error("Should throw error, port not set")
+		version++
+	}
+
+	port = 53
+	e.Port = &port
+	if err := client.CreateNameserver("nonexistent", e, "", version); err == nil {
+		t.Error("Should throw error, resolvers section does not exist")
+		version++
+	}
+
+	ipv6 := "fd00::1"
+	e = &models.Nameserver{
+		Address: &ipv6,
+		Port:    &port,
+		Name:    "dns6",
+	}
+	if err := client.CreateNameserver("test", e, "", version); err != nil {
+		t.Fatal(err.Error())
+	}
+	version++
+
+	_, l, err := client.GetNameserver("dns6", "test", "")
+	if err != nil {
+		t.Error(err.Error())
+	} else if !reflect.DeepEqual(e, l) {
+		t.Errorf("Nameserver %v returned, expected %v", l, e)
+	}
+
+	if err := client.DeleteNameserver("dns6", "test", "", version); err != nil {
+		t.Error(err.Error())
+	} else {
+		version++
+	}
+}
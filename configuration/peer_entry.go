@@ -70,6 +70,10 @@ func (c *Client) GetPeerEntry(name string, peerSection string, transactionID str
 // DeletePeerEntry deletes an peer entry in configuration. One of version or transactionID is
 // mandatory. Returns error on fail, nil on success.
 func (c *Client) DeletePeerEntry(name string, peerSection string, transactionID string, version int64) error {
+	if err := c.authorize("DeletePeerEntry", "peer_entry", name, "peers", peerSection, transactionID); err != nil {
+		return err
+	}
+
 	p, t, err := c.loadDataForChange(transactionID, version)
 	if err != nil {
 		return err
@@ -100,6 +104,13 @@ func (c *Client) CreatePeerEntry(peerSection string, data *models.PeerEntry, tra
 			return NewConfError(ErrValidationError, validationErr.Error())
 		}
 	}
+	if err := validateAddressPort("peer", data.Name, data.Address, data.Port); err != nil {
+		return NewConfError(ErrValidationError, err.Error())
+	}
+	if err := c.authorize("CreatePeerEntry", "peer_entry", data.Name, "peers", peerSection, transactionID); err != nil {
+		return err
+	}
+
 	p, t, err := c.loadDataForChange(transactionID, version)
 	if err != nil {
 		return err
@@ -131,6 +142,13 @@ func (c *Client) EditPeerEntry(name string, peerSection string, data *models.Pee
 			return NewConfError(ErrValidationError, validationErr.Error())
 		}
 	}
+	if err := validateAddressPort("peer", data.Name, data.Address, data.Port); err != nil {
+		return NewConfError(ErrValidationError, err.Error())
+	}
+	if err := c.authorize("EditPeerEntry", "peer_entry", name, "peers", peerSection, transactionID); err != nil {
+		return err
+	}
+
 	p, t, err := c.loadDataForChange(transactionID, version)
 	if err != nil {
 		return err
@@ -180,3 +180,23 @@ func TestCreateEditDeletePeerEntry(t *testing.T) {
 		version++
 	}
 }
+
+func TestPeerEntryValidation(t *testing.T) {
+	address := "192.168.1.3"
+	port := int64(70000)
+	e := &models.PeerEntry{
+		Address: &address,
+		Port:    &port,
+		Name:    "invalid",
+	}
+	if err := client.CreatePeerEntry("mycluster", e, "", version); err == nil {
+		t.Error("Should throw error, invalid port")
+		version++
+	}
+	e.Address = nil
+	port = 10000
+	if err := client.CreatePeerEntry("mycluster", e, "", version); err == nil {
+		t.Error("Should throw error, address not set")
+		version++
+	}
+}
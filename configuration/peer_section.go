@@ -17,9 +17,11 @@ package configuration
 
 import (
 	"fmt"
+	"strings"
 
 	strfmt "github.com/go-openapi/strfmt"
 	parser "github.com/haproxytech/config-parser/v3"
+	"github.com/haproxytech/config-parser/v3/types"
 	"github.com/haproxytech/models/v2"
 )
 
@@ -72,9 +74,14 @@ func (c *Client) GetPeerSection(name string, transactionID string) (int64, *mode
 	return v, peerSection, nil
 }
 
-// DeletePeerSection deletes a peerSection in configuration. One of version or transactionID is
-// mandatory. Returns error on fail, nil on success.
+// DeletePeerSection deletes a peerSection in configuration. Peer sections replicating stick tables
+// can not be deleted. One of version or transactionID is mandatory. Returns error on fail, nil on
+// success.
 func (c *Client) DeletePeerSection(name string, transactionID string, version int64) error {
+	if err := c.authorize("DeletePeerSection", "peers", name, "", "", transactionID); err != nil {
+		return err
+	}
+
 	p, t, err := c.loadDataForChange(transactionID, version)
 	if err != nil {
 		return err
@@ -85,6 +92,11 @@ func (c *Client) DeletePeerSection(name string, transactionID string, version in
 		return c.handleError(name, "", "", t, transactionID == "", e)
 	}
 
+	if users := peerSectionUsers(p, name); len(users) > 0 {
+		e := NewConfError(ErrValidationError, fmt.Sprintf("%s %s is used by stick-table of %s", parser.Peers, name, strings.Join(users, ", ")))
+		return c.handleError(name, "", "", t, transactionID == "", e)
+	}
+
 	if err := DeletePeerSection(p, name); err != nil {
 		return c.handleError(name, "", "", t, transactionID == "", err)
 	}
@@ -106,6 +118,10 @@ func (c *Client) CreatePeerSection(data *models.PeerSection, transactionID strin
 		}
 	}
 
+	if err := c.authorize("CreatePeerSection", "peers", data.Name, "", "", transactionID); err != nil {
+		return err
+	}
+
 	p, t, err := c.loadDataForChange(transactionID, version)
 	if err != nil {
 		return err
@@ -127,3 +143,25 @@ func SerializePeerSection(p *parser.Parser, data *models.PeerSection) error {
 func DeletePeerSection(p *parser.Parser, name string) error {
 	return p.SectionsDelete(parser.Peers, name)
 }
+
+// peerSectionUsers returns the frontends and backends with a stick-table replicated by the peers
+// section
+func peerSectionUsers(p *parser.Parser, name string) []string {
+	users := []string{}
+	for _, section := range []parser.Section{parser.Frontends, parser.Backends} {
+		names, err := p.SectionsGet(section)
+		if err != nil {
+			continue
+		}
+		for _, n := range names {
+			data, err := p.Get(section, n, "stick-table", false)
+			if err != nil {
+				continue
+			}
+			if st, ok := data.(*types.StickTable); ok && st.Peers == name {
+				users = append(users, fmt.Sprintf("%s %s", section, n))
+			}
+		}
+	}
+	return users
+}
@@ -132,3 +132,14 @@ func TestCreateEditDeletePeerSection(t *testing.T) {
 	}
 
 }
+
+func TestDeleteUsedPeerSection(t *testing.T) {
+	err := client.DeletePeerSection("mycluster", "", version)
+	if err == nil {
+		t.Error("Should throw error, peers section used by stick-table of backend test_2")
+		version++
+	}
+	if _, _, err := client.GetPeerSection("mycluster", ""); err != nil {
+		t.Error(err.Error())
+	}
+}
@@ -0,0 +1,260 @@
+// Copyright 2019 HAProxy Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package configuration
+
+import (
+	"fmt"
+	"strings"
+
+	parser "github.com/haproxytech/config-parser/v3"
+	parser_errors "github.com/haproxytech/config-parser/v3/errors"
+	"github.com/haproxytech/config-parser/v3/types"
+)
+
+// Program is a program section, an external process started and supervised by the HAProxy
+// master in master-worker mode
+type Program struct {
+	Name string `json:"name"`
+	// Command is the command line of the process, including its arguments
+	Command string `json:"command"`
+	User    string `json:"user,omitempty"`
+	Group   string `json:"group,omitempty"`
+	// StartOnReload restarts the process on every reload of HAProxy, enabled or disabled
+	StartOnReload string `json:"start_on_reload,omitempty"`
+}
+
+// Validate checks the program name, command and the process credentials
+func (pr *Program) Validate() error {
+	if pr.Name == "" || strings.ContainsAny(pr.Name, " \t#") {
+		return fmt.Errorf("invalid program name %s", pr.Name)
+	}
+	if strings.TrimSpace(pr.Command) == "" || strings.ContainsAny(pr.Command, "#\n") {
+		return fmt.Errorf("program %s: command can not be empty nor contain '#'", pr.Name)
+	}
+	if strings.ContainsAny(pr.User, " \t#") {
+		return fmt.Errorf("program %s: invalid user %s", pr.Name, pr.User)
+	}
+	if strings.ContainsAny(pr.Group, " \t#") {
+		return fmt.Errorf("program %s: invalid group %s", pr.Name, pr.Group)
+	}
+	if pr.StartOnReload != "" && pr.StartOnReload != "enabled" && pr.StartOnReload != "disabled" {
+		return fmt.Errorf("program %s: start_on_reload must be enabled or disabled", pr.Name)
+	}
+	return nil
+}
+
+// GetPrograms returns configuration version and an array of
+// configured programs. Returns error on fail.
+func (c *Client) GetPrograms(transactionID string) (int64, []*Program, error) {
+	p, err := c.GetParser(transactionID)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	v, err := c.GetVersion(transactionID)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	names, err := p.SectionsGet(parser.Program)
+	if err != nil {
+		return v, nil, err
+	}
+
+	programs := []*Program{}
+	for _, name := range names {
+		pr, err := ParseProgram(p, name)
+		if err != nil {
+			return v, nil, c.handleError(name, "", "", "", false, err)
+		}
+		programs = append(programs, pr)
+	}
+
+	return v, programs, nil
+}
+
+// GetProgram returns configuration version and a requested program.
+// Returns error on fail or if program does not exist.
+func (c *Client) GetProgram(name string, transactionID string) (int64, *Program, error) {
+	p, err := c.GetParser(transactionID)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	v, err := c.GetVersion(transactionID)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	if !c.checkSectionExists(parser.Program, name, p) {
+		return v, nil, NewConfError(ErrObjectDoesNotExist, fmt.Sprintf("Program %s does not exist", name))
+	}
+
+	pr, err := ParseProgram(p, name)
+	if err != nil {
+		return v, nil, c.handleError(name, "", "", "", false, err)
+	}
+
+	return v, pr, nil
+}
+
+// DeleteProgram deletes a program in configuration. One of version or transactionID is
+// mandatory. Returns error on fail, nil on success.
+func (c *Client) DeleteProgram(name string, transactionID string, version int64) error {
+	if err := c.authorize("DeleteProgram", "program", name, "", "", transactionID); err != nil {
+		return err
+	}
+
+	p, t, err := c.loadDataForChange(transactionID, version)
+	if err != nil {
+		return err
+	}
+
+	if !c.checkSectionExists(parser.Program, name, p) {
+		e := NewConfError(ErrObjectDoesNotExist, fmt.Sprintf("%s %s does not exist", parser.Program, name))
+		return c.handleError(name, "", "", t, transactionID == "", e)
+	}
+
+	if err := p.SectionsDelete(parser.Program, name); err != nil {
+		return c.handleError(name, "", "", t, transactionID == "", err)
+	}
+
+	if err := c.saveData(p, t, transactionID == ""); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// CreateProgram creates a program in configuration. One of version or transactionID is
+// mandatory. Returns error on fail, nil on success.
+func (c *Client) CreateProgram(data *Program, transactionID string, version int64) error {
+	if err := data.Validate(); err != nil {
+		return NewConfError(ErrValidationError, err.Error())
+	}
+
+	if err := c.authorize("CreateProgram", "program", data.Name, "", "", transactionID); err != nil {
+		return err
+	}
+
+	p, t, err := c.loadDataForChange(transactionID, version)
+	if err != nil {
+		return err
+	}
+
+	if err := p.SectionsCreate(parser.Program, data.Name); err != nil {
+		return c.handleError(data.Name, "", "", t, transactionID == "", err)
+	}
+	if err := SerializeProgram(p, data); err != nil {
+		return c.handleError(data.Name, "", "", t, transactionID == "", err)
+	}
+
+	if err := c.saveData(p, t, transactionID == ""); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// EditProgram edits a program in configuration. One of version or transactionID is mandatory.
+// Returns error on fail, nil on success.
+func (c *Client) EditProgram(name string, data *Program, transactionID string, version int64) error {
+	if err := data.Validate(); err != nil {
+		return NewConfError(ErrValidationError, err.Error())
+	}
+	if data.Name != name {
+		return NewConfError(ErrValidationError, fmt.Sprintf("program %s can not be renamed to %s", name, data.Name))
+	}
+
+	if err := c.authorize("EditProgram", "program", name, "", "", transactionID); err != nil {
+		return err
+	}
+
+	p, t, err := c.loadDataForChange(transactionID, version)
+	if err != nil {
+		return err
+	}
+
+	if !c.checkSectionExists(parser.Program, name, p) {
+		e := NewConfError(ErrObjectDoesNotExist, fmt.Sprintf("%s %s does not exist", parser.Program, name))
+		return c.handleError(name, "", "", t, transactionID == "", e)
+	}
+
+	if err := SerializeProgram(p, data); err != nil {
+		return c.handleError(name, "", "", t, transactionID == "", err)
+	}
+
+	if err := c.saveData(p, t, transactionID == ""); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func ParseProgram(p *parser.Parser, name string) (*Program, error) {
+	pr := &Program{Name: name}
+	for _, attr := range []string{"command", "user", "group"} {
+		data, err := p.Get(parser.Program, name, attr, false)
+		if err != nil {
+			if err == parser_errors.ErrFetch {
+				continue
+			}
+			return nil, err
+		}
+		value := data.(*types.StringC).Value
+		switch attr {
+		case "command":
+			pr.Command = value
+		case "user":
+			pr.User = value
+		case "group":
+			pr.Group = value
+		}
+	}
+
+	data, err := p.Get(parser.Program, name, "option start-on-reload", false)
+	if err != nil {
+		if err != parser_errors.ErrFetch {
+			return nil, err
+		}
+	} else if data.(*types.SimpleOption).NoOption {
+		pr.StartOnReload = "disabled"
+	} else {
+		pr.StartOnReload = "enabled"
+	}
+	return pr, nil
+}
+
+func SerializeProgram(p *parser.Parser, data *Program) error {
+	for attr, value := range map[string]string{"command": strings.TrimSpace(data.Command), "user": data.User, "group": data.Group} {
+		var d interface{}
+		if value != "" {
+			d = &types.StringC{Value: value}
+		}
+		if err := p.Set(parser.Program, data.Name, attr, d); err != nil {
+			return err
+		}
+	}
+
+	var option interface{}
+	switch data.StartOnReload {
+	case "enabled":
+		option = &types.SimpleOption{}
+	case "disabled":
+		option = &types.SimpleOption{NoOption: true}
+	}
+	return p.Set(parser.Program, data.Name, "option start-on-reload", option)
+}
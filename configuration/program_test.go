@@ -0,0 +1,80 @@
+// Copyright 2019 HAProxy Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package configuration
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCreateEditDeleteProgram(t *testing.T) {
+	pr := &Program{
+		Name:          "dataplaneapi",
+		Command:       "",
+		User:          "haproxy",
+		StartOnReload: "enabled",
+	}
+	if err := client.CreateProgram(pr, "", version); err == nil {
+		t.Error("Should throw error, program has no command")
+		version++
+	}
+
+	pr.Command = "/usr/bin/dataplaneapi --host 127.0.0.1 --port 5555"
+	if err := client.CreateProgram(pr, "", version); err != nil {
+		t.Fatal(err.Error())
+	}
+	version++
+
+	v, program, err := client.GetProgram("dataplaneapi", "")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if !reflect.DeepEqual(program, pr) {
+		t.Errorf("Program %v returned, expected %v", program, pr)
+	}
+	if v != version {
+		t.Errorf("Version %v returned, expected %v", v, version)
+	}
+
+	if err := client.CreateProgram(pr, "", version); err == nil {
+		t.Error("Should throw error, program dataplaneapi already exists")
+		version++
+	}
+
+	pr.User = ""
+	pr.Group = "haproxy"
+	pr.StartOnReload = "disabled"
+	if err := client.EditProgram("dataplaneapi", pr, "", version); err != nil {
+		t.Error(err.Error())
+	} else {
+		version++
+	}
+	_, programs, err := client.GetPrograms("")
+	if err != nil {
+		t.Error(err.Error())
+	} else if len(programs) != 1 || !reflect.DeepEqual(programs[0], pr) {
+		t.Errorf("Unexpected programs: %v", programs)
+	}
+
+	if err := client.DeleteProgram("dataplaneapi", "", version); err != nil {
+		t.Error(err.Error())
+	} else {
+		version++
+	}
+	if _, _, err := client.GetProgram("dataplaneapi", ""); err == nil {
+		t.Error("DeleteProgram failed, program dataplaneapi still exists")
+	}
+}
@@ -0,0 +1,323 @@
+// Copyright 2019 HAProxy Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package configuration
+
+import (
+	"fmt"
+	"strings"
+
+	strfmt "github.com/go-openapi/strfmt"
+	"github.com/haproxytech/models/v2"
+)
+
+// DefaultBotUserAgents are user agent fragments of common scanners and scripted clients
+var DefaultBotUserAgents = []string{"sqlmap", "nikto", "nmap", "masscan", "zgrab", "dirbuster", "gobuster", "wpscan"}
+
+// DefaultProbePaths are path prefixes commonly probed by vulnerability scanners
+var DefaultProbePaths = []string{"/.env", "/.git", "/wp-login.php", "/wp-admin", "/phpmyadmin", "/cgi-bin", "/xmlrpc.php"}
+
+const (
+	// protectionPrefix marks the acls and rate limit policies of protection rule sets, other
+	// acls and policies can not use it
+	protectionPrefix          = "protection."
+	protectionUserAgentSuffix = "_bad_ua"
+	protectionPathSuffix      = "_probe"
+	protectionRateSuffix      = "_rate"
+)
+
+// ProtectionRuleSet is a named group of rules protecting a frontend from bots and
+// scanners. Each non empty parameter instantiates its part of the template: a user-agent
+// acl with a deny rule, a path probe acl with a deny rule and a request rate limit per source.
+type ProtectionRuleSet struct {
+	// Name of the group, generated acls and tables are named protection.<name>_<part>
+	Name string `json:"name"`
+	// UserAgents are user agent fragments to deny, case insensitive
+	UserAgents []string `json:"user_agents,omitempty"`
+	// Paths are path prefixes to deny, case insensitive
+	Paths []string `json:"paths,omitempty"`
+	// RateLimit is the number of requests per source allowed within RatePeriod
+	RateLimit int64 `json:"rate_limit,omitempty"`
+	// RatePeriod in milliseconds
+	RatePeriod int64 `json:"rate_period,omitempty"`
+	// DenyStatus returned to denied clients, optional
+	DenyStatus *int64 `json:"deny_status,omitempty"`
+}
+
+// ProtectionRuleSets is an array of ProtectionRuleSet
+type ProtectionRuleSets []*ProtectionRuleSet
+
+// Validate validates the protection rule set
+func (r *ProtectionRuleSet) Validate(formats strfmt.Registry) error {
+	if r.Name == "" || strings.ContainsAny(r.Name, " \t") {
+		return fmt.Errorf("invalid name %s", r.Name)
+	}
+	for _, v := range append(append([]string{}, r.UserAgents...), r.Paths...) {
+		if v == "" || strings.ContainsAny(v, " \t") {
+			return fmt.Errorf("invalid pattern '%s', patterns can not contain spaces", v)
+		}
+	}
+	if r.RateLimit < 0 || (r.RateLimit > 0 && r.RatePeriod <= 0) {
+		return fmt.Errorf("rate_period must be greater than 0 when rate_limit is set")
+	}
+	if len(r.UserAgents) == 0 && len(r.Paths) == 0 && r.RateLimit == 0 {
+		return fmt.Errorf("rule set %s is empty", r.Name)
+	}
+	return nil
+}
+
+// GetProtectionRuleSets returns configuration version and an array of protection rule sets
+// instantiated in the frontend, found by the reserved prefix of their acls and rate limit
+// policies. Returns error on fail.
+func (c *Client) GetProtectionRuleSets(frontend string, transactionID string) (int64, ProtectionRuleSets, error) {
+	v, acls, err := c.GetACLs("frontend", frontend, transactionID)
+	if err != nil {
+		return 0, nil, err
+	}
+	_, rules, err := c.GetHTTPRequestRules("frontend", frontend, transactionID)
+	if err != nil {
+		return 0, nil, err
+	}
+	_, policies, err := c.GetRateLimitPolicies(frontend, transactionID)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	sets := map[string]*ProtectionRuleSet{}
+	names := []string{}
+	get := func(name string) *ProtectionRuleSet {
+		if _, ok := sets[name]; !ok {
+			sets[name] = &ProtectionRuleSet{Name: name}
+			names = append(names, name)
+		}
+		return sets[name]
+	}
+
+	for _, a := range acls {
+		if !strings.HasPrefix(a.ACLName, protectionPrefix) {
+			continue
+		}
+		name := strings.TrimPrefix(a.ACLName, protectionPrefix)
+		switch {
+		case strings.HasSuffix(name, protectionUserAgentSuffix) && a.Criterion == "req.hdr(user-agent)":
+			rs := get(strings.TrimSuffix(name, protectionUserAgentSuffix))
+			rs.UserAgents = strings.Fields(strings.TrimPrefix(a.Value, "-m sub -i "))
+		case strings.HasSuffix(name, protectionPathSuffix) && a.Criterion == "path_beg":
+			rs := get(strings.TrimSuffix(name, protectionPathSuffix))
+			rs.Paths = strings.Fields(strings.TrimPrefix(a.Value, "-i "))
+		}
+	}
+	for _, p := range policies {
+		if strings.HasPrefix(p.Name, protectionPrefix) && strings.HasSuffix(p.Name, protectionRateSuffix) {
+			rs := get(strings.TrimSuffix(strings.TrimPrefix(p.Name, protectionPrefix), protectionRateSuffix))
+			rs.RateLimit = p.Limit
+			rs.RatePeriod = p.Period
+			rs.DenyStatus = p.DenyStatus
+		}
+	}
+	for _, r := range rules {
+		for _, name := range names {
+			if r.Type == "deny" && (r.CondTest == protectionName(name, protectionUserAgentSuffix) || r.CondTest == protectionName(name, protectionPathSuffix)) {
+				sets[name].DenyStatus = r.DenyStatus
+			}
+		}
+	}
+
+	result := ProtectionRuleSets{}
+	for _, name := range names {
+		result = append(result, sets[name])
+	}
+	return v, result, nil
+}
+
+// GetProtectionRuleSet returns configuration version and a requested protection rule set
+// of the frontend. Returns error on fail or if rule set does not exist.
+func (c *Client) GetProtectionRuleSet(name string, frontend string, transactionID string) (int64, *ProtectionRuleSet, error) {
+	v, sets, err := c.GetProtectionRuleSets(frontend, transactionID)
+	if err != nil {
+		return 0, nil, err
+	}
+	for _, rs := range sets {
+		if rs.Name == name {
+			return v, rs, nil
+		}
+	}
+	return v, nil, NewConfError(ErrObjectDoesNotExist, fmt.Sprintf("Protection rule set %s does not exist in frontend %s", name, frontend))
+}
+
+// CreateProtectionRuleSet instantiates the protection rule set template in the frontend in
+// one transaction. One of version or transactionID is mandatory. Returns error on fail, nil on success.
+func (c *Client) CreateProtectionRuleSet(frontend string, data *ProtectionRuleSet, transactionID string, version int64) error {
+	var res []error
+	if c.UseValidation {
+		validationErr := data.Validate(strfmt.Default)
+		if validationErr != nil {
+			return NewConfError(ErrValidationError, validationErr.Error())
+		}
+	}
+
+	if err := c.authorize("CreateProtectionRuleSet", "protection_rule_set", data.Name, "frontend", frontend, transactionID); err != nil {
+		return err
+	}
+
+	p, t, err := c.loadDataForChange(transactionID, version)
+	if err != nil {
+		return err
+	}
+
+	if _, rs, _ := c.GetProtectionRuleSet(data.Name, frontend, t); rs != nil {
+		return c.handleError(data.Name, "frontend", frontend, t, transactionID == "",
+			NewConfError(ErrObjectAlreadyExists, fmt.Sprintf("Protection rule set %s already exists in frontend %s", data.Name, frontend)))
+	}
+
+	_, acls, err := c.GetACLs("frontend", frontend, t)
+	if err != nil {
+		return c.handleError(data.Name, "frontend", frontend, t, transactionID == "", err)
+	}
+	aclIndex := int64(len(acls))
+
+	denyACLs := []*models.ACL{}
+	if len(data.UserAgents) > 0 {
+		denyACLs = append(denyACLs, &models.ACL{
+			ACLName:   protectionName(data.Name, protectionUserAgentSuffix),
+			Criterion: "req.hdr(user-agent)",
+			Value:     "-m sub -i " + strings.Join(data.UserAgents, " "),
+		})
+	}
+	if len(data.Paths) > 0 {
+		denyACLs = append(denyACLs, &models.ACL{
+			ACLName:   protectionName(data.Name, protectionPathSuffix),
+			Criterion: "path_beg",
+			Value:     "-i " + strings.Join(data.Paths, " "),
+		})
+	}
+
+	for _, acl := range denyACLs {
+		index := aclIndex
+		acl.Index = &index
+		aclIndex++
+		if err := c.createACL("CreateProtectionRuleSet", "frontend", frontend, acl, t, 0); err != nil {
+			res = append(res, err)
+		}
+		ruleIndex := int64(0)
+		err := c.CreateHTTPRequestRule("frontend", frontend, &models.HTTPRequestRule{
+			Index:      &ruleIndex,
+			Type:       "deny",
+			DenyStatus: data.DenyStatus,
+			Cond:       "if",
+			CondTest:   acl.ACLName,
+		}, t, 0)
+		if err != nil {
+			res = append(res, err)
+		}
+	}
+
+	if data.RateLimit > 0 {
+		err := c.createRateLimitPolicy("CreateProtectionRuleSet", frontend, &RateLimitPolicy{
+			Name:       protectionName(data.Name, protectionRateSuffix),
+			Limit:      data.RateLimit,
+			Period:     data.RatePeriod,
+			DenyStatus: data.DenyStatus,
+		}, t, 0)
+		if err != nil {
+			res = append(res, err)
+		}
+	}
+
+	if len(res) > 0 {
+		return c.handleError(data.Name, "frontend", frontend, t, transactionID == "", CompositeTransactionError(res...))
+	}
+
+	if err := c.saveData(p, t, transactionID == ""); err != nil {
+		return err
+	}
+	return nil
+}
+
+// DeleteProtectionRuleSet removes all acls, rules and tables of the protection rule set from
+// the frontend in one transaction. One of version or transactionID is mandatory. Returns error
+// on fail, nil on success.
+func (c *Client) DeleteProtectionRuleSet(name string, frontend string, transactionID string, version int64) error {
+	var res []error
+	if err := c.authorize("DeleteProtectionRuleSet", "protection_rule_set", name, "frontend", frontend, transactionID); err != nil {
+		return err
+	}
+
+	p, t, err := c.loadDataForChange(transactionID, version)
+	if err != nil {
+		return err
+	}
+
+	_, rs, err := c.GetProtectionRuleSet(name, frontend, t)
+	if err != nil {
+		return c.handleError(name, "frontend", frontend, t, transactionID == "", err)
+	}
+
+	groupACLs := []string{protectionName(name, protectionUserAgentSuffix), protectionName(name, protectionPathSuffix)}
+
+	_, rules, err := c.GetHTTPRequestRules("frontend", frontend, t)
+	if err != nil {
+		return c.handleError(name, "frontend", frontend, t, transactionID == "", err)
+	}
+	// delete from the end so the remaining indexes stay valid
+	for i := len(rules) - 1; i >= 0; i-- {
+		if rules[i].Type == "deny" && (rules[i].CondTest == groupACLs[0] || rules[i].CondTest == groupACLs[1]) {
+			if err := c.DeleteHTTPRequestRule(*rules[i].Index, "frontend", frontend, t, 0); err != nil {
+				res = append(res, err)
+			}
+		}
+	}
+
+	_, acls, err := c.GetACLs("frontend", frontend, t)
+	if err != nil {
+		return c.handleError(name, "frontend", frontend, t, transactionID == "", err)
+	}
+	for i := len(acls) - 1; i >= 0; i-- {
+		if acls[i].ACLName == groupACLs[0] || acls[i].ACLName == groupACLs[1] {
+			if err := c.DeleteACL(*acls[i].Index, "frontend", frontend, t, 0); err != nil {
+				res = append(res, err)
+			}
+		}
+	}
+
+	if rs.RateLimit > 0 {
+		if err := c.DeleteRateLimitPolicy(protectionName(name, protectionRateSuffix), frontend, t, 0); err != nil {
+			res = append(res, err)
+		}
+	}
+
+	if len(res) > 0 {
+		return c.handleError(name, "frontend", frontend, t, transactionID == "", CompositeTransactionError(res...))
+	}
+
+	if err := c.saveData(p, t, transactionID == ""); err != nil {
+		return err
+	}
+	return nil
+}
+
+// protectionName returns the name of an acl or rate limit policy of the protection rule set
+func protectionName(name, suffix string) string {
+	return protectionPrefix + name + suffix
+}
+
+// validateProtectionReservedName rejects acl and rate limit policy names using the prefix
+// reserved for protection rule sets
+func validateProtectionReservedName(name string) error {
+	if strings.HasPrefix(name, protectionPrefix) {
+		return NewConfError(ErrValidationError, fmt.Sprintf("name %s is invalid, prefix %s is reserved for protection rule sets", name, protectionPrefix))
+	}
+	return nil
+}
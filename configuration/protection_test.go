@@ -0,0 +1,123 @@
+// Copyright 2019 HAProxy Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package configuration
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/haproxytech/models/v2"
+)
+
+func TestCreateGetDeleteProtectionRuleSet(t *testing.T) {
+	status := int64(403)
+	rs := &ProtectionRuleSet{
+		Name:       "scanners",
+		UserAgents: []string{"sqlmap", "nikto"},
+		Paths:      []string{"/.env", "/wp-admin"},
+		RateLimit:  50,
+		RatePeriod: 10000,
+		DenyStatus: &status,
+	}
+
+	err := client.CreateProtectionRuleSet("test_2", rs, "", version)
+	if err != nil {
+		t.Error(err.Error())
+	} else {
+		version++
+	}
+
+	v, ruleSet, err := client.GetProtectionRuleSet("scanners", "test_2", "")
+	if err != nil {
+		t.Error(err.Error())
+	}
+
+	if !reflect.DeepEqual(ruleSet, rs) {
+		fmt.Printf("Created protection rule set: %v\n", ruleSet)
+		fmt.Printf("Given protection rule set: %v\n", rs)
+		t.Error("Created protection rule set not equal to given protection rule set")
+	}
+
+	if v != version {
+		t.Errorf("Version %v returned, expected %v", v, version)
+	}
+
+	err = client.CreateProtectionRuleSet("test_2", rs, "", version)
+	if err == nil {
+		t.Error("Should throw error protection rule set already exists")
+		version++
+	}
+
+	err = client.DeleteProtectionRuleSet("scanners", "test_2", "", version)
+	if err != nil {
+		t.Error(err.Error())
+	} else {
+		version++
+	}
+
+	if v, _ := client.GetVersion(""); v != version {
+		t.Error("Version not incremented")
+	}
+
+	_, _, err = client.GetProtectionRuleSet("scanners", "test_2", "")
+	if err == nil {
+		t.Error("DeleteProtectionRuleSet failed, protection rule set scanners still exists")
+	}
+}
+
+func TestProtectionRuleSetReservedNames(t *testing.T) {
+	tr, err := client.StartTransaction(version)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.DeleteTransaction(tr.ID)
+
+	_, acls, err := client.GetACLs("frontend", "test_2", tr.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	index := int64(len(acls))
+	reserved := &models.ACL{Index: &index, ACLName: "protection.bots_bad_ua", Criterion: "req.hdr(user-agent)", Value: "-m sub -i curl"}
+	if err := client.CreateACL("frontend", "test_2", reserved, tr.ID, 0); err == nil {
+		t.Error("Should throw error acl name uses reserved prefix")
+	}
+	if err := client.CreateRateLimitPolicy("test_2", &RateLimitPolicy{Name: "protection.api_rate", Limit: 10, Period: 1000}, tr.ID, 0); err == nil {
+		t.Error("Should throw error rate limit policy name uses reserved prefix")
+	}
+
+	// objects following the naming of rule sets without the reserved prefix are not claimed
+	acl := &models.ACL{Index: &index, ACLName: "bots_bad_ua", Criterion: "req.hdr(user-agent)", Value: "-m sub -i curl"}
+	if err := client.CreateACL("frontend", "test_2", acl, tr.ID, 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.CreateRateLimitPolicy("test_2", &RateLimitPolicy{Name: "api_rate", Limit: 10, Period: 1000}, tr.ID, 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.CreateProtectionRuleSet("test_2", &ProtectionRuleSet{Name: "scanners", Paths: []string{"/.git"}}, tr.ID, 0); err != nil {
+		t.Fatal(err)
+	}
+	_, sets, err := client.GetProtectionRuleSets("test_2", tr.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sets) != 1 || sets[0].Name != "scanners" {
+		for _, rs := range sets {
+			fmt.Printf("Protection rule set: %v\n", rs)
+		}
+		t.Error("Only the created protection rule set should be returned")
+	}
+}
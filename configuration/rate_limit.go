@@ -0,0 +1,466 @@
+// Copyright 2019 HAProxy Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package configuration
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+
+	strfmt "github.com/go-openapi/strfmt"
+	"github.com/haproxytech/models/v2"
+)
+
+// RateLimitPolicy is a rate limit applied to a frontend. Requests (or connections on tcp layer)
+// are tracked by key in the stick table of a dedicated backend named after the policy, and the
+// ones over limit within period are denied, tarpitted or rejected. Each policy tracks with its
+// own sticky counter, so at most rateLimitCounters policies fit in a frontend, fewer if other
+// rules of the frontend already track counters.
+type RateLimitPolicy struct {
+	// Name of the policy and of the backend holding the stick table
+	Name string `json:"name"`
+	// Layer is http for http-request rules or tcp for tcp-request connection rules
+	Layer string `json:"layer,omitempty"`
+	// Key is the tracked sample, defaults to src
+	Key string `json:"key,omitempty"`
+	// TableType is the stick table key type, defaults to ip
+	TableType string `json:"table_type,omitempty"`
+	// TableSize is the number of entries in the stick table
+	TableSize *int64 `json:"table_size,omitempty"`
+	// Expire of stick table entries in milliseconds
+	Expire *int64 `json:"expire,omitempty"`
+	// Peers section used to synchronize the stick table, optional
+	Peers string `json:"peers,omitempty"`
+	// Limit is the number of requests allowed within period
+	Limit int64 `json:"limit"`
+	// Period over which requests are counted in milliseconds
+	Period int64 `json:"period"`
+	// Action is deny or tarpit on http layer, reject on tcp layer
+	Action string `json:"action,omitempty"`
+	// DenyStatus returned when denying on http layer
+	DenyStatus *int64 `json:"deny_status,omitempty"`
+}
+
+// RateLimitPolicies is an array of RateLimitPolicy
+type RateLimitPolicies []*RateLimitPolicy
+
+// rateLimitCounters is the number of sticky counters HAProxy tracks per stream, sc0 to sc2
+const rateLimitCounters = 3
+
+var rateLimitCondRegex = regexp.MustCompile(`^\{ sc_(http_req_rate|conn_rate)\((\d),([^)]+)\) gt (\d+) \}$`)
+
+// Validate validates the rate limit policy
+func (r *RateLimitPolicy) Validate(formats strfmt.Registry) error {
+	if r.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	if r.Limit <= 0 {
+		return fmt.Errorf("limit must be greater than 0")
+	}
+	if r.Period <= 0 {
+		return fmt.Errorf("period must be greater than 0")
+	}
+	switch r.layer() {
+	case "http":
+		if r.Action != "" && r.Action != "deny" && r.Action != "tarpit" {
+			return fmt.Errorf("action %s not supported on http layer", r.Action)
+		}
+	case "tcp":
+		if r.Action != "" && r.Action != "reject" {
+			return fmt.Errorf("action %s not supported on tcp layer", r.Action)
+		}
+		if r.DenyStatus != nil {
+			return fmt.Errorf("deny_status not supported on tcp layer")
+		}
+	default:
+		return fmt.Errorf("layer %s not supported", r.Layer)
+	}
+	return nil
+}
+
+func (r *RateLimitPolicy) layer() string {
+	if r.Layer == "" {
+		return "http"
+	}
+	return r.Layer
+}
+
+func (r *RateLimitPolicy) key() string {
+	if r.Key == "" {
+		return "src"
+	}
+	return r.Key
+}
+
+func (r *RateLimitPolicy) counter() string {
+	if r.layer() == "tcp" {
+		return "conn_rate"
+	}
+	return "http_req_rate"
+}
+
+func (r *RateLimitPolicy) condTest(counter int) string {
+	return fmt.Sprintf("{ sc_%s(%d,%s) gt %d }", r.counter(), counter, r.Name, r.Limit)
+}
+
+// httpTrackRule returns the sticky counter, key and table of a track-sc rule, -1 if r does not
+// track a counter
+func httpTrackRule(r *models.HTTPRequestRule) (int, string, string) {
+	switch r.Type {
+	case "track-sc0":
+		return 0, r.TrackSc0Key, r.TrackSc0Table
+	case "track-sc1":
+		return 1, r.TrackSc1Key, r.TrackSc1Table
+	case "track-sc2":
+		return 2, r.TrackSc2Key, r.TrackSc2Table
+	}
+	return -1, "", ""
+}
+
+// tcpTrackRule returns the sticky counter, key and table of a track-sc rule, -1 if r does not
+// track a counter
+func tcpTrackRule(r *models.TCPRequestRule) (int, string, string) {
+	switch r.Action {
+	case "track-sc0":
+		return 0, r.TrackKey, r.TrackTable
+	case "track-sc1":
+		return 1, r.TrackKey, r.TrackTable
+	case "track-sc2":
+		return 2, r.TrackKey, r.TrackTable
+	}
+	return -1, "", ""
+}
+
+// freeRateLimitCounter returns the lowest sticky counter not tracked by any http-request,
+// http-response or tcp-request rule of the frontend, counters are shared by all of them
+func freeRateLimitCounter(httpRules models.HTTPRequestRules, responseRules models.HTTPResponseRules, tcpRules models.TCPRequestRules) (int, error) {
+	used := make([]bool, rateLimitCounters)
+	for _, r := range responseRules {
+		switch r.Type {
+		case "track-sc0":
+			used[0] = true
+		case "track-sc1":
+			used[1] = true
+		case "track-sc2":
+			used[2] = true
+		}
+	}
+	for _, r := range httpRules {
+		if sc, _, _ := httpTrackRule(r); sc >= 0 {
+			used[sc] = true
+		}
+	}
+	for _, r := range tcpRules {
+		if sc, _, _ := tcpTrackRule(r); sc >= 0 {
+			used[sc] = true
+		}
+	}
+	for sc, u := range used {
+		if !u {
+			return sc, nil
+		}
+	}
+	return 0, fmt.Errorf("all %d sticky counters are already tracked", rateLimitCounters)
+}
+
+// GetRateLimitPolicies returns configuration version and an array of rate limit
+// policies applied to the frontend. Returns error on fail.
+func (c *Client) GetRateLimitPolicies(frontend string, transactionID string) (int64, RateLimitPolicies, error) {
+	v, httpRules, err := c.GetHTTPRequestRules("frontend", frontend, transactionID)
+	if err != nil {
+		return 0, nil, err
+	}
+	_, tcpRules, err := c.GetTCPRequestRules("frontend", frontend, transactionID)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	policies := RateLimitPolicies{}
+	for _, r := range httpRules {
+		if r.Type == "deny" || r.Type == "tarpit" {
+			if rl := c.parseRateLimitPolicy("http", r.Type, r.DenyStatus, r.CondTest, transactionID); rl != nil {
+				policies = append(policies, rl)
+			}
+		}
+	}
+	for _, r := range tcpRules {
+		if r.Type == "connection" && r.Action == "reject" {
+			if rl := c.parseRateLimitPolicy("tcp", r.Action, nil, r.CondTest, transactionID); rl != nil {
+				policies = append(policies, rl)
+			}
+		}
+	}
+	for _, rl := range policies {
+		for _, r := range httpRules {
+			if sc, key, table := httpTrackRule(r); sc >= 0 && table == rl.Name {
+				rl.Key = key
+			}
+		}
+		for _, r := range tcpRules {
+			if sc, key, table := tcpTrackRule(r); sc >= 0 && table == rl.Name {
+				rl.Key = key
+			}
+		}
+	}
+	return v, policies, nil
+}
+
+// GetRateLimitPolicy returns configuration version and a requested rate limit policy
+// applied to the frontend. Returns error on fail or if policy does not exist.
+func (c *Client) GetRateLimitPolicy(name string, frontend string, transactionID string) (int64, *RateLimitPolicy, error) {
+	v, policies, err := c.GetRateLimitPolicies(frontend, transactionID)
+	if err != nil {
+		return 0, nil, err
+	}
+	for _, rl := range policies {
+		if rl.Name == name {
+			return v, rl, nil
+		}
+	}
+	return v, nil, NewConfError(ErrObjectDoesNotExist, fmt.Sprintf("Rate limit policy %s does not exist in frontend %s", name, frontend))
+}
+
+// CreateRateLimitPolicy creates the stick table backend, the track rule and the deny rule of
+// a rate limit policy in one transaction. One of version or transactionID is mandatory.
+// Returns error on fail, nil on success.
+func (c *Client) CreateRateLimitPolicy(frontend string, data *RateLimitPolicy, transactionID string, version int64) error {
+	if err := validateProtectionReservedName(data.Name); err != nil {
+		return err
+	}
+	return c.createRateLimitPolicy("CreateRateLimitPolicy", frontend, data, transactionID, version)
+}
+
+func (c *Client) createRateLimitPolicy(operation string, frontend string, data *RateLimitPolicy, transactionID string, version int64) error {
+	var res []error
+	if c.UseValidation {
+		validationErr := data.Validate(strfmt.Default)
+		if validationErr != nil {
+			return NewConfError(ErrValidationError, validationErr.Error())
+		}
+	}
+
+	if err := c.authorize(operation, "rate_limit_policy", data.Name, "frontend", frontend, transactionID); err != nil {
+		return err
+	}
+
+	p, t, err := c.loadDataForChange(transactionID, version)
+	if err != nil {
+		return err
+	}
+
+	if _, _, err := c.GetRateLimitPolicy(data.Name, frontend, t); err == nil {
+		e := NewConfError(ErrObjectAlreadyExists, fmt.Sprintf("Rate limit policy %s already exists in frontend %s", data.Name, frontend))
+		return c.handleError(data.Name, "frontend", frontend, t, transactionID == "", e)
+	}
+
+	_, httpRules, err := c.GetHTTPRequestRules("frontend", frontend, t)
+	if err != nil {
+		return c.handleError(data.Name, "frontend", frontend, t, transactionID == "", err)
+	}
+	_, tcpRules, err := c.GetTCPRequestRules("frontend", frontend, t)
+	if err != nil {
+		return c.handleError(data.Name, "frontend", frontend, t, transactionID == "", err)
+	}
+	_, responseRules, err := c.GetHTTPResponseRules("frontend", frontend, t)
+	if err != nil {
+		return c.handleError(data.Name, "frontend", frontend, t, transactionID == "", err)
+	}
+	sc, err := freeRateLimitCounter(httpRules, responseRules, tcpRules)
+	if err != nil {
+		e := NewConfError(ErrValidationError, fmt.Sprintf("Cannot add rate limit policy %s to frontend %s: %s", data.Name, frontend, err.Error()))
+		return c.handleError(data.Name, "frontend", frontend, t, transactionID == "", e)
+	}
+	trackAction := fmt.Sprintf("track-sc%d", sc)
+
+	tableType := data.TableType
+	if tableType == "" {
+		tableType = "ip"
+	}
+	backend := &models.Backend{
+		Name: data.Name,
+		StickTable: &models.BackendStickTable{
+			Type:   tableType,
+			Size:   data.TableSize,
+			Expire: data.Expire,
+			Peers:  data.Peers,
+			Store:  fmt.Sprintf("%s(%d)", data.counter(), data.Period),
+		},
+	}
+	if err := c.CreateBackend(backend, t, 0); err != nil {
+		res = append(res, err)
+	}
+
+	// rate limiting goes before any other rule of the frontend
+	track := int64(0)
+	limit := int64(1)
+	if data.layer() == "tcp" {
+		err = c.CreateTCPRequestRule("frontend", frontend, &models.TCPRequestRule{
+			Index:      &track,
+			Type:       "connection",
+			Action:     trackAction,
+			TrackKey:   data.key(),
+			TrackTable: data.Name,
+		}, t, 0)
+		if err != nil {
+			res = append(res, err)
+		}
+		err = c.CreateTCPRequestRule("frontend", frontend, &models.TCPRequestRule{
+			Index:    &limit,
+			Type:     "connection",
+			Action:   "reject",
+			Cond:     "if",
+			CondTest: data.condTest(sc),
+		}, t, 0)
+		if err != nil {
+			res = append(res, err)
+		}
+	} else {
+		trackRule := &models.HTTPRequestRule{
+			Index: &track,
+			Type:  trackAction,
+		}
+		switch sc {
+		case 0:
+			trackRule.TrackSc0Key, trackRule.TrackSc0Table = data.key(), data.Name
+		case 1:
+			trackRule.TrackSc1Key, trackRule.TrackSc1Table = data.key(), data.Name
+		case 2:
+			trackRule.TrackSc2Key, trackRule.TrackSc2Table = data.key(), data.Name
+		}
+		err = c.CreateHTTPRequestRule("frontend", frontend, trackRule, t, 0)
+		if err != nil {
+			res = append(res, err)
+		}
+		action := data.Action
+		if action == "" {
+			action = "deny"
+		}
+		err = c.CreateHTTPRequestRule("frontend", frontend, &models.HTTPRequestRule{
+			Index:      &limit,
+			Type:       action,
+			DenyStatus: data.DenyStatus,
+			Cond:       "if",
+			CondTest:   data.condTest(sc),
+		}, t, 0)
+		if err != nil {
+			res = append(res, err)
+		}
+	}
+
+	if len(res) > 0 {
+		return c.handleError(data.Name, "frontend", frontend, t, transactionID == "", CompositeTransactionError(res...))
+	}
+
+	if err := c.saveData(p, t, transactionID == ""); err != nil {
+		return err
+	}
+	return nil
+}
+
+// DeleteRateLimitPolicy deletes the rules and the stick table backend of a rate limit policy
+// in one transaction. One of version or transactionID is mandatory. Returns error on fail,
+// nil on success.
+func (c *Client) DeleteRateLimitPolicy(name string, frontend string, transactionID string, version int64) error {
+	var res []error
+	if err := c.authorize("DeleteRateLimitPolicy", "rate_limit_policy", name, "frontend", frontend, transactionID); err != nil {
+		return err
+	}
+
+	p, t, err := c.loadDataForChange(transactionID, version)
+	if err != nil {
+		return err
+	}
+
+	if _, _, err := c.GetRateLimitPolicy(name, frontend, t); err != nil {
+		return c.handleError(name, "frontend", frontend, t, transactionID == "", err)
+	}
+
+	_, httpRules, err := c.GetHTTPRequestRules("frontend", frontend, t)
+	if err != nil {
+		return c.handleError(name, "frontend", frontend, t, transactionID == "", err)
+	}
+	// delete from the end so the remaining indexes stay valid
+	for i := len(httpRules) - 1; i >= 0; i-- {
+		r := httpRules[i]
+		if sc, _, table := httpTrackRule(r); (sc >= 0 && table == name) || rateLimitRuleOf(r.CondTest) == name {
+			if err := c.DeleteHTTPRequestRule(*r.Index, "frontend", frontend, t, 0); err != nil {
+				res = append(res, err)
+			}
+		}
+	}
+
+	_, tcpRules, err := c.GetTCPRequestRules("frontend", frontend, t)
+	if err != nil {
+		return c.handleError(name, "frontend", frontend, t, transactionID == "", err)
+	}
+	for i := len(tcpRules) - 1; i >= 0; i-- {
+		r := tcpRules[i]
+		if sc, _, table := tcpTrackRule(r); (sc >= 0 && table == name) || rateLimitRuleOf(r.CondTest) == name {
+			if err := c.DeleteTCPRequestRule(*r.Index, "frontend", frontend, t, 0); err != nil {
+				res = append(res, err)
+			}
+		}
+	}
+
+	if err := c.DeleteBackend(name, t, 0); err != nil {
+		res = append(res, err)
+	}
+
+	if len(res) > 0 {
+		return c.handleError(name, "frontend", frontend, t, transactionID == "", CompositeTransactionError(res...))
+	}
+
+	if err := c.saveData(p, t, transactionID == ""); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (c *Client) parseRateLimitPolicy(layer, action string, denyStatus *int64, condTest, transactionID string) *RateLimitPolicy {
+	m := rateLimitCondRegex.FindStringSubmatch(condTest)
+	if m == nil {
+		return nil
+	}
+	_, backend, err := c.GetBackend(m[3], transactionID)
+	if err != nil || backend.StickTable == nil {
+		return nil
+	}
+	limit, _ := strconv.ParseInt(m[4], 10, 64)
+
+	rl := &RateLimitPolicy{
+		Name:       m[3],
+		Layer:      layer,
+		TableType:  backend.StickTable.Type,
+		TableSize:  backend.StickTable.Size,
+		Expire:     backend.StickTable.Expire,
+		Peers:      backend.StickTable.Peers,
+		Limit:      limit,
+		Action:     action,
+		DenyStatus: denyStatus,
+	}
+	if period := regexp.MustCompile(m[1] + `\((\d+)\)`).FindStringSubmatch(backend.StickTable.Store); period != nil {
+		rl.Period, _ = strconv.ParseInt(period[1], 10, 64)
+	}
+	return rl
+}
+
+func rateLimitRuleOf(condTest string) string {
+	m := rateLimitCondRegex.FindStringSubmatch(condTest)
+	if m == nil {
+		return ""
+	}
+	return m[3]
+}
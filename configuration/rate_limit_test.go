@@ -0,0 +1,143 @@
+// Copyright 2019 HAProxy Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package configuration
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestCreateGetDeleteRateLimitPolicy(t *testing.T) {
+	size := int64(100000)
+	expire := int64(30000)
+	status := int64(429)
+	rl := &RateLimitPolicy{
+		Name:       "rl_test",
+		Layer:      "http",
+		Key:        "src",
+		TableType:  "ip",
+		TableSize:  &size,
+		Expire:     &expire,
+		Limit:      100,
+		Period:     10000,
+		Action:     "deny",
+		DenyStatus: &status,
+	}
+
+	err := client.CreateRateLimitPolicy("test_2", rl, "", version)
+	if err != nil {
+		t.Error(err.Error())
+	} else {
+		version++
+	}
+
+	v, policy, err := client.GetRateLimitPolicy("rl_test", "test_2", "")
+	if err != nil {
+		t.Error(err.Error())
+	}
+
+	if !reflect.DeepEqual(policy, rl) {
+		fmt.Printf("Created rate limit policy: %v\n", policy)
+		fmt.Printf("Given rate limit policy: %v\n", rl)
+		t.Error("Created rate limit policy not equal to given rate limit policy")
+	}
+
+	if v != version {
+		t.Errorf("Version %v returned, expected %v", v, version)
+	}
+
+	err = client.CreateRateLimitPolicy("test_2", rl, "", version)
+	if err == nil {
+		t.Error("Should throw error rate limit policy already exists")
+		version++
+	}
+
+	err = client.DeleteRateLimitPolicy("rl_test", "test_2", "", version)
+	if err != nil {
+		t.Error(err.Error())
+	} else {
+		version++
+	}
+
+	if v, _ := client.GetVersion(""); v != version {
+		t.Error("Version not incremented")
+	}
+
+	_, _, err = client.GetRateLimitPolicy("rl_test", "test_2", "")
+	if err == nil {
+		t.Error("DeleteRateLimitPolicy failed, rate limit policy rl_test still exists")
+	}
+
+	_, _, err = client.GetBackend("rl_test", "")
+	if err == nil {
+		t.Error("DeleteRateLimitPolicy failed, backend rl_test still exists")
+	}
+}
+
+func TestRateLimitPolicyCounters(t *testing.T) {
+	names := []string{"rl_sc_a", "rl_sc_b", "rl_sc_c"}
+	for _, name := range names {
+		rl := &RateLimitPolicy{Name: name, Limit: 10, Period: 1000}
+		if err := client.CreateRateLimitPolicy("test_2", rl, "", version); err != nil {
+			t.Error(err.Error())
+		} else {
+			version++
+		}
+	}
+
+	// every policy tracks its own sticky counter
+	_, rules, err := client.GetHTTPRequestRules("frontend", "test_2", "")
+	if err != nil {
+		t.Error(err.Error())
+	}
+	used := map[int]string{}
+	for _, r := range rules {
+		if sc, _, table := httpTrackRule(r); sc >= 0 {
+			if other, ok := used[sc]; ok {
+				t.Errorf("Sticky counter %v tracked by %s and %s", sc, other, table)
+			}
+			used[sc] = table
+		}
+	}
+	if len(used) != len(names) {
+		t.Errorf("%v sticky counters tracked, expected %v", len(used), len(names))
+	}
+	_, policies, err := client.GetRateLimitPolicies("test_2", "")
+	if err != nil {
+		t.Error(err.Error())
+	}
+	if len(policies) != len(names) {
+		t.Errorf("%v rate limit policies returned, expected %v", len(policies), len(names))
+	}
+
+	rl := &RateLimitPolicy{Name: "rl_sc_d", Limit: 10, Period: 1000}
+	if err := client.CreateRateLimitPolicy("test_2", rl, "", version); err == nil {
+		t.Error("Should throw error, no sticky counter left")
+		version++
+	}
+
+	for _, name := range names {
+		if err := client.DeleteRateLimitPolicy(name, "test_2", "", version); err != nil {
+			t.Error(err.Error())
+		} else {
+			version++
+		}
+	}
+	if _, _, err := client.GetBackend("rl_sc_d", ""); err == nil {
+		t.Error("Backend rl_sc_d should not be created")
+	}
+}
@@ -72,6 +72,14 @@ func (c *Client) GetRawConfiguration(transactionID string, version int64) (int64
 		return ondiskV, "", NewConfError(ErrCannotReadConfFile, err.Error())
 	}
 
+	// the configuration file holds the version only with the comment version strategy
+	if transactionID == "" && version == 0 {
+		ondiskV, err = c.GetVersion("")
+		if err != nil {
+			return 0, "", err
+		}
+	}
+
 	return ondiskV, dataStr, nil
 }
 
@@ -98,6 +106,12 @@ func (c *Client) PostRawConfiguration(config *string, version int64, skipVersion
 		}
 		return nil
 	}
+	if _, _, err := splitDefaultsSections(*config); err != nil {
+		return err
+	}
+	if err := c.authorizeRaw("PostRawConfiguration", *config, ""); err != nil {
+		return err
+	}
 	t := ""
 	if skipVersionCheck {
 		// Create impicit transaction
@@ -143,9 +157,11 @@ func (c *Client) PostRawConfiguration(config *string, version int64, skipVersion
 		return err
 	}
 
-	if err := p.LoadData(tFile); err != nil {
+	c.auditSnapshot(p, t)
+	if err := loadConfig(p, tFile); err != nil {
 		return NewConfError(ErrCannotReadConfFile, fmt.Sprintf("Cannot read %s", tFile))
 	}
+	c.auditChange(p, t)
 
 	// Do a regular commit of the transaction
 	if _, err := c.commitTransaction(t, skipVersionCheck); err != nil {
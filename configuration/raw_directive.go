@@ -0,0 +1,167 @@
+// Copyright 2019 HAProxy Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package configuration
+
+import (
+	"strings"
+
+	parser "github.com/haproxytech/config-parser/v3"
+	parser_errors "github.com/haproxytech/config-parser/v3/errors"
+	"github.com/haproxytech/config-parser/v3/types"
+)
+
+// Directives without a dedicated parser are kept by the parser as unprocessed lines of their
+// section. The helpers below read and replace such directives by their keyword.
+
+// getRawDirective returns the value of the directive and true if the section has it
+func getRawDirective(p *parser.Parser, section parser.Section, name string, keyword string) (string, bool, error) {
+	lines, err := getRawLines(p, section, name)
+	if err != nil {
+		return "", false, err
+	}
+	for _, l := range lines {
+		if value, ok := matchRawDirective(l.Value, keyword); ok {
+			return value, true, nil
+		}
+	}
+	return "", false, nil
+}
+
+// setRawDirective replaces the directive in the section with the keyword followed by value, nil
+// value removes the directive
+func setRawDirective(p *parser.Parser, section parser.Section, name string, keyword string, value *string) error {
+	lines, err := getRawLines(p, section, name)
+	if err != nil {
+		return err
+	}
+
+	result := []types.UnProcessed{}
+	set := false
+	for _, l := range lines {
+		if _, ok := matchRawDirective(l.Value, keyword); !ok {
+			result = append(result, l)
+			continue
+		}
+		// keep the position of the first occurrence and drop duplicates
+		if value != nil && !set {
+			result = append(result, types.UnProcessed{Value: rawDirectiveLine(keyword, *value)})
+			set = true
+		}
+	}
+	if value != nil && !set {
+		result = append(result, types.UnProcessed{Value: rawDirectiveLine(keyword, *value)})
+	}
+
+	if len(result) == 0 {
+		return p.Set(section, name, "", nil)
+	}
+	return p.Set(section, name, "", result)
+}
+
+func getRawLines(p *parser.Parser, section parser.Section, name string) ([]types.UnProcessed, error) {
+	data, err := p.Get(section, name, "", false)
+	if err != nil {
+		if err == parser_errors.ErrFetch {
+			return []types.UnProcessed{}, nil
+		}
+		return nil, err
+	}
+	return data.([]types.UnProcessed), nil
+}
+
+// matchRawDirective returns the value of the line if it starts with all words of the keyword
+func matchRawDirective(line string, keyword string) (string, bool) {
+	fields := strings.Fields(line)
+	words := strings.Fields(keyword)
+	if len(fields) < len(words) {
+		return "", false
+	}
+	for i, w := range words {
+		if fields[i] != w {
+			return "", false
+		}
+	}
+	return strings.Join(fields[len(words):], " "), true
+}
+
+func rawDirectiveLine(keyword string, value string) string {
+	if value == "" {
+		return keyword
+	}
+	return keyword + " " + value
+}
+
+// getRawRules returns the lines of the section starting with the keyword, in order
+func getRawRules(p *parser.Parser, section parser.Section, name string, keyword string) ([]string, error) {
+	lines, err := getRawLines(p, section, name)
+	if err != nil {
+		return nil, err
+	}
+	rules := []string{}
+	for _, l := range lines {
+		if _, ok := matchRawDirective(l.Value, keyword); ok {
+			rules = append(rules, l.Value)
+		}
+	}
+	return rules, nil
+}
+
+// setRawRule inserts the line before the rule at the index, or after the last rule for the index
+// following it, replaces the rule at the index or deletes it when line is nil. Rules are the lines
+// starting with the keyword, the other lines of the section keep their place.
+func setRawRule(p *parser.Parser, section parser.Section, name string, keyword string, index int, line *string, insert bool) error {
+	lines, err := getRawLines(p, section, name)
+	if err != nil {
+		return err
+	}
+
+	positions := []int{}
+	for i, l := range lines {
+		if _, ok := matchRawDirective(l.Value, keyword); ok {
+			positions = append(positions, i)
+		}
+	}
+	count := len(positions)
+	if index < 0 || index > count || (!insert && index == count) {
+		return parser_errors.ErrIndexOutOfRange
+	}
+
+	var pos int
+	switch {
+	case index < count:
+		pos = positions[index]
+	case count > 0:
+		pos = positions[count-1] + 1
+	default:
+		pos = len(lines)
+	}
+
+	result := make([]types.UnProcessed, 0, len(lines)+1)
+	result = append(result, lines[:pos]...)
+	if line != nil {
+		result = append(result, types.UnProcessed{Value: *line})
+	}
+	if insert {
+		result = append(result, lines[pos:]...)
+	} else {
+		result = append(result, lines[pos+1:]...)
+	}
+
+	if len(result) == 0 {
+		return p.Set(section, name, "", nil)
+	}
+	return p.Set(section, name, "", result)
+}
@@ -0,0 +1,95 @@
+// Copyright 2019 HAProxy Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package configuration
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	parser "github.com/haproxytech/config-parser/v3"
+)
+
+// checkReferencedFiles returns error for the first ca-file, ca-verify-file or crl-file of binds and
+// servers which does not exist. Relative paths are resolved against ca-base and crl-base of the
+// global section.
+func checkReferencedFiles(p *parser.Parser) error {
+	caBase, _, err := getRawDirective(p, parser.Global, parser.GlobalSectionName, "ca-base")
+	if err != nil {
+		return err
+	}
+	crlBase, _, err := getRawDirective(p, parser.Global, parser.GlobalSectionName, "crl-base")
+	if err != nil {
+		return err
+	}
+	check := func(owner, keyword, base, file string) error {
+		if file == "" {
+			return nil
+		}
+		path := file
+		if !filepath.IsAbs(path) && base != "" {
+			path = filepath.Join(base, path)
+		}
+		if _, err := os.Stat(path); err != nil {
+			return fmt.Errorf("%s: %s %s does not exist", owner, keyword, path)
+		}
+		return nil
+	}
+
+	frontends, err := p.SectionsGet(parser.Frontends)
+	if err != nil {
+		return err
+	}
+	for _, frontend := range frontends {
+		binds, err := ParseBinds(frontend, p)
+		if err != nil {
+			return err
+		}
+		for _, b := range binds {
+			owner := fmt.Sprintf("frontend %s bind %s", frontend, b.Name)
+			if err := check(owner, "ca-file", caBase, b.SslCafile); err != nil {
+				return err
+			}
+			if err := check(owner, "ca-verify-file", caBase, b.CaVerifyFile); err != nil {
+				return err
+			}
+			if err := check(owner, "crl-file", crlBase, b.CrlFile); err != nil {
+				return err
+			}
+		}
+	}
+
+	backends, err := p.SectionsGet(parser.Backends)
+	if err != nil {
+		return err
+	}
+	for _, backend := range backends {
+		servers, err := ParseServers(backend, p)
+		if err != nil {
+			return err
+		}
+		for _, s := range servers {
+			owner := fmt.Sprintf("backend %s server %s", backend, s.Name)
+			if err := check(owner, "ca-file", caBase, s.SslCafile); err != nil {
+				return err
+			}
+			if err := check(owner, "crl-file", crlBase, s.CrlFile); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+// Copyright 2019 HAProxy Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package configuration
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func TestCheckReferencedFiles(t *testing.T) {
+	tr, err := client.StartTransaction(version)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	defer func() {
+		if err := client.DeleteTransaction(tr.ID); err != nil {
+			t.Error(err.Error())
+		}
+	}()
+
+	_, b, err := client.GetBind("webserv", "test", tr.ID)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	b.Ssl = true
+	b.SslCertificate = "dummy.crt"
+	b.SslCafile = "/nonexistent/ca.pem"
+	if err := client.EditBind("webserv", "test", b, tr.ID, 0); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	p, err := client.GetParser(tr.ID)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if err := checkReferencedFiles(p); err == nil {
+		t.Error("Should throw error, ca-file does not exist")
+	}
+
+	ca, err := ioutil.TempFile("", "ca")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	ca.Close()
+	defer os.Remove(ca.Name())
+
+	b.SslCafile = ca.Name()
+	if err := client.EditBind("webserv", "test", b, tr.ID, 0); err != nil {
+		t.Fatal(err.Error())
+	}
+	if err := checkReferencedFiles(p); err != nil {
+		t.Error(err.Error())
+	}
+}
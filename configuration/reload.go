@@ -0,0 +1,79 @@
+// Copyright 2019 HAProxy Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package configuration
+
+import (
+	"fmt"
+	"time"
+)
+
+// Reloader makes the running HAProxy load the configuration file, returning the output of the
+// reload
+type Reloader interface {
+	Reload() (string, error)
+}
+
+// ReloadResult is the result of the reload done after a commit
+type ReloadResult struct {
+	Transaction string
+	Version     int64
+	Time        time.Time
+	Output      string
+	Err         error
+}
+
+// SetReloader sets the reload strategy run after every successful commit, nil disables reloads
+func (c *Client) SetReloader(r Reloader) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.reloader = r
+}
+
+// LastReload returns the result of the last reload, nil if no reload was done
+func (c *Client) LastReload() *ReloadResult {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.lastReload
+}
+
+// reload runs the reloader after a commit, without holding the lock of the configuration.
+// Reloads run one at a time. The configuration stays committed when the reload fails, which is
+// returned as ErrReloadFailed.
+func (c *Client) reload(transactionID string, version int64) error {
+	c.mu.Lock()
+	reloader := c.reloader
+	c.mu.Unlock()
+	if reloader == nil {
+		return nil
+	}
+
+	c.reloadMu.Lock()
+	defer c.reloadMu.Unlock()
+	output, err := reloader.Reload()
+	c.mu.Lock()
+	c.lastReload = &ReloadResult{
+		Transaction: transactionID,
+		Version:     version,
+		Time:        time.Now(),
+		Output:      output,
+		Err:         err,
+	}
+	c.mu.Unlock()
+	if err != nil {
+		return NewConfError(ErrReloadFailed, fmt.Sprintf("configuration committed but reload failed: %s", err.Error()))
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+// Copyright 2019 HAProxy Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package configuration
+
+import (
+	"fmt"
+	"testing"
+)
+
+type testReloader struct {
+	reloads int
+	err     error
+}
+
+func (r *testReloader) Reload() (string, error) {
+	r.reloads++
+	// the configuration is not locked while reloading
+	client.LastReload()
+	return fmt.Sprintf("reload %d", r.reloads), r.err
+}
+
+func TestReloadAfterCommit(t *testing.T) {
+	r := &testReloader{}
+	client.SetReloader(r)
+	defer client.SetReloader(nil)
+
+	err := client.SetLogFormat(LogFormatDirective, "frontend", "test_2", `"%ci"`, "", version)
+	if err != nil {
+		t.Error(err.Error())
+	} else {
+		version++
+	}
+
+	if r.reloads != 1 {
+		t.Errorf("Reloaded %d times, expected 1", r.reloads)
+	}
+	if res := client.LastReload(); res == nil || res.Err != nil || res.Output != "reload 1" {
+		t.Errorf("Unexpected reload result: %v", res)
+	}
+
+	r.err = fmt.Errorf("reload refused")
+	err = client.SetLogFormat(LogFormatDirective, "frontend", "test_2", "", "", version)
+	// the change is committed even though the reload failed
+	version++
+	if err == nil {
+		t.Error("Should throw error, reload failed")
+	} else if confErr, ok := err.(*ConfError); !ok || confErr.Code() != ErrReloadFailed {
+		t.Errorf("Unexpected error: %v", err)
+	}
+
+	if res := client.LastReload(); res == nil || res.Err == nil {
+		t.Errorf("Reload error not recorded: %v", res)
+	}
+
+	_, _, err = client.GetLogFormat(LogFormatDirective, "frontend", "test_2", "")
+	if err == nil {
+		t.Error("Change not committed after failed reload")
+	}
+}
@@ -83,6 +83,10 @@ func (c *Client) GetResolver(name string, transactionID string) (int64, *models.
 // DeleteResolver deletes a resolver in configuration. One of version or transactionID is
 // mandatory. Returns error on fail, nil on success.
 func (c *Client) DeleteResolver(name string, transactionID string, version int64) error {
+	if err := c.authorize("DeleteResolver", "resolvers", name, "", "", transactionID); err != nil {
+		return err
+	}
+
 	p, t, err := c.loadDataForChange(transactionID, version)
 	if err != nil {
 		return err
@@ -114,6 +118,10 @@ func (c *Client) EditResolver(name string, data *models.Resolver, transactionID
 		}
 	}
 
+	if err := c.authorize("EditResolver", "resolvers", name, "", "", transactionID); err != nil {
+		return err
+	}
+
 	p, t, err := c.loadDataForChange(transactionID, version)
 	if err != nil {
 		return err
@@ -145,6 +153,10 @@ func (c *Client) CreateResolver(data *models.Resolver, transactionID string, ver
 		}
 	}
 
+	if err := c.authorize("CreateResolver", "resolvers", data.Name, "", "", transactionID); err != nil {
+		return err
+	}
+
 	p, t, err := c.loadDataForChange(transactionID, version)
 	if err != nil {
 		return err
@@ -0,0 +1,150 @@
+// Copyright 2019 HAProxy Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package configuration
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	parser "github.com/haproxytech/config-parser/v3"
+)
+
+const retryOnDirective = "retry-on"
+
+// RetryOnEventVersions are the HAProxy versions introducing the retry-on events
+var RetryOnEventVersions = map[string]string{
+	"none":                 "2.0",
+	"conn-failure":         "2.0",
+	"empty-response":       "2.0",
+	"junk-response":        "2.0",
+	"response-timeout":     "2.0",
+	"0rtt-rejected":        "2.0",
+	"404":                  "2.0",
+	"408":                  "2.0",
+	"425":                  "2.0",
+	"500":                  "2.0",
+	"501":                  "2.0",
+	"502":                  "2.0",
+	"503":                  "2.0",
+	"504":                  "2.0",
+	"all-retryable-errors": "2.0",
+	"401":                  "2.4",
+	"403":                  "2.4",
+}
+
+// GetRetryOn returns configuration version and the retry-on events of the defaults or backend
+// section. Returns error on fail or if retry-on is not set.
+func (c *Client) GetRetryOn(parentType string, parentName string, transactionID string) (int64, []string, error) {
+	section, name, err := retryOnSection(parentType, parentName)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	p, err := c.GetParser(transactionID)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	v, err := c.GetVersion(transactionID)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	if !c.checkSectionExists(section, name, p) {
+		return v, nil, NewConfError(ErrObjectDoesNotExist, fmt.Sprintf("%s %s does not exist", parentType, parentName))
+	}
+
+	value, found, err := getRawDirective(p, section, name, retryOnDirective)
+	if err != nil {
+		return v, nil, c.handleError(retryOnDirective, parentType, parentName, "", false, err)
+	}
+	if !found {
+		return v, nil, NewConfError(ErrObjectDoesNotExist, fmt.Sprintf("%s not set in %s %s", retryOnDirective, parentType, parentName))
+	}
+	return v, strings.Fields(value), nil
+}
+
+// SetRetryOn sets the retry-on events of the defaults or backend section, nil or empty events
+// remove it. Events are checked against the HAProxy version set with SetHAProxyVersion. One of
+// version or transactionID is mandatory. Returns error on fail, nil on success.
+func (c *Client) SetRetryOn(parentType string, parentName string, events []string, transactionID string, version int64) error {
+	section, name, err := retryOnSection(parentType, parentName)
+	if err != nil {
+		return err
+	}
+	if err := validateRetryOn(events, c.haproxyVersion); err != nil {
+		return NewConfError(ErrValidationError, err.Error())
+	}
+
+	if err := c.authorize("SetRetryOn", "retry_on", "", parentType, parentName, transactionID); err != nil {
+		return err
+	}
+
+	p, t, err := c.loadDataForChange(transactionID, version)
+	if err != nil {
+		return err
+	}
+
+	if !c.checkSectionExists(section, name, p) {
+		e := NewConfError(ErrObjectDoesNotExist, fmt.Sprintf("%s %s does not exist", parentType, parentName))
+		return c.handleError(retryOnDirective, parentType, parentName, t, transactionID == "", e)
+	}
+
+	var value *string
+	if len(events) > 0 {
+		s := strings.Join(events, " ")
+		value = &s
+	}
+	if err := setRawDirective(p, section, name, retryOnDirective, value); err != nil {
+		return c.handleError(retryOnDirective, parentType, parentName, t, transactionID == "", err)
+	}
+
+	if err := c.saveData(p, t, transactionID == ""); err != nil {
+		return err
+	}
+	return nil
+}
+
+// validateRetryOn checks that the events are known, against the HAProxy version if not empty,
+// and that none is not combined with other events
+func validateRetryOn(events []string, haproxyVersion string) error {
+	for _, e := range events {
+		if _, ok := RetryOnEventVersions[e]; !ok {
+			known := make([]string, 0, len(RetryOnEventVersions))
+			for k := range RetryOnEventVersions {
+				known = append(known, k)
+			}
+			sort.Strings(known)
+			return fmt.Errorf("unknown %s event %s, expected one of %s", retryOnDirective, e, strings.Join(known, ", "))
+		}
+		if e == "none" && len(events) > 1 {
+			return fmt.Errorf("%s none can not be combined with other events", retryOnDirective)
+		}
+	}
+	return checkKeywordVersions(retryOnDirective, "event", events, RetryOnEventVersions, haproxyVersion)
+}
+
+func retryOnSection(parentType string, parentName string) (parser.Section, string, error) {
+	switch parentType {
+	case "defaults":
+		return parser.Defaults, parser.DefaultSectionName, nil
+	case "backend":
+		return parser.Backends, parentName, nil
+	default:
+		return "", "", NewConfError(ErrValidationError, fmt.Sprintf("%s is not supported in %s", retryOnDirective, parentType))
+	}
+}
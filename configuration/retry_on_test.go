@@ -0,0 +1,64 @@
+// Copyright 2019 HAProxy Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package configuration
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRetryOn(t *testing.T) {
+	if err := client.SetRetryOn("backend", "test", []string{"conn-failure", "timeout"}, "", version); err == nil {
+		t.Error("Should throw error, unknown retry-on event")
+	}
+	if err := client.SetRetryOn("backend", "test", []string{"none", "503"}, "", version); err == nil {
+		t.Error("Should throw error, none combined with other events")
+	}
+	if err := client.SetHAProxyVersion("2.2"); err != nil {
+		t.Fatal(err.Error())
+	}
+	if err := client.SetRetryOn("backend", "test", []string{"401"}, "", version); err == nil {
+		t.Error("Should throw error, retry-on 401 requires HAProxy 2.4")
+	}
+	client.SetHAProxyVersion("")
+
+	events := []string{"conn-failure", "empty-response", "503"}
+	if err := client.SetRetryOn("backend", "test", events, "", version); err != nil {
+		t.Error(err.Error())
+	} else {
+		version++
+	}
+
+	v, retryOn, err := client.GetRetryOn("backend", "test", "")
+	if err != nil {
+		t.Error(err.Error())
+	}
+	if !reflect.DeepEqual(retryOn, events) {
+		t.Errorf("retry-on %v not equal to given %v", retryOn, events)
+	}
+	if v != version {
+		t.Errorf("Version %v returned, expected %v", v, version)
+	}
+
+	if err := client.SetRetryOn("backend", "test", nil, "", version); err != nil {
+		t.Error(err.Error())
+	} else {
+		version++
+	}
+	if _, _, err := client.GetRetryOn("backend", "test", ""); err == nil {
+		t.Error("Should throw error, retry-on removed from backend test")
+	}
+}
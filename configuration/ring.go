@@ -0,0 +1,338 @@
+// Copyright 2019 HAProxy Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package configuration
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	parser "github.com/haproxytech/config-parser/v3"
+	"github.com/haproxytech/config-parser/v3/params"
+	"github.com/haproxytech/config-parser/v3/types"
+	"github.com/haproxytech/models/v2"
+
+	"github.com/haproxytech/client-native/v2/misc"
+)
+
+var ringFormats = []string{"iso", "raw", "rfc3164", "rfc5424", "short", "timed", "timestamp"}
+
+// ringKeywords are the directives of a ring section managed by Ring, other lines are kept as is
+var ringKeywords = []string{"format", "maxlen", "size", "timeout connect", "timeout server", "server"}
+
+// Ring is a ring section, an in-memory buffer logs are sent to with log ring@<name> and
+// forwarded from to the servers of the section. Requires HAProxy 2.2.
+type Ring struct {
+	Name   string `json:"name"`
+	Format string `json:"format,omitempty"`
+	// Maxlen is the maximum length of a message in bytes
+	Maxlen *int64 `json:"maxlen,omitempty"`
+	// Size is the size of the ring buffer in bytes
+	Size *int64 `json:"size,omitempty"`
+	// TimeoutConnect is the timeout to connect to a server in milliseconds
+	TimeoutConnect *int64 `json:"timeout_connect,omitempty"`
+	// TimeoutServer is the timeout to send messages to a server in milliseconds
+	TimeoutServer *int64 `json:"timeout_server,omitempty"`
+	// Servers the messages are forwarded to over TCP
+	Servers []*models.Server `json:"servers,omitempty"`
+}
+
+// Validate checks the ring settings and servers
+func (r *Ring) Validate() error {
+	if r.Name == "" || strings.ContainsAny(r.Name, " \t#@") {
+		return fmt.Errorf("invalid ring name %s", r.Name)
+	}
+	if r.Format != "" && !misc.StringInSlice(r.Format, ringFormats) {
+		return fmt.Errorf("ring %s: format must be one of %s", r.Name, strings.Join(ringFormats, ", "))
+	}
+	for keyword, v := range map[string]*int64{
+		"maxlen":          r.Maxlen,
+		"size":            r.Size,
+		"timeout connect": r.TimeoutConnect,
+		"timeout server":  r.TimeoutServer,
+	} {
+		if v != nil && *v <= 0 {
+			return fmt.Errorf("ring %s: %s has to be greater than 0", r.Name, keyword)
+		}
+	}
+	names := map[string]bool{}
+	for _, s := range r.Servers {
+		if s.Name == "" || strings.ContainsAny(s.Name, " \t#") {
+			return fmt.Errorf("ring %s: invalid server name %s", r.Name, s.Name)
+		}
+		if names[s.Name] {
+			return fmt.Errorf("ring %s: server %s set more than once", r.Name, s.Name)
+		}
+		names[s.Name] = true
+		address := s.Address
+		if err := validateAddressPort("server", s.Name, &address, s.Port); err != nil {
+			return fmt.Errorf("ring %s: %s", r.Name, err.Error())
+		}
+	}
+	return nil
+}
+
+// GetRings returns configuration version and an array of
+// configured rings. Returns error on fail.
+func (c *Client) GetRings(transactionID string) (int64, []*Ring, error) {
+	p, err := c.GetParser(transactionID)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	v, err := c.GetVersion(transactionID)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	names, err := p.SectionsGet(parser.Ring)
+	if err != nil {
+		return v, nil, err
+	}
+
+	rings := []*Ring{}
+	for _, name := range names {
+		r, err := ParseRing(p, name)
+		if err != nil {
+			return v, nil, c.handleError(name, "", "", "", false, err)
+		}
+		rings = append(rings, r)
+	}
+
+	return v, rings, nil
+}
+
+// GetRing returns configuration version and a requested ring.
+// Returns error on fail or if ring does not exist.
+func (c *Client) GetRing(name string, transactionID string) (int64, *Ring, error) {
+	p, err := c.GetParser(transactionID)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	v, err := c.GetVersion(transactionID)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	if !c.checkSectionExists(parser.Ring, name, p) {
+		return v, nil, NewConfError(ErrObjectDoesNotExist, fmt.Sprintf("Ring %s does not exist", name))
+	}
+
+	r, err := ParseRing(p, name)
+	if err != nil {
+		return v, nil, c.handleError(name, "", "", "", false, err)
+	}
+
+	return v, r, nil
+}
+
+// DeleteRing deletes a ring in configuration. Rings logs are sent to can not be deleted. One of
+// version or transactionID is mandatory. Returns error on fail, nil on success.
+func (c *Client) DeleteRing(name string, transactionID string, version int64) error {
+	if err := c.authorize("DeleteRing", "ring", name, "", "", transactionID); err != nil {
+		return err
+	}
+
+	p, t, err := c.loadDataForChange(transactionID, version)
+	if err != nil {
+		return err
+	}
+
+	if !c.checkSectionExists(parser.Ring, name, p) {
+		e := NewConfError(ErrObjectDoesNotExist, fmt.Sprintf("%s %s does not exist", parser.Ring, name))
+		return c.handleError(name, "", "", t, transactionID == "", e)
+	}
+
+	r := regexp.MustCompile(`ring@` + regexp.QuoteMeta(name) + `(\s|$)`)
+	if r.MatchString(configString(p)) {
+		e := NewConfError(ErrValidationError, fmt.Sprintf("%s %s is used as a log target", parser.Ring, name))
+		return c.handleError(name, "", "", t, transactionID == "", e)
+	}
+
+	if err := p.SectionsDelete(parser.Ring, name); err != nil {
+		return c.handleError(name, "", "", t, transactionID == "", err)
+	}
+
+	if err := c.saveData(p, t, transactionID == ""); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// CreateRing creates a ring in configuration. One of version or transactionID is mandatory.
+// Returns error on fail, nil on success.
+func (c *Client) CreateRing(data *Ring, transactionID string, version int64) error {
+	if err := data.Validate(); err != nil {
+		return NewConfError(ErrValidationError, err.Error())
+	}
+
+	if err := c.authorize("CreateRing", "ring", data.Name, "", "", transactionID); err != nil {
+		return err
+	}
+
+	p, t, err := c.loadDataForChange(transactionID, version)
+	if err != nil {
+		return err
+	}
+
+	if err := p.SectionsCreate(parser.Ring, data.Name); err != nil {
+		return c.handleError(data.Name, "", "", t, transactionID == "", err)
+	}
+	if err := SerializeRing(p, data); err != nil {
+		return c.handleError(data.Name, "", "", t, transactionID == "", err)
+	}
+
+	if err := c.saveData(p, t, transactionID == ""); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// EditRing replaces the settings and servers of a ring in configuration. One of version or
+// transactionID is mandatory. Returns error on fail, nil on success.
+func (c *Client) EditRing(name string, data *Ring, transactionID string, version int64) error {
+	if err := data.Validate(); err != nil {
+		return NewConfError(ErrValidationError, err.Error())
+	}
+	if data.Name != name {
+		return NewConfError(ErrValidationError, fmt.Sprintf("ring %s can not be renamed to %s", name, data.Name))
+	}
+
+	if err := c.authorize("EditRing", "ring", name, "", "", transactionID); err != nil {
+		return err
+	}
+
+	p, t, err := c.loadDataForChange(transactionID, version)
+	if err != nil {
+		return err
+	}
+
+	if !c.checkSectionExists(parser.Ring, name, p) {
+		e := NewConfError(ErrObjectDoesNotExist, fmt.Sprintf("%s %s does not exist", parser.Ring, name))
+		return c.handleError(name, "", "", t, transactionID == "", e)
+	}
+
+	if err := SerializeRing(p, data); err != nil {
+		return c.handleError(name, "", "", t, transactionID == "", err)
+	}
+
+	if err := c.saveData(p, t, transactionID == ""); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// ParseRing returns the ring, the directives of ring sections have no parser and are kept as
+// unprocessed lines
+func ParseRing(p *parser.Parser, name string) (*Ring, error) {
+	r := &Ring{Name: name}
+	lines, err := getRawLines(p, parser.Ring, name)
+	if err != nil {
+		return nil, err
+	}
+	for _, l := range lines {
+		if value, ok := matchRawDirective(l.Value, "format"); ok {
+			r.Format = value
+		} else if value, ok := matchRawDirective(l.Value, "maxlen"); ok {
+			r.Maxlen = parseRingInt(value)
+		} else if value, ok := matchRawDirective(l.Value, "size"); ok {
+			r.Size = misc.ParseSize(value)
+		} else if value, ok := matchRawDirective(l.Value, "timeout connect"); ok {
+			r.TimeoutConnect = misc.ParseTimeout(value)
+		} else if value, ok := matchRawDirective(l.Value, "timeout server"); ok {
+			r.TimeoutServer = misc.ParseTimeout(value)
+		} else if value, ok := matchRawDirective(l.Value, "server"); ok {
+			words := strings.Fields(value)
+			if len(words) < 2 {
+				continue
+			}
+			s := ParseServer(types.Server{
+				Name:    words[0],
+				Address: words[1],
+				Params:  params.ParseServerOptions(words[2:]),
+			})
+			if s != nil {
+				r.Servers = append(r.Servers, s)
+			}
+		}
+	}
+	return r, nil
+}
+
+func SerializeRing(p *parser.Parser, data *Ring) error {
+	lines, err := getRawLines(p, parser.Ring, data.Name)
+	if err != nil {
+		return err
+	}
+	result := []types.UnProcessed{}
+	for _, l := range lines {
+		managed := false
+		for _, keyword := range ringKeywords {
+			if _, ok := matchRawDirective(l.Value, keyword); ok {
+				managed = true
+				break
+			}
+		}
+		if !managed {
+			result = append(result, l)
+		}
+	}
+
+	add := func(keyword string, value string) {
+		result = append(result, types.UnProcessed{Value: rawDirectiveLine(keyword, value)})
+	}
+	if data.Format != "" {
+		add("format", data.Format)
+	}
+	if data.Maxlen != nil {
+		add("maxlen", strconv.FormatInt(*data.Maxlen, 10))
+	}
+	if data.Size != nil {
+		add("size", strconv.FormatInt(*data.Size, 10))
+	}
+	if data.TimeoutConnect != nil {
+		add("timeout connect", strconv.FormatInt(*data.TimeoutConnect, 10))
+	}
+	if data.TimeoutServer != nil {
+		add("timeout server", strconv.FormatInt(*data.TimeoutServer, 10))
+	}
+	for _, s := range data.Servers {
+		srv := SerializeServer(*s)
+		value := srv.Name + " " + srv.Address
+		if options := params.ServerOptionsString(srv.Params); options != "" {
+			value += " " + options
+		}
+		add("server", value)
+	}
+
+	if len(result) == 0 {
+		return p.Set(parser.Ring, data.Name, "", nil)
+	}
+	return p.Set(parser.Ring, data.Name, "", result)
+}
+
+func parseRingInt(value string) *int64 {
+	v, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return nil
+	}
+	return &v
+}
@@ -0,0 +1,118 @@
+// Copyright 2019 HAProxy Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package configuration
+
+import (
+	"testing"
+
+	"github.com/haproxytech/models/v2"
+
+	"github.com/haproxytech/client-native/v2/misc"
+)
+
+func TestCreateEditDeleteRing(t *testing.T) {
+	r := &Ring{
+		Name:           "logbuffer",
+		Format:         "syslog",
+		Maxlen:         misc.Int64P(1200),
+		Size:           misc.Int64P(32764),
+		TimeoutConnect: misc.Int64P(5000),
+		TimeoutServer:  misc.Int64P(10000),
+		Servers: []*models.Server{
+			{Name: "log1", Address: "10.0.0.10", Port: misc.Int64P(6514)},
+		},
+	}
+	if err := client.CreateRing(r, "", version); err == nil {
+		t.Error("Should throw error, syslog is not a ring format")
+		version++
+	}
+	r.Format = "rfc5424"
+	r.Servers = append(r.Servers, &models.Server{Name: "log2", Address: "10.0.0.11"})
+	if err := client.CreateRing(r, "", version); err == nil {
+		t.Error("Should throw error, server log2 has no port")
+		version++
+	}
+
+	r.Servers[1].Port = misc.Int64P(6514)
+	if err := client.CreateRing(r, "", version); err != nil {
+		t.Fatal(err.Error())
+	}
+	version++
+
+	v, ring, err := client.GetRing("logbuffer", "")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if v != version {
+		t.Errorf("Version %v returned, expected %v", v, version)
+	}
+	checkRing(t, ring, r)
+
+	r.Maxlen = nil
+	r.TimeoutServer = misc.Int64P(30000)
+	r.Servers = r.Servers[:1]
+	if err := client.EditRing("logbuffer", r, "", version); err != nil {
+		t.Error(err.Error())
+	} else {
+		version++
+	}
+	_, rings, err := client.GetRings("")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if len(rings) != 1 {
+		t.Fatalf("%v rings returned, expected 1", len(rings))
+	}
+	checkRing(t, rings[0], r)
+
+	if err := client.DeleteRing("logbuffer", "", version); err != nil {
+		t.Error(err.Error())
+	} else {
+		version++
+	}
+	if _, _, err := client.GetRing("logbuffer", ""); err == nil {
+		t.Error("DeleteRing failed, ring logbuffer still exists")
+	}
+	if err := client.DeleteRing("logbuffer", "", version); err == nil {
+		t.Error("Should throw error, ring logbuffer does not exist")
+		version++
+	}
+}
+
+func checkRing(t *testing.T, got *Ring, expected *Ring) {
+	if got.Name != expected.Name || got.Format != expected.Format {
+		t.Errorf("Ring %s %s returned, expected %s %s", got.Name, got.Format, expected.Name, expected.Format)
+	}
+	for name, v := range map[string][2]*int64{
+		"maxlen":          {got.Maxlen, expected.Maxlen},
+		"size":            {got.Size, expected.Size},
+		"timeout connect": {got.TimeoutConnect, expected.TimeoutConnect},
+		"timeout server":  {got.TimeoutServer, expected.TimeoutServer},
+	} {
+		if (v[0] == nil) != (v[1] == nil) || (v[0] != nil && *v[0] != *v[1]) {
+			t.Errorf("Ring %s: unexpected %s", got.Name, name)
+		}
+	}
+	if len(got.Servers) != len(expected.Servers) {
+		t.Fatalf("Ring %s: %v servers returned, expected %v", got.Name, len(got.Servers), len(expected.Servers))
+	}
+	for i, s := range got.Servers {
+		e := expected.Servers[i]
+		if s.Name != e.Name || s.Address != e.Address || s.Port == nil || *s.Port != *e.Port {
+			t.Errorf("Ring %s: server %s returned, expected %s", got.Name, s.Name, e.Name)
+		}
+	}
+}
@@ -0,0 +1,259 @@
+// Copyright 2019 HAProxy Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package configuration
+
+import (
+	"fmt"
+	"regexp"
+
+	strfmt "github.com/go-openapi/strfmt"
+	parser "github.com/haproxytech/config-parser/v3"
+	parser_errors "github.com/haproxytech/config-parser/v3/errors"
+	"github.com/haproxytech/config-parser/v3/params"
+	"github.com/haproxytech/config-parser/v3/types"
+	"github.com/haproxytech/models/v2"
+)
+
+var runtimeAPIModeRegexp = regexp.MustCompile(`^[0-7]{3,4}$`)
+
+// GetRuntimeAPIs returns configuration version and an array of
+// configured stats sockets of the global section. Returns error on fail.
+func (c *Client) GetRuntimeAPIs(transactionID string) (int64, []*models.RuntimeAPI, error) {
+	p, err := c.GetParser(transactionID)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	v, err := c.GetVersion(transactionID)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	rAPIs, err := ParseRuntimeAPIs(p)
+	if err != nil {
+		return v, nil, c.handleError("", "global", "", "", false, err)
+	}
+
+	return v, rAPIs, nil
+}
+
+// GetRuntimeAPI returns configuration version and the stats socket
+// listening on address. Returns error on fail or if it does not exist.
+func (c *Client) GetRuntimeAPI(address string, transactionID string) (int64, *models.RuntimeAPI, error) {
+	v, rAPIs, err := c.GetRuntimeAPIs(transactionID)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	rAPI, _ := getRuntimeAPIByAddress(address, rAPIs)
+	if rAPI == nil {
+		return v, nil, NewConfError(ErrObjectDoesNotExist, fmt.Sprintf("Stats socket %s does not exist", address))
+	}
+
+	return v, rAPI, nil
+}
+
+// CreateRuntimeAPI adds a stats socket to the global section. One of version or
+// transactionID is mandatory. Returns error on fail, nil on success.
+func (c *Client) CreateRuntimeAPI(data *models.RuntimeAPI, transactionID string, version int64) error {
+	if err := c.validateRuntimeAPI(data); err != nil {
+		return err
+	}
+
+	if err := c.authorize("CreateRuntimeAPI", "runtime_api", *data.Address, "global", "", transactionID); err != nil {
+		return err
+	}
+
+	p, t, err := c.loadDataForChange(transactionID, version)
+	if err != nil {
+		return err
+	}
+
+	rAPIs, err := ParseRuntimeAPIs(p)
+	if err != nil {
+		return c.handleError(*data.Address, "global", "", t, transactionID == "", err)
+	}
+	if rAPI, _ := getRuntimeAPIByAddress(*data.Address, rAPIs); rAPI != nil {
+		e := NewConfError(ErrObjectAlreadyExists, fmt.Sprintf("Stats socket %s already exists", *data.Address))
+		return c.handleError(*data.Address, "global", "", t, transactionID == "", e)
+	}
+
+	if err := SerializeRuntimeAPIs(p, append(rAPIs, data)); err != nil {
+		return c.handleError(*data.Address, "global", "", t, transactionID == "", err)
+	}
+
+	if err := c.saveData(p, t, transactionID == ""); err != nil {
+		return err
+	}
+	return nil
+}
+
+// EditRuntimeAPI replaces the stats socket listening on address. One of version or
+// transactionID is mandatory. Returns error on fail, nil on success.
+func (c *Client) EditRuntimeAPI(address string, data *models.RuntimeAPI, transactionID string, version int64) error {
+	if err := c.validateRuntimeAPI(data); err != nil {
+		return err
+	}
+
+	if err := c.authorize("EditRuntimeAPI", "runtime_api", address, "global", "", transactionID); err != nil {
+		return err
+	}
+
+	p, t, err := c.loadDataForChange(transactionID, version)
+	if err != nil {
+		return err
+	}
+
+	rAPIs, err := ParseRuntimeAPIs(p)
+	if err != nil {
+		return c.handleError(address, "global", "", t, transactionID == "", err)
+	}
+	rAPI, i := getRuntimeAPIByAddress(address, rAPIs)
+	if rAPI == nil {
+		e := NewConfError(ErrObjectDoesNotExist, fmt.Sprintf("Stats socket %s does not exist", address))
+		return c.handleError(address, "global", "", t, transactionID == "", e)
+	}
+	if *data.Address != address {
+		if other, _ := getRuntimeAPIByAddress(*data.Address, rAPIs); other != nil {
+			e := NewConfError(ErrObjectAlreadyExists, fmt.Sprintf("Stats socket %s already exists", *data.Address))
+			return c.handleError(address, "global", "", t, transactionID == "", e)
+		}
+	}
+	rAPIs[i] = data
+
+	if err := SerializeRuntimeAPIs(p, rAPIs); err != nil {
+		return c.handleError(address, "global", "", t, transactionID == "", err)
+	}
+
+	if err := c.saveData(p, t, transactionID == ""); err != nil {
+		return err
+	}
+	return nil
+}
+
+// DeleteRuntimeAPI removes the stats socket listening on address. One of version or
+// transactionID is mandatory. Returns error on fail, nil on success.
+func (c *Client) DeleteRuntimeAPI(address string, transactionID string, version int64) error {
+	if err := c.authorize("DeleteRuntimeAPI", "runtime_api", address, "global", "", transactionID); err != nil {
+		return err
+	}
+
+	p, t, err := c.loadDataForChange(transactionID, version)
+	if err != nil {
+		return err
+	}
+
+	rAPIs, err := ParseRuntimeAPIs(p)
+	if err != nil {
+		return c.handleError(address, "global", "", t, transactionID == "", err)
+	}
+	rAPI, i := getRuntimeAPIByAddress(address, rAPIs)
+	if rAPI == nil {
+		e := NewConfError(ErrObjectDoesNotExist, fmt.Sprintf("Stats socket %s does not exist", address))
+		return c.handleError(address, "global", "", t, transactionID == "", e)
+	}
+
+	if err := SerializeRuntimeAPIs(p, append(rAPIs[:i], rAPIs[i+1:]...)); err != nil {
+		return c.handleError(address, "global", "", t, transactionID == "", err)
+	}
+
+	if err := c.saveData(p, t, transactionID == ""); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (c *Client) validateRuntimeAPI(data *models.RuntimeAPI) error {
+	if c.UseValidation {
+		if err := data.Validate(strfmt.Default); err != nil {
+			return NewConfError(ErrValidationError, err.Error())
+		}
+	}
+	if data.Address == nil || *data.Address == "" {
+		return NewConfError(ErrValidationError, "stats socket address is required")
+	}
+	if data.Mode != "" && !runtimeAPIModeRegexp.MatchString(data.Mode) {
+		return NewConfError(ErrValidationError, fmt.Sprintf("stats socket %s: invalid mode %s, expected octal permissions", *data.Address, data.Mode))
+	}
+	return nil
+}
+
+func getRuntimeAPIByAddress(address string, rAPIs []*models.RuntimeAPI) (*models.RuntimeAPI, int) {
+	for i, rAPI := range rAPIs {
+		if rAPI.Address != nil && *rAPI.Address == address {
+			return rAPI, i
+		}
+	}
+	return nil, -1
+}
+
+func ParseRuntimeAPIs(p *parser.Parser) ([]*models.RuntimeAPI, error) {
+	rAPIs := []*models.RuntimeAPI{}
+	data, err := p.Get(parser.Global, parser.GlobalSectionName, "stats socket")
+	if err != nil {
+		if err == parser_errors.ErrFetch {
+			return rAPIs, nil
+		}
+		return nil, err
+	}
+	for _, s := range data.([]types.Socket) {
+		address := s.Path
+		rAPI := &models.RuntimeAPI{Address: &address}
+		for _, p := range s.Params {
+			switch v := p.(type) {
+			case *params.BindOptionDoubleWord:
+				if v.Name == "expose-fd" && v.Value == "listeners" {
+					rAPI.ExposeFdListeners = true
+				}
+			case *params.BindOptionValue:
+				switch v.Name {
+				case "level":
+					rAPI.Level = v.Value
+				case "mode":
+					rAPI.Mode = v.Value
+				case "process":
+					rAPI.Process = v.Value
+				}
+			}
+		}
+		rAPIs = append(rAPIs, rAPI)
+	}
+	return rAPIs, nil
+}
+
+func SerializeRuntimeAPIs(p *parser.Parser, rAPIs []*models.RuntimeAPI) error {
+	sockets := []types.Socket{}
+	for _, rAPI := range rAPIs {
+		s := types.Socket{
+			Path:   *rAPI.Address,
+			Params: []params.BindOption{},
+		}
+		if rAPI.ExposeFdListeners {
+			s.Params = append(s.Params, &params.BindOptionDoubleWord{Name: "expose-fd", Value: "listeners"})
+		}
+		if rAPI.Level != "" {
+			s.Params = append(s.Params, &params.BindOptionValue{Name: "level", Value: rAPI.Level})
+		}
+		if rAPI.Mode != "" {
+			s.Params = append(s.Params, &params.BindOptionValue{Name: "mode", Value: rAPI.Mode})
+		}
+		if rAPI.Process != "" {
+			s.Params = append(s.Params, &params.BindOptionValue{Name: "process", Value: rAPI.Process})
+		}
+		sockets = append(sockets, s)
+	}
+	return p.Set(parser.Global, parser.GlobalSectionName, "stats socket", sockets)
+}
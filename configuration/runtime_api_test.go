@@ -0,0 +1,90 @@
+// Copyright 2019 HAProxy Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package configuration
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/haproxytech/models/v2"
+
+	"github.com/haproxytech/client-native/v2/misc"
+)
+
+func TestCreateEditDeleteRuntimeAPI(t *testing.T) {
+	_, existing, err := client.GetRuntimeAPIs("")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	r := &models.RuntimeAPI{
+		Address:           misc.StringP("/var/run/haproxy-master.sock"),
+		Level:             "operator",
+		Mode:              "0600",
+		ExposeFdListeners: true,
+	}
+	if err := client.CreateRuntimeAPI(r, "", version); err != nil {
+		t.Fatal(err.Error())
+	}
+	version++
+	if err := client.CreateRuntimeAPI(r, "", version); err == nil {
+		t.Error("Should throw error, stats socket already exists")
+		version++
+	}
+
+	v, rAPI, err := client.GetRuntimeAPI("/var/run/haproxy-master.sock", "")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if !reflect.DeepEqual(rAPI, r) {
+		t.Errorf("Stats socket %v returned, expected %v", rAPI, r)
+	}
+	if v != version {
+		t.Errorf("Version %v returned, expected %v", v, version)
+	}
+
+	r.Mode = "rw"
+	if err := client.EditRuntimeAPI("/var/run/haproxy-master.sock", r, "", version); err == nil {
+		t.Error("Should throw error, invalid mode")
+		version++
+	}
+	r = &models.RuntimeAPI{
+		Address: misc.StringP("ipv4@127.0.0.1:9999"),
+		Level:   "user",
+	}
+	if err := client.EditRuntimeAPI("/var/run/haproxy-master.sock", r, "", version); err != nil {
+		t.Fatal(err.Error())
+	}
+	version++
+	if _, rAPI, err = client.GetRuntimeAPI("ipv4@127.0.0.1:9999", ""); err != nil {
+		t.Error(err.Error())
+	} else if !reflect.DeepEqual(rAPI, r) {
+		t.Errorf("Stats socket %v returned, expected %v", rAPI, r)
+	}
+
+	if err := client.DeleteRuntimeAPI("ipv4@127.0.0.1:9999", "", version); err != nil {
+		t.Error(err.Error())
+	} else {
+		version++
+	}
+	if err := client.DeleteRuntimeAPI("ipv4@127.0.0.1:9999", "", version); err == nil {
+		t.Error("Should throw error, stats socket does not exist")
+		version++
+	}
+	if _, rAPIs, _ := client.GetRuntimeAPIs(""); !reflect.DeepEqual(rAPIs, existing) {
+		t.Errorf("Stats sockets %v returned, expected %v", rAPIs, existing)
+	}
+}
@@ -0,0 +1,149 @@
+// Copyright 2019 HAProxy Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package configuration
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var sampleNameRegexp = regexp.MustCompile(`^[a-z][a-z0-9._-]*$`)
+
+// SampleExpr builds a sample expression, a fetch followed by a chain of converters, for example
+// req.hdr(host),lower,map(/etc/haproxy/hosts.map,default). Arguments are validated so that the
+// expression is written as a single word of rules, maps and log formats.
+type SampleExpr struct {
+	parts []string
+	err   error
+}
+
+// NewSampleExpr returns an expression starting with the sample fetch
+func NewSampleExpr(fetch string, args ...string) *SampleExpr {
+	e := &SampleExpr{parts: []string{}}
+	return e.add("fetch", fetch, args)
+}
+
+// FetchReqHdr returns an expression fetching the last occurrence of the request header
+func FetchReqHdr(name string) *SampleExpr {
+	return NewSampleExpr("req.hdr", name)
+}
+
+// FetchResHdr returns an expression fetching the last occurrence of the response header
+func FetchResHdr(name string) *SampleExpr {
+	return NewSampleExpr("res.hdr", name)
+}
+
+// FetchCookie returns an expression fetching the request cookie
+func FetchCookie(name string) *SampleExpr {
+	return NewSampleExpr("req.cook", name)
+}
+
+// FetchURLParam returns an expression fetching the query string parameter
+func FetchURLParam(name string) *SampleExpr {
+	return NewSampleExpr("url_param", name)
+}
+
+// FetchVar returns an expression fetching the variable, for example txn.host
+func FetchVar(name string) *SampleExpr {
+	return NewSampleExpr("var", name)
+}
+
+// FetchSrc returns an expression fetching the client address
+func FetchSrc() *SampleExpr {
+	return NewSampleExpr("src")
+}
+
+// FetchPath returns an expression fetching the request path
+func FetchPath() *SampleExpr {
+	return NewSampleExpr("path")
+}
+
+// Conv appends a converter to the expression
+func (e *SampleExpr) Conv(name string, args ...string) *SampleExpr {
+	return e.add("converter", name, args)
+}
+
+// Lower appends the lower converter
+func (e *SampleExpr) Lower() *SampleExpr {
+	return e.Conv("lower")
+}
+
+// Upper appends the upper converter
+func (e *SampleExpr) Upper() *SampleExpr {
+	return e.Conv("upper")
+}
+
+// Map appends the map converter looking up the value in the map file, an empty default is
+// omitted
+func (e *SampleExpr) Map(file string, def string) *SampleExpr {
+	if def == "" {
+		return e.Conv("map", file)
+	}
+	return e.Conv("map", file, def)
+}
+
+// Field appends the field converter returning the field of the value split by the delimiters
+func (e *SampleExpr) Field(index int, delimiters string) *SampleExpr {
+	return e.Conv("field", strconv.Itoa(index), delimiters)
+}
+
+// Build returns the expression, or the first error met while building it
+func (e *SampleExpr) Build() (string, error) {
+	if e.err != nil {
+		return "", e.err
+	}
+	return strings.Join(e.parts, ","), nil
+}
+
+// String returns the expression, empty if it is invalid
+func (e *SampleExpr) String() string {
+	s, _ := e.Build()
+	return s
+}
+
+// Format returns the expression as a log-format value, used in log formats and in header
+// values of http-request and http-response rules
+func (e *SampleExpr) Format() (string, error) {
+	s, err := e.Build()
+	if err != nil {
+		return "", err
+	}
+	return "%[" + s + "]", nil
+}
+
+func (e *SampleExpr) add(kind string, name string, args []string) *SampleExpr {
+	if e.err != nil {
+		return e
+	}
+	if !sampleNameRegexp.MatchString(name) {
+		e.err = fmt.Errorf("invalid sample %s name %s", kind, name)
+		return e
+	}
+	for _, a := range args {
+		if a == "" || strings.ContainsAny(a, " \t,()#\"'\\") {
+			e.err = fmt.Errorf("invalid argument %s of sample %s %s", a, kind, name)
+			return e
+		}
+	}
+	if len(args) == 0 {
+		e.parts = append(e.parts, name)
+	} else {
+		e.parts = append(e.parts, name+"("+strings.Join(args, ",")+")")
+	}
+	return e
+}
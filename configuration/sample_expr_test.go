@@ -0,0 +1,63 @@
+// Copyright 2019 HAProxy Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package configuration
+
+import (
+	"testing"
+)
+
+func TestSampleExpr(t *testing.T) {
+	tests := []struct {
+		expr     *SampleExpr
+		expected string
+		valid    bool
+	}{
+		{FetchReqHdr("host").Lower().Map("/etc/haproxy/hosts.map", "default"), "req.hdr(host),lower,map(/etc/haproxy/hosts.map,default)", true},
+		{FetchSrc(), "src", true},
+		{FetchPath().Field(2, "/").Upper(), "path,field(2,/),upper", true},
+		{FetchVar("txn.host").Map("hosts.map", ""), "var(txn.host),map(hosts.map)", true},
+		{NewSampleExpr("req.hdr", "x forwarded"), "", false},
+		{NewSampleExpr("Req.Hdr", "host"), "", false},
+		{FetchCookie("id").Conv("map", "a,b"), "", false},
+		{FetchURLParam("q").Conv("lower)"), "", false},
+	}
+	for _, test := range tests {
+		expr, err := test.expr.Build()
+		if test.valid && err != nil {
+			t.Error(err.Error())
+		} else if !test.valid && err == nil {
+			t.Errorf("Should throw error, %s is invalid", expr)
+		} else if expr != test.expected {
+			t.Errorf("Expression %s built, expected %s", expr, test.expected)
+		}
+	}
+
+	format, err := FetchReqHdr("host").Format()
+	if err != nil || format != "%[req.hdr(host)]" {
+		t.Errorf("Format %s returned, expected %%[req.hdr(host)]: %v", format, err)
+	}
+
+	logFormat, err := NewLogFormatBuilder().Text("host ").Sample(FetchReqHdr("host").Lower(), LogFlagQuote).Build()
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if logFormat != `"host %{+Q}[req.hdr(host),lower]"` {
+		t.Errorf("Log format %s built", logFormat)
+	}
+	if _, err := NewLogFormatBuilder().Sample(FetchReqHdr("")).Build(); err == nil {
+		t.Error("Should throw error, empty header name")
+	}
+}
@@ -0,0 +1,304 @@
+// Copyright 2019 HAProxy Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package configuration
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	strfmt "github.com/go-openapi/strfmt"
+	parser "github.com/haproxytech/config-parser/v3"
+	parser_errors "github.com/haproxytech/config-parser/v3/errors"
+	http_actions "github.com/haproxytech/config-parser/v3/parsers/http/actions"
+	"github.com/haproxytech/config-parser/v3/types"
+
+	"github.com/haproxytech/client-native/v2/misc"
+)
+
+const (
+	hdrStrictTransportSecurity = "Strict-Transport-Security"
+	hdrFrameOptions            = "X-Frame-Options"
+	hdrContentTypeOptions      = "X-Content-Type-Options"
+	hdrReferrerPolicy          = "Referrer-Policy"
+	hdrContentSecurityPolicy   = "Content-Security-Policy"
+	hdrPermissionsPolicy       = "Permissions-Policy"
+
+	// securityHeadersComment marks the set-header rules managed by ApplySecurityHeaders
+	securityHeadersComment = "managed: security-headers"
+)
+
+var securityHeaderNames = []string{
+	hdrStrictTransportSecurity,
+	hdrFrameOptions,
+	hdrContentTypeOptions,
+	hdrReferrerPolicy,
+	hdrContentSecurityPolicy,
+	hdrPermissionsPolicy,
+}
+
+// SecurityHeaders are the standard security response headers set on a frontend as a
+// managed group of http-response set-header rules, marked with a comment so rules written by
+// users are left alone. Unset fields are not sent.
+type SecurityHeaders struct {
+	// HSTSMaxAge in seconds of Strict-Transport-Security, sent on TLS connections only
+	HSTSMaxAge *int64 `json:"hsts_max_age,omitempty"`
+	// HSTSIncludeSubdomains adds includeSubDomains to Strict-Transport-Security
+	HSTSIncludeSubdomains bool `json:"hsts_include_subdomains,omitempty"`
+	// HSTSPreload adds preload to Strict-Transport-Security
+	HSTSPreload bool `json:"hsts_preload,omitempty"`
+	// FrameOptions value of X-Frame-Options, DENY or SAMEORIGIN
+	FrameOptions string `json:"frame_options,omitempty"`
+	// ContentTypeNosniff sends X-Content-Type-Options: nosniff
+	ContentTypeNosniff bool `json:"content_type_nosniff,omitempty"`
+	// ReferrerPolicy value of Referrer-Policy
+	ReferrerPolicy string `json:"referrer_policy,omitempty"`
+	// ContentSecurityPolicy value of Content-Security-Policy
+	ContentSecurityPolicy string `json:"content_security_policy,omitempty"`
+	// PermissionsPolicy value of Permissions-Policy
+	PermissionsPolicy string `json:"permissions_policy,omitempty"`
+}
+
+// Validate validates the security headers
+func (s *SecurityHeaders) Validate(formats strfmt.Registry) error {
+	if s.HSTSMaxAge != nil && *s.HSTSMaxAge < 0 {
+		return fmt.Errorf("hsts_max_age can not be negative")
+	}
+	if s.HSTSMaxAge == nil && (s.HSTSIncludeSubdomains || s.HSTSPreload) {
+		return fmt.Errorf("hsts_max_age is required with hsts_include_subdomains or hsts_preload")
+	}
+	if s.FrameOptions != "" && s.FrameOptions != "DENY" && s.FrameOptions != "SAMEORIGIN" {
+		return fmt.Errorf("frame_options must be DENY or SAMEORIGIN")
+	}
+	for _, v := range []string{s.ReferrerPolicy, s.ContentSecurityPolicy, s.PermissionsPolicy} {
+		if strings.ContainsAny(v, "\"#\r\n") {
+			return fmt.Errorf("header values can not contain quotes, '#' or line breaks")
+		}
+	}
+	return nil
+}
+
+// GetSecurityHeaders returns configuration version and the security headers set on the frontend.
+// Returns error on fail.
+func (c *Client) GetSecurityHeaders(frontend string, transactionID string) (int64, *SecurityHeaders, error) {
+	p, err := c.GetParser(transactionID)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	v, err := c.GetVersion(transactionID)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	if !c.checkSectionExists(parser.Frontends, frontend, p) {
+		return v, nil, NewConfError(ErrParentDoesNotExist, fmt.Sprintf("frontend %s does not exist", frontend))
+	}
+
+	rules, err := getSecurityHeaderRules(p, frontend)
+	if err != nil {
+		return v, nil, c.handleError("", "frontend", frontend, "", false, err)
+	}
+
+	s := &SecurityHeaders{}
+	for _, r := range rules {
+		value := strings.Trim(r.Fmt, "\"")
+		switch r.Name {
+		case hdrStrictTransportSecurity:
+			for _, d := range strings.Split(value, ";") {
+				d = strings.TrimSpace(d)
+				switch {
+				case strings.HasPrefix(d, "max-age="):
+					if maxAge, err := strconv.ParseInt(strings.TrimPrefix(d, "max-age="), 10, 64); err == nil {
+						s.HSTSMaxAge = &maxAge
+					}
+				case d == "includeSubDomains":
+					s.HSTSIncludeSubdomains = true
+				case d == "preload":
+					s.HSTSPreload = true
+				}
+			}
+		case hdrFrameOptions:
+			s.FrameOptions = value
+		case hdrContentTypeOptions:
+			s.ContentTypeNosniff = value == "nosniff"
+		case hdrReferrerPolicy:
+			s.ReferrerPolicy = value
+		case hdrContentSecurityPolicy:
+			s.ContentSecurityPolicy = value
+		case hdrPermissionsPolicy:
+			s.PermissionsPolicy = value
+		}
+	}
+	return v, s, nil
+}
+
+// ApplySecurityHeaders replaces the managed security header rules of the frontend with the
+// given ones in one transaction, applying the same headers twice results in the same
+// configuration. set-header rules written by users for the same headers are kept. One of
+// version or transactionID is mandatory. Returns error on fail, nil on success.
+func (c *Client) ApplySecurityHeaders(frontend string, data *SecurityHeaders, transactionID string, version int64) error {
+	if c.UseValidation {
+		validationErr := data.Validate(strfmt.Default)
+		if validationErr != nil {
+			return NewConfError(ErrValidationError, validationErr.Error())
+		}
+	}
+
+	if err := c.authorize("ApplySecurityHeaders", "security_headers", "", "frontend", frontend, transactionID); err != nil {
+		return err
+	}
+
+	p, t, err := c.loadDataForChange(transactionID, version)
+	if err != nil {
+		return err
+	}
+
+	if !c.checkSectionExists(parser.Frontends, frontend, p) {
+		e := NewConfError(ErrParentDoesNotExist, fmt.Sprintf("frontend %s does not exist", frontend))
+		return c.handleError("", "frontend", frontend, t, transactionID == "", e)
+	}
+
+	if err := deleteSecurityHeaders(p, frontend); err != nil {
+		return c.handleError("", "frontend", frontend, t, transactionID == "", err)
+	}
+	for _, r := range serializeSecurityHeaders(data) {
+		if err := p.Insert(parser.Frontends, frontend, "http-response", r, -1); err != nil {
+			return c.handleError("", "frontend", frontend, t, transactionID == "", err)
+		}
+	}
+
+	if err := c.saveData(p, t, transactionID == ""); err != nil {
+		return err
+	}
+	return nil
+}
+
+// DeleteSecurityHeaders removes all managed security header rules from the frontend.
+// One of version or transactionID is mandatory. Returns error on fail, nil on success.
+func (c *Client) DeleteSecurityHeaders(frontend string, transactionID string, version int64) error {
+	if err := c.authorize("DeleteSecurityHeaders", "security_headers", "", "frontend", frontend, transactionID); err != nil {
+		return err
+	}
+
+	p, t, err := c.loadDataForChange(transactionID, version)
+	if err != nil {
+		return err
+	}
+
+	if !c.checkSectionExists(parser.Frontends, frontend, p) {
+		e := NewConfError(ErrParentDoesNotExist, fmt.Sprintf("frontend %s does not exist", frontend))
+		return c.handleError("", "frontend", frontend, t, transactionID == "", e)
+	}
+
+	if err := deleteSecurityHeaders(p, frontend); err != nil {
+		return c.handleError("", "frontend", frontend, t, transactionID == "", err)
+	}
+
+	if err := c.saveData(p, t, transactionID == ""); err != nil {
+		return err
+	}
+	return nil
+}
+
+// getSecurityHeaderRules returns the set-header rules of the frontend created by
+// ApplySecurityHeaders, recognized by their comment
+func getSecurityHeaderRules(p *parser.Parser, frontend string) ([]*http_actions.SetHeader, error) {
+	data, err := p.Get(parser.Frontends, frontend, "http-response", false)
+	if err != nil {
+		if err == parser_errors.ErrFetch {
+			return nil, nil
+		}
+		return nil, err
+	}
+	rules := []*http_actions.SetHeader{}
+	for _, r := range data.([]types.HTTPAction) {
+		if isSecurityHeaderRule(r) {
+			rules = append(rules, r.(*http_actions.SetHeader))
+		}
+	}
+	return rules, nil
+}
+
+func deleteSecurityHeaders(p *parser.Parser, frontend string) error {
+	data, err := p.Get(parser.Frontends, frontend, "http-response", false)
+	if err != nil {
+		if err == parser_errors.ErrFetch {
+			return nil
+		}
+		return err
+	}
+	rules := data.([]types.HTTPAction)
+	// delete from the end so the remaining indexes stay valid
+	for i := len(rules) - 1; i >= 0; i-- {
+		if isSecurityHeaderRule(rules[i]) {
+			if err := p.Delete(parser.Frontends, frontend, "http-response", i); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func isSecurityHeaderRule(r types.HTTPAction) bool {
+	h, ok := r.(*http_actions.SetHeader)
+	return ok && h.Comment == securityHeadersComment && misc.StringInSlice(h.Name, securityHeaderNames)
+}
+
+func serializeSecurityHeaders(s *SecurityHeaders) []*http_actions.SetHeader {
+	rules := []*http_actions.SetHeader{}
+	add := func(name, value string) *http_actions.SetHeader {
+		if strings.ContainsAny(value, " \t") {
+			value = fmt.Sprintf("\"%s\"", value)
+		}
+		r := &http_actions.SetHeader{
+			Name:    name,
+			Fmt:     value,
+			Comment: securityHeadersComment,
+		}
+		rules = append(rules, r)
+		return r
+	}
+
+	if s.HSTSMaxAge != nil {
+		value := fmt.Sprintf("max-age=%d", *s.HSTSMaxAge)
+		if s.HSTSIncludeSubdomains {
+			value += ";includeSubDomains"
+		}
+		if s.HSTSPreload {
+			value += ";preload"
+		}
+		r := add(hdrStrictTransportSecurity, value)
+		r.Cond = "if"
+		r.CondTest = "{ ssl_fc }"
+	}
+	if s.FrameOptions != "" {
+		add(hdrFrameOptions, s.FrameOptions)
+	}
+	if s.ContentTypeNosniff {
+		add(hdrContentTypeOptions, "nosniff")
+	}
+	if s.ReferrerPolicy != "" {
+		add(hdrReferrerPolicy, s.ReferrerPolicy)
+	}
+	if s.ContentSecurityPolicy != "" {
+		add(hdrContentSecurityPolicy, s.ContentSecurityPolicy)
+	}
+	if s.PermissionsPolicy != "" {
+		add(hdrPermissionsPolicy, s.PermissionsPolicy)
+	}
+	return rules
+}
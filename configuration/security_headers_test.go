@@ -0,0 +1,127 @@
+// Copyright 2019 HAProxy Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package configuration
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/haproxytech/client-native/v2/misc"
+	"github.com/haproxytech/models/v2"
+)
+
+func TestApplyDeleteSecurityHeaders(t *testing.T) {
+	maxAge := int64(31536000)
+	sh := &SecurityHeaders{
+		HSTSMaxAge:            &maxAge,
+		HSTSIncludeSubdomains: true,
+		FrameOptions:          "DENY",
+		ContentTypeNosniff:    true,
+		ReferrerPolicy:        "no-referrer",
+		ContentSecurityPolicy: "default-src 'self'",
+	}
+
+	_, rules, err := client.GetHTTPResponseRules("frontend", "test_2", "")
+	if err != nil {
+		t.Error(err.Error())
+	}
+	count := len(rules)
+
+	// set-header written by users for a managed header must be kept
+	userRule := &models.HTTPResponseRule{
+		Index:     misc.Int64P(count),
+		Type:      "set-header",
+		HdrName:   "X-Frame-Options",
+		HdrFormat: "SAMEORIGIN",
+	}
+	err = client.CreateHTTPResponseRule("frontend", "test_2", userRule, "", version)
+	if err != nil {
+		t.Error(err.Error())
+	} else {
+		version++
+	}
+	count++
+
+	// apply twice, result must be the same
+	for i := 0; i < 2; i++ {
+		err = client.ApplySecurityHeaders("test_2", sh, "", version)
+		if err != nil {
+			t.Error(err.Error())
+		} else {
+			version++
+		}
+	}
+
+	v, headers, err := client.GetSecurityHeaders("test_2", "")
+	if err != nil {
+		t.Error(err.Error())
+	}
+
+	if !reflect.DeepEqual(headers, sh) {
+		fmt.Printf("Applied security headers: %v\n", headers)
+		fmt.Printf("Given security headers: %v\n", sh)
+		t.Error("Applied security headers not equal to given security headers")
+	}
+
+	if v != version {
+		t.Errorf("Version %v returned, expected %v", v, version)
+	}
+
+	_, rules, err = client.GetHTTPResponseRules("frontend", "test_2", "")
+	if err != nil {
+		t.Error(err.Error())
+	}
+	if len(rules) != count+5 {
+		t.Errorf("%v http response rules returned, expected %v", len(rules), count+5)
+	}
+
+	err = client.DeleteSecurityHeaders("test_2", "", version)
+	if err != nil {
+		t.Error(err.Error())
+	} else {
+		version++
+	}
+
+	_, headers, err = client.GetSecurityHeaders("test_2", "")
+	if err != nil {
+		t.Error(err.Error())
+	}
+	if !reflect.DeepEqual(headers, &SecurityHeaders{}) {
+		t.Error("DeleteSecurityHeaders failed, security headers still exist")
+	}
+
+	_, rules, err = client.GetHTTPResponseRules("frontend", "test_2", "")
+	if err != nil {
+		t.Error(err.Error())
+	}
+	if len(rules) != count {
+		t.Errorf("%v http response rules returned, expected %v", len(rules), count)
+	}
+	_, r, err := client.GetHTTPResponseRule(int64(count-1), "frontend", "test_2", "")
+	if err != nil {
+		t.Error(err.Error())
+	} else if r.HdrName != "X-Frame-Options" || r.HdrFormat != "SAMEORIGIN" {
+		t.Errorf("user set-header rule not kept: %v %v", r.HdrName, r.HdrFormat)
+	}
+
+	err = client.DeleteHTTPResponseRule(int64(count-1), "frontend", "test_2", "", version)
+	if err != nil {
+		t.Error(err.Error())
+	} else {
+		version++
+	}
+}
@@ -75,6 +75,10 @@ func (c *Client) GetServer(name string, backend string, transactionID string) (i
 // DeleteServer deletes a server in configuration. One of version or transactionID is
 // mandatory. Returns error on fail, nil on success.
 func (c *Client) DeleteServer(name string, backend string, transactionID string, version int64) error {
+	if err := c.authorize("DeleteServer", "server", name, "backend", backend, transactionID); err != nil {
+		return err
+	}
+
 	p, t, err := c.loadDataForChange(transactionID, version)
 	if err != nil {
 		return err
@@ -106,6 +110,16 @@ func (c *Client) CreateServer(backend string, data *models.Server, transactionID
 			return NewConfError(ErrValidationError, validationErr.Error())
 		}
 	}
+	if err := validateServerAgent(data); err != nil {
+		return NewConfError(ErrValidationError, err.Error())
+	}
+	if err := ValidateServerKeywords(data, c.haproxyVersion); err != nil {
+		return NewConfError(ErrValidationError, err.Error())
+	}
+	if err := c.authorize("CreateServer", "server", data.Name, "backend", backend, transactionID); err != nil {
+		return err
+	}
+
 	p, t, err := c.loadDataForChange(transactionID, version)
 	if err != nil {
 		return err
@@ -136,6 +150,16 @@ func (c *Client) EditServer(name string, backend string, data *models.Server, tr
 			return NewConfError(ErrValidationError, validationErr.Error())
 		}
 	}
+	if err := validateServerAgent(data); err != nil {
+		return NewConfError(ErrValidationError, err.Error())
+	}
+	if err := ValidateServerKeywords(data, c.haproxyVersion); err != nil {
+		return NewConfError(ErrValidationError, err.Error())
+	}
+	if err := c.authorize("EditServer", "server", name, "backend", backend, transactionID); err != nil {
+		return err
+	}
+
 	p, t, err := c.loadDataForChange(transactionID, version)
 	if err != nil {
 		return err
@@ -266,8 +290,14 @@ func ParseServer(ondiskServer types.Server) *models.Server {
 				s.ForceTlsv13 = "disabled"
 			case "send-proxy-v2-ssl":
 				s.SendProxyV2Ssl = "enabled"
+			case "no-send-proxy-v2-ssl":
+				s.SendProxyV2Ssl = "disabled"
 			case "send-proxy-v2-ssl-cn":
 				s.SendProxyV2SslCn = "enabled"
+			case "no-send-proxy-v2-ssl-cn":
+				s.SendProxyV2SslCn = "disabled"
+			case "no-verifyhost":
+				s.NoVerifyhost = "enabled"
 			case "ssl-reuse":
 				s.SslReuse = "enabled"
 			case "no-ssl-reuse":
@@ -729,6 +759,9 @@ func SerializeServer(s models.Server) types.Server {
 	if s.Verifyhost != "" {
 		srv.Params = append(srv.Params, &params.ServerOptionValue{Name: "verifyhost", Value: s.Verifyhost})
 	}
+	if s.NoVerifyhost == "enabled" {
+		srv.Params = append(srv.Params, &params.ServerOptionWord{Name: "no-verifyhost"})
+	}
 	return srv
 }
 
@@ -745,3 +778,15 @@ func GetServerByName(name string, backend string, p *parser.Parser) (*models.Ser
 	}
 	return nil, 0
 }
+
+// validateServerAgent checks that the agent settings can be written on the server line, which is
+// split on whitespace and cut at '#'
+func validateServerAgent(s *models.Server) error {
+	if s.AgentSend != "" && strings.ContainsAny(s.AgentSend, " \t#") {
+		return fmt.Errorf("agent-send of server %s can not contain whitespace or '#'", s.Name)
+	}
+	if s.AgentInter != nil && *s.AgentInter <= 0 {
+		return fmt.Errorf("agent-inter of server %s has to be greater than 0", s.Name)
+	}
+	return nil
+}
@@ -16,7 +16,9 @@
 package configuration
 
 import (
+	"fmt"
 	"strconv"
+	"strings"
 
 	strfmt "github.com/go-openapi/strfmt"
 	parser "github.com/haproxytech/config-parser/v3"
@@ -73,6 +75,10 @@ func (c *Client) GetServerSwitchingRule(id int64, backend string, transactionID
 // DeleteServerSwitchingRule deletes a server switching rule in configuration. One of version or transactionID is
 // mandatory. Returns error on fail, nil on success.
 func (c *Client) DeleteServerSwitchingRule(id int64, backend string, transactionID string, version int64) error {
+	if err := c.authorize("DeleteServerSwitchingRule", "server_switching_rule", authorizedIndex(id), "backend", backend, transactionID); err != nil {
+		return err
+	}
+
 	p, t, err := c.loadDataForChange(transactionID, version)
 	if err != nil {
 		return err
@@ -97,11 +103,19 @@ func (c *Client) CreateServerSwitchingRule(backend string, data *models.ServerSw
 			return NewConfError(ErrValidationError, validationErr.Error())
 		}
 	}
+	if err := c.authorize("CreateServerSwitchingRule", "server_switching_rule", authorizedIndexP(data.Index), "backend", backend, transactionID); err != nil {
+		return err
+	}
+
 	p, t, err := c.loadDataForChange(transactionID, version)
 	if err != nil {
 		return err
 	}
 
+	if err := checkTargetServer(data.TargetServer, backend, p); err != nil {
+		return c.handleError(strconv.FormatInt(*data.Index, 10), "backend", backend, t, transactionID == "", err)
+	}
+
 	if err := p.Insert(parser.Backends, backend, "use-server", SerializeServerSwitchingRule(*data), int(*data.Index)); err != nil {
 		return c.handleError(strconv.FormatInt(*data.Index, 10), "backend", backend, t, transactionID == "", err)
 	}
@@ -121,17 +135,25 @@ func (c *Client) EditServerSwitchingRule(id int64, backend string, data *models.
 			return NewConfError(ErrValidationError, validationErr.Error())
 		}
 	}
+	if err := c.authorize("EditServerSwitchingRule", "server_switching_rule", authorizedIndex(id), "backend", backend, transactionID); err != nil {
+		return err
+	}
+
 	p, t, err := c.loadDataForChange(transactionID, version)
 	if err != nil {
 		return err
 	}
 
 	if _, err := p.GetOne(parser.Backends, backend, "use-server", int(id)); err != nil {
-		return c.handleError(strconv.FormatInt(*data.Index, 10), "backend", backend, t, transactionID == "", err)
+		return c.handleError(strconv.FormatInt(id, 10), "backend", backend, t, transactionID == "", err)
+	}
+
+	if err := checkTargetServer(data.TargetServer, backend, p); err != nil {
+		return c.handleError(strconv.FormatInt(id, 10), "backend", backend, t, transactionID == "", err)
 	}
 
 	if err := p.Set(parser.Backends, backend, "use-server", SerializeServerSwitchingRule(*data), int(id)); err != nil {
-		return c.handleError(strconv.FormatInt(*data.Index, 10), "backend", backend, t, transactionID == "", err)
+		return c.handleError(strconv.FormatInt(id, 10), "backend", backend, t, transactionID == "", err)
 	}
 
 	if err := c.saveData(p, t, transactionID == ""); err != nil {
@@ -178,3 +200,29 @@ func SerializeServerSwitchingRule(sRule models.ServerSwitchingRule) types.UseSer
 		CondTest: sRule.CondTest,
 	}
 }
+
+// checkTargetServer returns an error if the backend has no server of the name, neither declared
+// with a server line nor generated by a server-template
+func checkTargetServer(name string, backend string, p *parser.Parser) error {
+	if server, _ := GetServerByName(name, backend, p); server != nil {
+		return nil
+	}
+	templates, err := ParseServerTemplates(backend, p)
+	if err != nil {
+		return err
+	}
+	for _, t := range templates {
+		if !strings.HasPrefix(name, t.Prefix) {
+			continue
+		}
+		id, err := strconv.ParseInt(strings.TrimPrefix(name, t.Prefix), 10, 64)
+		if err != nil {
+			continue
+		}
+		first, last := serverTemplateRange(t.NumOrRange)
+		if id >= first && id <= last {
+			return nil
+		}
+	}
+	return NewConfError(ErrValidationError, fmt.Sprintf("server %s does not exist in backend %s", name, backend))
+}
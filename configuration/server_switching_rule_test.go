@@ -188,3 +188,23 @@ func TestCreateEditDeleteServerSwitchingRule(t *testing.T) {
 		version++
 	}
 }
+
+func TestCreateServerSwitchingRuleUnknownServer(t *testing.T) {
+	id := int64(0)
+	sr := &models.ServerSwitchingRule{
+		Index:        &id,
+		TargetServer: "webserv404",
+		Cond:         "if",
+		CondTest:     "TRUE",
+	}
+
+	err := client.CreateServerSwitchingRule("test", sr, "", version)
+	if err == nil {
+		t.Error("Should throw error, non existant target server")
+		version++
+	}
+
+	if v, _ := client.GetVersion(""); v != version {
+		t.Error("Version incremented on failed server switching rule create")
+	}
+}
@@ -0,0 +1,308 @@
+// Copyright 2019 HAProxy Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package configuration
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	strfmt "github.com/go-openapi/strfmt"
+	parser "github.com/haproxytech/config-parser/v3"
+	"github.com/haproxytech/config-parser/v3/params"
+	"github.com/haproxytech/config-parser/v3/types"
+	"github.com/haproxytech/models/v2"
+)
+
+var serverTemplateNumOrRange = regexp.MustCompile(`^([0-9]+)(-([0-9]+))?$`)
+
+// ServerTemplate is a server-template of a backend, a set of servers named <prefix><id> sharing
+// the same settings and resolving the same FQDN. SRV discovery templates are server-templates too.
+type ServerTemplate struct {
+	Prefix string `json:"prefix"`
+	// NumOrRange is the number of servers, 5 for ids 1 to 5, or a range of ids, 3-5
+	NumOrRange string `json:"num_or_range"`
+	Fqdn       string `json:"fqdn"`
+	Port       *int64 `json:"port,omitempty"`
+	// Server holds the settings of the servers of the template, its name, address and port are
+	// not used
+	Server *models.Server `json:"server,omitempty"`
+}
+
+// Validate checks the prefix, server ids and FQDN of the template
+func (t *ServerTemplate) Validate() error {
+	if t.Prefix == "" || strings.ContainsAny(t.Prefix, " \t#") {
+		return fmt.Errorf("invalid server-template prefix %s", t.Prefix)
+	}
+	m := serverTemplateNumOrRange.FindStringSubmatch(t.NumOrRange)
+	if m == nil {
+		return fmt.Errorf("server-template %s: num or range must be <num> or <first>-<last>", t.Prefix)
+	}
+	first, _ := strconv.ParseInt(m[1], 10, 64)
+	if first <= 0 {
+		return fmt.Errorf("server-template %s: server ids start at 1", t.Prefix)
+	}
+	if m[3] != "" {
+		if last, _ := strconv.ParseInt(m[3], 10, 64); last < first {
+			return fmt.Errorf("server-template %s: invalid range %s", t.Prefix, t.NumOrRange)
+		}
+	}
+	if t.Fqdn == "" || strings.ContainsAny(t.Fqdn, " \t#:") {
+		return fmt.Errorf("server-template %s: invalid fqdn %s", t.Prefix, t.Fqdn)
+	}
+	if t.Port != nil && (*t.Port < 1 || *t.Port > 65535) {
+		return fmt.Errorf("server-template %s: port must be between 1 and 65535", t.Prefix)
+	}
+	return nil
+}
+
+// serverTemplateRange returns the first and last server id of the num or range, first is greater
+// than last when it is not valid
+func serverTemplateRange(numOrRange string) (int64, int64) {
+	m := serverTemplateNumOrRange.FindStringSubmatch(numOrRange)
+	if m == nil {
+		return 1, 0
+	}
+	n, _ := strconv.ParseInt(m[1], 10, 64)
+	if m[3] == "" {
+		return 1, n
+	}
+	last, _ := strconv.ParseInt(m[3], 10, 64)
+	return n, last
+}
+
+// GetServerTemplates returns configuration version and an array of
+// configured server templates in the specified backend. Returns error on fail.
+func (c *Client) GetServerTemplates(backend string, transactionID string) (int64, []*ServerTemplate, error) {
+	p, err := c.GetParser(transactionID)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	v, err := c.GetVersion(transactionID)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	if !c.checkSectionExists(parser.Backends, backend, p) {
+		return v, nil, NewConfError(ErrParentDoesNotExist, fmt.Sprintf("Backend %s does not exist", backend))
+	}
+
+	templates, err := ParseServerTemplates(backend, p)
+	if err != nil {
+		return v, nil, c.handleError("", "backend", backend, "", false, err)
+	}
+
+	return v, templates, nil
+}
+
+// GetServerTemplate returns configuration version and a requested server template
+// in the specified backend. Returns error on fail or if server template does not exist.
+func (c *Client) GetServerTemplate(prefix string, backend string, transactionID string) (int64, *ServerTemplate, error) {
+	p, err := c.GetParser(transactionID)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	v, err := c.GetVersion(transactionID)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	template, _ := GetServerTemplateByPrefix(prefix, backend, p)
+	if template == nil {
+		return v, nil, NewConfError(ErrObjectDoesNotExist, fmt.Sprintf("Server template %s does not exist in backend %s", prefix, backend))
+	}
+
+	return v, template, nil
+}
+
+// DeleteServerTemplate deletes a server template in configuration. One of version or
+// transactionID is mandatory. Returns error on fail, nil on success.
+func (c *Client) DeleteServerTemplate(prefix string, backend string, transactionID string, version int64) error {
+	return c.setServerTemplate("DeleteServerTemplate", prefix, backend, nil, false, transactionID, version)
+}
+
+// CreateServerTemplate creates a server template in configuration. One of version or
+// transactionID is mandatory. Returns error on fail, nil on success.
+func (c *Client) CreateServerTemplate(backend string, data *ServerTemplate, transactionID string, version int64) error {
+	if err := c.validateServerTemplate(data); err != nil {
+		return NewConfError(ErrValidationError, err.Error())
+	}
+	return c.setServerTemplate("CreateServerTemplate", data.Prefix, backend, data, true, transactionID, version)
+}
+
+// EditServerTemplate edits a server template in configuration. One of version or transactionID
+// is mandatory. Returns error on fail, nil on success.
+func (c *Client) EditServerTemplate(prefix string, backend string, data *ServerTemplate, transactionID string, version int64) error {
+	if err := c.validateServerTemplate(data); err != nil {
+		return NewConfError(ErrValidationError, err.Error())
+	}
+	if data.Prefix != prefix {
+		return NewConfError(ErrValidationError, fmt.Sprintf("server template %s can not be renamed to %s", prefix, data.Prefix))
+	}
+	return c.setServerTemplate("EditServerTemplate", prefix, backend, data, false, transactionID, version)
+}
+
+func (c *Client) setServerTemplate(operation string, prefix string, backend string, data *ServerTemplate, create bool, transactionID string, version int64) error {
+	if err := c.authorize(operation, "server_template", prefix, "backend", backend, transactionID); err != nil {
+		return err
+	}
+
+	p, t, err := c.loadDataForChange(transactionID, version)
+	if err != nil {
+		return err
+	}
+
+	if !c.checkSectionExists(parser.Backends, backend, p) {
+		e := NewConfError(ErrParentDoesNotExist, fmt.Sprintf("Backend %s does not exist", backend))
+		return c.handleError(prefix, "backend", backend, t, transactionID == "", e)
+	}
+
+	template, i := GetServerTemplateByPrefix(prefix, backend, p)
+	if create && template != nil {
+		e := NewConfError(ErrObjectAlreadyExists, fmt.Sprintf("Server template %s already exists in backend %s", prefix, backend))
+		return c.handleError(prefix, "backend", backend, t, transactionID == "", e)
+	}
+	if !create && template == nil {
+		e := NewConfError(ErrObjectDoesNotExist, fmt.Sprintf("Server template %s does not exist in backend %s", prefix, backend))
+		return c.handleError(prefix, "backend", backend, t, transactionID == "", e)
+	}
+
+	lines, err := getRawLines(p, parser.Backends, backend)
+	if err != nil {
+		return c.handleError(prefix, "backend", backend, t, transactionID == "", err)
+	}
+	switch {
+	case data == nil:
+		lines = append(lines[:i], lines[i+1:]...)
+	case create:
+		lines = append(lines, types.UnProcessed{Value: SerializeServerTemplate(*data)})
+	default:
+		lines[i] = types.UnProcessed{Value: SerializeServerTemplate(*data)}
+	}
+
+	if len(lines) == 0 {
+		err = p.Set(parser.Backends, backend, "", nil)
+	} else {
+		err = p.Set(parser.Backends, backend, "", lines)
+	}
+	if err != nil {
+		return c.handleError(prefix, "backend", backend, t, transactionID == "", err)
+	}
+
+	if err := c.saveData(p, t, transactionID == ""); err != nil {
+		return err
+	}
+	return nil
+}
+
+// ParseServerTemplates returns the server templates of the backend, server-template lines have no
+// parser and are kept as unprocessed lines
+func ParseServerTemplates(backend string, p *parser.Parser) ([]*ServerTemplate, error) {
+	templates := []*ServerTemplate{}
+	lines, err := getRawLines(p, parser.Backends, backend)
+	if err != nil {
+		return nil, err
+	}
+	for _, l := range lines {
+		if t := ParseServerTemplate(l.Value); t != nil {
+			templates = append(templates, t)
+		}
+	}
+	return templates, nil
+}
+
+// ParseServerTemplate returns the server template of a line, nil if the line is not one
+func ParseServerTemplate(line string) *ServerTemplate {
+	value, ok := matchRawDirective(line, "server-template")
+	if !ok {
+		return nil
+	}
+	words := strings.Fields(value)
+	if len(words) < 3 {
+		return nil
+	}
+	s := ParseServer(types.Server{Name: words[0], Address: words[2], Params: params.ParseServerOptions(words[3:])})
+	if s == nil {
+		return nil
+	}
+	t := &ServerTemplate{
+		Prefix:     words[0],
+		NumOrRange: words[1],
+		Fqdn:       s.Address,
+		Port:       s.Port,
+		Server:     s,
+	}
+	s.Name = ""
+	s.Address = ""
+	s.Port = nil
+	return t
+}
+
+func SerializeServerTemplate(t ServerTemplate) string {
+	address := t.Fqdn
+	if t.Port != nil {
+		address = fmt.Sprintf("%s:%d", t.Fqdn, *t.Port)
+	}
+	line := fmt.Sprintf("server-template %s %s %s", t.Prefix, t.NumOrRange, address)
+	if t.Server != nil {
+		srv := SerializeServer(*t.Server)
+		if options := params.ServerOptionsString(srv.Params); options != "" {
+			line += " " + options
+		}
+	}
+	return line
+}
+
+// GetServerTemplateByPrefix returns the server template and the index of its line among the
+// unprocessed lines of the backend
+func GetServerTemplateByPrefix(prefix string, backend string, p *parser.Parser) (*ServerTemplate, int) {
+	lines, err := getRawLines(p, parser.Backends, backend)
+	if err != nil {
+		return nil, 0
+	}
+	for i, l := range lines {
+		if t := ParseServerTemplate(l.Value); t != nil && t.Prefix == prefix {
+			return t, i
+		}
+	}
+	return nil, 0
+}
+
+func (c *Client) validateServerTemplate(t *ServerTemplate) error {
+	if err := t.Validate(); err != nil {
+		return err
+	}
+	if t.Server == nil {
+		return nil
+	}
+	// the settings are checked as a server named after the prefix
+	s := *t.Server
+	s.Name = t.Prefix
+	s.Address = t.Fqdn
+	s.Port = t.Port
+	if c.UseValidation {
+		if err := s.Validate(strfmt.Default); err != nil {
+			return err
+		}
+	}
+	if err := validateServerAgent(&s); err != nil {
+		return err
+	}
+	return ValidateServerKeywords(&s, c.haproxyVersion)
+}
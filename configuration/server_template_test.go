@@ -0,0 +1,116 @@
+// Copyright 2019 HAProxy Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package configuration
+
+import (
+	"testing"
+
+	"github.com/haproxytech/client-native/v2/misc"
+	"github.com/haproxytech/models/v2"
+)
+
+func TestCreateEditDeleteServerTemplate(t *testing.T) {
+	st := &ServerTemplate{
+		Prefix:     "web",
+		NumOrRange: "1-3",
+		Fqdn:       "web.local",
+		Port:       misc.Int64P(8080),
+		Server: &models.Server{
+			Check:     "enabled",
+			Inter:     misc.Int64P(2000),
+			Resolvers: "test",
+		},
+	}
+	if err := client.CreateServerTemplate("test_2", st, "", version); err != nil {
+		t.Error(err.Error())
+	} else {
+		version++
+	}
+
+	if err := client.CreateServerTemplate("test_2", st, "", version); err == nil {
+		t.Error("Should throw error, server template web already exists")
+	}
+
+	v, template, err := client.GetServerTemplate("web", "test_2", "")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if v != version {
+		t.Errorf("Version %v returned, expected %v", v, version)
+	}
+	if template.NumOrRange != "1-3" || template.Fqdn != "web.local" || template.Port == nil || *template.Port != 8080 {
+		t.Errorf("server template %+v not as created", template)
+	}
+	if template.Server.Check != "enabled" || template.Server.Inter == nil || *template.Server.Inter != 2000 || template.Server.Resolvers != "test" {
+		t.Errorf("server template settings %+v not as created", template.Server)
+	}
+
+	st.NumOrRange = "5"
+	st.Port = nil
+	st.Server.Check = ""
+	if err := client.EditServerTemplate("web", "test_2", st, "", version); err != nil {
+		t.Error(err.Error())
+	} else {
+		version++
+	}
+
+	d := &SRVDiscovery{Service: "_http._tcp.app.local", Prefix: "app", Count: 2, Resolvers: "test"}
+	if err := client.ApplySRVDiscovery("test_2", d, "", version); err != nil {
+		t.Error(err.Error())
+	} else {
+		version++
+	}
+
+	_, templates, err := client.GetServerTemplates("test_2", "")
+	if err != nil {
+		t.Error(err.Error())
+	}
+	if len(templates) != 2 {
+		t.Fatalf("%v server templates returned, expected 2", len(templates))
+	}
+	if templates[0].Prefix != "web" || templates[0].NumOrRange != "5" || templates[0].Port != nil || templates[0].Server.Check != "" {
+		t.Errorf("server template %+v not as edited", templates[0])
+	}
+	if templates[1].Prefix != "app" || templates[1].Fqdn != "_http._tcp.app.local" {
+		t.Errorf("SRV discovery server template %+v not returned", templates[1])
+	}
+
+	invalid := []*ServerTemplate{
+		{Prefix: "bad", NumOrRange: "0", Fqdn: "web.local"},
+		{Prefix: "bad", NumOrRange: "5-3", Fqdn: "web.local"},
+		{Prefix: "bad", NumOrRange: "3", Fqdn: "web.local:80"},
+	}
+	for _, i := range invalid {
+		if err := client.CreateServerTemplate("test_2", i, "", version); err == nil {
+			t.Errorf("Should throw error, invalid server template %+v", i)
+		}
+	}
+
+	for _, prefix := range []string{"web", "app"} {
+		if err := client.DeleteServerTemplate(prefix, "test_2", "", version); err != nil {
+			t.Error(err.Error())
+		} else {
+			version++
+		}
+	}
+
+	if _, _, err := client.GetServerTemplate("web", "test_2", ""); err == nil {
+		t.Error("DeleteServerTemplate failed, server template web still exists")
+	}
+	if err := client.DeleteServerTemplate("web", "test_2", "", version); err == nil {
+		t.Error("Should throw error, non existent server template")
+	}
+}
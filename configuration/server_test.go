@@ -253,3 +253,98 @@ func TestCreateEditDeleteServer(t *testing.T) {
 		version++
 	}
 }
+
+func TestServerAgentCheck(t *testing.T) {
+	port := int64(4400)
+	agentPort := int64(9999)
+	agentInter := int64(2000)
+	s := &models.Server{
+		Name:       "agent",
+		Address:    "192.168.2.2",
+		Port:       &port,
+		AgentCheck: "enabled",
+		AgentAddr:  "127.0.0.1",
+		AgentPort:  &agentPort,
+		AgentInter: &agentInter,
+		AgentSend:  `ready\n`,
+	}
+
+	err := client.CreateServer("test", s, "", version)
+	if err != nil {
+		t.Error(err.Error())
+	} else {
+		version++
+	}
+
+	_, server, err := client.GetServer("agent", "test", "")
+	if err != nil {
+		t.Error(err.Error())
+	}
+
+	if !reflect.DeepEqual(server, s) {
+		fmt.Printf("Created server: %v\n", server)
+		fmt.Printf("Given server: %v\n", s)
+		t.Error("Created server not equal to given server")
+	}
+
+	s.AgentSend = "ready now"
+	err = client.EditServer("agent", "test", s, "", version)
+	if err == nil {
+		t.Error("Should throw error, agent-send contains whitespace")
+		version++
+	}
+
+	err = client.DeleteServer("agent", "test", "", version)
+	if err != nil {
+		t.Error(err.Error())
+	} else {
+		version++
+	}
+}
+
+func TestCreateDeleteSSLServer(t *testing.T) {
+	port := int64(443)
+	s := &models.Server{
+		Name:             "created_ssl",
+		Address:          "192.168.2.2",
+		Port:             &port,
+		Ssl:              "enabled",
+		Verify:           "required",
+		NoVerifyhost:     "enabled",
+		Sni:              "req.hdr(host)",
+		SslCafile:        "ca.pem",
+		SslCertificate:   "client.pem",
+		Ciphers:          "ECDHE-RSA-AES128-GCM-SHA256",
+		Alpn:             "h2,http/1.1",
+		CheckSsl:         "enabled",
+		CheckSni:         "example.com",
+		ForceTlsv12:      "enabled",
+		SendProxyV2Ssl:   "disabled",
+		SendProxyV2SslCn: "disabled",
+	}
+
+	err := client.CreateServer("test", s, "", version)
+	if err != nil {
+		t.Error(err.Error())
+	} else {
+		version++
+	}
+
+	_, server, err := client.GetServer("created_ssl", "test", "")
+	if err != nil {
+		t.Error(err.Error())
+	}
+
+	if !reflect.DeepEqual(server, s) {
+		fmt.Printf("Created server: %v\n", server)
+		fmt.Printf("Given server: %v\n", s)
+		t.Error("Created server not equal to given server")
+	}
+
+	err = client.DeleteServer("created_ssl", "test", "", version)
+	if err != nil {
+		t.Error(err.Error())
+	} else {
+		version++
+	}
+}
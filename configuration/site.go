@@ -85,6 +85,10 @@ func (c *Client) CreateSite(data *models.Site, transactionID string, version int
 		}
 	}
 	// start an implicit transaction for create site (multiple operations required) if not already given
+	if err := c.authorize("CreateSite", "site", data.Name, "", "", transactionID); err != nil {
+		return err
+	}
+
 	p, t, err := c.loadDataForChange(transactionID, version)
 	if err != nil {
 		return err
@@ -163,6 +167,10 @@ func (c *Client) EditSite(name string, data *models.Site, transactionID string,
 		}
 	}
 	// start an implicit transaction for create site (multiple operations required) if not already given
+	if err := c.authorize("EditSite", "site", name, "", "", transactionID); err != nil {
+		return err
+	}
+
 	p, t, err := c.loadDataForChange(transactionID, version)
 	if err != nil {
 		return err
@@ -399,6 +407,10 @@ func (c *Client) DeleteSite(name string, transactionID string, version int64) er
 	var err error
 
 	// start an implicit transaction for delete site (multiple operations required) if not already given
+	if err := c.authorize("DeleteSite", "site", name, "", "", transactionID); err != nil {
+		return err
+	}
+
 	p, t, err := c.loadDataForChange(transactionID, version)
 	if err != nil {
 		return err
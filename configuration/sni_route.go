@@ -0,0 +1,354 @@
+// Copyright 2019 HAProxy Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package configuration
+
+import (
+	"fmt"
+	"strings"
+
+	strfmt "github.com/go-openapi/strfmt"
+	"github.com/haproxytech/models/v2"
+)
+
+const (
+	sniPassthroughCriterion = "req_ssl_sni"
+	sniTerminationCriterion = "ssl_fc_sni"
+	sniHelloCond            = "{ req_ssl_hello_type 1 }"
+	sniDefaultInspectDelay  = int64(5000)
+)
+
+// SNIRoute routes TLS connections of a frontend to a backend by the requested server name.
+// Passthrough routes inspect the client hello of connections forwarded without decryption,
+// termination routes match the server name of connections decrypted by the frontend.
+type SNIRoute struct {
+	// Name of the acl matching the server names
+	Name string `json:"name"`
+	// ServerNames are the matched server names, case insensitive
+	ServerNames []string `json:"server_names"`
+	// Backend connections are routed to
+	Backend string `json:"backend"`
+	// Termination is true when TLS is terminated on the frontend
+	Termination bool `json:"termination,omitempty"`
+	// InspectDelay in milliseconds to wait for the client hello of passthrough routes, 5s if not set
+	InspectDelay *int64 `json:"inspect_delay,omitempty"`
+}
+
+// SNIRoutes is an array of SNIRoute
+type SNIRoutes []*SNIRoute
+
+// Validate validates the SNI route
+func (r *SNIRoute) Validate(formats strfmt.Registry) error {
+	if r.Name == "" || strings.ContainsAny(r.Name, " \t") {
+		return fmt.Errorf("invalid name %s", r.Name)
+	}
+	if r.Backend == "" {
+		return fmt.Errorf("backend is required")
+	}
+	if len(r.ServerNames) == 0 {
+		return fmt.Errorf("at least one server name is required")
+	}
+	for _, n := range r.ServerNames {
+		if n == "" || strings.ContainsAny(n, " \t") {
+			return fmt.Errorf("invalid server name '%s'", n)
+		}
+	}
+	if r.InspectDelay != nil && *r.InspectDelay <= 0 {
+		return fmt.Errorf("inspect_delay must be greater than 0")
+	}
+	return nil
+}
+
+// GetSNIRoutes returns configuration version and an array of SNI routes of the frontend.
+// Returns error on fail.
+func (c *Client) GetSNIRoutes(frontend string, transactionID string) (int64, SNIRoutes, error) {
+	v, acls, err := c.GetACLs("frontend", frontend, transactionID)
+	if err != nil {
+		return 0, nil, err
+	}
+	_, rules, err := c.GetBackendSwitchingRules(frontend, transactionID)
+	if err != nil {
+		return 0, nil, err
+	}
+	_, tcpRules, err := c.GetTCPRequestRules("frontend", frontend, transactionID)
+	if err != nil {
+		return 0, nil, err
+	}
+	var inspectDelay *int64
+	for _, r := range tcpRules {
+		if r.Type == "inspect-delay" {
+			inspectDelay = r.Timeout
+		}
+	}
+
+	routes := SNIRoutes{}
+	for _, a := range acls {
+		if a.Criterion != sniPassthroughCriterion && a.Criterion != sniTerminationCriterion {
+			continue
+		}
+		for _, r := range rules {
+			if r.Cond != "if" || r.CondTest != a.ACLName {
+				continue
+			}
+			route := &SNIRoute{
+				Name:        a.ACLName,
+				ServerNames: strings.Fields(strings.TrimPrefix(a.Value, "-i ")),
+				Backend:     r.Name,
+				Termination: a.Criterion == sniTerminationCriterion,
+			}
+			if !route.Termination {
+				route.InspectDelay = inspectDelay
+			}
+			routes = append(routes, route)
+			break
+		}
+	}
+	return v, routes, nil
+}
+
+// GetSNIRoute returns configuration version and a requested SNI route of the frontend.
+// Returns error on fail or if SNI route does not exist.
+func (c *Client) GetSNIRoute(name string, frontend string, transactionID string) (int64, *SNIRoute, error) {
+	v, routes, err := c.GetSNIRoutes(frontend, transactionID)
+	if err != nil {
+		return 0, nil, err
+	}
+	for _, r := range routes {
+		if r.Name == name {
+			return v, r, nil
+		}
+	}
+	return v, nil, NewConfError(ErrObjectDoesNotExist, fmt.Sprintf("SNI route %s does not exist in frontend %s", name, frontend))
+}
+
+// CreateSNIRoute creates the server name acl and the backend switching rule of the SNI route in
+// the frontend. Passthrough routes also get the tcp-request rules waiting for the client hello,
+// shared by all passthrough routes of the frontend. One of version or transactionID is mandatory.
+// Returns error on fail, nil on success.
+func (c *Client) CreateSNIRoute(frontend string, data *SNIRoute, transactionID string, version int64) error {
+	if c.UseValidation {
+		validationErr := data.Validate(strfmt.Default)
+		if validationErr != nil {
+			return NewConfError(ErrValidationError, validationErr.Error())
+		}
+	}
+
+	if err := c.authorize("CreateSNIRoute", "sni_route", data.Name, "frontend", frontend, transactionID); err != nil {
+		return err
+	}
+
+	p, t, err := c.loadDataForChange(transactionID, version)
+	if err != nil {
+		return err
+	}
+
+	if _, r, _ := c.GetSNIRoute(data.Name, frontend, t); r != nil {
+		return c.handleError(data.Name, "frontend", frontend, t, transactionID == "",
+			NewConfError(ErrObjectAlreadyExists, fmt.Sprintf("SNI route %s already exists in frontend %s", data.Name, frontend)))
+	}
+
+	criterion := sniTerminationCriterion
+	if !data.Termination {
+		criterion = sniPassthroughCriterion
+		if err := c.ensureSNIInspectRules(frontend, data.InspectDelay, t); err != nil {
+			return c.handleError(data.Name, "frontend", frontend, t, transactionID == "", err)
+		}
+	}
+
+	_, acls, err := c.GetACLs("frontend", frontend, t)
+	if err != nil {
+		return c.handleError(data.Name, "frontend", frontend, t, transactionID == "", err)
+	}
+	aclIndex := int64(len(acls))
+	err = c.CreateACL("frontend", frontend, &models.ACL{
+		Index:     &aclIndex,
+		ACLName:   data.Name,
+		Criterion: criterion,
+		Value:     "-i " + strings.Join(data.ServerNames, " "),
+	}, t, 0)
+	if err != nil {
+		return c.handleError(data.Name, "frontend", frontend, t, transactionID == "", err)
+	}
+
+	_, rules, err := c.GetBackendSwitchingRules(frontend, t)
+	if err != nil {
+		return c.handleError(data.Name, "frontend", frontend, t, transactionID == "", err)
+	}
+	ruleIndex := int64(len(rules))
+	err = c.CreateBackendSwitchingRule(frontend, &models.BackendSwitchingRule{
+		Index:    &ruleIndex,
+		Name:     data.Backend,
+		Cond:     "if",
+		CondTest: data.Name,
+	}, t, 0)
+	if err != nil {
+		return c.handleError(data.Name, "frontend", frontend, t, transactionID == "", err)
+	}
+
+	if err := c.saveData(p, t, transactionID == ""); err != nil {
+		return err
+	}
+	return nil
+}
+
+// DeleteSNIRoute removes the acl and the backend switching rule of the SNI route from the
+// frontend. The client hello inspection rules are removed with the last passthrough route.
+// One of version or transactionID is mandatory. Returns error on fail, nil on success.
+func (c *Client) DeleteSNIRoute(name string, frontend string, transactionID string, version int64) error {
+	if err := c.authorize("DeleteSNIRoute", "sni_route", name, "frontend", frontend, transactionID); err != nil {
+		return err
+	}
+
+	p, t, err := c.loadDataForChange(transactionID, version)
+	if err != nil {
+		return err
+	}
+
+	if _, _, err := c.GetSNIRoute(name, frontend, t); err != nil {
+		return c.handleError(name, "frontend", frontend, t, transactionID == "", err)
+	}
+
+	_, rules, err := c.GetBackendSwitchingRules(frontend, t)
+	if err != nil {
+		return c.handleError(name, "frontend", frontend, t, transactionID == "", err)
+	}
+	// delete from the end so the remaining indexes stay valid
+	for i := len(rules) - 1; i >= 0; i-- {
+		if rules[i].Cond == "if" && rules[i].CondTest == name {
+			if err := c.DeleteBackendSwitchingRule(*rules[i].Index, frontend, t, 0); err != nil {
+				return c.handleError(name, "frontend", frontend, t, transactionID == "", err)
+			}
+		}
+	}
+
+	_, acls, err := c.GetACLs("frontend", frontend, t)
+	if err != nil {
+		return c.handleError(name, "frontend", frontend, t, transactionID == "", err)
+	}
+	for i := len(acls) - 1; i >= 0; i-- {
+		if acls[i].ACLName == name {
+			if err := c.DeleteACL(*acls[i].Index, "frontend", frontend, t, 0); err != nil {
+				return c.handleError(name, "frontend", frontend, t, transactionID == "", err)
+			}
+		}
+	}
+
+	_, routes, err := c.GetSNIRoutes(frontend, t)
+	if err != nil {
+		return c.handleError(name, "frontend", frontend, t, transactionID == "", err)
+	}
+	passthrough := false
+	for _, r := range routes {
+		if !r.Termination {
+			passthrough = true
+		}
+	}
+	if !passthrough {
+		if err := c.deleteSNIInspectRules(frontend, t); err != nil {
+			return c.handleError(name, "frontend", frontend, t, transactionID == "", err)
+		}
+	}
+
+	if err := c.saveData(p, t, transactionID == ""); err != nil {
+		return err
+	}
+	return nil
+}
+
+// ensureSNIInspectRules creates the inspect-delay rule and the rule accepting connections once the
+// client hello is received, if the frontend does not have them yet
+func (c *Client) ensureSNIInspectRules(frontend string, inspectDelay *int64, t string) error {
+	_, rules, err := c.GetTCPRequestRules("frontend", frontend, t)
+	if err != nil {
+		return err
+	}
+	hasDelay, hasAccept := false, false
+	for _, r := range rules {
+		if r.Type == "inspect-delay" {
+			hasDelay = true
+		}
+		if isSNIAcceptRule(r) {
+			hasAccept = true
+		}
+	}
+
+	if !hasDelay {
+		delay := sniDefaultInspectDelay
+		if inspectDelay != nil {
+			delay = *inspectDelay
+		}
+		index := int64(0)
+		err := c.CreateTCPRequestRule("frontend", frontend, &models.TCPRequestRule{
+			Index:   &index,
+			Type:    "inspect-delay",
+			Timeout: &delay,
+		}, t, 0)
+		if err != nil {
+			return err
+		}
+	}
+	if !hasAccept {
+		index := int64(1)
+		err := c.CreateTCPRequestRule("frontend", frontend, &models.TCPRequestRule{
+			Index:    &index,
+			Type:     "content",
+			Action:   "accept",
+			Cond:     "if",
+			CondTest: sniHelloCond,
+		}, t, 0)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// deleteSNIInspectRules removes the client hello accept rule, and the inspect-delay rule when no
+// other content rule needs it
+func (c *Client) deleteSNIInspectRules(frontend string, t string) error {
+	_, rules, err := c.GetTCPRequestRules("frontend", frontend, t)
+	if err != nil {
+		return err
+	}
+	for i := len(rules) - 1; i >= 0; i-- {
+		if isSNIAcceptRule(rules[i]) {
+			if err := c.DeleteTCPRequestRule(*rules[i].Index, "frontend", frontend, t, 0); err != nil {
+				return err
+			}
+		}
+	}
+
+	_, rules, err = c.GetTCPRequestRules("frontend", frontend, t)
+	if err != nil {
+		return err
+	}
+	for _, r := range rules {
+		if r.Type == "content" {
+			return nil
+		}
+	}
+	for i := len(rules) - 1; i >= 0; i-- {
+		if rules[i].Type == "inspect-delay" {
+			if err := c.DeleteTCPRequestRule(*rules[i].Index, "frontend", frontend, t, 0); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func isSNIAcceptRule(r *models.TCPRequestRule) bool {
+	return r.Type == "content" && r.Action == "accept" && r.Cond == "if" && r.CondTest == sniHelloCond
+}
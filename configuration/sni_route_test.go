@@ -0,0 +1,92 @@
+// Copyright 2019 HAProxy Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package configuration
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestCreateDeleteSNIRoute(t *testing.T) {
+	delay := int64(3000)
+	r := &SNIRoute{
+		Name:         "sni_test",
+		ServerNames:  []string{"www.example.com", "example.com"},
+		Backend:      "test",
+		InspectDelay: &delay,
+	}
+
+	err := client.CreateSNIRoute("test_2", r, "", version)
+	if err != nil {
+		t.Error(err.Error())
+	} else {
+		version++
+	}
+
+	v, route, err := client.GetSNIRoute("sni_test", "test_2", "")
+	if err != nil {
+		t.Error(err.Error())
+	}
+
+	if !reflect.DeepEqual(route, r) {
+		fmt.Printf("Created SNI route: %v\n", route)
+		fmt.Printf("Given SNI route: %v\n", r)
+		t.Error("Created SNI route not equal to given SNI route")
+	}
+
+	if v != version {
+		t.Errorf("Version %v returned, expected %v", v, version)
+	}
+
+	_, rules, err := client.GetTCPRequestRules("frontend", "test_2", "")
+	if err != nil {
+		t.Error(err.Error())
+	} else if len(rules) != 2 {
+		t.Errorf("%v tcp-request rules returned, expected 2", len(rules))
+	}
+
+	err = client.CreateSNIRoute("test_2", r, "", version)
+	if err == nil {
+		t.Error("Should throw error, SNI route already exists")
+		version++
+	}
+
+	err = client.DeleteSNIRoute("sni_test", "test_2", "", version)
+	if err != nil {
+		t.Error(err.Error())
+	} else {
+		version++
+	}
+
+	_, _, err = client.GetSNIRoute("sni_test", "test_2", "")
+	if err == nil {
+		t.Error("DeleteSNIRoute failed, SNI route still exists")
+	}
+
+	_, rules, err = client.GetTCPRequestRules("frontend", "test_2", "")
+	if err != nil {
+		t.Error(err.Error())
+	} else if len(rules) != 0 {
+		t.Errorf("%v tcp-request rules returned, expected 0", len(rules))
+	}
+
+	err = client.DeleteSNIRoute("sni_test", "test_2", "", version)
+	if err == nil {
+		t.Error("Should throw error, SNI route does not exist")
+		version++
+	}
+}
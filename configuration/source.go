@@ -0,0 +1,207 @@
+// Copyright 2019 HAProxy Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package configuration
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	parser "github.com/haproxytech/config-parser/v3"
+)
+
+const sourceDirective = "source"
+
+var sourcePortRange = regexp.MustCompile(`^([0-9]+)(-([0-9]+))?$`)
+
+// Source is the source directive setting the address outgoing connections to the servers are
+// bound to. Usesrc is an address[:port], client, clientip or hdr_ip(<hdr>[,<occ>]) and requires
+// HAProxy built with transparent proxy support.
+type Source struct {
+	Address      string
+	Port         *int64
+	PortRangeEnd *int64
+	Usesrc       string
+	Interface    string
+}
+
+// Validate checks the address, the port range and that usesrc and interface are not both set
+func (s *Source) Validate() error {
+	if s.Address == "" || strings.ContainsAny(s.Address, " \t#") {
+		return fmt.Errorf("invalid source address %s", s.Address)
+	}
+	if s.Port != nil && (*s.Port < 0 || *s.Port > 65535) {
+		return fmt.Errorf("source port %d out of range", *s.Port)
+	}
+	if s.PortRangeEnd != nil {
+		if s.Port == nil || *s.PortRangeEnd <= *s.Port || *s.PortRangeEnd > 65535 {
+			return fmt.Errorf("invalid source port range")
+		}
+	}
+	if s.Usesrc != "" && s.Interface != "" {
+		return fmt.Errorf("source usesrc and interface are mutually exclusive")
+	}
+	if strings.ContainsAny(s.Usesrc, " \t#") {
+		return fmt.Errorf("invalid source usesrc %s", s.Usesrc)
+	}
+	if strings.ContainsAny(s.Interface, " \t#") {
+		return fmt.Errorf("invalid source interface %s", s.Interface)
+	}
+	return nil
+}
+
+// GetSource returns configuration version and the source of the defaults or backend section.
+// Returns error on fail or if source is not set.
+func (c *Client) GetSource(parentType string, parentName string, transactionID string) (int64, *Source, error) {
+	section, name, err := sourceSection(parentType, parentName)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	p, err := c.GetParser(transactionID)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	v, err := c.GetVersion(transactionID)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	if !c.checkSectionExists(section, name, p) {
+		return v, nil, NewConfError(ErrObjectDoesNotExist, fmt.Sprintf("%s %s does not exist", parentType, parentName))
+	}
+
+	value, found, err := getRawDirective(p, section, name, sourceDirective)
+	if err != nil {
+		return v, nil, c.handleError(sourceDirective, parentType, parentName, "", false, err)
+	}
+	if !found {
+		return v, nil, NewConfError(ErrObjectDoesNotExist, fmt.Sprintf("%s not set in %s %s", sourceDirective, parentType, parentName))
+	}
+	s, err := parseSource(value)
+	if err != nil {
+		return v, nil, c.handleError(sourceDirective, parentType, parentName, "", false, err)
+	}
+	return v, s, nil
+}
+
+// SetSource sets the source of the defaults or backend section, nil data removes it. One of
+// version or transactionID is mandatory. Returns error on fail, nil on success.
+func (c *Client) SetSource(parentType string, parentName string, data *Source, transactionID string, version int64) error {
+	section, name, err := sourceSection(parentType, parentName)
+	if err != nil {
+		return err
+	}
+	if data != nil {
+		if err := data.Validate(); err != nil {
+			return NewConfError(ErrValidationError, err.Error())
+		}
+	}
+
+	if err := c.authorize("SetSource", "source", "", parentType, parentName, transactionID); err != nil {
+		return err
+	}
+
+	p, t, err := c.loadDataForChange(transactionID, version)
+	if err != nil {
+		return err
+	}
+
+	if !c.checkSectionExists(section, name, p) {
+		e := NewConfError(ErrObjectDoesNotExist, fmt.Sprintf("%s %s does not exist", parentType, parentName))
+		return c.handleError(sourceDirective, parentType, parentName, t, transactionID == "", e)
+	}
+
+	var value *string
+	if data != nil {
+		s := serializeSource(data)
+		value = &s
+	}
+	if err := setRawDirective(p, section, name, sourceDirective, value); err != nil {
+		return c.handleError(sourceDirective, parentType, parentName, t, transactionID == "", err)
+	}
+
+	if err := c.saveData(p, t, transactionID == ""); err != nil {
+		return err
+	}
+	return nil
+}
+
+func parseSource(value string) (*Source, error) {
+	words := strings.Fields(value)
+	if len(words) == 0 {
+		return nil, fmt.Errorf("%s requires an address", sourceDirective)
+	}
+	s := &Source{Address: words[0]}
+	// like HAProxy the port follows the last colon
+	if i := strings.LastIndex(words[0], ":"); i != -1 {
+		if m := sourcePortRange.FindStringSubmatch(words[0][i+1:]); m != nil {
+			s.Address = words[0][:i]
+			port, _ := strconv.ParseInt(m[1], 10, 64)
+			s.Port = &port
+			if m[3] != "" {
+				end, _ := strconv.ParseInt(m[3], 10, 64)
+				s.PortRangeEnd = &end
+			}
+		}
+	}
+	for i := 1; i < len(words); i += 2 {
+		if i+1 >= len(words) {
+			return nil, fmt.Errorf("%s %s requires a value", sourceDirective, words[i])
+		}
+		switch words[i] {
+		case "usesrc":
+			s.Usesrc = words[i+1]
+		case "interface":
+			s.Interface = words[i+1]
+		default:
+			return nil, fmt.Errorf("unknown %s parameter %s", sourceDirective, words[i])
+		}
+	}
+	return s, nil
+}
+
+func serializeSource(s *Source) string {
+	address := s.Address
+	if s.Port != nil {
+		address = address + ":" + strconv.FormatInt(*s.Port, 10)
+		if s.PortRangeEnd != nil {
+			address = address + "-" + strconv.FormatInt(*s.PortRangeEnd, 10)
+		}
+	}
+	words := []string{address}
+	if s.Usesrc != "" {
+		words = append(words, "usesrc", s.Usesrc)
+	}
+	if s.Interface != "" {
+		words = append(words, "interface", s.Interface)
+	}
+	return strings.Join(words, " ")
+}
+
+func sourceSection(parentType string, parentName string) (parser.Section, string, error) {
+	switch parentType {
+	case "defaults":
+		return parser.Defaults, parser.DefaultSectionName, nil
+	case "backend":
+		return parser.Backends, parentName, nil
+	default:
+		// the source address applies to connections to the servers
+		return "", "", NewConfError(ErrValidationError, fmt.Sprintf("%s is not supported in %s", sourceDirective, parentType))
+	}
+}
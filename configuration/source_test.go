@@ -0,0 +1,78 @@
+// Copyright 2019 HAProxy Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package configuration
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSource(t *testing.T) {
+	port := int64(1024)
+	end := int64(65535)
+	s := &Source{
+		Address:      "192.168.1.200",
+		Port:         &port,
+		PortRangeEnd: &end,
+		Usesrc:       "clientip",
+	}
+
+	if err := client.SetSource("frontend", "test", s, "", version); err == nil {
+		t.Error("Should throw error, source not supported in frontends")
+	}
+	invalid := *s
+	invalid.Interface = "eth0"
+	if err := client.SetSource("backend", "test", &invalid, "", version); err == nil {
+		t.Error("Should throw error, usesrc and interface are exclusive")
+	}
+
+	if err := client.SetSource("backend", "test", s, "", version); err != nil {
+		t.Error(err.Error())
+	} else {
+		version++
+	}
+
+	v, source, err := client.GetSource("backend", "test", "")
+	if err != nil {
+		t.Error(err.Error())
+	}
+	if !reflect.DeepEqual(source, s) {
+		t.Errorf("Source %v not equal to given %v", source, s)
+	}
+	if v != version {
+		t.Errorf("Version %v returned, expected %v", v, version)
+	}
+
+	s = &Source{Address: "0.0.0.0", Interface: "eth1"}
+	if err := client.SetSource("backend", "test", s, "", version); err != nil {
+		t.Error(err.Error())
+	} else {
+		version++
+	}
+	_, source, _ = client.GetSource("backend", "test", "")
+	if !reflect.DeepEqual(source, s) {
+		t.Errorf("Source %v not equal to given %v", source, s)
+	}
+
+	if err := client.SetSource("backend", "test", nil, "", version); err != nil {
+		t.Error(err.Error())
+	} else {
+		version++
+	}
+	if _, _, err := client.GetSource("backend", "test", ""); err == nil {
+		t.Error("Should throw error, source removed from backend test")
+	}
+}
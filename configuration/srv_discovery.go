@@ -0,0 +1,258 @@
+// Copyright 2019 HAProxy Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package configuration
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	parser "github.com/haproxytech/config-parser/v3"
+	"github.com/haproxytech/config-parser/v3/types"
+	"github.com/haproxytech/models/v2"
+)
+
+var srvRecordName = regexp.MustCompile(`^_[a-zA-Z0-9-]+\._(tcp|udp)\.[^\s#]+$`)
+
+// SRVDiscovery is a server-template of a backend filled from the SRV records of a service
+type SRVDiscovery struct {
+	// Service is the SRV record name, for example _http._tcp.example.local
+	Service string `json:"service"`
+	// Prefix of the server names, slots are named <prefix>1 to <prefix><count>
+	Prefix string `json:"prefix"`
+	Count  int64  `json:"count"`
+	// Resolvers section used to resolve the service
+	Resolvers string `json:"resolvers"`
+	// Nameservers of the resolvers section, used only when the section is created
+	Nameservers   []*models.Nameserver `json:"nameservers,omitempty"`
+	ResolvePrefer string               `json:"resolve_prefer,omitempty"`
+	Check         bool                 `json:"check,omitempty"`
+}
+
+// Validate validates the SRV discovery settings
+func (d *SRVDiscovery) Validate() error {
+	if !srvRecordName.MatchString(d.Service) {
+		return fmt.Errorf("service %s is not an SRV record name _service._proto.name", d.Service)
+	}
+	if d.Prefix == "" || strings.ContainsAny(d.Prefix, " \t#") {
+		return fmt.Errorf("invalid server-template prefix %s", d.Prefix)
+	}
+	if d.Count <= 0 {
+		return fmt.Errorf("server-template count has to be greater than 0")
+	}
+	if d.Resolvers == "" {
+		return fmt.Errorf("resolvers section is required")
+	}
+	if d.ResolvePrefer != "" && d.ResolvePrefer != "ipv4" && d.ResolvePrefer != "ipv6" {
+		return fmt.Errorf("resolve-prefer has to be ipv4 or ipv6")
+	}
+	return nil
+}
+
+// ServerNames returns the names of the server slots of the template
+func (d *SRVDiscovery) ServerNames() []string {
+	names := make([]string, 0, d.Count)
+	for i := int64(1); i <= d.Count; i++ {
+		names = append(names, fmt.Sprintf("%s%d", d.Prefix, i))
+	}
+	return names
+}
+
+// GetSRVDiscovery returns configuration version and the SRV discovery server-template of the
+// backend with the given prefix. Returns error on fail or if it does not exist.
+func (c *Client) GetSRVDiscovery(backend string, prefix string, transactionID string) (int64, *SRVDiscovery, error) {
+	p, err := c.GetParser(transactionID)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	v, err := c.GetVersion(transactionID)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	lines, err := getRawLines(p, parser.Backends, backend)
+	if err != nil {
+		return v, nil, c.handleError(prefix, "backend", backend, "", false, err)
+	}
+	for _, l := range lines {
+		if d := parseSRVDiscovery(l.Value); d != nil && d.Prefix == prefix {
+			return v, d, nil
+		}
+	}
+	return v, nil, NewConfError(ErrObjectDoesNotExist, fmt.Sprintf("SRV discovery %s does not exist in backend %s", prefix, backend))
+}
+
+// ApplySRVDiscovery creates or replaces the SRV discovery server-template of the backend with the
+// prefix of data. The resolvers section is created with the given nameservers when it does not
+// exist. One of version or transactionID is mandatory. Returns error on fail, nil on success.
+func (c *Client) ApplySRVDiscovery(backend string, data *SRVDiscovery, transactionID string, version int64) error {
+	if err := data.Validate(); err != nil {
+		return NewConfError(ErrValidationError, err.Error())
+	}
+
+	if err := c.authorize("ApplySRVDiscovery", "srv_discovery", data.Prefix, "backend", backend, transactionID); err != nil {
+		return err
+	}
+
+	p, t, err := c.loadDataForChange(transactionID, version)
+	if err != nil {
+		return err
+	}
+
+	if !c.checkSectionExists(parser.Backends, backend, p) {
+		e := NewConfError(ErrObjectDoesNotExist, fmt.Sprintf("Backend %s does not exist", backend))
+		return c.handleError(backend, "", "", t, transactionID == "", e)
+	}
+
+	if !c.checkSectionExists(parser.Resolvers, data.Resolvers, p) {
+		if len(data.Nameservers) == 0 {
+			e := NewConfError(ErrValidationError, fmt.Sprintf("resolvers %s does not exist and no nameservers are given", data.Resolvers))
+			return c.handleError(data.Resolvers, "", "", t, transactionID == "", e)
+		}
+		if err := c.CreateResolver(&models.Resolver{Name: data.Resolvers}, t, 0); err != nil {
+			return c.handleError(data.Resolvers, "", "", t, transactionID == "", err)
+		}
+		for _, ns := range data.Nameservers {
+			if err := c.CreateNameserver(data.Resolvers, ns, t, 0); err != nil {
+				return c.handleError(ns.Name, "resolvers", data.Resolvers, t, transactionID == "", err)
+			}
+		}
+	}
+
+	lines, err := getRawLines(p, parser.Backends, backend)
+	if err != nil {
+		return c.handleError(data.Prefix, "backend", backend, t, transactionID == "", err)
+	}
+	line := types.UnProcessed{Value: serializeSRVDiscovery(data)}
+	found := false
+	for i, l := range lines {
+		if d := parseSRVDiscovery(l.Value); d != nil && d.Prefix == data.Prefix {
+			lines[i] = line
+			found = true
+			break
+		}
+	}
+	if !found {
+		lines = append(lines, line)
+	}
+
+	if err := p.Set(parser.Backends, backend, "", lines); err != nil {
+		return c.handleError(data.Prefix, "backend", backend, t, transactionID == "", err)
+	}
+
+	if err := c.saveData(p, t, transactionID == ""); err != nil {
+		return err
+	}
+	return nil
+}
+
+// DeleteSRVDiscovery removes the SRV discovery server-template with the prefix from the backend,
+// the resolvers section is kept. One of version or transactionID is mandatory. Returns error on
+// fail, nil on success.
+func (c *Client) DeleteSRVDiscovery(backend string, prefix string, transactionID string, version int64) error {
+	if err := c.authorize("DeleteSRVDiscovery", "srv_discovery", prefix, "backend", backend, transactionID); err != nil {
+		return err
+	}
+
+	p, t, err := c.loadDataForChange(transactionID, version)
+	if err != nil {
+		return err
+	}
+
+	lines, err := getRawLines(p, parser.Backends, backend)
+	if err != nil {
+		return c.handleError(prefix, "backend", backend, t, transactionID == "", err)
+	}
+	result := []types.UnProcessed{}
+	for _, l := range lines {
+		if d := parseSRVDiscovery(l.Value); d != nil && d.Prefix == prefix {
+			continue
+		}
+		result = append(result, l)
+	}
+	if len(result) == len(lines) {
+		e := NewConfError(ErrObjectDoesNotExist, fmt.Sprintf("SRV discovery %s does not exist in backend %s", prefix, backend))
+		return c.handleError(prefix, "backend", backend, t, transactionID == "", e)
+	}
+
+	if len(result) == 0 {
+		err = p.Set(parser.Backends, backend, "", nil)
+	} else {
+		err = p.Set(parser.Backends, backend, "", result)
+	}
+	if err != nil {
+		return c.handleError(prefix, "backend", backend, t, transactionID == "", err)
+	}
+
+	if err := c.saveData(p, t, transactionID == ""); err != nil {
+		return err
+	}
+	return nil
+}
+
+// parseSRVDiscovery returns the SRV discovery of a server-template line, nil if the line is not
+// a server-template resolving an SRV record
+func parseSRVDiscovery(line string) *SRVDiscovery {
+	value, ok := matchRawDirective(line, "server-template")
+	if !ok {
+		return nil
+	}
+	fields := strings.Fields(value)
+	if len(fields) < 3 || !srvRecordName.MatchString(fields[2]) {
+		return nil
+	}
+	count, err := strconv.ParseInt(fields[1], 10, 64)
+	if err != nil {
+		return nil
+	}
+	d := &SRVDiscovery{
+		Prefix:  fields[0],
+		Count:   count,
+		Service: fields[2],
+	}
+	for i := 3; i < len(fields); i++ {
+		switch fields[i] {
+		case "check":
+			d.Check = true
+		case "resolvers", "resolve-prefer":
+			if i+1 >= len(fields) {
+				continue
+			}
+			if fields[i] == "resolvers" {
+				d.Resolvers = fields[i+1]
+			} else {
+				d.ResolvePrefer = fields[i+1]
+			}
+			i++
+		}
+	}
+	return d
+}
+
+func serializeSRVDiscovery(d *SRVDiscovery) string {
+	parts := []string{"server-template", d.Prefix, strconv.FormatInt(d.Count, 10), d.Service, "resolvers", d.Resolvers}
+	if d.ResolvePrefer != "" {
+		parts = append(parts, "resolve-prefer", d.ResolvePrefer)
+	}
+	// start without addresses, the slots are filled once the records are resolved
+	parts = append(parts, "init-addr", "none")
+	if d.Check {
+		parts = append(parts, "check")
+	}
+	return strings.Join(parts, " ")
+}
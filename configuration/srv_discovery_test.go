@@ -0,0 +1,82 @@
+// Copyright 2019 HAProxy Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package configuration
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestApplyDeleteSRVDiscovery(t *testing.T) {
+	d := &SRVDiscovery{
+		Service:       "_http._tcp.app.local",
+		Prefix:        "app",
+		Count:         5,
+		Resolvers:     "test",
+		ResolvePrefer: "ipv4",
+		Check:         true,
+	}
+
+	err := client.ApplySRVDiscovery("test_2", d, "", version)
+	if err != nil {
+		t.Error(err.Error())
+	} else {
+		version++
+	}
+
+	v, discovery, err := client.GetSRVDiscovery("test_2", "app", "")
+	if err != nil {
+		t.Error(err.Error())
+	}
+
+	if !reflect.DeepEqual(discovery, d) {
+		fmt.Printf("Applied SRV discovery: %v\n", discovery)
+		fmt.Printf("Given SRV discovery: %v\n", d)
+		t.Error("Applied SRV discovery not equal to given SRV discovery")
+	}
+
+	if v != version {
+		t.Errorf("Version %v returned, expected %v", v, version)
+	}
+
+	d.Service = "app.local"
+	err = client.ApplySRVDiscovery("test_2", d, "", version)
+	if err == nil {
+		t.Error("Should throw error, service is not an SRV record name")
+		version++
+	}
+
+	d.Service = "_http._tcp.app.local"
+	d.Resolvers = "doesnotexist"
+	err = client.ApplySRVDiscovery("test_2", d, "", version)
+	if err == nil {
+		t.Error("Should throw error, non existent resolvers without nameservers")
+		version++
+	}
+
+	err = client.DeleteSRVDiscovery("test_2", "app", "", version)
+	if err != nil {
+		t.Error(err.Error())
+	} else {
+		version++
+	}
+
+	_, _, err = client.GetSRVDiscovery("test_2", "app", "")
+	if err == nil {
+		t.Error("DeleteSRVDiscovery failed, SRV discovery still exists")
+	}
+}
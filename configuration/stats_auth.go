@@ -0,0 +1,298 @@
+// Copyright 2019 HAProxy Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package configuration
+
+import (
+	"fmt"
+	"strings"
+
+	strfmt "github.com/go-openapi/strfmt"
+	parser "github.com/haproxytech/config-parser/v3"
+	parser_errors "github.com/haproxytech/config-parser/v3/errors"
+	stats "github.com/haproxytech/config-parser/v3/parsers/stats/settings"
+	"github.com/haproxytech/config-parser/v3/types"
+)
+
+// StatsAuthUser is a user allowed to access the stats page
+type StatsAuthUser struct {
+	Username string `json:"username"`
+	// Password in plain text when set, returned as stored in configuration, which is the
+	// password hash for users kept in a userlist
+	Password string `json:"password"`
+}
+
+// StatsAuth are the credentials protecting the stats page of a frontend or backend. Without a
+// userlist the users are configured with stats auth lines, which HAProxy only accepts in plain
+// text. With a userlist the users are stored in it with hashed passwords and the stats page
+// requires authentication against the userlist, which is only supported in backends.
+type StatsAuth struct {
+	// Realm sent in the authentication request
+	Realm string `json:"realm,omitempty"`
+	// Users allowed to access the stats page
+	Users []*StatsAuthUser `json:"users"`
+	// Admin enables the admin level of the stats page for authenticated users
+	Admin bool `json:"admin,omitempty"`
+	// Userlist keeping the users, created when it does not exist. The users of the userlist
+	// are replaced with the given ones.
+	Userlist string `json:"userlist,omitempty"`
+}
+
+// Validate validates the stats auth
+func (a *StatsAuth) Validate(formats strfmt.Registry) error {
+	if strings.ContainsAny(a.Realm, " \t") {
+		return fmt.Errorf("realm can not contain spaces")
+	}
+	if strings.ContainsAny(a.Userlist, " \t") {
+		return fmt.Errorf("invalid userlist name %s", a.Userlist)
+	}
+	if len(a.Users) == 0 {
+		return fmt.Errorf("at least one user is required")
+	}
+	for _, u := range a.Users {
+		if u.Username == "" || strings.ContainsAny(u.Username, " \t:") {
+			return fmt.Errorf("invalid username '%s'", u.Username)
+		}
+		if u.Password == "" || strings.ContainsAny(u.Password, " \t") {
+			return fmt.Errorf("invalid password for user %s, password can not be empty or contain spaces", u.Username)
+		}
+		if a.Userlist == "" && strings.Contains(u.Password, ":") {
+			return fmt.Errorf("invalid password for user %s, stats auth passwords can not contain ':'", u.Username)
+		}
+	}
+	return nil
+}
+
+// GetStatsAuth returns configuration version and the stats page credentials of the frontend or
+// backend. Returns error on fail or if the stats page is not protected.
+func (c *Client) GetStatsAuth(parentType string, parentName string, transactionID string) (int64, *StatsAuth, error) {
+	p, err := c.GetParser(transactionID)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	v, err := c.GetVersion(transactionID)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	section := statsAuthSection(parentType)
+	settings, err := getStatsSettings(section, parentName, p)
+	if err != nil {
+		return v, nil, c.handleError("", parentType, parentName, "", false, err)
+	}
+
+	auth := &StatsAuth{Users: []*StatsAuthUser{}}
+	found := false
+	for _, s := range settings {
+		switch st := s.(type) {
+		case *stats.Auth:
+			found = true
+			auth.Users = append(auth.Users, &StatsAuthUser{Username: st.User, Password: st.Password})
+		case *stats.Realm:
+			auth.Realm = st.Realm
+		case *stats.Admin:
+			auth.Admin = true
+		case *stats.HTTPRequest:
+			if userlist := statsAuthUserlist(st); userlist != "" {
+				found = true
+				auth.Userlist = userlist
+				auth.Realm = strings.TrimSpace(strings.TrimPrefix(st.Type, "auth realm"))
+			}
+		}
+	}
+	if !found {
+		return v, nil, NewConfError(ErrObjectDoesNotExist, fmt.Sprintf("Stats auth not configured in %s %s", parentType, parentName))
+	}
+
+	if auth.Userlist != "" {
+		data, err := p.Get(parser.UserList, auth.Userlist, "user", false)
+		if err == nil {
+			for _, u := range data.([]types.User) {
+				auth.Users = append(auth.Users, &StatsAuthUser{Username: u.Name, Password: u.Password})
+			}
+		}
+	}
+	return v, auth, nil
+}
+
+// ApplyStatsAuth replaces the stats page credentials of the frontend or backend, keeping other
+// stats settings. Plain text passwords of users kept in a userlist are hashed before they are
+// stored. One of version or transactionID is mandatory. Returns error on fail, nil on success.
+func (c *Client) ApplyStatsAuth(parentType string, parentName string, data *StatsAuth, transactionID string, version int64) error {
+	if c.UseValidation {
+		validationErr := data.Validate(strfmt.Default)
+		if validationErr != nil {
+			return NewConfError(ErrValidationError, validationErr.Error())
+		}
+	}
+	if data.Userlist != "" && parentType == "frontend" {
+		return NewConfError(ErrValidationError, "stats auth with a userlist is not supported in frontends")
+	}
+
+	if err := c.authorize("ApplyStatsAuth", "stats_auth", "", parentType, parentName, transactionID); err != nil {
+		return err
+	}
+
+	p, t, err := c.loadDataForChange(transactionID, version)
+	if err != nil {
+		return err
+	}
+
+	section := statsAuthSection(parentType)
+	settings, err := getStatsSettings(section, parentName, p)
+	if err != nil {
+		return c.handleError("", parentType, parentName, t, transactionID == "", err)
+	}
+	settings = withoutStatsAuth(settings)
+
+	if data.Realm != "" && data.Userlist == "" {
+		settings = append(settings, &stats.Realm{Realm: data.Realm})
+	}
+
+	if data.Userlist == "" {
+		for _, u := range data.Users {
+			settings = append(settings, &stats.Auth{User: u.Username, Password: u.Password})
+		}
+		if data.Admin {
+			settings = append(settings, &stats.Admin{Cond: "if", CondTest: "TRUE"})
+		}
+	} else {
+		if err := c.setStatsAuthUserlist(data, p); err != nil {
+			return c.handleError(data.Userlist, "userlist", data.Userlist, t, transactionID == "", err)
+		}
+		cond := fmt.Sprintf("{ http_auth(%s) }", data.Userlist)
+		authType := "auth"
+		if data.Realm != "" {
+			authType = fmt.Sprintf("auth realm %s", data.Realm)
+		}
+		settings = append(settings, &stats.HTTPRequest{Type: authType, Cond: "unless", CondTest: cond})
+		if data.Admin {
+			settings = append(settings, &stats.Admin{Cond: "if", CondTest: cond})
+		}
+	}
+
+	if err := p.Set(section, parentName, "stats", settings); err != nil {
+		return c.handleError("", parentType, parentName, t, transactionID == "", err)
+	}
+
+	if err := c.saveData(p, t, transactionID == ""); err != nil {
+		return err
+	}
+	return nil
+}
+
+// DeleteStatsAuth removes the stats page credentials of the frontend or backend, keeping other
+// stats settings. A userlist used by the credentials is kept. One of version or transactionID
+// is mandatory. Returns error on fail, nil on success.
+func (c *Client) DeleteStatsAuth(parentType string, parentName string, transactionID string, version int64) error {
+	if err := c.authorize("DeleteStatsAuth", "stats_auth", "", parentType, parentName, transactionID); err != nil {
+		return err
+	}
+
+	p, t, err := c.loadDataForChange(transactionID, version)
+	if err != nil {
+		return err
+	}
+
+	if _, _, err := c.GetStatsAuth(parentType, parentName, t); err != nil {
+		return c.handleError("", parentType, parentName, t, transactionID == "", err)
+	}
+
+	section := statsAuthSection(parentType)
+	settings, err := getStatsSettings(section, parentName, p)
+	if err != nil {
+		return c.handleError("", parentType, parentName, t, transactionID == "", err)
+	}
+	settings = withoutStatsAuth(settings)
+
+	var value interface{}
+	if len(settings) > 0 {
+		value = settings
+	}
+	if err := p.Set(section, parentName, "stats", value); err != nil {
+		return c.handleError("", parentType, parentName, t, transactionID == "", err)
+	}
+
+	if err := c.saveData(p, t, transactionID == ""); err != nil {
+		return err
+	}
+	return nil
+}
+
+// setStatsAuthUserlist creates the userlist if it does not exist and replaces its users with
+// the stats users, hashing plain text passwords
+func (c *Client) setStatsAuthUserlist(data *StatsAuth, p *parser.Parser) error {
+	if !c.checkSectionExists(parser.UserList, data.Userlist, p) {
+		if err := p.SectionsCreate(parser.UserList, data.Userlist); err != nil {
+			return err
+		}
+	}
+	users := []types.User{}
+	for _, u := range data.Users {
+		password, err := c.hashUserPassword(u.Password)
+		if err != nil {
+			return err
+		}
+		users = append(users, types.User{Name: u.Username, Password: password})
+	}
+	return p.Set(parser.UserList, data.Userlist, "user", users)
+}
+
+func statsAuthSection(parentType string) parser.Section {
+	if parentType == "frontend" {
+		return parser.Frontends
+	}
+	return parser.Backends
+}
+
+func getStatsSettings(section parser.Section, name string, p *parser.Parser) ([]types.StatsSettings, error) {
+	data, err := p.Get(section, name, "stats", false)
+	if err != nil {
+		if err == parser_errors.ErrFetch {
+			return []types.StatsSettings{}, nil
+		}
+		return nil, err
+	}
+	return data.([]types.StatsSettings), nil
+}
+
+// withoutStatsAuth returns stats settings without the ones managing credentials
+func withoutStatsAuth(settings []types.StatsSettings) []types.StatsSettings {
+	result := []types.StatsSettings{}
+	for _, s := range settings {
+		switch v := s.(type) {
+		case *stats.Auth, *stats.Realm, *stats.Admin:
+			continue
+		case *stats.HTTPRequest:
+			if statsAuthUserlist(v) != "" {
+				continue
+			}
+		}
+		result = append(result, s)
+	}
+	return result
+}
+
+// statsAuthUserlist returns the userlist of a stats http-request auth rule created by ApplyStatsAuth
+func statsAuthUserlist(r *stats.HTTPRequest) string {
+	if !strings.HasPrefix(r.Type, "auth") || r.Cond != "unless" {
+		return ""
+	}
+	if !strings.HasPrefix(r.CondTest, "{ http_auth(") || !strings.HasSuffix(r.CondTest, ") }") {
+		return ""
+	}
+	return strings.TrimSuffix(strings.TrimPrefix(r.CondTest, "{ http_auth("), ") }")
+}
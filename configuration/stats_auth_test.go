@@ -0,0 +1,100 @@
+// Copyright 2019 HAProxy Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package configuration
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestApplyDeleteStatsAuth(t *testing.T) {
+	a := &StatsAuth{
+		Realm: "HAProxy",
+		Users: []*StatsAuthUser{
+			{Username: "admin", Password: "secret"},
+		},
+		Admin: true,
+	}
+
+	err := client.ApplyStatsAuth("frontend", "test", a, "", version)
+	if err != nil {
+		t.Error(err.Error())
+	} else {
+		version++
+	}
+
+	v, auth, err := client.GetStatsAuth("frontend", "test", "")
+	if err != nil {
+		t.Error(err.Error())
+	}
+
+	if !reflect.DeepEqual(auth, a) {
+		fmt.Printf("Applied stats auth: %v\n", auth)
+		fmt.Printf("Given stats auth: %v\n", a)
+		t.Error("Applied stats auth not equal to given stats auth")
+	}
+
+	if v != version {
+		t.Errorf("Version %v returned, expected %v", v, version)
+	}
+
+	err = client.DeleteStatsAuth("frontend", "test", "", version)
+	if err != nil {
+		t.Error(err.Error())
+	} else {
+		version++
+	}
+
+	_, _, err = client.GetStatsAuth("frontend", "test", "")
+	if err == nil {
+		t.Error("DeleteStatsAuth failed, stats auth still exists")
+	}
+
+	a.Userlist = "stats_users"
+	err = client.ApplyStatsAuth("frontend", "test", a, "", version)
+	if err == nil {
+		t.Error("Should throw error, stats auth with a userlist is not supported in frontends")
+		version++
+	}
+
+	err = client.ApplyStatsAuth("backend", "test_2", a, "", version)
+	if err != nil {
+		t.Error(err.Error())
+	} else {
+		version++
+	}
+
+	_, auth, err = client.GetStatsAuth("backend", "test_2", "")
+	if err != nil {
+		t.Error(err.Error())
+	} else {
+		if auth.Userlist != "stats_users" || auth.Realm != "HAProxy" || !auth.Admin {
+			t.Errorf("Userlist, realm or admin not applied: %v", auth)
+		}
+		if len(auth.Users) != 1 || !strings.HasPrefix(auth.Users[0].Password, "$6$") {
+			t.Errorf("Userlist password not hashed: %v", auth.Users)
+		}
+	}
+
+	err = client.DeleteStatsAuth("backend", "test_2", "", version)
+	if err != nil {
+		t.Error(err.Error())
+	} else {
+		version++
+	}
+}
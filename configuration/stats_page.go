@@ -0,0 +1,231 @@
+// Copyright 2019 HAProxy Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package configuration
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	parser "github.com/haproxytech/config-parser/v3"
+	stats "github.com/haproxytech/config-parser/v3/parsers/stats/settings"
+	"github.com/haproxytech/config-parser/v3/types"
+
+	"github.com/haproxytech/client-native/v2/misc"
+)
+
+// StatsPage is the built-in stats page of a frontend or backend
+type StatsPage struct {
+	Enable      bool   `json:"enable,omitempty"`
+	URI         string `json:"uri,omitempty"`
+	HideVersion bool   `json:"hide_version,omitempty"`
+	// Refresh is the auto refresh delay of the page in milliseconds
+	Refresh *int64 `json:"refresh,omitempty"`
+	Realm   string `json:"realm,omitempty"`
+	// Users allowed to access the page with stats auth, passwords in plain text
+	Users []*StatsAuthUser `json:"users,omitempty"`
+	// AdminRules are the stats admin rules enabling the admin level of the page, in order. Their
+	// index is ignored when the page is set.
+	AdminRules []*StatsAdminRule `json:"admin_rules,omitempty"`
+}
+
+// StatsAdminRule enables the admin level of the stats page when the condition matches
+type StatsAdminRule struct {
+	// Index of the rule among the stats admin rules of the section
+	Index    *int64 `json:"index,omitempty"`
+	Cond     string `json:"cond"`
+	CondTest string `json:"cond_test"`
+}
+
+// Validate validates the stats admin rule
+func (r *StatsAdminRule) Validate() error {
+	if r.Cond != "if" && r.Cond != "unless" {
+		return fmt.Errorf("stats admin condition must be if or unless")
+	}
+	if strings.TrimSpace(r.CondTest) == "" || strings.ContainsAny(r.CondTest, "#\n") {
+		return fmt.Errorf("invalid stats admin condition test %s", r.CondTest)
+	}
+	return nil
+}
+
+// Validate validates the stats page
+func (s *StatsPage) Validate() error {
+	if s.URI != "" && (!strings.HasPrefix(s.URI, "/") || strings.ContainsAny(s.URI, " \t#")) {
+		return fmt.Errorf("invalid stats uri %s", s.URI)
+	}
+	if s.Refresh != nil && *s.Refresh <= 0 {
+		return fmt.Errorf("stats refresh has to be greater than 0")
+	}
+	if strings.ContainsAny(s.Realm, " \t#") {
+		return fmt.Errorf("realm can not contain spaces or '#'")
+	}
+	for _, u := range s.Users {
+		if u.Username == "" || strings.ContainsAny(u.Username, " \t:#") {
+			return fmt.Errorf("invalid username '%s'", u.Username)
+		}
+		if u.Password == "" || strings.ContainsAny(u.Password, " \t:#") {
+			return fmt.Errorf("invalid password for user %s, password can not be empty or contain spaces, ':' or '#'", u.Username)
+		}
+	}
+	for _, r := range s.AdminRules {
+		if err := r.Validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetStatsPage returns configuration version and the stats page of the frontend or backend.
+// Returns error on fail.
+func (c *Client) GetStatsPage(parentType string, parentName string, transactionID string) (int64, *StatsPage, error) {
+	section, err := statsPageSection(parentType)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	p, err := c.GetParser(transactionID)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	v, err := c.GetVersion(transactionID)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	if !c.checkSectionExists(section, parentName, p) {
+		return v, nil, NewConfError(ErrParentDoesNotExist, fmt.Sprintf("%s %s does not exist", parentType, parentName))
+	}
+
+	settings, err := getStatsSettings(section, parentName, p)
+	if err != nil {
+		return v, nil, c.handleError("", parentType, parentName, "", false, err)
+	}
+
+	page := &StatsPage{}
+	for _, s := range settings {
+		switch st := s.(type) {
+		case *stats.OneWord:
+			switch st.Name {
+			case "enable":
+				page.Enable = true
+			case "hide-version":
+				page.HideVersion = true
+			}
+		case *stats.URI:
+			page.URI = st.Prefix
+		case *stats.Refresh:
+			page.Refresh = misc.ParseTimeout(st.Delay)
+		case *stats.Realm:
+			page.Realm = st.Realm
+		case *stats.Auth:
+			page.Users = append(page.Users, &StatsAuthUser{Username: st.User, Password: st.Password})
+		case *stats.Admin:
+			index := int64(len(page.AdminRules))
+			page.AdminRules = append(page.AdminRules, &StatsAdminRule{Index: &index, Cond: st.Cond, CondTest: st.CondTest})
+		}
+	}
+	return v, page, nil
+}
+
+// SetStatsPage replaces the stats page of the frontend or backend, keeping the other stats
+// settings such as maxconn or http-request rules. One of version or transactionID is mandatory.
+// Returns error on fail, nil on success.
+func (c *Client) SetStatsPage(parentType string, parentName string, data *StatsPage, transactionID string, version int64) error {
+	section, err := statsPageSection(parentType)
+	if err != nil {
+		return err
+	}
+	if err := data.Validate(); err != nil {
+		return NewConfError(ErrValidationError, err.Error())
+	}
+
+	if err := c.authorize("SetStatsPage", "stats_page", "", parentType, parentName, transactionID); err != nil {
+		return err
+	}
+
+	p, t, err := c.loadDataForChange(transactionID, version)
+	if err != nil {
+		return err
+	}
+
+	if !c.checkSectionExists(section, parentName, p) {
+		e := NewConfError(ErrParentDoesNotExist, fmt.Sprintf("%s %s does not exist", parentType, parentName))
+		return c.handleError("", parentType, parentName, t, transactionID == "", e)
+	}
+
+	settings, err := getStatsSettings(section, parentName, p)
+	if err != nil {
+		return c.handleError("", parentType, parentName, t, transactionID == "", err)
+	}
+
+	result := []types.StatsSettings{}
+	if data.Enable {
+		result = append(result, &stats.OneWord{Name: "enable"})
+	}
+	if data.URI != "" {
+		result = append(result, &stats.URI{Prefix: data.URI})
+	}
+	if data.HideVersion {
+		result = append(result, &stats.OneWord{Name: "hide-version"})
+	}
+	if data.Refresh != nil {
+		result = append(result, &stats.Refresh{Delay: strconv.FormatInt(*data.Refresh, 10) + "ms"})
+	}
+	if data.Realm != "" {
+		result = append(result, &stats.Realm{Realm: data.Realm})
+	}
+	for _, u := range data.Users {
+		result = append(result, &stats.Auth{User: u.Username, Password: u.Password})
+	}
+	for _, r := range data.AdminRules {
+		result = append(result, &stats.Admin{Cond: r.Cond, CondTest: r.CondTest})
+	}
+	for _, s := range settings {
+		switch st := s.(type) {
+		case *stats.URI, *stats.Refresh, *stats.Realm, *stats.Auth, *stats.Admin:
+			continue
+		case *stats.OneWord:
+			if st.Name == "enable" || st.Name == "hide-version" {
+				continue
+			}
+		}
+		result = append(result, s)
+	}
+
+	var value interface{}
+	if len(result) > 0 {
+		value = result
+	}
+	if err := p.Set(section, parentName, "stats", value); err != nil {
+		return c.handleError("", parentType, parentName, t, transactionID == "", err)
+	}
+
+	if err := c.saveData(p, t, transactionID == ""); err != nil {
+		return err
+	}
+	return nil
+}
+
+func statsPageSection(parentType string) (parser.Section, error) {
+	switch parentType {
+	case "frontend":
+		return parser.Frontends, nil
+	case "backend":
+		return parser.Backends, nil
+	}
+	return "", NewConfError(ErrValidationError, fmt.Sprintf("unsupported stats page parent type %s", parentType))
+}
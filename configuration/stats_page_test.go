@@ -0,0 +1,86 @@
+// Copyright 2019 HAProxy Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package configuration
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/haproxytech/client-native/v2/misc"
+)
+
+func TestSetStatsPage(t *testing.T) {
+	s := &StatsPage{
+		Enable:      true,
+		URI:         "/stats",
+		HideVersion: true,
+		Refresh:     misc.Int64P(10000),
+		Realm:       "HAProxy",
+		Users: []*StatsAuthUser{
+			{Username: "admin", Password: "secret"},
+		},
+		AdminRules: []*StatsAdminRule{
+			{Index: misc.Int64P(0), Cond: "if", CondTest: "LOCALHOST"},
+		},
+	}
+	if err := client.SetStatsPage("listen", "test", s, "", version); err == nil {
+		t.Error("Should throw error, unsupported parent type")
+		version++
+	}
+	s.URI = "stats"
+	if err := client.SetStatsPage("frontend", "test", s, "", version); err == nil {
+		t.Error("Should throw error, invalid stats uri")
+		version++
+	}
+	s.URI = "/stats"
+	if err := client.SetStatsPage("frontend", "test", s, "", version); err != nil {
+		t.Fatal(err.Error())
+	}
+	version++
+
+	v, page, err := client.GetStatsPage("frontend", "test", "")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if !reflect.DeepEqual(page, s) {
+		t.Errorf("Stats page %v returned, expected %v", page, s)
+	}
+	if v != version {
+		t.Errorf("Version %v returned, expected %v", v, version)
+	}
+
+	// pushing the frontend keeps the settings not covered by its stats options
+	_, f, err := client.GetFrontend("test", "")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if err := client.EditFrontend("test", f, "", version); err != nil {
+		t.Fatal(err.Error())
+	}
+	version++
+	if _, page, _ = client.GetStatsPage("frontend", "test", ""); !reflect.DeepEqual(page, s) {
+		t.Errorf("EditFrontend changed stats page to %v", page)
+	}
+
+	if err := client.SetStatsPage("frontend", "test", &StatsPage{}, "", version); err != nil {
+		t.Error(err.Error())
+	} else {
+		version++
+	}
+	if _, page, _ = client.GetStatsPage("frontend", "test", ""); !reflect.DeepEqual(page, &StatsPage{}) {
+		t.Errorf("Stats page %v returned, expected empty", page)
+	}
+}
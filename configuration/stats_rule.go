@@ -0,0 +1,334 @@
+// Copyright 2019 HAProxy Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package configuration
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	stats "github.com/haproxytech/config-parser/v3/parsers/stats/settings"
+	"github.com/haproxytech/config-parser/v3/types"
+)
+
+// StatsHTTPRequestRule is a stats http-request rule of a backend, allowing, denying or
+// requesting authentication for the stats page when the condition matches
+type StatsHTTPRequestRule struct {
+	// Index of the rule among the stats http-request rules of the backend
+	Index *int64 `json:"index,omitempty"`
+	// Type is one of allow, deny or auth
+	Type string `json:"type"`
+	// AuthRealm is the realm of auth rules
+	AuthRealm string `json:"auth_realm,omitempty"`
+	Cond      string `json:"cond,omitempty"`
+	CondTest  string `json:"cond_test,omitempty"`
+}
+
+// Validate validates the stats http-request rule
+func (r *StatsHTTPRequestRule) Validate() error {
+	switch r.Type {
+	case "allow", "deny":
+		if r.AuthRealm != "" {
+			return fmt.Errorf("stats http-request %s: realm is only supported by auth rules", r.Type)
+		}
+	case "auth":
+		if strings.ContainsAny(r.AuthRealm, " \t#") {
+			return fmt.Errorf("stats http-request auth: realm can not contain spaces or '#'")
+		}
+	default:
+		return fmt.Errorf("stats http-request type must be one of allow, deny or auth")
+	}
+	if r.Cond == "" && r.CondTest == "" {
+		return nil
+	}
+	if r.Cond != "if" && r.Cond != "unless" {
+		return fmt.Errorf("stats http-request %s: condition must be if or unless", r.Type)
+	}
+	if strings.TrimSpace(r.CondTest) == "" || strings.ContainsAny(r.CondTest, "#\n") {
+		return fmt.Errorf("stats http-request %s: invalid condition test %s", r.Type, r.CondTest)
+	}
+	return nil
+}
+
+// GetStatsAdminRules returns configuration version and an array of
+// the stats admin rules of the frontend or backend. Returns error on fail.
+func (c *Client) GetStatsAdminRules(parentType string, parentName string, transactionID string) (int64, []*StatsAdminRule, error) {
+	v, settings, err := c.getStatsRules(parentType, parentName, isStatsAdminRule, transactionID)
+	if err != nil {
+		return 0, nil, err
+	}
+	rules := []*StatsAdminRule{}
+	for i, s := range settings {
+		index := int64(i)
+		admin := s.(*stats.Admin)
+		rules = append(rules, &StatsAdminRule{Index: &index, Cond: admin.Cond, CondTest: admin.CondTest})
+	}
+	return v, rules, nil
+}
+
+// GetStatsAdminRule returns configuration version and the stats admin rule
+// at index id. Returns error on fail or if the rule does not exist.
+func (c *Client) GetStatsAdminRule(id int64, parentType string, parentName string, transactionID string) (int64, *StatsAdminRule, error) {
+	v, rules, err := c.GetStatsAdminRules(parentType, parentName, transactionID)
+	if err != nil {
+		return 0, nil, err
+	}
+	if id < 0 || id >= int64(len(rules)) {
+		return v, nil, NewConfError(ErrObjectDoesNotExist, fmt.Sprintf("Stats admin rule %d does not exist in %s %s", id, parentType, parentName))
+	}
+	return v, rules[id], nil
+}
+
+// CreateStatsAdminRule inserts a stats admin rule at its index. One of version or
+// transactionID is mandatory. Returns error on fail, nil on success.
+func (c *Client) CreateStatsAdminRule(parentType string, parentName string, data *StatsAdminRule, transactionID string, version int64) error {
+	if err := validateStatsRuleIndex(data.Index); err != nil {
+		return err
+	}
+	if err := data.Validate(); err != nil {
+		return NewConfError(ErrValidationError, err.Error())
+	}
+	rule := &stats.Admin{Cond: data.Cond, CondTest: data.CondTest}
+	return c.setStatsRule("CreateStatsAdminRule", *data.Index, parentType, parentName, isStatsAdminRule, rule, true, transactionID, version)
+}
+
+// EditStatsAdminRule replaces the stats admin rule at index id. One of version or
+// transactionID is mandatory. Returns error on fail, nil on success.
+func (c *Client) EditStatsAdminRule(id int64, parentType string, parentName string, data *StatsAdminRule, transactionID string, version int64) error {
+	if err := data.Validate(); err != nil {
+		return NewConfError(ErrValidationError, err.Error())
+	}
+	rule := &stats.Admin{Cond: data.Cond, CondTest: data.CondTest}
+	return c.setStatsRule("EditStatsAdminRule", id, parentType, parentName, isStatsAdminRule, rule, false, transactionID, version)
+}
+
+// DeleteStatsAdminRule deletes the stats admin rule at index id. One of version or
+// transactionID is mandatory. Returns error on fail, nil on success.
+func (c *Client) DeleteStatsAdminRule(id int64, parentType string, parentName string, transactionID string, version int64) error {
+	return c.setStatsRule("DeleteStatsAdminRule", id, parentType, parentName, isStatsAdminRule, nil, false, transactionID, version)
+}
+
+// GetStatsHTTPRequestRules returns configuration version and an array of
+// the stats http-request rules of the backend. Returns error on fail.
+func (c *Client) GetStatsHTTPRequestRules(parentType string, parentName string, transactionID string) (int64, []*StatsHTTPRequestRule, error) {
+	if err := statsHTTPRequestParent(parentType); err != nil {
+		return 0, nil, err
+	}
+	v, settings, err := c.getStatsRules(parentType, parentName, isStatsHTTPRequestRule, transactionID)
+	if err != nil {
+		return 0, nil, err
+	}
+	rules := []*StatsHTTPRequestRule{}
+	for i, s := range settings {
+		index := int64(i)
+		r := s.(*stats.HTTPRequest)
+		rule := &StatsHTTPRequestRule{Index: &index, Type: r.Type, Cond: r.Cond, CondTest: r.CondTest}
+		if strings.HasPrefix(r.Type, "auth realm ") {
+			rule.Type = "auth"
+			rule.AuthRealm = strings.TrimSpace(strings.TrimPrefix(r.Type, "auth realm "))
+		}
+		rules = append(rules, rule)
+	}
+	return v, rules, nil
+}
+
+// GetStatsHTTPRequestRule returns configuration version and the stats http-request rule
+// at index id. Returns error on fail or if the rule does not exist.
+func (c *Client) GetStatsHTTPRequestRule(id int64, parentType string, parentName string, transactionID string) (int64, *StatsHTTPRequestRule, error) {
+	v, rules, err := c.GetStatsHTTPRequestRules(parentType, parentName, transactionID)
+	if err != nil {
+		return 0, nil, err
+	}
+	if id < 0 || id >= int64(len(rules)) {
+		return v, nil, NewConfError(ErrObjectDoesNotExist, fmt.Sprintf("Stats http-request rule %d does not exist in %s %s", id, parentType, parentName))
+	}
+	return v, rules[id], nil
+}
+
+// CreateStatsHTTPRequestRule inserts a stats http-request rule at its index. One of version or
+// transactionID is mandatory. Returns error on fail, nil on success.
+func (c *Client) CreateStatsHTTPRequestRule(parentType string, parentName string, data *StatsHTTPRequestRule, transactionID string, version int64) error {
+	if err := statsHTTPRequestParent(parentType); err != nil {
+		return err
+	}
+	if err := validateStatsRuleIndex(data.Index); err != nil {
+		return err
+	}
+	if err := data.Validate(); err != nil {
+		return NewConfError(ErrValidationError, err.Error())
+	}
+	return c.setStatsRule("CreateStatsHTTPRequestRule", *data.Index, parentType, parentName, isStatsHTTPRequestRule, serializeStatsHTTPRequestRule(data), true, transactionID, version)
+}
+
+// EditStatsHTTPRequestRule replaces the stats http-request rule at index id. One of version or
+// transactionID is mandatory. Returns error on fail, nil on success.
+func (c *Client) EditStatsHTTPRequestRule(id int64, parentType string, parentName string, data *StatsHTTPRequestRule, transactionID string, version int64) error {
+	if err := statsHTTPRequestParent(parentType); err != nil {
+		return err
+	}
+	if err := data.Validate(); err != nil {
+		return NewConfError(ErrValidationError, err.Error())
+	}
+	return c.setStatsRule("EditStatsHTTPRequestRule", id, parentType, parentName, isStatsHTTPRequestRule, serializeStatsHTTPRequestRule(data), false, transactionID, version)
+}
+
+// DeleteStatsHTTPRequestRule deletes the stats http-request rule at index id. One of version or
+// transactionID is mandatory. Returns error on fail, nil on success.
+func (c *Client) DeleteStatsHTTPRequestRule(id int64, parentType string, parentName string, transactionID string, version int64) error {
+	if err := statsHTTPRequestParent(parentType); err != nil {
+		return err
+	}
+	return c.setStatsRule("DeleteStatsHTTPRequestRule", id, parentType, parentName, isStatsHTTPRequestRule, nil, false, transactionID, version)
+}
+
+func (c *Client) getStatsRules(parentType string, parentName string, isKind func(types.StatsSettings) bool, transactionID string) (int64, []types.StatsSettings, error) {
+	section, err := statsPageSection(parentType)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	p, err := c.GetParser(transactionID)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	v, err := c.GetVersion(transactionID)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	if !c.checkSectionExists(section, parentName, p) {
+		return v, nil, NewConfError(ErrParentDoesNotExist, fmt.Sprintf("%s %s does not exist", parentType, parentName))
+	}
+
+	settings, err := getStatsSettings(section, parentName, p)
+	if err != nil {
+		return v, nil, c.handleError("", parentType, parentName, "", false, err)
+	}
+	rules := []types.StatsSettings{}
+	for _, s := range settings {
+		if isKind(s) {
+			rules = append(rules, s)
+		}
+	}
+	return v, rules, nil
+}
+
+// setStatsRule inserts, replaces or, when rule is nil, removes the id-th stats setting of a kind,
+// keeping the other stats settings in place
+func (c *Client) setStatsRule(operation string, id int64, parentType string, parentName string, isKind func(types.StatsSettings) bool, rule types.StatsSettings, insert bool, transactionID string, version int64) error {
+	section, err := statsPageSection(parentType)
+	if err != nil {
+		return err
+	}
+
+	if err := c.authorize(operation, "stats_rule", authorizedIndex(id), parentType, parentName, transactionID); err != nil {
+		return err
+	}
+
+	p, t, err := c.loadDataForChange(transactionID, version)
+	if err != nil {
+		return err
+	}
+
+	index := strconv.FormatInt(id, 10)
+	if !c.checkSectionExists(section, parentName, p) {
+		e := NewConfError(ErrParentDoesNotExist, fmt.Sprintf("%s %s does not exist", parentType, parentName))
+		return c.handleError(index, parentType, parentName, t, transactionID == "", e)
+	}
+
+	settings, err := getStatsSettings(section, parentName, p)
+	if err != nil {
+		return c.handleError(index, parentType, parentName, t, transactionID == "", err)
+	}
+	positions := []int{}
+	for i, s := range settings {
+		if isKind(s) {
+			positions = append(positions, i)
+		}
+	}
+
+	count := int64(len(positions))
+	if id < 0 || id > count || (!insert && id == count) {
+		e := NewConfError(ErrObjectDoesNotExist, fmt.Sprintf("Stats rule %d does not exist in %s %s", id, parentType, parentName))
+		return c.handleError(index, parentType, parentName, t, transactionID == "", e)
+	}
+
+	result := make([]types.StatsSettings, 0, len(settings)+1)
+	switch {
+	case insert:
+		pos := len(settings)
+		if id < count {
+			pos = positions[id]
+		} else if count > 0 {
+			pos = positions[count-1] + 1
+		}
+		result = append(result, settings[:pos]...)
+		result = append(result, rule)
+		result = append(result, settings[pos:]...)
+	case rule == nil:
+		result = append(result, settings[:positions[id]]...)
+		result = append(result, settings[positions[id]+1:]...)
+	default:
+		result = append(result, settings...)
+		result[positions[id]] = rule
+	}
+
+	var value interface{}
+	if len(result) > 0 {
+		value = result
+	}
+	if err := p.Set(section, parentName, "stats", value); err != nil {
+		return c.handleError(index, parentType, parentName, t, transactionID == "", err)
+	}
+
+	if err := c.saveData(p, t, transactionID == ""); err != nil {
+		return err
+	}
+	return nil
+}
+
+func validateStatsRuleIndex(index *int64) error {
+	if index == nil || *index < 0 {
+		return NewConfError(ErrValidationError, "index is required and can not be negative")
+	}
+	return nil
+}
+
+func statsHTTPRequestParent(parentType string) error {
+	if parentType != "backend" {
+		return NewConfError(ErrValidationError, "stats http-request rules are only supported in backends")
+	}
+	return nil
+}
+
+func isStatsAdminRule(s types.StatsSettings) bool {
+	_, ok := s.(*stats.Admin)
+	return ok
+}
+
+func isStatsHTTPRequestRule(s types.StatsSettings) bool {
+	_, ok := s.(*stats.HTTPRequest)
+	return ok
+}
+
+func serializeStatsHTTPRequestRule(r *StatsHTTPRequestRule) *stats.HTTPRequest {
+	ruleType := r.Type
+	if r.AuthRealm != "" {
+		ruleType = fmt.Sprintf("auth realm %s", r.AuthRealm)
+	}
+	return &stats.HTTPRequest{Type: ruleType, Cond: r.Cond, CondTest: r.CondTest}
+}
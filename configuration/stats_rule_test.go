@@ -0,0 +1,125 @@
+// Copyright 2019 HAProxy Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package configuration
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/haproxytech/client-native/v2/misc"
+)
+
+func TestCreateEditDeleteStatsAdminRule(t *testing.T) {
+	rules := []*StatsAdminRule{
+		{Index: misc.Int64P(0), Cond: "if", CondTest: "LOCALHOST"},
+		{Index: misc.Int64P(1), Cond: "unless", CondTest: "{ src 10.0.0.0/8 }"},
+	}
+	for _, r := range []*StatsAdminRule{rules[1], rules[0]} {
+		r := *r
+		r.Index = misc.Int64P(0)
+		if err := client.CreateStatsAdminRule("frontend", "test", &r, "", version); err != nil {
+			t.Fatal(err.Error())
+		}
+		version++
+	}
+	if err := client.CreateStatsAdminRule("frontend", "test", &StatsAdminRule{Index: misc.Int64P(5), Cond: "if", CondTest: "TRUE"}, "", version); err == nil {
+		t.Error("Should throw error, index out of range")
+		version++
+	}
+
+	v, got, err := client.GetStatsAdminRules("frontend", "test", "")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if !reflect.DeepEqual(got, rules) {
+		t.Errorf("Stats admin rules %v returned, expected %v", got, rules)
+	}
+	if v != version {
+		t.Errorf("Version %v returned, expected %v", v, version)
+	}
+
+	rules[1].CondTest = "{ src 192.168.0.0/16 }"
+	if err := client.EditStatsAdminRule(1, "frontend", "test", rules[1], "", version); err != nil {
+		t.Error(err.Error())
+	} else {
+		version++
+	}
+	if _, r, err := client.GetStatsAdminRule(1, "frontend", "test", ""); err != nil {
+		t.Error(err.Error())
+	} else if !reflect.DeepEqual(r, rules[1]) {
+		t.Errorf("Stats admin rule %v returned, expected %v", r, rules[1])
+	}
+
+	for range rules {
+		if err := client.DeleteStatsAdminRule(0, "frontend", "test", "", version); err != nil {
+			t.Error(err.Error())
+		} else {
+			version++
+		}
+	}
+	if err := client.DeleteStatsAdminRule(0, "frontend", "test", "", version); err == nil {
+		t.Error("Should throw error, stats admin rule does not exist")
+		version++
+	}
+}
+
+func TestCreateEditDeleteStatsHTTPRequestRule(t *testing.T) {
+	r := &StatsHTTPRequestRule{Index: misc.Int64P(0), Type: "allow", Cond: "if", CondTest: "LOCALHOST"}
+	if err := client.CreateStatsHTTPRequestRule("frontend", "test", r, "", version); err == nil {
+		t.Error("Should throw error, stats http-request rules are not supported in frontends")
+		version++
+	}
+	if err := client.CreateStatsHTTPRequestRule("backend", "test", r, "", version); err != nil {
+		t.Fatal(err.Error())
+	}
+	version++
+	auth := &StatsHTTPRequestRule{Index: misc.Int64P(1), Type: "auth", AuthRealm: "Stats"}
+	if err := client.CreateStatsHTTPRequestRule("backend", "test", auth, "", version); err != nil {
+		t.Fatal(err.Error())
+	}
+	version++
+
+	_, rules, err := client.GetStatsHTTPRequestRules("backend", "test", "")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if !reflect.DeepEqual(rules, []*StatsHTTPRequestRule{r, auth}) {
+		t.Errorf("Stats http-request rules %v returned, expected %v", rules, []*StatsHTTPRequestRule{r, auth})
+	}
+
+	r.Type = "deny"
+	if err := client.EditStatsHTTPRequestRule(0, "backend", "test", r, "", version); err != nil {
+		t.Error(err.Error())
+	} else {
+		version++
+	}
+	if _, rule, err := client.GetStatsHTTPRequestRule(0, "backend", "test", ""); err != nil {
+		t.Error(err.Error())
+	} else if !reflect.DeepEqual(rule, r) {
+		t.Errorf("Stats http-request rule %v returned, expected %v", rule, r)
+	}
+
+	for i := 0; i < 2; i++ {
+		if err := client.DeleteStatsHTTPRequestRule(0, "backend", "test", "", version); err != nil {
+			t.Error(err.Error())
+		} else {
+			version++
+		}
+	}
+	if _, rules, _ := client.GetStatsHTTPRequestRules("backend", "test", ""); len(rules) != 0 {
+		t.Errorf("DeleteStatsHTTPRequestRule failed, %v still exist", rules)
+	}
+}
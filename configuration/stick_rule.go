@@ -73,6 +73,10 @@ func (c *Client) GetStickRule(id int64, backend string, transactionID string) (i
 // DeleteStickRule deletes a stick rule in configuration. One of version or transactionID is
 // mandatory. Returns error on fail, nil on success.
 func (c *Client) DeleteStickRule(id int64, backend string, transactionID string, version int64) error {
+	if err := c.authorize("DeleteStickRule", "stick_rule", authorizedIndex(id), "backend", backend, transactionID); err != nil {
+		return err
+	}
+
 	p, t, err := c.loadDataForChange(transactionID, version)
 	if err != nil {
 		return err
@@ -97,6 +101,10 @@ func (c *Client) CreateStickRule(backend string, data *models.StickRule, transac
 			return NewConfError(ErrValidationError, validationErr.Error())
 		}
 	}
+	if err := c.authorize("CreateStickRule", "stick_rule", authorizedIndexP(data.Index), "backend", backend, transactionID); err != nil {
+		return err
+	}
+
 	p, t, err := c.loadDataForChange(transactionID, version)
 	if err != nil {
 		return err
@@ -121,6 +129,10 @@ func (c *Client) EditStickRule(id int64, backend string, data *models.StickRule,
 			return NewConfError(ErrValidationError, validationErr.Error())
 		}
 	}
+	if err := c.authorize("EditStickRule", "stick_rule", authorizedIndex(id), "backend", backend, transactionID); err != nil {
+		return err
+	}
+
 	p, t, err := c.loadDataForChange(transactionID, version)
 	if err != nil {
 		return err
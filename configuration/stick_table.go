@@ -0,0 +1,452 @@
+// Copyright 2019 HAProxy Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package configuration
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	parser "github.com/haproxytech/config-parser/v3"
+	parser_errors "github.com/haproxytech/config-parser/v3/errors"
+	"github.com/haproxytech/config-parser/v3/types"
+
+	"github.com/haproxytech/client-native/v2/misc"
+)
+
+var stickTableTypes = []string{"ip", "ipv6", "integer", "string", "binary"}
+
+var stickTableStoreRegexp = regexp.MustCompile(`^(server_id|gpc[01]|gpt0|conn_cnt|conn_cur|sess_cnt|http_req_cnt|http_err_cnt|bytes_in_cnt|bytes_out_cnt|(gpc[01]_rate|conn_rate|sess_rate|http_req_rate|http_err_rate|bytes_in_rate|bytes_out_rate)\(\d+[smhd]?\))$`)
+
+// StickTableDefinition is the declaration of a stick-table. Frontends and backends declare one
+// stick-table named after the section, peers sections declare named tables shared with their
+// peers.
+type StickTableDefinition struct {
+	// Name of a table declared in a peers section, empty for frontends and backends
+	Name string `json:"name,omitempty"`
+	Type string `json:"type"`
+	// Keylen is the key length of string and binary tables
+	Keylen *int64 `json:"keylen,omitempty"`
+	// Size is the maximum number of entries
+	Size *int64 `json:"size"`
+	// Expire is the inactivity time after which entries are removed in milliseconds
+	Expire  *int64 `json:"expire,omitempty"`
+	Nopurge bool   `json:"nopurge,omitempty"`
+	// Store is the comma separated list of data types stored per entry, such as
+	// conn_cur,http_req_rate(10s)
+	Store string `json:"store,omitempty"`
+	// Peers is the peers section the table of a frontend or backend is synchronized with
+	Peers string `json:"peers,omitempty"`
+}
+
+// Validate checks the table type, size and stored data types
+func (st *StickTableDefinition) Validate() error {
+	if !misc.StringInSlice(st.Type, stickTableTypes) {
+		return fmt.Errorf("stick-table type must be one of %s", strings.Join(stickTableTypes, ", "))
+	}
+	if st.Size == nil || *st.Size <= 0 {
+		return fmt.Errorf("stick-table size has to be greater than 0")
+	}
+	if st.Keylen != nil && st.Type != "string" && st.Type != "binary" {
+		return fmt.Errorf("stick-table len is only supported by string and binary tables")
+	}
+	if st.Keylen != nil && *st.Keylen <= 0 {
+		return fmt.Errorf("stick-table len has to be greater than 0")
+	}
+	if st.Expire != nil && *st.Expire <= 0 {
+		return fmt.Errorf("stick-table expire has to be greater than 0")
+	}
+	if st.Store != "" {
+		for _, s := range strings.Split(st.Store, ",") {
+			if !stickTableStoreRegexp.MatchString(s) {
+				return fmt.Errorf("stick-table store: invalid data type %s", s)
+			}
+		}
+	}
+	if strings.ContainsAny(st.Peers, " \t#") {
+		return fmt.Errorf("stick-table peers: invalid peers section %s", st.Peers)
+	}
+	return nil
+}
+
+// GetStickTableDefinition returns configuration version and the stick-table declared
+// in the frontend or backend. Returns error on fail or if no stick-table is declared.
+func (c *Client) GetStickTableDefinition(parentType string, parentName string, transactionID string) (int64, *StickTableDefinition, error) {
+	section, err := stickTableSection(parentType)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	p, err := c.GetParser(transactionID)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	v, err := c.GetVersion(transactionID)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	if !c.checkSectionExists(section, parentName, p) {
+		return v, nil, NewConfError(ErrParentDoesNotExist, fmt.Sprintf("%s %s does not exist", parentType, parentName))
+	}
+
+	data, err := p.Get(section, parentName, "stick-table", false)
+	if err != nil {
+		if err == parser_errors.ErrFetch {
+			return v, nil, NewConfError(ErrObjectDoesNotExist, fmt.Sprintf("%s %s has no stick-table", parentType, parentName))
+		}
+		return v, nil, c.handleError("stick-table", parentType, parentName, "", false, err)
+	}
+
+	return v, ParseStickTableDefinition(*data.(*types.StickTable)), nil
+}
+
+// SetStickTableDefinition declares the stick-table of the frontend or backend, replacing the
+// current one. The peers section of the table has to exist. One of version or transactionID is
+// mandatory. Returns error on fail, nil on success.
+func (c *Client) SetStickTableDefinition(parentType string, parentName string, data *StickTableDefinition, transactionID string, version int64) error {
+	section, err := stickTableSection(parentType)
+	if err != nil {
+		return err
+	}
+	if err := data.Validate(); err != nil {
+		return NewConfError(ErrValidationError, err.Error())
+	}
+	if data.Name != "" {
+		return NewConfError(ErrValidationError, fmt.Sprintf("the stick-table of %s %s is named after the section", parentType, parentName))
+	}
+
+	if err := c.authorize("SetStickTableDefinition", "stick_table", "", parentType, parentName, transactionID); err != nil {
+		return err
+	}
+
+	p, t, err := c.loadDataForChange(transactionID, version)
+	if err != nil {
+		return err
+	}
+
+	if !c.checkSectionExists(section, parentName, p) {
+		e := NewConfError(ErrParentDoesNotExist, fmt.Sprintf("%s %s does not exist", parentType, parentName))
+		return c.handleError("stick-table", parentType, parentName, t, transactionID == "", e)
+	}
+	if data.Peers != "" && !c.checkSectionExists(parser.Peers, data.Peers, p) {
+		e := NewConfError(ErrValidationError, fmt.Sprintf("peers section %s does not exist", data.Peers))
+		return c.handleError("stick-table", parentType, parentName, t, transactionID == "", e)
+	}
+
+	if err := p.Set(section, parentName, "stick-table", SerializeStickTableDefinition(*data)); err != nil {
+		return c.handleError("stick-table", parentType, parentName, t, transactionID == "", err)
+	}
+
+	if err := c.saveData(p, t, transactionID == ""); err != nil {
+		return err
+	}
+	return nil
+}
+
+// DeleteStickTableDefinition removes the stick-table of the frontend or backend. One of version
+// or transactionID is mandatory. Returns error on fail, nil on success.
+func (c *Client) DeleteStickTableDefinition(parentType string, parentName string, transactionID string, version int64) error {
+	section, err := stickTableSection(parentType)
+	if err != nil {
+		return err
+	}
+
+	if err := c.authorize("DeleteStickTableDefinition", "stick_table", "", parentType, parentName, transactionID); err != nil {
+		return err
+	}
+
+	p, t, err := c.loadDataForChange(transactionID, version)
+	if err != nil {
+		return err
+	}
+
+	if _, err := p.Get(section, parentName, "stick-table", false); err != nil {
+		e := NewConfError(ErrObjectDoesNotExist, fmt.Sprintf("%s %s has no stick-table", parentType, parentName))
+		if err == parser_errors.ErrSectionMissing {
+			e = NewConfError(ErrParentDoesNotExist, fmt.Sprintf("%s %s does not exist", parentType, parentName))
+		}
+		return c.handleError("stick-table", parentType, parentName, t, transactionID == "", e)
+	}
+
+	if err := p.Set(section, parentName, "stick-table", nil); err != nil {
+		return c.handleError("stick-table", parentType, parentName, t, transactionID == "", err)
+	}
+
+	if err := c.saveData(p, t, transactionID == ""); err != nil {
+		return err
+	}
+	return nil
+}
+
+// GetPeerTables returns configuration version and an array of
+// tables declared in the specified peers section. Returns error on fail.
+func (c *Client) GetPeerTables(peerSection string, transactionID string) (int64, []*StickTableDefinition, error) {
+	p, err := c.GetParser(transactionID)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	v, err := c.GetVersion(transactionID)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	if !c.checkSectionExists(parser.Peers, peerSection, p) {
+		return v, nil, NewConfError(ErrParentDoesNotExist, fmt.Sprintf("Peer section %s does not exist", peerSection))
+	}
+
+	tables, err := ParsePeerTables(peerSection, p)
+	if err != nil {
+		return v, nil, c.handleError("", "peers", peerSection, "", false, err)
+	}
+
+	return v, tables, nil
+}
+
+// GetPeerTable returns configuration version and a requested table
+// in the specified peers section. Returns error on fail or if table does not exist.
+func (c *Client) GetPeerTable(name string, peerSection string, transactionID string) (int64, *StickTableDefinition, error) {
+	p, err := c.GetParser(transactionID)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	v, err := c.GetVersion(transactionID)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	table, _ := GetPeerTableByName(name, peerSection, p)
+	if table == nil {
+		return v, nil, NewConfError(ErrObjectDoesNotExist, fmt.Sprintf("Table %s does not exist in peer section %s", name, peerSection))
+	}
+
+	return v, table, nil
+}
+
+// DeletePeerTable deletes a table in the peers section. One of version or transactionID is
+// mandatory. Returns error on fail, nil on success.
+func (c *Client) DeletePeerTable(name string, peerSection string, transactionID string, version int64) error {
+	return c.setPeerTable("DeletePeerTable", name, peerSection, nil, false, transactionID, version)
+}
+
+// CreatePeerTable declares a table in the peers section. One of version or transactionID is
+// mandatory. Returns error on fail, nil on success.
+func (c *Client) CreatePeerTable(peerSection string, data *StickTableDefinition, transactionID string, version int64) error {
+	if err := validatePeerTable(data); err != nil {
+		return NewConfError(ErrValidationError, err.Error())
+	}
+	return c.setPeerTable("CreatePeerTable", data.Name, peerSection, data, true, transactionID, version)
+}
+
+// EditPeerTable edits a table in the peers section. One of version or transactionID is
+// mandatory. Returns error on fail, nil on success.
+func (c *Client) EditPeerTable(name string, peerSection string, data *StickTableDefinition, transactionID string, version int64) error {
+	if err := validatePeerTable(data); err != nil {
+		return NewConfError(ErrValidationError, err.Error())
+	}
+	if data.Name != name {
+		return NewConfError(ErrValidationError, fmt.Sprintf("table %s can not be renamed to %s", name, data.Name))
+	}
+	return c.setPeerTable("EditPeerTable", name, peerSection, data, false, transactionID, version)
+}
+
+func (c *Client) setPeerTable(operation string, name string, peerSection string, data *StickTableDefinition, create bool, transactionID string, version int64) error {
+	if err := c.authorize(operation, "table", name, "peers", peerSection, transactionID); err != nil {
+		return err
+	}
+
+	p, t, err := c.loadDataForChange(transactionID, version)
+	if err != nil {
+		return err
+	}
+
+	if !c.checkSectionExists(parser.Peers, peerSection, p) {
+		e := NewConfError(ErrParentDoesNotExist, fmt.Sprintf("Peer section %s does not exist", peerSection))
+		return c.handleError(name, "peers", peerSection, t, transactionID == "", e)
+	}
+
+	table, _ := GetPeerTableByName(name, peerSection, p)
+	if create && table != nil {
+		e := NewConfError(ErrObjectAlreadyExists, fmt.Sprintf("Table %s already exists in peer section %s", name, peerSection))
+		return c.handleError(name, "peers", peerSection, t, transactionID == "", e)
+	}
+	if !create && table == nil {
+		e := NewConfError(ErrObjectDoesNotExist, fmt.Sprintf("Table %s does not exist in peer section %s", name, peerSection))
+		return c.handleError(name, "peers", peerSection, t, transactionID == "", e)
+	}
+
+	var value *string
+	if data != nil {
+		v := serializePeerTable(*data)
+		value = &v
+	}
+	if err := setRawDirective(p, parser.Peers, peerSection, "table "+name, value); err != nil {
+		return c.handleError(name, "peers", peerSection, t, transactionID == "", err)
+	}
+
+	if err := c.saveData(p, t, transactionID == ""); err != nil {
+		return err
+	}
+	return nil
+}
+
+func ParseStickTableDefinition(d types.StickTable) *StickTableDefinition {
+	st := &StickTableDefinition{
+		Type:    d.Type,
+		Size:    misc.ParseSize(d.Size),
+		Expire:  misc.ParseTimeout(d.Expire),
+		Nopurge: d.NoPurge,
+		Store:   d.Store,
+		Peers:   d.Peers,
+	}
+	if k, err := strconv.ParseInt(d.Length, 10, 64); err == nil {
+		st.Keylen = &k
+	}
+	return st
+}
+
+func SerializeStickTableDefinition(st StickTableDefinition) types.StickTable {
+	d := types.StickTable{
+		Type:    st.Type,
+		Store:   st.Store,
+		Peers:   st.Peers,
+		NoPurge: st.Nopurge,
+	}
+	if st.Keylen != nil {
+		d.Length = strconv.FormatInt(*st.Keylen, 10)
+	}
+	if st.Size != nil {
+		d.Size = strconv.FormatInt(*st.Size, 10)
+	}
+	if st.Expire != nil {
+		d.Expire = strconv.FormatInt(*st.Expire, 10)
+	}
+	return d
+}
+
+// ParsePeerTables returns the tables of the peers section, table lines have no parser and are
+// kept as unprocessed lines
+func ParsePeerTables(peerSection string, p *parser.Parser) ([]*StickTableDefinition, error) {
+	lines, err := getRawLines(p, parser.Peers, peerSection)
+	if err != nil {
+		return nil, err
+	}
+	tables := []*StickTableDefinition{}
+	for _, l := range lines {
+		value, ok := matchRawDirective(l.Value, "table")
+		if !ok {
+			continue
+		}
+		if table := parsePeerTable(strings.Fields(value)); table != nil {
+			tables = append(tables, table)
+		}
+	}
+	return tables, nil
+}
+
+func GetPeerTableByName(name string, peerSection string, p *parser.Parser) (*StickTableDefinition, int) {
+	tables, err := ParsePeerTables(peerSection, p)
+	if err != nil {
+		return nil, 0
+	}
+
+	for i, t := range tables {
+		if t.Name == name {
+			return t, i
+		}
+	}
+	return nil, 0
+}
+
+func parsePeerTable(words []string) *StickTableDefinition {
+	if len(words) == 0 {
+		return nil
+	}
+	d := types.StickTable{}
+	for i := 1; i < len(words); i++ {
+		if words[i] == "nopurge" {
+			d.NoPurge = true
+			continue
+		}
+		if i+1 >= len(words) {
+			break
+		}
+		switch words[i] {
+		case "type":
+			d.Type = words[i+1]
+		case "len":
+			d.Length = words[i+1]
+		case "size":
+			d.Size = words[i+1]
+		case "expire":
+			d.Expire = words[i+1]
+		case "store":
+			d.Store = words[i+1]
+		default:
+			continue
+		}
+		i++
+	}
+	st := ParseStickTableDefinition(d)
+	st.Name = words[0]
+	return st
+}
+
+// serializePeerTable returns the table line of the peers section without the table keyword and
+// name, in the order of the stick-table directive
+func serializePeerTable(st StickTableDefinition) string {
+	d := SerializeStickTableDefinition(st)
+	parts := []string{"type", d.Type}
+	if d.Length != "" {
+		parts = append(parts, "len", d.Length)
+	}
+	parts = append(parts, "size", d.Size)
+	if d.Expire != "" {
+		parts = append(parts, "expire", d.Expire)
+	}
+	if d.NoPurge {
+		parts = append(parts, "nopurge")
+	}
+	if d.Store != "" {
+		parts = append(parts, "store", d.Store)
+	}
+	return strings.Join(parts, " ")
+}
+
+func validatePeerTable(data *StickTableDefinition) error {
+	if data.Name == "" || strings.ContainsAny(data.Name, " \t#") {
+		return fmt.Errorf("invalid table name %s", data.Name)
+	}
+	if data.Peers != "" {
+		return fmt.Errorf("table %s: tables of a peers section are synchronized with the section", data.Name)
+	}
+	return data.Validate()
+}
+
+func stickTableSection(parentType string) (parser.Section, error) {
+	switch parentType {
+	case "frontend":
+		return parser.Frontends, nil
+	case "backend":
+		return parser.Backends, nil
+	default:
+		return "", NewConfError(ErrValidationError, fmt.Sprintf("stick-table is not supported in %s, use the tables of peers sections", parentType))
+	}
+}
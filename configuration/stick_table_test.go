@@ -0,0 +1,142 @@
+// Copyright 2019 HAProxy Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package configuration
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/haproxytech/client-native/v2/misc"
+)
+
+func TestGetStickTableDefinition(t *testing.T) {
+	_, st, err := client.GetStickTableDefinition("backend", "test_2", "")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	expected := &StickTableDefinition{
+		Type:   "ip",
+		Size:   misc.Int64P(102400),
+		Expire: misc.Int64P(3600000),
+		Store:  "http_req_rate(10s)",
+		Peers:  "mycluster",
+	}
+	if !reflect.DeepEqual(st, expected) {
+		t.Errorf("Stick-table %v returned, expected %v", st, expected)
+	}
+
+	if _, _, err := client.GetStickTableDefinition("frontend", "test_2", ""); err == nil {
+		t.Error("Should throw error, frontend test_2 has no stick-table")
+	}
+}
+
+func TestSetDeleteStickTableDefinition(t *testing.T) {
+	st := &StickTableDefinition{
+		Type:  "ipv6",
+		Size:  misc.Int64P(1000),
+		Store: "conn_cur,http_req_cnt,requests",
+	}
+	if err := client.SetStickTableDefinition("frontend", "test_2", st, "", version); err == nil {
+		t.Error("Should throw error, requests is not a stick-table data type")
+		version++
+	}
+	st.Store = "conn_cur,http_req_rate(10s)"
+	st.Peers = "unknown"
+	if err := client.SetStickTableDefinition("frontend", "test_2", st, "", version); err == nil {
+		t.Error("Should throw error, peers section unknown does not exist")
+		version++
+	}
+	st.Peers = "mycluster"
+	if err := client.SetStickTableDefinition("frontend", "test_2", st, "", version); err != nil {
+		t.Fatal(err.Error())
+	}
+	version++
+
+	v, table, err := client.GetStickTableDefinition("frontend", "test_2", "")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if !reflect.DeepEqual(table, st) {
+		t.Errorf("Stick-table %v returned, expected %v", table, st)
+	}
+	if v != version {
+		t.Errorf("Version %v returned, expected %v", v, version)
+	}
+
+	if err := client.DeleteStickTableDefinition("frontend", "test_2", "", version); err != nil {
+		t.Error(err.Error())
+	} else {
+		version++
+	}
+	if err := client.DeleteStickTableDefinition("frontend", "test_2", "", version); err == nil {
+		t.Error("Should throw error, frontend test_2 has no stick-table")
+		version++
+	}
+}
+
+func TestCreateEditDeletePeerTable(t *testing.T) {
+	table := &StickTableDefinition{
+		Name:   "sessions",
+		Type:   "string",
+		Keylen: misc.Int64P(32),
+		Size:   misc.Int64P(10000),
+		Expire: misc.Int64P(600000),
+		Store:  "gpc0,conn_rate(30s)",
+	}
+	if err := client.CreatePeerTable("mycluster", table, "", version); err != nil {
+		t.Fatal(err.Error())
+	}
+	version++
+	if err := client.CreatePeerTable("mycluster", table, "", version); err == nil {
+		t.Error("Should throw error, table sessions already exists")
+		version++
+	}
+
+	v, tables, err := client.GetPeerTables("mycluster", "")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if len(tables) != 1 || !reflect.DeepEqual(tables[0], table) {
+		t.Errorf("Unexpected tables in peers section mycluster: %v", tables)
+	}
+	if v != version {
+		t.Errorf("Version %v returned, expected %v", v, version)
+	}
+
+	table.Keylen = nil
+	table.Type = "integer"
+	table.Nopurge = true
+	if err := client.EditPeerTable("sessions", "mycluster", table, "", version); err != nil {
+		t.Error(err.Error())
+	} else {
+		version++
+	}
+	_, edited, err := client.GetPeerTable("sessions", "mycluster", "")
+	if err != nil {
+		t.Error(err.Error())
+	} else if !reflect.DeepEqual(edited, table) {
+		t.Errorf("Table %v returned, expected %v", edited, table)
+	}
+
+	if err := client.DeletePeerTable("sessions", "mycluster", "", version); err != nil {
+		t.Error(err.Error())
+	} else {
+		version++
+	}
+	if _, _, err := client.GetPeerTable("sessions", "mycluster", ""); err == nil {
+		t.Error("DeletePeerTable failed, table sessions still exists")
+	}
+}
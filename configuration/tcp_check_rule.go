@@ -0,0 +1,402 @@
+// Copyright 2019 HAProxy Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package configuration
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	parser "github.com/haproxytech/config-parser/v3"
+	"github.com/haproxytech/config-parser/v3/parsers/http/actions"
+
+	"github.com/haproxytech/client-native/v2/misc"
+)
+
+var tcpCheckRuleTypes = []string{"comment", "connect", "expect", "send", "send-binary", "send-lf", "send-binary-lf", "set-var", "unset-var"}
+
+var tcpCheckExpectMatches = []string{"string", "rstring", "binary", "rbinary", "string-lf", "binary-lf"}
+
+// TCPCheckRule is a tcp-check rule of a backend or defaults section. The rules of a section form
+// the ordered sequence of the TCP health check enabled with option tcp-check.
+type TCPCheckRule struct {
+	Index *int64 `json:"index"`
+	// Type is one of comment, connect, expect, send, send-binary, send-lf, send-binary-lf,
+	// set-var or unset-var
+	Type string `json:"type"`
+	// CheckComment is the message of comment rules and the comment logged when connect, send or
+	// expect rules fail
+	CheckComment string `json:"check_comment,omitempty"`
+
+	// connect
+	Default   bool   `json:"default,omitempty"`
+	Port      *int64 `json:"port,omitempty"`
+	Addr      string `json:"addr,omitempty"`
+	SendProxy bool   `json:"send_proxy,omitempty"`
+	ViaSocks4 bool   `json:"via_socks4,omitempty"`
+	SSL       bool   `json:"ssl,omitempty"`
+	SNI       string `json:"sni,omitempty"`
+	ALPN      string `json:"alpn,omitempty"`
+	Linger    bool   `json:"linger,omitempty"`
+	Proto     string `json:"proto,omitempty"`
+
+	// Data sent by send rules, a string, a hex string or a log-format string depending on the type
+	Data string `json:"data,omitempty"`
+
+	// expect
+	MinRecv         *int64 `json:"min_recv,omitempty"`
+	OkStatus        string `json:"ok_status,omitempty"`
+	ErrorStatus     string `json:"error_status,omitempty"`
+	TimeoutStatus   string `json:"timeout_status,omitempty"`
+	OnSuccess       string `json:"on_success,omitempty"`
+	OnError         string `json:"on_error,omitempty"`
+	ExclamationMark bool   `json:"exclamation_mark,omitempty"`
+	Match           string `json:"match,omitempty"`
+	Pattern         string `json:"pattern,omitempty"`
+
+	// set-var and unset-var
+	VarScope string `json:"var_scope,omitempty"`
+	VarName  string `json:"var_name,omitempty"`
+	VarExpr  string `json:"var_expr,omitempty"`
+}
+
+// Validate checks the rule type and the arguments of the type. Except for the set-var
+// expression, arguments are single words.
+func (r *TCPCheckRule) Validate() error {
+	if r.Index == nil {
+		return fmt.Errorf("tcp-check rule index is required")
+	}
+	if !misc.StringInSlice(r.Type, tcpCheckRuleTypes) {
+		return fmt.Errorf("tcp-check rule type must be one of %s", strings.Join(tcpCheckRuleTypes, ", "))
+	}
+	words := map[string]string{
+		"comment": r.CheckComment, "addr": r.Addr, "sni": r.SNI, "alpn": r.ALPN, "proto": r.Proto,
+		"data": r.Data, "ok-status": r.OkStatus, "error-status": r.ErrorStatus,
+		"tout-status": r.TimeoutStatus, "on-success": r.OnSuccess, "on-error": r.OnError,
+		"pattern": r.Pattern,
+	}
+	for keyword, value := range words {
+		if strings.ContainsAny(value, " \t#") {
+			return fmt.Errorf("tcp-check %s: %s can not contain whitespace or '#'", r.Type, keyword)
+		}
+	}
+	if r.Port != nil && (*r.Port < 1 || *r.Port > 65535) {
+		return fmt.Errorf("tcp-check connect: port must be between 1 and 65535")
+	}
+	switch r.Type {
+	case "comment":
+		if r.CheckComment == "" {
+			return fmt.Errorf("tcp-check comment: message is required")
+		}
+	case "send", "send-binary", "send-lf", "send-binary-lf":
+		if r.Data == "" {
+			return fmt.Errorf("tcp-check %s: data is required", r.Type)
+		}
+	case "expect":
+		if !misc.StringInSlice(r.Match, tcpCheckExpectMatches) {
+			return fmt.Errorf("tcp-check expect: match must be one of %s", strings.Join(tcpCheckExpectMatches, ", "))
+		}
+		if r.Pattern == "" {
+			return fmt.Errorf("tcp-check expect: pattern is required")
+		}
+		if r.MinRecv != nil && *r.MinRecv < -1 {
+			return fmt.Errorf("tcp-check expect: min-recv can not be lower than -1")
+		}
+	case "set-var", "unset-var":
+		if !misc.StringInSlice(r.VarScope, varScopes) {
+			return fmt.Errorf("tcp-check %s: scope must be one of %s", r.Type, strings.Join(varScopes, ", "))
+		}
+		if !varNameRegexp.MatchString(r.VarName) {
+			return fmt.Errorf("tcp-check %s: invalid variable name %s", r.Type, r.VarName)
+		}
+		if r.Type == "set-var" && (strings.TrimSpace(r.VarExpr) == "" || strings.ContainsAny(r.VarExpr, "#\n")) {
+			return fmt.Errorf("tcp-check set-var: invalid expression %s", r.VarExpr)
+		}
+	}
+	return nil
+}
+
+// GetTCPCheckRules returns configuration version and an array of
+// configured tcp-check rules in the specified parent. Returns error on fail.
+func (c *Client) GetTCPCheckRules(parentType string, parentName string, transactionID string) (int64, []*TCPCheckRule, error) {
+	section, name, err := tcpCheckSection(parentType, parentName)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	p, err := c.GetParser(transactionID)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	v, err := c.GetVersion(transactionID)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	if !c.checkSectionExists(section, name, p) {
+		return v, nil, NewConfError(ErrParentDoesNotExist, fmt.Sprintf("%s %s does not exist", parentType, parentName))
+	}
+
+	rules, err := ParseTCPCheckRules(section, name, p)
+	if err != nil {
+		return v, nil, c.handleError("", parentType, parentName, "", false, err)
+	}
+
+	return v, rules, nil
+}
+
+// GetTCPCheckRule returns configuration version and a requested tcp-check rule
+// in the specified parent. Returns error on fail or if tcp-check rule does not exist.
+func (c *Client) GetTCPCheckRule(id int64, parentType string, parentName string, transactionID string) (int64, *TCPCheckRule, error) {
+	section, name, err := tcpCheckSection(parentType, parentName)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	p, err := c.GetParser(transactionID)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	v, err := c.GetVersion(transactionID)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	rules, err := ParseTCPCheckRules(section, name, p)
+	if err != nil {
+		return v, nil, c.handleError(strconv.FormatInt(id, 10), parentType, parentName, "", false, err)
+	}
+	if id < 0 || id >= int64(len(rules)) {
+		return v, nil, NewConfError(ErrObjectDoesNotExist, fmt.Sprintf("tcp-check rule %v does not exist in %s %s", id, parentType, parentName))
+	}
+
+	return v, rules[id], nil
+}
+
+// DeleteTCPCheckRule deletes a tcp-check rule in configuration. One of version or transactionID
+// is mandatory. Returns error on fail, nil on success.
+func (c *Client) DeleteTCPCheckRule(id int64, parentType string, parentName string, transactionID string, version int64) error {
+	return c.changeTCPCheckRule("DeleteTCPCheckRule", id, parentType, parentName, nil, false, transactionID, version)
+}
+
+// CreateTCPCheckRule creates a tcp-check rule in configuration at the index of the rule. One of
+// version or transactionID is mandatory. Returns error on fail, nil on success.
+func (c *Client) CreateTCPCheckRule(parentType string, parentName string, data *TCPCheckRule, transactionID string, version int64) error {
+	if err := data.Validate(); err != nil {
+		return NewConfError(ErrValidationError, err.Error())
+	}
+	return c.changeTCPCheckRule("CreateTCPCheckRule", *data.Index, parentType, parentName, data, true, transactionID, version)
+}
+
+// EditTCPCheckRule edits a tcp-check rule in configuration. One of version or transactionID is
+// mandatory. Returns error on fail, nil on success.
+func (c *Client) EditTCPCheckRule(id int64, parentType string, parentName string, data *TCPCheckRule, transactionID string, version int64) error {
+	if err := data.Validate(); err != nil {
+		return NewConfError(ErrValidationError, err.Error())
+	}
+	return c.changeTCPCheckRule("EditTCPCheckRule", id, parentType, parentName, data, false, transactionID, version)
+}
+
+// changeTCPCheckRule inserts the rule before the tcp-check line at the index, replaces the line
+// at the index or deletes it when data is nil. The other lines of the section keep their place.
+func (c *Client) changeTCPCheckRule(operation string, id int64, parentType string, parentName string, data *TCPCheckRule, insert bool, transactionID string, version int64) error {
+	section, name, err := tcpCheckSection(parentType, parentName)
+	if err != nil {
+		return err
+	}
+
+	if err := c.authorize(operation, "tcp_check_rule", authorizedIndex(id), parentType, parentName, transactionID); err != nil {
+		return err
+	}
+
+	p, t, err := c.loadDataForChange(transactionID, version)
+	if err != nil {
+		return err
+	}
+
+	if !c.checkSectionExists(section, name, p) {
+		e := NewConfError(ErrParentDoesNotExist, fmt.Sprintf("%s %s does not exist", parentType, parentName))
+		return c.handleError(strconv.FormatInt(id, 10), parentType, parentName, t, transactionID == "", e)
+	}
+
+	var line *string
+	if data != nil {
+		l := SerializeTCPCheckRule(*data)
+		line = &l
+	}
+	if err := setRawRule(p, section, name, "tcp-check", int(id), line, insert); err != nil {
+		return c.handleError(strconv.FormatInt(id, 10), parentType, parentName, t, transactionID == "", err)
+	}
+
+	if err := c.saveData(p, t, transactionID == ""); err != nil {
+		return err
+	}
+	return nil
+}
+
+func ParseTCPCheckRules(section parser.Section, name string, p *parser.Parser) ([]*TCPCheckRule, error) {
+	rules := []*TCPCheckRule{}
+	lines, err := getRawRules(p, section, name, "tcp-check")
+	if err != nil {
+		return nil, err
+	}
+	for _, l := range lines {
+		r, err := ParseTCPCheckRule(l)
+		if err != nil {
+			return nil, err
+		}
+		id := int64(len(rules))
+		r.Index = &id
+		rules = append(rules, r)
+	}
+	return rules, nil
+}
+
+func ParseTCPCheckRule(line string) (*TCPCheckRule, error) {
+	parts := strings.Fields(line)
+	if len(parts) < 2 {
+		return nil, NewConfError(ErrValidationError, fmt.Sprintf("invalid tcp-check rule %s", line))
+	}
+	r := &TCPCheckRule{Type: parts[1]}
+	switch {
+	case parts[1] == "comment":
+		if len(parts) < 3 {
+			return nil, NewConfError(ErrValidationError, fmt.Sprintf("invalid tcp-check rule %s", line))
+		}
+		r.CheckComment = parts[2]
+	case parts[1] == "connect":
+		a := &actions.CheckConnect{}
+		if err := a.Parse(parts, ""); err != nil {
+			return nil, NewConfError(ErrValidationError, err.Error())
+		}
+		r.Default = a.Default
+		r.Addr = a.Addr
+		r.SendProxy = a.SendProxy
+		r.ViaSocks4 = a.ViaSOCKS4
+		r.SSL = a.SSL
+		r.SNI = a.SNI
+		r.ALPN = a.ALPN
+		r.Linger = a.Linger
+		r.Proto = a.Proto
+		r.CheckComment = a.CheckComment
+		if port, err := strconv.ParseInt(a.Port, 10, 64); err == nil {
+			r.Port = &port
+		}
+	case parts[1] == "expect":
+		a := &actions.CheckExpect{}
+		if err := a.Parse(parts, ""); err != nil {
+			return nil, NewConfError(ErrValidationError, err.Error())
+		}
+		r.MinRecv = a.MinRecv
+		r.OkStatus = a.OKStatus
+		r.ErrorStatus = a.ErrorStatus
+		r.TimeoutStatus = a.TimeoutStatus
+		r.OnSuccess = a.OnSuccess
+		r.OnError = a.OnError
+		r.ExclamationMark = a.ExclamationMark
+		r.Match = a.Match
+		r.Pattern = a.Pattern
+		r.CheckComment = a.CheckComment
+	case strings.HasPrefix(parts[1], "send"):
+		if len(parts) < 3 {
+			return nil, NewConfError(ErrValidationError, fmt.Sprintf("invalid tcp-check rule %s", line))
+		}
+		r.Data = parts[2]
+		if len(parts) > 4 && parts[3] == "comment" {
+			r.CheckComment = parts[4]
+		}
+	case strings.HasPrefix(parts[1], "set-var("):
+		a := &actions.SetVar{}
+		if err := a.Parse(parts, ""); err != nil {
+			return nil, NewConfError(ErrValidationError, err.Error())
+		}
+		r.Type = "set-var"
+		r.VarScope = a.VarScope
+		r.VarName = a.VarName
+		r.VarExpr = strings.Join(a.Expr.Expr, " ")
+	case strings.HasPrefix(parts[1], "unset-var("):
+		a := &actions.UnsetVar{}
+		if err := a.Parse(parts, ""); err != nil {
+			return nil, NewConfError(ErrValidationError, err.Error())
+		}
+		r.Type = "unset-var"
+		r.VarScope = a.Scope
+		r.VarName = a.Name
+	}
+	return r, nil
+}
+
+func SerializeTCPCheckRule(r TCPCheckRule) string {
+	var rule string
+	switch r.Type {
+	case "comment":
+		rule = "comment " + r.CheckComment
+	case "connect":
+		a := &actions.CheckConnect{
+			Default:      r.Default,
+			Addr:         r.Addr,
+			SendProxy:    r.SendProxy,
+			ViaSOCKS4:    r.ViaSocks4,
+			SSL:          r.SSL,
+			SNI:          r.SNI,
+			ALPN:         r.ALPN,
+			Linger:       r.Linger,
+			Proto:        r.Proto,
+			CheckComment: r.CheckComment,
+		}
+		if r.Port != nil {
+			a.Port = strconv.FormatInt(*r.Port, 10)
+		}
+		rule = a.String()
+	case "expect":
+		a := &actions.CheckExpect{
+			MinRecv:         r.MinRecv,
+			CheckComment:    r.CheckComment,
+			OKStatus:        r.OkStatus,
+			ErrorStatus:     r.ErrorStatus,
+			TimeoutStatus:   r.TimeoutStatus,
+			OnSuccess:       r.OnSuccess,
+			OnError:         r.OnError,
+			ExclamationMark: r.ExclamationMark,
+			Match:           r.Match,
+			Pattern:         r.Pattern,
+		}
+		rule = a.String()
+	case "set-var":
+		rule = fmt.Sprintf("set-var(%s.%s) %s", r.VarScope, r.VarName, strings.TrimSpace(r.VarExpr))
+	case "unset-var":
+		rule = fmt.Sprintf("unset-var(%s.%s)", r.VarScope, r.VarName)
+	default:
+		rule = r.Type + " " + r.Data
+		if r.CheckComment != "" {
+			rule += " comment " + r.CheckComment
+		}
+	}
+	return "tcp-check " + rule
+}
+
+func tcpCheckSection(parentType string, parentName string) (parser.Section, string, error) {
+	switch parentType {
+	case "defaults":
+		return parser.Defaults, parser.DefaultSectionName, nil
+	case "backend":
+		return parser.Backends, parentName, nil
+	default:
+		return "", "", NewConfError(ErrValidationError, fmt.Sprintf("tcp-check rules are not supported in %s", parentType))
+	}
+}
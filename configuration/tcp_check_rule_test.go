@@ -0,0 +1,111 @@
+// Copyright 2019 HAProxy Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package configuration
+
+import (
+	"testing"
+
+	"github.com/haproxytech/client-native/v2/misc"
+)
+
+func TestCreateEditDeleteTCPCheckRule(t *testing.T) {
+	_, rules, err := client.GetTCPCheckRules("backend", "test_2", "")
+	if err != nil {
+		t.Error(err.Error())
+	}
+	if len(rules) != 0 {
+		t.Errorf("%v tcp-check rules returned, expected 0", len(rules))
+	}
+
+	newRules := []*TCPCheckRule{
+		{Index: misc.Int64P(0), Type: "connect", Port: misc.Int64P(6379)},
+		{Index: misc.Int64P(1), Type: "send", Data: "PING\\r\\n"},
+		{Index: misc.Int64P(2), Type: "expect", Match: "string", Pattern: "+PONG", CheckComment: "ping"},
+		{Index: misc.Int64P(1), Type: "comment", CheckComment: "redis"},
+	}
+	for _, r := range newRules {
+		if err := client.CreateTCPCheckRule("backend", "test_2", r, "", version); err != nil {
+			t.Error(err.Error())
+		} else {
+			version++
+		}
+	}
+
+	_, rules, err = client.GetTCPCheckRules("backend", "test_2", "")
+	if err != nil {
+		t.Error(err.Error())
+	}
+	if len(rules) != 4 {
+		t.Fatalf("%v tcp-check rules returned, expected 4", len(rules))
+	}
+	for i, typ := range []string{"connect", "comment", "send", "expect"} {
+		if rules[i].Type != typ || *rules[i].Index != int64(i) {
+			t.Errorf("tcp-check rule %v: %v returned, expected %v", i, rules[i].Type, typ)
+		}
+	}
+	if rules[0].Port == nil || *rules[0].Port != 6379 {
+		t.Errorf("connect rule %+v not as created", rules[0])
+	}
+	if rules[2].Data != "PING\\r\\n" {
+		t.Errorf("send rule data %v not as created", rules[2].Data)
+	}
+	if rules[3].Match != "string" || rules[3].Pattern != "+PONG" || rules[3].CheckComment != "ping" {
+		t.Errorf("expect rule %+v not as created", rules[3])
+	}
+
+	edit := &TCPCheckRule{Index: misc.Int64P(3), Type: "expect", ExclamationMark: true, Match: "rstring", Pattern: "^-ERR"}
+	if err := client.EditTCPCheckRule(3, "backend", "test_2", edit, "", version); err != nil {
+		t.Error(err.Error())
+	} else {
+		version++
+	}
+
+	_, r, err := client.GetTCPCheckRule(3, "backend", "test_2", "")
+	if err != nil {
+		t.Error(err.Error())
+	} else if !r.ExclamationMark || r.Match != "rstring" || r.Pattern != "^-ERR" {
+		t.Errorf("expect rule %+v not as edited", r)
+	}
+
+	if err := client.EditTCPCheckRule(4, "backend", "test_2", edit, "", version); err == nil {
+		t.Error("Should throw error, non existent tcp-check rule")
+	}
+
+	invalid := &TCPCheckRule{Index: misc.Int64P(0), Type: "expect", Match: "status", Pattern: "200"}
+	if err := client.CreateTCPCheckRule("backend", "test_2", invalid, "", version); err == nil {
+		t.Error("Should throw error, invalid expect match")
+	}
+
+	for i := 3; i >= 0; i-- {
+		if err := client.DeleteTCPCheckRule(int64(i), "backend", "test_2", "", version); err != nil {
+			t.Error(err.Error())
+		} else {
+			version++
+		}
+	}
+
+	_, rules, err = client.GetTCPCheckRules("backend", "test_2", "")
+	if err != nil {
+		t.Error(err.Error())
+	}
+	if len(rules) != 0 {
+		t.Errorf("%v tcp-check rules returned, expected 0", len(rules))
+	}
+
+	if err := client.DeleteTCPCheckRule(0, "backend", "test_2", "", version); err == nil {
+		t.Error("Should throw error, non existent tcp-check rule")
+	}
+}
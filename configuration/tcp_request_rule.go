@@ -16,6 +16,7 @@
 package configuration
 
 import (
+	"fmt"
 	"strconv"
 	"strings"
 
@@ -89,6 +90,10 @@ func (c *Client) GetTCPRequestRule(id int64, parentType, parentName string, tran
 // DeleteTCPRequestRule deletes a tcp request rule in configuration. One of version or transactionID is
 // mandatory. Returns error on fail, nil on success.
 func (c *Client) DeleteTCPRequestRule(id int64, parentType string, parentName string, transactionID string, version int64) error {
+	if err := c.authorize("DeleteTCPRequestRule", "tcp_request_rule", authorizedIndex(id), parentType, parentName, transactionID); err != nil {
+		return err
+	}
+
 	p, t, err := c.loadDataForChange(transactionID, version)
 	if err != nil {
 		return err
@@ -121,6 +126,10 @@ func (c *Client) CreateTCPRequestRule(parentType string, parentName string, data
 		}
 	}
 
+	if err := c.authorize("CreateTCPRequestRule", "tcp_request_rule", authorizedIndexP(data.Index), parentType, parentName, transactionID); err != nil {
+		return err
+	}
+
 	p, t, err := c.loadDataForChange(transactionID, version)
 	if err != nil {
 		return err
@@ -157,6 +166,10 @@ func (c *Client) EditTCPRequestRule(id int64, parentType string, parentName stri
 			return NewConfError(ErrValidationError, validationErr.Error())
 		}
 	}
+	if err := c.authorize("EditTCPRequestRule", "tcp_request_rule", authorizedIndex(id), parentType, parentName, transactionID); err != nil {
+		return err
+	}
+
 	p, t, err := c.loadDataForChange(transactionID, version)
 	if err != nil {
 		return err
@@ -218,575 +231,331 @@ func ParseTCPRequestRules(t, pName string, p *parser.Parser) (models.TCPRequestR
 	return tcpReqRules, nil
 }
 
-func ParseTCPRequestRule(f types.TCPType) (rule *models.TCPRequestRule, err error) {
+// tcpRequestRuleActions lists the actions HAProxy accepts for each tcp-request rule type
+var tcpRequestRuleActions = map[string][]string{
+	"connection": {
+		"accept", "reject", "expect-proxy", "expect-netscaler-cip", "capture", "track-sc0", "track-sc1", "track-sc2",
+		"sc-inc-gpc0", "sc-inc-gpc1", "sc-set-gpt0", "set-src", "lua",
+	},
+	"content": {
+		"accept", "reject", "do-resolve", "capture", "set-priority", "track-sc0", "track-sc1", "track-sc2",
+		"sc-inc-gpc0", "sc-inc-gpc1", "sc-set-gpt0", "set-dst", "set-dst-port", "set-var", "unset-var",
+		"silent-drop", "send-spoe-group", "use-service", "lua",
+	},
+	"session": {
+		"accept", "reject", "track-sc0", "track-sc1", "track-sc2", "sc-inc-gpc0", "sc-inc-gpc1", "sc-set-gpt0",
+		"set-var", "unset-var", "silent-drop",
+	},
+}
+
+func ParseTCPRequestRule(f types.TCPType) (*models.TCPRequestRule, error) {
+	var rule *models.TCPRequestRule
+	var action types.TCPAction
 	switch v := f.(type) {
 	case *tcp_types.InspectDelay:
 		return &models.TCPRequestRule{
 			Type:    "inspect-delay",
 			Timeout: misc.ParseTimeout(v.Timeout),
 		}, nil
-
 	case *tcp_types.Connection:
 		rule = &models.TCPRequestRule{
 			Type:     "connection",
 			Cond:     v.Cond,
 			CondTest: v.CondTest,
 		}
-
-		switch a := v.Action.(type) {
-		case *tcp_actions.Accept:
-			rule.Action = "accept"
-		case *tcp_actions.Reject:
-			rule.Action = "reject"
-		case *tcp_actions.ExpectProxy:
-			rule.Action = "expect-proxy layer4"
-		case *tcp_actions.ExpectNetscalerCip:
-			rule.Action = "expect-netscaler-cip layer4"
-		case *tcp_actions.Capture:
-			rule.Action = "capture"
-			rule.Expr = a.Expr.String()
-			rule.CaptureLen = a.Len
-		case *tcp_actions.TrackSc0:
-			rule.Action = "track-sc0"
-			rule.TrackKey = a.Key
-			if a.Table != "" {
-				rule.TrackTable = a.Table
-			}
-		case *tcp_actions.TrackSc1:
-			rule.Action = "track-sc1"
-			rule.TrackKey = a.Key
-			if a.Table != "" {
-				rule.TrackTable = a.Table
-			}
-		case *tcp_actions.TrackSc2:
-			rule.Action = "track-sc2"
-			rule.TrackKey = a.Key
-			if a.Table != "" {
-				rule.TrackTable = a.Table
-			}
-		case *tcp_actions.ScIncGpc0:
-			rule.Action = "sc-set-gpt-0"
-			rule.ScIncID = a.ScID
-		case *tcp_actions.ScIncGpc1:
-			rule.Action = "sc-inc-gpc1"
-			rule.ScIncID = a.ScID
-		case *tcp_actions.ScSetGpt0:
-			rule.Action = "sc-set-gpt-0"
-			rule.ScIncID = a.ScID
-			rule.Expr = a.Value
-		case *tcp_actions.SetSrc:
-			rule.Action = "set-src"
-			rule.Expr = a.Expr.String()
-		case *tcp_actions.Lua:
-			rule.Action = "lua"
-			rule.LuaAction = a.Action
-			rule.LuaParams = a.Params
-		default:
-			return nil, NewConfError(ErrValidationError, "unsupported action in tcp_request_rule")
-		}
-
-		return rule, nil
+		action = v.Action
 	case *tcp_types.Content:
 		rule = &models.TCPRequestRule{
 			Type:     "content",
 			Cond:     v.Cond,
 			CondTest: v.CondTest,
 		}
-
-		switch a := v.Action.(type) {
-		case *tcp_actions.Accept:
-			rule.Action = "accept"
-		case *tcp_actions.DoResolve:
-			rule.Action = "do-resolve"
-			rule.VarName = a.Var
-			rule.ResolveResolvers = a.Resolvers
-			rule.ResolveProtocol = a.Protocol
-			rule.Expr = a.Expr.String()
-		case *tcp_actions.Reject:
-			rule.Action = "reject"
-		case *tcp_actions.Capture:
-			rule.Action = "capture"
-			rule.Expr = a.Expr.String()
-			rule.CaptureLen = a.Len
-		case *tcp_actions.SetPriorityClass:
-			rule.Action = "set-priority-class"
-			rule.Expr = a.Expr.String()
-		case *tcp_actions.SetPriorityOffset:
-			rule.Action = "set-priority-offset"
-			rule.Expr = a.Expr.String()
-		case *tcp_actions.TrackSc0:
-			rule.Action = "track-sc0"
-			rule.TrackKey = a.Key
-			if a.Table != "" {
-				rule.TrackTable = a.Table
-			}
-		case *tcp_actions.TrackSc1:
-			rule.Action = "track-sc1"
-			rule.TrackKey = a.Key
-			if a.Table != "" {
-				rule.TrackTable = a.Table
-			}
-		case *tcp_actions.TrackSc2:
-			rule.Action = "track-sc2"
-			rule.TrackKey = a.Key
-			if a.Table != "" {
-				rule.TrackTable = a.Table
-			}
-		case *tcp_actions.ScIncGpc0:
-			rule.Action = "sc-inc-gpc0"
-			rule.ScIncID = a.ScID
-		case *tcp_actions.ScIncGpc1:
-			rule.Action = "sc-inc-gpc1"
-			rule.ScIncID = a.ScID
-		case *tcp_actions.ScSetGpt0:
-			rule.Action = "sc-set-gpt-0"
-			rule.ScIncID = a.ScID
-			rule.GptValue = a.Value
-		case *tcp_actions.SetDst:
-			rule.Action = "set-dst"
-			rule.Expr = a.Expr.String()
-		case *tcp_actions.SetDstPort:
-			rule.Action = "set-dst-port"
-			rule.Expr = a.Expr.String()
-		case *tcp_actions.SetVar:
-			rule.Action = "set-var"
-			rule.VarScope = a.VarScope
-			rule.VarName = a.VarName
-			rule.Expr = a.Expr.String()
-		case *tcp_actions.UnsetVar:
-			rule.Action = "unset-var"
-			rule.VarScope = a.VarScope
-			rule.VarName = a.VarName
-		case *tcp_actions.SilentDrop:
-			rule.Action = "silent-drop"
-		case *tcp_actions.SendSpoeGroup:
-			rule.Action = "send-spoe-group"
-			rule.SpoeEngineName = a.Engine
-			rule.SpoeGroupName = a.Group
-		case *tcp_actions.UseService:
-			rule.Action = "use-service"
-			rule.ServiceName = a.ServiceName
-		case *tcp_actions.Lua:
-			rule.Action = "lua"
-			rule.LuaAction = a.Action
-			rule.LuaParams = a.Params
-		default:
-			return nil, NewConfError(ErrValidationError, "unsupported action in tcp_request_rule")
-		}
+		action = v.Action
 	case *tcp_types.Session:
 		rule = &models.TCPRequestRule{
 			Type:     "session",
 			Cond:     v.Cond,
 			CondTest: v.CondTest,
 		}
-		switch a := v.Action.(type) {
-		case *tcp_actions.Accept:
-			rule.Action = models.TCPRequestRuleActionAccept // TODO use constants for everything maybe?
-		case *tcp_actions.Reject:
-			rule.Action = "reject"
-		case *tcp_actions.TrackSc0:
-			rule.Action = "track-sc0"
-			rule.TrackKey = a.Key
-			if a.Table != "" {
-				rule.TrackTable = a.Table
-			}
-		case *tcp_actions.TrackSc1:
-			rule.Action = "track-sc1"
-			rule.TrackKey = a.Key
-			if a.Table != "" {
-				rule.TrackTable = a.Table
-			}
-		case *tcp_actions.TrackSc2:
-			rule.Action = "track-sc2"
-			rule.TrackKey = a.Key
-			if a.Table != "" {
-				rule.TrackTable = a.Table
-			}
-		case *tcp_actions.ScIncGpc0:
-			rule.Action = "sc-inc-gpc0"
-			rule.ScIncID = a.ScID
-		case *tcp_actions.ScIncGpc1:
-			rule.Action = "sc-inc-gpc1"
-			rule.ScIncID = a.ScID
-		case *tcp_actions.ScSetGpt0:
-			rule.Action = "sc-set-gpt0"
-			rule.ScIncID = a.ScID
-			rule.GptValue = a.Value
-		case *tcp_actions.SetVar:
-			rule.Action = "set-var"
-			rule.VarScope = a.VarScope
-			rule.VarName = a.VarName
-			rule.Expr = a.Expr.String()
-		case *tcp_actions.UnsetVar:
-			rule.Action = "unset-var"
-			rule.VarScope = a.VarScope
-			rule.VarName = a.VarName
-		case *tcp_actions.SilentDrop:
-			rule.Action = "silent-drop"
-		default:
-			return nil, NewConfError(ErrValidationError, "unsupported action in tcp_request_rule")
-		}
+		action = v.Action
 	default:
 		return nil, NewConfError(ErrValidationError, "unsupported action in tcp_request_rule")
 	}
 
+	if err := parseTCPRequestAction(action, rule); err != nil {
+		return nil, err
+	}
+	if !misc.StringInSlice(rule.Action, tcpRequestRuleActions[rule.Type]) {
+		return nil, NewConfError(ErrValidationError, fmt.Sprintf("action %s not supported in tcp-request %s", rule.Action, rule.Type))
+	}
 	return rule, nil
 }
 
-func SerializeTCPRequestRule(f models.TCPRequestRule) (rule types.TCPType, err error) {
+// parseTCPRequestAction fills the action fields of the rule from a parsed tcp-request action
+func parseTCPRequestAction(action types.TCPAction, rule *models.TCPRequestRule) error {
+	switch a := action.(type) {
+	case *tcp_actions.Accept:
+		rule.Action = models.TCPRequestRuleActionAccept
+	case *tcp_actions.Reject:
+		rule.Action = models.TCPRequestRuleActionReject
+	case *tcp_actions.ExpectProxy:
+		rule.Action = models.TCPRequestRuleActionExpectProxy
+	case *tcp_actions.ExpectNetscalerCip:
+		rule.Action = models.TCPRequestRuleActionExpectNetscalerCip
+	case *tcp_actions.Capture:
+		rule.Action = models.TCPRequestRuleActionCapture
+		rule.Expr = a.Expr.String()
+		rule.CaptureLen = a.Len
+	case *tcp_actions.DoResolve:
+		rule.Action = models.TCPRequestRuleActionDoResolve
+		rule.ResolveVar = a.Var
+		rule.ResolveResolvers = a.Resolvers
+		rule.ResolveProtocol = a.Protocol
+		rule.Expr = a.Expr.String()
+	case *tcp_actions.SetPriorityClass:
+		rule.Action = models.TCPRequestRuleActionSetPriority
+		rule.PriorityType = models.TCPRequestRulePriorityTypeClass
+		rule.Expr = a.Expr.String()
+	case *tcp_actions.SetPriorityOffset:
+		rule.Action = models.TCPRequestRuleActionSetPriority
+		rule.PriorityType = models.TCPRequestRulePriorityTypeOffset
+		rule.Expr = a.Expr.String()
+	case *tcp_actions.TrackSc0:
+		rule.Action = models.TCPRequestRuleActionTrackSc0
+		rule.TrackKey = a.Key
+		rule.TrackTable = a.Table
+	case *tcp_actions.TrackSc1:
+		rule.Action = models.TCPRequestRuleActionTrackSc1
+		rule.TrackKey = a.Key
+		rule.TrackTable = a.Table
+	case *tcp_actions.TrackSc2:
+		rule.Action = models.TCPRequestRuleActionTrackSc2
+		rule.TrackKey = a.Key
+		rule.TrackTable = a.Table
+	case *tcp_actions.ScIncGpc0:
+		rule.Action = models.TCPRequestRuleActionScIncGpc0
+		rule.ScIncID = a.ScID
+	case *tcp_actions.ScIncGpc1:
+		rule.Action = models.TCPRequestRuleActionScIncGpc1
+		rule.ScIncID = a.ScID
+	case *tcp_actions.ScSetGpt0:
+		rule.Action = models.TCPRequestRuleActionScSetGpt0
+		rule.ScIncID = a.ScID
+		rule.GptValue = a.Value
+	case *tcp_actions.SetSrc:
+		rule.Action = models.TCPRequestRuleActionSetSrc
+		rule.Expr = a.Expr.String()
+	case *tcp_actions.SetDst:
+		rule.Action = models.TCPRequestRuleActionSetDst
+		rule.Expr = a.Expr.String()
+	case *tcp_actions.SetDstPort:
+		rule.Action = models.TCPRequestRuleActionSetDstPort
+		rule.Expr = a.Expr.String()
+	case *tcp_actions.SetVar:
+		rule.Action = models.TCPRequestRuleActionSetVar
+		rule.VarScope = a.VarScope
+		rule.VarName = a.VarName
+		rule.Expr = a.Expr.String()
+	case *tcp_actions.UnsetVar:
+		rule.Action = models.TCPRequestRuleActionUnsetVar
+		rule.VarScope = a.VarScope
+		rule.VarName = a.VarName
+	case *tcp_actions.SilentDrop:
+		rule.Action = models.TCPRequestRuleActionSilentDrop
+	case *tcp_actions.SendSpoeGroup:
+		rule.Action = models.TCPRequestRuleActionSendSpoeGroup
+		rule.SpoeEngineName = a.Engine
+		rule.SpoeGroupName = a.Group
+	case *tcp_actions.UseService:
+		rule.Action = models.TCPRequestRuleActionUseService
+		rule.ServiceName = a.ServiceName
+	case *tcp_actions.Lua:
+		rule.Action = models.TCPRequestRuleActionLua
+		rule.LuaAction = a.Action
+		rule.LuaParams = a.Params
+	default:
+		return NewConfError(ErrValidationError, "unsupported action in tcp_request_rule")
+	}
+	return nil
+}
+
+func SerializeTCPRequestRule(f models.TCPRequestRule) (types.TCPType, error) {
+	if f.Type == "inspect-delay" {
+		if f.Timeout == nil {
+			return nil, NewConfError(ErrValidationError, "unsupported action in tcp_request_rule")
+		}
+		return &tcp_types.InspectDelay{
+			Timeout: strconv.FormatInt(*f.Timeout, 10),
+		}, nil
+	}
+
+	action, err := serializeTCPRequestAction(f)
+	if err != nil {
+		return nil, err
+	}
 	switch f.Type {
 	case "connection":
-		switch f.Action {
-		case "accept":
-			return &tcp_types.Connection{
-				Action:   &tcp_actions.Accept{},
-				Cond:     f.Cond,
-				CondTest: f.CondTest,
-			}, nil
-		case "reject":
-			return &tcp_types.Connection{
-				Action:   &tcp_actions.Reject{},
-				Cond:     f.Cond,
-				CondTest: f.CondTest,
-			}, nil
-		case "expect-proxy layer4":
-			return &tcp_types.Connection{
-				Action:   &tcp_actions.ExpectProxy{},
-				Cond:     f.Cond,
-				CondTest: f.CondTest,
-			}, nil
-		case "expect-netscaler-cip layer4":
-			return &tcp_types.Connection{
-				Action:   &tcp_actions.ExpectNetscalerCip{},
-				Cond:     f.Cond,
-				CondTest: f.CondTest,
-			}, nil
-		case "capture":
-			return &tcp_types.Connection{
-				Action: &tcp_actions.Capture{
-					Expr: common.Expression{Expr: strings.Split(f.Expr, " ")},
-					Len:  f.CaptureLen,
-				},
-				Cond:     f.Cond,
-				CondTest: f.CondTest,
-			}, nil
-		case "track-sc0":
-			return &tcp_types.Connection{
-				Action: &tcp_actions.TrackSc0{
-					Key:   f.TrackKey,
-					Table: f.TrackTable,
-				},
-				Cond:     f.Cond,
-				CondTest: f.CondTest,
-			}, nil
-		case "track-sc1":
-			return &tcp_types.Connection{
-				Action: &tcp_actions.TrackSc1{
-					Key:   f.TrackKey,
-					Table: f.TrackTable,
-				},
-				Cond:     f.Cond,
-				CondTest: f.CondTest,
-			}, nil
-		case "track-sc2":
-			return &tcp_types.Connection{
-				Action: &tcp_actions.TrackSc2{
-					Key:   f.TrackKey,
-					Table: f.TrackTable,
-				},
-				Cond:     f.Cond,
-				CondTest: f.CondTest,
-			}, nil
-		case "sc-inc-gpc0":
-			return &tcp_types.Connection{
-				Action: &tcp_actions.ScIncGpc0{
-					ScID: f.ScIncID,
-				},
-				Cond:     f.Cond,
-				CondTest: f.CondTest,
-			}, nil
-		case "sc-inc-gpc1":
-			return &tcp_types.Connection{
-				Action: &tcp_actions.ScIncGpc1{
-					ScID: f.ScIncID,
-				},
-				Cond:     f.Cond,
-				CondTest: f.CondTest,
-			}, nil
-		case "lua":
-			return &tcp_types.Connection{
-				Action: &tcp_actions.Lua{
-					Action: f.LuaAction,
-					Params: f.LuaParams,
-				},
-				Cond:     f.Cond,
-				CondTest: f.CondTest,
-			}, nil
-		}
-		return nil, NewConfError(ErrValidationError, "unsupported action in tcp_request_rule")
+		return &tcp_types.Connection{
+			Action:   action,
+			Cond:     f.Cond,
+			CondTest: f.CondTest,
+		}, nil
 	case "content":
+		return &tcp_types.Content{
+			Action:   action,
+			Cond:     f.Cond,
+			CondTest: f.CondTest,
+		}, nil
+	case "session":
+		return &tcp_types.Session{
+			Action:   action,
+			Cond:     f.Cond,
+			CondTest: f.CondTest,
+		}, nil
+	}
+	return nil, NewConfError(ErrValidationError, "unsupported action in tcp_request_rule")
+}
+
+// serializeTCPRequestAction builds the tcp-request action of the rule, checking it is
+// supported by the rule type. Action names used by earlier versions are still accepted.
+func serializeTCPRequestAction(f models.TCPRequestRule) (types.TCPAction, error) {
+	switch f.Action {
+	case "expect-proxy layer4":
+		f.Action = models.TCPRequestRuleActionExpectProxy
+	case "expect-netscaler-cip layer4":
+		f.Action = models.TCPRequestRuleActionExpectNetscalerCip
+	case "set-priority-class":
+		f.Action = models.TCPRequestRuleActionSetPriority
+		f.PriorityType = models.TCPRequestRulePriorityTypeClass
+	case "set-priority-offset":
+		f.Action = models.TCPRequestRuleActionSetPriority
+		f.PriorityType = models.TCPRequestRulePriorityTypeOffset
+	case "sc-inc-gpt0", "sc-set-gpt-0":
+		f.Action = models.TCPRequestRuleActionScSetGpt0
+	}
+	if !misc.StringInSlice(f.Action, tcpRequestRuleActions[f.Type]) {
+		return nil, NewConfError(ErrValidationError, fmt.Sprintf("action %s not supported in tcp-request %s", f.Action, f.Type))
+	}
+
+	switch f.Action {
+	case models.TCPRequestRuleActionAccept:
+		return &tcp_actions.Accept{}, nil
+	case models.TCPRequestRuleActionReject:
+		return &tcp_actions.Reject{}, nil
+	case models.TCPRequestRuleActionExpectProxy:
+		return &tcp_actions.ExpectProxy{}, nil
+	case models.TCPRequestRuleActionExpectNetscalerCip:
+		return &tcp_actions.ExpectNetscalerCip{}, nil
+	case models.TCPRequestRuleActionCapture:
+		expr := f.Expr
+		if expr == "" {
+			expr = f.CaptureSample
+		}
+		if expr == "" || f.CaptureLen <= 0 {
+			return nil, NewConfError(ErrValidationError, "capture requires a sample expression and a length")
+		}
+		return &tcp_actions.Capture{
+			Expr: common.Expression{Expr: strings.Split(expr, " ")},
+			Len:  f.CaptureLen,
+		}, nil
+	case models.TCPRequestRuleActionDoResolve:
+		resolveVar := f.ResolveVar
+		if resolveVar == "" {
+			resolveVar = f.VarName
+		}
+		if resolveVar == "" || f.ResolveResolvers == "" || f.Expr == "" {
+			return nil, NewConfError(ErrValidationError, "do-resolve requires a variable, resolvers and an expression")
+		}
+		return &tcp_actions.DoResolve{
+			Var:       resolveVar,
+			Resolvers: f.ResolveResolvers,
+			Protocol:  f.ResolveProtocol,
+			Expr:      common.Expression{Expr: strings.Split(f.Expr, " ")},
+		}, nil
+	case models.TCPRequestRuleActionSetPriority:
+		if f.Expr == "" {
+			return nil, NewConfError(ErrValidationError, "set-priority requires an expression")
+		}
+		expr := common.Expression{Expr: strings.Split(f.Expr, " ")}
+		switch f.PriorityType {
+		case models.TCPRequestRulePriorityTypeClass:
+			return &tcp_actions.SetPriorityClass{Expr: expr}, nil
+		case models.TCPRequestRulePriorityTypeOffset:
+			return &tcp_actions.SetPriorityOffset{Expr: expr}, nil
+		}
+		return nil, NewConfError(ErrValidationError, "set-priority requires priority_type class or offset")
+	case models.TCPRequestRuleActionTrackSc0, models.TCPRequestRuleActionTrackSc1, models.TCPRequestRuleActionTrackSc2:
+		if f.TrackKey == "" {
+			return nil, NewConfError(ErrValidationError, fmt.Sprintf("%s requires a track key", f.Action))
+		}
 		switch f.Action {
-		case "accept":
-			return &tcp_types.Content{
-				Action:   &tcp_actions.Accept{},
-				Cond:     f.Cond,
-				CondTest: f.CondTest,
-			}, nil
-		case "do-resolve":
-			return &tcp_types.Content{
-				Action: &tcp_actions.DoResolve{
-					Var:       f.VarName,
-					Resolvers: f.ResolveResolvers,
-					Protocol:  f.ResolveProtocol,
-					Expr:      common.Expression{Expr: strings.Split(f.Expr, " ")},
-				},
-				Cond:     f.Cond,
-				CondTest: f.CondTest,
-			}, nil
-		case "reject":
-			return &tcp_types.Content{
-				Action:   &tcp_actions.Reject{},
-				Cond:     f.Cond,
-				CondTest: f.CondTest,
-			}, nil
-		case "capture":
-			return &tcp_types.Content{
-				Action: &tcp_actions.Capture{
-					Expr: common.Expression{Expr: strings.Split(f.Expr, " ")},
-					Len:  f.CaptureLen,
-				},
-				Cond:     f.Cond,
-				CondTest: f.CondTest,
-			}, nil
-		case "set-priority-class":
-			return &tcp_types.Content{
-				Action: &tcp_actions.SetPriorityClass{
-					Expr: common.Expression{Expr: strings.Split(f.Expr, " ")},
-				},
-				Cond:     f.Cond,
-				CondTest: f.CondTest,
-			}, nil
-		case "set-priority-offset":
-			return &tcp_types.Content{
-				Action: &tcp_actions.SetPriorityOffset{
-					Expr: common.Expression{Expr: strings.Split(f.Expr, " ")},
-				},
-				Cond:     f.Cond,
-				CondTest: f.CondTest,
-			}, nil
-		case "track-sc0":
-			return &tcp_types.Content{
-				Action: &tcp_actions.TrackSc0{
-					Key:   f.TrackKey,
-					Table: f.TrackTable,
-				},
-				Cond:     f.Cond,
-				CondTest: f.CondTest,
-			}, nil
-		case "track-sc1":
-			return &tcp_types.Content{
-				Action: &tcp_actions.TrackSc1{
-					Key:   f.TrackKey,
-					Table: f.TrackTable,
-				},
-				Cond:     f.Cond,
-				CondTest: f.CondTest,
-			}, nil
-		case "track-sc2":
-			return &tcp_types.Content{
-				Action: &tcp_actions.TrackSc2{
-					Key:   f.TrackKey,
-					Table: f.TrackTable,
-				},
-				Cond:     f.Cond,
-				CondTest: f.CondTest,
-			}, nil
-		case "sc-inc-gpc0":
-			return &tcp_types.Content{
-				Action: &tcp_actions.ScIncGpc0{
-					ScID: f.ScIncID,
-				},
-				Cond:     f.Cond,
-				CondTest: f.CondTest,
-			}, nil
-		case "sc-inc-gpc1":
-			return &tcp_types.Content{
-				Action: &tcp_actions.ScIncGpc1{
-					ScID: f.ScIncID,
-				},
-				Cond:     f.Cond,
-				CondTest: f.CondTest,
-			}, nil
-		case "set-dst":
-			return &tcp_types.Content{
-				Action: &tcp_actions.SetDst{
-					Expr: common.Expression{Expr: strings.Split(f.Expr, " ")},
-				},
-				Cond:     f.Cond,
-				CondTest: f.CondTest,
-			}, nil
-		case "set-dst-port":
-			return &tcp_types.Content{
-				Action: &tcp_actions.SetDstPort{
-					Expr: common.Expression{Expr: strings.Split(f.Expr, " ")},
-				},
-				Cond:     f.Cond,
-				CondTest: f.CondTest,
-			}, nil
-		case "set-var":
-			return &tcp_types.Content{
-				Action: &tcp_actions.SetVar{
-					VarName:  f.VarName,
-					VarScope: f.VarScope,
-					Expr:     common.Expression{Expr: strings.Split(f.Expr, " ")},
-				},
-				Cond:     f.Cond,
-				CondTest: f.CondTest,
-			}, nil
-		case "unset-var":
-			return &tcp_types.Content{
-				Action: &tcp_actions.UnsetVar{
-					VarName:  f.VarName,
-					VarScope: f.VarScope,
-				},
-				Cond:     f.Cond,
-				CondTest: f.CondTest,
-			}, nil
-		case "silent-drop":
-			return &tcp_types.Content{
-				Action:   &tcp_actions.SilentDrop{},
-				Cond:     f.Cond,
-				CondTest: f.CondTest,
-			}, nil
-		case "send-spoe-group":
-			return &tcp_types.Content{
-				Action: &tcp_actions.SendSpoeGroup{
-					Engine: f.SpoeEngineName,
-					Group:  f.SpoeGroupName,
-				},
-				Cond:     f.Cond,
-				CondTest: f.CondTest,
-			}, nil
-		case "use-service":
-			return &tcp_types.Content{
-				Action: &tcp_actions.UseService{
-					ServiceName: f.ServiceName,
-				},
-				Cond:     f.Cond,
-				CondTest: f.CondTest,
-			}, nil
-		case "lua":
-			return &tcp_types.Content{
-				Action: &tcp_actions.Lua{
-					Action: f.LuaAction,
-					Params: f.LuaParams,
-				},
-				Cond:     f.Cond,
-				CondTest: f.CondTest,
-			}, nil
+		case models.TCPRequestRuleActionTrackSc0:
+			return &tcp_actions.TrackSc0{Key: f.TrackKey, Table: f.TrackTable}, nil
+		case models.TCPRequestRuleActionTrackSc1:
+			return &tcp_actions.TrackSc1{Key: f.TrackKey, Table: f.TrackTable}, nil
 		}
-		return nil, NewConfError(ErrValidationError, "unsupported action in tcp_request_rule")
-	case "session":
+		return &tcp_actions.TrackSc2{Key: f.TrackKey, Table: f.TrackTable}, nil
+	case models.TCPRequestRuleActionScIncGpc0:
+		return &tcp_actions.ScIncGpc0{ScID: f.ScIncID}, nil
+	case models.TCPRequestRuleActionScIncGpc1:
+		return &tcp_actions.ScIncGpc1{ScID: f.ScIncID}, nil
+	case models.TCPRequestRuleActionScSetGpt0:
+		value := f.GptValue
+		if value == "" {
+			value = f.Expr
+		}
+		if value == "" {
+			return nil, NewConfError(ErrValidationError, "sc-set-gpt0 requires a value")
+		}
+		return &tcp_actions.ScSetGpt0{ScID: f.ScIncID, Value: value}, nil
+	case models.TCPRequestRuleActionSetSrc, models.TCPRequestRuleActionSetDst, models.TCPRequestRuleActionSetDstPort:
+		if f.Expr == "" {
+			return nil, NewConfError(ErrValidationError, fmt.Sprintf("%s requires an expression", f.Action))
+		}
+		expr := common.Expression{Expr: strings.Split(f.Expr, " ")}
 		switch f.Action {
-		case "accept":
-			return &tcp_types.Session{
-				Action:   &tcp_actions.Accept{},
-				Cond:     f.Cond,
-				CondTest: f.CondTest,
-			}, nil
-		case "reject":
-			return &tcp_types.Session{
-				Action:   &tcp_actions.Reject{},
-				Cond:     f.Cond,
-				CondTest: f.CondTest,
-			}, nil
-		case "track-sc0":
-			return &tcp_types.Session{
-				Action: &tcp_actions.TrackSc0{
-					Key:   f.TrackKey,
-					Table: f.TrackTable,
-				},
-				Cond:     f.Cond,
-				CondTest: f.CondTest,
-			}, nil
-		case "track-sc1":
-			return &tcp_types.Session{
-				Action: &tcp_actions.TrackSc1{
-					Key:   f.TrackKey,
-					Table: f.TrackTable,
-				},
-				Cond:     f.Cond,
-				CondTest: f.CondTest,
-			}, nil
-		case "track-sc2":
-			return &tcp_types.Session{
-				Action: &tcp_actions.TrackSc2{
-					Key:   f.TrackKey,
-					Table: f.TrackTable,
-				},
-				Cond:     f.Cond,
-				CondTest: f.CondTest,
-			}, nil
-		case "sc-inc-gpc0":
-			return &tcp_types.Session{
-				Action: &tcp_actions.ScIncGpc0{
-					ScID: f.ScIncID,
-				},
-				Cond:     f.Cond,
-				CondTest: f.CondTest,
-			}, nil
-		case "sc-inc-gpc1":
-			return &tcp_types.Session{
-				Action: &tcp_actions.ScIncGpc1{
-					ScID: f.ScIncID,
-				},
-				Cond:     f.Cond,
-				CondTest: f.CondTest,
-			}, nil
-		case "sc-inc-gpt0":
-			return &tcp_types.Session{
-				Action: &tcp_actions.ScSetGpt0{
-					ScID:  f.ScIncID,
-					Value: f.GptValue,
-				},
-				Cond:     f.Cond,
-				CondTest: f.CondTest,
-			}, nil
-		case "set-var":
-			return &tcp_types.Session{
-				Action: &tcp_actions.SetVar{
-					VarName:  f.VarName,
-					VarScope: f.VarScope,
-					Expr:     common.Expression{Expr: strings.Split(f.Expr, " ")},
-				},
-				Cond:     f.Cond,
-				CondTest: f.CondTest,
-			}, nil
-		case "unset-var":
-			return &tcp_types.Session{
-				Action: &tcp_actions.UnsetVar{
-					VarName:  f.VarName,
-					VarScope: f.VarScope,
-				},
-				Cond:     f.Cond,
-				CondTest: f.CondTest,
-			}, nil
-		case "silent-drop":
-			return &tcp_types.Session{
-				Action:   &tcp_actions.SilentDrop{},
-				Cond:     f.Cond,
-				CondTest: f.CondTest,
-			}, nil
+		case models.TCPRequestRuleActionSetSrc:
+			return &tcp_actions.SetSrc{Expr: expr}, nil
+		case models.TCPRequestRuleActionSetDst:
+			return &tcp_actions.SetDst{Expr: expr}, nil
 		}
-		return nil, NewConfError(ErrValidationError, "unsupported action in tcp_request_rule")
-	case "inspect-delay":
-		if f.Timeout == nil {
-			return nil, NewConfError(ErrValidationError, "unsupported action in tcp_request_rule")
+		return &tcp_actions.SetDstPort{Expr: expr}, nil
+	case models.TCPRequestRuleActionSetVar:
+		if f.VarScope == "" || f.VarName == "" || f.Expr == "" {
+			return nil, NewConfError(ErrValidationError, "set-var requires a variable scope, name and expression")
 		}
-		return &tcp_types.InspectDelay{
-			Timeout: strconv.FormatInt(*f.Timeout, 10),
+		return &tcp_actions.SetVar{
+			VarScope: f.VarScope,
+			VarName:  f.VarName,
+			Expr:     common.Expression{Expr: strings.Split(f.Expr, " ")},
+		}, nil
+	case models.TCPRequestRuleActionUnsetVar:
+		if f.VarScope == "" || f.VarName == "" {
+			return nil, NewConfError(ErrValidationError, "unset-var requires a variable scope and name")
+		}
+		return &tcp_actions.UnsetVar{
+			VarScope: f.VarScope,
+			VarName:  f.VarName,
+		}, nil
+	case models.TCPRequestRuleActionSilentDrop:
+		return &tcp_actions.SilentDrop{}, nil
+	case models.TCPRequestRuleActionSendSpoeGroup:
+		return &tcp_actions.SendSpoeGroup{
+			Engine: f.SpoeEngineName,
+			Group:  f.SpoeGroupName,
+		}, nil
+	case models.TCPRequestRuleActionUseService:
+		return &tcp_actions.UseService{
+			ServiceName: f.ServiceName,
+		}, nil
+	case models.TCPRequestRuleActionLua:
+		if f.LuaAction == "" {
+			return nil, NewConfError(ErrValidationError, "lua requires an action name")
+		}
+		return &tcp_actions.Lua{
+			Action: f.LuaAction,
+			Params: f.LuaParams,
 		}, nil
 	}
-
 	return nil, NewConfError(ErrValidationError, "unsupported action in tcp_request_rule")
 }
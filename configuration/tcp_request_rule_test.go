@@ -262,3 +262,52 @@ func TestCreateEditDeleteTCPRequestRule(t *testing.T) {
 		version++
 	}
 }
+
+func TestTCPRequestRuleActions(t *testing.T) {
+	rules := []*models.TCPRequestRule{
+		{Type: "connection", Action: "expect-proxy", Cond: "if", CondTest: "TRUE"},
+		{Type: "connection", Action: "track-sc0", TrackKey: "src", TrackTable: "test_2"},
+		{Type: "connection", Action: "sc-inc-gpc0", ScIncID: "0", Cond: "if", CondTest: "FALSE"},
+		{Type: "content", Action: "capture", Expr: "req.payload(0,6)", CaptureLen: 8},
+		{Type: "content", Action: "set-var", VarScope: "txn", VarName: "proto", Expr: "req.proto_http"},
+		{Type: "content", Action: "set-priority", PriorityType: "class", Expr: "int(1)"},
+		{Type: "session", Action: "sc-set-gpt0", ScIncID: "1", GptValue: "1"},
+		{Type: "session", Action: "silent-drop", Cond: "unless", CondTest: "TRUE"},
+	}
+
+	for i, r := range rules {
+		id := int64(i)
+		r.Index = &id
+		err := client.CreateTCPRequestRule("frontend", "test_2", r, "", version)
+		if err != nil {
+			t.Error(err.Error())
+		} else {
+			version++
+		}
+	}
+
+	_, ondisk, err := client.GetTCPRequestRules("frontend", "test_2", "")
+	if err != nil {
+		t.Error(err.Error())
+	}
+	if !reflect.DeepEqual(ondisk, models.TCPRequestRules(rules)) {
+		for _, r := range ondisk {
+			fmt.Printf("TCP request rule on disk: %v\n", r)
+		}
+		t.Error("TCP request rules on disk not equal to given TCP request rules")
+	}
+
+	r := &models.TCPRequestRule{Index: &[]int64{0}[0], Type: "session", Action: "expect-proxy"}
+	if err := client.CreateTCPRequestRule("frontend", "test_2", r, "", version); err == nil {
+		t.Error("Should throw error, expect-proxy is not a tcp-request session action")
+		version++
+	}
+
+	for i := len(rules) - 1; i >= 0; i-- {
+		if err := client.DeleteTCPRequestRule(int64(i), "frontend", "test_2", "", version); err != nil {
+			t.Error(err.Error())
+		} else {
+			version++
+		}
+	}
+}
@@ -67,7 +67,10 @@ func (c *Client) GetTCPResponseRule(id int64, backend string, transactionID stri
 		return v, nil, c.handleError(strconv.FormatInt(id, 10), "backend", backend, "", false, err)
 	}
 
-	tcpRule := ParseTCPResponseRule(data.(types.TCPType))
+	tcpRule, err := ParseTCPResponseRule(data.(types.TCPType))
+	if err != nil {
+		return v, nil, c.handleError(strconv.FormatInt(id, 10), "backend", backend, "", false, err)
+	}
 	tcpRule.Index = &id
 
 	return v, tcpRule, nil
@@ -76,6 +79,10 @@ func (c *Client) GetTCPResponseRule(id int64, backend string, transactionID stri
 // DeleteTCPResponseRule deletes a tcp response rule in configuration. One of version or transactionID is
 // mandatory. Returns error on fail, nil on success.
 func (c *Client) DeleteTCPResponseRule(id int64, backend string, transactionID string, version int64) error {
+	if err := c.authorize("DeleteTCPResponseRule", "tcp_response_rule", authorizedIndex(id), "backend", backend, transactionID); err != nil {
+		return err
+	}
+
 	p, t, err := c.loadDataForChange(transactionID, version)
 	if err != nil {
 		return err
@@ -100,12 +107,21 @@ func (c *Client) CreateTCPResponseRule(backend string, data *models.TCPResponseR
 			return NewConfError(ErrValidationError, validationErr.Error())
 		}
 	}
+	if err := c.authorize("CreateTCPResponseRule", "tcp_response_rule", authorizedIndexP(data.Index), "backend", backend, transactionID); err != nil {
+		return err
+	}
+
 	p, t, err := c.loadDataForChange(transactionID, version)
 	if err != nil {
 		return err
 	}
 
-	if err := p.Insert(parser.Backends, backend, "tcp-response", SerializeTCPResponseRule(*data), int(*data.Index)); err != nil {
+	s, err := SerializeTCPResponseRule(*data)
+	if err != nil {
+		return err
+	}
+
+	if err := p.Insert(parser.Backends, backend, "tcp-response", s, int(*data.Index)); err != nil {
 		return c.handleError(strconv.FormatInt(*data.Index, 10), "backend", backend, t, transactionID == "", err)
 	}
 
@@ -124,17 +140,26 @@ func (c *Client) EditTCPResponseRule(id int64, backend string, data *models.TCPR
 			return NewConfError(ErrValidationError, validationErr.Error())
 		}
 	}
+	if err := c.authorize("EditTCPResponseRule", "tcp_response_rule", authorizedIndex(id), "backend", backend, transactionID); err != nil {
+		return err
+	}
+
 	p, t, err := c.loadDataForChange(transactionID, version)
 	if err != nil {
 		return err
 	}
 
 	if _, err := p.GetOne(parser.Backends, backend, "tcp-response", int(id)); err != nil {
-		return c.handleError(strconv.FormatInt(*data.Index, 10), "backend", backend, t, transactionID == "", err)
+		return c.handleError(strconv.FormatInt(id, 10), "backend", backend, t, transactionID == "", err)
 	}
 
-	if err := p.Set(parser.Backends, backend, "tcp-response", SerializeTCPResponseRule(*data), int(id)); err != nil {
-		return c.handleError(strconv.FormatInt(*data.Index, 10), "backend", backend, t, transactionID == "", err)
+	s, err := SerializeTCPResponseRule(*data)
+	if err != nil {
+		return err
+	}
+
+	if err := p.Set(parser.Backends, backend, "tcp-response", s, int(id)); err != nil {
+		return c.handleError(strconv.FormatInt(id, 10), "backend", backend, t, transactionID == "", err)
 	}
 
 	if err := c.saveData(p, t, transactionID == ""); err != nil {
@@ -157,8 +182,8 @@ func ParseTCPResponseRules(backend string, p *parser.Parser) (models.TCPResponse
 	tRules := data.([]types.TCPType)
 	for i, tRule := range tRules {
 		id := int64(i)
-		tcpResRule := ParseTCPResponseRule(tRule)
-		if tcpResRule != nil {
+		tcpResRule, err := ParseTCPResponseRule(tRule)
+		if err == nil {
 			tcpResRule.Index = &id
 			tcpResRules = append(tcpResRules, tcpResRule)
 		}
@@ -166,76 +191,67 @@ func ParseTCPResponseRules(backend string, p *parser.Parser) (models.TCPResponse
 	return tcpResRules, nil
 }
 
-func ParseTCPResponseRule(t types.TCPType) *models.TCPResponseRule {
+func ParseTCPResponseRule(t types.TCPType) (*models.TCPResponseRule, error) {
 	switch v := t.(type) {
 	case *tcp_types.InspectDelay:
 		return &models.TCPResponseRule{
 			Type:    "inspect-delay",
 			Timeout: misc.ParseTimeout(v.Timeout),
-		}
+		}, nil
 	case *tcp_types.Content:
+		rule := &models.TCPResponseRule{
+			Type:     "content",
+			Cond:     v.Cond,
+			CondTest: v.CondTest,
+		}
 		switch a := v.Action.(type) {
 		case *tcp_actions.Accept:
-			return &models.TCPResponseRule{
-				Type:     "content",
-				Action:   a.String(),
-				Cond:     v.Cond,
-				CondTest: v.CondTest,
-			}
+			rule.Action = models.TCPResponseRuleActionAccept
 		case *tcp_actions.Reject:
-			return &models.TCPResponseRule{
-				Type:     "content",
-				Action:   a.String(),
-				Cond:     v.Cond,
-				CondTest: v.CondTest,
-			}
+			rule.Action = models.TCPResponseRuleActionReject
 		case *tcp_actions.Lua:
-			return &models.TCPResponseRule{
-				Type:      "content",
-				Action:    "lua",
-				LuaAction: a.Action,
-				LuaParams: a.Params,
-				Cond:      v.Cond,
-				CondTest:  v.CondTest,
-			}
+			rule.Action = models.TCPResponseRuleActionLua
+			rule.LuaAction = a.Action
+			rule.LuaParams = a.Params
+		default:
+			return nil, NewConfError(ErrValidationError, "unsupported action in tcp_response_rule")
 		}
+		return rule, nil
 	}
-	return nil
+	return nil, NewConfError(ErrValidationError, "unsupported action in tcp_response_rule")
 }
 
-func SerializeTCPResponseRule(t models.TCPResponseRule) types.TCPType {
+func SerializeTCPResponseRule(t models.TCPResponseRule) (types.TCPType, error) {
 	switch t.Type {
 	case "content":
+		var action types.TCPAction
 		switch t.Action {
-		case "accept":
-			return &tcp_types.Content{
-				Action:   &tcp_actions.Accept{},
-				Cond:     t.Cond,
-				CondTest: t.CondTest,
+		case models.TCPResponseRuleActionAccept:
+			action = &tcp_actions.Accept{}
+		case models.TCPResponseRuleActionReject:
+			action = &tcp_actions.Reject{}
+		case models.TCPResponseRuleActionLua:
+			if t.LuaAction == "" {
+				return nil, NewConfError(ErrValidationError, "lua requires an action name")
 			}
-		case "reject":
-			return &tcp_types.Content{
-				Action:   &tcp_actions.Reject{},
-				Cond:     t.Cond,
-				CondTest: t.CondTest,
-			}
-		case "lua":
-			return &tcp_types.Content{
-				Action: &tcp_actions.Lua{
-					Action: t.LuaAction,
-					Params: t.LuaParams,
-				},
-				Cond:     t.Cond,
-				CondTest: t.CondTest,
+			action = &tcp_actions.Lua{
+				Action: t.LuaAction,
+				Params: t.LuaParams,
 			}
+		default:
+			return nil, NewConfError(ErrValidationError, "unsupported action in tcp_response_rule")
 		}
+		return &tcp_types.Content{
+			Action:   action,
+			Cond:     t.Cond,
+			CondTest: t.CondTest,
+		}, nil
 	case "inspect-delay":
 		if t.Timeout != nil {
 			return &tcp_types.InspectDelay{
 				Timeout: strconv.FormatInt(*t.Timeout, 10),
-			}
+			}, nil
 		}
 	}
-
-	return nil
+	return nil, NewConfError(ErrValidationError, "unsupported action in tcp_response_rule")
 }
@@ -217,3 +217,25 @@ func TestCreateEditDeleteTCPResponseRule(t *testing.T) {
 		version++
 	}
 }
+
+func TestCreateUnsupportedTCPResponseRule(t *testing.T) {
+	id := int64(0)
+	r := &models.TCPResponseRule{
+		Index:  &id,
+		Type:   "content",
+		Action: "close",
+	}
+	if err := client.CreateTCPResponseRule("test", r, "", version); err == nil {
+		t.Error("Should throw error, unsupported tcp-response action")
+		version++
+	}
+
+	r = &models.TCPResponseRule{
+		Index: &id,
+		Type:  "inspect-delay",
+	}
+	if err := client.CreateTCPResponseRule("test", r, "", version); err == nil {
+		t.Error("Should throw error, inspect-delay without timeout")
+		version++
+	}
+}
@@ -0,0 +1,208 @@
+// Copyright 2019 HAProxy Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package configuration
+
+import (
+	"fmt"
+	"strconv"
+
+	parser "github.com/haproxytech/config-parser/v3"
+	parser_errors "github.com/haproxytech/config-parser/v3/errors"
+	"github.com/haproxytech/config-parser/v3/types"
+
+	"github.com/haproxytech/client-native/v2/misc"
+)
+
+// Timeouts are the timeouts of a defaults, frontend or backend section in milliseconds. The
+// frontend ones are client, client-fin, http-request, http-keep-alive and tarpit, backends take
+// all but client and client-fin.
+type Timeouts struct {
+	Client        *int64 `json:"client,omitempty"`
+	ClientFin     *int64 `json:"client_fin,omitempty"`
+	Server        *int64 `json:"server,omitempty"`
+	ServerFin     *int64 `json:"server_fin,omitempty"`
+	Connect       *int64 `json:"connect,omitempty"`
+	Queue         *int64 `json:"queue,omitempty"`
+	Tunnel        *int64 `json:"tunnel,omitempty"`
+	HTTPRequest   *int64 `json:"http_request,omitempty"`
+	HTTPKeepAlive *int64 `json:"http_keep_alive,omitempty"`
+	Check         *int64 `json:"check,omitempty"`
+	Tarpit        *int64 `json:"tarpit,omitempty"`
+}
+
+func (t *Timeouts) keywords() map[string]**int64 {
+	return map[string]**int64{
+		"timeout client":          &t.Client,
+		"timeout client-fin":      &t.ClientFin,
+		"timeout server":          &t.Server,
+		"timeout server-fin":      &t.ServerFin,
+		"timeout connect":         &t.Connect,
+		"timeout queue":           &t.Queue,
+		"timeout tunnel":          &t.Tunnel,
+		"timeout http-request":    &t.HTTPRequest,
+		"timeout http-keep-alive": &t.HTTPKeepAlive,
+		"timeout check":           &t.Check,
+		"timeout tarpit":          &t.Tarpit,
+	}
+}
+
+// GetTimeouts returns configuration version and the timeouts of the defaults, frontend or backend
+// section. Returns error on fail.
+func (c *Client) GetTimeouts(parentType string, parentName string, transactionID string) (int64, *Timeouts, error) {
+	section, name, err := timeoutsSection(parentType, parentName)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	p, err := c.GetParser(transactionID)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	v, err := c.GetVersion(transactionID)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	if !c.checkSectionExists(section, name, p) {
+		return v, nil, NewConfError(ErrObjectDoesNotExist, fmt.Sprintf("%s %s does not exist", parentType, parentName))
+	}
+
+	timeouts := &Timeouts{}
+	for keyword, value := range timeouts.keywords() {
+		if *value, err = getTimeout(p, section, name, keyword); err != nil {
+			return v, nil, c.handleError(keyword, parentType, parentName, "", false, err)
+		}
+	}
+	return v, timeouts, nil
+}
+
+// SetTimeouts replaces the timeouts of the defaults, frontend or backend section, nil timeouts are
+// removed. Timeouts keeping their value are left as written, with their unit. One of version or
+// transactionID is mandatory. Returns error on fail, nil on success.
+func (c *Client) SetTimeouts(parentType string, parentName string, data *Timeouts, transactionID string, version int64) error {
+	section, name, err := timeoutsSection(parentType, parentName)
+	if err != nil {
+		return err
+	}
+	if data == nil {
+		data = &Timeouts{}
+	}
+
+	if err := c.authorize("SetTimeouts", "timeouts", "", parentType, parentName, transactionID); err != nil {
+		return err
+	}
+
+	p, t, err := c.loadDataForChange(transactionID, version)
+	if err != nil {
+		return err
+	}
+
+	if !c.checkSectionExists(section, name, p) {
+		e := NewConfError(ErrObjectDoesNotExist, fmt.Sprintf("%s %s does not exist", parentType, parentName))
+		return c.handleError("timeout", parentType, parentName, t, transactionID == "", e)
+	}
+
+	for keyword, value := range data.keywords() {
+		if *value == nil {
+			continue
+		}
+		if **value <= 0 {
+			e := NewConfError(ErrValidationError, fmt.Sprintf("%s has to be greater than 0", keyword))
+			return c.handleError(keyword, parentType, parentName, t, transactionID == "", e)
+		}
+		if keyword != tarpitTimeout && !p.HasParser(section, keyword) {
+			e := NewConfError(ErrValidationError, fmt.Sprintf("%s is not supported in %s", keyword, parentType))
+			return c.handleError(keyword, parentType, parentName, t, transactionID == "", e)
+		}
+	}
+	for keyword, value := range data.keywords() {
+		if keyword != tarpitTimeout && !p.HasParser(section, keyword) {
+			continue
+		}
+		if err := setTimeout(p, section, name, keyword, *value); err != nil {
+			return c.handleError(keyword, parentType, parentName, t, transactionID == "", err)
+		}
+	}
+
+	if err := c.saveData(p, t, transactionID == ""); err != nil {
+		return err
+	}
+	return nil
+}
+
+// timeout tarpit has no parser and is kept as an unprocessed line
+const tarpitTimeout = "timeout tarpit"
+
+func getTimeout(p *parser.Parser, section parser.Section, name string, keyword string) (*int64, error) {
+	if keyword == tarpitTimeout {
+		value, found, err := getRawDirective(p, section, name, keyword)
+		if err != nil || !found {
+			return nil, err
+		}
+		return misc.ParseTimeout(value), nil
+	}
+	if !p.HasParser(section, keyword) {
+		return nil, nil
+	}
+	data, err := p.Get(section, name, keyword, false)
+	if err != nil {
+		if err == parser_errors.ErrFetch {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return misc.ParseTimeout(data.(*types.SimpleTimeout).Value), nil
+}
+
+// setTimeout sets the timeout in milliseconds, nil removes it. A timeout already set to the same
+// duration is kept as written, with its unit.
+func setTimeout(p *parser.Parser, section parser.Section, name string, keyword string, value *int64) error {
+	if value != nil {
+		current, err := getTimeout(p, section, name, keyword)
+		if err != nil {
+			return err
+		}
+		if current != nil && *current == *value {
+			return nil
+		}
+	}
+	if keyword == tarpitTimeout {
+		var s *string
+		if value != nil {
+			v := strconv.FormatInt(*value, 10)
+			s = &v
+		}
+		return setRawDirective(p, section, name, keyword, s)
+	}
+	if value == nil {
+		return p.Set(section, name, keyword, nil)
+	}
+	return p.Set(section, name, keyword, &types.SimpleTimeout{Value: strconv.FormatInt(*value, 10)})
+}
+
+func timeoutsSection(parentType string, parentName string) (parser.Section, string, error) {
+	switch parentType {
+	case "defaults":
+		return parser.Defaults, parser.DefaultSectionName, nil
+	case "frontend":
+		return parser.Frontends, parentName, nil
+	case "backend":
+		return parser.Backends, parentName, nil
+	default:
+		return "", "", NewConfError(ErrValidationError, fmt.Sprintf("timeouts are not supported in %s", parentType))
+	}
+}
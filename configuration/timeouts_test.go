@@ -0,0 +1,75 @@
+// Copyright 2019 HAProxy Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package configuration
+
+import (
+	"reflect"
+	"testing"
+
+	parser "github.com/haproxytech/config-parser/v3"
+	"github.com/haproxytech/config-parser/v3/types"
+)
+
+func TestTimeouts(t *testing.T) {
+	_, original, err := client.GetTimeouts("backend", "test", "")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if original.Server == nil || *original.Server != 3000 || original.Tunnel == nil || *original.Tunnel != 5000 {
+		t.Errorf("Timeouts %v: expected timeout server 3s and timeout tunnel 5s", original)
+	}
+
+	clientTimeout := int64(1000)
+	if err := client.SetTimeouts("backend", "test", &Timeouts{Client: &clientTimeout}, "", version); err == nil {
+		t.Error("Should throw error, timeout client not supported in backends")
+	}
+
+	serverFin, tarpit := int64(1000), int64(10000)
+	edited := *original
+	edited.ServerFin = &serverFin
+	edited.Tarpit = &tarpit
+	if err := client.SetTimeouts("backend", "test", &edited, "", version); err != nil {
+		t.Error(err.Error())
+	} else {
+		version++
+	}
+
+	_, timeouts, err := client.GetTimeouts("backend", "test", "")
+	if err != nil {
+		t.Error(err.Error())
+	}
+	if !reflect.DeepEqual(timeouts, &edited) {
+		t.Errorf("Timeouts %v not equal to given %v", timeouts, edited)
+	}
+
+	// unchanged timeouts keep their unit
+	p, err := client.GetParser("")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	data, err := p.Get(parser.Backends, "test", "timeout server", false)
+	if err != nil {
+		t.Error(err.Error())
+	} else if v := data.(*types.SimpleTimeout).Value; v != "3s" {
+		t.Errorf("timeout server %s rewritten, expected 3s", v)
+	}
+
+	if err := client.SetTimeouts("backend", "test", original, "", version); err != nil {
+		t.Error(err.Error())
+	} else {
+		version++
+	}
+}
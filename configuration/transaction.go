@@ -17,6 +17,7 @@ package configuration
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"io/ioutil"
 	"os"
@@ -57,6 +58,19 @@ func (c *Client) StartTransaction(version int64) (*models.Transaction, error) {
 	return c.startTransaction(version, false)
 }
 
+// StartTransactionContext starts a new empty transaction whose changes are made in ctx, the
+// subject of ctx is passed to the authorizer
+func (c *Client) StartTransactionContext(ctx context.Context, version int64) (*models.Transaction, error) {
+	t, err := c.startTransaction(version, false)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.SetContext(t.ID, ctx); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
 func (c *Client) startTransaction(version int64, skipVersion bool) (*models.Transaction, error) {
 	t := &models.Transaction{}
 
@@ -90,15 +104,45 @@ func (c *Client) startTransaction(version int64, skipVersion bool) (*models.Tran
 		}
 		return nil, err
 	}
+
+	// the configuration file may not hold the version, depending on the version strategy,
+	// and the annotation of the last commit does not apply to the new transaction
+	if !skipVersion {
+		err := c.setTransactionVersion(t.ID, version)
+		if err == nil {
+			err = c.clearCommitInfo(t.ID)
+		}
+		if err != nil {
+			c.DeleteParser(t.ID)
+			if c.PersistentTransactions {
+				c.deleteTransactionFiles(t.ID)
+			}
+			return nil, err
+		}
+	}
 	return t, nil
 }
 
-// CommitTransaction commits a transaction by id.
+// CommitTransaction commits a transaction by id. When the reload following the commit fails,
+// the committed transaction is returned along with an ErrReloadFailed error, callers have to
+// check the error code to tell it from a failed commit.
 func (c *Client) CommitTransaction(id string) (*models.Transaction, error) {
 	return c.commitTransaction(id, false)
 }
 
 func (c *Client) commitTransaction(id string, skipVersion bool) (*models.Transaction, error) {
+	t, err := c.commitTransactionData(id, skipVersion)
+	if err == nil {
+		// reloads run external commands, other changes are not blocked meanwhile
+		err = c.reload(id, t.Version)
+	}
+	version, _ := c.GetVersion("")
+	c.auditCommit(id, version, err)
+	c.forgetContext(id)
+	return t, err
+}
+
+func (c *Client) commitTransactionData(id string, skipVersion bool) (*models.Transaction, error) {
 	// check if parser exists and if transaction exists
 	c.mu.Lock()
 	defer c.mu.Unlock()
@@ -107,6 +151,9 @@ func (c *Client) commitTransaction(id string, skipVersion bool) (*models.Transac
 	if err != nil {
 		return nil, err
 	}
+	if err := defaultsSectionsErr(p); err != nil {
+		return nil, err
+	}
 
 	// do a version check before commiting
 	version, err := c.GetVersion("")
@@ -141,12 +188,19 @@ func (c *Client) commitTransaction(id string, skipVersion bool) (*models.Transac
 
 	// save to transaction file if transactions are not persistent
 	if !c.PersistentTransactions {
-		if err := p.Save(transactionFile); err != nil {
+		if err := saveConfig(p, transactionFile); err != nil {
 			c.failTransaction(id)
 			return nil, NewConfError(ErrErrorChangingConfig, err.Error())
 		}
 	}
 
+	if c.ValidateReferencedFiles {
+		if err := checkReferencedFiles(p); err != nil {
+			c.failTransaction(id)
+			return nil, NewConfError(ErrValidationError, err.Error())
+		}
+	}
+
 	if err := c.checkTransactionFile(id); err != nil {
 		c.failTransaction(id)
 		return nil, err
@@ -159,6 +213,22 @@ func (c *Client) commitTransaction(id string, skipVersion bool) (*models.Transac
 		os.Remove(backupToDel)
 	}
 
+	if err := stampCommitInfo(p, id); err != nil {
+		c.failTransaction(id)
+		return nil, NewConfError(ErrErrorChangingConfig, err.Error())
+	}
+
+	newVersion := version + 1
+	if skipVersion {
+		newVersion = tVersion
+	}
+	strategy := c.getVersionStrategy()
+	newVersion, err = strategy.Prepare(p, c.ConfigurationFile, newVersion)
+	if err != nil {
+		c.failTransaction(id)
+		return nil, NewConfError(ErrCannotSetVersion, fmt.Sprintf("Cannot set version: %s", err.Error()))
+	}
+
 	if err := c.writeFile(id, c.ConfigurationFile); err != nil {
 		c.failTransaction(id)
 		return nil, err
@@ -167,14 +237,12 @@ func (c *Client) commitTransaction(id string, skipVersion bool) (*models.Transac
 	c.deleteTransactionFiles(id)
 
 	if err := c.CommitParser(id); err != nil {
-		c.Parser.LoadData(c.ConfigurationFile)
+		loadConfig(c.Parser, c.ConfigurationFile)
 		return nil, err
 	}
 
-	if !skipVersion {
-		if err := c.incrementVersion(); err != nil {
-			return nil, err
-		}
+	if err := strategy.Saved(c.ConfigurationFile, newVersion); err != nil {
+		return nil, NewConfError(ErrCannotSetVersion, fmt.Sprintf("Cannot set version: %s", err.Error()))
 	}
 
 	return &models.Transaction{ID: id, Version: tVersion, Status: "success"}, nil
@@ -487,13 +555,13 @@ func (c *Client) getFailedTransactionVersion(id string) (int64, error) {
 
 func (c *Client) writeFile(id, dest string) error {
 	if id == "" {
-		return c.Parser.Save(dest)
+		return saveConfig(c.Parser, dest)
 	}
 	p, err := c.GetParser(id)
 	if err != nil {
 		return err
 	}
-	return p.Save(dest)
+	return saveConfig(p, dest)
 }
 
 func moveFile(src, dest string) error {
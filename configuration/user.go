@@ -0,0 +1,301 @@
+// Copyright 2019 HAProxy Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package configuration
+
+import (
+	"fmt"
+	"strings"
+
+	parser "github.com/haproxytech/config-parser/v3"
+	parser_errors "github.com/haproxytech/config-parser/v3/errors"
+	"github.com/haproxytech/config-parser/v3/types"
+
+	"github.com/haproxytech/client-native/v2/misc"
+)
+
+// User is a user of a userlist
+type User struct {
+	Username string `json:"username"`
+	// Password in plain text when set, hashed unless Insecure is set. Returned as stored in
+	// configuration, which is the password hash for secure passwords.
+	Password string `json:"password"`
+	// Insecure keeps the password in clear text, insecure-password
+	Insecure bool `json:"insecure,omitempty"`
+	// Groups of the userlist the user belongs to
+	Groups []string `json:"groups,omitempty"`
+}
+
+// GetUsers returns configuration version and an array of
+// configured users in the specified userlist. Returns error on fail.
+func (c *Client) GetUsers(userlist string, transactionID string) (int64, []*User, error) {
+	p, err := c.GetParser(transactionID)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	v, err := c.GetVersion(transactionID)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	if !c.checkSectionExists(parser.UserList, userlist, p) {
+		return v, nil, NewConfError(ErrParentDoesNotExist, fmt.Sprintf("Userlist %s does not exist", userlist))
+	}
+
+	users, err := ParseUsers(userlist, p)
+	if err != nil {
+		return v, nil, c.handleError("", "userlist", userlist, "", false, err)
+	}
+
+	return v, users, nil
+}
+
+// GetUser returns configuration version and a requested user
+// in the specified userlist. Returns error on fail or if user does not exist.
+func (c *Client) GetUser(username string, userlist string, transactionID string) (int64, *User, error) {
+	p, err := c.GetParser(transactionID)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	v, err := c.GetVersion(transactionID)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	user, _ := GetUserByName(username, userlist, p)
+	if user == nil {
+		return v, nil, NewConfError(ErrObjectDoesNotExist, fmt.Sprintf("User %s does not exist in userlist %s", username, userlist))
+	}
+
+	return v, user, nil
+}
+
+// DeleteUser deletes a user in configuration, the user is removed from the groups listing it.
+// One of version or transactionID is mandatory. Returns error on fail, nil on success.
+func (c *Client) DeleteUser(username string, userlist string, transactionID string, version int64) error {
+	if err := c.authorize("DeleteUser", "user", username, "userlist", userlist, transactionID); err != nil {
+		return err
+	}
+
+	p, t, err := c.loadDataForChange(transactionID, version)
+	if err != nil {
+		return err
+	}
+
+	user, i := GetUserByName(username, userlist, p)
+	if user == nil {
+		e := NewConfError(ErrObjectDoesNotExist, fmt.Sprintf("User %s does not exist in userlist %s", username, userlist))
+		return c.handleError(username, "userlist", userlist, t, transactionID == "", e)
+	}
+
+	if err := p.Delete(parser.UserList, userlist, "user", i); err != nil {
+		return c.handleError(username, "userlist", userlist, t, transactionID == "", err)
+	}
+
+	groups, err := ParseGroups(userlist, p)
+	if err != nil {
+		return c.handleError(username, "userlist", userlist, t, transactionID == "", err)
+	}
+	for i, g := range groups {
+		if !misc.StringInSlice(username, g.Users) {
+			continue
+		}
+		g.Users = removeString(g.Users, username)
+		if err := p.Set(parser.UserList, userlist, "group", SerializeGroup(*g), i); err != nil {
+			return c.handleError(username, "userlist", userlist, t, transactionID == "", err)
+		}
+	}
+
+	if err := c.saveData(p, t, transactionID == ""); err != nil {
+		return err
+	}
+	return nil
+}
+
+// CreateUser creates a user in configuration, hashing a plain text password. The groups of the
+// user have to exist. One of version or transactionID is mandatory. Returns error on fail, nil on
+// success.
+func (c *Client) CreateUser(userlist string, data *User, transactionID string, version int64) error {
+	if err := validateUser(data); err != nil {
+		return NewConfError(ErrValidationError, err.Error())
+	}
+	if err := c.authorize("CreateUser", "user", data.Username, "userlist", userlist, transactionID); err != nil {
+		return err
+	}
+
+	p, t, err := c.loadDataForChange(transactionID, version)
+	if err != nil {
+		return err
+	}
+
+	if !c.checkSectionExists(parser.UserList, userlist, p) {
+		e := NewConfError(ErrParentDoesNotExist, fmt.Sprintf("Userlist %s does not exist", userlist))
+		return c.handleError(data.Username, "userlist", userlist, t, transactionID == "", e)
+	}
+
+	user, _ := GetUserByName(data.Username, userlist, p)
+	if user != nil {
+		e := NewConfError(ErrObjectAlreadyExists, fmt.Sprintf("User %s already exists in userlist %s", data.Username, userlist))
+		return c.handleError(data.Username, "userlist", userlist, t, transactionID == "", e)
+	}
+
+	u, err := c.serializeUser(data, userlist, p)
+	if err != nil {
+		return c.handleError(data.Username, "userlist", userlist, t, transactionID == "", err)
+	}
+	if err := p.Insert(parser.UserList, userlist, "user", u, -1); err != nil {
+		return c.handleError(data.Username, "userlist", userlist, t, transactionID == "", err)
+	}
+
+	if err := c.saveData(p, t, transactionID == ""); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// EditUser edits a user in configuration, hashing a plain text password. The groups of the user
+// have to exist. One of version or transactionID is mandatory. Returns error on fail, nil on
+// success.
+func (c *Client) EditUser(username string, userlist string, data *User, transactionID string, version int64) error {
+	if err := validateUser(data); err != nil {
+		return NewConfError(ErrValidationError, err.Error())
+	}
+	if data.Username != username {
+		return NewConfError(ErrValidationError, fmt.Sprintf("user %s can not be renamed to %s", username, data.Username))
+	}
+	if err := c.authorize("EditUser", "user", username, "userlist", userlist, transactionID); err != nil {
+		return err
+	}
+
+	p, t, err := c.loadDataForChange(transactionID, version)
+	if err != nil {
+		return err
+	}
+
+	user, i := GetUserByName(username, userlist, p)
+	if user == nil {
+		e := NewConfError(ErrObjectDoesNotExist, fmt.Sprintf("User %s does not exist in userlist %s", username, userlist))
+		return c.handleError(username, "userlist", userlist, t, transactionID == "", e)
+	}
+
+	u, err := c.serializeUser(data, userlist, p)
+	if err != nil {
+		return c.handleError(username, "userlist", userlist, t, transactionID == "", err)
+	}
+	if err := p.Set(parser.UserList, userlist, "user", u, i); err != nil {
+		return c.handleError(username, "userlist", userlist, t, transactionID == "", err)
+	}
+
+	if err := c.saveData(p, t, transactionID == ""); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func ParseUsers(userlist string, p *parser.Parser) ([]*User, error) {
+	users := []*User{}
+
+	data, err := p.Get(parser.UserList, userlist, "user", false)
+	if err != nil {
+		if err == parser_errors.ErrFetch {
+			return users, nil
+		}
+		return nil, err
+	}
+
+	for _, u := range data.([]types.User) {
+		users = append(users, ParseUser(u))
+	}
+	return users, nil
+}
+
+func ParseUser(u types.User) *User {
+	return &User{
+		Username: u.Name,
+		Password: u.Password,
+		Insecure: u.IsInsecure,
+		Groups:   u.Groups,
+	}
+}
+
+func SerializeUser(u User) types.User {
+	return types.User{
+		Name:       u.Username,
+		Password:   u.Password,
+		IsInsecure: u.Insecure,
+		Groups:     u.Groups,
+	}
+}
+
+func GetUserByName(username string, userlist string, p *parser.Parser) (*User, int) {
+	users, err := ParseUsers(userlist, p)
+	if err != nil {
+		return nil, 0
+	}
+
+	for i, u := range users {
+		if u.Username == username {
+			return u, i
+		}
+	}
+	return nil, 0
+}
+
+// serializeUser checks that the groups of the user exist and hashes its password
+func (c *Client) serializeUser(data *User, userlist string, p *parser.Parser) (types.User, error) {
+	for _, g := range data.Groups {
+		if group, _ := GetGroupByName(g, userlist, p); group == nil {
+			return types.User{}, NewConfError(ErrValidationError, fmt.Sprintf("Group %s does not exist in userlist %s", g, userlist))
+		}
+	}
+	u := *data
+	if !u.Insecure {
+		password, err := c.hashUserPassword(u.Password)
+		if err != nil {
+			return types.User{}, err
+		}
+		u.Password = password
+	}
+	return SerializeUser(u), nil
+}
+
+func validateUser(u *User) error {
+	if u.Username == "" || strings.ContainsAny(u.Username, " \t#") {
+		return fmt.Errorf("invalid username %s", u.Username)
+	}
+	if u.Password == "" || strings.ContainsAny(u.Password, " \t#") {
+		return fmt.Errorf("user %s: password can not be empty nor contain whitespace or '#'", u.Username)
+	}
+	for _, g := range u.Groups {
+		if g == "" || strings.ContainsAny(g, " \t#,") {
+			return fmt.Errorf("user %s: invalid group %s", u.Username, g)
+		}
+	}
+	return nil
+}
+
+func removeString(list []string, s string) []string {
+	result := make([]string, 0, len(list))
+	for _, item := range list {
+		if item != s {
+			result = append(result, item)
+		}
+	}
+	return result
+}
@@ -0,0 +1,98 @@
+// Copyright 2019 HAProxy Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package configuration
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCreateEditDeleteUser(t *testing.T) {
+	if err := client.CreateUserlist(&Userlist{Name: "operators"}, "", version); err != nil {
+		t.Fatal(err.Error())
+	}
+	version++
+	if err := client.CreateGroup("operators", &Group{Name: "ops"}, "", version); err != nil {
+		t.Fatal(err.Error())
+	}
+	version++
+
+	u := &User{Username: "alice", Password: "secret", Groups: []string{"ops"}}
+	if err := client.CreateUser("operators", u, "", version); err != nil {
+		t.Fatal(err.Error())
+	}
+	version++
+
+	v, user, err := client.GetUser("alice", "operators", "")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if !strings.HasPrefix(user.Password, "$6$") || user.Insecure || len(user.Groups) != 1 || user.Groups[0] != "ops" {
+		t.Errorf("Unexpected user: %v", user)
+	}
+	if v != version {
+		t.Errorf("Version %v returned, expected %v", v, version)
+	}
+	if ok, err := client.VerifyUserPassword("operators", "alice", "secret", ""); err != nil || !ok {
+		t.Errorf("Password of alice not verified: %v", err)
+	}
+
+	if err := client.CreateUser("operators", u, "", version); err == nil {
+		t.Error("Should throw error user already exists")
+		version++
+	}
+	if err := client.CreateUser("operators", &User{Username: "bob", Password: "secret", Groups: []string{"devs"}}, "", version); err == nil {
+		t.Error("Should throw error, group devs does not exist")
+		version++
+	}
+	if err := client.CreateUser("operators", &User{Username: "bob", Password: "two words"}, "", version); err == nil {
+		t.Error("Should throw error, invalid password")
+		version++
+	}
+
+	u = &User{Username: "alice", Password: "rotated", Insecure: true}
+	if err := client.EditUser("alice", "operators", u, "", version); err != nil {
+		t.Error(err.Error())
+	} else {
+		version++
+	}
+	_, users, err := client.GetUsers("operators", "")
+	if err != nil {
+		t.Error(err.Error())
+	} else if len(users) != 1 || users[0].Password != "rotated" || !users[0].Insecure || len(users[0].Groups) != 0 {
+		t.Errorf("Unexpected users: %v", users)
+	}
+
+	if err := client.EditGroup("ops", "operators", &Group{Name: "ops", Users: []string{"alice"}}, "", version); err != nil {
+		t.Error(err.Error())
+	} else {
+		version++
+	}
+	if err := client.DeleteUser("alice", "operators", "", version); err != nil {
+		t.Error(err.Error())
+	} else {
+		version++
+	}
+	if _, g, err := client.GetGroup("ops", "operators", ""); err != nil || len(g.Users) != 0 {
+		t.Errorf("User alice not removed from group ops: %v %v", g, err)
+	}
+
+	if err := client.DeleteUserlist("operators", "", version); err != nil {
+		t.Error(err.Error())
+	} else {
+		version++
+	}
+}
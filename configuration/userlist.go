@@ -0,0 +1,142 @@
+// Copyright 2019 HAProxy Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package configuration
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	parser "github.com/haproxytech/config-parser/v3"
+)
+
+// Userlist is a userlist section, the users and groups HTTP authentication is checked against
+type Userlist struct {
+	Name string `json:"name"`
+}
+
+// GetUserlists returns configuration version and an array of
+// configured userlists. Returns error on fail.
+func (c *Client) GetUserlists(transactionID string) (int64, []*Userlist, error) {
+	p, err := c.GetParser(transactionID)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	v, err := c.GetVersion(transactionID)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	names, err := p.SectionsGet(parser.UserList)
+	if err != nil {
+		return v, nil, err
+	}
+
+	userlists := []*Userlist{}
+	for _, name := range names {
+		userlists = append(userlists, &Userlist{Name: name})
+	}
+
+	return v, userlists, nil
+}
+
+// GetUserlist returns configuration version and a requested userlist.
+// Returns error on fail or if userlist does not exist.
+func (c *Client) GetUserlist(name string, transactionID string) (int64, *Userlist, error) {
+	p, err := c.GetParser(transactionID)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	v, err := c.GetVersion(transactionID)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	if !c.checkSectionExists(parser.UserList, name, p) {
+		return v, nil, NewConfError(ErrObjectDoesNotExist, fmt.Sprintf("Userlist %s does not exist", name))
+	}
+
+	return v, &Userlist{Name: name}, nil
+}
+
+// DeleteUserlist deletes a userlist in configuration. Userlists used for HTTP authentication can
+// not be deleted. One of version or transactionID is mandatory. Returns error on fail, nil on
+// success.
+func (c *Client) DeleteUserlist(name string, transactionID string, version int64) error {
+	if err := c.authorize("DeleteUserlist", "userlist", name, "", "", transactionID); err != nil {
+		return err
+	}
+
+	p, t, err := c.loadDataForChange(transactionID, version)
+	if err != nil {
+		return err
+	}
+
+	if !c.checkSectionExists(parser.UserList, name, p) {
+		e := NewConfError(ErrObjectDoesNotExist, fmt.Sprintf("%s %s does not exist", parser.UserList, name))
+		return c.handleError(name, "", "", t, transactionID == "", e)
+	}
+
+	if userlistInUse(p, name) {
+		e := NewConfError(ErrValidationError, fmt.Sprintf("%s %s is used for HTTP authentication", parser.UserList, name))
+		return c.handleError(name, "", "", t, transactionID == "", e)
+	}
+
+	if err := p.SectionsDelete(parser.UserList, name); err != nil {
+		return c.handleError(name, "", "", t, transactionID == "", err)
+	}
+
+	if err := c.saveData(p, t, transactionID == ""); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// CreateUserlist creates a userlist in configuration. One of version or transactionID is
+// mandatory. Returns error on fail, nil on success.
+func (c *Client) CreateUserlist(data *Userlist, transactionID string, version int64) error {
+	if data.Name == "" || strings.ContainsAny(data.Name, " \t#") {
+		return NewConfError(ErrValidationError, fmt.Sprintf("invalid userlist name %s", data.Name))
+	}
+
+	if err := c.authorize("CreateUserlist", "userlist", data.Name, "", "", transactionID); err != nil {
+		return err
+	}
+
+	p, t, err := c.loadDataForChange(transactionID, version)
+	if err != nil {
+		return err
+	}
+
+	if err := p.SectionsCreate(parser.UserList, data.Name); err != nil {
+		return c.handleError(data.Name, "", "", t, transactionID == "", err)
+	}
+
+	if err := c.saveData(p, t, transactionID == ""); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// userlistInUse returns true if an http_auth or http_auth_group fetch checks the userlist
+func userlistInUse(p *parser.Parser, name string) bool {
+	r := regexp.MustCompile(`http_auth(_group)?\(\s*` + regexp.QuoteMeta(name) + `\s*[,)]`)
+	return r.MatchString(p.String())
+}
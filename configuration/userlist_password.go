@@ -0,0 +1,81 @@
+// Copyright 2019 HAProxy Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package configuration
+
+import (
+	"crypto/subtle"
+	"fmt"
+
+	parser "github.com/haproxytech/config-parser/v3"
+	"github.com/haproxytech/config-parser/v3/types"
+
+	"github.com/haproxytech/client-native/v2/misc"
+)
+
+// SetPasswordHashMethod sets the method used to hash plain text passwords of userlist users,
+// SHA-512 crypt by default
+func (c *Client) SetPasswordHashMethod(method misc.PasswordHashMethod) error {
+	if _, err := misc.HashPassword("", method); err != nil {
+		return NewConfError(ErrValidationError, err.Error())
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.passwordHash = method
+	return nil
+}
+
+// VerifyUserPassword checks the password of a user of the userlist against its stored
+// password hash, or its insecure password
+func (c *Client) VerifyUserPassword(userlist string, username string, password string, transactionID string) (bool, error) {
+	p, err := c.GetParser(transactionID)
+	if err != nil {
+		return false, err
+	}
+	if !c.checkSectionExists(parser.UserList, userlist, p) {
+		return false, NewConfError(ErrParentDoesNotExist, fmt.Sprintf("Userlist %s does not exist", userlist))
+	}
+
+	data, err := p.Get(parser.UserList, userlist, "user", false)
+	if err != nil {
+		return false, NewConfError(ErrObjectDoesNotExist, fmt.Sprintf("User %s does not exist in userlist %s", username, userlist))
+	}
+	for _, u := range data.([]types.User) {
+		if u.Name != username {
+			continue
+		}
+		if u.IsInsecure {
+			return subtle.ConstantTimeCompare([]byte(u.Password), []byte(password)) == 1, nil
+		}
+		ok, err := misc.VerifyPassword(password, u.Password)
+		if err != nil {
+			return false, NewConfError(ErrValidationError, fmt.Sprintf("User %s: %s", username, err.Error()))
+		}
+		return ok, nil
+	}
+	return false, NewConfError(ErrObjectDoesNotExist, fmt.Sprintf("User %s does not exist in userlist %s", username, userlist))
+}
+
+// hashUserPassword hashes a plain text userlist password, values already hashed are kept
+func (c *Client) hashUserPassword(password string) (string, error) {
+	if misc.IsPasswordHash(password) {
+		return password, nil
+	}
+	method := c.passwordHash
+	if method == "" {
+		method = misc.PasswordSHA512
+	}
+	return misc.HashPassword(password, method)
+}
@@ -0,0 +1,84 @@
+// Copyright 2019 HAProxy Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package configuration
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/haproxytech/client-native/v2/misc"
+)
+
+func TestUserlistPasswordHash(t *testing.T) {
+	if err := client.SetPasswordHashMethod("des"); err == nil {
+		t.Error("Should throw error, unsupported hash method")
+	}
+	if err := client.SetPasswordHashMethod(misc.PasswordSHA256); err != nil {
+		t.Fatal(err.Error())
+	}
+	defer client.SetPasswordHashMethod(misc.PasswordSHA512)
+
+	a := &StatsAuth{
+		Users: []*StatsAuthUser{
+			{Username: "admin", Password: "secret"},
+			{Username: "ops", Password: "$1$saltsalt$qjXMvbEw8oaL.CzflDtaK/"},
+		},
+		Userlist: "password_users",
+	}
+	err := client.ApplyStatsAuth("backend", "test_2", a, "", version)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	version++
+
+	_, auth, err := client.GetStatsAuth("backend", "test_2", "")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if len(auth.Users) != 2 || !strings.HasPrefix(auth.Users[0].Password, "$5$") || auth.Users[1].Password != a.Users[1].Password {
+		t.Errorf("Unexpected userlist passwords: %v %v", auth.Users[0], auth.Users[1])
+	}
+
+	checks := []struct {
+		user     string
+		password string
+		valid    bool
+	}{
+		{"admin", "secret", true},
+		{"admin", "Secret", false},
+		{"ops", "password", true},
+		{"ops", "secret", false},
+	}
+	for _, c := range checks {
+		ok, err := client.VerifyUserPassword("password_users", c.user, c.password, "")
+		if err != nil {
+			t.Error(err.Error())
+		} else if ok != c.valid {
+			t.Errorf("Password %s of %s verified as %v, expected %v", c.password, c.user, ok, c.valid)
+		}
+	}
+
+	if _, err := client.VerifyUserPassword("password_users", "nobody", "secret", ""); err == nil {
+		t.Error("Should throw error, user does not exist")
+	}
+
+	err = client.DeleteStatsAuth("backend", "test_2", "", version)
+	if err != nil {
+		t.Error(err.Error())
+	} else {
+		version++
+	}
+}
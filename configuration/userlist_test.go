@@ -0,0 +1,97 @@
+// Copyright 2019 HAProxy Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package configuration
+
+import (
+	"testing"
+
+	"github.com/haproxytech/models/v2"
+
+	"github.com/haproxytech/client-native/v2/misc"
+)
+
+func TestCreateDeleteUserlist(t *testing.T) {
+	err := client.CreateUserlist(&Userlist{Name: "admins"}, "", version)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	version++
+	// do not leave the userlist in the shared configuration when the test fails
+	defer func() {
+		if _, _, err := client.GetUserlist("admins", ""); err == nil {
+			if err := client.DeleteUserlist("admins", "", version); err == nil {
+				version++
+			}
+		}
+	}()
+
+	if err := client.CreateUserlist(&Userlist{Name: "admins"}, "", version); err == nil {
+		t.Error("Should throw error userlist already exists")
+		version++
+	}
+
+	v, u, err := client.GetUserlist("admins", "")
+	if err != nil {
+		t.Error(err.Error())
+	} else if u.Name != "admins" {
+		t.Errorf("Userlist %s returned, expected admins", u.Name)
+	}
+	if v != version {
+		t.Errorf("Version %v returned, expected %v", v, version)
+	}
+
+	_, userlists, err := client.GetUserlists("")
+	if err != nil {
+		t.Error(err.Error())
+	}
+	found := false
+	for _, u := range userlists {
+		found = found || u.Name == "admins"
+	}
+	if !found {
+		t.Error("Userlist admins not returned")
+	}
+
+	// userlists checked by http_auth_group can not be deleted
+	acl := &models.ACL{
+		Index:     misc.Int64P(0),
+		ACLName:   "is_admin",
+		Criterion: "http_auth_group(admins)",
+		Value:     "admin",
+	}
+	if err := client.CreateACL("frontend", "test_2", acl, "", version); err != nil {
+		t.Fatal(err.Error())
+	}
+	version++
+	if err := client.DeleteUserlist("admins", "", version); err == nil {
+		t.Error("Should throw error, userlist used by http_auth_group")
+		version++
+	}
+	if err := client.DeleteACL(0, "frontend", "test_2", "", version); err != nil {
+		t.Error(err.Error())
+	} else {
+		version++
+	}
+
+	if err := client.DeleteUserlist("admins", "", version); err != nil {
+		t.Error(err.Error())
+	} else {
+		version++
+	}
+	if _, _, err := client.GetUserlist("admins", ""); err == nil {
+		t.Error("DeleteUserlist failed, userlist admins still exists")
+	}
+}
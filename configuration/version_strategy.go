@@ -0,0 +1,227 @@
+// Copyright 2019 HAProxy Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package configuration
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	parser "github.com/haproxytech/config-parser/v3"
+	"github.com/haproxytech/config-parser/v3/types"
+)
+
+// VersionStrategy keeps track of the version of the configuration file. Transactions always
+// keep the version they started from in their own # _version comment, the strategy only
+// applies to the configuration file itself.
+type VersionStrategy interface {
+	// Version returns the version of the configuration loaded from file into p
+	Version(p *parser.Parser, file string) (int64, error)
+	// Prepare readies p to be saved to file as the given version and returns the version the
+	// configuration will have once saved
+	Prepare(p *parser.Parser, file string, version int64) (int64, error)
+	// Saved is called after the configuration was saved to file as version
+	Saved(file string, version int64) error
+}
+
+// CommentVersion keeps the version in the # _version comment at the top of the configuration
+// file, this is the default strategy
+type CommentVersion struct{}
+
+// Version returns the version from the comment, 1 if the comment is missing
+func (s *CommentVersion) Version(p *parser.Parser, file string) (int64, error) {
+	return commentVersion(p), nil
+}
+
+// Prepare sets the comment to version
+func (s *CommentVersion) Prepare(p *parser.Parser, file string, version int64) (int64, error) {
+	if err := p.Set(parser.Comments, parser.CommentsSectionName, "# _version", types.ConfigVersion{Value: version}); err != nil {
+		return 0, err
+	}
+	return version, nil
+}
+
+// Saved does nothing, the version is saved with the configuration
+func (s *CommentVersion) Saved(file string, version int64) error {
+	return nil
+}
+
+// FileVersion keeps the version in a separate file, leaving the configuration file without
+// the # _version comment
+type FileVersion struct {
+	// Path of the version file, <configuration file>.version if empty
+	Path string
+}
+
+// Version returns the version from the version file, 1 if the file does not exist yet
+func (s *FileVersion) Version(p *parser.Parser, file string) (int64, error) {
+	data, err := ioutil.ReadFile(s.path(file))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return 1, nil
+		}
+		return 0, err
+	}
+	v, err := strconv.ParseInt(strings.TrimSpace(string(data)), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid version in %s", s.path(file))
+	}
+	return v, nil
+}
+
+// Prepare removes the # _version comment from the configuration
+func (s *FileVersion) Prepare(p *parser.Parser, file string, version int64) (int64, error) {
+	if err := removeVersionComment(p); err != nil {
+		return 0, err
+	}
+	return version, nil
+}
+
+// Saved writes version to the version file
+func (s *FileVersion) Saved(file string, version int64) error {
+	path := s.path(file)
+	tmp, err := ioutil.TempFile(filepath.Dir(path), filepath.Base(path))
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.WriteString(strconv.FormatInt(version, 10) + "\n"); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+func (s *FileVersion) path(file string) string {
+	if s.Path != "" {
+		return s.Path
+	}
+	return file + ".version"
+}
+
+// HashVersion derives the version from the sha256 hash of the configuration, nothing is
+// written to keep track of it. Versions are not sequential, so backups of the configuration
+// are named by hash and are not cleaned up.
+type HashVersion struct{}
+
+// Version returns the hash of the configuration
+func (s *HashVersion) Version(p *parser.Parser, file string) (int64, error) {
+	return hashVersion(p), nil
+}
+
+// Prepare removes the # _version comment and returns the hash of the configuration without it
+func (s *HashVersion) Prepare(p *parser.Parser, file string, version int64) (int64, error) {
+	if err := removeVersionComment(p); err != nil {
+		return 0, err
+	}
+	return hashVersion(p), nil
+}
+
+// Saved does nothing, the version is derived from the configuration
+func (s *HashVersion) Saved(file string, version int64) error {
+	return nil
+}
+
+// SetVersionStrategy sets how the version of the configuration file is tracked, nil sets the
+// default CommentVersion
+func (c *Client) SetVersionStrategy(s VersionStrategy) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.versionStrategy = s
+}
+
+func (c *Client) getVersionStrategy() VersionStrategy {
+	if c.versionStrategy == nil {
+		return &CommentVersion{}
+	}
+	return c.versionStrategy
+}
+
+// setTransactionVersion records the version the transaction started from in its # _version
+// comment, saving the transaction file if it changed
+func (c *Client) setTransactionVersion(id string, version int64) error {
+	p, err := c.GetParser(id)
+	if err != nil {
+		return err
+	}
+	data, err := p.Get(parser.Comments, parser.CommentsSectionName, "# _version", false)
+	if ver, ok := data.(*types.ConfigVersion); err == nil && ok && ver.Value == version {
+		return nil
+	}
+	if err := p.Set(parser.Comments, parser.CommentsSectionName, "# _version", types.ConfigVersion{Value: version}); err != nil {
+		return NewConfError(ErrCannotSetVersion, fmt.Sprintf("Cannot set version: %s", err.Error()))
+	}
+	if c.PersistentTransactions {
+		tFile, err := c.getTransactionFile(id)
+		if err != nil {
+			return err
+		}
+		if err := saveConfig(p, tFile); err != nil {
+			return NewConfError(ErrCannotSetVersion, fmt.Sprintf("Cannot set version: %s", err.Error()))
+		}
+	}
+	return nil
+}
+
+// removeVersionComment removes the # _version comment from p. When the configuration does not
+// start with the comment, the parser keeps it as a pre-comment of the first section instead.
+func removeVersionComment(p *parser.Parser) error {
+	if err := p.Set(parser.Comments, parser.CommentsSectionName, "# _version", nil); err != nil {
+		return err
+	}
+	sections := map[parser.Section]string{
+		parser.Global:   parser.GlobalSectionName,
+		parser.Defaults: parser.DefaultSectionName,
+	}
+	for section, name := range sections {
+		data, ok := p.Parsers[section][name]
+		if !ok {
+			continue
+		}
+		comments := []string{}
+		for _, comment := range data.PreComments {
+			if !strings.HasPrefix(comment, "_version=") {
+				comments = append(comments, comment)
+			}
+		}
+		data.PreComments = comments
+	}
+	return nil
+}
+
+func commentVersion(p *parser.Parser) int64 {
+	data, _ := p.Get(parser.Comments, parser.CommentsSectionName, "# _version", true)
+	ver, _ := data.(*types.ConfigVersion)
+	return ver.Value
+}
+
+func hashVersion(p *parser.Parser) int64 {
+	sum := sha256.Sum256([]byte(configString(p)))
+	// keep it positive and non zero, version 0 means no version given
+	v := int64(binary.BigEndian.Uint64(sum[:8]) >> 1)
+	if v == 0 {
+		v = 1
+	}
+	return v
+}
@@ -0,0 +1,137 @@
+// Copyright 2019 HAProxy Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package configuration
+
+import (
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/haproxytech/models/v2"
+)
+
+func TestFileVersionStrategy(t *testing.T) {
+	path := "/tmp/haproxy-version-file.cfg"
+	if err := prepareTestFile(testConf, path); err != nil {
+		t.Fatal(err.Error())
+	}
+	defer deleteTestFile(path)
+	defer os.Remove(path + ".version")
+
+	c := prepareClient(path)
+	c.SetVersionStrategy(&FileVersion{})
+
+	v, err := c.GetVersion("")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if v != 1 {
+		t.Errorf("Version %v returned, expected 1", v)
+	}
+
+	if err := c.CreateBackend(&models.Backend{Name: "version_file"}, "", 1); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	tr, err := c.StartTransaction(2)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if v, _ := c.GetVersion(tr.ID); v != 2 {
+		t.Errorf("Transaction version %v returned, expected 2", v)
+	}
+	if err := c.DeleteBackend("version_file", tr.ID, 0); err != nil {
+		t.Fatal(err.Error())
+	}
+	if _, err := c.CommitTransaction(tr.ID); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	data, err := ioutil.ReadFile(path + ".version")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if strings.TrimSpace(string(data)) != "3" {
+		t.Errorf("Version file holds %s, expected 3", data)
+	}
+	if v, _ := c.GetVersion(""); v != 3 {
+		t.Errorf("Version %v returned, expected 3", v)
+	}
+
+	conf, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if strings.Contains(string(conf), "# _version") {
+		t.Error("Configuration file should not hold the version comment")
+	}
+
+	rawV, _, err := c.GetRawConfiguration("", 0)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if rawV != 3 {
+		t.Errorf("Raw configuration version %v returned, expected 3", rawV)
+	}
+}
+
+func TestHashVersionStrategy(t *testing.T) {
+	path := "/tmp/haproxy-version-hash.cfg"
+	if err := prepareTestFile(testConf, path); err != nil {
+		t.Fatal(err.Error())
+	}
+	defer deleteTestFile(path)
+
+	c := prepareClient(path)
+	c.SetVersionStrategy(&HashVersion{})
+
+	v, err := c.GetVersion("")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if err := c.CreateBackend(&models.Backend{Name: "version_hash"}, "", v); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	newV, err := c.GetVersion("")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if newV == v {
+		t.Error("Version should change with the configuration")
+	}
+
+	conf, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if strings.Contains(string(conf), "# _version") {
+		t.Error("Configuration file should not hold the version comment")
+	}
+
+	err = c.DeleteBackend("version_hash", "", v)
+	if err == nil {
+		t.Error("Should throw error, outdated version")
+	} else if confErr, ok := err.(*ConfError); !ok || confErr.Code() != ErrVersionMismatch {
+		t.Errorf("Unexpected error: %v", err)
+	}
+
+	if err := c.DeleteBackend("version_hash", "", newV); err != nil {
+		t.Error(err.Error())
+	}
+}
@@ -0,0 +1,129 @@
+// Copyright 2019 HAProxy Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package client_native
+
+import (
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	native_errors "github.com/haproxytech/client-native/v2/errors"
+	"github.com/haproxytech/client-native/v2/storage"
+)
+
+// CreateCrtList writes a new crt-list file with the entries to crt-list storage.
+// Returns the path of the file.
+func (c *HAProxyClient) CreateCrtList(name string, entries []*storage.CrtListEntry) (string, error) {
+	if c.CrtListStorage == nil {
+		return "", fmt.Errorf("crt-list storage not configured %w", native_errors.ErrGeneral)
+	}
+	for _, e := range entries {
+		if err := e.Validate(); err != nil {
+			return "", err
+		}
+	}
+	return c.CrtListStorage.Create(name, ioutil.NopCloser(strings.NewReader(storage.SerializeCrtList(entries))))
+}
+
+// GetCrtList returns the entries of the crt-list file in storage
+func (c *HAProxyClient) GetCrtList(name string) ([]*storage.CrtListEntry, error) {
+	if c.CrtListStorage == nil {
+		return nil, fmt.Errorf("crt-list storage not configured %w", native_errors.ErrGeneral)
+	}
+	path, err := c.CrtListStorage.Get(name)
+	if err != nil {
+		return nil, err
+	}
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return storage.ParseCrtList(string(content))
+}
+
+// AddCrtListEntry inserts the entry in the crt-list file before the entry at index, appends it if
+// index is nil. HAProxy uses the first entry matching the SNI of a connection.
+func (c *HAProxyClient) AddCrtListEntry(name string, index *int64, entry *storage.CrtListEntry) error {
+	if err := entry.Validate(); err != nil {
+		return err
+	}
+	entries, err := c.GetCrtList(name)
+	if err != nil {
+		return err
+	}
+	i := int64(len(entries))
+	if index != nil {
+		i = *index
+	}
+	if i < 0 || i > int64(len(entries)) {
+		return fmt.Errorf("crt-list %s entry %d %w", name, i, native_errors.ErrNotFound)
+	}
+	entries = append(entries[:i], append([]*storage.CrtListEntry{entry}, entries[i:]...)...)
+	_, err = c.CrtListStorage.Replace(name, storage.SerializeCrtList(entries))
+	return err
+}
+
+// EditCrtListEntry replaces the entry at index of the crt-list file
+func (c *HAProxyClient) EditCrtListEntry(name string, index int64, entry *storage.CrtListEntry) error {
+	if err := entry.Validate(); err != nil {
+		return err
+	}
+	entries, err := c.GetCrtList(name)
+	if err != nil {
+		return err
+	}
+	if index < 0 || index >= int64(len(entries)) {
+		return fmt.Errorf("crt-list %s entry %d %w", name, index, native_errors.ErrNotFound)
+	}
+	entries[index] = entry
+	_, err = c.CrtListStorage.Replace(name, storage.SerializeCrtList(entries))
+	return err
+}
+
+// DeleteCrtListEntry removes the entry at index from the crt-list file
+func (c *HAProxyClient) DeleteCrtListEntry(name string, index int64) error {
+	entries, err := c.GetCrtList(name)
+	if err != nil {
+		return err
+	}
+	if index < 0 || index >= int64(len(entries)) {
+		return fmt.Errorf("crt-list %s entry %d %w", name, index, native_errors.ErrNotFound)
+	}
+	entries = append(entries[:index], entries[index+1:]...)
+	_, err = c.CrtListStorage.Replace(name, storage.SerializeCrtList(entries))
+	return err
+}
+
+// SetBindCrtList configures the bind to load its certificates from the crt-list file in storage.
+// One of version or transactionID is mandatory. Returns error on fail, nil on success.
+func (c *HAProxyClient) SetBindCrtList(frontend string, bind string, name string, transactionID string, version int64) error {
+	if c.CrtListStorage == nil {
+		return fmt.Errorf("crt-list storage not configured %w", native_errors.ErrGeneral)
+	}
+	path, err := c.CrtListStorage.Get(name)
+	if err != nil {
+		return err
+	}
+	return c.withTransaction(transactionID, version, func(t string) error {
+		_, b, err := c.Configuration.GetBind(bind, frontend, t)
+		if err != nil {
+			return err
+		}
+		b.CrtList = path
+		b.Ssl = true
+		return c.Configuration.EditBind(bind, frontend, b, t, 0)
+	})
+}
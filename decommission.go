@@ -0,0 +1,216 @@
+// Copyright 2019 HAProxy Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package client_native
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/haproxytech/client-native/v2/configuration"
+	native_errors "github.com/haproxytech/client-native/v2/errors"
+	"github.com/haproxytech/models/v2"
+)
+
+// DecommissionPollInterval is the interval used to poll backend sessions while decommissioning
+var DecommissionPollInterval = time.Second
+
+// DecommissionBackend safely tears down a backend. First it removes all references to it:
+// switching rules, default_backend settings of frontends and defaults, use_backend lines of
+// listen sections and map routing entries and defaults. HAProxy is reloaded with reloader,
+// unless the configuration client reloaded it on commit, so no new traffic is sent to the
+// backend. Then it drains its servers through the runtime API and waits up to timeout for
+// the current sessions to finish. At the end it deletes the backend in a final transaction
+// and reloads again. Returns error on fail, nil on success.
+func (c *HAProxyClient) DecommissionBackend(name string, reloader configuration.Reloader, timeout time.Duration) error {
+	if c.Runtime == nil {
+		return fmt.Errorf("runtime client not configured %w", native_errors.ErrGeneral)
+	}
+	if _, _, err := c.Configuration.GetBackend(name, ""); err != nil {
+		return err
+	}
+
+	start := time.Now()
+	if err := c.detachBackend(name); err != nil && !isReloadError(err) {
+		return err
+	}
+	if err := c.detachBackendMapEntries(name); err != nil {
+		return err
+	}
+	if err := c.reloadAfter(reloader, start); err != nil {
+		return err
+	}
+
+	_, servers, err := c.Configuration.GetServers(name, "")
+	if err != nil {
+		return err
+	}
+	for _, s := range servers {
+		if err := c.Runtime.SetServerState(name, s.Name, "drain"); err != nil {
+			return err
+		}
+	}
+
+	if err := c.waitBackendSessions(name, timeout); err != nil {
+		return err
+	}
+
+	start = time.Now()
+	err = c.inTransaction(func(transactionID string) error {
+		return c.Configuration.DeleteBackend(name, transactionID, 0)
+	})
+	if err != nil && !isReloadError(err) {
+		return err
+	}
+	return c.reloadAfter(reloader, start)
+}
+
+// detachBackend removes all references to the backend from frontends, listen sections and
+// defaults in one transaction
+func (c *HAProxyClient) detachBackend(name string) error {
+	return c.inTransaction(func(transactionID string) error {
+		_, frontends, err := c.Configuration.GetFrontends(transactionID)
+		if err != nil {
+			return err
+		}
+		for _, f := range frontends {
+			_, rules, err := c.Configuration.GetBackendSwitchingRules(f.Name, transactionID)
+			if err != nil {
+				return err
+			}
+			// delete from the end so the remaining indexes stay valid
+			for i := len(rules) - 1; i >= 0; i-- {
+				if rules[i].Name == name {
+					if err := c.Configuration.DeleteBackendSwitchingRule(*rules[i].Index, f.Name, transactionID, 0); err != nil {
+						return err
+					}
+					continue
+				}
+				if err := c.detachMapRoutingDefault(name, f.Name, rules[i], transactionID); err != nil {
+					return err
+				}
+			}
+			if f.DefaultBackend == name {
+				f.DefaultBackend = ""
+				if err := c.Configuration.EditFrontend(f.Name, f, transactionID, 0); err != nil {
+					return err
+				}
+			}
+		}
+
+		_, listens, err := c.Configuration.GetListens(transactionID)
+		if err != nil {
+			return err
+		}
+		for _, l := range listens {
+			lines := []string{}
+			for _, line := range l.Lines {
+				fields := strings.Fields(line)
+				if len(fields) > 1 && (fields[0] == "use_backend" || fields[0] == "default_backend") && fields[1] == name {
+					continue
+				}
+				lines = append(lines, line)
+			}
+			if len(lines) == len(l.Lines) {
+				continue
+			}
+			l.Lines = lines
+			if err := c.Configuration.EditListen(l.Name, l, transactionID, 0); err != nil {
+				return err
+			}
+		}
+
+		_, defaults, err := c.Configuration.GetDefaultsConfiguration(transactionID)
+		if err != nil {
+			return err
+		}
+		if defaults.DefaultBackend == name {
+			defaults.DefaultBackend = ""
+			return c.Configuration.PushDefaultsConfiguration(defaults, transactionID, 0)
+		}
+		return nil
+	})
+}
+
+// detachMapRoutingDefault drops the backend as default of a map routing rule
+func (c *HAProxyClient) detachMapRoutingDefault(name, frontend string, rule *models.BackendSwitchingRule, transactionID string) error {
+	m := mapRoutingRegex.FindStringSubmatch(rule.Name)
+	if m == nil || m[3] != name {
+		return nil
+	}
+	rule.Name = fmt.Sprintf("%%[%s(%s)]", m[1], m[2])
+	return c.Configuration.EditBackendSwitchingRule(*rule.Index, frontend, rule, transactionID, 0)
+}
+
+// detachBackendMapEntries removes the entries routing to the backend from all map files in
+// map storage, and from the runtime maps loaded by HAProxy
+func (c *HAProxyClient) detachBackendMapEntries(name string) error {
+	if c.MapStorage == nil {
+		return nil
+	}
+	paths, err := c.MapStorage.GetAll()
+	if err != nil {
+		return err
+	}
+	for _, path := range paths {
+		mapName := filepath.Base(path)
+		entries, err := c.GetMapRoutingEntries(mapName)
+		if err != nil {
+			return err
+		}
+		for _, e := range entries {
+			if e.Value != name {
+				continue
+			}
+			if err := c.DeleteMapRoutingEntry(mapName, e.Key); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// waitBackendSessions polls runtime stats until the backend has no current sessions
+func (c *HAProxyClient) waitBackendSessions(name string, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	for {
+		active := int64(0)
+		for _, collection := range c.Runtime.GetStats() {
+			if collection.Error != "" {
+				return fmt.Errorf("%s %s", collection.RuntimeAPI, collection.Error)
+			}
+			for _, s := range collection.Stats {
+				if s.Type != "backend" || s.Name != name || s.Stats == nil {
+					continue
+				}
+				if s.Stats.Scur != nil {
+					active += *s.Stats.Scur
+				}
+				if s.Stats.Qcur != nil {
+					active += *s.Stats.Qcur
+				}
+			}
+		}
+		if active == 0 {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("backend %s still has %d active sessions after %s", name, active, timeout)
+		}
+		time.Sleep(DecommissionPollInterval)
+	}
+}
@@ -0,0 +1,121 @@
+// Copyright 2019 HAProxy Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package client_native
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/haproxytech/client-native/v2/storage"
+)
+
+const decommissionConfig = `# _version=1
+global
+  daemon
+
+defaults
+  mode http
+  timeout client 5s
+  timeout server 5s
+  timeout connect 5s
+  default_backend app
+
+frontend web
+  bind :8080
+  use_backend app if { path_beg /app }
+  use_backend static if { path_beg /static }
+  default_backend app
+
+listen admin
+  bind :8404
+  use_backend app if { path_beg /app }
+  use_backend static if { path_beg /static }
+  server admin1 127.0.0.1:8082
+
+backend app
+  server app1 127.0.0.1:8081
+
+backend static
+  server static1 127.0.0.1:8083
+`
+
+func TestDetachBackend(t *testing.T) {
+	c := newTestClientConfig(t, decommissionConfig)
+	c.MapStorage = testStorage(t, storage.MapsType)
+	if err := c.CreateMapRouting("web", &MapRouting{Map: "hosts.map", Match: "host", DefaultBackend: "app"}, "", 1); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.CreateMapRouting("web", &MapRouting{Map: "paths.map", Match: "path", DefaultBackend: "static"}, "", 2); err != nil {
+		t.Fatal(err)
+	}
+	for key, backend := range map[string]string{"app.example.com": "app", "static.example.com": "static"} {
+		if err := c.AddMapRoutingEntry("hosts.map", key, backend); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := c.detachBackend("app"); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.detachBackendMapEntries("app"); err != nil {
+		t.Fatal(err)
+	}
+
+	_, raw, err := c.Configuration.GetRawConfiguration("", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range strings.Split(raw, "\n") {
+		if strings.Contains(line, "app") && !strings.Contains(line, "backend app") && !strings.Contains(line, "app1") {
+			t.Errorf("backend app still referenced by %s", strings.TrimSpace(line))
+		}
+	}
+	if strings.Count(raw, "use_backend static if") != 2 {
+		t.Errorf("use_backend static removed:\n%s", raw)
+	}
+	hosts, err := c.GetMapRouting("hosts.map", "web", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hosts.DefaultBackend != "" {
+		t.Errorf("map routing default backend %s not removed", hosts.DefaultBackend)
+	}
+	paths, err := c.GetMapRouting("paths.map", "web", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if paths.DefaultBackend != "static" {
+		t.Errorf("map routing default backend %s, expected static", paths.DefaultBackend)
+	}
+	entries, err := c.GetMapRoutingEntries("hosts.map")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Value != "static" {
+		t.Errorf("map entries %v, expected only the static entry", entries)
+	}
+}
+
+func TestDecommissionBackendWithoutRuntime(t *testing.T) {
+	c := newTestClientConfig(t, decommissionConfig)
+	if err := c.DecommissionBackend("app", nil, time.Second); err == nil {
+		t.Fatal("backend decommissioned without runtime client")
+	}
+	if _, _, err := c.Configuration.GetBackend("app", ""); err != nil {
+		t.Error("backend deleted without runtime client")
+	}
+}
@@ -0,0 +1,47 @@
+// Copyright 2019 HAProxy Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package client_native
+
+import (
+	"fmt"
+
+	"github.com/haproxytech/client-native/v2/configuration"
+	native_errors "github.com/haproxytech/client-native/v2/errors"
+)
+
+// SetErrorPageFile configures the defaults, frontend or backend section to return the file from
+// general storage for the code, replacing the error page of the code. One of version or
+// transactionID is mandatory. Returns error on fail, nil on success.
+func (c *HAProxyClient) SetErrorPageFile(parentType string, parentName string, code int64, name string, transactionID string, version int64) error {
+	if c.GeneralStorage == nil {
+		return fmt.Errorf("general storage not configured %w", native_errors.ErrGeneral)
+	}
+	path, err := c.GeneralStorage.Get(name)
+	if err != nil {
+		return err
+	}
+	page := &configuration.ErrorPage{Code: code, Type: "errorfile", Value: path}
+	return c.withTransaction(transactionID, version, func(t string) error {
+		_, _, err := c.Configuration.GetErrorPage(code, parentType, parentName, t)
+		if confErr, ok := err.(*configuration.ConfError); ok && confErr.Code() == configuration.ErrObjectDoesNotExist {
+			return c.Configuration.CreateErrorPage(parentType, parentName, page, t, 0)
+		}
+		if err != nil {
+			return err
+		}
+		return c.Configuration.EditErrorPage(code, parentType, parentName, page, t, 0)
+	})
+}
@@ -0,0 +1,282 @@
+// Copyright 2019 HAProxy Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package client_native
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"regexp"
+	"strings"
+
+	native_errors "github.com/haproxytech/client-native/v2/errors"
+	"github.com/haproxytech/models/v2"
+)
+
+// MapRouting is a map based routing in a frontend, a map file with host or path keys
+// and backend names as values, used by one generated use_backend rule
+type MapRouting struct {
+	// Map is the name of the map file in map storage
+	Map string `json:"map"`
+	// Match is what is looked up in the map, one of host, path or base
+	Match string `json:"match"`
+	// DefaultBackend is used when there is no match in the map, optional
+	DefaultBackend string `json:"default_backend,omitempty"`
+	// Index of the generated use_backend rule, appended at the end if not set
+	Index *int64 `json:"index,omitempty"`
+}
+
+var mapRoutingFetches = map[string]string{
+	"host": "req.hdr(host),lower,map",
+	"path": "path,map_beg",
+	"base": "base,map_beg",
+}
+
+var mapRoutingRegex = regexp.MustCompile(`^%\[(req\.hdr\(host\),lower,map|path,map_beg|base,map_beg)\(([^,)]+)(?:,([^)]+))?\)\]$`)
+
+// CreateMapRouting creates the map file in map storage if it does not exist and adds the
+// use_backend rule routing by it to the frontend. One of version or transactionID is
+// mandatory. A map file created in a transaction is deleted with the transaction by
+// DeleteTransaction. Returns error on fail, nil on success.
+func (c *HAProxyClient) CreateMapRouting(frontend string, data *MapRouting, transactionID string, version int64) error {
+	if c.MapStorage == nil {
+		return fmt.Errorf("map storage not configured %w", native_errors.ErrGeneral)
+	}
+	created := false
+	path, err := c.MapStorage.Get(data.Map)
+	if err != nil {
+		if path, err = c.MapStorage.Create(data.Map, ioutil.NopCloser(&bytes.Buffer{})); err != nil {
+			return err
+		}
+		created = true
+	}
+	if err = c.createMapRoutingRule(frontend, data, path, transactionID, version); err != nil {
+		if created {
+			c.MapStorage.Delete(data.Map)
+		}
+		return err
+	}
+	if created {
+		c.addTransactionFile(transactionID, c.MapStorage, data.Map, path)
+	}
+	return nil
+}
+
+func (c *HAProxyClient) createMapRoutingRule(frontend string, data *MapRouting, path string, transactionID string, version int64) error {
+	expr, err := mapRoutingExpression(data.Match, path, data.DefaultBackend)
+	if err != nil {
+		return err
+	}
+
+	_, rules, err := c.Configuration.GetBackendSwitchingRules(frontend, transactionID)
+	if err != nil {
+		return err
+	}
+	for _, r := range rules {
+		if m := mapRoutingRegex.FindStringSubmatch(r.Name); m != nil && m[2] == path {
+			return fmt.Errorf("map routing %s in frontend %s %w", data.Map, frontend, native_errors.ErrAlreadyExists)
+		}
+	}
+	index := int64(len(rules))
+	if data.Index != nil {
+		index = *data.Index
+	}
+
+	rule := &models.BackendSwitchingRule{
+		Name:  expr,
+		Index: &index,
+	}
+	return c.Configuration.CreateBackendSwitchingRule(frontend, rule, transactionID, version)
+}
+
+// GetMapRouting returns the map routing using the map file in the frontend.
+// Returns error on fail or if map routing does not exist.
+func (c *HAProxyClient) GetMapRouting(mapName string, frontend string, transactionID string) (*MapRouting, error) {
+	if c.MapStorage == nil {
+		return nil, fmt.Errorf("map storage not configured %w", native_errors.ErrGeneral)
+	}
+	path, err := c.MapStorage.Get(mapName)
+	if err != nil {
+		return nil, err
+	}
+	_, rules, err := c.Configuration.GetBackendSwitchingRules(frontend, transactionID)
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range rules {
+		m := mapRoutingRegex.FindStringSubmatch(r.Name)
+		if m == nil || m[2] != path {
+			continue
+		}
+		mr := &MapRouting{
+			Map:            mapName,
+			DefaultBackend: m[3],
+			Index:          r.Index,
+		}
+		for match, fetch := range mapRoutingFetches {
+			if fetch == m[1] {
+				mr.Match = match
+			}
+		}
+		return mr, nil
+	}
+	return nil, fmt.Errorf("map routing %s in frontend %s %w", mapName, frontend, native_errors.ErrNotFound)
+}
+
+// DeleteMapRouting deletes the use_backend rule routing by the map file from the frontend,
+// the map file itself is kept in map storage. One of version or transactionID is mandatory.
+// Returns error on fail, nil on success.
+func (c *HAProxyClient) DeleteMapRouting(mapName string, frontend string, transactionID string, version int64) error {
+	mr, err := c.GetMapRouting(mapName, frontend, transactionID)
+	if err != nil {
+		return err
+	}
+	return c.Configuration.DeleteBackendSwitchingRule(*mr.Index, frontend, transactionID, version)
+}
+
+// GetMapRoutingEntries returns the entries of the map file from map storage
+func (c *HAProxyClient) GetMapRoutingEntries(mapName string) (models.MapEntries, error) {
+	if c.MapStorage == nil {
+		return nil, fmt.Errorf("map storage not configured %w", native_errors.ErrGeneral)
+	}
+	path, err := c.MapStorage.Get(mapName)
+	if err != nil {
+		return nil, err
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	// parsing does not use the runtime API, it works without a runtime client
+	return c.Runtime.ParseMapEntriesFromFile(bytes.NewReader(data), false), nil
+}
+
+// AddMapRoutingEntry adds a key to backend entry to the map file and to the runtime map
+// if the map is already loaded by HAProxy. Without a runtime client only the file is changed
+func (c *HAProxyClient) AddMapRoutingEntry(mapName, key, backend string) error {
+	if err := validateMapRoutingEntry(key, backend); err != nil {
+		return err
+	}
+	entries, err := c.GetMapRoutingEntries(mapName)
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		if e.Key == key {
+			return fmt.Errorf("key %s in map %s %w", key, mapName, native_errors.ErrAlreadyExists)
+		}
+	}
+	entries = append(entries, &models.MapEntry{Key: key, Value: backend})
+	path, err := c.writeMapRoutingEntries(mapName, entries)
+	if err != nil {
+		return err
+	}
+	if c.Runtime == nil {
+		return nil
+	}
+	if m, _ := c.Runtime.GetMap(path); m != nil {
+		return c.Runtime.AddMapEntry(path, key, backend)
+	}
+	return nil
+}
+
+// EditMapRoutingEntry changes the backend of a key in the map file and in the runtime map
+// if the map is already loaded by HAProxy. Without a runtime client only the file is changed
+func (c *HAProxyClient) EditMapRoutingEntry(mapName, key, backend string) error {
+	if err := validateMapRoutingEntry(key, backend); err != nil {
+		return err
+	}
+	entries, err := c.GetMapRoutingEntries(mapName)
+	if err != nil {
+		return err
+	}
+	found := false
+	for _, e := range entries {
+		if e.Key == key {
+			e.Value = backend
+			found = true
+		}
+	}
+	if !found {
+		return fmt.Errorf("key %s in map %s %w", key, mapName, native_errors.ErrNotFound)
+	}
+	path, err := c.writeMapRoutingEntries(mapName, entries)
+	if err != nil {
+		return err
+	}
+	if c.Runtime == nil {
+		return nil
+	}
+	if m, _ := c.Runtime.GetMap(path); m != nil {
+		return c.Runtime.SetMapEntry(path, key, backend)
+	}
+	return nil
+}
+
+// DeleteMapRoutingEntry removes a key from the map file and from the runtime map
+// if the map is already loaded by HAProxy. Without a runtime client only the file is changed
+func (c *HAProxyClient) DeleteMapRoutingEntry(mapName, key string) error {
+	entries, err := c.GetMapRoutingEntries(mapName)
+	if err != nil {
+		return err
+	}
+	newEntries := models.MapEntries{}
+	for _, e := range entries {
+		if e.Key != key {
+			newEntries = append(newEntries, e)
+		}
+	}
+	if len(newEntries) == len(entries) {
+		return fmt.Errorf("key %s in map %s %w", key, mapName, native_errors.ErrNotFound)
+	}
+	path, err := c.writeMapRoutingEntries(mapName, newEntries)
+	if err != nil {
+		return err
+	}
+	if c.Runtime == nil {
+		return nil
+	}
+	if m, _ := c.Runtime.GetMap(path); m != nil {
+		return c.Runtime.DeleteMapEntry(path, key)
+	}
+	return nil
+}
+
+func (c *HAProxyClient) writeMapRoutingEntries(mapName string, entries models.MapEntries) (string, error) {
+	var b strings.Builder
+	for _, e := range entries {
+		b.WriteString(fmt.Sprintf("%s %s\n", e.Key, e.Value))
+	}
+	return c.MapStorage.Replace(mapName, b.String())
+}
+
+func mapRoutingExpression(match, path, defaultBackend string) (string, error) {
+	fetch, ok := mapRoutingFetches[match]
+	if !ok {
+		return "", fmt.Errorf("unsupported map routing match %s %w", match, native_errors.ErrGeneral)
+	}
+	if defaultBackend != "" {
+		return fmt.Sprintf("%%[%s(%s,%s)]", fetch, path, defaultBackend), nil
+	}
+	return fmt.Sprintf("%%[%s(%s)]", fetch, path), nil
+}
+
+func validateMapRoutingEntry(key, backend string) error {
+	if key == "" || backend == "" || strings.ContainsAny(key, " \t\n") || strings.ContainsAny(backend, " \t\n") {
+		return fmt.Errorf("invalid map entry %s %s %w", key, backend, native_errors.ErrGeneral)
+	}
+	return nil
+}
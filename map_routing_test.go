@@ -0,0 +1,131 @@
+// Copyright 2019 HAProxy Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package client_native
+
+import (
+	"errors"
+	"testing"
+
+	native_errors "github.com/haproxytech/client-native/v2/errors"
+	"github.com/haproxytech/client-native/v2/storage"
+)
+
+func TestMapRouting(t *testing.T) {
+	c := newTestClient(t)
+	c.MapStorage = testStorage(t, storage.MapsType)
+
+	if err := c.CreateMapRouting("web", &MapRouting{Map: "hosts.map", Match: "host", DefaultBackend: "app"}, "", 1); err != nil {
+		t.Fatal(err)
+	}
+	mr, err := c.GetMapRouting("hosts.map", "web", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mr.Match != "host" || mr.DefaultBackend != "app" || *mr.Index != 0 {
+		t.Errorf("map routing %+v, expected host match with default backend app at index 0", mr)
+	}
+	err = c.CreateMapRouting("web", &MapRouting{Map: "hosts.map", Match: "path"}, "", 2)
+	if !errors.Is(err, native_errors.ErrAlreadyExists) {
+		t.Errorf("map routing created twice: %v", err)
+	}
+	if _, err := c.MapStorage.Get("hosts.map"); err != nil {
+		t.Error("existing map deleted by failed create")
+	}
+	if err := c.CreateMapRouting("web", &MapRouting{Map: "invalid.map", Match: "cookie"}, "", 2); err == nil {
+		t.Error("map routing created with unsupported match")
+	}
+	if _, err := c.MapStorage.Get("invalid.map"); err == nil {
+		t.Error("map created by failed create not deleted")
+	}
+
+	if err := c.AddMapRoutingEntry("hosts.map", "example.com", "app"); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.AddMapRoutingEntry("hosts.map", "example.com", "app"); !errors.Is(err, native_errors.ErrAlreadyExists) {
+		t.Errorf("entry added twice: %v", err)
+	}
+	if err := c.AddMapRoutingEntry("hosts.map", "example.org", "other app"); err == nil {
+		t.Error("entry with space in backend added")
+	}
+	if err := c.AddMapRoutingEntry("hosts.map", "example.org", "app"); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.EditMapRoutingEntry("hosts.map", "example.org", "static"); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.EditMapRoutingEntry("hosts.map", "example.net", "static"); !errors.Is(err, native_errors.ErrNotFound) {
+		t.Errorf("missing entry edited: %v", err)
+	}
+	if err := c.DeleteMapRoutingEntry("hosts.map", "example.com"); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.DeleteMapRoutingEntry("hosts.map", "example.com"); !errors.Is(err, native_errors.ErrNotFound) {
+		t.Errorf("missing entry deleted: %v", err)
+	}
+	entries, err := c.GetMapRoutingEntries("hosts.map")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Key != "example.org" || entries[0].Value != "static" {
+		t.Errorf("map entries %v, expected example.org static", entries)
+	}
+
+	if err := c.DeleteMapRouting("hosts.map", "web", "", 2); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.GetMapRouting("hosts.map", "web", ""); !errors.Is(err, native_errors.ErrNotFound) {
+		t.Errorf("map routing not deleted: %v", err)
+	}
+	if _, err := c.MapStorage.Get("hosts.map"); err != nil {
+		t.Error("map deleted with map routing")
+	}
+}
+
+func TestMapRoutingTransactionFiles(t *testing.T) {
+	c := newTestClient(t)
+	c.MapStorage = testStorage(t, storage.MapsType)
+
+	committed := testTransaction(t, c)
+	if err := c.CreateMapRouting("web", &MapRouting{Map: "committed.map", Match: "host"}, committed, 0); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Configuration.CommitTransaction(committed); err != nil {
+		t.Fatal(err)
+	}
+
+	deleted := testTransaction(t, c)
+	if err := c.CreateMapRouting("web", &MapRouting{Map: "deleted.map", Match: "host"}, deleted, 0); err != nil {
+		t.Fatal(err)
+	}
+	// deleted with the configuration client, cleaned up with the next transaction
+	if err := c.Configuration.DeleteTransaction(deleted); err != nil {
+		t.Fatal(err)
+	}
+
+	discarded := testTransaction(t, c)
+	if err := c.CreateMapRouting("web", &MapRouting{Map: "discarded.map", Match: "path"}, discarded, 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.DeleteTransaction(discarded); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, exists := range map[string]bool{"committed.map": true, "deleted.map": false, "discarded.map": false} {
+		if _, err := c.MapStorage.Get(name); (err == nil) != exists {
+			t.Errorf("%s exists: %t, expected %t", name, err == nil, exists)
+		}
+	}
+}
@@ -0,0 +1,324 @@
+// Copyright 2019 HAProxy Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package misc
+
+import (
+	"crypto/md5"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/subtle"
+	"fmt"
+	"hash"
+	"strconv"
+	"strings"
+)
+
+// PasswordHashMethod is a crypt(3) password hashing method accepted in HAProxy userlists
+type PasswordHashMethod string
+
+const (
+	// PasswordMD5 is md5-crypt ($1$), supported by every crypt(3) implementation
+	PasswordMD5 PasswordHashMethod = "md5"
+	// PasswordSHA256 is SHA-256 crypt ($5$)
+	PasswordSHA256 PasswordHashMethod = "sha256"
+	// PasswordSHA512 is SHA-512 crypt ($6$)
+	PasswordSHA512 PasswordHashMethod = "sha512"
+)
+
+const cryptAlphabet = "./0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+
+const (
+	shaCryptRounds    = 5000
+	shaCryptMinRounds = 1000
+	shaCryptMaxRounds = 999999999
+	md5CryptRounds    = 1000
+)
+
+// sha256CryptOrder is the order in which bytes of the SHA-256 digest are encoded, in groups of three
+var sha256CryptOrder = []int{
+	0, 10, 20, 21, 1, 11, 12, 22, 2, 3, 13, 23, 24, 4, 14, 15, 25, 5, 6, 16, 26, 27, 7, 17,
+	18, 28, 8, 9, 19, 29, 31, 30,
+}
+
+// sha512CryptOrder is the order in which bytes of the SHA-512 digest are encoded, in groups of three
+var sha512CryptOrder = []int{
+	0, 21, 42, 22, 43, 1, 44, 2, 23, 3, 24, 45, 25, 46, 4, 47, 5, 26, 6, 27, 48, 28, 49, 7,
+	50, 8, 29, 9, 30, 51, 31, 52, 10, 53, 11, 32, 12, 33, 54, 34, 55, 13, 56, 14, 35, 15, 36, 57,
+	37, 58, 16, 59, 17, 38, 18, 39, 60, 40, 61, 19, 62, 20, 41, 63,
+}
+
+// HashPasswordSHA512 returns the SHA-512 crypt hash ($6$) of the password with a random salt,
+// the format used by HAProxy userlist password lines
+func HashPasswordSHA512(password string) (string, error) {
+	salt, err := cryptSalt(16)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("$6$%s$%s", salt, shaCrypt(sha512.New, sha512CryptOrder, []byte(password), []byte(salt), shaCryptRounds)), nil
+}
+
+// HashPasswordSHA256 returns the SHA-256 crypt hash ($5$) of the password with a random salt
+func HashPasswordSHA256(password string) (string, error) {
+	salt, err := cryptSalt(16)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("$5$%s$%s", salt, shaCrypt(sha256.New, sha256CryptOrder, []byte(password), []byte(salt), shaCryptRounds)), nil
+}
+
+// HashPasswordMD5 returns the md5-crypt hash ($1$) of the password with a random salt
+func HashPasswordMD5(password string) (string, error) {
+	salt, err := cryptSalt(8)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("$1$%s$%s", salt, md5Crypt([]byte(password), []byte(salt))), nil
+}
+
+// HashPassword returns the hash of the password with the given method
+func HashPassword(password string, method PasswordHashMethod) (string, error) {
+	switch method {
+	case PasswordMD5:
+		return HashPasswordMD5(password)
+	case PasswordSHA256:
+		return HashPasswordSHA256(password)
+	case PasswordSHA512:
+		return HashPasswordSHA512(password)
+	default:
+		return "", fmt.Errorf("unsupported password hash method %s", method)
+	}
+}
+
+// IsPasswordHash returns true if the value is a crypt(3) hash in the modular format, as opposed
+// to a plain text password
+func IsPasswordHash(value string) bool {
+	parts := strings.Split(value, "$")
+	return len(parts) >= 4 && parts[0] == "" && parts[1] != ""
+}
+
+// VerifyPassword checks the password against a md5-crypt, SHA-256 or SHA-512 crypt hash,
+// returns error for other hash formats
+func VerifyPassword(password, hashed string) (bool, error) {
+	if !IsPasswordHash(hashed) {
+		return false, fmt.Errorf("not a password hash")
+	}
+	parts := strings.Split(hashed, "$")
+	id := parts[1]
+	params := parts[2 : len(parts)-1]
+
+	var computed string
+	switch id {
+	case "1":
+		if len(params) != 1 {
+			return false, fmt.Errorf("invalid md5-crypt hash")
+		}
+		computed = fmt.Sprintf("$1$%s$%s", params[0], md5Crypt([]byte(password), []byte(truncate(params[0], 8))))
+	case "5", "6":
+		rounds := shaCryptRounds
+		roundsParam := ""
+		if len(params) == 2 && strings.HasPrefix(params[0], "rounds=") {
+			r, err := strconv.Atoi(strings.TrimPrefix(params[0], "rounds="))
+			if err != nil {
+				return false, fmt.Errorf("invalid rounds in hash")
+			}
+			rounds = r
+			if rounds < shaCryptMinRounds {
+				rounds = shaCryptMinRounds
+			}
+			if rounds > shaCryptMaxRounds {
+				rounds = shaCryptMaxRounds
+			}
+			roundsParam = fmt.Sprintf("rounds=%d$", rounds)
+			params = params[1:]
+		}
+		if len(params) != 1 {
+			return false, fmt.Errorf("invalid SHA crypt hash")
+		}
+		salt := truncate(params[0], 16)
+		newHash, order := sha512.New, sha512CryptOrder
+		if id == "5" {
+			newHash, order = sha256.New, sha256CryptOrder
+		}
+		computed = fmt.Sprintf("$%s$%s%s$%s", id, roundsParam, salt, shaCrypt(newHash, order, []byte(password), []byte(salt), rounds))
+	default:
+		return false, fmt.Errorf("unsupported password hash $%s$", id)
+	}
+	return subtle.ConstantTimeCompare([]byte(computed), []byte(hashed)) == 1, nil
+}
+
+func truncate(s string, length int) string {
+	if len(s) > length {
+		return s[:length]
+	}
+	return s
+}
+
+func cryptSalt(length int) (string, error) {
+	b := make([]byte, length)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	for i := range b {
+		b[i] = cryptAlphabet[int(b[i])%len(cryptAlphabet)]
+	}
+	return string(b), nil
+}
+
+// shaCrypt implements the SHA-crypt algorithm by Ulrich Drepper and returns the encoded digest
+func shaCrypt(newHash func() hash.Hash, order []int, password, salt []byte, rounds int) string {
+	h := newHash()
+	h.Write(password)
+	h.Write(salt)
+	h.Write(password)
+	b := h.Sum(nil)
+
+	h = newHash()
+	h.Write(password)
+	h.Write(salt)
+	h.Write(repeatBytes(b, len(password)))
+	for i := len(password); i > 0; i >>= 1 {
+		if i&1 != 0 {
+			h.Write(b)
+		} else {
+			h.Write(password)
+		}
+	}
+	a := h.Sum(nil)
+
+	h = newHash()
+	for i := 0; i < len(password); i++ {
+		h.Write(password)
+	}
+	p := repeatBytes(h.Sum(nil), len(password))
+
+	h = newHash()
+	for i := 0; i < 16+int(a[0]); i++ {
+		h.Write(salt)
+	}
+	s := repeatBytes(h.Sum(nil), len(salt))
+
+	c := a
+	for i := 0; i < rounds; i++ {
+		h = newHash()
+		if i%2 != 0 {
+			h.Write(p)
+		} else {
+			h.Write(c)
+		}
+		if i%3 != 0 {
+			h.Write(s)
+		}
+		if i%7 != 0 {
+			h.Write(p)
+		}
+		if i%2 != 0 {
+			h.Write(c)
+		} else {
+			h.Write(p)
+		}
+		c = h.Sum(nil)
+	}
+
+	return cryptEncode(c, order)
+}
+
+// md5Crypt implements the md5-crypt algorithm by Poul-Henning Kamp and returns the encoded digest
+func md5Crypt(password, salt []byte) string {
+	h := md5.New()
+	h.Write(password)
+	h.Write(salt)
+	h.Write(password)
+	alt := h.Sum(nil)
+
+	h = md5.New()
+	h.Write(password)
+	h.Write([]byte("$1$"))
+	h.Write(salt)
+	h.Write(repeatBytes(alt, len(password)))
+	for i := len(password); i > 0; i >>= 1 {
+		if i&1 != 0 {
+			h.Write([]byte{0})
+		} else {
+			h.Write(password[:1])
+		}
+	}
+	c := h.Sum(nil)
+
+	for i := 0; i < md5CryptRounds; i++ {
+		h = md5.New()
+		if i%2 != 0 {
+			h.Write(password)
+		} else {
+			h.Write(c)
+		}
+		if i%3 != 0 {
+			h.Write(salt)
+		}
+		if i%7 != 0 {
+			h.Write(password)
+		}
+		if i%2 != 0 {
+			h.Write(c)
+		} else {
+			h.Write(password)
+		}
+		c = h.Sum(nil)
+	}
+
+	return cryptEncode(c, md5CryptOrder)
+}
+
+// md5CryptOrder is the order in which bytes of the MD5 digest are encoded, in groups of three
+var md5CryptOrder = []int{0, 6, 12, 1, 7, 13, 2, 8, 14, 3, 9, 15, 4, 10, 5, 11}
+
+// repeatBytes returns data repeated up to length bytes
+func repeatBytes(data []byte, length int) []byte {
+	result := make([]byte, 0, length)
+	for len(result) < length {
+		n := length - len(result)
+		if n > len(data) {
+			n = len(data)
+		}
+		result = append(result, data[:n]...)
+	}
+	return result
+}
+
+// cryptEncode encodes the digest bytes in the given order with the crypt base64 alphabet,
+// each group of three bytes is encoded as four characters, the least significant bits first
+func cryptEncode(digest []byte, order []int) string {
+	result := make([]byte, 0, (len(order)*4+2)/3)
+	for i := 0; i < len(order); i += 3 {
+		w := 0
+		n := 4
+		switch len(order) - i {
+		case 1:
+			w = int(digest[order[i]])
+			n = 2
+		case 2:
+			w = int(digest[order[i]])<<8 | int(digest[order[i+1]])
+			n = 3
+		default:
+			w = int(digest[order[i]])<<16 | int(digest[order[i+1]])<<8 | int(digest[order[i+2]])
+		}
+		for j := 0; j < n; j++ {
+			result = append(result, cryptAlphabet[w&0x3f])
+			w >>= 6
+		}
+	}
+	return string(result)
+}
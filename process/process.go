@@ -0,0 +1,345 @@
+// Copyright 2019 HAProxy Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+// Package process starts, reloads, stops and supervises a local HAProxy process
+package process
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// Mode is the way HAProxy processes are run and reloaded
+type Mode string
+
+const (
+	// ModeMasterWorker runs HAProxy in master-worker mode, reloads are done by the master
+	ModeMasterWorker Mode = "master-worker"
+	// ModeLegacy starts a new process on reload which takes over from the old one with -sf or -st
+	ModeLegacy Mode = "legacy"
+)
+
+// EventType is the type of a process lifecycle event
+type EventType string
+
+const (
+	// EventStarted is sent when HAProxy is started
+	EventStarted EventType = "started"
+	// EventReloaded is sent when a reload is triggered
+	EventReloaded EventType = "reloaded"
+	// EventStopped is sent when HAProxy exits after Stop
+	EventStopped EventType = "stopped"
+	// EventExited is sent when HAProxy exits unexpectedly
+	EventExited EventType = "exited"
+	// EventRestarted is sent when the supervisor restarts HAProxy after an unexpected exit
+	EventRestarted EventType = "restarted"
+)
+
+// Event is a process lifecycle event
+type Event struct {
+	Type EventType
+	PID  int
+	Time time.Time
+	Err  error
+}
+
+// Config of the managed HAProxy process
+type Config struct {
+	// Binary is the path of the HAProxy binary
+	Binary string
+	// ConfigFile is the configuration file, usually the one managed by the configuration client
+	ConfigFile string
+	Mode       Mode
+	// MasterSocket is the master CLI socket in master-worker mode, optional
+	MasterSocket string
+	// PIDFile is written by HAProxy, optional
+	PIDFile string
+	// HardStop makes legacy reloads terminate old processes (-st) instead of letting them
+	// finish their connections (-sf)
+	HardStop bool
+	// Supervise restarts HAProxy after RestartDelay when it exits unexpectedly
+	Supervise    bool
+	RestartDelay time.Duration
+	// OnEvent is called with every lifecycle event, it must not block
+	OnEvent func(Event)
+}
+
+// Process is a HAProxy process managed by this package
+type Process struct {
+	cfg Config
+
+	mu       sync.Mutex
+	cmd      *exec.Cmd
+	stopping bool
+	done     chan struct{}
+	// previous processes replaced by legacy reloads which are still running, the last one
+	// takes over again if the new process exits before them
+	previous []child
+}
+
+type child struct {
+	cmd  *exec.Cmd
+	done chan struct{}
+}
+
+// New returns a manager of the HAProxy process described by cfg, the process is not started
+func New(cfg Config) (*Process, error) {
+	if cfg.Binary == "" {
+		cfg.Binary = "/usr/sbin/haproxy"
+	}
+	if cfg.ConfigFile == "" {
+		return nil, fmt.Errorf("configuration file not specified")
+	}
+	if cfg.Mode == "" {
+		cfg.Mode = ModeMasterWorker
+	}
+	if cfg.Mode != ModeMasterWorker && cfg.Mode != ModeLegacy {
+		return nil, fmt.Errorf("unknown process mode %s", cfg.Mode)
+	}
+	if cfg.RestartDelay == 0 {
+		cfg.RestartDelay = time.Second
+	}
+	return &Process{cfg: cfg}, nil
+}
+
+// Validate checks the configuration file with haproxy -c
+func (p *Process) Validate() error {
+	args := []string{"-c", "-f", p.cfg.ConfigFile}
+	if p.cfg.Mode == ModeMasterWorker {
+		args = append([]string{"-W"}, args...)
+	}
+	var out bytes.Buffer
+	cmd := exec.Command(p.cfg.Binary, args...)
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("invalid configuration: %s", bytes.TrimSpace(out.Bytes()))
+	}
+	return nil
+}
+
+// Start starts HAProxy in the foreground of the manager. Returns error if it is already running.
+func (p *Process) Start() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.cmd != nil {
+		return fmt.Errorf("haproxy already running with pid %d", p.cmd.Process.Pid)
+	}
+	p.stopping = false
+	if err := p.start(nil); err != nil {
+		return err
+	}
+	p.emit(EventStarted, p.cmd.Process.Pid, nil)
+	return nil
+}
+
+// Reload applies the configuration file. In master-worker mode the master is signaled to start
+// new workers, in legacy mode a new process takes over from the running one. The replaced process
+// is tracked until it exits and stays the managed process if the new one exits first.
+func (p *Process) Reload() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.cmd == nil {
+		return fmt.Errorf("haproxy is not running")
+	}
+
+	if p.cfg.Mode == ModeMasterWorker {
+		if err := p.cmd.Process.Signal(syscall.SIGUSR2); err != nil {
+			return err
+		}
+		p.emit(EventReloaded, p.cmd.Process.Pid, nil)
+		return nil
+	}
+
+	flag := "-sf"
+	if p.cfg.HardStop {
+		flag = "-st"
+	}
+	old := child{cmd: p.cmd, done: p.done}
+	if err := p.start([]string{flag, strconv.Itoa(old.cmd.Process.Pid)}); err != nil {
+		return err
+	}
+	p.previous = append(p.previous, old)
+	p.emit(EventReloaded, p.cmd.Process.Pid, nil)
+	return nil
+}
+
+// Stop stops HAProxy, letting it finish current connections when graceful is set. Processes
+// replaced by a legacy reload and still running are stopped too. Processes still running after
+// timeout are killed. A pending supervisor restart is cancelled even if HAProxy is not running.
+func (p *Process) Stop(graceful bool, timeout time.Duration) error {
+	p.mu.Lock()
+	p.stopping = true
+	children := append([]child{}, p.previous...)
+	if p.cmd != nil {
+		children = append(children, child{cmd: p.cmd, done: p.done})
+	}
+	p.mu.Unlock()
+
+	sig := syscall.SIGTERM
+	if graceful {
+		sig = syscall.SIGUSR1
+	}
+	for _, c := range children {
+		// fails only for processes which already exited, others are killed after timeout
+		_ = c.cmd.Process.Signal(sig)
+	}
+
+	deadline := time.After(timeout)
+	for _, c := range children {
+		select {
+		case <-c.done:
+		case <-deadline:
+			for _, c := range children {
+				if !exited(c) {
+					_ = c.cmd.Process.Kill()
+				}
+			}
+			for _, c := range children {
+				<-c.done
+			}
+			return fmt.Errorf("haproxy did not stop in %s and was killed", timeout)
+		}
+	}
+	return nil
+}
+
+// PID returns the pid of the running HAProxy, the master in master-worker mode, 0 if not running
+func (p *Process) PID() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.cmd == nil {
+		return 0
+	}
+	return p.cmd.Process.Pid
+}
+
+// Running returns true if HAProxy is running
+func (p *Process) Running() bool {
+	return p.PID() != 0
+}
+
+// start runs a new HAProxy process with extra arguments and waits for it in the background,
+// the caller holds the lock
+func (p *Process) start(extra []string) error {
+	args := []string{"-db", "-f", p.cfg.ConfigFile}
+	if p.cfg.Mode == ModeMasterWorker {
+		args = append([]string{"-W"}, args...)
+		if p.cfg.MasterSocket != "" {
+			args = append(args, "-S", p.cfg.MasterSocket)
+		}
+	}
+	if p.cfg.PIDFile != "" {
+		args = append(args, "-p", p.cfg.PIDFile)
+	}
+	args = append(args, extra...)
+
+	cmd := exec.Command(p.cfg.Binary, args...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	done := make(chan struct{})
+	p.cmd = cmd
+	p.done = done
+	go p.wait(cmd, done)
+	return nil
+}
+
+// wait reports the exit of the process and restarts it when supervised
+func (p *Process) wait(cmd *exec.Cmd, done chan struct{}) {
+	err := cmd.Wait()
+	close(done)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.cmd != cmd {
+		// replaced by a legacy reload, the old process exiting is expected
+		for i, c := range p.previous {
+			if c.cmd == cmd {
+				p.previous = append(p.previous[:i], p.previous[i+1:]...)
+				break
+			}
+		}
+		if p.stopping && p.cmd == nil && len(p.previous) == 0 {
+			p.emit(EventStopped, cmd.Process.Pid, nil)
+		}
+		return
+	}
+	p.cmd = nil
+	if p.stopping {
+		if len(p.previous) == 0 {
+			p.emit(EventStopped, cmd.Process.Pid, nil)
+		}
+		return
+	}
+	if err == nil {
+		err = fmt.Errorf("haproxy exited")
+	}
+	if n := len(p.previous); n > 0 {
+		// the process started by a legacy reload failed before the one it replaces exited,
+		// which keeps running as the managed process
+		prev := p.previous[n-1]
+		p.previous = p.previous[:n-1]
+		p.cmd = prev.cmd
+		p.done = prev.done
+		p.emit(EventExited, cmd.Process.Pid, fmt.Errorf("reload failed, haproxy %d kept running: %v", prev.cmd.Process.Pid, err))
+		return
+	}
+	p.emit(EventExited, cmd.Process.Pid, err)
+	if !p.cfg.Supervise {
+		return
+	}
+	go p.restart()
+}
+
+func (p *Process) restart() {
+	time.Sleep(p.cfg.RestartDelay)
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.cmd != nil || p.stopping {
+		return
+	}
+	if err := p.start(nil); err != nil {
+		p.emit(EventExited, 0, err)
+		go p.restart()
+		return
+	}
+	p.emit(EventRestarted, p.cmd.Process.Pid, nil)
+}
+
+// exited returns true if the child process has been waited for
+func exited(c child) bool {
+	select {
+	case <-c.done:
+		return true
+	default:
+		return false
+	}
+}
+
+func (p *Process) emit(t EventType, pid int, err error) {
+	if p.cfg.OnEvent == nil {
+		return
+	}
+	p.cfg.OnEvent(Event{Type: t, PID: pid, Time: time.Now(), Err: err})
+}
@@ -0,0 +1,264 @@
+// Copyright 2019 HAProxy Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package process
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// fakeHAProxy takes over from the process given with -sf or -st like HAProxy does, and exits
+// without taking over when the configuration file contains "fail". Running processes append
+// their pid to the configuration file name with a .running suffix.
+const fakeHAProxy = `#!/bin/sh
+cfg=""
+old=""
+while [ $# -gt 0 ]; do
+  case "$1" in
+    -f) cfg="$2"; shift ;;
+    -sf|-st) old="$2"; shift ;;
+  esac
+  shift
+done
+grep -q fail "$cfg" && exit 1
+trap 'exit 0' USR1 TERM
+echo $$ >> "$cfg.running"
+[ -n "$old" ] && kill -USR1 "$old"
+while :; do sleep 0.01; done
+`
+
+type testProcess struct {
+	*Process
+	file   string
+	events chan Event
+}
+
+func newTestProcess(t *testing.T, supervise bool) *testProcess {
+	dir, err := ioutil.TempDir("", "process")
+	if err != nil {
+		t.Fatal(err)
+	}
+	binary := filepath.Join(dir, "haproxy")
+	if err := ioutil.WriteFile(binary, []byte(fakeHAProxy), 0755); err != nil {
+		t.Fatal(err)
+	}
+	cfg := filepath.Join(dir, "haproxy.cfg")
+	if err := ioutil.WriteFile(cfg, []byte("global\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	events := make(chan Event, 16)
+	p, err := New(Config{
+		Binary:       binary,
+		ConfigFile:   cfg,
+		Mode:         ModeLegacy,
+		Supervise:    supervise,
+		RestartDelay: 100 * time.Millisecond,
+		OnEvent:      func(e Event) { events <- e },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tp := &testProcess{Process: p, file: cfg, events: events}
+	t.Cleanup(func() {
+		_ = p.Stop(false, time.Second)
+		os.RemoveAll(dir)
+	})
+	return tp
+}
+
+func (p *testProcess) event(t *testing.T, typ EventType) Event {
+	t.Helper()
+	for {
+		select {
+		case e := <-p.events:
+			if e.Type == typ {
+				return e
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("no %s event", typ)
+		}
+	}
+}
+
+// running waits until the process with pid has checked the configuration and is running
+func (p *testProcess) running(t *testing.T, pid int) {
+	t.Helper()
+	for i := 0; i < 500; i++ {
+		data, _ := ioutil.ReadFile(p.file + ".running")
+		for _, l := range strings.Fields(string(data)) {
+			if l == strconv.Itoa(pid) {
+				return
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("process %d not running", pid)
+}
+
+func (p *testProcess) setConfig(t *testing.T, content string) {
+	if err := ioutil.WriteFile(p.file, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestStartStop(t *testing.T) {
+	p := newTestProcess(t, false)
+	if err := p.Start(); err != nil {
+		t.Fatal(err)
+	}
+	pid := p.event(t, EventStarted).PID
+	p.running(t, pid)
+	if !p.Running() || p.PID() != pid {
+		t.Fatalf("haproxy %d not running", pid)
+	}
+	if err := p.Start(); err == nil {
+		t.Error("haproxy started twice")
+	}
+	if err := p.Stop(true, 5*time.Second); err != nil {
+		t.Fatal(err)
+	}
+	p.event(t, EventStopped)
+	if p.Running() {
+		t.Error("haproxy running after stop")
+	}
+}
+
+func TestStopCancelsRestart(t *testing.T) {
+	p := newTestProcess(t, true)
+	if err := p.Start(); err != nil {
+		t.Fatal(err)
+	}
+	pid := p.event(t, EventStarted).PID
+	p.running(t, pid)
+	if err := syscall.Kill(pid, syscall.SIGKILL); err != nil {
+		t.Fatal(err)
+	}
+	p.event(t, EventExited)
+
+	if err := p.Stop(true, time.Second); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(300 * time.Millisecond)
+	if p.Running() {
+		t.Error("supervisor restarted haproxy after stop")
+	}
+}
+
+func TestLegacyReload(t *testing.T) {
+	p := newTestProcess(t, false)
+	if err := p.Start(); err != nil {
+		t.Fatal(err)
+	}
+	old := p.event(t, EventStarted).PID
+	p.running(t, old)
+	if err := p.Reload(); err != nil {
+		t.Fatal(err)
+	}
+	pid := p.event(t, EventReloaded).PID
+	p.running(t, pid)
+	if pid == old || p.PID() != pid {
+		t.Fatalf("managed pid %d after reload, expected the new process %d", p.PID(), pid)
+	}
+	waitExit(t, old)
+
+	if err := p.Stop(true, 5*time.Second); err != nil {
+		t.Fatal(err)
+	}
+	if e := p.event(t, EventStopped); e.PID != pid {
+		t.Errorf("stopped event for %d, expected %d", e.PID, pid)
+	}
+}
+
+func TestLegacyReloadFailed(t *testing.T) {
+	p := newTestProcess(t, true)
+	if err := p.Start(); err != nil {
+		t.Fatal(err)
+	}
+	old := p.event(t, EventStarted).PID
+	p.running(t, old)
+	p.setConfig(t, "fail\n")
+	if err := p.Reload(); err != nil {
+		t.Fatal(err)
+	}
+	pid := p.event(t, EventReloaded).PID
+	if e := p.event(t, EventExited); e.PID != pid || e.Err == nil {
+		t.Fatalf("exited event %+v, expected the failed process %d", e, pid)
+	}
+	if p.PID() != old {
+		t.Fatalf("managed pid %d after failed reload, expected the previous process %d", p.PID(), old)
+	}
+	if err := syscall.Kill(old, 0); err != nil {
+		t.Fatalf("previous process %d not running: %v", old, err)
+	}
+
+	time.Sleep(300 * time.Millisecond)
+	select {
+	case e := <-p.events:
+		t.Fatalf("unexpected %s event, the previous process is still running", e.Type)
+	default:
+	}
+
+	if err := p.Stop(true, 5*time.Second); err != nil {
+		t.Fatal(err)
+	}
+	if e := p.event(t, EventStopped); e.PID != old {
+		t.Errorf("stopped event for %d, expected %d", e.PID, old)
+	}
+}
+
+func TestStopDuringLegacyReload(t *testing.T) {
+	p := newTestProcess(t, false)
+	p.Process.cfg.HardStop = true
+	if err := p.Start(); err != nil {
+		t.Fatal(err)
+	}
+	old := p.event(t, EventStarted).PID
+	p.running(t, old)
+	if err := p.Reload(); err != nil {
+		t.Fatal(err)
+	}
+	pid := p.event(t, EventReloaded).PID
+	p.running(t, pid)
+	if err := p.Stop(false, 5*time.Second); err != nil {
+		t.Fatal(err)
+	}
+	if p.Running() {
+		t.Error("haproxy running after stop")
+	}
+	for _, id := range []int{old, pid} {
+		if err := syscall.Kill(id, 0); err == nil {
+			t.Errorf("process %d running after stop", id)
+		}
+	}
+}
+
+// waitExit waits until the process with pid has exited
+func waitExit(t *testing.T, pid int) {
+	t.Helper()
+	for i := 0; i < 500; i++ {
+		if err := syscall.Kill(pid, 0); err != nil {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("process %d still running", pid)
+}
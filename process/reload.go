@@ -0,0 +1,103 @@
+// Copyright 2019 HAProxy Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package process
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os/exec"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// Reload strategies below can be set as the reloader of the configuration client, so HAProxy is
+// reloaded after every successful commit.
+
+// SystemdReloader reloads HAProxy with systemctl reload
+type SystemdReloader struct {
+	// Unit is the systemd unit, haproxy.service if empty
+	Unit string
+}
+
+// Reload runs systemctl reload on the unit
+func (r *SystemdReloader) Reload() (string, error) {
+	unit := r.Unit
+	if unit == "" {
+		unit = "haproxy.service"
+	}
+	return runCommand("systemctl", "reload", unit)
+}
+
+// MasterSignalReloader reloads HAProxy running in master-worker mode by sending SIGUSR2 to the
+// master process
+type MasterSignalReloader struct {
+	// PIDFile holds the pid of the master process on its first line
+	PIDFile string
+}
+
+// Reload signals the master process to load the configuration
+func (r *MasterSignalReloader) Reload() (string, error) {
+	data, err := ioutil.ReadFile(r.PIDFile)
+	if err != nil {
+		return "", err
+	}
+	line := strings.TrimSpace(strings.SplitN(string(data), "\n", 2)[0])
+	pid, err := strconv.Atoi(line)
+	if err != nil || pid <= 0 {
+		return "", fmt.Errorf("invalid master pid %s in %s", line, r.PIDFile)
+	}
+	if err := syscall.Kill(pid, syscall.SIGUSR2); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("sent SIGUSR2 to master %d", pid), nil
+}
+
+// CommandReloader reloads HAProxy with a custom command
+type CommandReloader struct {
+	Command string
+	Args    []string
+}
+
+// Reload runs the command, a non zero exit status is an error
+func (r *CommandReloader) Reload() (string, error) {
+	return runCommand(r.Command, r.Args...)
+}
+
+// ProcessReloader reloads a HAProxy process managed by this package
+type ProcessReloader struct {
+	Process *Process
+}
+
+// Reload reloads the managed process
+func (r *ProcessReloader) Reload() (string, error) {
+	if err := r.Process.Reload(); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("reloaded haproxy %d", r.Process.PID()), nil
+}
+
+func runCommand(name string, args ...string) (string, error) {
+	out, err := exec.Command(name, args...).CombinedOutput()
+	output := strings.TrimSpace(string(out))
+	if err != nil {
+		if output == "" {
+			return "", err
+		}
+		return output, fmt.Errorf("%s: %s", err.Error(), output)
+	}
+	return output, nil
+}
@@ -0,0 +1,139 @@
+// Copyright 2019 HAProxy Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package client_native
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/haproxytech/client-native/v2/configuration"
+	"github.com/haproxytech/client-native/v2/runtime"
+)
+
+// ReloadPollInterval is the interval used to poll the master socket while waiting for a reload
+var ReloadPollInterval = 500 * time.Millisecond
+
+// ApplyAndReload commits the transaction and reloads HAProxy running in master-worker mode, unless
+// the configuration client already reloaded it with its own reloader. Then it waits up to timeout
+// until show proc on the master socket lists only new workers. If no new workers come up the
+// previous configuration is restored and HAProxy is reloaded again. Old workers still finishing
+// connections at the timeout are reported as an error without rolling back.
+func (c *HAProxyClient) ApplyAndReload(transactionID string, reloader configuration.Reloader, masterSocket string, timeout time.Duration) error {
+	before, err := runtime.ShowProc(masterSocket)
+	if err != nil {
+		return err
+	}
+	oldWorkers := map[int64]bool{}
+	for _, w := range before.Workers(false) {
+		oldWorkers[w.PID] = true
+	}
+
+	_, backup, err := c.Configuration.GetRawConfiguration("", 0)
+	if err != nil {
+		return err
+	}
+
+	start := time.Now()
+	_, err = c.Configuration.CommitTransaction(transactionID)
+	if err != nil && !isReloadError(err) {
+		return err
+	}
+	if err == nil {
+		err = c.reloadAfter(reloader, start)
+	}
+	if err == nil {
+		err = waitWorkers(masterSocket, oldWorkers, start.Add(timeout))
+	}
+	if err != nil {
+		if rbErr := c.rollbackReload(backup, reloader); rbErr != nil {
+			return fmt.Errorf("%s, rollback failed: %s", err.Error(), rbErr.Error())
+		}
+		return fmt.Errorf("%s, previous configuration restored", err.Error())
+	}
+
+	return waitOldWorkers(masterSocket, oldWorkers, start.Add(timeout))
+}
+
+// reloadAfter runs the reloader unless the configuration client reloaded HAProxy since start
+func (c *HAProxyClient) reloadAfter(reloader configuration.Reloader, start time.Time) error {
+	if r := c.Configuration.LastReload(); r != nil && !r.Time.Before(start) {
+		return r.Err
+	}
+	if reloader == nil {
+		return fmt.Errorf("no reloader configured")
+	}
+	_, err := reloader.Reload()
+	return err
+}
+
+func (c *HAProxyClient) rollbackReload(backup string, reloader configuration.Reloader) error {
+	start := time.Now()
+	if err := restoreConfiguration(c, backup); err != nil && !isReloadError(err) {
+		return err
+	}
+	return c.reloadAfter(reloader, start)
+}
+
+// waitWorkers waits until all current workers were started after the reload
+func waitWorkers(masterSocket string, oldWorkers map[int64]bool, deadline time.Time) error {
+	for {
+		procs, err := runtime.ShowProc(masterSocket)
+		if err == nil {
+			workers := procs.Workers(false)
+			started := len(workers) > 0
+			for _, w := range workers {
+				if oldWorkers[w.PID] {
+					started = false
+				}
+			}
+			if started {
+				return nil
+			}
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("new workers did not start")
+		}
+		time.Sleep(ReloadPollInterval)
+	}
+}
+
+// waitOldWorkers waits until the workers running before the reload exit
+func waitOldWorkers(masterSocket string, oldWorkers map[int64]bool, deadline time.Time) error {
+	for {
+		procs, err := runtime.ShowProc(masterSocket)
+		if err != nil {
+			return err
+		}
+		running := 0
+		for _, w := range procs.Workers(true) {
+			if oldWorkers[w.PID] {
+				running++
+			}
+		}
+		if running == 0 {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("configuration applied but %d old workers are still running", running)
+		}
+		time.Sleep(ReloadPollInterval)
+	}
+}
+
+func isReloadError(err error) bool {
+	confErr, ok := err.(*configuration.ConfError)
+	return ok && confErr.Code() == configuration.ErrReloadFailed
+}
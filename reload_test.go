@@ -0,0 +1,169 @@
+// Copyright 2019 HAProxy Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package client_native
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/haproxytech/models/v2"
+)
+
+// fakeMaster answers show proc on a unix socket with the workers it is given
+type fakeMaster struct {
+	mu      sync.Mutex
+	workers []int64
+	old     []int64
+}
+
+func (m *fakeMaster) set(workers []int64, old []int64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.workers, m.old = workers, old
+}
+
+func (m *fakeMaster) showProc() string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var sb strings.Builder
+	sb.WriteString("#<PID>          <type>          <reloads>       <uptime>        <version>\n")
+	sb.WriteString("1               master          0 [failed: 0]   0d00h00m01s     2.5.0\n# workers\n")
+	for _, w := range m.workers {
+		sb.WriteString(fmt.Sprintf("%d             worker          0               0d00h00m01s     2.5.0\n", w))
+	}
+	sb.WriteString("# old workers\n")
+	for _, w := range m.old {
+		sb.WriteString(fmt.Sprintf("%d             worker          1               0d00h00m01s     2.5.0\n", w))
+	}
+	return sb.String()
+}
+
+func startFakeMaster(t *testing.T, m *fakeMaster) string {
+	socket := filepath.Join(testDir(t), "master.sock")
+	l, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { l.Close() })
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			if _, err := bufio.NewReader(conn).ReadString('\n'); err == nil {
+				conn.Write([]byte(m.showProc()))
+			}
+			conn.Close()
+		}
+	}()
+	return socket
+}
+
+// testReloader runs reload on every call
+type testReloader struct {
+	reloads int
+	reload  func(n int)
+}
+
+func (r *testReloader) Reload() (string, error) {
+	r.reloads++
+	if r.reload != nil {
+		r.reload(r.reloads)
+	}
+	return "", nil
+}
+
+func startTestTransaction(t *testing.T, c *HAProxyClient) string {
+	id := testTransaction(t, c)
+	if err := c.Configuration.CreateBackend(&models.Backend{Name: "reloaded"}, id, 0); err != nil {
+		t.Fatal(err)
+	}
+	return id
+}
+
+func TestApplyAndReload(t *testing.T) {
+	ReloadPollInterval = 10 * time.Millisecond
+	tests := []struct {
+		name     string
+		reload   func(m *fakeMaster, n int)
+		err      string
+		reloads  int
+		reverted bool
+	}{
+		{
+			name:    "new workers started",
+			reload:  func(m *fakeMaster, n int) { m.set([]int64{200}, nil) },
+			reloads: 1,
+		},
+		{
+			name:     "new workers not started",
+			reload:   func(m *fakeMaster, n int) {},
+			err:      "new workers did not start, previous configuration restored",
+			reloads:  2,
+			reverted: true,
+		},
+		{
+			name:    "old workers still running",
+			reload:  func(m *fakeMaster, n int) { m.set([]int64{200}, []int64{100}) },
+			err:     "configuration applied but 1 old workers are still running",
+			reloads: 1,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := newTestClient(t)
+			m := &fakeMaster{workers: []int64{100}}
+			socket := startFakeMaster(t, m)
+			r := &testReloader{reload: func(n int) { test.reload(m, n) }}
+
+			err := c.ApplyAndReload(startTestTransaction(t, c), r, socket, 200*time.Millisecond)
+			if test.err == "" && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if test.err != "" && (err == nil || err.Error() != test.err) {
+				t.Errorf("error %v returned, expected %s", err, test.err)
+			}
+			if r.reloads != test.reloads {
+				t.Errorf("reloaded %d times, expected %d", r.reloads, test.reloads)
+			}
+			_, _, err = c.Configuration.GetBackend("reloaded", "")
+			if test.reverted && err == nil {
+				t.Error("configuration not restored")
+			}
+			if !test.reverted && err != nil {
+				t.Errorf("configuration not applied: %v", err)
+			}
+		})
+	}
+}
+
+func TestApplyAndReloadWithoutMaster(t *testing.T) {
+	c := newTestClient(t)
+	tid := startTestTransaction(t, c)
+	if err := c.ApplyAndReload(tid, &testReloader{}, filepath.Join(testDir(t), "missing.sock"), time.Second); err == nil {
+		t.Error("applied without master socket")
+	}
+	if _, _, err := c.Configuration.GetBackend("reloaded", ""); err == nil {
+		t.Error("transaction committed without master socket")
+	}
+}
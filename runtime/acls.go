@@ -0,0 +1,123 @@
+// Copyright 2019 HAProxy Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package runtime
+
+import (
+	"fmt"
+	"strings"
+
+	native_errors "github.com/haproxytech/client-native/v2/errors"
+)
+
+// ACLFile is an ACL pattern file or ACL expression loaded in runtime
+type ACLFile struct {
+	ID          string `json:"id,omitempty"`
+	File        string `json:"file,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+// ACLFiles is an array of ACLFile
+type ACLFiles []*ACLFile
+
+// ACLFileEntry is one pattern of an ACL file in runtime
+type ACLFileEntry struct {
+	ID    string `json:"id,omitempty"`
+	Value string `json:"value,omitempty"`
+}
+
+// ACLFileEntries is an array of ACLFileEntry
+type ACLFileEntries []*ACLFileEntry
+
+// ShowACLs returns ACL files description from runtime
+func (s *SingleRuntime) ShowACLs() (ACLFiles, error) {
+	response, err := s.ExecuteWithResponse("show acl")
+	if err != nil {
+		return nil, fmt.Errorf("%s %w", err.Error(), native_errors.ErrNotFound)
+	}
+	return parseACLFiles(response), nil
+}
+
+// GetACLFile returns one ACL file loaded in runtime
+func (s *SingleRuntime) GetACLFile(file string) (*ACLFile, error) {
+	acls, err := s.ShowACLs()
+	if err != nil {
+		return nil, err
+	}
+	for _, a := range acls {
+		if a.File == file || "#"+a.ID == file {
+			return a, nil
+		}
+	}
+	return nil, fmt.Errorf("%s %w", file, native_errors.ErrNotFound)
+}
+
+// ShowACLFileEntries returns runtime entries of one ACL file
+func (s *SingleRuntime) ShowACLFileEntries(file string) (ACLFileEntries, error) {
+	response, err := s.ExecuteWithResponse(fmt.Sprintf("show acl %s", file))
+	if err != nil {
+		return nil, fmt.Errorf("%s %w", err.Error(), native_errors.ErrNotFound)
+	}
+	entries := ACLFileEntries{}
+	for _, line := range strings.Split(strings.TrimSpace(response), "\n") {
+		parts := strings.Fields(line)
+		if len(parts) < 2 {
+			continue
+		}
+		entries = append(entries, &ACLFileEntry{ID: parts[0], Value: parts[1]})
+	}
+	return entries, nil
+}
+
+// AddACLFileEntry adds a pattern into the ACL file in runtime
+func (s *SingleRuntime) AddACLFileEntry(file, value string) error {
+	err := s.Execute(fmt.Sprintf("add acl %s %s", file, value))
+	if err != nil {
+		return fmt.Errorf("%s %w", err.Error(), native_errors.ErrGeneral)
+	}
+	return nil
+}
+
+// DeleteACLFileEntry deletes a pattern from the ACL file in runtime
+func (s *SingleRuntime) DeleteACLFileEntry(file, value string) error {
+	err := s.Execute(fmt.Sprintf("del acl %s %s", file, value))
+	if err != nil {
+		return fmt.Errorf("%s %w", err.Error(), native_errors.ErrNotFound)
+	}
+	return nil
+}
+
+// parseACLFiles parses output from `show acl` command, same format as `show map`
+// Sample output format:
+// # id (file) description
+// 0 (/etc/haproxy/blocklist.acl) pattern loaded from file '/etc/haproxy/blocklist.acl' used by acl at file '/etc/haproxy/haproxy.cfg' line 26
+func parseACLFiles(output string) ACLFiles {
+	acls := ACLFiles{}
+	for _, line := range strings.Split(strings.TrimSpace(output), "\n") {
+		if line == "" || strings.HasPrefix(strings.TrimSpace(line), "#") {
+			continue
+		}
+		parts := strings.Fields(line)
+		if len(parts) < 3 {
+			continue
+		}
+		acls = append(acls, &ACLFile{
+			ID:          parts[0],
+			File:        strings.TrimSuffix(strings.TrimPrefix(parts[1], "("), ")"),
+			Description: strings.Join(parts[2:], " "),
+		})
+	}
+	return acls
+}
@@ -0,0 +1,98 @@
+// Copyright 2019 HAProxy Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package runtime
+
+import (
+	"fmt"
+	"strings"
+
+	native_errors "github.com/haproxytech/client-native/v2/errors"
+)
+
+// ShowSSLCerts returns file names of certificates loaded in runtime
+func (s *SingleRuntime) ShowSSLCerts() ([]string, error) {
+	response, err := s.ExecuteWithResponse("show ssl cert")
+	if err != nil {
+		return nil, fmt.Errorf("%s %w", err.Error(), native_errors.ErrNotFound)
+	}
+	certs := []string{}
+	for _, line := range strings.Split(strings.TrimSpace(response), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		// certificates in an uncommitted transaction are prefixed with *
+		certs = append(certs, strings.TrimPrefix(line, "*"))
+	}
+	return certs, nil
+}
+
+// SetSSLCert starts a transaction updating the certificate with the PEM payload
+func (s *SingleRuntime) SetSSLCert(file, payload string) error {
+	if s.worker > 0 {
+		return fmt.Errorf("commands with payload are not supported through the master socket %w", native_errors.ErrGeneral)
+	}
+	err := s.Execute(fmt.Sprintf("set ssl cert %s <<\n%s\n", file, payloadLines(payload)))
+	if err != nil {
+		return fmt.Errorf("%s %w", err.Error(), native_errors.ErrGeneral)
+	}
+	return nil
+}
+
+// CommitSSLCert commits the certificate transaction started with SetSSLCert
+func (s *SingleRuntime) CommitSSLCert(file string) error {
+	response, err := s.ExecuteWithResponse(fmt.Sprintf("commit ssl cert %s", file))
+	if err != nil {
+		return fmt.Errorf("%s %w", err.Error(), native_errors.ErrGeneral)
+	}
+	if !strings.Contains(response, "Success!") {
+		return fmt.Errorf("%s %w", strings.TrimSpace(response), native_errors.ErrGeneral)
+	}
+	return nil
+}
+
+// AbortSSLCert aborts the certificate transaction started with SetSSLCert
+func (s *SingleRuntime) AbortSSLCert(file string) error {
+	err := s.Execute(fmt.Sprintf("abort ssl cert %s", file))
+	if err != nil {
+		return fmt.Errorf("%s %w", err.Error(), native_errors.ErrGeneral)
+	}
+	return nil
+}
+
+// SetOCSPResponse updates the OCSP response stapled for the certificate it matches, the response
+// is base64 encoded DER
+func (s *SingleRuntime) SetOCSPResponse(response string) error {
+	result, err := s.ExecuteWithResponse(fmt.Sprintf("set ssl ocsp-response %s", response))
+	if err != nil {
+		return fmt.Errorf("%s %w", err.Error(), native_errors.ErrGeneral)
+	}
+	if !strings.Contains(result, "OCSP Response updated") {
+		return fmt.Errorf("%s %w", strings.TrimSpace(result), native_errors.ErrGeneral)
+	}
+	return nil
+}
+
+// payloadLines removes empty lines from payload, since an empty line ends the payload
+func payloadLines(payload string) string {
+	lines := []string{}
+	for _, l := range strings.Split(payload, "\n") {
+		if strings.TrimSpace(l) != "" {
+			lines = append(lines, strings.TrimRight(l, "\r"))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
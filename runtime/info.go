@@ -40,7 +40,11 @@ func parseInfo(info string, socketPath string) *models.ProcessInfoItem {
 	data := &models.ProcessInfoItem{}
 
 	for _, line := range strings.Split(info, "\n") {
-		fields := strings.Split(line, ":")
+		// id.name.process:origin,nature,scope:type:value, the value may contain ':'
+		fields := strings.SplitN(line, ":", 4)
+		if len(fields) < 4 {
+			continue
+		}
 		fID := strings.TrimSpace(strings.Split(fields[0], ".")[0])
 		switch fID {
 		case "1":
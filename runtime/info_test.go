@@ -0,0 +1,80 @@
+// Copyright 2019 HAProxy Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package runtime
+
+import (
+	"testing"
+
+	"github.com/haproxytech/models/v2"
+
+	"github.com/haproxytech/client-native/v2/misc"
+)
+
+func TestParseInfo(t *testing.T) {
+	tests := []struct {
+		name     string
+		info     string
+		expected *models.ProcessInfoItem
+	}{
+		{
+			name: "typed output",
+			info: "1.Version.1:POS:str:2.2.4\n3.Nbthread.1:CGS:u32:4\n6.Pid.1:SGP:u32:1234\n8.Uptime_sec.1:MDP:u32:3600\n",
+			expected: &models.ProcessInfoItem{
+				Version:  "2.2.4",
+				Nbthread: misc.Int64P(4),
+				Pid:      misc.Int64P(1234),
+				Uptime:   misc.Int64P(3600),
+			},
+		},
+		{
+			name:     "values containing colons",
+			info:     "1.Version.1:POS:str:2.2.4-1:dev\n50.node.1:CSS:str:lb1:eu-west:1\n",
+			expected: &models.ProcessInfoItem{Version: "2.2.4-1:dev", Node: "lb1:eu-west:1"},
+		},
+		{
+			name:     "truncated lines",
+			info:     "1.Version.1:POS\n6.Pid.1\n3.Nbthread.1:CGS:u32\n8\n:::\n\n",
+			expected: &models.ProcessInfoItem{},
+		},
+		{
+			name:     "invalid numbers",
+			info:     "3.Nbthread.1:CGS:u32:\n6.Pid.1:SGP:u32:abc\n",
+			expected: &models.ProcessInfoItem{},
+		},
+		{
+			name:     "empty output",
+			info:     "",
+			expected: &models.ProcessInfoItem{},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			info := parseInfo(test.info, "/var/run/haproxy.sock")
+			if info.Version != test.expected.Version || info.Node != test.expected.Node {
+				t.Errorf("version %q and node %q parsed, expected %q and %q", info.Version, info.Node, test.expected.Version, test.expected.Node)
+			}
+			for name, v := range map[string][2]*int64{
+				"nbthread": {info.Nbthread, test.expected.Nbthread},
+				"pid":      {info.Pid, test.expected.Pid},
+				"uptime":   {info.Uptime, test.expected.Uptime},
+			} {
+				if (v[0] == nil) != (v[1] == nil) || v[0] != nil && *v[0] != *v[1] {
+					t.Errorf("%s parsed as %v, expected %v", name, v[0], v[1])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,209 @@
+// Copyright 2019 HAProxy Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package runtime
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	native_errors "github.com/haproxytech/client-native/v2/errors"
+)
+
+// PeerTableStatus is the synchronization state of a stick table shared with a peer
+type PeerTableStatus struct {
+	Name           string `json:"name"`
+	LocalID        int64  `json:"local_id"`
+	RemoteID       int64  `json:"remote_id"`
+	LastAcked      int64  `json:"last_acked"`
+	LastPushed     int64  `json:"last_pushed"`
+	LastGet        int64  `json:"last_get"`
+	TeachingOrigin int64  `json:"teaching_origin"`
+	Update         int64  `json:"update"`
+	LocalUpdate    int64  `json:"local_update"`
+	CommitUpdate   int64  `json:"commit_update"`
+	Syncing        bool   `json:"syncing"`
+}
+
+// PeerStatus is the connection state of a peer
+type PeerStatus struct {
+	Name    string `json:"name"`
+	Local   bool   `json:"local"`
+	Active  bool   `json:"active"`
+	Address string `json:"address,omitempty"`
+	// Status is the state of the connection, or of the last connection attempt
+	Status        string `json:"status,omitempty"`
+	LastHandshake string `json:"last_handshake,omitempty"`
+	Reconnect     string `json:"reconnect,omitempty"`
+	Heartbeat     string `json:"heartbeat,omitempty"`
+	Confirm       int64  `json:"confirm"`
+	NewConn       int64  `json:"new_conn"`
+	ProtoErr      int64  `json:"proto_err"`
+	// Fields are all key=value fields of the peer, including those not mapped above
+	Fields map[string]string  `json:"fields,omitempty"`
+	Tables []*PeerTableStatus `json:"tables"`
+}
+
+// PeersStatus is the replication state of a peers section
+type PeersStatus struct {
+	RuntimeAPI    string        `json:"runtimeAPI,omitempty"`
+	Name          string        `json:"name"`
+	Disabled      bool          `json:"disabled"`
+	ResyncTimeout string        `json:"resync_timeout,omitempty"`
+	Peers         []*PeerStatus `json:"peers"`
+}
+
+// GetPeersStatus returns the replication state of all peers sections from show peers
+func (s *SingleRuntime) GetPeersStatus() ([]*PeersStatus, error) {
+	response, err := s.ExecuteWithResponse("show peers")
+	if err != nil {
+		return nil, fmt.Errorf("%s %w", err.Error(), native_errors.ErrGeneral)
+	}
+	if strings.HasPrefix(strings.TrimSpace(response), "Unknown command") {
+		return nil, fmt.Errorf("show peers is not supported %w", native_errors.ErrGeneral)
+	}
+	sections := parsePeersStatus(response)
+	for _, section := range sections {
+		section.RuntimeAPI = s.socketPath
+	}
+	return sections, nil
+}
+
+func parsePeersStatus(response string) []*PeersStatus {
+	result := []*PeersStatus{}
+	var section *PeersStatus
+	var peer *PeerStatus
+	var table *PeerTableStatus
+	for _, line := range strings.Split(response, "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		indent := len(line) - len(strings.TrimLeft(line, " \t"))
+		fields := peerFields(line)
+
+		switch {
+		case indent == 0 && fields["id"] != "":
+			// 0x55deb0224320: [15/Apr/2019:11:28:01] id=mypeers disabled=0 flags=0x2 resync_timeout=<PAST>
+			section = &PeersStatus{
+				Name:          fields["id"],
+				Disabled:      fields["disabled"] != "" && fields["disabled"] != "0",
+				ResyncTimeout: fields["resync_timeout"],
+				Peers:         []*PeerStatus{},
+			}
+			result = append(result, section)
+			peer = nil
+			table = nil
+		case section == nil:
+			continue
+		case strings.HasPrefix(strings.TrimSpace(line), "0x") && fields["id"] != "" && fields["addr"] != "":
+			// 0x55deb022b540: id=hostB(remote,active) addr=127.0.0.12:10002 last_status=CONN ...
+			peer = newPeerStatus(fields)
+			section.Peers = append(section.Peers, peer)
+			table = nil
+		case peer == nil:
+			continue
+		case fields["local_id"] != "":
+			// 0x55deb0239a00 local_id=1 remote_id=1 flags=0x0 remote_data=0x65
+			table = &PeerTableStatus{}
+			peer.Tables = append(peer.Tables, table)
+			setPeerTableFields(table, fields)
+		case table != nil && strings.HasPrefix(strings.TrimSpace(line), "table:"):
+			// table:0x55deb022d6a0 id=t1 update=3 localupdate=3 commitupdate=3 syncing=0
+			table.Name = fields["id"]
+			setPeerTableFields(table, fields)
+		case table != nil:
+			setPeerTableFields(table, fields)
+		default:
+			setPeerFields(peer, fields)
+		}
+	}
+	return result
+}
+
+func newPeerStatus(fields map[string]string) *PeerStatus {
+	peer := &PeerStatus{
+		Name:   fields["id"],
+		Fields: map[string]string{},
+		Tables: []*PeerTableStatus{},
+	}
+	// id=hostB(remote,active)
+	if i := strings.Index(peer.Name, "("); i > 0 && strings.HasSuffix(peer.Name, ")") {
+		for _, flag := range strings.Split(peer.Name[i+1:len(peer.Name)-1], ",") {
+			switch flag {
+			case "local":
+				peer.Local = true
+			case "active":
+				peer.Active = true
+			}
+		}
+		peer.Name = peer.Name[:i]
+	}
+	setPeerFields(peer, fields)
+	return peer
+}
+
+// setPeerFields adds the fields of a line of the peer, the peer fields span several lines
+func setPeerFields(peer *PeerStatus, fields map[string]string) {
+	for k, v := range fields {
+		peer.Fields[k] = v
+	}
+	f := peer.Fields
+	peer.Address = f["addr"]
+	peer.Status = f["status"]
+	if peer.Status == "" {
+		peer.Status = f["last_status"]
+	}
+	peer.LastHandshake = f["last_hdshk"]
+	peer.Reconnect = f["reconnect"]
+	peer.Heartbeat = f["heartbeat"]
+	peer.Confirm, _ = strconv.ParseInt(f["confirm"], 10, 64)
+	peer.NewConn, _ = strconv.ParseInt(f["new_conn"], 10, 64)
+	peer.ProtoErr, _ = strconv.ParseInt(f["proto_err"], 10, 64)
+}
+
+func setPeerTableFields(table *PeerTableStatus, fields map[string]string) {
+	counters := map[string]*int64{
+		"local_id":        &table.LocalID,
+		"remote_id":       &table.RemoteID,
+		"last_acked":      &table.LastAcked,
+		"last_pushed":     &table.LastPushed,
+		"last_get":        &table.LastGet,
+		"teaching_origin": &table.TeachingOrigin,
+		"update":          &table.Update,
+		"localupdate":     &table.LocalUpdate,
+		"commitupdate":    &table.CommitUpdate,
+	}
+	for k, v := range fields {
+		if counter, ok := counters[k]; ok {
+			*counter, _ = strconv.ParseInt(v, 10, 64)
+		}
+	}
+	if syncing, ok := fields["syncing"]; ok {
+		table.Syncing = syncing != "0"
+	}
+}
+
+// peerFields returns the key=value words of the line
+func peerFields(line string) map[string]string {
+	fields := map[string]string{}
+	for _, word := range strings.Fields(line) {
+		parts := strings.SplitN(word, "=", 2)
+		if len(parts) == 2 {
+			fields[parts[0]] = parts[1]
+		}
+	}
+	return fields
+}
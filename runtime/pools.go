@@ -0,0 +1,147 @@
+// Copyright 2019 HAProxy Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package runtime
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	native_errors "github.com/haproxytech/client-native/v2/errors"
+)
+
+// Pool is the memory usage of a HAProxy memory pool
+type Pool struct {
+	Name           string `json:"name"`
+	Size           int64  `json:"size"`
+	Allocated      int64  `json:"allocated"`
+	AllocatedBytes int64  `json:"allocated_bytes"`
+	Used           int64  `json:"used"`
+	NeededAvg      *int64 `json:"needed_avg,omitempty"`
+	Failures       int64  `json:"failures"`
+	Users          int64  `json:"users"`
+	Shared         bool   `json:"shared"`
+}
+
+// Pools is the memory pools usage of a HAProxy process
+type Pools struct {
+	RuntimeAPI     string  `json:"runtimeAPI,omitempty"`
+	Pools          []*Pool `json:"pools"`
+	AllocatedBytes int64   `json:"allocated_bytes"`
+	UsedBytes      int64   `json:"used_bytes"`
+}
+
+// DevInfo is the build and platform information of a HAProxy process
+type DevInfo struct {
+	RuntimeAPI string `json:"runtimeAPI,omitempty"`
+	// Features are the enabled (+) and disabled (-) build features
+	Features []string `json:"features,omitempty"`
+	// Sections are the key: value entries of the other parts of the output, by part title
+	Sections map[string]map[string]string `json:"sections,omitempty"`
+}
+
+var (
+	poolLine  = regexp.MustCompile(`^-\s*Pool\s+(\S+)\s+\((\d+)\s+bytes\)\s*:\s*(\d+)\s+allocated\s+\((\d+)\s+bytes\),\s*(\d+)\s+used(?:,\s*needed_avg\s+(\d+))?,\s*(\d+)\s+failures,\s*(\d+)\s+users`)
+	poolTotal = regexp.MustCompile(`^Total:\s*\d+\s+pools,\s*(\d+)\s+bytes\s+allocated,\s*(\d+)\s+used`)
+)
+
+// GetPools returns the memory pools usage from show pools
+func (s *SingleRuntime) GetPools() (*Pools, error) {
+	response, err := s.ExecuteWithResponse("show pools")
+	if err != nil {
+		return nil, fmt.Errorf("%s %w", err.Error(), native_errors.ErrGeneral)
+	}
+	pools := parsePools(response)
+	pools.RuntimeAPI = s.socketPath
+	return pools, nil
+}
+
+// GetDevInfo returns the build and platform information from show dev
+func (s *SingleRuntime) GetDevInfo() (*DevInfo, error) {
+	response, err := s.ExecuteWithResponse("show dev")
+	if err != nil {
+		return nil, fmt.Errorf("%s %w", err.Error(), native_errors.ErrGeneral)
+	}
+	if strings.HasPrefix(strings.TrimSpace(response), "Unknown command") {
+		return nil, fmt.Errorf("show dev is not supported %w", native_errors.ErrGeneral)
+	}
+	info := parseDevInfo(response)
+	info.RuntimeAPI = s.socketPath
+	return info, nil
+}
+
+func parsePools(response string) *Pools {
+	pools := &Pools{Pools: []*Pool{}}
+	for _, line := range strings.Split(response, "\n") {
+		line = strings.TrimSpace(line)
+		if m := poolTotal.FindStringSubmatch(line); m != nil {
+			pools.AllocatedBytes, _ = strconv.ParseInt(m[1], 10, 64)
+			pools.UsedBytes, _ = strconv.ParseInt(m[2], 10, 64)
+			continue
+		}
+		m := poolLine.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		p := &Pool{
+			Name:   m[1],
+			Shared: strings.Contains(line, "[SHARED]"),
+		}
+		p.Size, _ = strconv.ParseInt(m[2], 10, 64)
+		p.Allocated, _ = strconv.ParseInt(m[3], 10, 64)
+		p.AllocatedBytes, _ = strconv.ParseInt(m[4], 10, 64)
+		p.Used, _ = strconv.ParseInt(m[5], 10, 64)
+		if m[6] != "" {
+			if n, err := strconv.ParseInt(m[6], 10, 64); err == nil {
+				p.NeededAvg = &n
+			}
+		}
+		p.Failures, _ = strconv.ParseInt(m[7], 10, 64)
+		p.Users, _ = strconv.ParseInt(m[8], 10, 64)
+		pools.Pools = append(pools.Pools, p)
+	}
+	return pools
+}
+
+func parseDevInfo(response string) *DevInfo {
+	info := &DevInfo{Features: []string{}, Sections: map[string]map[string]string{}}
+	section := ""
+	for _, line := range strings.Split(response, "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		// part titles start at the beginning of the line, entries are indented
+		if !strings.HasPrefix(line, " ") && !strings.HasPrefix(line, "\t") {
+			section = strings.TrimSpace(line)
+			continue
+		}
+		line = strings.TrimSpace(line)
+		if section == "Features" {
+			info.Features = append(info.Features, strings.Fields(line)...)
+			continue
+		}
+		parts := strings.SplitN(line, ":", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		if _, ok := info.Sections[section]; !ok {
+			info.Sections[section] = map[string]string{}
+		}
+		info.Sections[section][strings.TrimSpace(parts[0])] = strings.TrimSpace(parts[1])
+	}
+	return info
+}
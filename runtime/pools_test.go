@@ -0,0 +1,102 @@
+// Copyright 2019 HAProxy Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package runtime
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/haproxytech/client-native/v2/misc"
+)
+
+func TestParsePools(t *testing.T) {
+	tests := []struct {
+		name     string
+		response string
+		expected *Pools
+	}{
+		{
+			name: "pools with needed_avg",
+			response: `Dumping pools usage. Use SIGQUIT to flush them.
+  - Pool comp_state (32 bytes) : 5 allocated (160 bytes), 3 used, needed_avg 4, 0 failures, 2 users, @0x55f1 [SHARED]
+  - Pool buffer (16384 bytes) : 10 allocated (163840 bytes), 8 used, needed_avg 9, 1 failures, 1 users, @0x55f2
+Total: 2 pools, 164000 bytes allocated, 131168 used.
+`,
+			expected: &Pools{
+				Pools: []*Pool{
+					{Name: "comp_state", Size: 32, Allocated: 5, AllocatedBytes: 160, Used: 3, NeededAvg: misc.Int64P(4), Users: 2, Shared: true},
+					{Name: "buffer", Size: 16384, Allocated: 10, AllocatedBytes: 163840, Used: 8, NeededAvg: misc.Int64P(9), Failures: 1, Users: 1},
+				},
+				AllocatedBytes: 164000,
+				UsedBytes:      131168,
+			},
+		},
+		{
+			name: "pools without needed_avg",
+			response: `  - Pool pipe (32 bytes) : 0 allocated (0 bytes), 0 used, 0 failures, 1 users, @0x1 [SHARED]
+Total: 1 pools, 0 bytes allocated, 0 used.
+`,
+			expected: &Pools{Pools: []*Pool{{Name: "pipe", Size: 32, Users: 1, Shared: true}}},
+		},
+		{
+			name:     "truncated lines",
+			response: "  - Pool pipe (32 bytes) : 0 allocated\nTotal: 1 pools\n",
+			expected: &Pools{Pools: []*Pool{}},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			pools := parsePools(test.response)
+			if !reflect.DeepEqual(pools, test.expected) {
+				for _, p := range pools.Pools {
+					t.Logf("parsed pool %+v", *p)
+				}
+				t.Errorf("parsed pools %+v, expected %+v", *pools, *test.expected)
+			}
+		})
+	}
+}
+
+func TestParseDevInfo(t *testing.T) {
+	response := `HAProxy version 2.4.0
+Features
+  +EPOLL -KQUEUE +THREAD
+  +OPENSSL
+Platform info
+  machine: x86_64
+  Running on: Linux 5.10.0 #1 SMP x86_64
+  invalid line
+Process info
+  pid: 42
+`
+	info := parseDevInfo(response)
+	expected := &DevInfo{
+		Features: []string{"+EPOLL", "-KQUEUE", "+THREAD", "+OPENSSL"},
+		Sections: map[string]map[string]string{
+			"Platform info": {"machine": "x86_64", "Running on": "Linux 5.10.0 #1 SMP x86_64"},
+			"Process info":  {"pid": "42"},
+		},
+	}
+	if !reflect.DeepEqual(info, expected) {
+		t.Errorf("parsed dev info %+v, expected %+v", *info, *expected)
+	}
+
+	// values may contain ':'
+	info = parseDevInfo("Build options\n  TARGET: linux-glibc:custom\n")
+	if v := info.Sections["Build options"]["TARGET"]; v != "linux-glibc:custom" {
+		t.Errorf("TARGET parsed as %q", v)
+	}
+}
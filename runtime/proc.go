@@ -0,0 +1,172 @@
+// Copyright 2019 HAProxy Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package runtime
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	native_errors "github.com/haproxytech/client-native/v2/errors"
+)
+
+// MasterProcess is a process listed by show proc on the master socket
+type MasterProcess struct {
+	PID  int64  `json:"pid"`
+	Type string `json:"type"`
+	// RelativePID is the process number, for old workers the number they had
+	RelativePID int64  `json:"relative_pid"`
+	Reloads     int64  `json:"reloads"`
+	Uptime      string `json:"uptime"`
+	Version     string `json:"version,omitempty"`
+	// Old is set for workers of a previous configuration that are still finishing connections
+	Old bool `json:"old"`
+}
+
+// MasterProcesses is an array of MasterProcess
+type MasterProcesses []*MasterProcess
+
+// Master returns the master process, nil if not listed
+func (p MasterProcesses) Master() *MasterProcess {
+	for _, proc := range p {
+		if proc.Type == "master" {
+			return proc
+		}
+	}
+	return nil
+}
+
+// Workers returns the current workers, or the old ones when old is set
+func (p MasterProcesses) Workers(old bool) MasterProcesses {
+	result := MasterProcesses{}
+	for _, proc := range p {
+		if proc.Type == "worker" && proc.Old == old {
+			result = append(result, proc)
+		}
+	}
+	return result
+}
+
+// ShowProc returns the processes managed by the HAProxy master listening on the master socket
+func ShowProc(masterSocketPath string) (MasterProcesses, error) {
+	conn, err := net.DialTimeout("unix", masterSocketPath, 5*time.Second)
+	if err != nil {
+		return nil, fmt.Errorf("%s %w", err.Error(), native_errors.ErrGeneral)
+	}
+	defer conn.Close()
+	if err := conn.SetDeadline(time.Now().Add(30 * time.Second)); err != nil {
+		return nil, err
+	}
+	if _, err := conn.Write([]byte("show proc\n")); err != nil {
+		return nil, fmt.Errorf("%s %w", err.Error(), native_errors.ErrGeneral)
+	}
+	response, err := ioutil.ReadAll(conn)
+	if err != nil {
+		return nil, fmt.Errorf("%s %w", err.Error(), native_errors.ErrGeneral)
+	}
+	return parseShowProc(string(response)), nil
+}
+
+// showProcColumns are the columns of show proc before HAProxy 2.5, used when the output has no
+// header line
+var showProcColumns = []string{"pid", "type", "relative pid", "reloads", "uptime", "version"}
+
+// parseShowProc reads the processes by the columns named in the header line, HAProxy 2.5 removed
+// the relative pid column
+func parseShowProc(response string) MasterProcesses {
+	procs := MasterProcesses{}
+	columns := showProcColumns
+	old := false
+	for _, line := range strings.Split(response, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if strings.HasPrefix(line, "#<") {
+			columns = []string{}
+			for _, m := range showProcHeader.FindAllStringSubmatch(line, -1) {
+				columns = append(columns, strings.ToLower(m[1]))
+			}
+			continue
+		}
+		if strings.HasPrefix(line, "#") {
+			old = strings.Contains(line, "old")
+			continue
+		}
+		values := showProcValues(line)
+		if len(values) < len(columns)-1 {
+			continue
+		}
+		proc := &MasterProcess{Old: old}
+		valid := true
+		for i, column := range columns {
+			if i >= len(values) {
+				break
+			}
+			v := values[i]
+			var err error
+			switch column {
+			case "pid":
+				proc.PID, err = strconv.ParseInt(v, 10, 64)
+				valid = err == nil
+			case "type":
+				proc.Type = v
+			case "relative pid":
+				// old workers show their relative pid as [was: N]
+				proc.RelativePID, _ = strconv.ParseInt(strings.TrimSpace(strings.TrimSuffix(strings.TrimPrefix(v, "[was:"), "]")), 10, 64)
+			case "reloads":
+				proc.Reloads, _ = strconv.ParseInt(v, 10, 64)
+			case "uptime":
+				proc.Uptime = v
+			case "version":
+				proc.Version = v
+			}
+		}
+		if valid {
+			procs = append(procs, proc)
+		}
+	}
+	return procs
+}
+
+var showProcHeader = regexp.MustCompile(`<([^>]+)>`)
+
+// showProcValues splits a show proc line in column values. [was: N] is the value of the relative
+// pid column of old workers, other bracketed notes such as [failed: N] after the reloads of the
+// master are not columns and are dropped.
+func showProcValues(line string) []string {
+	values := []string{}
+	fields := strings.Fields(line)
+	for i := 0; i < len(fields); i++ {
+		if !strings.HasPrefix(fields[i], "[") {
+			values = append(values, fields[i])
+			continue
+		}
+		note := fields[i]
+		for !strings.HasSuffix(note, "]") && i+1 < len(fields) {
+			i++
+			note += " " + fields[i]
+		}
+		if strings.HasPrefix(note, "[was:") {
+			values = append(values, note)
+		}
+	}
+	return values
+}
@@ -0,0 +1,97 @@
+// Copyright 2019 HAProxy Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package runtime
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseShowProc(t *testing.T) {
+	tests := []struct {
+		name     string
+		response string
+		expected MasterProcesses
+	}{
+		{
+			name: "before 2.5",
+			response: `#<PID>          <type>          <relative PID>  <reloads>       <uptime>        <version>
+1162            master          0               5               0d00h02m07s     2.2.4
+# workers
+1271            worker          1               0               0d00h00m00s     2.2.4
+# old workers
+1233            worker          [was: 1]        3               0d00h00m28s     2.2.4
+# programs
+`,
+			expected: MasterProcesses{
+				{PID: 1162, Type: "master", Reloads: 5, Uptime: "0d00h02m07s", Version: "2.2.4"},
+				{PID: 1271, Type: "worker", RelativePID: 1, Uptime: "0d00h00m00s", Version: "2.2.4"},
+				{PID: 1233, Type: "worker", RelativePID: 1, Reloads: 3, Uptime: "0d00h00m28s", Version: "2.2.4", Old: true},
+			},
+		},
+		{
+			name: "2.5 and later",
+			response: `#<PID>          <type>          <reloads>       <uptime>        <version>
+4016            master          1 [failed: 0]   0d00h00m08s     2.5.0
+# workers
+4053            worker          0               0d00h00m00s     2.5.0
+# old workers
+4031            worker          1               0d00h00m08s     2.5.0
+# programs
+`,
+			expected: MasterProcesses{
+				{PID: 4016, Type: "master", Reloads: 1, Uptime: "0d00h00m08s", Version: "2.5.0"},
+				{PID: 4053, Type: "worker", Uptime: "0d00h00m00s", Version: "2.5.0"},
+				{PID: 4031, Type: "worker", Reloads: 1, Uptime: "0d00h00m08s", Version: "2.5.0", Old: true},
+			},
+		},
+		{
+			name: "without header",
+			response: `1162            master          0               5               0d00h02m07s     2.2.4
+# workers
+1271            worker          1               0               0d00h00m00s
+`,
+			expected: MasterProcesses{
+				{PID: 1162, Type: "master", Reloads: 5, Uptime: "0d00h02m07s", Version: "2.2.4"},
+				{PID: 1271, Type: "worker", RelativePID: 1, Uptime: "0d00h00m00s"},
+			},
+		},
+		{
+			name:     "invalid lines",
+			response: "#<PID> <type> <reloads> <uptime> <version>\nUnknown command\nabc worker 0 0d00h00m00s 2.5.0\n4053 worker\n",
+			expected: MasterProcesses{},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			procs := parseShowProc(test.response)
+			if !reflect.DeepEqual(procs, test.expected) {
+				for _, p := range procs {
+					t.Logf("parsed process %+v", *p)
+				}
+				t.Error("parsed processes not equal to expected")
+			}
+		})
+	}
+
+	procs := parseShowProc(tests[1].response)
+	if m := procs.Master(); m == nil || m.PID != 4016 {
+		t.Errorf("master %v returned, expected 4016", m)
+	}
+	if w := procs.Workers(true); len(w) != 1 || w[0].PID != 4031 {
+		t.Errorf("old workers %v returned, expected 4031", w)
+	}
+}
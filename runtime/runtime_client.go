@@ -86,6 +86,9 @@ func (c *Client) GetMapsPath(name string) (string, error) {
 	if strings.HasPrefix(name, "#") { //id
 		return name, nil
 	}
+	if filepath.IsAbs(name) { //path
+		return name, nil
+	}
 	//CLI
 	if c.MapsDir != "" {
 		ext := filepath.Ext(name)
@@ -147,6 +150,16 @@ func (c *Client) GetStats() models.NativeStats {
 	return result
 }
 
+//GetStatsFiltered returns stats of the proxy, or of all proxies when empty, for the types
+//selected with the StatsType flags
+func (c *Client) GetStatsFiltered(proxy string, types int) models.NativeStats {
+	result := make(models.NativeStats, len(c.runtimes))
+	for index, runtime := range c.runtimes {
+		result[index] = runtime.GetStatsFiltered(proxy, types)
+	}
+	return result
+}
+
 //GetInfo returns info from the socket
 func (c *Client) GetInfo() (models.ProcessInfos, error) {
 	result := models.ProcessInfos{}
@@ -610,3 +623,203 @@ func (c *Client) ParseMapEntries(output string) models.MapEntries {
 func (c *Client) ParseMapEntriesFromFile(inputFile io.Reader, hasId bool) models.MapEntries {
 	return parseMapEntriesFromFile(inputFile, hasId)
 }
+
+//ShowACLs returns ACL files loaded in runtime, unique across all processes
+func (c *Client) ShowACLs() (ACLFiles, error) {
+	acls := ACLFiles{}
+	var lastErr error
+	for _, runtime := range c.runtimes {
+		a, err := runtime.ShowACLs()
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		for _, acl := range a {
+			exists := false
+			for _, e := range acls {
+				if e.File == acl.File {
+					exists = true
+					break
+				}
+			}
+			if !exists {
+				acls = append(acls, acl)
+			}
+		}
+	}
+	if len(acls) == 0 && lastErr != nil {
+		return nil, lastErr
+	}
+	return acls, nil
+}
+
+//GetACLFile returns one ACL file loaded in runtime
+func (c *Client) GetACLFile(file string) (*ACLFile, error) {
+	var lastErr error
+	for _, runtime := range c.runtimes {
+		a, err := runtime.GetACLFile(file)
+		if a != nil {
+			return a, nil
+		}
+		if err != nil {
+			lastErr = err
+		}
+	}
+	return nil, lastErr
+}
+
+//ShowACLFileEntries returns runtime entries of one ACL file
+func (c *Client) ShowACLFileEntries(file string) (ACLFileEntries, error) {
+	var lastErr error
+	for _, runtime := range c.runtimes {
+		e, err := runtime.ShowACLFileEntries(file)
+		if err == nil {
+			return e, nil
+		}
+		lastErr = err
+	}
+	return nil, lastErr
+}
+
+//AddACLFileEntry adds a pattern into the ACL file in all processes
+func (c *Client) AddACLFileEntry(file, value string) error {
+	for _, runtime := range c.runtimes {
+		err := runtime.AddACLFileEntry(file, value)
+		if err != nil {
+			return fmt.Errorf("%s %w", runtime.socketPath, err)
+		}
+	}
+	return nil
+}
+
+//DeleteACLFileEntry deletes a pattern from the ACL file in all processes
+func (c *Client) DeleteACLFileEntry(file, value string) error {
+	for _, runtime := range c.runtimes {
+		err := runtime.DeleteACLFileEntry(file, value)
+		if err != nil {
+			return fmt.Errorf("%s %w", runtime.socketPath, err)
+		}
+	}
+	return nil
+}
+
+//ShowSSLCerts returns file names of certificates loaded in runtime
+func (c *Client) ShowSSLCerts() ([]string, error) {
+	var lastErr error
+	for _, runtime := range c.runtimes {
+		certs, err := runtime.ShowSSLCerts()
+		if err == nil {
+			return certs, nil
+		}
+		lastErr = err
+	}
+	return nil, lastErr
+}
+
+//SetSSLCert starts a certificate update transaction in all processes
+func (c *Client) SetSSLCert(file, payload string) error {
+	for _, runtime := range c.runtimes {
+		err := runtime.SetSSLCert(file, payload)
+		if err != nil {
+			return fmt.Errorf("%s %w", runtime.socketPath, err)
+		}
+	}
+	return nil
+}
+
+//CommitSSLCert commits the certificate update transaction in all processes
+func (c *Client) CommitSSLCert(file string) error {
+	for _, runtime := range c.runtimes {
+		err := runtime.CommitSSLCert(file)
+		if err != nil {
+			return fmt.Errorf("%s %w", runtime.socketPath, err)
+		}
+	}
+	return nil
+}
+
+//AbortSSLCert aborts the certificate update transaction in all processes
+func (c *Client) AbortSSLCert(file string) error {
+	var lastErr error
+	for _, runtime := range c.runtimes {
+		err := runtime.AbortSSLCert(file)
+		if err != nil {
+			lastErr = fmt.Errorf("%s %w", runtime.socketPath, err)
+		}
+	}
+	return lastErr
+}
+
+//SetOCSPResponse updates the stapled OCSP response in all processes
+func (c *Client) SetOCSPResponse(response string) error {
+	for _, runtime := range c.runtimes {
+		err := runtime.SetOCSPResponse(response)
+		if err != nil {
+			return fmt.Errorf("%s %w", runtime.socketPath, err)
+		}
+	}
+	return nil
+}
+
+//ShowTLSKeys returns TLS ticket keys files loaded in runtime
+func (c *Client) ShowTLSKeys() (TLSKeysFiles, error) {
+	var lastErr error
+	for _, runtime := range c.runtimes {
+		files, err := runtime.ShowTLSKeys()
+		if err == nil {
+			return files, nil
+		}
+		lastErr = err
+	}
+	return nil, lastErr
+}
+
+//SetTLSKey sets the next TLS ticket key of the keys file in all processes
+func (c *Client) SetTLSKey(id string, key string) error {
+	for _, runtime := range c.runtimes {
+		err := runtime.SetTLSKey(id, key)
+		if err != nil {
+			return fmt.Errorf("%s %w", runtime.socketPath, err)
+		}
+	}
+	return nil
+}
+
+//GetPools returns the memory pools usage of all processes
+func (c *Client) GetPools() ([]*Pools, error) {
+	result := []*Pools{}
+	for _, runtime := range c.runtimes {
+		pools, err := runtime.GetPools()
+		if err != nil {
+			return nil, fmt.Errorf("%s %w", runtime.socketPath, err)
+		}
+		result = append(result, pools)
+	}
+	return result, nil
+}
+
+//GetDevInfo returns the build and platform information of all processes
+func (c *Client) GetDevInfo() ([]*DevInfo, error) {
+	result := []*DevInfo{}
+	for _, runtime := range c.runtimes {
+		info, err := runtime.GetDevInfo()
+		if err != nil {
+			return nil, fmt.Errorf("%s %w", runtime.socketPath, err)
+		}
+		result = append(result, info)
+	}
+	return result, nil
+}
+
+//GetPeersStatus returns the replication state of the peers sections of all processes
+func (c *Client) GetPeersStatus() ([]*PeersStatus, error) {
+	result := []*PeersStatus{}
+	for _, runtime := range c.runtimes {
+		sections, err := runtime.GetPeersStatus()
+		if err != nil {
+			return nil, fmt.Errorf("%s %w", runtime.socketPath, err)
+		}
+		result = append(result, sections...)
+	}
+	return result, nil
+}
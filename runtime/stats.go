@@ -23,20 +23,51 @@ import (
 	"github.com/mitchellh/mapstructure"
 )
 
+// Stats type flags of show stat, combined to select several types
+const (
+	StatsTypeFrontend = 1
+	StatsTypeBackend  = 2
+	StatsTypeServer   = 4
+)
+
 //GetStats fetches HAProxy stats from runtime API
 func (s *SingleRuntime) GetStats() *models.NativeStatsCollection {
-	rAPI := ""
+	return s.showStats("show stat")
+}
+
+//GetStatsFiltered fetches HAProxy stats of the proxy, or of all proxies when empty, for the types
+//selected with the StatsType flags
+func (s *SingleRuntime) GetStatsFiltered(proxy string, types int) *models.NativeStatsCollection {
+	if proxy == "" {
+		proxy = "-1"
+	}
+	if strings.ContainsAny(proxy, " \t") || types < 1 || types > StatsTypeFrontend|StatsTypeBackend|StatsTypeServer {
+		return &models.NativeStatsCollection{
+			RuntimeAPI: s.runtimeAPIName(),
+			Error:      fmt.Sprintf("invalid stats filter %s %d", proxy, types),
+		}
+	}
+	return s.showStats(fmt.Sprintf("show stat %s %d -1", proxy, types))
+}
+
+func (s *SingleRuntime) runtimeAPIName() string {
 	if s.worker != 0 {
-		rAPI = fmt.Sprintf("%s@%v", s.socketPath, s.worker)
-	} else {
-		rAPI = s.socketPath
+		return fmt.Sprintf("%s@%v", s.socketPath, s.worker)
 	}
-	result := &models.NativeStatsCollection{RuntimeAPI: rAPI}
-	rawdata, err := s.ExecuteRaw("show stat")
+	return s.socketPath
+}
+
+func (s *SingleRuntime) showStats(command string) *models.NativeStatsCollection {
+	result := &models.NativeStatsCollection{RuntimeAPI: s.runtimeAPIName()}
+	rawdata, err := s.ExecuteRaw(command)
 	if err != nil {
 		result.Error = err.Error()
 		return result
 	}
+	if !strings.HasPrefix(rawdata, "# ") {
+		result.Error = fmt.Sprintf("%s: %s", command, strings.TrimSpace(rawdata))
+		return result
+	}
 	lines := strings.Split(rawdata[2:], "\n")
 	stats := []*models.NativeStat{}
 	keys := strings.Split(lines[0], ",")
@@ -0,0 +1,68 @@
+// Copyright 2019 HAProxy Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package runtime
+
+import (
+	"fmt"
+	"strings"
+
+	native_errors "github.com/haproxytech/client-native/v2/errors"
+)
+
+// TLSKeysFile is a TLS ticket keys file or a bind with ticket keys loaded in runtime
+type TLSKeysFile struct {
+	ID   string `json:"id,omitempty"`
+	File string `json:"file,omitempty"`
+}
+
+// TLSKeysFiles is an array of TLSKeysFile
+type TLSKeysFiles []*TLSKeysFile
+
+// ShowTLSKeys returns TLS ticket keys files loaded in runtime
+func (s *SingleRuntime) ShowTLSKeys() (TLSKeysFiles, error) {
+	response, err := s.ExecuteWithResponse("show tls-keys")
+	if err != nil {
+		return nil, fmt.Errorf("%s %w", err.Error(), native_errors.ErrNotFound)
+	}
+	files := TLSKeysFiles{}
+	for _, line := range strings.Split(strings.TrimSpace(response), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		parts := strings.SplitN(line, " ", 2)
+		f := &TLSKeysFile{ID: parts[0]}
+		if len(parts) == 2 {
+			f.File = strings.Trim(strings.TrimSpace(parts[1]), "()")
+		}
+		files = append(files, f)
+	}
+	return files, nil
+}
+
+// SetTLSKey sets the next TLS ticket key of the keys file, id is the file name or #<id> from
+// show tls-keys. The new key becomes the last one, the penultimate one is used for encryption
+// and the oldest one is dropped.
+func (s *SingleRuntime) SetTLSKey(id string, key string) error {
+	response, err := s.ExecuteWithResponse(fmt.Sprintf("set ssl tls-key %s %s", id, key))
+	if err != nil {
+		return fmt.Errorf("%s %w", err.Error(), native_errors.ErrGeneral)
+	}
+	if !strings.Contains(response, "TLS ticket key updated") {
+		return fmt.Errorf("%s %w", strings.TrimSpace(response), native_errors.ErrGeneral)
+	}
+	return nil
+}
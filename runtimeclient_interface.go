@@ -6,6 +6,7 @@ import (
 	"io"
 	"mime/multipart"
 
+	"github.com/haproxytech/client-native/v2/runtime"
 	"github.com/haproxytech/models/v2"
 )
 
@@ -21,6 +22,9 @@ type IRuntimeClient interface {
 	InitWithMasterSocket(masterSocketPath string, nbproc int) error
 	//GetStats returns stats from the socket
 	GetStats() models.NativeStats
+	//GetStatsFiltered returns stats of the proxy, or of all proxies when empty, for the types
+	//selected with the StatsType flags
+	GetStatsFiltered(proxy string, types int) models.NativeStats
 	//GetInfo returns info from the socket
 	GetInfo() (models.ProcessInfos, error)
 	//SetFrontendMaxConn set maxconn for frontend
@@ -80,5 +84,35 @@ type IRuntimeClient interface {
 	ParseMapEntries(output string) models.MapEntries
 	// ParseMapEntriesFromFile reads entries from file
 	ParseMapEntriesFromFile(inputFile io.Reader, hasId bool) models.MapEntries
+	//ShowACLs returns ACL files loaded in runtime, unique across all processes
+	ShowACLs() (runtime.ACLFiles, error)
+	//GetACLFile returns one ACL file loaded in runtime
+	GetACLFile(file string) (*runtime.ACLFile, error)
+	//ShowACLFileEntries returns runtime entries of one ACL file
+	ShowACLFileEntries(file string) (runtime.ACLFileEntries, error)
+	//AddACLFileEntry adds a pattern into the ACL file in all processes
+	AddACLFileEntry(file, value string) error
+	//DeleteACLFileEntry deletes a pattern from the ACL file in all processes
+	DeleteACLFileEntry(file, value string) error
+	//ShowSSLCerts returns file names of certificates loaded in runtime
+	ShowSSLCerts() ([]string, error)
+	//SetSSLCert starts a certificate update transaction in all processes
+	SetSSLCert(file, payload string) error
+	//CommitSSLCert commits the certificate update transaction in all processes
+	CommitSSLCert(file string) error
+	//AbortSSLCert aborts the certificate update transaction in all processes
+	AbortSSLCert(file string) error
+	//SetOCSPResponse updates the stapled OCSP response in all processes
+	SetOCSPResponse(response string) error
+	//ShowTLSKeys returns TLS ticket keys files loaded in runtime
+	ShowTLSKeys() (runtime.TLSKeysFiles, error)
+	//SetTLSKey sets the next TLS ticket key of the keys file in all processes
+	SetTLSKey(id string, key string) error
+	//GetPools returns the memory pools usage of all processes
+	GetPools() ([]*runtime.Pools, error)
+	//GetDevInfo returns the build and platform information of all processes
+	GetDevInfo() ([]*runtime.DevInfo, error)
+	//GetPeersStatus returns the replication state of the peers sections of all processes
+	GetPeersStatus() ([]*runtime.PeersStatus, error)
 }
 
@@ -0,0 +1,238 @@
+// Copyright 2019 HAProxy Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package client_native
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path"
+	"path/filepath"
+	"time"
+
+	"github.com/haproxytech/client-native/v2/storage"
+)
+
+const (
+	snapshotManifest = "manifest.json"
+	snapshotConfig   = "haproxy.cfg"
+)
+
+// SnapshotFile is a file stored in a snapshot
+type SnapshotFile struct {
+	Type   storage.FileType `json:"type"`
+	Name   string           `json:"name"`
+	Size   int64            `json:"size"`
+	SHA256 string           `json:"sha256"`
+}
+
+// SnapshotManifest describes the contents of a snapshot
+type SnapshotManifest struct {
+	Version      int64           `json:"version"`
+	Created      time.Time       `json:"created"`
+	ConfigSHA256 string          `json:"config_sha256"`
+	Files        []*SnapshotFile `json:"files"`
+}
+
+// ExportSnapshot writes a tar.gz archive with the configuration file, the files of all configured
+// storages (maps, certificates, TLS ticket keys, crt-lists, CA files, SPOE and general files)
+// and a manifest with their checksums. Returns the manifest of the written snapshot.
+func (c *HAProxyClient) ExportSnapshot(w io.Writer) (*SnapshotManifest, error) {
+	version, config, err := c.Configuration.GetRawConfiguration("", 0)
+	if err != nil {
+		return nil, err
+	}
+
+	manifest := &SnapshotManifest{
+		Version:      version,
+		Created:      time.Now().UTC(),
+		ConfigSHA256: checksum([]byte(config)),
+		Files:        []*SnapshotFile{},
+	}
+
+	files := map[string][]byte{snapshotConfig: []byte(config)}
+	for fileType, st := range c.snapshotStorages() {
+		paths, err := st.GetAll()
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range paths {
+			data, err := ioutil.ReadFile(p)
+			if err != nil {
+				return nil, err
+			}
+			f := &SnapshotFile{
+				Type:   fileType,
+				Name:   filepath.Base(p),
+				Size:   int64(len(data)),
+				SHA256: checksum(data),
+			}
+			manifest.Files = append(manifest.Files, f)
+			files[path.Join(string(f.Type), f.Name)] = data
+		}
+	}
+
+	m, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	gw := gzip.NewWriter(w)
+	tw := tar.NewWriter(gw)
+	if err := writeTarFile(tw, snapshotManifest, m, 0644); err != nil {
+		return nil, err
+	}
+	if err := writeTarFile(tw, snapshotConfig, files[snapshotConfig], 0644); err != nil {
+		return nil, err
+	}
+	for _, f := range manifest.Files {
+		name := path.Join(string(f.Type), f.Name)
+		if err := writeTarFile(tw, name, files[name], 0600); err != nil {
+			return nil, err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return nil, err
+	}
+	if err := gw.Close(); err != nil {
+		return nil, err
+	}
+	return manifest, nil
+}
+
+// ImportSnapshot restores a snapshot written by ExportSnapshot. All checksums are verified before
+// anything is written, then the storage files are restored and the configuration is pushed as a
+// new version. Files of storages that are not configured on this client are skipped. Returns the
+// manifest of the restored snapshot.
+func (c *HAProxyClient) ImportSnapshot(r io.Reader) (*SnapshotManifest, error) {
+	gr, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+	defer gr.Close()
+
+	files := map[string][]byte{}
+	tr := tar.NewReader(gr)
+	for {
+		h, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if h.Typeflag != tar.TypeReg {
+			continue
+		}
+		data, err := ioutil.ReadAll(tr)
+		if err != nil {
+			return nil, err
+		}
+		files[path.Clean(h.Name)] = data
+	}
+
+	m, ok := files[snapshotManifest]
+	if !ok {
+		return nil, fmt.Errorf("snapshot has no %s", snapshotManifest)
+	}
+	manifest := &SnapshotManifest{}
+	if err := json.Unmarshal(m, manifest); err != nil {
+		return nil, fmt.Errorf("invalid snapshot manifest: %w", err)
+	}
+
+	config, ok := files[snapshotConfig]
+	if !ok || checksum(config) != manifest.ConfigSHA256 {
+		return nil, fmt.Errorf("snapshot configuration is missing or does not match the manifest")
+	}
+	for _, f := range manifest.Files {
+		data, ok := files[path.Join(string(f.Type), f.Name)]
+		if !ok || checksum(data) != f.SHA256 {
+			return nil, fmt.Errorf("snapshot file %s/%s is missing or does not match the manifest", f.Type, f.Name)
+		}
+	}
+
+	storages := c.snapshotStorages()
+	for _, f := range manifest.Files {
+		st, ok := storages[f.Type]
+		if !ok {
+			continue
+		}
+		if _, err := putStorageFile(st, f.Name, files[path.Join(string(f.Type), f.Name)]); err != nil {
+			return nil, fmt.Errorf("%s/%s: %w", f.Type, f.Name, err)
+		}
+	}
+
+	v, err := c.Configuration.GetVersion("")
+	if err != nil {
+		return nil, err
+	}
+	data := string(config)
+	if err := c.Configuration.PostRawConfiguration(&data, v, false); err != nil {
+		return nil, err
+	}
+	return manifest, nil
+}
+
+// snapshotStorages returns the configured storages by file type
+func (c *HAProxyClient) snapshotStorages() map[storage.FileType]storage.Storage {
+	storages := map[storage.FileType]storage.Storage{}
+	if c.MapStorage != nil {
+		storages[storage.MapsType] = c.MapStorage
+	}
+	if c.GeneralStorage != nil {
+		storages[storage.GeneralType] = c.GeneralStorage
+	}
+	if c.SSLCertStorage != nil {
+		storages[storage.SSLType] = c.SSLCertStorage
+	}
+	if c.TLSTicketKeysStorage != nil {
+		storages[storage.TLSTicketKeysType] = c.TLSTicketKeysStorage
+	}
+	if c.CrtListStorage != nil {
+		storages[storage.CrtListType] = c.CrtListStorage
+	}
+	if c.CAStorage != nil {
+		storages[storage.CAType] = c.CAStorage
+	}
+	if c.Spoe != nil {
+		storages[storage.SpoeType] = c.Spoe.Storage()
+	}
+	return storages
+}
+
+func writeTarFile(tw *tar.Writer, name string, data []byte, mode int64) error {
+	h := &tar.Header{
+		Name:    name,
+		Mode:    mode,
+		Size:    int64(len(data)),
+		ModTime: time.Now(),
+	}
+	if err := tw.WriteHeader(h); err != nil {
+		return err
+	}
+	_, err := tw.Write(data)
+	return err
+}
+
+func checksum(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,175 @@
+// Copyright 2019 HAProxy Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package client_native
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/haproxytech/client-native/v2/spoe"
+	"github.com/haproxytech/client-native/v2/storage"
+	"github.com/haproxytech/models/v2"
+)
+
+func newSnapshotClient(t *testing.T) *HAProxyClient {
+	c := newTestClient(t)
+	c.MapStorage = testStorage(t, storage.MapsType)
+	c.GeneralStorage = testStorage(t, storage.GeneralType)
+	s, err := spoe.New(filepath.Join(testDir(t), "spoe"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.Spoe = s
+	return c
+}
+
+func exportTestSnapshot(t *testing.T) (*bytes.Buffer, *SnapshotManifest) {
+	c := newSnapshotClient(t)
+	files := []struct {
+		st      storage.Storage
+		name    string
+		content string
+	}{
+		{c.MapStorage, "hosts.map", "example.com app\n"},
+		{c.GeneralStorage, "blocked.acl", "10.0.0.1\n"},
+		{c.Spoe.Storage(), "agents.conf", "[agents]\nspoe-agent agent\n"},
+	}
+	for _, f := range files {
+		if _, err := f.st.Create(f.name, ioutil.NopCloser(strings.NewReader(f.content))); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := c.Configuration.CreateBackend(&models.Backend{Name: "snapshot"}, "", 1); err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	manifest, err := c.ExportSnapshot(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &buf, manifest
+}
+
+func TestSnapshotRoundTrip(t *testing.T) {
+	buf, exported := exportTestSnapshot(t)
+	if exported.Version != 2 || len(exported.Files) != 3 {
+		t.Fatalf("manifest of version %d with %d files, expected version 2 with 3 files", exported.Version, len(exported.Files))
+	}
+
+	c := newSnapshotClient(t)
+	imported, err := c.ImportSnapshot(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if imported.ConfigSHA256 != exported.ConfigSHA256 || len(imported.Files) != len(exported.Files) {
+		t.Error("imported manifest differs from the exported one")
+	}
+	if _, _, err := c.Configuration.GetBackend("snapshot", ""); err != nil {
+		t.Error(err)
+	}
+	for fileType, st := range map[storage.FileType]storage.Storage{
+		storage.MapsType:    c.MapStorage,
+		storage.GeneralType: c.GeneralStorage,
+		storage.SpoeType:    c.Spoe.Storage(),
+	} {
+		for _, f := range exported.Files {
+			if f.Type != fileType {
+				continue
+			}
+			path, err := st.Get(f.Name)
+			if err != nil {
+				t.Errorf("%s/%s not restored", f.Type, f.Name)
+				continue
+			}
+			if data, _ := ioutil.ReadFile(path); checksum(data) != f.SHA256 {
+				t.Errorf("%s/%s restored with different content", f.Type, f.Name)
+			}
+		}
+	}
+}
+
+func TestSnapshotImportSkipsMissingStorages(t *testing.T) {
+	buf, _ := exportTestSnapshot(t)
+	c := newTestClient(t)
+	c.MapStorage = testStorage(t, storage.MapsType)
+	if _, err := c.ImportSnapshot(buf); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.MapStorage.Get("hosts.map"); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestSnapshotImportTampered(t *testing.T) {
+	buf, _ := exportTestSnapshot(t)
+	tampered := rewriteSnapshot(t, buf, "maps/hosts.map", "evil.com app\n")
+
+	c := newSnapshotClient(t)
+	if _, err := c.ImportSnapshot(tampered); err == nil {
+		t.Fatal("tampered snapshot imported")
+	}
+	if _, err := c.MapStorage.Get("hosts.map"); err == nil {
+		t.Error("file of a rejected snapshot restored")
+	}
+	if v, _ := c.Configuration.GetVersion(""); v != 1 {
+		t.Errorf("version %d after rejected import, expected 1", v)
+	}
+}
+
+// rewriteSnapshot returns the snapshot with the content of the named file replaced
+func rewriteSnapshot(t *testing.T, r io.Reader, name, content string) *bytes.Buffer {
+	gr, err := gzip.NewReader(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out bytes.Buffer
+	gw := gzip.NewWriter(&out)
+	tw := tar.NewWriter(gw)
+	tr := tar.NewReader(gr)
+	for {
+		h, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, err := ioutil.ReadAll(tr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if h.Name == name {
+			data = []byte(content)
+		}
+		if err := writeTarFile(tw, h.Name, data, h.Mode); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return &out
+}
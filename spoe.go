@@ -0,0 +1,68 @@
+// Copyright 2019 HAProxy Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package client_native
+
+import (
+	"fmt"
+
+	native_errors "github.com/haproxytech/client-native/v2/errors"
+	"github.com/haproxytech/models/v2"
+)
+
+// AttachSpoeFilter adds a spoe filter for the engine, the scope of the SPOE file, to the frontend
+// or backend. The backends used by the agents of the scope have to exist in configuration. One of
+// version or transactionID is mandatory. Returns error on fail, nil on success.
+func (c *HAProxyClient) AttachSpoeFilter(parentType string, parentName string, name string, engine string, transactionID string, version int64) error {
+	if c.Spoe == nil {
+		return fmt.Errorf("spoe directory not configured %w", native_errors.ErrGeneral)
+	}
+	path, err := c.Spoe.GetPath(name)
+	if err != nil {
+		return err
+	}
+	scope, err := c.Spoe.GetScope(name, engine)
+	if err != nil {
+		return err
+	}
+	if len(scope.Agents) == 0 {
+		return fmt.Errorf("scope %s in spoe file %s has no agent %w", engine, name, native_errors.ErrGeneral)
+	}
+
+	return c.withTransaction(transactionID, version, func(t string) error {
+		for _, a := range scope.Agents {
+			if _, _, err := c.Configuration.GetBackend(a.UseBackend, t); err != nil {
+				return err
+			}
+		}
+		_, filters, err := c.Configuration.GetFilters(parentType, parentName, t)
+		if err != nil {
+			return err
+		}
+		for _, f := range filters {
+			if f.Type == "spoe" && f.SpoeConfig == path && f.SpoeEngine == engine {
+				return fmt.Errorf("spoe filter %s %s in %s %s %w", name, engine, parentType, parentName, native_errors.ErrAlreadyExists)
+			}
+		}
+		index := int64(len(filters))
+		filter := &models.Filter{
+			Type:       "spoe",
+			SpoeConfig: path,
+			SpoeEngine: engine,
+			Index:      &index,
+		}
+		return c.Configuration.CreateFilter(parentType, parentName, filter, t, 0)
+	})
+}
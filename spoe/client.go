@@ -0,0 +1,356 @@
+// Copyright 2019 HAProxy Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package spoe
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	native_errors "github.com/haproxytech/client-native/v2/errors"
+	"github.com/haproxytech/client-native/v2/storage"
+)
+
+// Client manages the SPOE configuration files of one directory, the files referenced by the spoe
+// filters of HAProxy configuration
+type Client struct {
+	dirname string
+	files   storage.Storage
+	mu      sync.Mutex
+}
+
+// New returns a client for the SPOE files in the directory, creating the directory if it does
+// not exist
+func New(dirname string) (*Client, error) {
+	if dirname == "" {
+		return nil, fmt.Errorf("spoe directory not specified %w", native_errors.ErrGeneral)
+	}
+	files, err := storage.New(dirname, storage.SpoeType)
+	if err != nil {
+		return nil, err
+	}
+	return &Client{dirname: dirname, files: files}, nil
+}
+
+// Storage returns the SPOE files as a storage of unparsed files, used to copy them between
+// clients and to snapshots
+func (c *Client) Storage() storage.Storage {
+	return c.files
+}
+
+// GetAll returns the names of all SPOE files
+func (c *Client) GetAll() ([]string, error) {
+	files, err := ioutil.ReadDir(c.dirname)
+	if err != nil {
+		return nil, err
+	}
+	result := []string{}
+	for _, f := range files {
+		if !f.IsDir() && !strings.HasPrefix(f.Name(), ".") {
+			result = append(result, f.Name())
+		}
+	}
+	return result, nil
+}
+
+// GetPath returns the path of the SPOE file, as referenced by a spoe filter
+func (c *Client) GetPath(name string) (string, error) {
+	f, err := c.path(name)
+	if err != nil {
+		return "", err
+	}
+	if _, err := os.Stat(f); err != nil {
+		if os.IsNotExist(err) {
+			return "", fmt.Errorf("spoe file %s %w", name, native_errors.ErrNotFound)
+		}
+		return "", err
+	}
+	return f, nil
+}
+
+// Get returns the parsed SPOE file
+func (c *Client) Get(name string) (*File, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.load(name)
+}
+
+// Create writes a new SPOE file and returns its path. Returns error if the file already exists.
+func (c *Client) Create(name string, data *File) (string, error) {
+	if err := data.Validate(); err != nil {
+		return "", fmt.Errorf("%s %w", err.Error(), native_errors.ErrGeneral)
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	f, err := c.path(name)
+	if err != nil {
+		return "", err
+	}
+	if _, err := os.Stat(f); err == nil {
+		return "", fmt.Errorf("spoe file %s %w", name, native_errors.ErrAlreadyExists)
+	}
+	if err := writeFile(f, data.String()); err != nil {
+		return "", err
+	}
+	return f, nil
+}
+
+// Replace overwrites an existing SPOE file
+func (c *Client) Replace(name string, data *File) error {
+	return c.update(name, func(f *File) error {
+		*f = *data
+		return nil
+	})
+}
+
+// Delete removes the SPOE file
+func (c *Client) Delete(name string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	f, err := c.GetPath(name)
+	if err != nil {
+		return err
+	}
+	return os.Remove(f)
+}
+
+// GetScope returns the scope of the SPOE file
+func (c *Client) GetScope(name string, scope string) (*Scope, error) {
+	f, err := c.Get(name)
+	if err != nil {
+		return nil, err
+	}
+	s := f.Scope(scope)
+	if s == nil {
+		return nil, fmt.Errorf("scope %s in spoe file %s %w", scope, name, native_errors.ErrNotFound)
+	}
+	return s, nil
+}
+
+// CreateScope adds an empty scope at the end of the SPOE file
+func (c *Client) CreateScope(name string, scope string) error {
+	return c.update(name, func(f *File) error {
+		if f.Scope(scope) != nil {
+			return fmt.Errorf("scope %s %w", scope, native_errors.ErrAlreadyExists)
+		}
+		f.Scopes = append(f.Scopes, &Scope{Name: scope})
+		return nil
+	})
+}
+
+// DeleteScope removes the scope with all its sections from the SPOE file
+func (c *Client) DeleteScope(name string, scope string) error {
+	return c.update(name, func(f *File) error {
+		for i, s := range f.Scopes {
+			if s.Name == scope {
+				f.Scopes = append(f.Scopes[:i], f.Scopes[i+1:]...)
+				return nil
+			}
+		}
+		return fmt.Errorf("scope %s %w", scope, native_errors.ErrNotFound)
+	})
+}
+
+// CreateAgent adds the spoe-agent section to the scope
+func (c *Client) CreateAgent(name string, scope string, data *Agent) error {
+	return c.updateScope(name, scope, func(s *Scope) error {
+		if s.Agent(data.Name) != nil {
+			return fmt.Errorf("%s %s %w", AgentSection, data.Name, native_errors.ErrAlreadyExists)
+		}
+		s.Agents = append(s.Agents, data)
+		return nil
+	})
+}
+
+// EditAgent replaces the spoe-agent section of the scope
+func (c *Client) EditAgent(name string, scope string, agent string, data *Agent) error {
+	return c.updateScope(name, scope, func(s *Scope) error {
+		for i, a := range s.Agents {
+			if a.Name == agent {
+				s.Agents[i] = data
+				return nil
+			}
+		}
+		return fmt.Errorf("%s %s %w", AgentSection, agent, native_errors.ErrNotFound)
+	})
+}
+
+// DeleteAgent removes the spoe-agent section from the scope
+func (c *Client) DeleteAgent(name string, scope string, agent string) error {
+	return c.updateScope(name, scope, func(s *Scope) error {
+		for i, a := range s.Agents {
+			if a.Name == agent {
+				s.Agents = append(s.Agents[:i], s.Agents[i+1:]...)
+				return nil
+			}
+		}
+		return fmt.Errorf("%s %s %w", AgentSection, agent, native_errors.ErrNotFound)
+	})
+}
+
+// CreateMessage adds the spoe-message section to the scope
+func (c *Client) CreateMessage(name string, scope string, data *Message) error {
+	return c.updateScope(name, scope, func(s *Scope) error {
+		if s.Message(data.Name) != nil {
+			return fmt.Errorf("%s %s %w", MessageSection, data.Name, native_errors.ErrAlreadyExists)
+		}
+		s.Messages = append(s.Messages, data)
+		return nil
+	})
+}
+
+// EditMessage replaces the spoe-message section of the scope
+func (c *Client) EditMessage(name string, scope string, message string, data *Message) error {
+	return c.updateScope(name, scope, func(s *Scope) error {
+		for i, m := range s.Messages {
+			if m.Name == message {
+				s.Messages[i] = data
+				return nil
+			}
+		}
+		return fmt.Errorf("%s %s %w", MessageSection, message, native_errors.ErrNotFound)
+	})
+}
+
+// DeleteMessage removes the spoe-message section from the scope. Returns error if an agent or
+// a group of the scope still sends it.
+func (c *Client) DeleteMessage(name string, scope string, message string) error {
+	return c.updateScope(name, scope, func(s *Scope) error {
+		for i, m := range s.Messages {
+			if m.Name == message {
+				s.Messages = append(s.Messages[:i], s.Messages[i+1:]...)
+				return nil
+			}
+		}
+		return fmt.Errorf("%s %s %w", MessageSection, message, native_errors.ErrNotFound)
+	})
+}
+
+// CreateGroup adds the spoe-group section to the scope
+func (c *Client) CreateGroup(name string, scope string, data *Group) error {
+	return c.updateScope(name, scope, func(s *Scope) error {
+		if s.Group(data.Name) != nil {
+			return fmt.Errorf("%s %s %w", GroupSection, data.Name, native_errors.ErrAlreadyExists)
+		}
+		s.Groups = append(s.Groups, data)
+		return nil
+	})
+}
+
+// EditGroup replaces the spoe-group section of the scope
+func (c *Client) EditGroup(name string, scope string, group string, data *Group) error {
+	return c.updateScope(name, scope, func(s *Scope) error {
+		for i, g := range s.Groups {
+			if g.Name == group {
+				s.Groups[i] = data
+				return nil
+			}
+		}
+		return fmt.Errorf("%s %s %w", GroupSection, group, native_errors.ErrNotFound)
+	})
+}
+
+// DeleteGroup removes the spoe-group section from the scope. Returns error if an agent of the
+// scope still uses it.
+func (c *Client) DeleteGroup(name string, scope string, group string) error {
+	return c.updateScope(name, scope, func(s *Scope) error {
+		for i, g := range s.Groups {
+			if g.Name == group {
+				s.Groups = append(s.Groups[:i], s.Groups[i+1:]...)
+				return nil
+			}
+		}
+		return fmt.Errorf("%s %s %w", GroupSection, group, native_errors.ErrNotFound)
+	})
+}
+
+// update applies fn to the parsed file and writes it back if the result is valid
+func (c *Client) update(name string, fn func(f *File) error) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	f, err := c.load(name)
+	if err != nil {
+		return err
+	}
+	if err := fn(f); err != nil {
+		return err
+	}
+	if err := f.Validate(); err != nil {
+		return fmt.Errorf("%s %w", err.Error(), native_errors.ErrGeneral)
+	}
+	path, err := c.path(name)
+	if err != nil {
+		return err
+	}
+	return writeFile(path, f.String())
+}
+
+func (c *Client) updateScope(name string, scope string, fn func(s *Scope) error) error {
+	return c.update(name, func(f *File) error {
+		s := f.Scope(scope)
+		if s == nil {
+			return fmt.Errorf("scope %s in spoe file %s %w", scope, name, native_errors.ErrNotFound)
+		}
+		return fn(s)
+	})
+}
+
+func (c *Client) load(name string) (*File, error) {
+	path, err := c.GetPath(name)
+	if err != nil {
+		return nil, err
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	f, err := Parse(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("spoe file %s: %s %w", name, err.Error(), native_errors.ErrGeneral)
+	}
+	return f, nil
+}
+
+func (c *Client) path(name string) (string, error) {
+	if name == "" || filepath.Base(name) != name || name == "." || name == ".." {
+		return "", fmt.Errorf("invalid file name %s %w", name, native_errors.ErrGeneral)
+	}
+	return filepath.Join(c.dirname, name), nil
+}
+
+// writeFile writes data to a temporary file and renames it to dest, so HAProxy never reads a
+// partially written file
+func writeFile(dest string, data string) error {
+	tmp, err := ioutil.TempFile(filepath.Dir(dest), fmt.Sprintf(".%s.", filepath.Base(dest)))
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.WriteString(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), dest)
+}
@@ -0,0 +1,574 @@
+// Copyright 2019 HAProxy Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package spoe
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/haproxytech/client-native/v2/misc"
+)
+
+// Sections of a SPOE configuration file
+const (
+	AgentSection   = "spoe-agent"
+	MessageSection = "spoe-message"
+	GroupSection   = "spoe-group"
+)
+
+// Events which trigger sending a message
+var Events = []string{
+	"on-client-session", "on-server-session", "on-frontend-tcp-request", "on-backend-tcp-request",
+	"on-tcp-response", "on-frontend-http-request", "on-backend-http-request", "on-http-response",
+}
+
+// File is a SPOE configuration file, the scopes it holds in order
+type File struct {
+	Scopes []*Scope `json:"scopes"`
+}
+
+// Scope is the configuration of one SPOE engine, referenced by the engine id of a spoe filter.
+// Sections preceding the first scope line belong to the scope with an empty name.
+type Scope struct {
+	Name     string     `json:"name"`
+	Agents   []*Agent   `json:"agents,omitempty"`
+	Messages []*Message `json:"messages,omitempty"`
+	Groups   []*Group   `json:"groups,omitempty"`
+}
+
+// Agent is a spoe-agent section, the messages it sends and how it talks to the agents servers
+type Agent struct {
+	Name string `json:"name"`
+	// UseBackend is the backend of the agents servers, mandatory
+	UseBackend string   `json:"use_backend"`
+	Messages   []string `json:"messages,omitempty"`
+	Groups     []string `json:"groups,omitempty"`
+	// Log are the log targets of the agent, as written after the log keyword
+	Log              []string `json:"log,omitempty"`
+	MaxConnRate      *int64   `json:"maxconnrate,omitempty"`
+	MaxErrRate       *int64   `json:"maxerrrate,omitempty"`
+	MaxFrameSize     *int64   `json:"max_frame_size,omitempty"`
+	MaxWaitingFrames *int64   `json:"max_waiting_frames,omitempty"`
+	Async            bool     `json:"async,omitempty"`
+	ContinueOnError  bool     `json:"continue_on_error,omitempty"`
+	DontlogNormal    bool     `json:"dontlog_normal,omitempty"`
+	ForceSetVar      bool     `json:"force_set_var,omitempty"`
+	Pipelining       bool     `json:"pipelining,omitempty"`
+	SendFragPayload  bool     `json:"send_frag_payload,omitempty"`
+	SetOnError       string   `json:"set_on_error,omitempty"`
+	SetProcessTime   string   `json:"set_process_time,omitempty"`
+	SetTotalTime     string   `json:"set_total_time,omitempty"`
+	VarPrefix        string   `json:"var_prefix,omitempty"`
+	RegisterVarNames []string `json:"register_var_names,omitempty"`
+	// Timeouts are in milliseconds
+	TimeoutHello      *int64 `json:"timeout_hello,omitempty"`
+	TimeoutIdle       *int64 `json:"timeout_idle,omitempty"`
+	TimeoutProcessing *int64 `json:"timeout_processing,omitempty"`
+	// Raw are the lines of the section not covered by the fields above
+	Raw []string `json:"raw,omitempty"`
+}
+
+// Message is a spoe-message section, the arguments sent to the agents and the event sending them
+type Message struct {
+	Name string `json:"name"`
+	// Args are the arguments as written, name=sample or sample
+	Args []string `json:"args,omitempty"`
+	ACLs []*ACL   `json:"acls,omitempty"`
+	// Event is empty for messages sent by groups only
+	Event         string   `json:"event,omitempty"`
+	EventCond     string   `json:"event_cond,omitempty"`
+	EventCondTest string   `json:"event_cond_test,omitempty"`
+	Raw           []string `json:"raw,omitempty"`
+}
+
+// ACL is an acl declared in a spoe-message section
+type ACL struct {
+	Name      string `json:"name"`
+	Criterion string `json:"criterion"`
+	Value     string `json:"value,omitempty"`
+}
+
+// Group is a spoe-group section, messages sent together by a send-spoe-group action
+type Group struct {
+	Name     string   `json:"name"`
+	Messages []string `json:"messages"`
+}
+
+// options are the flag options of the agent, written as option <name>
+func (a *Agent) options() map[string]*bool {
+	return map[string]*bool{
+		"async":             &a.Async,
+		"continue-on-error": &a.ContinueOnError,
+		"dontlog-normal":    &a.DontlogNormal,
+		"force-set-var":     &a.ForceSetVar,
+		"pipelining":        &a.Pipelining,
+		"send-frag-payload": &a.SendFragPayload,
+	}
+}
+
+func (a *Agent) valueOptions() map[string]*string {
+	return map[string]*string{
+		"set-on-error":     &a.SetOnError,
+		"set-process-time": &a.SetProcessTime,
+		"set-total-time":   &a.SetTotalTime,
+		"var-prefix":       &a.VarPrefix,
+	}
+}
+
+func (a *Agent) numbers() map[string]**int64 {
+	return map[string]**int64{
+		"maxconnrate":        &a.MaxConnRate,
+		"maxerrrate":         &a.MaxErrRate,
+		"max-frame-size":     &a.MaxFrameSize,
+		"max-waiting-frames": &a.MaxWaitingFrames,
+	}
+}
+
+func (a *Agent) timeouts() map[string]**int64 {
+	return map[string]**int64{
+		"hello":      &a.TimeoutHello,
+		"idle":       &a.TimeoutIdle,
+		"processing": &a.TimeoutProcessing,
+	}
+}
+
+// Scope returns the scope with the name, nil if the file does not have it
+func (f *File) Scope(name string) *Scope {
+	for _, s := range f.Scopes {
+		if s.Name == name {
+			return s
+		}
+	}
+	return nil
+}
+
+// Agent returns the agent with the name, nil if the scope does not have it
+func (s *Scope) Agent(name string) *Agent {
+	for _, a := range s.Agents {
+		if a.Name == name {
+			return a
+		}
+	}
+	return nil
+}
+
+// Message returns the message with the name, nil if the scope does not have it
+func (s *Scope) Message(name string) *Message {
+	for _, m := range s.Messages {
+		if m.Name == name {
+			return m
+		}
+	}
+	return nil
+}
+
+// Group returns the group with the name, nil if the scope does not have it
+func (s *Scope) Group(name string) *Group {
+	for _, g := range s.Groups {
+		if g.Name == name {
+			return g
+		}
+	}
+	return nil
+}
+
+// Validate checks the sections of each scope and the messages and groups they reference
+func (f *File) Validate() error {
+	scopes := map[string]bool{}
+	for _, s := range f.Scopes {
+		if scopes[s.Name] {
+			return fmt.Errorf("scope %s defined more than once", s.Name)
+		}
+		scopes[s.Name] = true
+		if s.Name != "" && !validName(s.Name) {
+			return fmt.Errorf("invalid scope name %s", s.Name)
+		}
+		if err := s.Validate(); err != nil {
+			if s.Name == "" {
+				return err
+			}
+			return fmt.Errorf("scope %s: %s", s.Name, err.Error())
+		}
+	}
+	return nil
+}
+
+// Validate checks the sections of the scope and the messages and groups they reference
+func (s *Scope) Validate() error {
+	names := map[string]bool{}
+	for _, m := range s.Messages {
+		if err := m.validate(); err != nil {
+			return err
+		}
+		if names[m.Name] {
+			return fmt.Errorf("%s %s defined more than once", MessageSection, m.Name)
+		}
+		names[m.Name] = true
+	}
+	groups := map[string]bool{}
+	for _, g := range s.Groups {
+		if !validName(g.Name) {
+			return fmt.Errorf("invalid %s name %s", GroupSection, g.Name)
+		}
+		if groups[g.Name] {
+			return fmt.Errorf("%s %s defined more than once", GroupSection, g.Name)
+		}
+		groups[g.Name] = true
+		if len(g.Messages) == 0 {
+			return fmt.Errorf("%s %s has no messages", GroupSection, g.Name)
+		}
+		for _, m := range g.Messages {
+			if !names[m] {
+				return fmt.Errorf("%s %s: %s %s does not exist", GroupSection, g.Name, MessageSection, m)
+			}
+		}
+	}
+	agents := map[string]bool{}
+	for _, a := range s.Agents {
+		if err := a.validate(); err != nil {
+			return err
+		}
+		if agents[a.Name] {
+			return fmt.Errorf("%s %s defined more than once", AgentSection, a.Name)
+		}
+		agents[a.Name] = true
+		for _, m := range a.Messages {
+			if !names[m] {
+				return fmt.Errorf("%s %s: %s %s does not exist", AgentSection, a.Name, MessageSection, m)
+			}
+		}
+		for _, g := range a.Groups {
+			if !groups[g] {
+				return fmt.Errorf("%s %s: %s %s does not exist", AgentSection, a.Name, GroupSection, g)
+			}
+		}
+	}
+	return nil
+}
+
+func (a *Agent) validate() error {
+	if !validName(a.Name) {
+		return fmt.Errorf("invalid %s name %s", AgentSection, a.Name)
+	}
+	if !validName(a.UseBackend) {
+		return fmt.Errorf("%s %s: use-backend is mandatory", AgentSection, a.Name)
+	}
+	for keyword, v := range a.numbers() {
+		if *v != nil && **v < 0 {
+			return fmt.Errorf("%s %s: %s can not be negative", AgentSection, a.Name, keyword)
+		}
+	}
+	for keyword, v := range a.timeouts() {
+		if *v != nil && **v <= 0 {
+			return fmt.Errorf("%s %s: timeout %s has to be greater than 0", AgentSection, a.Name, keyword)
+		}
+	}
+	for keyword, v := range a.valueOptions() {
+		if *v != "" && !validName(*v) {
+			return fmt.Errorf("%s %s: invalid option %s %s", AgentSection, a.Name, keyword, *v)
+		}
+	}
+	for _, l := range append(a.Log, a.Raw...) {
+		if strings.TrimSpace(l) == "" || strings.ContainsAny(l, "\n") || stripComment(l) != l {
+			return fmt.Errorf("%s %s: invalid line %s", AgentSection, a.Name, l)
+		}
+	}
+	return nil
+}
+
+func (m *Message) validate() error {
+	if !validName(m.Name) {
+		return fmt.Errorf("invalid %s name %s", MessageSection, m.Name)
+	}
+	if m.Event != "" && !misc.StringInSlice(m.Event, Events) {
+		return fmt.Errorf("%s %s: unknown event %s", MessageSection, m.Name, m.Event)
+	}
+	if m.EventCond != "" {
+		if m.Event == "" {
+			return fmt.Errorf("%s %s: condition without event", MessageSection, m.Name)
+		}
+		if m.EventCond != "if" && m.EventCond != "unless" {
+			return fmt.Errorf("%s %s: event condition must be if or unless", MessageSection, m.Name)
+		}
+		if strings.TrimSpace(m.EventCondTest) == "" {
+			return fmt.Errorf("%s %s: event condition test is empty", MessageSection, m.Name)
+		}
+		if stripComment(m.EventCondTest) != m.EventCondTest {
+			return fmt.Errorf("%s %s: invalid event condition test %s", MessageSection, m.Name, m.EventCondTest)
+		}
+	}
+	for _, arg := range m.Args {
+		if !validName(arg) {
+			return fmt.Errorf("%s %s: invalid argument %s", MessageSection, m.Name, arg)
+		}
+	}
+	for _, acl := range m.ACLs {
+		if acl == nil || !validName(acl.Name) || !validName(acl.Criterion) || stripComment(acl.Value) != acl.Value {
+			return fmt.Errorf("%s %s: invalid acl", MessageSection, m.Name)
+		}
+	}
+	for _, l := range m.Raw {
+		if strings.TrimSpace(l) == "" || strings.ContainsAny(l, "\n") || stripComment(l) != l {
+			return fmt.Errorf("%s %s: invalid line %s", MessageSection, m.Name, l)
+		}
+	}
+	return nil
+}
+
+// validName checks the name is one word which does not start a comment
+func validName(name string) bool {
+	return name != "" && !strings.ContainsAny(name, " \t\n[]") && !strings.HasPrefix(name, "#")
+}
+
+// Parse reads the sections of a SPOE configuration file. Comments, from a # starting a word to
+// the end of the line, are not kept.
+func Parse(data string) (*File, error) {
+	f := &File{}
+	var scope *Scope
+	var agent *Agent
+	var message *Message
+	var group *Group
+	for i, line := range strings.Split(data, "\n") {
+		line = strings.TrimSpace(stripComment(line))
+		if line == "" {
+			continue
+		}
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			scope = &Scope{Name: strings.TrimSpace(line[1 : len(line)-1])}
+			f.Scopes = append(f.Scopes, scope)
+			agent, message, group = nil, nil, nil
+			continue
+		}
+		words := strings.Fields(line)
+		switch words[0] {
+		case AgentSection, MessageSection, GroupSection:
+			if len(words) != 2 {
+				return nil, fmt.Errorf("line %d: %s needs a name", i+1, words[0])
+			}
+			if scope == nil {
+				scope = &Scope{}
+				f.Scopes = append(f.Scopes, scope)
+			}
+			agent, message, group = nil, nil, nil
+			switch words[0] {
+			case AgentSection:
+				agent = &Agent{Name: words[1]}
+				scope.Agents = append(scope.Agents, agent)
+			case MessageSection:
+				message = &Message{Name: words[1]}
+				scope.Messages = append(scope.Messages, message)
+			case GroupSection:
+				group = &Group{Name: words[1]}
+				scope.Groups = append(scope.Groups, group)
+			}
+			continue
+		}
+		var err error
+		switch {
+		case agent != nil:
+			err = agent.parse(words)
+		case message != nil:
+			err = message.parse(words)
+		case group != nil:
+			if words[0] != "messages" {
+				err = fmt.Errorf("unknown keyword %s", words[0])
+				break
+			}
+			group.Messages = append(group.Messages, words[1:]...)
+		default:
+			err = fmt.Errorf("%s outside of a section", words[0])
+		}
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %s", i+1, err.Error())
+		}
+	}
+	return f, nil
+}
+
+// stripComment removes the comment of the line, a # starting a word. A # inside a word, such
+// as in a sample fetch argument, is kept.
+func stripComment(line string) string {
+	for i, c := range line {
+		if c == '#' && (i == 0 || line[i-1] == ' ' || line[i-1] == '\t') {
+			return line[:i]
+		}
+	}
+	return line
+}
+
+func (a *Agent) parse(words []string) error {
+	value := strings.Join(words[1:], " ")
+	switch words[0] {
+	case "use-backend":
+		a.UseBackend = value
+		return nil
+	case "messages":
+		a.Messages = append(a.Messages, words[1:]...)
+		return nil
+	case "groups":
+		a.Groups = append(a.Groups, words[1:]...)
+		return nil
+	case "register-var-names":
+		a.RegisterVarNames = append(a.RegisterVarNames, words[1:]...)
+		return nil
+	case "log":
+		a.Log = append(a.Log, value)
+		return nil
+	case "timeout":
+		if len(words) == 3 {
+			if t, ok := a.timeouts()[words[1]]; ok {
+				*t = misc.ParseTimeout(words[2])
+				return nil
+			}
+		}
+	case "option":
+		if len(words) == 2 {
+			if o, ok := a.options()[words[1]]; ok {
+				*o = true
+				return nil
+			}
+		}
+		if len(words) == 3 {
+			if o, ok := a.valueOptions()[words[1]]; ok {
+				*o = words[2]
+				return nil
+			}
+		}
+	default:
+		if n, ok := a.numbers()[words[0]]; ok && len(words) == 2 {
+			v, err := strconv.ParseInt(words[1], 10, 64)
+			if err != nil {
+				return fmt.Errorf("invalid %s %s", words[0], words[1])
+			}
+			*n = &v
+			return nil
+		}
+	}
+	a.Raw = append(a.Raw, strings.Join(words, " "))
+	return nil
+}
+
+func (m *Message) parse(words []string) error {
+	switch words[0] {
+	case "args":
+		m.Args = append(m.Args, words[1:]...)
+	case "acl":
+		if len(words) < 3 {
+			return fmt.Errorf("acl needs a name and a criterion")
+		}
+		m.ACLs = append(m.ACLs, &ACL{Name: words[1], Criterion: words[2], Value: strings.Join(words[3:], " ")})
+	case "event":
+		if len(words) < 2 {
+			return fmt.Errorf("event needs a name")
+		}
+		m.Event = words[1]
+		if len(words) > 2 {
+			m.EventCond = words[2]
+			m.EventCondTest = strings.Join(words[3:], " ")
+		}
+	default:
+		m.Raw = append(m.Raw, strings.Join(words, " "))
+	}
+	return nil
+}
+
+// String returns the file as written to disk
+func (f *File) String() string {
+	var sb strings.Builder
+	for i, s := range f.Scopes {
+		if i > 0 {
+			sb.WriteString("\n")
+		}
+		if s.Name != "" {
+			sb.WriteString("[" + s.Name + "]\n")
+		}
+		for _, a := range s.Agents {
+			writeSection(&sb, AgentSection, a.Name, a.lines())
+		}
+		for _, m := range s.Messages {
+			writeSection(&sb, MessageSection, m.Name, m.lines())
+		}
+		for _, g := range s.Groups {
+			writeSection(&sb, GroupSection, g.Name, []string{"messages " + strings.Join(g.Messages, " ")})
+		}
+	}
+	return sb.String()
+}
+
+func writeSection(sb *strings.Builder, section, name string, lines []string) {
+	sb.WriteString(section + " " + name + "\n")
+	for _, l := range lines {
+		sb.WriteString("    " + l + "\n")
+	}
+}
+
+func (a *Agent) lines() []string {
+	lines := []string{}
+	if len(a.Messages) > 0 {
+		lines = append(lines, "messages "+strings.Join(a.Messages, " "))
+	}
+	if len(a.Groups) > 0 {
+		lines = append(lines, "groups "+strings.Join(a.Groups, " "))
+	}
+	for _, keyword := range []string{"async", "continue-on-error", "dontlog-normal", "force-set-var", "pipelining", "send-frag-payload"} {
+		if *a.options()[keyword] {
+			lines = append(lines, "option "+keyword)
+		}
+	}
+	for _, keyword := range []string{"set-on-error", "set-process-time", "set-total-time", "var-prefix"} {
+		if v := *a.valueOptions()[keyword]; v != "" {
+			lines = append(lines, "option "+keyword+" "+v)
+		}
+	}
+	for _, keyword := range []string{"hello", "idle", "processing"} {
+		if v := *a.timeouts()[keyword]; v != nil {
+			lines = append(lines, "timeout "+keyword+" "+strconv.FormatInt(*v, 10)+"ms")
+		}
+	}
+	for _, keyword := range []string{"maxconnrate", "maxerrrate", "max-frame-size", "max-waiting-frames"} {
+		if v := *a.numbers()[keyword]; v != nil {
+			lines = append(lines, keyword+" "+strconv.FormatInt(*v, 10))
+		}
+	}
+	if len(a.RegisterVarNames) > 0 {
+		lines = append(lines, "register-var-names "+strings.Join(a.RegisterVarNames, " "))
+	}
+	for _, l := range a.Log {
+		lines = append(lines, "log "+l)
+	}
+	lines = append(lines, a.Raw...)
+	return append(lines, "use-backend "+a.UseBackend)
+}
+
+func (m *Message) lines() []string {
+	lines := []string{}
+	for _, acl := range m.ACLs {
+		lines = append(lines, strings.TrimSpace("acl "+acl.Name+" "+acl.Criterion+" "+acl.Value))
+	}
+	if len(m.Args) > 0 {
+		lines = append(lines, "args "+strings.Join(m.Args, " "))
+	}
+	lines = append(lines, m.Raw...)
+	if m.Event != "" {
+		event := "event " + m.Event
+		if m.EventCond != "" {
+			event += " " + m.EventCond + " " + m.EventCondTest
+		}
+		lines = append(lines, event)
+	}
+	return lines
+}
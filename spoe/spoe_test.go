@@ -0,0 +1,209 @@
+// Copyright 2019 HAProxy Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package spoe
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/haproxytech/client-native/v2/misc"
+)
+
+const testSPOEFile = `[ip-reputation]
+spoe-agent iprep-agent
+    messages check-client-ip
+    groups log-group
+    option async
+    option continue-on-error
+    option dontlog-normal
+    option force-set-var
+    option pipelining
+    option send-frag-payload
+    option set-on-error error
+    option set-process-time ptime
+    option set-total-time ttime
+    option var-prefix iprep
+    timeout hello 2000ms
+    timeout idle 120000ms
+    timeout processing 15ms
+    maxconnrate 100
+    maxerrrate 50
+    max-frame-size 16380
+    max-waiting-frames 20
+    register-var-names score reason
+    log global
+    log 127.0.0.1:514 local0 info
+    engine-name iprep
+    use-backend agents
+spoe-message check-client-ip
+    acl is_local src 127.0.0.1
+    acl has_cookie req.cook(sess#id) -m found
+    args ip=src path=path
+    event on-frontend-http-request unless is_local
+spoe-message log-request
+    args method=method
+spoe-group log-group
+    messages log-request
+
+[waf]
+spoe-agent waf-agent
+    messages check-request
+    use-backend waf-agents
+spoe-message check-request
+    args req.body
+    event on-backend-http-request
+`
+
+func TestParseSerialize(t *testing.T) {
+	f, err := Parse(testSPOEFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := &File{Scopes: []*Scope{
+		{
+			Name: "ip-reputation",
+			Agents: []*Agent{{
+				Name:              "iprep-agent",
+				UseBackend:        "agents",
+				Messages:          []string{"check-client-ip"},
+				Groups:            []string{"log-group"},
+				Log:               []string{"global", "127.0.0.1:514 local0 info"},
+				MaxConnRate:       misc.Int64P(100),
+				MaxErrRate:        misc.Int64P(50),
+				MaxFrameSize:      misc.Int64P(16380),
+				MaxWaitingFrames:  misc.Int64P(20),
+				Async:             true,
+				ContinueOnError:   true,
+				DontlogNormal:     true,
+				ForceSetVar:       true,
+				Pipelining:        true,
+				SendFragPayload:   true,
+				SetOnError:        "error",
+				SetProcessTime:    "ptime",
+				SetTotalTime:      "ttime",
+				VarPrefix:         "iprep",
+				RegisterVarNames:  []string{"score", "reason"},
+				TimeoutHello:      misc.Int64P(2000),
+				TimeoutIdle:       misc.Int64P(120000),
+				TimeoutProcessing: misc.Int64P(15),
+				Raw:               []string{"engine-name iprep"},
+			}},
+			Messages: []*Message{
+				{
+					Name: "check-client-ip",
+					Args: []string{"ip=src", "path=path"},
+					ACLs: []*ACL{
+						{Name: "is_local", Criterion: "src", Value: "127.0.0.1"},
+						{Name: "has_cookie", Criterion: "req.cook(sess#id)", Value: "-m found"},
+					},
+					Event:         "on-frontend-http-request",
+					EventCond:     "unless",
+					EventCondTest: "is_local",
+				},
+				{Name: "log-request", Args: []string{"method=method"}},
+			},
+			Groups: []*Group{{Name: "log-group", Messages: []string{"log-request"}}},
+		},
+		{
+			Name:     "waf",
+			Agents:   []*Agent{{Name: "waf-agent", UseBackend: "waf-agents", Messages: []string{"check-request"}}},
+			Messages: []*Message{{Name: "check-request", Args: []string{"req.body"}, Event: "on-backend-http-request"}},
+		},
+	}}
+	if !reflect.DeepEqual(f, expected) {
+		for _, s := range f.Scopes {
+			t.Logf("parsed scope %s: %+v", s.Name, *s)
+		}
+		t.Fatal("parsed SPOE file not equal to expected")
+	}
+
+	if s := f.String(); s != testSPOEFile {
+		t.Errorf("serialized SPOE file:\n%s\nexpected:\n%s", s, testSPOEFile)
+	}
+}
+
+func TestParseComments(t *testing.T) {
+	data := `# agents of the default scope
+spoe-agent agent # trailing comment
+    messages msg	# after a tab
+    use-backend agents
+spoe-message msg
+    acl tagged req.hdr(x-tag) -m str a#b
+    args tag=req.hdr(x-tag#1)
+#   args ignored
+`
+	f, err := Parse(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(f.Scopes) != 1 || f.Scopes[0].Name != "" {
+		t.Fatalf("sections without scope line not in the default scope: %+v", f.Scopes)
+	}
+	a := f.Scopes[0].Agent("agent")
+	if a == nil || !reflect.DeepEqual(a.Messages, []string{"msg"}) || a.UseBackend != "agents" {
+		t.Errorf("agent parsed as %+v", a)
+	}
+	m := f.Scopes[0].Message("msg")
+	if m == nil {
+		t.Fatal("message msg not parsed")
+	}
+	if !reflect.DeepEqual(m.Args, []string{"tag=req.hdr(x-tag#1)"}) {
+		t.Errorf("message args parsed as %v", m.Args)
+	}
+	if len(m.ACLs) != 1 || m.ACLs[0].Value != "-m str a#b" {
+		t.Errorf("message acls parsed as %+v", m.ACLs)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	files := map[string]string{
+		"unknown message": "spoe-agent a\n    messages missing\n    use-backend b\n",
+		"unknown group":   "spoe-agent a\n    groups missing\n    use-backend b\n",
+		"no backend":      "spoe-message m\n    args src\nspoe-agent a\n    messages m\n",
+		"unknown event":   "spoe-message m\n    event on-nothing\n",
+		"empty group":     "spoe-group g\n",
+		"duplicate scope": "[s]\nspoe-message m\n[s]\nspoe-message m\n",
+	}
+	for name, data := range files {
+		f, err := Parse(data)
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+			continue
+		}
+		if err := f.Validate(); err == nil {
+			t.Errorf("%s: invalid file accepted", name)
+		}
+	}
+}
+
+func TestValidateComments(t *testing.T) {
+	m := &Message{Name: "m", Args: []string{"src"}, Event: "on-client-session", EventCond: "if", EventCondTest: "TRUE # always"}
+	if err := m.validate(); err == nil {
+		t.Error("event condition with comment accepted")
+	}
+	a := &Agent{Name: "a", UseBackend: "b", Raw: []string{"engine-name x #y"}}
+	if err := a.validate(); err == nil {
+		t.Error("raw line with comment accepted")
+	}
+	a.Raw = []string{"engine-name x#y"}
+	if err := a.validate(); err != nil {
+		t.Errorf("raw line with # inside a word rejected: %v", err)
+	}
+}
@@ -0,0 +1,48 @@
+// Copyright 2019 HAProxy Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package client_native
+
+import (
+	"github.com/haproxytech/models/v2"
+)
+
+// GetSRVServers returns the server slots of the SRV discovery server-template of the backend with
+// their runtime state. Slots HAProxy does not report yet are returned with their name only.
+func (c *HAProxyClient) GetSRVServers(backend string, prefix string) (models.RuntimeServers, error) {
+	_, d, err := c.Configuration.GetSRVDiscovery(backend, prefix, "")
+	if err != nil {
+		return nil, err
+	}
+
+	states, err := c.Runtime.GetServersState(backend)
+	if err != nil {
+		return nil, err
+	}
+	byName := make(map[string]*models.RuntimeServer, len(states))
+	for _, s := range states {
+		byName[s.Name] = s
+	}
+
+	servers := models.RuntimeServers{}
+	for _, name := range d.ServerNames() {
+		if s, ok := byName[name]; ok {
+			servers = append(servers, s)
+			continue
+		}
+		servers = append(servers, &models.RuntimeServer{Name: name})
+	}
+	return servers, nil
+}
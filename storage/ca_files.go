@@ -0,0 +1,58 @@
+// Copyright 2019 HAProxy Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package storage
+
+import (
+	"crypto/x509"
+	"fmt"
+
+	native_errors "github.com/haproxytech/client-native/v2/errors"
+)
+
+// ValidateCAFile checks the content is PEM encoded certificates, as loaded by ca-file
+func ValidateCAFile(content string) error {
+	blocks, err := pemBlocks(content, func(blockType string) bool { return blockType == "CERTIFICATE" })
+	if err != nil {
+		return fmt.Errorf("invalid CA file: %s %w", err.Error(), native_errors.ErrGeneral)
+	}
+	if len(blocks) == 0 {
+		return fmt.Errorf("CA file has no certificate %w", native_errors.ErrGeneral)
+	}
+	for _, b := range blocks {
+		if _, err := x509.ParseCertificate(b.Bytes); err != nil {
+			return fmt.Errorf("invalid CA certificate: %s %w", err.Error(), native_errors.ErrGeneral)
+		}
+	}
+	return nil
+}
+
+// ValidateCRLFile checks the content is PEM encoded certificate revocation lists, as loaded by
+// crl-file
+func ValidateCRLFile(content string) error {
+	blocks, err := pemBlocks(content, func(blockType string) bool { return blockType == "X509 CRL" })
+	if err != nil {
+		return fmt.Errorf("invalid CRL file: %s %w", err.Error(), native_errors.ErrGeneral)
+	}
+	if len(blocks) == 0 {
+		return fmt.Errorf("CRL file has no revocation list %w", native_errors.ErrGeneral)
+	}
+	for _, b := range blocks {
+		if _, err := x509.ParseDERCRL(b.Bytes); err != nil {
+			return fmt.Errorf("invalid revocation list: %s %w", err.Error(), native_errors.ErrGeneral)
+		}
+	}
+	return nil
+}
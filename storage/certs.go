@@ -0,0 +1,245 @@
+// Copyright 2019 HAProxy Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package storage
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strings"
+	"time"
+
+	native_errors "github.com/haproxytech/client-native/v2/errors"
+)
+
+// CertificateInfo is the metadata of the leaf certificate of a PEM file in storage
+type CertificateInfo struct {
+	// File is the path of the PEM file
+	File string `json:"file"`
+	// Subject is the subject distinguished name
+	Subject string `json:"subject"`
+	// SANs are the DNS names, IP addresses and email addresses the certificate is valid for
+	SANs []string `json:"sans,omitempty"`
+	// Issuer is the issuer distinguished name
+	Issuer    string    `json:"issuer"`
+	NotBefore time.Time `json:"not_before"`
+	NotAfter  time.Time `json:"not_after"`
+	// KeyType is the public key algorithm and size, for example RSA-2048 or ECDSA-P256
+	KeyType string `json:"key_type"`
+}
+
+// CertificatesInfo is an array of CertificateInfo
+type CertificatesInfo []*CertificateInfo
+
+// SSLStorage is a storage of PEM certificates which can inspect the stored certificates
+type SSLStorage interface {
+	Storage
+	GetCertificateInfo(name string) (*CertificateInfo, error)
+	GetCertificatesInfo() (CertificatesInfo, error)
+	ListExpiring(within time.Duration) (CertificatesInfo, error)
+	StoreCertificate(name, cert, chain, key string) (string, error)
+	StoreOCSPResponse(name string, response []byte) (string, error)
+	GetOCSPResponseInfo(name string) (*OCSPResponseInfo, error)
+	ValidateOCSPResponses() error
+}
+
+type sslStorage struct {
+	Storage
+}
+
+// NewSSLStorage returns a certificate storage for the given directory, creating the directory if it does not exist
+func NewSSLStorage(dirname string) (SSLStorage, error) {
+	s, err := New(dirname, SSLType)
+	if err != nil {
+		return nil, err
+	}
+	return &sslStorage{Storage: s}, nil
+}
+
+// GetCertificateInfo returns metadata of the certificate in storage
+func (s *sslStorage) GetCertificateInfo(name string) (*CertificateInfo, error) {
+	f, err := s.Get(name)
+	if err != nil {
+		return nil, err
+	}
+	return ParseCertificateFile(f)
+}
+
+// GetCertificatesInfo returns metadata of all certificates in storage
+func (s *sslStorage) GetCertificatesInfo() (CertificatesInfo, error) {
+	files, err := s.GetAll()
+	if err != nil {
+		return nil, err
+	}
+	result := CertificatesInfo{}
+	for _, f := range files {
+		info, err := ParseCertificateFile(f)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, info)
+	}
+	return result, nil
+}
+
+// ListExpiring returns certificates in storage expiring within the given duration, including
+// already expired ones, sorted by expiry date
+func (s *sslStorage) ListExpiring(within time.Duration) (CertificatesInfo, error) {
+	all, err := s.GetCertificatesInfo()
+	if err != nil {
+		return nil, err
+	}
+	limit := time.Now().Add(within)
+	result := CertificatesInfo{}
+	for _, info := range all {
+		if info.NotAfter.Before(limit) {
+			result = append(result, info)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].NotAfter.Before(result[j].NotAfter)
+	})
+	return result, nil
+}
+
+// StoreCertificate assembles the certificate, its chain and private key into one PEM file and
+// writes it to storage, atomically replacing an existing file. Returns the path of the file.
+func (s *sslStorage) StoreCertificate(name, cert, chain, key string) (string, error) {
+	bundle, err := BuildPEMBundle(cert, chain, key)
+	if err != nil {
+		return "", err
+	}
+	f, err := s.Replace(name, bundle)
+	if errors.Is(err, native_errors.ErrNotFound) {
+		return s.Create(name, ioutil.NopCloser(strings.NewReader(bundle)))
+	}
+	return f, err
+}
+
+// BuildPEMBundle combines the leaf certificate, the intermediate chain and the private key into
+// the layout HAProxy expects: leaf first, then intermediates, then the key. Returns error if the
+// key does not match the certificate or an input contains unexpected PEM blocks.
+func BuildPEMBundle(cert, chain, key string) (string, error) {
+	certBlocks, err := pemBlocks(cert, func(t string) bool { return t == "CERTIFICATE" })
+	if err != nil || len(certBlocks) != 1 {
+		return "", fmt.Errorf("certificate must contain exactly one certificate %w", native_errors.ErrGeneral)
+	}
+	chainBlocks, err := pemBlocks(chain, func(t string) bool { return t == "CERTIFICATE" })
+	if err != nil {
+		return "", fmt.Errorf("chain: %s %w", err.Error(), native_errors.ErrGeneral)
+	}
+	keyBlocks, err := pemBlocks(key, func(t string) bool { return strings.HasSuffix(t, "PRIVATE KEY") })
+	if err != nil || len(keyBlocks) != 1 {
+		return "", fmt.Errorf("key must contain exactly one private key %w", native_errors.ErrGeneral)
+	}
+
+	certPEM := pem.EncodeToMemory(certBlocks[0])
+	keyPEM := pem.EncodeToMemory(keyBlocks[0])
+	if _, err := tls.X509KeyPair(certPEM, keyPEM); err != nil {
+		return "", fmt.Errorf("%s %w", err.Error(), native_errors.ErrGeneral)
+	}
+
+	var b strings.Builder
+	b.Write(certPEM)
+	for _, block := range chainBlocks {
+		b.Write(pem.EncodeToMemory(block))
+	}
+	b.Write(keyPEM)
+	return b.String(), nil
+}
+
+// pemBlocks decodes all PEM blocks of data, returns error on blocks of unexpected type or
+// on data which is not PEM encoded
+func pemBlocks(data string, allowed func(blockType string) bool) ([]*pem.Block, error) {
+	blocks := []*pem.Block{}
+	rest := []byte(data)
+	for {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		if !allowed(block.Type) {
+			return nil, fmt.Errorf("unexpected PEM block %s", block.Type)
+		}
+		blocks = append(blocks, block)
+	}
+	if strings.TrimSpace(string(rest)) != "" {
+		return nil, fmt.Errorf("invalid PEM data")
+	}
+	return blocks, nil
+}
+
+// ParseCertificateFile returns metadata of the first certificate in the PEM file, which is the
+// leaf certificate in the layout HAProxy expects
+func ParseCertificateFile(file string) (*CertificateInfo, error) {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	cert, err := parseLeafCertificate(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %s %w", file, err.Error(), native_errors.ErrGeneral)
+	}
+
+	info := &CertificateInfo{
+		File:      file,
+		Subject:   cert.Subject.String(),
+		Issuer:    cert.Issuer.String(),
+		NotBefore: cert.NotBefore,
+		NotAfter:  cert.NotAfter,
+		KeyType:   keyType(cert),
+	}
+	info.SANs = append(info.SANs, cert.DNSNames...)
+	for _, ip := range cert.IPAddresses {
+		info.SANs = append(info.SANs, ip.String())
+	}
+	info.SANs = append(info.SANs, cert.EmailAddresses...)
+	return info, nil
+}
+
+func parseLeafCertificate(data []byte) (*x509.Certificate, error) {
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			return nil, fmt.Errorf("no certificate found")
+		}
+		if block.Type == "CERTIFICATE" {
+			return x509.ParseCertificate(block.Bytes)
+		}
+	}
+}
+
+func keyType(cert *x509.Certificate) string {
+	switch k := cert.PublicKey.(type) {
+	case *rsa.PublicKey:
+		return fmt.Sprintf("RSA-%d", k.N.BitLen())
+	case *ecdsa.PublicKey:
+		return fmt.Sprintf("ECDSA-%s", k.Curve.Params().Name)
+	case ed25519.PublicKey:
+		return "Ed25519"
+	default:
+		return cert.PublicKeyAlgorithm.String()
+	}
+}
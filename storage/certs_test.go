@@ -0,0 +1,154 @@
+// Copyright 2019 HAProxy Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package storage
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestParseCertificateFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "certs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	certPEM, keyPEM, cert := testCertificate(t, "example.com")
+	f := filepath.Join(dir, "example.pem")
+	if err := ioutil.WriteFile(f, []byte(keyPEM+certPEM), 0600); err != nil {
+		t.Fatal(err)
+	}
+	info, err := ParseCertificateFile(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.File != f || info.Subject != "CN=example.com" || info.Issuer != "CN=example.com" {
+		t.Errorf("certificate info %+v returned", *info)
+	}
+	if len(info.SANs) != 1 || info.SANs[0] != "example.com" {
+		t.Errorf("SANs %v returned, expected example.com", info.SANs)
+	}
+	if !info.NotAfter.Equal(cert.NotAfter) || !info.NotBefore.Equal(cert.NotBefore) {
+		t.Errorf("validity %s - %s returned, expected %s - %s", info.NotBefore, info.NotAfter, cert.NotBefore, cert.NotAfter)
+	}
+	if info.KeyType != "ECDSA-P-256" {
+		t.Errorf("key type %s returned, expected ECDSA-P-256", info.KeyType)
+	}
+
+	if err := ioutil.WriteFile(f, []byte(keyPEM), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ParseCertificateFile(f); err == nil {
+		t.Error("file without certificate parsed")
+	}
+}
+
+func TestListExpiring(t *testing.T) {
+	dir, err := ioutil.TempDir("", "certs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	s, err := NewSSLStorage(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	for name, notAfter := range map[string]time.Time{
+		"expired": now.Add(-time.Hour),
+		"soon":    now.Add(5 * 24 * time.Hour),
+		"later":   now.Add(60 * 24 * time.Hour),
+		"sooner":  now.Add(2 * 24 * time.Hour),
+	} {
+		certPEM, keyPEM, _ := testCertificateExpiring(t, name+".example.com", notAfter)
+		if _, err := s.StoreCertificate(name, certPEM, "", keyPEM); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	expiring, err := s.ListExpiring(30 * 24 * time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	names := []string{}
+	for _, info := range expiring {
+		names = append(names, filepath.Base(info.File))
+	}
+	expected := []string{"expired.pem", "sooner.pem", "soon.pem"}
+	if len(names) != len(expected) {
+		t.Fatalf("expiring certificates %v returned, expected %v", names, expected)
+	}
+	for i := range names {
+		if names[i] != expected[i] {
+			t.Fatalf("expiring certificates %v returned, expected %v", names, expected)
+		}
+	}
+
+	expiring, err = s.ListExpiring(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(expiring) != 1 || filepath.Base(expiring[0].File) != "expired.pem" {
+		t.Errorf("expired certificates %v returned, expected expired.pem", expiring)
+	}
+}
+
+func TestBuildPEMBundle(t *testing.T) {
+	certPEM, keyPEM, _ := testCertificate(t, "example.com")
+	intermediatePEM, _, _ := testCertificate(t, "intermediate.example.com")
+	rootPEM, _, _ := testCertificate(t, "root.example.com")
+
+	bundle, err := BuildPEMBundle(certPEM, intermediatePEM+rootPEM, keyPEM)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := certPEM + intermediatePEM + rootPEM + keyPEM; bundle != expected {
+		t.Errorf("bundle:\n%s\nexpected leaf, chain in the given order, then key:\n%s", bundle, expected)
+	}
+
+	bundle, err = BuildPEMBundle(certPEM, "", keyPEM)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bundle != certPEM+keyPEM {
+		t.Errorf("bundle without chain:\n%s", bundle)
+	}
+}
+
+func TestBuildPEMBundleInvalid(t *testing.T) {
+	certPEM, keyPEM, _ := testCertificate(t, "example.com")
+	otherPEM, otherKeyPEM, _ := testCertificate(t, "other.example.com")
+
+	tests := map[string][3]string{
+		"key of another certificate": {certPEM, "", otherKeyPEM},
+		"two leaf certificates":      {certPEM + otherPEM, "", keyPEM},
+		"no certificate":             {"", "", keyPEM},
+		"no key":                     {certPEM, "", ""},
+		"key in chain":               {certPEM, otherKeyPEM, keyPEM},
+		"certificate as key":         {certPEM, "", otherPEM},
+		"not PEM encoded":            {certPEM, "garbage", keyPEM},
+	}
+	for name, args := range tests {
+		if _, err := BuildPEMBundle(args[0], args[1], args[2]); err == nil {
+			t.Errorf("%s: bundle built", name)
+		}
+	}
+}
@@ -0,0 +1,153 @@
+// Copyright 2019 HAProxy Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package storage
+
+import (
+	"fmt"
+	"strings"
+
+	native_errors "github.com/haproxytech/client-native/v2/errors"
+	"github.com/haproxytech/client-native/v2/misc"
+)
+
+// CrtListEntry is a line of a crt-list file, a certificate with the SSL options and SNI filters
+// it is used with
+type CrtListEntry struct {
+	// File is the path of the certificate, relative to crt-base if it does not start with /
+	File string `json:"file"`
+	// SSLBindConfig are the options of the certificate as written, such as "alpn h2" or
+	// "verify required"
+	SSLBindConfig []string `json:"ssl_bind_config,omitempty"`
+	// SNIFilters are the server names the certificate is used for, negated with a leading !
+	SNIFilters []string `json:"sni_filters,omitempty"`
+}
+
+// crtListOptions are the options accepted in crt-list lines with their number of arguments
+var crtListOptions = map[string]int{
+	"allow-0rtt":   0,
+	"alpn":         1,
+	"ca-file":      1,
+	"ciphers":      1,
+	"ciphersuites": 1,
+	"crl-file":     1,
+	"curves":       1,
+	"ecdhe":        1,
+	"no-ca-names":  0,
+	"npn":          1,
+	"ssl-max-ver":  1,
+	"ssl-min-ver":  1,
+	"verify":       1,
+}
+
+// Validate checks the certificate path, the options and the SNI filters of the entry
+func (e *CrtListEntry) Validate() error {
+	if e.File == "" || strings.ContainsAny(e.File, " \t#[]") {
+		return fmt.Errorf("invalid crt-list certificate %s %w", e.File, native_errors.ErrGeneral)
+	}
+	for _, o := range e.SSLBindConfig {
+		words := strings.Fields(o)
+		if len(words) == 0 || strings.ContainsAny(o, "#[]") {
+			return fmt.Errorf("%s: invalid option %s %w", e.File, o, native_errors.ErrGeneral)
+		}
+		args, ok := crtListOptions[words[0]]
+		if !ok {
+			return fmt.Errorf("%s: unknown option %s %w", e.File, words[0], native_errors.ErrGeneral)
+		}
+		if len(words) != args+1 {
+			return fmt.Errorf("%s: option %s takes %d arguments %w", e.File, words[0], args, native_errors.ErrGeneral)
+		}
+		if words[0] == "verify" && !misc.StringInSlice(words[1], []string{"none", "optional", "required"}) {
+			return fmt.Errorf("%s: verify must be none, optional or required %w", e.File, native_errors.ErrGeneral)
+		}
+	}
+	for _, sni := range e.SNIFilters {
+		if strings.TrimPrefix(sni, "!") == "" || strings.ContainsAny(sni, " \t#[]") {
+			return fmt.Errorf("%s: invalid sni filter %s %w", e.File, sni, native_errors.ErrGeneral)
+		}
+	}
+	return nil
+}
+
+// String returns the crt-list line of the entry
+func (e *CrtListEntry) String() string {
+	words := []string{e.File}
+	if len(e.SSLBindConfig) > 0 {
+		words = append(words, "["+strings.Join(e.SSLBindConfig, " ")+"]")
+	}
+	return strings.Join(append(words, e.SNIFilters...), " ")
+}
+
+// ParseCrtList returns the entries of a crt-list file
+func ParseCrtList(content string) ([]*CrtListEntry, error) {
+	entries := []*CrtListEntry{}
+	for i, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		words := strings.Fields(line)
+		e := &CrtListEntry{File: words[0]}
+		rest := strings.TrimSpace(strings.TrimPrefix(line, words[0]))
+		if strings.HasPrefix(rest, "[") {
+			end := strings.Index(rest, "]")
+			if end == -1 {
+				return nil, fmt.Errorf("crt-list line %d: missing ] %w", i+1, native_errors.ErrGeneral)
+			}
+			e.SSLBindConfig = splitCrtListOptions(strings.Fields(rest[1:end]))
+			rest = rest[end+1:]
+		}
+		e.SNIFilters = strings.Fields(rest)
+		if err := e.Validate(); err != nil {
+			return nil, fmt.Errorf("crt-list line %d: %w", i+1, err)
+		}
+		entries = append(entries, e)
+	}
+	return entries, nil
+}
+
+// SerializeCrtList returns contents of a crt-list file
+func SerializeCrtList(entries []*CrtListEntry) string {
+	lines := make([]string, 0, len(entries))
+	for _, e := range entries {
+		lines = append(lines, e.String())
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+// splitCrtListOptions groups the words of the options with their arguments, unknown options
+// keep the following words until the next known option
+func splitCrtListOptions(words []string) []string {
+	options := []string{}
+	for i := 0; i < len(words); i++ {
+		option := []string{words[i]}
+		if args, ok := crtListOptions[words[i]]; ok {
+			for ; args > 0 && i+1 < len(words); args-- {
+				i++
+				option = append(option, words[i])
+			}
+		} else {
+			for i+1 < len(words) {
+				if _, ok := crtListOptions[words[i+1]]; ok {
+					break
+				}
+				i++
+				option = append(option, words[i])
+			}
+		}
+		options = append(options, strings.Join(option, " "))
+	}
+	return options
+}
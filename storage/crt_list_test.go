@@ -0,0 +1,97 @@
+// Copyright 2019 HAProxy Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package storage
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseSerializeCrtList(t *testing.T) {
+	content := `# certificates by server name
+/etc/haproxy/certs/site1.pem [alpn h2,http/1.1 verify required ssl-min-ver TLSv1.2] site1.example.com !www.site1.example.com
+
+site2.pem *.site2.example.com
+  default.pem
+`
+	expected := []*CrtListEntry{
+		{
+			File:          "/etc/haproxy/certs/site1.pem",
+			SSLBindConfig: []string{"alpn h2,http/1.1", "verify required", "ssl-min-ver TLSv1.2"},
+			SNIFilters:    []string{"site1.example.com", "!www.site1.example.com"},
+		},
+		{File: "site2.pem", SNIFilters: []string{"*.site2.example.com"}},
+		{File: "default.pem", SNIFilters: []string{}},
+	}
+	entries, err := ParseCrtList(content)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(entries, expected) {
+		for _, e := range entries {
+			t.Logf("parsed entry: %+v", *e)
+		}
+		t.Fatal("parsed crt-list entries not equal to expected")
+	}
+
+	serialized := SerializeCrtList(entries)
+	expectedContent := `/etc/haproxy/certs/site1.pem [alpn h2,http/1.1 verify required ssl-min-ver TLSv1.2] site1.example.com !www.site1.example.com
+site2.pem *.site2.example.com
+default.pem
+`
+	if serialized != expectedContent {
+		t.Errorf("serialized crt-list:\n%s\nexpected:\n%s", serialized, expectedContent)
+	}
+	reparsed, err := ParseCrtList(serialized)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(reparsed, entries) {
+		t.Error("crt-list entries changed by serialization")
+	}
+}
+
+func TestParseCrtListInvalid(t *testing.T) {
+	for _, line := range []string{
+		"site1.pem [alpn h2 site1.example.com",
+		"site1.pem [bogus-option] site1.example.com",
+		"site1.pem [verify maybe] site1.example.com",
+		"site1.pem [alpn] site1.example.com",
+		"site1.pem [no-ca-names] !",
+	} {
+		if _, err := ParseCrtList(line); err == nil {
+			t.Errorf("invalid crt-list line %q accepted", line)
+		}
+	}
+}
+
+func TestCrtListEntryValidate(t *testing.T) {
+	valid := &CrtListEntry{File: "site1.pem", SSLBindConfig: []string{"allow-0rtt", "ciphers ECDHE-RSA-AES128-GCM-SHA256"}, SNIFilters: []string{"site1.example.com"}}
+	if err := valid.Validate(); err != nil {
+		t.Errorf("valid entry rejected: %v", err)
+	}
+	for _, e := range []*CrtListEntry{
+		{File: ""},
+		{File: "site 1.pem"},
+		{File: "site1.pem", SSLBindConfig: []string{"alpn h2 # comment"}},
+		{File: "site1.pem", SSLBindConfig: []string{"allow-0rtt yes"}},
+		{File: "site1.pem", SNIFilters: []string{"site1.example.com]"}},
+	} {
+		if err := e.Validate(); err == nil {
+			t.Errorf("invalid entry %+v accepted", *e)
+		}
+	}
+}
@@ -0,0 +1,324 @@
+// Copyright 2019 HAProxy Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package storage
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	native_errors "github.com/haproxytech/client-native/v2/errors"
+)
+
+// EncryptedKeyBlockType is the PEM block type of private keys encrypted with AES-256-GCM. The
+// block holds the nonce followed by the sealed PEM encoded private key.
+const EncryptedKeyBlockType = "HAPROXY ENCRYPTED PRIVATE KEY"
+
+// KeyProvider returns the 32 bytes AES-256 key used to encrypt private keys
+type KeyProvider interface {
+	Key() ([]byte, error)
+}
+
+// StaticKey is a KeyProvider returning a fixed key
+type StaticKey []byte
+
+// Key returns the key
+func (k StaticKey) Key() ([]byte, error) {
+	return []byte(k), nil
+}
+
+// FileKey is a KeyProvider reading the key from a file, either as 32 raw bytes or hex encoded
+type FileKey string
+
+// Key reads the key from the file
+func (k FileKey) Key() ([]byte, error) {
+	data, err := ioutil.ReadFile(string(k))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) == 32 {
+		return data, nil
+	}
+	key, err := hex.DecodeString(strings.TrimSpace(string(data)))
+	if err != nil {
+		return nil, fmt.Errorf("key file %s is neither 32 bytes nor hex encoded %w", string(k), native_errors.ErrGeneral)
+	}
+	return key, nil
+}
+
+// EncryptPrivateKeys encrypts the private keys of a PEM bundle, other blocks are kept as they are
+func EncryptPrivateKeys(bundle string, key []byte) (string, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return "", err
+	}
+	return mapPEMBlocks(bundle, func(block *pem.Block) (*pem.Block, error) {
+		if block.Type == EncryptedKeyBlockType || !strings.HasSuffix(block.Type, "PRIVATE KEY") {
+			return block, nil
+		}
+		nonce := make([]byte, gcm.NonceSize())
+		if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+			return nil, err
+		}
+		sealed := gcm.Seal(nonce, nonce, pem.EncodeToMemory(block), []byte(EncryptedKeyBlockType))
+		return &pem.Block{Type: EncryptedKeyBlockType, Bytes: sealed}, nil
+	})
+}
+
+// DecryptPrivateKeys decrypts the private keys of a PEM bundle encrypted with EncryptPrivateKeys
+func DecryptPrivateKeys(bundle string, key []byte) (string, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return "", err
+	}
+	return mapPEMBlocks(bundle, func(block *pem.Block) (*pem.Block, error) {
+		if block.Type != EncryptedKeyBlockType {
+			return block, nil
+		}
+		if len(block.Bytes) < gcm.NonceSize() {
+			return nil, fmt.Errorf("encrypted private key too short %w", native_errors.ErrGeneral)
+		}
+		nonce, sealed := block.Bytes[:gcm.NonceSize()], block.Bytes[gcm.NonceSize():]
+		data, err := gcm.Open(nil, nonce, sealed, []byte(EncryptedKeyBlockType))
+		if err != nil {
+			return nil, fmt.Errorf("cannot decrypt private key: %s %w", err.Error(), native_errors.ErrGeneral)
+		}
+		decrypted, _ := pem.Decode(data)
+		if decrypted == nil {
+			return nil, fmt.Errorf("decrypted private key is not PEM encoded %w", native_errors.ErrGeneral)
+		}
+		return decrypted, nil
+	})
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	if len(key) != 32 {
+		return nil, fmt.Errorf("encryption key must be 32 bytes %w", native_errors.ErrGeneral)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// mapPEMBlocks re-encodes all PEM blocks of data after passing them through fn
+func mapPEMBlocks(data string, fn func(block *pem.Block) (*pem.Block, error)) (string, error) {
+	var result bytes.Buffer
+	rest := []byte(data)
+	for {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		block, err := fn(block)
+		if err != nil {
+			return "", err
+		}
+		if err := pem.Encode(&result, block); err != nil {
+			return "", err
+		}
+	}
+	return result.String(), nil
+}
+
+// EncryptedSSLStorage is a certificate storage keeping private keys encrypted on disk.
+// HAProxy can not read the stored files, decrypted copies are written to the runtime
+// directory, which should be on tmpfs, for HAProxy to load on reload.
+type EncryptedSSLStorage struct {
+	SSLStorage
+	keys    KeyProvider
+	runtime *storage
+}
+
+// NewEncryptedSSLStorage returns a certificate storage encrypting private keys with the key
+// from keys, with decrypted copies written to runtimeDir
+func NewEncryptedSSLStorage(dirname, runtimeDir string, keys KeyProvider) (*EncryptedSSLStorage, error) {
+	if keys == nil {
+		return nil, fmt.Errorf("key provider not specified %w", native_errors.ErrGeneral)
+	}
+	if filepath.Clean(dirname) == filepath.Clean(runtimeDir) {
+		return nil, fmt.Errorf("runtime directory must differ from the storage directory %w", native_errors.ErrGeneral)
+	}
+	s, err := NewSSLStorage(dirname)
+	if err != nil {
+		return nil, err
+	}
+	r, err := newStorage(runtimeDir, SSLType)
+	if err != nil {
+		return nil, err
+	}
+	return &EncryptedSSLStorage{
+		SSLStorage: s,
+		keys:       keys,
+		runtime:    r,
+	}, nil
+}
+
+// Create encrypts the private keys and writes a new file to storage
+func (s *EncryptedSSLStorage) Create(name string, readCloser io.ReadCloser) (string, error) {
+	defer readCloser.Close()
+	data, err := ioutil.ReadAll(readCloser)
+	if err != nil {
+		return "", err
+	}
+	encrypted, err := s.encrypt(string(data))
+	if err != nil {
+		return "", err
+	}
+	return s.SSLStorage.Create(name, ioutil.NopCloser(strings.NewReader(encrypted)))
+}
+
+// Replace encrypts the private keys and overwrites an existing file in storage
+func (s *EncryptedSSLStorage) Replace(name string, config string) (string, error) {
+	encrypted, err := s.encrypt(config)
+	if err != nil {
+		return "", err
+	}
+	return s.SSLStorage.Replace(name, encrypted)
+}
+
+// Delete removes the file from storage and its decrypted copy
+func (s *EncryptedSSLStorage) Delete(name string) error {
+	if err := s.SSLStorage.Delete(name); err != nil {
+		return err
+	}
+	if err := s.runtime.Delete(name); err != nil && !errors.Is(err, native_errors.ErrNotFound) {
+		return err
+	}
+	path, err := s.runtime.path(name)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path + OCSPSuffix); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// StoreCertificate assembles the PEM bundle like SSLStorage and stores it with the private
+// key encrypted
+func (s *EncryptedSSLStorage) StoreCertificate(name, cert, chain, key string) (string, error) {
+	bundle, err := BuildPEMBundle(cert, chain, key)
+	if err != nil {
+		return "", err
+	}
+	f, err := s.Replace(name, bundle)
+	if errors.Is(err, native_errors.ErrNotFound) {
+		return s.Create(name, ioutil.NopCloser(strings.NewReader(bundle)))
+	}
+	return f, err
+}
+
+// GetDecrypted returns the stored PEM bundle with its private key decrypted, as needed for the
+// payload of set ssl cert
+func (s *EncryptedSSLStorage) GetDecrypted(name string) (string, error) {
+	f, err := s.Get(name)
+	if err != nil {
+		return "", err
+	}
+	data, err := ioutil.ReadFile(f)
+	if err != nil {
+		return "", err
+	}
+	key, err := s.keys.Key()
+	if err != nil {
+		return "", err
+	}
+	return DecryptPrivateKeys(string(data), key)
+}
+
+// RuntimePath returns the path of the decrypted copy HAProxy loads
+func (s *EncryptedSSLStorage) RuntimePath(name string) (string, error) {
+	if _, err := s.Get(name); err != nil {
+		return "", err
+	}
+	return s.runtime.path(name)
+}
+
+// WriteRuntimeFile writes the decrypted copy of the stored file with its OCSP response and returns
+// its path
+func (s *EncryptedSSLStorage) WriteRuntimeFile(name string) (string, error) {
+	bundle, err := s.GetDecrypted(name)
+	if err != nil {
+		return "", err
+	}
+	f, err := s.runtime.Replace(name, bundle)
+	if errors.Is(err, native_errors.ErrNotFound) {
+		f, err = s.runtime.Create(name, ioutil.NopCloser(strings.NewReader(bundle)))
+	}
+	if err != nil {
+		return "", err
+	}
+	if _, err := s.writeRuntimeOCSPResponse(name); err != nil {
+		return "", err
+	}
+	return f, nil
+}
+
+// WriteRuntimeFiles writes decrypted copies of all stored files before HAProxy is reloaded,
+// removing copies of files no longer in storage. Returns the paths of the copies.
+func (s *EncryptedSSLStorage) WriteRuntimeFiles() ([]string, error) {
+	files, err := s.GetAll()
+	if err != nil {
+		return nil, err
+	}
+	stored := map[string]bool{}
+	result := []string{}
+	for _, f := range files {
+		name := filepath.Base(f)
+		stored[name] = true
+		path, err := s.WriteRuntimeFile(name)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, path)
+	}
+
+	copies, err := s.runtime.GetAll()
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range copies {
+		if !stored[filepath.Base(f)] {
+			for _, file := range []string{f, f + OCSPSuffix} {
+				if err := os.Remove(file); err != nil && !os.IsNotExist(err) {
+					return nil, err
+				}
+			}
+		}
+	}
+	return result, nil
+}
+
+func (s *EncryptedSSLStorage) encrypt(bundle string) (string, error) {
+	key, err := s.keys.Key()
+	if err != nil {
+		return "", err
+	}
+	return EncryptPrivateKeys(bundle, key)
+}
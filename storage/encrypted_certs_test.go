@@ -0,0 +1,260 @@
+// Copyright 2019 HAProxy Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package storage
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testCertificate returns a PEM encoded self-signed certificate with its private key
+func testCertificate(t *testing.T, name string) (string, string, *x509.Certificate) {
+	return testCertificateExpiring(t, name, time.Now().Add(24*time.Hour))
+}
+
+// testCertificateExpiring returns a PEM encoded self-signed certificate valid until notAfter
+// with its private key
+func testCertificateExpiring(t *testing.T, name string, notAfter time.Time) (string, string, *x509.Certificate) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 64))
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: name},
+		DNSNames:     []string{name},
+		NotBefore:    notAfter.Add(-48 * time.Hour),
+		NotAfter:     notAfter,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+	return string(certPEM), string(keyPEM), cert
+}
+
+func testKey(b byte) []byte {
+	key := make([]byte, 32)
+	for i := range key {
+		key[i] = b
+	}
+	return key
+}
+
+func TestEncryptDecryptPrivateKeys(t *testing.T) {
+	certPEM, keyPEM, _ := testCertificate(t, "example.com")
+	bundle := certPEM + keyPEM
+
+	encrypted, err := EncryptPrivateKeys(bundle, testKey(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(encrypted, "EC PRIVATE KEY") || strings.Contains(encrypted, strings.Split(keyPEM, "\n")[1]) {
+		t.Error("private key not encrypted")
+	}
+	if !strings.HasPrefix(encrypted, certPEM) {
+		t.Errorf("certificate block changed by encryption:\n%s", encrypted)
+	}
+	if !strings.Contains(encrypted, EncryptedKeyBlockType) {
+		t.Errorf("%s block missing:\n%s", EncryptedKeyBlockType, encrypted)
+	}
+
+	// encrypting again keeps the encrypted block
+	again, err := EncryptPrivateKeys(encrypted, testKey(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again != encrypted {
+		t.Error("encrypted private key encrypted twice")
+	}
+
+	decrypted, err := DecryptPrivateKeys(encrypted, testKey(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if decrypted != bundle {
+		t.Errorf("decrypted bundle differs from original:\n%s\nexpected:\n%s", decrypted, bundle)
+	}
+}
+
+func TestDecryptPrivateKeysWrongKey(t *testing.T) {
+	certPEM, keyPEM, _ := testCertificate(t, "example.com")
+	encrypted, err := EncryptPrivateKeys(certPEM+keyPEM, testKey(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := DecryptPrivateKeys(encrypted, testKey(2)); err == nil {
+		t.Error("decryption with wrong key succeeded")
+	}
+	if _, err := DecryptPrivateKeys(encrypted, testKey(1)[:16]); err == nil {
+		t.Error("decryption with short key succeeded")
+	}
+}
+
+func TestDecryptPrivateKeysTampered(t *testing.T) {
+	certPEM, keyPEM, _ := testCertificate(t, "example.com")
+	encrypted, err := EncryptPrivateKeys(certPEM+keyPEM, testKey(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tampered, err := mapPEMBlocks(encrypted, func(block *pem.Block) (*pem.Block, error) {
+		if block.Type == EncryptedKeyBlockType {
+			block.Bytes[len(block.Bytes)/2] ^= 0xff
+		}
+		return block, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := DecryptPrivateKeys(tampered, testKey(1)); err == nil {
+		t.Error("decryption of tampered private key succeeded")
+	}
+
+	truncated, err := mapPEMBlocks(encrypted, func(block *pem.Block) (*pem.Block, error) {
+		if block.Type == EncryptedKeyBlockType {
+			block.Bytes = block.Bytes[:4]
+		}
+		return block, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := DecryptPrivateKeys(truncated, testKey(1)); err == nil {
+		t.Error("decryption of truncated private key succeeded")
+	}
+}
+
+func TestEncryptPrivateKeysOtherBlocks(t *testing.T) {
+	certPEM, _, _ := testCertificate(t, "example.com")
+	chainPEM, _, _ := testCertificate(t, "ca.example.com")
+	params := string(pem.EncodeToMemory(&pem.Block{Type: "DH PARAMETERS", Bytes: []byte{1, 2, 3}}))
+	bundle := certPEM + chainPEM + params
+
+	encrypted, err := EncryptPrivateKeys(bundle, testKey(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if encrypted != bundle {
+		t.Errorf("bundle without private key changed by encryption:\n%s", encrypted)
+	}
+	decrypted, err := DecryptPrivateKeys(bundle, testKey(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if decrypted != bundle {
+		t.Errorf("bundle without private key changed by decryption:\n%s", decrypted)
+	}
+}
+
+func TestEncryptedSSLStorageRuntimeFiles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "encrypted-certs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	s, err := NewEncryptedSSLStorage(filepath.Join(dir, "certs"), filepath.Join(dir, "runtime"), StaticKey(testKey(1)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"site1", "site2"} {
+		certPEM, keyPEM, _ := testCertificate(t, name+".example.com")
+		f, err := s.StoreCertificate(name, certPEM, "", keyPEM)
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, err := ioutil.ReadFile(f)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if strings.Contains(string(data), "EC PRIVATE KEY") {
+			t.Errorf("%s: private key stored unencrypted", f)
+		}
+	}
+
+	stale := filepath.Join(dir, "runtime", "removed.pem")
+	for _, f := range []string{stale, stale + OCSPSuffix} {
+		if err := ioutil.WriteFile(f, []byte("stale"), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	copies, err := s.WriteRuntimeFiles()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(copies) != 2 {
+		t.Errorf("%v runtime files written, expected 2", len(copies))
+	}
+	for _, f := range copies {
+		data, err := ioutil.ReadFile(f)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(string(data), "EC PRIVATE KEY") {
+			t.Errorf("%s: private key not decrypted", f)
+		}
+	}
+	for _, f := range []string{stale, stale + OCSPSuffix} {
+		if _, err := os.Stat(f); !os.IsNotExist(err) {
+			t.Errorf("%s: stale runtime file not removed", f)
+		}
+	}
+
+	// a file removed from storage outside of the storage loses its runtime copy
+	f, err := s.Get("site2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(f); err != nil {
+		t.Fatal(err)
+	}
+	copies, err = s.WriteRuntimeFiles()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(copies) != 1 || filepath.Base(copies[0]) != "site1.pem" {
+		t.Errorf("runtime files %v written, expected site1.pem", copies)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "runtime", "site2.pem")); !os.IsNotExist(err) {
+		t.Error("runtime copy of removed certificate not removed")
+	}
+}
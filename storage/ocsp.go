@@ -0,0 +1,282 @@
+// Copyright 2019 HAProxy Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package storage
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"time"
+
+	// hash functions of OCSP certificate ids
+	_ "crypto/sha1"
+	_ "crypto/sha256"
+	_ "crypto/sha512"
+
+	native_errors "github.com/haproxytech/client-native/v2/errors"
+)
+
+// OCSPSuffix is appended to the certificate file name for the OCSP response HAProxy staples
+const OCSPSuffix = ".ocsp"
+
+// OCSPResponseInfo is the status of a certificate in an OCSP response
+type OCSPResponseInfo struct {
+	// File is the path of the OCSP response file
+	File string `json:"file"`
+	// Status is good, revoked or unknown
+	Status     string    `json:"status"`
+	ThisUpdate time.Time `json:"this_update"`
+	// NextUpdate is zero when the responder does not tell when newer information is available
+	NextUpdate time.Time `json:"next_update,omitempty"`
+}
+
+// ASN.1 structures of RFC 6960 OCSP responses
+type ocspResponse struct {
+	Status   asn1.Enumerated
+	Response ocspResponseBytes `asn1:"explicit,tag:0,optional"`
+}
+
+type ocspResponseBytes struct {
+	ResponseType asn1.ObjectIdentifier
+	Response     []byte
+}
+
+type basicOCSPResponse struct {
+	TBSResponseData    ocspResponseData
+	SignatureAlgorithm pkix.AlgorithmIdentifier
+	Signature          asn1.BitString
+	Certificates       []asn1.RawValue `asn1:"explicit,tag:0,optional"`
+}
+
+type ocspResponseData struct {
+	Raw            asn1.RawContent
+	Version        int `asn1:"optional,default:0,explicit,tag:0"`
+	RawResponderID asn1.RawValue
+	ProducedAt     time.Time `asn1:"generalized"`
+	Responses      []ocspSingleResponse
+}
+
+type ocspSingleResponse struct {
+	CertID           ocspCertID
+	Good             asn1.Flag        `asn1:"tag:0,optional"`
+	Revoked          ocspRevokedInfo  `asn1:"tag:1,optional"`
+	Unknown          asn1.Flag        `asn1:"tag:2,optional"`
+	ThisUpdate       time.Time        `asn1:"generalized"`
+	NextUpdate       time.Time        `asn1:"generalized,explicit,tag:0,optional"`
+	SingleExtensions []pkix.Extension `asn1:"explicit,tag:1,optional"`
+}
+
+type ocspCertID struct {
+	HashAlgorithm pkix.AlgorithmIdentifier
+	NameHash      []byte
+	IssuerKeyHash []byte
+	SerialNumber  *big.Int
+}
+
+type ocspRevokedInfo struct {
+	RevocationTime time.Time       `asn1:"generalized"`
+	Reason         asn1.Enumerated `asn1:"explicit,tag:0,optional"`
+}
+
+var (
+	oidOCSPBasic = asn1.ObjectIdentifier{1, 3, 6, 1, 5, 5, 7, 48, 1, 1}
+	ocspHashes   = map[string]crypto.Hash{
+		"1.3.14.3.2.26":          crypto.SHA1,
+		"2.16.840.1.101.3.4.2.1": crypto.SHA256,
+		"2.16.840.1.101.3.4.2.2": crypto.SHA384,
+		"2.16.840.1.101.3.4.2.3": crypto.SHA512,
+	}
+)
+
+// ParseOCSPResponse returns the status of the certificate in the DER encoded OCSP response.
+// Returns error if the response is not successful or has no status for the certificate. The
+// signature of the response is not verified, HAProxy staples it as it is.
+func ParseOCSPResponse(der []byte, cert *x509.Certificate) (*OCSPResponseInfo, error) {
+	var resp ocspResponse
+	if rest, err := asn1.Unmarshal(der, &resp); err != nil || len(rest) > 0 {
+		return nil, fmt.Errorf("invalid OCSP response %w", native_errors.ErrGeneral)
+	}
+	if resp.Status != 0 {
+		return nil, fmt.Errorf("OCSP response status %d is not successful %w", resp.Status, native_errors.ErrGeneral)
+	}
+	if !resp.Response.ResponseType.Equal(oidOCSPBasic) {
+		return nil, fmt.Errorf("OCSP response type %s is not basic %w", resp.Response.ResponseType, native_errors.ErrGeneral)
+	}
+	var basic basicOCSPResponse
+	if _, err := asn1.Unmarshal(resp.Response.Response, &basic); err != nil {
+		return nil, fmt.Errorf("invalid basic OCSP response: %s %w", err.Error(), native_errors.ErrGeneral)
+	}
+
+	for _, r := range basic.TBSResponseData.Responses {
+		if r.CertID.SerialNumber == nil || r.CertID.SerialNumber.Cmp(cert.SerialNumber) != 0 {
+			continue
+		}
+		hash, ok := ocspHashes[r.CertID.HashAlgorithm.Algorithm.String()]
+		if !ok || !hash.Available() {
+			return nil, fmt.Errorf("unsupported OCSP certificate id hash %s %w", r.CertID.HashAlgorithm.Algorithm, native_errors.ErrGeneral)
+		}
+		h := hash.New()
+		h.Write(cert.RawIssuer)
+		if !bytes.Equal(h.Sum(nil), r.CertID.NameHash) {
+			continue
+		}
+		info := &OCSPResponseInfo{
+			Status:     "unknown",
+			ThisUpdate: r.ThisUpdate,
+			NextUpdate: r.NextUpdate,
+		}
+		switch {
+		case bool(r.Good):
+			info.Status = "good"
+		case !r.Revoked.RevocationTime.IsZero():
+			info.Status = "revoked"
+		}
+		return info, nil
+	}
+	return nil, fmt.Errorf("OCSP response does not match certificate %s %w", cert.SerialNumber, native_errors.ErrGeneral)
+}
+
+// StoreOCSPResponse writes the DER encoded OCSP response next to the certificate in storage, where
+// HAProxy loads it on reload. Returns error if the response does not match the certificate.
+func (s *sslStorage) StoreOCSPResponse(name string, response []byte) (string, error) {
+	f, err := s.Get(name)
+	if err != nil {
+		return "", err
+	}
+	if _, err := checkOCSPResponse(f, response); err != nil {
+		return "", err
+	}
+	if err := writeFile(f+OCSPSuffix, response, 0644); err != nil {
+		return "", err
+	}
+	return f + OCSPSuffix, nil
+}
+
+// GetOCSPResponseInfo returns the status of the certificate in its OCSP response file
+func (s *sslStorage) GetOCSPResponseInfo(name string) (*OCSPResponseInfo, error) {
+	f, err := s.Get(name)
+	if err != nil {
+		return nil, err
+	}
+	response, err := ioutil.ReadFile(f + OCSPSuffix)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("OCSP response of %s %w", name, native_errors.ErrNotFound)
+		}
+		return nil, err
+	}
+	return checkOCSPResponse(f, response)
+}
+
+// ValidateOCSPResponses checks the OCSP response files in storage match their certificates and
+// are not past their next update
+func (s *sslStorage) ValidateOCSPResponses() error {
+	files, err := s.GetAll()
+	if err != nil {
+		return err
+	}
+	for _, f := range files {
+		response, err := ioutil.ReadFile(f + OCSPSuffix)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		info, err := checkOCSPResponse(f, response)
+		if err != nil {
+			return err
+		}
+		if !info.NextUpdate.IsZero() && info.NextUpdate.Before(time.Now()) {
+			return fmt.Errorf("%s: OCSP response expired on %s %w", info.File, info.NextUpdate, native_errors.ErrGeneral)
+		}
+	}
+	return nil
+}
+
+// Delete removes the certificate and its OCSP response from storage
+func (s *sslStorage) Delete(name string) error {
+	f, err := s.Get(name)
+	if err != nil {
+		return err
+	}
+	if err := s.Storage.Delete(name); err != nil {
+		return err
+	}
+	if err := os.Remove(f + OCSPSuffix); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+func checkOCSPResponse(certFile string, response []byte) (*OCSPResponseInfo, error) {
+	data, err := ioutil.ReadFile(certFile)
+	if err != nil {
+		return nil, err
+	}
+	cert, err := parseLeafCertificate(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %s %w", certFile, err.Error(), native_errors.ErrGeneral)
+	}
+	info, err := ParseOCSPResponse(response, cert)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", certFile+OCSPSuffix, err)
+	}
+	info.File = certFile + OCSPSuffix
+	return info, nil
+}
+
+// StoreOCSPResponse stores the OCSP response like SSLStorage and copies it next to the decrypted
+// certificate HAProxy loads. Returns the path of the copy.
+func (s *EncryptedSSLStorage) StoreOCSPResponse(name string, response []byte) (string, error) {
+	if _, err := s.SSLStorage.StoreOCSPResponse(name, response); err != nil {
+		return "", err
+	}
+	return s.writeRuntimeOCSPResponse(name)
+}
+
+// writeRuntimeOCSPResponse copies the stored OCSP response of the certificate next to its
+// decrypted copy, removing a stale copy if the certificate has no response
+func (s *EncryptedSSLStorage) writeRuntimeOCSPResponse(name string) (string, error) {
+	f, err := s.Get(name)
+	if err != nil {
+		return "", err
+	}
+	path, err := s.runtime.path(name)
+	if err != nil {
+		return "", err
+	}
+	response, err := ioutil.ReadFile(f + OCSPSuffix)
+	if err != nil {
+		if os.IsNotExist(err) {
+			if err := os.Remove(path + OCSPSuffix); err != nil && !os.IsNotExist(err) {
+				return "", err
+			}
+			return "", nil
+		}
+		return "", err
+	}
+	if err := writeFile(path+OCSPSuffix, response, 0644); err != nil {
+		return "", err
+	}
+	return path + OCSPSuffix, nil
+}
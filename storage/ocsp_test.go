@@ -0,0 +1,166 @@
+// Copyright 2019 HAProxy Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package storage
+
+import (
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+)
+
+var oidSHA256 = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 1}
+
+// testOCSPResponse returns a DER encoded OCSP response with the single response for the
+// certificate, the signature is not valid
+func testOCSPResponse(t *testing.T, cert *x509.Certificate, single ocspSingleResponse) []byte {
+	nameHash := sha256.Sum256(cert.RawIssuer)
+	single.CertID = ocspCertID{
+		HashAlgorithm: pkix.AlgorithmIdentifier{Algorithm: oidSHA256},
+		NameHash:      nameHash[:],
+		IssuerKeyHash: make([]byte, sha256.Size),
+		SerialNumber:  cert.SerialNumber,
+	}
+	responderID, err := asn1.Marshal(make([]byte, sha256.Size))
+	if err != nil {
+		t.Fatal(err)
+	}
+	basic, err := asn1.Marshal(basicOCSPResponse{
+		TBSResponseData: ocspResponseData{
+			RawResponderID: asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 2, IsCompound: true, Bytes: responderID},
+			ProducedAt:     single.ThisUpdate,
+			Responses:      []ocspSingleResponse{single},
+		},
+		SignatureAlgorithm: pkix.AlgorithmIdentifier{Algorithm: asn1.ObjectIdentifier{1, 2, 840, 10045, 4, 3, 2}},
+		Signature:          asn1.BitString{Bytes: []byte{0}, BitLength: 8},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := asn1.Marshal(ocspResponse{Response: ocspResponseBytes{ResponseType: oidOCSPBasic, Response: basic}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return der
+}
+
+func TestParseOCSPResponse(t *testing.T) {
+	_, _, cert := testCertificate(t, "example.com")
+	thisUpdate := time.Now().Add(-time.Hour).UTC().Truncate(time.Second)
+	nextUpdate := thisUpdate.Add(48 * time.Hour)
+
+	tests := []struct {
+		status string
+		single ocspSingleResponse
+	}{
+		{"good", ocspSingleResponse{Good: true, ThisUpdate: thisUpdate, NextUpdate: nextUpdate}},
+		{"revoked", ocspSingleResponse{Revoked: ocspRevokedInfo{RevocationTime: thisUpdate}, ThisUpdate: thisUpdate, NextUpdate: nextUpdate}},
+		{"unknown", ocspSingleResponse{Unknown: true, ThisUpdate: thisUpdate}},
+	}
+	for _, test := range tests {
+		info, err := ParseOCSPResponse(testOCSPResponse(t, cert, test.single), cert)
+		if err != nil {
+			t.Errorf("%s: %v", test.status, err)
+			continue
+		}
+		if info.Status != test.status {
+			t.Errorf("status %s returned, expected %s", info.Status, test.status)
+		}
+		if !info.ThisUpdate.Equal(thisUpdate) {
+			t.Errorf("%s: this update %s returned, expected %s", test.status, info.ThisUpdate, thisUpdate)
+		}
+		if !info.NextUpdate.Equal(test.single.NextUpdate) {
+			t.Errorf("%s: next update %s returned, expected %s", test.status, info.NextUpdate, test.single.NextUpdate)
+		}
+	}
+}
+
+func TestParseOCSPResponseInvalid(t *testing.T) {
+	_, _, cert := testCertificate(t, "example.com")
+	_, _, other := testCertificate(t, "other.example.com")
+	der := testOCSPResponse(t, cert, ocspSingleResponse{Good: true, ThisUpdate: time.Now().UTC().Truncate(time.Second)})
+
+	if _, err := ParseOCSPResponse(der, other); err == nil {
+		t.Error("OCSP response of another certificate accepted")
+	}
+	if _, err := ParseOCSPResponse(der[:len(der)-1], cert); err == nil {
+		t.Error("truncated OCSP response accepted")
+	}
+	if _, err := ParseOCSPResponse([]byte("not an OCSP response"), cert); err == nil {
+		t.Error("invalid OCSP response accepted")
+	}
+	// tryLater status without response bytes
+	unsuccessful, err := asn1.Marshal(struct{ Status asn1.Enumerated }{Status: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ParseOCSPResponse(unsuccessful, cert); err == nil {
+		t.Error("unsuccessful OCSP response accepted")
+	}
+}
+
+func TestStoreOCSPResponse(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ocsp")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	s, err := NewSSLStorage(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certPEM, keyPEM, cert := testCertificate(t, "example.com")
+	if _, err := s.StoreCertificate("site1", certPEM, "", keyPEM); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.GetOCSPResponseInfo("site1"); err == nil {
+		t.Error("OCSP response info returned before storing a response")
+	}
+
+	_, _, other := testCertificate(t, "other.example.com")
+	thisUpdate := time.Now().Add(-2 * time.Hour).UTC().Truncate(time.Second)
+	if _, err := s.StoreOCSPResponse("site1", testOCSPResponse(t, other, ocspSingleResponse{Good: true, ThisUpdate: thisUpdate})); err == nil {
+		t.Error("OCSP response of another certificate stored")
+	}
+
+	expired := testOCSPResponse(t, cert, ocspSingleResponse{Good: true, ThisUpdate: thisUpdate, NextUpdate: thisUpdate.Add(time.Hour)})
+	f, err := s.StoreOCSPResponse("site1", expired)
+	if err != nil {
+		t.Fatal(err)
+	}
+	info, err := s.GetOCSPResponseInfo("site1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.File != f || info.Status != "good" {
+		t.Errorf("OCSP response info %+v returned, expected good status in %s", *info, f)
+	}
+	if err := s.ValidateOCSPResponses(); err == nil {
+		t.Error("expired OCSP response validated")
+	}
+
+	if err := s.Delete("site1"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(f); !os.IsNotExist(err) {
+		t.Error("OCSP response not removed with its certificate")
+	}
+}
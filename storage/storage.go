@@ -0,0 +1,200 @@
+// Copyright 2019 HAProxy Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package storage
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	native_errors "github.com/haproxytech/client-native/v2/errors"
+)
+
+// FileType represents the kind of files kept in a storage directory
+type FileType string
+
+const (
+	// MapsType storage for map files
+	MapsType FileType = "maps"
+	// GeneralType storage for any other file used by the configuration
+	GeneralType FileType = "general"
+	// SSLType storage for PEM certificates with their private keys
+	SSLType FileType = "certs"
+	// TLSTicketKeysType storage for TLS session ticket key files
+	TLSTicketKeysType FileType = "tls-ticket-keys"
+	// CrtListType storage for crt-list files selecting certificates by SNI
+	CrtListType FileType = "crt-lists"
+	// CAType storage for CA and CRL files verifying client and server certificates
+	CAType FileType = "ca-files"
+	// SpoeType storage for SPOE configuration files referenced by spoe filters
+	SpoeType FileType = "spoe"
+)
+
+// extensions are default extensions of file types, files without it are ignored
+var extensions = map[FileType]string{
+	MapsType: ".map",
+	SSLType:  ".pem",
+}
+
+// Storage manages files referenced from HAProxy configuration in one directory
+type Storage interface {
+	GetAll() ([]string, error)
+	Get(name string) (string, error)
+	Delete(name string) error
+	Replace(name string, config string) (string, error)
+	Create(name string, readCloser io.ReadCloser) (string, error)
+}
+
+type storage struct {
+	dirname  string
+	fileType FileType
+}
+
+// New returns a storage for the given directory, creating the directory if it does not exist
+func New(dirname string, fileType FileType) (Storage, error) {
+	return newStorage(dirname, fileType)
+}
+
+func newStorage(dirname string, fileType FileType) (*storage, error) {
+	if dirname == "" {
+		return nil, fmt.Errorf("storage directory for %s not specified %w", fileType, native_errors.ErrGeneral)
+	}
+	if err := os.MkdirAll(dirname, 0755); err != nil {
+		return nil, fmt.Errorf("%s %w", err.Error(), native_errors.ErrGeneral)
+	}
+	return &storage{
+		dirname:  dirname,
+		fileType: fileType,
+	}, nil
+}
+
+// GetAll returns paths of all files in storage
+func (s *storage) GetAll() ([]string, error) {
+	files, err := ioutil.ReadDir(s.dirname)
+	if err != nil {
+		return nil, err
+	}
+	result := []string{}
+	for _, f := range files {
+		if f.IsDir() || strings.HasPrefix(f.Name(), ".") {
+			continue
+		}
+		if ext, ok := extensions[s.fileType]; ok && filepath.Ext(f.Name()) != ext {
+			continue
+		}
+		result = append(result, filepath.Join(s.dirname, f.Name()))
+	}
+	return result, nil
+}
+
+// Get returns the path of the file in storage
+func (s *storage) Get(name string) (string, error) {
+	f, err := s.path(name)
+	if err != nil {
+		return "", err
+	}
+	if _, err := os.Stat(f); err != nil {
+		if os.IsNotExist(err) {
+			return "", fmt.Errorf("file %s %w", name, native_errors.ErrNotFound)
+		}
+		return "", err
+	}
+	return f, nil
+}
+
+// Delete removes the file from storage
+func (s *storage) Delete(name string) error {
+	f, err := s.Get(name)
+	if err != nil {
+		return err
+	}
+	return os.Remove(f)
+}
+
+// Replace overwrites the contents of an existing file in storage and returns its path
+func (s *storage) Replace(name string, config string) (string, error) {
+	f, err := s.Get(name)
+	if err != nil {
+		return "", err
+	}
+	if err := writeFile(f, []byte(config), s.perm()); err != nil {
+		return "", err
+	}
+	return f, nil
+}
+
+// Create writes a new file to storage and returns its path. Returns error if the file already exists
+func (s *storage) Create(name string, readCloser io.ReadCloser) (string, error) {
+	defer readCloser.Close()
+	f, err := s.path(name)
+	if err != nil {
+		return "", err
+	}
+	if _, err := os.Stat(f); err == nil {
+		return "", fmt.Errorf("file %s %w", name, native_errors.ErrAlreadyExists)
+	}
+	data, err := ioutil.ReadAll(readCloser)
+	if err != nil {
+		return "", err
+	}
+	if err := writeFile(f, data, s.perm()); err != nil {
+		return "", err
+	}
+	return f, nil
+}
+
+// path returns the full path of a file in storage, adding the default extension for the file type
+func (s *storage) path(name string) (string, error) {
+	if name == "" || filepath.Base(name) != name || name == "." || name == ".." {
+		return "", fmt.Errorf("invalid file name %s %w", name, native_errors.ErrGeneral)
+	}
+	if ext, ok := extensions[s.fileType]; ok && filepath.Ext(name) != ext {
+		name = fmt.Sprintf("%s%s", name, ext)
+	}
+	return filepath.Join(s.dirname, name), nil
+}
+
+// perm returns file mode of files in storage, certificates and ticket keys are secret
+func (s *storage) perm() os.FileMode {
+	if s.fileType == SSLType || s.fileType == TLSTicketKeysType {
+		return 0600
+	}
+	return 0644
+}
+
+// writeFile writes data to a temporary file and renames it to dest, so HAProxy
+// never reads a partially written file
+func writeFile(dest string, data []byte, perm os.FileMode) error {
+	tmp, err := ioutil.TempFile(filepath.Dir(dest), fmt.Sprintf(".%s.", filepath.Base(dest)))
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), perm); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), dest)
+}
@@ -0,0 +1,99 @@
+// Copyright 2019 HAProxy Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package storage
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	native_errors "github.com/haproxytech/client-native/v2/errors"
+)
+
+// TLSTicketKeysNo is the number of ticket keys HAProxy uses, the last ones of the file.
+// The penultimate key encrypts new tickets, all of them decrypt.
+const TLSTicketKeysNo = 3
+
+// TLSTicketKeySize is the size in bytes of generated ticket keys, for AES-256 tickets
+const TLSTicketKeySize = 80
+
+// NewTLSTicketKey returns a new random base64 encoded ticket key
+func NewTLSTicketKey() (string, error) {
+	key := make([]byte, TLSTicketKeySize)
+	if _, err := rand.Read(key); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(key), nil
+}
+
+// NewTLSTicketKeys returns contents of a new ticket keys file with TLSTicketKeysNo random keys
+func NewTLSTicketKeys() (string, error) {
+	keys := make([]string, 0, TLSTicketKeysNo)
+	for i := 0; i < TLSTicketKeysNo; i++ {
+		key, err := NewTLSTicketKey()
+		if err != nil {
+			return "", err
+		}
+		keys = append(keys, key)
+	}
+	return SerializeTLSTicketKeys(keys), nil
+}
+
+// ParseTLSTicketKeys returns the keys of a ticket keys file. Returns error if a key is not base64
+// encoded, is not 48 or 80 bytes long, keys of different sizes are mixed or there are less
+// than TLSTicketKeysNo keys.
+func ParseTLSTicketKeys(content string) ([]string, error) {
+	keys := []string{}
+	size := 0
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		key, err := base64.StdEncoding.DecodeString(line)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ticket key: %s %w", err.Error(), native_errors.ErrGeneral)
+		}
+		if len(key) != 48 && len(key) != 80 {
+			return nil, fmt.Errorf("ticket keys must be 48 or 80 bytes long, got %d %w", len(key), native_errors.ErrGeneral)
+		}
+		if size != 0 && len(key) != size {
+			return nil, fmt.Errorf("ticket keys of different sizes can not be mixed %w", native_errors.ErrGeneral)
+		}
+		size = len(key)
+		keys = append(keys, line)
+	}
+	if len(keys) < TLSTicketKeysNo {
+		return nil, fmt.Errorf("at least %d ticket keys are required %w", TLSTicketKeysNo, native_errors.ErrGeneral)
+	}
+	return keys, nil
+}
+
+// SerializeTLSTicketKeys returns contents of a ticket keys file
+func SerializeTLSTicketKeys(keys []string) string {
+	return strings.Join(keys, "\n") + "\n"
+}
+
+// RotateTLSTicketKeys appends the key as the newest one and drops the oldest keys, so the file
+// keeps the same TLSTicketKeysNo keys HAProxy has in memory after `set ssl tls-key`
+func RotateTLSTicketKeys(keys []string, key string) []string {
+	keys = append(append([]string{}, keys...), key)
+	if len(keys) > TLSTicketKeysNo {
+		keys = keys[len(keys)-TLSTicketKeysNo:]
+	}
+	return keys
+}
@@ -0,0 +1,94 @@
+// Copyright 2019 HAProxy Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package storage
+
+import (
+	"encoding/base64"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestNewTLSTicketKeys(t *testing.T) {
+	content, err := NewTLSTicketKeys()
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys, err := ParseTLSTicketKeys(content)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(keys) != TLSTicketKeysNo {
+		t.Fatalf("%d keys generated, expected %d", len(keys), TLSTicketKeysNo)
+	}
+	seen := map[string]bool{}
+	for _, k := range keys {
+		key, err := base64.StdEncoding.DecodeString(k)
+		if err != nil || len(key) != TLSTicketKeySize {
+			t.Errorf("key %s is not %d base64 encoded bytes", k, TLSTicketKeySize)
+		}
+		if seen[k] {
+			t.Errorf("key %s generated twice", k)
+		}
+		seen[k] = true
+	}
+	if SerializeTLSTicketKeys(keys) != content {
+		t.Error("ticket keys changed by serialization")
+	}
+}
+
+func TestParseTLSTicketKeys(t *testing.T) {
+	key48 := base64.StdEncoding.EncodeToString(make([]byte, 48))
+	key80 := base64.StdEncoding.EncodeToString(make([]byte, 80))
+	key32 := base64.StdEncoding.EncodeToString(make([]byte, 32))
+
+	keys, err := ParseTLSTicketKeys("# ticket keys\n" + key48 + "\n\n" + key48 + "\n  " + key48 + "  \n" + key48 + "\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(keys) != 4 {
+		t.Errorf("%d keys parsed, expected 4", len(keys))
+	}
+
+	tests := map[string]string{
+		"too few keys":     key80 + "\n" + key80 + "\n",
+		"invalid length":   strings.Repeat(key32+"\n", 3),
+		"mixed sizes":      key80 + "\n" + key48 + "\n" + key80 + "\n",
+		"not base64":       key80 + "\n" + key80 + "\nnot-base64!\n",
+		"empty":            "",
+		"only comments":    "# a\n# b\n# c\n",
+		"truncated base64": key80 + "\n" + key80 + "\n" + key80[:len(key80)-2] + "\n",
+	}
+	for name, content := range tests {
+		if _, err := ParseTLSTicketKeys(content); err == nil {
+			t.Errorf("%s: ticket keys accepted", name)
+		}
+	}
+}
+
+func TestRotateTLSTicketKeys(t *testing.T) {
+	keys := []string{"a", "b", "c"}
+	rotated := RotateTLSTicketKeys(keys, "d")
+	if !reflect.DeepEqual(rotated, []string{"b", "c", "d"}) {
+		t.Errorf("rotated keys %v, expected the oldest dropped and the new key last", rotated)
+	}
+	if !reflect.DeepEqual(keys, []string{"a", "b", "c"}) {
+		t.Errorf("rotation changed the given keys to %v", keys)
+	}
+	if rotated := RotateTLSTicketKeys([]string{"a", "b", "c", "d", "e"}, "f"); !reflect.DeepEqual(rotated, []string{"d", "e", "f"}) {
+		t.Errorf("rotated keys %v, expected the last %d keys", rotated, TLSTicketKeysNo)
+	}
+}
@@ -0,0 +1,204 @@
+// Copyright 2019 HAProxy Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package client_native
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"sync"
+	"time"
+
+	native_errors "github.com/haproxytech/client-native/v2/errors"
+	"github.com/haproxytech/client-native/v2/storage"
+)
+
+// ConfigSync replicates the configuration and storage files of a primary node to standby nodes.
+// Nodes are expected to use the same storage directories, since the configuration references
+// files by path.
+type ConfigSync struct {
+	Primary  *HAProxyClient
+	Standbys *ClusterClient
+	// Interval between checks of the primary configuration version in Run
+	Interval time.Duration
+	// PruneFiles removes files of standby storages that do not exist on the primary
+	PruneFiles bool
+	// OnSync is called with the result of every sync done by Run
+	OnSync func(version int64, result ClusterResult)
+
+	mu          sync.Mutex
+	lastVersion int64
+}
+
+// NewConfigSync returns a sync of the primary to the standby nodes
+func NewConfigSync(primary *HAProxyClient, standbys ...*ClusterNode) (*ConfigSync, error) {
+	cluster, err := NewClusterClient(standbys...)
+	if err != nil {
+		return nil, err
+	}
+	return &ConfigSync{
+		Primary:  primary,
+		Standbys: cluster,
+		Interval: 5 * time.Second,
+	}, nil
+}
+
+// Sync pushes the storage files and the configuration of the primary to all standby nodes, the
+// configuration keeps the primary version. After pushing, the version and checksum of the
+// configuration and the checksums of the files on each standby are verified. Returns the
+// synced version and the result of each standby.
+func (s *ConfigSync) Sync() (int64, ClusterResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	version, config, err := s.Primary.Configuration.GetRawConfiguration("", 0)
+	if err != nil {
+		return 0, nil, err
+	}
+	checksum := sha256.Sum256([]byte(config))
+
+	result := s.Standbys.Do(func(n *ClusterNode) error {
+		for _, pair := range storagePairs(s.Primary, n.Client) {
+			if err := s.syncStorage(pair[0], pair[1]); err != nil {
+				return err
+			}
+		}
+
+		data := fmt.Sprintf("# _version=%d\n%s", version, config)
+		if err := n.Client.Configuration.PostRawConfiguration(&data, version, true); err != nil {
+			return err
+		}
+
+		v, standbyConfig, err := n.Client.Configuration.GetRawConfiguration("", 0)
+		if err != nil {
+			return err
+		}
+		if v != version {
+			return fmt.Errorf("version %d after sync, expected %d", v, version)
+		}
+		if sha256.Sum256([]byte(standbyConfig)) != checksum {
+			return fmt.Errorf("configuration checksum mismatch after sync")
+		}
+		return nil
+	})
+	if result.Err() == nil {
+		s.lastVersion = version
+	}
+	return version, result, nil
+}
+
+// Run syncs the standby nodes every time the primary configuration version changes, until stop
+// is closed. Unsuccessful syncs are retried on the next interval.
+func (s *ConfigSync) Run(stop <-chan struct{}) {
+	ticker := time.NewTicker(s.Interval)
+	defer ticker.Stop()
+	for {
+		v, err := s.Primary.Configuration.GetVersion("")
+		s.mu.Lock()
+		changed := err == nil && v != s.lastVersion
+		s.mu.Unlock()
+		if changed {
+			version, result, err := s.Sync()
+			if err == nil && s.OnSync != nil {
+				s.OnSync(version, result)
+			}
+		}
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// storagePairs returns the storages configured on both the primary and the standby
+func storagePairs(primary *HAProxyClient, standby *HAProxyClient) [][2]storage.Storage {
+	pairs := [][2]storage.Storage{}
+	add := func(p, s storage.Storage) {
+		if p != nil && s != nil {
+			pairs = append(pairs, [2]storage.Storage{p, s})
+		}
+	}
+	add(primary.MapStorage, standby.MapStorage)
+	add(primary.GeneralStorage, standby.GeneralStorage)
+	add(primary.SSLCertStorage, standby.SSLCertStorage)
+	add(primary.TLSTicketKeysStorage, standby.TLSTicketKeysStorage)
+	add(primary.CrtListStorage, standby.CrtListStorage)
+	add(primary.CAStorage, standby.CAStorage)
+	if primary.Spoe != nil && standby.Spoe != nil {
+		add(primary.Spoe.Storage(), standby.Spoe.Storage())
+	}
+	return pairs
+}
+
+// syncStorage copies all files of the primary storage to the standby storage and verifies their
+// checksums
+func (s *ConfigSync) syncStorage(primary storage.Storage, standby storage.Storage) error {
+	files, err := primary.GetAll()
+	if err != nil {
+		return err
+	}
+	names := map[string]bool{}
+	for _, f := range files {
+		name := filepath.Base(f)
+		names[name] = true
+		data, err := ioutil.ReadFile(f)
+		if err != nil {
+			return err
+		}
+		path, err := putStorageFile(standby, name, data)
+		if err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		written, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		if sha256.Sum256(written) != sha256.Sum256(data) {
+			return fmt.Errorf("%s: checksum mismatch after sync", name)
+		}
+	}
+
+	if !s.PruneFiles {
+		return nil
+	}
+	standbyFiles, err := standby.GetAll()
+	if err != nil {
+		return err
+	}
+	for _, f := range standbyFiles {
+		name := filepath.Base(f)
+		if names[name] {
+			continue
+		}
+		if err := standby.Delete(name); err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// putStorageFile replaces the file in storage or creates it if it does not exist
+func putStorageFile(st storage.Storage, name string, data []byte) (string, error) {
+	path, err := st.Replace(name, string(data))
+	if errors.Is(err, native_errors.ErrNotFound) {
+		return st.Create(name, ioutil.NopCloser(bytes.NewReader(data)))
+	}
+	return path, err
+}
@@ -0,0 +1,157 @@
+// Copyright 2019 HAProxy Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package client_native
+
+import (
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/haproxytech/client-native/v2/storage"
+	"github.com/haproxytech/models/v2"
+)
+
+// corruptStorage changes the content of the files written to it
+type corruptStorage struct {
+	storage.Storage
+}
+
+func (s *corruptStorage) Replace(name string, config string) (string, error) {
+	return s.Storage.Replace(name, config+"# corrupted\n")
+}
+
+func (s *corruptStorage) Create(name string, readCloser io.ReadCloser) (string, error) {
+	data, err := ioutil.ReadAll(readCloser)
+	if err != nil {
+		return "", err
+	}
+	return s.Storage.Create(name, ioutil.NopCloser(strings.NewReader(string(data)+"# corrupted\n")))
+}
+
+func newTestSync(t *testing.T) (*ConfigSync, *HAProxyClient, *HAProxyClient) {
+	primary := newTestClient(t)
+	primary.MapStorage = testStorage(t, storage.MapsType)
+	standby := newTestClient(t)
+	standby.MapStorage = testStorage(t, storage.MapsType)
+	s, err := NewConfigSync(primary, &ClusterNode{Name: "standby", Client: standby})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := primary.MapStorage.Create("hosts.map", ioutil.NopCloser(strings.NewReader("example.com app\n"))); err != nil {
+		t.Fatal(err)
+	}
+	if err := primary.Configuration.CreateBackend(&models.Backend{Name: "synced"}, "", 1); err != nil {
+		t.Fatal(err)
+	}
+	return s, primary, standby
+}
+
+func TestSync(t *testing.T) {
+	s, primary, standby := newTestSync(t)
+	if _, err := standby.MapStorage.Create("stale.map", ioutil.NopCloser(strings.NewReader(""))); err != nil {
+		t.Fatal(err)
+	}
+	s.PruneFiles = true
+
+	version, result, err := s.Sync()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := result.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if version != 2 {
+		t.Errorf("synced version %d, expected 2", version)
+	}
+
+	_, primaryConfig, err := primary.Configuration.GetRawConfiguration("", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v, standbyConfig, err := standby.Configuration.GetRawConfiguration("", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v != version || standbyConfig != primaryConfig {
+		t.Errorf("standby configuration version %d differs from the primary:\n%s", v, standbyConfig)
+	}
+	path, err := standby.MapStorage.Get("hosts.map")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := ioutil.ReadFile(path); string(data) != "example.com app\n" {
+		t.Errorf("map synced as %q", data)
+	}
+	if _, err := standby.MapStorage.Get("stale.map"); err == nil {
+		t.Error("file missing on the primary not pruned")
+	}
+
+	// files changed on the primary are replaced
+	if _, err := primary.MapStorage.Replace("hosts.map", "example.org app\n"); err != nil {
+		t.Fatal(err)
+	}
+	if _, result, _ := s.Sync(); result.Err() != nil {
+		t.Fatal(result.Err())
+	}
+	if data, _ := ioutil.ReadFile(path); string(data) != "example.org app\n" {
+		t.Errorf("map synced as %q", data)
+	}
+}
+
+func TestSyncChecksumMismatch(t *testing.T) {
+	s, _, standby := newTestSync(t)
+	standby.MapStorage = &corruptStorage{Storage: standby.MapStorage}
+
+	_, result, err := s.Sync()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Err() == nil || !strings.Contains(result.Err().Error(), "hosts.map: checksum mismatch after sync") {
+		t.Fatalf("sync result %v, expected checksum mismatch", result.Err())
+	}
+	// the configuration is not pushed when files failed to sync
+	if v, _ := standby.Configuration.GetVersion(""); v != 1 {
+		t.Errorf("standby version %d after failed sync, expected 1", v)
+	}
+}
+
+func TestSyncFailedPush(t *testing.T) {
+	s, _, standby := newTestSync(t)
+	standby.Configuration.ValidateConfigurationFile = true
+	standby.Configuration.Haproxy = "false"
+
+	_, result, err := s.Sync()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Err() == nil {
+		t.Fatal("sync succeeded although the standby rejected the configuration")
+	}
+	if _, _, err := standby.Configuration.GetBackend("synced", ""); err == nil {
+		t.Error("rejected configuration applied on the standby")
+	}
+
+	// the failed sync is retried
+	standby.Configuration.Haproxy = "echo"
+	if _, result, _ := s.Sync(); result.Err() != nil {
+		t.Fatal(result.Err())
+	}
+	if _, _, err := standby.Configuration.GetBackend("synced", ""); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,102 @@
+// Copyright 2019 HAProxy Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package client_native
+
+import (
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	native_errors "github.com/haproxytech/client-native/v2/errors"
+	"github.com/haproxytech/client-native/v2/storage"
+)
+
+// CreateTLSTicketKeys writes a new ticket keys file with random keys to ticket keys storage.
+// Returns the path of the file.
+func (c *HAProxyClient) CreateTLSTicketKeys(name string) (string, error) {
+	if c.TLSTicketKeysStorage == nil {
+		return "", fmt.Errorf("tls ticket keys storage not configured %w", native_errors.ErrGeneral)
+	}
+	content, err := storage.NewTLSTicketKeys()
+	if err != nil {
+		return "", err
+	}
+	return c.TLSTicketKeysStorage.Create(name, ioutil.NopCloser(strings.NewReader(content)))
+}
+
+// SetBindTLSTicketKeys configures the bind to load its session ticket keys from the ticket keys
+// file in storage. One of version or transactionID is mandatory. Returns error on fail, nil on success.
+func (c *HAProxyClient) SetBindTLSTicketKeys(frontend string, bind string, name string, transactionID string, version int64) error {
+	if c.TLSTicketKeysStorage == nil {
+		return fmt.Errorf("tls ticket keys storage not configured %w", native_errors.ErrGeneral)
+	}
+	path, err := c.TLSTicketKeysStorage.Get(name)
+	if err != nil {
+		return err
+	}
+	return c.withTransaction(transactionID, version, func(t string) error {
+		_, b, err := c.Configuration.GetBind(bind, frontend, t)
+		if err != nil {
+			return err
+		}
+		b.TLSTicketKeys = path
+		return c.Configuration.EditBind(bind, frontend, b, t, 0)
+	})
+}
+
+// RotateTLSTicketKeys adds a new random key to the ticket keys file and drops the oldest one. When
+// the file is loaded in the running HAProxy, the same key is set through the runtime API so
+// new tickets are encrypted with the rotated key without a reload. Without a runtime client only
+// the file is rotated and HAProxy picks the key up on its next reload.
+func (c *HAProxyClient) RotateTLSTicketKeys(name string) error {
+	if c.TLSTicketKeysStorage == nil {
+		return fmt.Errorf("tls ticket keys storage not configured %w", native_errors.ErrGeneral)
+	}
+	path, err := c.TLSTicketKeysStorage.Get(name)
+	if err != nil {
+		return err
+	}
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	keys, err := storage.ParseTLSTicketKeys(string(content))
+	if err != nil {
+		return err
+	}
+	key, err := storage.NewTLSTicketKey()
+	if err != nil {
+		return err
+	}
+	keys = storage.RotateTLSTicketKeys(keys, key)
+	if _, err := c.TLSTicketKeysStorage.Replace(name, storage.SerializeTLSTicketKeys(keys)); err != nil {
+		return err
+	}
+
+	if c.Runtime == nil {
+		return nil
+	}
+	files, err := c.Runtime.ShowTLSKeys()
+	if err != nil {
+		return err
+	}
+	for _, f := range files {
+		if f.File == path {
+			return c.Runtime.SetTLSKey(path, key)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+// Copyright 2019 HAProxy Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package client_native
+
+import (
+	"io/ioutil"
+	"reflect"
+	"testing"
+
+	"github.com/haproxytech/client-native/v2/storage"
+)
+
+func TestTLSTicketKeys(t *testing.T) {
+	c := newTestClient(t)
+	if _, err := c.CreateTLSTicketKeys("web.keys"); err == nil {
+		t.Error("ticket keys created without storage")
+	}
+	c.TLSTicketKeysStorage = testStorage(t, storage.TLSTicketKeysType)
+
+	path, err := c.CreateTLSTicketKeys("web.keys")
+	if err != nil {
+		t.Fatal(err)
+	}
+	before := readTLSTicketKeys(t, path)
+
+	if err := c.SetBindTLSTicketKeys("web", "bind_1", "web.keys", "", 1); err == nil {
+		t.Error("ticket keys set on a missing bind")
+	}
+	if err := c.SetBindTLSTicketKeys("web", ":8080", "missing.keys", "", 1); err == nil {
+		t.Error("missing ticket keys file set on bind")
+	}
+	if err := c.SetBindTLSTicketKeys("web", ":8080", "web.keys", "", 1); err != nil {
+		t.Fatal(err)
+	}
+	_, b, err := c.Configuration.GetBind(":8080", "web", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b.TLSTicketKeys != path {
+		t.Errorf("bind loads ticket keys from %s, expected %s", b.TLSTicketKeys, path)
+	}
+
+	if err := c.RotateTLSTicketKeys("web.keys"); err != nil {
+		t.Fatal(err)
+	}
+	after := readTLSTicketKeys(t, path)
+	if len(after) != storage.TLSTicketKeysNo {
+		t.Fatalf("%d keys after rotation, expected %d", len(after), storage.TLSTicketKeysNo)
+	}
+	if !reflect.DeepEqual(after[:2], before[1:]) {
+		t.Error("rotation did not drop the oldest key")
+	}
+	for _, k := range before {
+		if after[2] == k {
+			t.Error("rotation did not add a new key last")
+		}
+	}
+}
+
+func readTLSTicketKeys(t *testing.T, path string) []string {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys, err := storage.ParseTLSTicketKeys(string(content))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return keys
+}
@@ -0,0 +1,119 @@
+// Copyright 2019 HAProxy Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package client_native
+
+import (
+	"strings"
+
+	"github.com/haproxytech/client-native/v2/storage"
+)
+
+// inTransaction runs fn in a new transaction started on the current configuration
+// version, commits it on success and deletes it if fn fails
+func (c *HAProxyClient) inTransaction(fn func(transactionID string) error) error {
+	v, err := c.Configuration.GetVersion("")
+	if err != nil {
+		return err
+	}
+	return c.withTransaction("", v, fn)
+}
+
+// withTransaction runs fn in the given transaction. If transactionID is empty, fn runs
+// in a new transaction started on version which is committed on success and deleted
+// if fn fails, so composite changes are applied all at once.
+func (c *HAProxyClient) withTransaction(transactionID string, version int64, fn func(transactionID string) error) error {
+	if transactionID != "" {
+		return fn(transactionID)
+	}
+	t, err := c.Configuration.StartTransaction(version)
+	if err != nil {
+		return err
+	}
+	if err := fn(t.ID); err != nil {
+		c.Configuration.DeleteTransaction(t.ID)
+		return err
+	}
+	// failed commits are cleaned up by the configuration client
+	_, err = c.Configuration.CommitTransaction(t.ID)
+	return err
+}
+
+// transactionFile is a storage file created for a change in a transaction
+type transactionFile struct {
+	storage storage.Storage
+	name    string
+	path    string
+}
+
+// DeleteTransaction deletes the transaction and the storage files created for changes in it,
+// like map routing maps and blocklist ACL files. Files created for transactions already deleted
+// with the configuration client are removed too, unless the configuration uses them.
+// Returns error on fail, nil on success.
+func (c *HAProxyClient) DeleteTransaction(transactionID string) error {
+	if err := c.Configuration.DeleteTransaction(transactionID); err != nil {
+		return err
+	}
+	return c.cleanupTransactionFiles()
+}
+
+// addTransactionFile records a storage file created for a change in the transaction, so it
+// is deleted with the transaction. Nothing is recorded outside of a transaction.
+func (c *HAProxyClient) addTransactionFile(transactionID string, s storage.Storage, name, path string) {
+	if transactionID == "" {
+		return
+	}
+	c.transactionFilesMu.Lock()
+	defer c.transactionFilesMu.Unlock()
+	if c.transactionFiles == nil {
+		c.transactionFiles = map[string][]transactionFile{}
+	}
+	c.transactionFiles[transactionID] = append(c.transactionFiles[transactionID], transactionFile{storage: s, name: name, path: path})
+}
+
+// cleanupTransactionFiles deletes the recorded files of transactions which are no longer in
+// progress and whose files are not used by the configuration, the transaction was deleted or
+// failed instead of being committed
+func (c *HAProxyClient) cleanupTransactionFiles() error {
+	c.transactionFilesMu.Lock()
+	defer c.transactionFilesMu.Unlock()
+	if len(c.transactionFiles) == 0 {
+		return nil
+	}
+	_, config, err := c.Configuration.GetRawConfiguration("", 0)
+	if err != nil {
+		return err
+	}
+	var lastErr error
+	for id, files := range c.transactionFiles {
+		if t, err := c.Configuration.GetTransaction(id); err == nil && t.Status == "in_progress" {
+			continue
+		}
+		for _, f := range files {
+			if strings.Contains(config, f.path) {
+				continue
+			}
+			if _, err := f.storage.Get(f.name); err != nil {
+				// already deleted by a change in the transaction
+				continue
+			}
+			if err := f.storage.Delete(f.name); err != nil {
+				lastErr = err
+			}
+		}
+		delete(c.transactionFiles, id)
+	}
+	return lastErr
+}